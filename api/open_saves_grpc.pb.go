@@ -69,8 +69,24 @@ type OpenSavesClient interface {
 	// CreateChunkedBlob. It returns an INVALID_ARGUMENT error if the blob is not
 	// a chunked object.
 	GetBlobChunk(ctx context.Context, in *GetBlobChunkRequest, opts ...grpc.CallOption) (OpenSaves_GetBlobChunkClient, error)
+	// ArchiveRecordBlobs streams a zip archive of the blobs of a record: the
+	// inline blob and every ready external blob, including chunked blobs.
+	// The archive ends with a MANIFEST.json entry that lists the blobs and any
+	// blob whose object is missing instead of failing the whole archive.
+	ArchiveRecordBlobs(ctx context.Context, in *ArchiveRecordBlobsRequest, opts ...grpc.CallOption) (OpenSaves_ArchiveRecordBlobsClient, error)
 	// DeleteBlob removes an blob object from a record.
 	DeleteBlob(ctx context.Context, in *DeleteBlobRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ExternalizeBlob moves the inline blob of a record to an external blob.
+	// The key of the new blob is blob_key if set, or otherwise derived from the
+	// store key, the record key, and name, so that clients can compute the key
+	// in advance. It fails with ALREADY_EXISTS if a blob with the key exists.
+	ExternalizeBlob(ctx context.Context, in *ExternalizeBlobRequest, opts ...grpc.CallOption) (*ExternalizeBlobResponse, error)
+	// CheckBlobExists looks up a ready blob object in the store with the same
+	// MD5 hash and size, so that clients can skip uploading content the server
+	// already has. It returns an empty blob_key if there is no such blob.
+	// If the server is configured to use eventually consistent lookups, a blob
+	// uploaded just before the call may not be found and clients upload it again.
+	CheckBlobExists(ctx context.Context, in *CheckBlobExistsRequest, opts ...grpc.CallOption) (*CheckBlobExistsResponse, error)
 	// Ping returns the same string provided by the client.
 	// The string is optional and the server returns an empty string if
 	// omitted.
@@ -406,6 +422,38 @@ func (x *openSavesGetBlobChunkClient) Recv() (*GetBlobChunkResponse, error) {
 	return m, nil
 }
 
+func (c *openSavesClient) ArchiveRecordBlobs(ctx context.Context, in *ArchiveRecordBlobsRequest, opts ...grpc.CallOption) (OpenSaves_ArchiveRecordBlobsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OpenSaves_ServiceDesc.Streams[4], "/opensaves.OpenSaves/ArchiveRecordBlobs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &openSavesArchiveRecordBlobsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OpenSaves_ArchiveRecordBlobsClient interface {
+	Recv() (*ArchiveRecordBlobsResponse, error)
+	grpc.ClientStream
+}
+
+type openSavesArchiveRecordBlobsClient struct {
+	grpc.ClientStream
+}
+
+func (x *openSavesArchiveRecordBlobsClient) Recv() (*ArchiveRecordBlobsResponse, error) {
+	m := new(ArchiveRecordBlobsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *openSavesClient) DeleteBlob(ctx context.Context, in *DeleteBlobRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, "/opensaves.OpenSaves/DeleteBlob", in, out, opts...)
@@ -415,6 +463,24 @@ func (c *openSavesClient) DeleteBlob(ctx context.Context, in *DeleteBlobRequest,
 	return out, nil
 }
 
+func (c *openSavesClient) ExternalizeBlob(ctx context.Context, in *ExternalizeBlobRequest, opts ...grpc.CallOption) (*ExternalizeBlobResponse, error) {
+	out := new(ExternalizeBlobResponse)
+	err := c.cc.Invoke(ctx, "/opensaves.OpenSaves/ExternalizeBlob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *openSavesClient) CheckBlobExists(ctx context.Context, in *CheckBlobExistsRequest, opts ...grpc.CallOption) (*CheckBlobExistsResponse, error) {
+	out := new(CheckBlobExistsResponse)
+	err := c.cc.Invoke(ctx, "/opensaves.OpenSaves/CheckBlobExists", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *openSavesClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
 	out := new(PingResponse)
 	err := c.cc.Invoke(ctx, "/opensaves.OpenSaves/Ping", in, out, opts...)
@@ -537,8 +603,24 @@ type OpenSavesServer interface {
 	// CreateChunkedBlob. It returns an INVALID_ARGUMENT error if the blob is not
 	// a chunked object.
 	GetBlobChunk(*GetBlobChunkRequest, OpenSaves_GetBlobChunkServer) error
+	// ArchiveRecordBlobs streams a zip archive of the blobs of a record: the
+	// inline blob and every ready external blob, including chunked blobs.
+	// The archive ends with a MANIFEST.json entry that lists the blobs and any
+	// blob whose object is missing instead of failing the whole archive.
+	ArchiveRecordBlobs(*ArchiveRecordBlobsRequest, OpenSaves_ArchiveRecordBlobsServer) error
 	// DeleteBlob removes an blob object from a record.
 	DeleteBlob(context.Context, *DeleteBlobRequest) (*emptypb.Empty, error)
+	// ExternalizeBlob moves the inline blob of a record to an external blob.
+	// The key of the new blob is blob_key if set, or otherwise derived from the
+	// store key, the record key, and name, so that clients can compute the key
+	// in advance. It fails with ALREADY_EXISTS if a blob with the key exists.
+	ExternalizeBlob(context.Context, *ExternalizeBlobRequest) (*ExternalizeBlobResponse, error)
+	// CheckBlobExists looks up a ready blob object in the store with the same
+	// MD5 hash and size, so that clients can skip uploading content the server
+	// already has. It returns an empty blob_key if there is no such blob.
+	// If the server is configured to use eventually consistent lookups, a blob
+	// uploaded just before the call may not be found and clients upload it again.
+	CheckBlobExists(context.Context, *CheckBlobExistsRequest) (*CheckBlobExistsResponse, error)
 	// Ping returns the same string provided by the client.
 	// The string is optional and the server returns an empty string if
 	// omitted.
@@ -667,9 +749,18 @@ func (UnimplementedOpenSavesServer) GetBlob(*GetBlobRequest, OpenSaves_GetBlobSe
 func (UnimplementedOpenSavesServer) GetBlobChunk(*GetBlobChunkRequest, OpenSaves_GetBlobChunkServer) error {
 	return status.Errorf(codes.Unimplemented, "method GetBlobChunk not implemented")
 }
+func (UnimplementedOpenSavesServer) ArchiveRecordBlobs(*ArchiveRecordBlobsRequest, OpenSaves_ArchiveRecordBlobsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ArchiveRecordBlobs not implemented")
+}
 func (UnimplementedOpenSavesServer) DeleteBlob(context.Context, *DeleteBlobRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteBlob not implemented")
 }
+func (UnimplementedOpenSavesServer) ExternalizeBlob(context.Context, *ExternalizeBlobRequest) (*ExternalizeBlobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExternalizeBlob not implemented")
+}
+func (UnimplementedOpenSavesServer) CheckBlobExists(context.Context, *CheckBlobExistsRequest) (*CheckBlobExistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckBlobExists not implemented")
+}
 func (UnimplementedOpenSavesServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
@@ -1053,6 +1144,27 @@ func (x *openSavesGetBlobChunkServer) Send(m *GetBlobChunkResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _OpenSaves_ArchiveRecordBlobs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ArchiveRecordBlobsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OpenSavesServer).ArchiveRecordBlobs(m, &openSavesArchiveRecordBlobsServer{stream})
+}
+
+type OpenSaves_ArchiveRecordBlobsServer interface {
+	Send(*ArchiveRecordBlobsResponse) error
+	grpc.ServerStream
+}
+
+type openSavesArchiveRecordBlobsServer struct {
+	grpc.ServerStream
+}
+
+func (x *openSavesArchiveRecordBlobsServer) Send(m *ArchiveRecordBlobsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _OpenSaves_DeleteBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteBlobRequest)
 	if err := dec(in); err != nil {
@@ -1071,6 +1183,42 @@ func _OpenSaves_DeleteBlob_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OpenSaves_ExternalizeBlob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExternalizeBlobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpenSavesServer).ExternalizeBlob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opensaves.OpenSaves/ExternalizeBlob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpenSavesServer).ExternalizeBlob(ctx, req.(*ExternalizeBlobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OpenSaves_CheckBlobExists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckBlobExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OpenSavesServer).CheckBlobExists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opensaves.OpenSaves/CheckBlobExists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OpenSavesServer).CheckBlobExists(ctx, req.(*CheckBlobExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OpenSaves_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PingRequest)
 	if err := dec(in); err != nil {
@@ -1282,6 +1430,14 @@ var OpenSaves_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteBlob",
 			Handler:    _OpenSaves_DeleteBlob_Handler,
 		},
+		{
+			MethodName: "ExternalizeBlob",
+			Handler:    _OpenSaves_ExternalizeBlob_Handler,
+		},
+		{
+			MethodName: "CheckBlobExists",
+			Handler:    _OpenSaves_CheckBlobExists_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _OpenSaves_Ping_Handler,
@@ -1336,6 +1492,11 @@ var OpenSaves_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _OpenSaves_GetBlobChunk_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ArchiveRecordBlobs",
+			Handler:       _OpenSaves_ArchiveRecordBlobs_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "open_saves.proto",
 }
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/googleforgames/open-saves/internal/app/collector"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
 	"github.com/googleforgames/open-saves/internal/pkg/cmd"
 	log "github.com/sirupsen/logrus"
 )
@@ -27,25 +28,54 @@ import (
 func main() {
 	defaultCloud := cmd.GetEnvVarString("OPEN_SAVES_CLOUD", "gcp")
 	defaultBucket := cmd.GetEnvVarString("OPEN_SAVES_BUCKET", "gs://triton-dev-store")
+	defaultBlobStoreKind := cmd.GetEnvVarString("OPEN_SAVES_BLOB_STORE_KIND", blob.KindGCS)
+	defaultBlobRootDir := cmd.GetEnvVarString("OPEN_SAVES_BLOB_ROOT_DIR", "")
+	defaultBlobSyncMode := cmd.GetEnvVarString("OPEN_SAVES_BLOB_SYNC_MODE", "file")
+	defaultReplicaBucket := cmd.GetEnvVarString("OPEN_SAVES_REPLICA_BUCKET", "")
 	defaultProject := cmd.GetEnvVarString("OPEN_SAVES_PROJECT", "triton-for-games-dev")
 	defaultCache := cmd.GetEnvVarString("OPEN_SAVES_CACHE", "localhost:6379")
 	defaultExpiration := cmd.GetEnvVarDuration("OPEN_SAVES_GARBAGE_EXPIRATION", 24*time.Hour)
+	defaultWorkers := cmd.GetEnvVarUInt("OPEN_SAVES_GARBAGE_WORKERS", collector.DefaultWorkers)
+	defaultTombstoneRetention := cmd.GetEnvVarDuration("OPEN_SAVES_TOMBSTONE_RETENTION", 7*24*time.Hour)
+	defaultLeaseTTL := cmd.GetEnvVarDuration("OPEN_SAVES_GARBAGE_LEASE_TTL", collector.DefaultLeaseTTL)
+	defaultScrubStore := cmd.GetEnvVarString("OPEN_SAVES_SCRUB_STORE", "")
+	defaultScrubSampleSize := cmd.GetEnvVarUInt("OPEN_SAVES_SCRUB_SAMPLE_SIZE", collector.DefaultScrubSampleSize)
+	defaultScrubBytesPerSecond := cmd.GetEnvVarUInt("OPEN_SAVES_SCRUB_BYTES_PER_SECOND", collector.DefaultScrubBytesPerSecond)
 
 	var (
 		cloud      = flag.String("cloud", defaultCloud, "The public cloud provider you wish to run Open Saves on")
 		bucket     = flag.String("bucket", defaultBucket, "The bucket which will hold Open Saves blobs")
+		blobKind   = flag.String("blob-store-kind", defaultBlobStoreKind, "The kind of the blob store, either \"gcs\" with -bucket or \"filesystem\" with -blob-root-dir")
+		blobRoot   = flag.String("blob-root-dir", defaultBlobRootDir, "The root directory of blobs for the filesystem blob store")
+		blobSync   = flag.String("blob-sync-mode", defaultBlobSyncMode, "How the filesystem blob store flushes written blobs, either \"file\", \"file_and_dir\", or \"none\"")
+		replica    = flag.String("replica-bucket", defaultReplicaBucket, "A bucket with a copy of the blobs to heal corrupt blobs from, or none if empty")
 		project    = flag.String("project", defaultProject, "The GCP project ID to use for Datastore")
 		cache      = flag.String("cache", defaultCache, "The address of the cache store instance")
 		expiration = flag.Duration("garbage-expiration", defaultExpiration, "Collector deletes entries older than this time.Duration value (e.g. \"24h\")")
+		workers    = flag.Uint64("garbage-workers", defaultWorkers, "The number of goroutines that delete blobs in parallel")
+		retention  = flag.Duration("tombstone-retention", defaultTombstoneRetention, "Collector deletes record tombstones older than this time.Duration value, or none if 0")
+		leaseTTL   = flag.Duration("garbage-lease-ttl", defaultLeaseTTL, "How long the lease that prevents concurrent collectors lasts if the collector dies")
+
+		scrubStore          = flag.String("scrub-store", defaultScrubStore, "The key of the store whose blobs are verified against their checksums after collection, or none if empty")
+		scrubSampleSize     = flag.Uint64("scrub-sample-size", defaultScrubSampleSize, "The number of blobs verified in each run")
+		scrubBytesPerSecond = flag.Uint64("scrub-bytes-per-second", defaultScrubBytesPerSecond, "The maximum average rate at which blobs are read for verification")
+		scrubMarkError      = flag.Bool("scrub-mark-error", false, "Mark corrupt blobs as errors, which makes the collector delete them")
 	)
 
 	flag.Parse()
 	if *cloud == "" {
 		log.Fatal("missing -cloud argument for cloud provider")
 	}
-	if *bucket == "" {
+	if *blobKind == blob.KindGCS && *bucket == "" {
 		log.Fatal("missing -bucket argument for storing blobs")
 	}
+	if *blobKind == blob.KindFilesystem && *blobRoot == "" {
+		log.Fatal("missing -blob-root-dir argument for storing blobs")
+	}
+	syncMode, err := blob.ParseSyncMode(*blobSync)
+	if err != nil {
+		log.Fatalf("invalid -blob-sync-mode argument: %v", err)
+	}
 	if *project == "" {
 		log.Fatal("missing -project argument")
 	}
@@ -59,6 +89,21 @@ func main() {
 		Project: *project,
 		Cache:   *cache,
 		Before:  time.Now().Add(-*expiration),
+		Workers: int(*workers),
+
+		BlobStoreKind: *blobKind,
+		BlobRootDir:   *blobRoot,
+		BlobSyncMode:  syncMode,
+		ReplicaBucket: *replica,
+
+		TombstoneRetention: *retention,
+		LeaseTTL:           *leaseTTL,
+		Scrub: collector.ScrubberConfig{
+			StoreKey:       *scrubStore,
+			SampleSize:     int(*scrubSampleSize),
+			BytesPerSecond: int64(*scrubBytesPerSecond),
+			MarkError:      *scrubMarkError,
+		},
 	}
 
 	ctx := context.Background()
@@ -16,7 +16,9 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
@@ -39,14 +41,51 @@ type Config struct {
 	Cache   string
 	Project string
 	Before  time.Time
+	// BlobStoreKind is the kind of the blob store backend, either blob.KindGCS,
+	// which uses Bucket, or blob.KindFilesystem, which uses BlobRootDir.
+	// An empty kind is blob.KindGCS.
+	BlobStoreKind string
+	// BlobRootDir is the root directory of objects of the filesystem backend.
+	BlobRootDir string
+	// BlobSyncMode is how the filesystem backend flushes written objects.
+	BlobSyncMode blob.SyncMode
+	// ReplicaBucket is the URL of a bucket that has a copy of the blob objects.
+	// The scrubber heals corrupt objects from it. There is no replica if empty.
+	ReplicaBucket string
+	// Workers is the number of goroutines that delete blobs in parallel.
+	// DefaultWorkers is used if it is 0.
+	Workers int
+	// TombstoneRetention is how long record tombstones are kept before they
+	// are deleted. Tombstones are not deleted if it is not positive.
+	TombstoneRetention time.Duration
+	// LeaseHolder identifies this collector in the garbage collection lease.
+	// The host name and a random UUID are used if it is empty.
+	LeaseHolder string
+	// LeaseTTL is how long the garbage collection lease lasts without renewal.
+	// DefaultLeaseTTL is used if it is 0. The lease is renewed in the
+	// background every third of the TTL while the collector runs.
+	LeaseTTL time.Duration
+	// Scrub configures the scrubbing cycle run after the collection steps.
+	// Blobs are not scrubbed if Scrub.StoreKey is empty.
+	Scrub ScrubberConfig
 }
 
+// DefaultLeaseTTL is the TTL of the garbage collection lease used when
+// Config.LeaseTTL is 0.
+const DefaultLeaseTTL = 30 * time.Minute
+
+// gcBatchSize is the number of candidate BlobRefs passed to the workers, or
+// ChunkRefs whose objects are deleted with one DeleteMulti call, at a time.
+const gcBatchSize = 1000
+
 // Collector is a garbage collector of unused resources in Datastore.
 type Collector struct {
 	cache  *cache.Cache
-	metaDB *metadb.MetaDB
+	metaDB metadataStore
 	blob   blob.BlobStore
 	cfg    *Config
+	// scrubber is nil if scrubbing is not configured.
+	scrubber *Scrubber
 }
 
 func newCollector(ctx context.Context, cfg *Config) (*Collector, error) {
@@ -56,7 +95,11 @@ func newCollector(ctx context.Context, cfg *Config) (*Collector, error) {
 	switch cfg.Cloud {
 	case "gcp":
 		log.Infoln("Starting Open Saves garbage collector on GCP")
-		gcs, err := blob.NewBlobGCP(ctx, cfg.Bucket)
+		bs, err := blob.NewBlobStore(ctx, cfg.BlobStoreKind, blob.BlobStoreConfig{
+			BucketURL: cfg.Bucket,
+			RootDir:   cfg.BlobRootDir,
+			SyncMode:  cfg.BlobSyncMode,
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -65,13 +108,29 @@ func newCollector(ctx context.Context, cfg *Config) (*Collector, error) {
 			log.Fatalf("Failed to create a MetaDB instance: %v", err)
 			return nil, err
 		}
+		if cfg.LeaseHolder == "" {
+			host, _ := os.Hostname()
+			cfg.LeaseHolder = fmt.Sprintf("%s-%s", host, uuid.NewString())
+		}
+		if cfg.LeaseTTL == 0 {
+			cfg.LeaseTTL = DefaultLeaseTTL
+		}
 		cache := cache.New(redis.NewRedis(cfg.Cache), &config.CacheConfig{})
 		c := &Collector{
-			blob:   gcs,
+			blob:   bs,
 			metaDB: metadb,
 			cache:  cache,
 			cfg:    cfg,
 		}
+		if cfg.Scrub.StoreKey != "" {
+			var replica blob.BlobStore
+			if cfg.ReplicaBucket != "" {
+				if replica, err = blob.NewBlobGCP(ctx, cfg.ReplicaBucket); err != nil {
+					return nil, err
+				}
+			}
+			c.scrubber = NewScrubber(metadb, bs, replica, cfg.Scrub)
+		}
 		return c, nil
 	default:
 		return nil, fmt.Errorf("cloud provider(%q) is not yet supported", cfg.Cloud)
@@ -85,40 +144,174 @@ func Run(ctx context.Context, cfg *Config) {
 		log.Fatalf("Failed to create a new Collector instance: %v", err)
 		return
 	}
-	c.run(ctx)
+	if err := c.run(ctx); err != nil {
+		log.Errorf("Garbage collection stopped: %v", err)
+	}
 }
 
-func (c *Collector) run(ctx context.Context) {
+// run runs the collection steps while holding the garbage collection lease.
+// It returns metadb.ErrGCLeaseHeld without collecting anything if another
+// collector holds the lease. The lease is renewed in the background while the
+// steps run; if it is lost, the steps are canceled and the renewal error is
+// returned.
+func (c *Collector) run(ctx context.Context) error {
+	if err := c.renewLease(ctx); err != nil {
+		return err
+	}
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		if err := keepLease(leaseCtx, c.cfg.LeaseTTL, c.renewLease); err != nil {
+			cancel(err)
+		}
+	}()
+	defer func() {
+		cancel(nil)
+		<-renewed
+		if err := c.metaDB.ReleaseGCLease(ctx, c.cfg.LeaseHolder); err != nil {
+			log.Warnf("MetaDB.ReleaseGCLease failed for holder (%v): %v", c.cfg.LeaseHolder, err)
+		}
+	}()
+
+	steps := []func(context.Context){
+		// Delete expired records first so that their blobs are collected below.
+		c.deleteExpiredRecords,
+	}
 	var statuses = []blobref.Status{
 		blobref.StatusPendingDeletion,
 		blobref.StatusError,
 		blobref.StatusInitializing,
 	}
 	for _, s := range statuses {
-		c.deleteMatchingBlobRefs(ctx, s, c.cfg.Before)
-		c.deleteMatchingChunkRefs(ctx, s, c.cfg.Before)
+		s := s
+		steps = append(steps,
+			func(ctx context.Context) { c.deleteMatchingBlobRefs(ctx, s, c.cfg.Before) },
+			func(ctx context.Context) { c.deleteMatchingChunkRefs(ctx, s, c.cfg.Before) },
+		)
 	}
+	if c.cfg.TombstoneRetention > 0 {
+		steps = append(steps, c.reapTombstones)
+	}
+	steps = append(steps, c.reapIdempotencyTokens)
+	if c.scrubber != nil {
+		steps = append(steps, c.scrubber.scrubAndLog)
+	}
+	for _, step := range steps {
+		if leaseCtx.Err() != nil {
+			break
+		}
+		step(leaseCtx)
+	}
+	if ctx.Err() == nil && leaseCtx.Err() != nil {
+		return context.Cause(leaseCtx)
+	}
+	return nil
 }
 
-func (c *Collector) deleteChunk(ctx context.Context, chunk *chunkref.ChunkRef) error {
-	if err := c.blob.Delete(ctx, chunk.ObjectPath()); err != nil {
-		if gcerrors.Code(err) != gcerrors.NotFound {
-			log.Errorf("Blob.Delete failed for chunkref key(%v): %v", chunk.Key, err)
-			if chunk.Status != blobref.StatusError {
-				chunk.Fail()
-				if err := c.metaDB.UpdateChunkRef(ctx, chunk); err != nil {
-					log.Errorf("MetaDB.UpdateChunkRef failed for key(%v): %v", chunk.Key, err)
-				}
-			}
+// keepLease calls renew every third of ttl until ctx is done, and returns nil
+// then. It returns the renewal error once the lease is lost, either because
+// renew returns metadb.ErrGCLeaseHeld or because renewals keep failing until
+// the lease would expire before the next attempt.
+func keepLease(ctx context.Context, ttl time.Duration, renew func(context.Context) error) error {
+	interval := ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		err := renew(ctx)
+		if err == nil {
+			renewedAt = time.Now()
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, metadb.ErrGCLeaseHeld) || time.Since(renewedAt)+interval >= ttl {
 			return err
-		} else {
-			log.Warnf("Blob (%v) was not found.", chunk.ObjectPath())
 		}
 	}
+}
+
+// renewLease acquires or extends the garbage collection lease of the collector.
+func (c *Collector) renewLease(ctx context.Context) error {
+	if _, err := c.metaDB.AcquireGCLease(ctx, c.cfg.LeaseHolder, c.cfg.LeaseTTL); err != nil {
+		log.Errorf("MetaDB.AcquireGCLease failed for holder (%v): %v", c.cfg.LeaseHolder, err)
+		return err
+	}
 	return nil
 }
 
+func (c *Collector) reapTombstones(ctx context.Context) {
+	n, err := c.metaDB.ReapTombstones(ctx, c.cfg.TombstoneRetention)
+	if err != nil {
+		log.Errorf("MetaDB.ReapTombstones failed after deleting %d tombstones: %v", n, err)
+		return
+	}
+	log.Infof("Deleted %d record tombstones older than %v", n, c.cfg.TombstoneRetention)
+}
+
+func (c *Collector) reapIdempotencyTokens(ctx context.Context) {
+	n, err := c.metaDB.ReapIdempotencyTokens(ctx, time.Now())
+	if err != nil {
+		log.Errorf("MetaDB.ReapIdempotencyTokens failed after deleting %d tokens: %v", n, err)
+		return
+	}
+	log.Infof("Deleted %d expired idempotency tokens", n)
+}
+
+func (c *Collector) deleteExpiredRecords(ctx context.Context) {
+	n, err := c.metaDB.DeleteExpiredRecords(ctx, time.Now(), 0)
+	if err != nil {
+		log.Errorf("MetaDB.DeleteExpiredRecords failed after deleting %d records: %v", n, err)
+		return
+	}
+	log.Infof("Deleted %d expired records", n)
+}
+
+// deleteChunks deletes the objects of chunks with DeleteMulti, and returns the
+// chunks whose objects were deleted or didn't exist. The chunks that failed are
+// marked as failed, and the error of DeleteMulti is returned with them.
+func (c *Collector) deleteChunks(ctx context.Context, chunks []*chunkref.ChunkRef) ([]*chunkref.ChunkRef, error) {
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	paths := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		paths = append(paths, chunk.ObjectPath())
+	}
+	failedPaths, err := c.blob.DeleteMulti(ctx, paths)
+	if err == nil {
+		return chunks, nil
+	}
+	log.Errorf("Blob.DeleteMulti failed for %d of %d chunk objects: %v", len(failedPaths), len(chunks), err)
+	failed := make(map[string]bool, len(failedPaths))
+	for _, p := range failedPaths {
+		failed[p] = true
+	}
+	deleted := make([]*chunkref.ChunkRef, 0, len(chunks)-len(failedPaths))
+	for _, chunk := range chunks {
+		if !failed[chunk.ObjectPath()] {
+			deleted = append(deleted, chunk)
+			continue
+		}
+		if chunk.Status != blobref.StatusError {
+			chunk.Fail()
+			if err := c.metaDB.UpdateChunkRef(ctx, chunk); err != nil {
+				log.Errorf("MetaDB.UpdateChunkRef failed for key(%v): %v", chunk.Key, err)
+			}
+		}
+	}
+	return deleted, err
+}
+
 func (c *Collector) deleteChildChunks(ctx context.Context, blobKey uuid.UUID) error {
+	var chunks []*chunkref.ChunkRef
 	cur := c.metaDB.GetChildChunkRefs(ctx, blobKey)
 	for {
 		chunk, err := cur.Next()
@@ -129,11 +322,10 @@ func (c *Collector) deleteChildChunks(ctx context.Context, blobKey uuid.UUID) er
 			log.Errorf("cursor.Next() returned error: %v", err)
 			return err
 		}
-		if err := c.deleteChunk(ctx, chunk); err != nil {
-			return err
-		}
+		chunks = append(chunks, chunk)
 	}
-	return nil
+	_, err := c.deleteChunks(ctx, chunks)
+	return err
 }
 
 func (c *Collector) markBlobFailed(ctx context.Context, blob *blobref.BlobRef) {
@@ -146,18 +338,43 @@ func (c *Collector) markBlobFailed(ctx context.Context, blob *blobref.BlobRef) {
 	}
 }
 
-func (c *Collector) deleteBlob(ctx context.Context, blob *blobref.BlobRef) {
+// deleteBlobObject deletes the object of b only if it is the generation that
+// existed when the deletion was first attempted. The generation is saved to the
+// BlobRef before deleting, so that a retry after a failure doesn't delete a
+// newer object uploaded to the same path in the meantime.
+func (c *Collector) deleteBlobObject(ctx context.Context, b *blobref.BlobRef) error {
+	if b.ObjectGeneration == 0 {
+		g, err := blob.Generation(ctx, c.blob, b.ObjectPath())
+		if err != nil {
+			return err
+		}
+		if g != 0 {
+			b.ObjectGeneration = g
+			if _, err := c.metaDB.UpdateBlobRef(ctx, b); err != nil {
+				return err
+			}
+		}
+	}
+	err := blob.DeleteIfGeneration(ctx, c.blob, b.ObjectPath(), b.ObjectGeneration)
+	if errors.Is(err, blob.ErrGenerationMismatch) {
+		log.Warnf("Blob (%v) was replaced by a newer object, which is kept. Deleting BlobRef (%v) anyway.", b.ObjectPath(), b.Key)
+		return nil
+	}
+	return err
+}
+
+func (c *Collector) deleteBlob(ctx context.Context, blob *blobref.BlobRef) error {
 	if blob.Chunked {
 		if err := c.deleteChildChunks(ctx, blob.Key); err != nil {
 			c.markBlobFailed(ctx, blob)
-			return
+			return err
 		}
 	} else {
-		if err := c.blob.Delete(ctx, blob.ObjectPath()); err != nil {
+		if err := c.deleteBlobObject(ctx, blob); err != nil {
 			if gcerrors.Code(err) != gcerrors.NotFound {
 				log.Errorf("Blob.Delete failed for key(%v): %v", blob.Key, err)
 				c.markBlobFailed(ctx, blob)
-				return
+				return err
 			} else {
 				log.Warnf("Blob (%v) was not found. Deleting BlobRef (%v) anyway.", blob.ObjectPath(), blob.Key)
 			}
@@ -165,9 +382,17 @@ func (c *Collector) deleteBlob(ctx context.Context, blob *blobref.BlobRef) {
 	}
 	if err := c.metaDB.DeleteBlobRef(ctx, blob.Key); err != nil {
 		log.Errorf("DeleteBlobRef failed for key(%v): %v", blob.Key, err)
-	} else {
-		log.Infof("Deleted BlobRef (%v), status = %v", blob.Key, blob.Status)
+		return err
 	}
+	log.Infof("Deleted BlobRef (%v), status = %v", blob.Key, blob.Status)
+	return nil
+}
+
+// deleteBlobs deletes blobs in parallel using c.cfg.Workers goroutines.
+func (c *Collector) deleteBlobs(ctx context.Context, blobs []*blobref.BlobRef) error {
+	summary, err := runDeletes(ctx, blobs, c.cfg.Workers, c.deleteBlob)
+	log.Infof("Deleted %v BlobRef objects, %v failed", summary.Succeeded, summary.Failed)
+	return err
 }
 
 func (c *Collector) deleteMatchingBlobRefs(ctx context.Context, status blobref.Status, olderThan time.Time) error {
@@ -177,6 +402,7 @@ func (c *Collector) deleteMatchingBlobRefs(ctx context.Context, status blobref.S
 		log.Fatalf("ListBlobRefsByStatus returned error: %v", err)
 		return err
 	}
+	var batch []*blobref.BlobRef
 	for {
 		blob, err := cursor.Next()
 		if err == iterator.Done {
@@ -187,15 +413,32 @@ func (c *Collector) deleteMatchingBlobRefs(ctx context.Context, status blobref.S
 			break
 		}
 		if blob.Timestamps.UpdatedAt.Before(olderThan) {
-			c.deleteBlob(ctx, blob)
+			batch = append(batch, blob)
+		}
+		if len(batch) >= gcBatchSize {
+			if err := c.deleteBlobs(ctx, batch); err != nil {
+				return err
+			}
+			batch = nil
 		}
 	}
-	return nil
+	return c.deleteBlobs(ctx, batch)
 }
 
 func (c *Collector) deleteMatchingChunkRefs(ctx context.Context, status blobref.Status, olderThan time.Time) error {
 	log.Infof("Garbage collecting ChunkRef objects with status = %v, and older than %v", status, olderThan)
 	cursor := c.metaDB.ListChunkRefsByStatus(ctx, status)
+	var batch []*chunkref.ChunkRef
+	deleteBatch := func() {
+		// Failed chunks are logged and marked by deleteChunks, and retried by the next run.
+		deleted, _ := c.deleteChunks(ctx, batch)
+		for _, chunk := range deleted {
+			if err := c.metaDB.DeleteChunkRef(ctx, chunk.BlobRef, chunk.Key); err != nil {
+				log.Errorf("DeleteChunkRef failed for chunk (%v): %v", chunk.Key, err)
+			}
+		}
+		batch = nil
+	}
 	for {
 		chunk, err := cursor.Next()
 		if err == iterator.Done {
@@ -203,17 +446,16 @@ func (c *Collector) deleteMatchingChunkRefs(ctx context.Context, status blobref.
 		}
 		if err != nil {
 			log.Errorf("cursor.Next() return error: %v", err)
+			deleteBatch()
 			return err
 		}
 		if chunk.Timestamps.UpdatedAt.Before(olderThan) {
-			if err := c.deleteChunk(ctx, chunk); err != nil {
-				log.Errorf("deleteChunk failed for chunk (%v): %v", chunk.Key, err)
-				continue
-			}
-			if err := c.metaDB.DeleteChunkRef(ctx, chunk.BlobRef, chunk.Key); err != nil {
-				log.Errorf("DeleteChunkRef failed for chunk (%v): %v", chunk.Key, err)
-			}
+			batch = append(batch, chunk)
+		}
+		if len(batch) >= gcBatchSize {
+			deleteBatch()
 		}
 	}
+	deleteBatch()
 	return nil
 }
@@ -16,16 +16,21 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/datastore"
 	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/memblob"
 	"gocloud.dev/gcerrors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -120,7 +125,7 @@ func chunkRefKey(chunk *chunkref.ChunkRef) *datastore.Key {
 func setupTestChunkRef(ctx context.Context, t *testing.T, collector *Collector, ds *datastore.Client, blob *blobref.BlobRef, chunk *chunkref.ChunkRef) {
 	t.Helper()
 
-	if err := collector.metaDB.InsertChunkRef(ctx, blob, chunk); err != nil {
+	if err := collector.metaDB.(*metadb.MetaDB).InsertChunkRef(ctx, blob, chunk); err != nil {
 		t.Fatalf("InsertChunkRef failed: %v", err)
 	}
 	t.Cleanup(func() {
@@ -168,7 +173,7 @@ func TestCollector_DeletesBlobs(t *testing.T) {
 		setupTestBlobRef(ctx, t, ds, b)
 		setupExternalBlob(ctx, t, collector, b.ObjectPath())
 	}
-	collector.run(ctx)
+	assert.NoError(t, collector.run(ctx))
 
 	exists := []bool{false, true, false, true, true}
 	for i, e := range exists {
@@ -209,7 +214,7 @@ func TestCollector_DeletesUnlinkedBlobRefs(t *testing.T) {
 	// Create an external blob for just one item and test if it's deleted too
 	setupExternalBlob(ctx, t, collector, blobRefs[1].ObjectPath())
 
-	collector.run(ctx)
+	assert.NoError(t, collector.run(ctx))
 
 	for _, b := range blobRefs {
 		ref, err := collector.metaDB.GetBlobRef(ctx, b.Key)
@@ -222,6 +227,72 @@ func TestCollector_DeletesUnlinkedBlobRefs(t *testing.T) {
 	}
 }
 
+func TestCollector_DeletesExpiredRecords(t *testing.T) {
+	ctx := context.Background()
+	collector := newTestCollector(ctx, t)
+	store := setupTestStore(ctx, t, collector)
+	record, err := collector.metaDB.InsertRecord(ctx, store.Key, &record.Record{
+		Key:        uuid.NewString(),
+		Properties: make(record.PropertyMap),
+		ExpiresAt:  time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		collector.metaDB.DeleteRecord(ctx, store.Key, record.Key)
+	})
+
+	blobRef := blobref.NewBlobRef(0, store.Key, record.Key)
+	require.NoError(t, blobRef.Ready())
+	blobRef.Timestamps.CreatedAt = collector.cfg.Before.Add(-1 * time.Second)
+	blobRef.Timestamps.UpdatedAt = collector.cfg.Before.Add(-1 * time.Second)
+	setupTestBlobRef(ctx, t, newDatastoreClient(ctx, t), blobRef)
+	setupExternalBlob(ctx, t, collector, blobRef.ObjectPath())
+
+	assert.NoError(t, collector.run(ctx))
+
+	_, err = collector.metaDB.GetRecord(ctx, store.Key, record.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotErrorIs(t, err, metadb.ErrRecordExpired)
+	ref, err := collector.metaDB.GetBlobRef(ctx, blobRef.Key)
+	assert.Nil(t, ref)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = collector.blob.Get(ctx, blobRef.ObjectPath())
+	assert.Equal(t, gcerrors.NotFound, gcerrors.Code(err))
+}
+
+func TestCollector_KeepsReuploadedBlob(t *testing.T) {
+	ctx := context.Background()
+	collector := newTestCollector(ctx, t)
+	store := setupTestStore(ctx, t, collector)
+	record := setupTestRecord(ctx, t, collector, store.Key)
+	blobRef := blobref.NewBlobRef(0, store.Key, record.Key)
+	blobRef.Fail()
+	blobRef.Timestamps.CreatedAt = collector.cfg.Before.Add(-1 * time.Second)
+	blobRef.Timestamps.UpdatedAt = collector.cfg.Before.Add(-1 * time.Second)
+
+	// A previous attempt captured the generation and deleted the object, and
+	// then a new object was uploaded to the same path.
+	setupExternalBlob(ctx, t, collector, blobRef.ObjectPath())
+	g, err := blob.Generation(ctx, collector.blob, blobRef.ObjectPath())
+	if err != nil {
+		t.Fatalf("Generation() failed: %v", err)
+	}
+	blobRef.ObjectGeneration = g
+	if err := blob.DeleteIfGeneration(ctx, collector.blob, blobRef.ObjectPath(), g); err != nil {
+		t.Fatalf("DeleteIfGeneration() failed: %v", err)
+	}
+	setupExternalBlob(ctx, t, collector, blobRef.ObjectPath())
+	setupTestBlobRef(ctx, t, newDatastoreClient(ctx, t), blobRef)
+
+	assert.NoError(t, collector.run(ctx))
+
+	ref, err := collector.metaDB.GetBlobRef(ctx, blobRef.Key)
+	assert.Nil(t, ref)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = collector.blob.Get(ctx, blobRef.ObjectPath())
+	assert.NoError(t, err, "the new object should be kept")
+}
+
 func TestCollector_DeletesChunkedBlobs(t *testing.T) {
 	const chunkRefCount = 5
 
@@ -254,7 +325,7 @@ func TestCollector_DeletesChunkedBlobs(t *testing.T) {
 		setupTestChunkRef(ctx, t, collector, ds, blob, c)
 		setupExternalBlob(ctx, t, collector, c.ObjectPath())
 	}
-	collector.run(ctx)
+	assert.NoError(t, collector.run(ctx))
 
 	exists := []bool{false, true, false, true, true}
 	for i, e := range exists {
@@ -273,3 +344,127 @@ func TestCollector_DeletesChunkedBlobs(t *testing.T) {
 		}
 	}
 }
+
+func TestCollector_SkipsWhileLeaseHeld(t *testing.T) {
+	ctx := context.Background()
+	collector := newTestCollector(ctx, t)
+	store := setupTestStore(ctx, t, collector)
+	record := setupTestRecord(ctx, t, collector, store.Key)
+	blobRef := blobref.NewBlobRef(0, store.Key, record.Key)
+	blobRef.MarkForDeletion()
+	blobRef.Timestamps.UpdatedAt = collector.cfg.Before.Add(-1 * time.Microsecond)
+	setupTestBlobRef(ctx, t, newDatastoreClient(ctx, t), blobRef)
+
+	const other = "other-collector"
+	_, err := collector.metaDB.AcquireGCLease(ctx, other, time.Minute)
+	require.NoError(t, err)
+	t.Cleanup(func() { collector.metaDB.ReleaseGCLease(ctx, other) })
+
+	assert.ErrorIs(t, collector.run(ctx), metadb.ErrGCLeaseHeld)
+	_, err = collector.metaDB.GetBlobRef(ctx, blobRef.Key)
+	assert.NoError(t, err, "the BlobRef must not be deleted while another collector holds the lease")
+
+	require.NoError(t, collector.metaDB.ReleaseGCLease(ctx, other))
+	assert.NoError(t, collector.run(ctx))
+	_, err = collector.metaDB.GetBlobRef(ctx, blobRef.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestKeepLease_Renews(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	renewals := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- keepLease(ctx, 30*time.Millisecond, func(context.Context) error {
+			renewals <- struct{}{}
+			return nil
+		})
+	}()
+	<-renewals
+	<-renewals
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestKeepLease_Lost(t *testing.T) {
+	err := keepLease(context.Background(), 30*time.Millisecond, func(context.Context) error {
+		return metadb.ErrGCLeaseHeld
+	})
+	assert.ErrorIs(t, err, metadb.ErrGCLeaseHeld)
+}
+
+func TestKeepLease_RenewalsFailUntilExpiry(t *testing.T) {
+	const ttl = 30 * time.Millisecond
+	renewFailed := status.Error(codes.Unavailable, "unavailable")
+	err := keepLease(context.Background(), ttl, func(context.Context) error {
+		return renewFailed
+	})
+	assert.ErrorIs(t, err, renewFailed)
+}
+
+// partialDeleteBlobStore fails DeleteMulti for the paths in fail and deletes
+// the others from the underlying store.
+type partialDeleteBlobStore struct {
+	blob.BlobStore
+	fail  map[string]bool
+	calls int
+}
+
+func (b *partialDeleteBlobStore) DeleteMulti(ctx context.Context, paths []string) ([]string, error) {
+	b.calls++
+	var rest, failed []string
+	for _, p := range paths {
+		if b.fail[p] {
+			failed = append(failed, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	if _, err := b.BlobStore.DeleteMulti(ctx, rest); err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		return failed, errors.New("delete failed")
+	}
+	return nil, nil
+}
+
+// fakeChunkMetaDB records the ChunkRefs updated by the collector.
+type fakeChunkMetaDB struct {
+	metadataStore
+	updated []*chunkref.ChunkRef
+}
+
+func (f *fakeChunkMetaDB) UpdateChunkRef(ctx context.Context, chunk *chunkref.ChunkRef) error {
+	f.updated = append(f.updated, chunk)
+	return nil
+}
+
+func TestDeleteChunks_PartialFailure(t *testing.T) {
+	ctx := context.Background()
+	mem, err := blob.NewBlobGCP(ctx, "mem://")
+	require.NoError(t, err)
+	t.Cleanup(func() { mem.Close() })
+
+	blobKey := uuid.New()
+	chunks := []*chunkref.ChunkRef{chunkref.New(blobKey, 0), chunkref.New(blobKey, 1), chunkref.New(blobKey, 2)}
+	for _, chunk := range chunks[:2] {
+		require.NoError(t, mem.Put(ctx, chunk.ObjectPath(), []byte("chunk")))
+	}
+	// The object of the last chunk doesn't exist, which counts as deleted.
+	bs := &partialDeleteBlobStore{BlobStore: mem, fail: map[string]bool{chunks[1].ObjectPath(): true}}
+	fake := new(fakeChunkMetaDB)
+	c := &Collector{metaDB: fake, blob: bs}
+
+	deleted, err := c.deleteChunks(ctx, chunks)
+	assert.Error(t, err)
+	assert.Equal(t, 1, bs.calls)
+	assert.Equal(t, []*chunkref.ChunkRef{chunks[0], chunks[2]}, deleted)
+	if assert.Len(t, fake.updated, 1) {
+		assert.Equal(t, chunks[1].Key, fake.updated[0].Key)
+		assert.Equal(t, blobref.StatusError, fake.updated[0].Status)
+	}
+	exists, err := blob.Exists(ctx, mem, chunks[0].ObjectPath())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
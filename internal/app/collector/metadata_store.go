@@ -0,0 +1,50 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+)
+
+// metadataStore is the metadata store that the collector depends on: the
+// operations of metadb.MetadataStore and the garbage collection ones beyond it.
+// *metadb.MetaDB is the only implementation.
+type metadataStore interface {
+	metadb.MetadataStore
+
+	AcquireGCLease(ctx context.Context, holder string, ttl time.Duration) (*metadb.GCLease, error)
+	ReleaseGCLease(ctx context.Context, holder string) error
+
+	ListBlobRefsByStatus(ctx context.Context, status blobref.Status) (*blobref.BlobRefCursor, error)
+	UpdateBlobRefWithUpdater(ctx context.Context, key uuid.UUID, updater metadb.BlobRefUpdater) (*blobref.BlobRef, error)
+
+	ListChunkRefsByStatus(ctx context.Context, status blobref.Status) *chunkref.ChunkRefCursor
+	GetChildChunkRefs(ctx context.Context, blobKey uuid.UUID) *chunkref.ChunkRefCursor
+	UpdateChunkRef(ctx context.Context, chunk *chunkref.ChunkRef) error
+	DeleteChunkRef(ctx context.Context, blobKey, key uuid.UUID) error
+
+	DeleteExpiredRecords(ctx context.Context, now time.Time, limit int) (int, error)
+	ReapIdempotencyTokens(ctx context.Context, now time.Time) (int, error)
+	ReapTombstones(ctx context.Context, retention time.Duration) (int, error)
+}
+
+// Assert MetaDB implements the metadataStore interface.
+var _ metadataStore = new(metadb.MetaDB)
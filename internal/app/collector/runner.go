@@ -0,0 +1,101 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+)
+
+// DefaultWorkers is the number of deletion workers used when Config.Workers is 0.
+const DefaultWorkers = 8
+
+// BlobError is an error returned while deleting a BlobRef and its objects.
+type BlobError struct {
+	Key uuid.UUID
+	Err error
+}
+
+func (e *BlobError) Error() string {
+	return fmt.Sprintf("failed to delete BlobRef (%v): %v", e.Key, e.Err)
+}
+
+func (e *BlobError) Unwrap() error {
+	return e.Err
+}
+
+// Summary summarizes the result of a garbage collection run.
+type Summary struct {
+	Succeeded int
+	Failed    int
+	Errors    []*BlobError
+}
+
+// deleteFunc deletes a BlobRef and its objects.
+type deleteFunc func(ctx context.Context, blob *blobref.BlobRef) error
+
+// runDeletes deletes blobs with del using up to workers goroutines in parallel.
+// Failures are recorded in the returned Summary and don't stop the other deletes.
+// If ctx is canceled, no more deletes are started and runDeletes returns ctx.Err()
+// after the in-flight deletes finish. Blobs that were not started are counted
+// neither as succeeded nor failed.
+func runDeletes(ctx context.Context, blobs []*blobref.BlobRef, workers int, del deleteFunc) (*Summary, error) {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	summary := new(Summary)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan *blobref.BlobRef)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				err := del(ctx, b)
+				mu.Lock()
+				if err != nil {
+					summary.Failed++
+					summary.Errors = append(summary.Errors, &BlobError{Key: b.Key, Err: err})
+				} else {
+					summary.Succeeded++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for _, b := range blobs {
+		// Check first as select doesn't prefer any ready case.
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		case jobs <- b:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return summary, err
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlobRefs(n int) []*blobref.BlobRef {
+	blobs := make([]*blobref.BlobRef, n)
+	for i := range blobs {
+		blobs[i] = blobref.NewBlobRef(0, "store", "record")
+	}
+	return blobs
+}
+
+func TestRunDeletes_Parallel(t *testing.T) {
+	const workers = 4
+	blobs := newTestBlobRefs(100)
+
+	var mu sync.Mutex
+	deleted := make(map[uuid.UUID]bool)
+	var running, maxRunning int32
+	summary, err := runDeletes(context.Background(), blobs, workers, func(ctx context.Context, b *blobref.BlobRef) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		mu.Lock()
+		if n > maxRunning {
+			maxRunning = n
+		}
+		deleted[b.Key] = true
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Summary{Succeeded: len(blobs)}, summary)
+	assert.Len(t, deleted, len(blobs))
+	assert.LessOrEqual(t, maxRunning, int32(workers))
+	assert.Greater(t, maxRunning, int32(1))
+}
+
+func TestRunDeletes_ContinuesPastFailures(t *testing.T) {
+	blobs := newTestBlobRefs(10)
+	failing := blobs[3].Key
+	errDelete := errors.New("delete failed")
+
+	summary, err := runDeletes(context.Background(), blobs, 3, func(ctx context.Context, b *blobref.BlobRef) error {
+		if b.Key == failing {
+			return errDelete
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 9, summary.Succeeded)
+	assert.Equal(t, 1, summary.Failed)
+	if assert.Len(t, summary.Errors, 1) {
+		assert.Equal(t, failing, summary.Errors[0].Key)
+		assert.ErrorIs(t, summary.Errors[0], errDelete)
+	}
+}
+
+func TestRunDeletes_Cancel(t *testing.T) {
+	const workers = 2
+	blobs := newTestBlobRefs(20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, len(blobs))
+	release := make(chan struct{})
+	var finished int32
+	done := make(chan struct{})
+	var summary *Summary
+	var err error
+	go func() {
+		defer close(done)
+		summary, err = runDeletes(ctx, blobs, workers, func(ctx context.Context, b *blobref.BlobRef) error {
+			started <- struct{}{}
+			<-release
+			atomic.AddInt32(&finished, 1)
+			return nil
+		})
+	}()
+	for i := 0; i < workers; i++ {
+		<-started
+	}
+	cancel()
+	// runDeletes must wait for the in-flight deletes.
+	select {
+	case <-done:
+		t.Fatal("runDeletes returned before in-flight deletes finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	<-done
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(summary.Succeeded), atomic.LoadInt32(&finished))
+	assert.Less(t, summary.Succeeded, len(blobs))
+	assert.Zero(t, summary.Failed)
+}
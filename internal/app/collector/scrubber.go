@@ -0,0 +1,261 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultScrubSampleSize is the number of blobs verified in a scrub cycle
+	// when ScrubberConfig.SampleSize is 0.
+	DefaultScrubSampleSize = 100
+	// DefaultScrubBytesPerSecond is the read rate of the scrubber when
+	// ScrubberConfig.BytesPerSecond is 0.
+	DefaultScrubBytesPerSecond = 1 << 20
+	// DefaultScrubInterval is the time between scrub cycles when
+	// ScrubberConfig.Interval is 0.
+	DefaultScrubInterval = time.Hour
+)
+
+// ScrubberConfig configures a Scrubber.
+type ScrubberConfig struct {
+	// StoreKey is the key of the store whose blobs are scrubbed.
+	StoreKey string
+	// SampleSize is the number of Ready blobs picked at random and verified
+	// in each cycle. DefaultScrubSampleSize is used if it is 0.
+	SampleSize int
+	// BytesPerSecond bounds the rate at which blob content is read, to limit
+	// egress costs. DefaultScrubBytesPerSecond is used if it is 0.
+	BytesPerSecond int64
+	// Interval is the time between the starts of scrub cycles in Run.
+	// DefaultScrubInterval is used if it is 0.
+	Interval time.Duration
+	// MarkError changes the status of corrupt blobs to StatusError in addition
+	// to setting FailureReason. Note that the garbage collector deletes blobs
+	// in StatusError, including their objects.
+	MarkError bool
+}
+
+// ScrubSummary summarizes a scrub cycle.
+type ScrubSummary struct {
+	// Verified is the number of blobs that matched their checksums.
+	Verified int
+	// Skipped is the number of blobs that were not read because they are
+	// chunked or have no checksums.
+	Skipped int
+	// Failed is the number of blobs that could not be read or flagged.
+	Failed int
+	// Bytes is the total byte length of the blobs read.
+	Bytes int64
+	// Mismatches are the keys of the blobs whose content didn't match the
+	// checksums.
+	Mismatches []uuid.UUID
+}
+
+// scrubMetaDB is the subset of MetaDB used by Scrubber.
+type scrubMetaDB interface {
+	QueryBlobRefs(ctx context.Context, filter metadb.BlobRefFilter) ([]*blobref.BlobRef, error)
+	UpdateBlobRefWithUpdater(ctx context.Context, key uuid.UUID, updater metadb.BlobRefUpdater) (*blobref.BlobRef, error)
+}
+
+// Scrubber periodically reads a sample of the Ready blobs of a store and
+// verifies their content against the stored checksums, to detect silent
+// corruption before clients do.
+type Scrubber struct {
+	metaDB scrubMetaDB
+	blob   blob.BlobStore
+	// replica is nil if there is no replica to heal corrupt objects from.
+	replica blob.BlobStore
+	cfg     ScrubberConfig
+
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+	startKey func() uuid.UUID
+}
+
+// NewScrubber returns a Scrubber of the blobs in metaDB and bs. Corrupt objects
+// are healed from replica if it is not nil; see blob.GetVerified.
+func NewScrubber(metaDB *metadb.MetaDB, bs, replica blob.BlobStore, cfg ScrubberConfig) *Scrubber {
+	return newScrubber(metaDB, bs, replica, cfg)
+}
+
+func newScrubber(metaDB scrubMetaDB, bs, replica blob.BlobStore, cfg ScrubberConfig) *Scrubber {
+	if cfg.SampleSize == 0 {
+		cfg.SampleSize = DefaultScrubSampleSize
+	}
+	if cfg.BytesPerSecond == 0 {
+		cfg.BytesPerSecond = DefaultScrubBytesPerSecond
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = DefaultScrubInterval
+	}
+	return &Scrubber{
+		metaDB:   metaDB,
+		blob:     bs,
+		replica:  replica,
+		cfg:      cfg,
+		now:      time.Now,
+		sleep:    sleepContext,
+		startKey: uuid.New,
+	}
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Run scrubs the store every Interval until ctx is done, logging the summary
+// of each cycle. It returns ctx.Err().
+func (s *Scrubber) Run(ctx context.Context) error {
+	for {
+		start := s.now()
+		s.scrubAndLog(ctx)
+		if err := s.sleep(ctx, s.cfg.Interval-s.now().Sub(start)); err != nil {
+			return err
+		}
+	}
+}
+
+// scrubAndLog runs ScrubOnce and logs its summary.
+func (s *Scrubber) scrubAndLog(ctx context.Context) {
+	summary, err := s.ScrubOnce(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.Errorf("Scrubbing store (%v) failed: %v", s.cfg.StoreKey, err)
+	}
+	if summary != nil {
+		log.Infof("Scrubbed store (%v): verified = %v, mismatched = %v, skipped = %v, failed = %v, bytes = %v",
+			s.cfg.StoreKey, summary.Verified, len(summary.Mismatches), summary.Skipped, summary.Failed, summary.Bytes)
+	}
+}
+
+// ScrubOnce verifies a random sample of up to SampleSize Ready blobs of the
+// store. The sample is the blobs that follow a random key, wrapping around to
+// the first key, so that the cost of a cycle doesn't grow with the number of
+// blobs in the store. Reads are paced so that no more than BytesPerSecond are read on
+// average. Corrupt blobs get FailureReason set, and are also marked as
+// StatusError if MarkError is set. Failures of individual blobs are counted
+// in the summary and don't stop the cycle. If ctx is done, ScrubOnce returns
+// the summary so far with ctx.Err().
+func (s *Scrubber) ScrubOnce(ctx context.Context) (*ScrubSummary, error) {
+	blobs, err := s.sample(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := new(ScrubSummary)
+	start := s.now()
+	for _, b := range blobs {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		if b.Chunked || (len(b.MD5) == 0 && !b.HasCRC32C) {
+			summary.Skipped++
+			continue
+		}
+		_, err := blob.GetVerified(ctx, s.blob, s.replica, b.ObjectPath(), b.Checksums)
+		switch {
+		case err == nil:
+			summary.Verified++
+		case errors.Is(err, blob.ErrChecksumMismatch):
+			summary.Mismatches = append(summary.Mismatches, b.Key)
+			if err := s.flag(ctx, b, err); err != nil {
+				log.Errorf("Failed to flag corrupt blob (%v): %v", b.Key, err)
+				summary.Failed++
+			}
+		default:
+			log.Errorf("Failed to scrub blob (%v): %v", b.Key, err)
+			summary.Failed++
+			continue
+		}
+		summary.Bytes += b.Size
+		// Wait until the average read rate since the start is within the limit.
+		wait := time.Duration(float64(summary.Bytes)/float64(s.cfg.BytesPerSecond)*float64(time.Second)) - s.now().Sub(start)
+		if wait > 0 {
+			if err := s.sleep(ctx, wait); err != nil {
+				return summary, err
+			}
+		}
+	}
+	return summary, nil
+}
+
+// sample returns up to SampleSize Ready blobs of the store, starting at a
+// random key. The blobs are only sampled uniformly if their keys are random
+// UUIDs. Time-ordered keys, e.g. from blobref.NewUUIDv7Generator, occupy a
+// narrow range of the key space that most random start keys fall after, so
+// the sample wraps around to the oldest blobs almost every cycle.
+func (s *Scrubber) sample(ctx context.Context) ([]*blobref.BlobRef, error) {
+	ready := blobref.StatusReady
+	start := s.startKey()
+	blobs, err := s.metaDB.QueryBlobRefs(ctx, metadb.BlobRefFilter{
+		StoreKey: s.cfg.StoreKey,
+		Status:   &ready,
+		StartKey: start,
+		Limit:    s.cfg.SampleSize,
+	})
+	// A nil start key leaves the range open, so the whole store has been read.
+	if err != nil || len(blobs) >= s.cfg.SampleSize || start == uuid.Nil {
+		return blobs, err
+	}
+	wrapped, err := s.metaDB.QueryBlobRefs(ctx, metadb.BlobRefFilter{
+		StoreKey: s.cfg.StoreKey,
+		Status:   &ready,
+		EndKey:   start,
+		Limit:    s.cfg.SampleSize - len(blobs),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(blobs, wrapped...), nil
+}
+
+// flag records cause as the FailureReason of b, marking b as StatusError
+// if configured. The BlobRef is left unchanged if it is no longer Ready or its
+// checksums have changed since it was verified, because the result no longer
+// applies to it.
+func (s *Scrubber) flag(ctx context.Context, b *blobref.BlobRef, cause error) error {
+	log.Warnf("Blob (%v) of record (%v) in store (%v) is corrupt: %v", b.Key, b.RecordKey, b.StoreKey, cause)
+	_, err := s.metaDB.UpdateBlobRefWithUpdater(ctx, b.Key, func(current *blobref.BlobRef) (*blobref.BlobRef, error) {
+		if current.Status != blobref.StatusReady || current.Size != b.Size ||
+			!bytes.Equal(current.MD5, b.MD5) || current.GetCRC32C() != b.GetCRC32C() {
+			log.Infof("Blob (%v) was changed after it was scrubbed, not flagging it", b.Key)
+			return nil, metadb.ErrNoUpdate
+		}
+		current.FailureReason = cause.Error()
+		if s.cfg.MarkError {
+			current.Fail()
+		}
+		return current, nil
+	})
+	return err
+}
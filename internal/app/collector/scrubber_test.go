@@ -0,0 +1,256 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/memblob"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeScrubMetaDB serves a fixed list of BlobRefs and records the updates.
+// Queries return copies of the BlobRefs in key order, as Datastore does.
+type fakeScrubMetaDB struct {
+	mu      sync.Mutex
+	blobs   []*blobref.BlobRef
+	updated []*blobref.BlobRef
+}
+
+func (f *fakeScrubMetaDB) QueryBlobRefs(ctx context.Context, filter metadb.BlobRefFilter) ([]*blobref.BlobRef, error) {
+	sorted := append([]*blobref.BlobRef(nil), f.blobs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key.String() < sorted[j].Key.String() })
+	var ret []*blobref.BlobRef
+	for _, b := range sorted {
+		if filter.Limit > 0 && len(ret) >= filter.Limit {
+			break
+		}
+		if b.StoreKey != filter.StoreKey || (filter.Status != nil && b.Status != *filter.Status) {
+			continue
+		}
+		if filter.StartKey != uuid.Nil && b.Key.String() < filter.StartKey.String() {
+			continue
+		}
+		if filter.EndKey != uuid.Nil && b.Key.String() >= filter.EndKey.String() {
+			continue
+		}
+		c := *b
+		ret = append(ret, &c)
+	}
+	return ret, nil
+}
+
+func (f *fakeScrubMetaDB) UpdateBlobRefWithUpdater(ctx context.Context, key uuid.UUID, updater metadb.BlobRefUpdater) (*blobref.BlobRef, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, b := range f.blobs {
+		if b.Key != key {
+			continue
+		}
+		updated, err := updater(b)
+		if err == metadb.ErrNoUpdate {
+			return b, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.updated = append(f.updated, updated)
+		return updated, nil
+	}
+	return nil, status.Error(codes.NotFound, "blob not found")
+}
+
+// fakeClock is a clock that only advances when sleep is called.
+type fakeClock struct {
+	t      time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.t = c.t.Add(d)
+	return nil
+}
+
+func newTestScrubber(ctx context.Context, t *testing.T, cfg ScrubberConfig) (*Scrubber, *fakeScrubMetaDB, blob.BlobStore, *fakeClock) {
+	t.Helper()
+	bs, err := blob.NewBlobGCP(ctx, "mem://")
+	require.NoError(t, err)
+	t.Cleanup(func() { bs.Close() })
+	fake := new(fakeScrubMetaDB)
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	s := newScrubber(fake, bs, nil, cfg)
+	s.now = clock.now
+	s.sleep = clock.sleep
+	s.startKey = func() uuid.UUID { return uuid.Nil }
+	return s, fake, bs, clock
+}
+
+// addScrubTestBlob saves content as the object of a new Ready blob with the
+// checksums of want.
+func addScrubTestBlob(ctx context.Context, t *testing.T, fake *fakeScrubMetaDB, bs blob.BlobStore, content, want []byte) *blobref.BlobRef {
+	t.Helper()
+	b := blobref.NewBlobRef(int64(len(content)), "store", "record")
+	require.NoError(t, b.Ready())
+	digest := checksums.NewDigest()
+	digest.Write(want)
+	b.Checksums = digest.Checksums()
+	require.NoError(t, bs.Put(ctx, b.ObjectPath(), content))
+	fake.blobs = append(fake.blobs, b)
+	return b
+}
+
+func TestScrubber_Clean(t *testing.T) {
+	ctx := context.Background()
+	s, fake, bs, _ := newTestScrubber(ctx, t, ScrubberConfig{StoreKey: "store"})
+	for i := 0; i < 3; i++ {
+		addScrubTestBlob(ctx, t, fake, bs, []byte("content"), []byte("content"))
+	}
+	chunked := blobref.NewChunkedBlobRef("store", "record", 1)
+	require.NoError(t, chunked.Ready())
+	fake.blobs = append(fake.blobs, chunked)
+	// Blobs of other stores are not scrubbed.
+	other := addScrubTestBlob(ctx, t, fake, bs, []byte("content"), []byte("corrupt"))
+	other.StoreKey = "other"
+
+	summary, err := s.ScrubOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &ScrubSummary{Verified: 3, Skipped: 1, Bytes: 3 * int64(len("content"))}, summary)
+	assert.Empty(t, fake.updated)
+}
+
+func TestScrubber_FlagsMismatch(t *testing.T) {
+	ctx := context.Background()
+	for _, markError := range []bool{false, true} {
+		s, fake, bs, _ := newTestScrubber(ctx, t, ScrubberConfig{StoreKey: "store", MarkError: markError})
+		good := addScrubTestBlob(ctx, t, fake, bs, []byte("content"), []byte("content"))
+		bad := addScrubTestBlob(ctx, t, fake, bs, []byte("rotten"), []byte("content"))
+		missing := blobref.NewBlobRef(1, "store", "record")
+		require.NoError(t, missing.Ready())
+		missing.MD5 = make([]byte, 16)
+		fake.blobs = append(fake.blobs, missing)
+
+		summary, err := s.ScrubOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.Verified)
+		assert.Equal(t, 1, summary.Failed, "the missing object must be counted as a failure")
+		assert.Equal(t, []*blobref.BlobRef{bad}, fake.updated)
+		if assert.Len(t, summary.Mismatches, 1) {
+			assert.Equal(t, bad.Key, summary.Mismatches[0])
+		}
+		assert.Contains(t, bad.FailureReason, blob.ErrChecksumMismatch.Error())
+		assert.Empty(t, good.FailureReason)
+		if markError {
+			assert.Equal(t, blobref.StatusError, bad.Status)
+		} else {
+			assert.Equal(t, blobref.StatusReady, bad.Status)
+		}
+	}
+}
+
+func TestScrubber_HealsFromReplica(t *testing.T) {
+	ctx := context.Background()
+	s, fake, bs, _ := newTestScrubber(ctx, t, ScrubberConfig{StoreKey: "store"})
+	replica, err := blob.NewBlobGCP(ctx, "mem://")
+	require.NoError(t, err)
+	t.Cleanup(func() { replica.Close() })
+	s.replica = replica
+	bad := addScrubTestBlob(ctx, t, fake, bs, []byte("rotten"), []byte("content"))
+	require.NoError(t, replica.Put(ctx, bad.ObjectPath(), []byte("content")))
+
+	summary, err := s.ScrubOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Verified)
+	assert.Empty(t, summary.Mismatches)
+	assert.Empty(t, fake.updated)
+	healed, err := bs.Get(ctx, bad.ObjectPath())
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(healed))
+}
+
+func TestScrubber_SampleWrapsAround(t *testing.T) {
+	ctx := context.Background()
+	s, fake, bs, _ := newTestScrubber(ctx, t, ScrubberConfig{StoreKey: "store", SampleSize: 3})
+	for i := 0; i < 5; i++ {
+		addScrubTestBlob(ctx, t, fake, bs, []byte("content"), []byte("content"))
+	}
+	keys := make([]string, len(fake.blobs))
+	for i, b := range fake.blobs {
+		keys[i] = b.Key.String()
+	}
+	sort.Strings(keys)
+	// Start at the fourth key so that the sample wraps around to the first.
+	s.startKey = func() uuid.UUID { return uuid.MustParse(keys[3]) }
+
+	sample, err := s.sample(ctx)
+	require.NoError(t, err)
+	got := make([]string, len(sample))
+	for i, b := range sample {
+		got[i] = b.Key.String()
+	}
+	assert.Equal(t, []string{keys[3], keys[4], keys[0]}, got)
+}
+
+func TestScrubber_FlagSkipsChangedBlob(t *testing.T) {
+	ctx := context.Background()
+	s, fake, bs, _ := newTestScrubber(ctx, t, ScrubberConfig{StoreKey: "store", MarkError: true})
+	b := addScrubTestBlob(ctx, t, fake, bs, []byte("rotten"), []byte("content"))
+	verified := *b
+	// The blob is deleted after it was read.
+	require.NoError(t, b.MarkForDeletion())
+
+	require.NoError(t, s.flag(ctx, &verified, blob.ErrChecksumMismatch))
+	assert.Empty(t, fake.updated)
+	assert.Equal(t, blobref.StatusPendingDeletion, b.Status)
+	assert.Empty(t, b.FailureReason)
+}
+
+func TestScrubber_Throttle(t *testing.T) {
+	ctx := context.Background()
+	content := make([]byte, 1000)
+	s, fake, bs, clock := newTestScrubber(ctx, t, ScrubberConfig{StoreKey: "store", BytesPerSecond: 500, SampleSize: 4})
+	for i := 0; i < 5; i++ {
+		addScrubTestBlob(ctx, t, fake, bs, content, content)
+	}
+
+	start := clock.now()
+	summary, err := s.ScrubOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.Verified, "only SampleSize blobs must be scrubbed")
+	// Reading 4000 bytes at 500 bytes per second takes 8 seconds.
+	assert.Equal(t, 8*time.Second, clock.now().Sub(start))
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second}, clock.sleeps)
+
+	// The cycle stops when ctx is canceled.
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	summary, err = s.ScrubOnce(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, summary.Verified)
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	log "github.com/sirupsen/logrus"
+	"gocloud.dev/gcerrors"
+	"google.golang.org/api/iterator"
+)
+
+// archiveManifestName is the name of the manifest entry written at the end of
+// the archives of ArchiveRecordBlobs.
+const archiveManifestName = "MANIFEST.json"
+
+// archiveEntry is a blob to be written to an archive. The content is either
+// inline, or the concatenation of the objects at paths in the BlobStore.
+type archiveEntry struct {
+	name    string
+	blobKey string
+	size    int64
+	inline  []byte
+	paths   []string
+}
+
+// archiveManifest is the content of the manifest entry of an archive.
+type archiveManifest struct {
+	StoreKey  string                  `json:"store_key"`
+	RecordKey string                  `json:"record_key"`
+	Blobs     []*archiveManifestEntry `json:"blobs"`
+}
+
+// archiveManifestEntry describes a blob in the archive. Missing is true if the
+// object of the blob, or of one of its chunks, was not found, in which case the
+// entry of the blob is absent from the archive or truncated.
+type archiveManifestEntry struct {
+	Name    string `json:"name"`
+	BlobKey string `json:"blob_key,omitempty"`
+	Size    int64  `json:"size"`
+	Missing bool   `json:"missing,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (s *openSavesServer) ArchiveRecordBlobs(req *pb.ArchiveRecordBlobsRequest, stream pb.OpenSaves_ArchiveRecordBlobsServer) error {
+	ctx := stream.Context()
+	r, blobs, err := s.metaDB.GetRecordWithBlobs(ctx, req.GetStoreKey(), req.GetRecordKey())
+	if err != nil {
+		log.Errorf("ArchiveRecordBlobs failed to get the record and blobs for store (%v), record (%v): %v",
+			req.GetStoreKey(), req.GetRecordKey(), err)
+		return err
+	}
+	entries, err := s.archiveEntries(ctx, r, blobs)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(&archiveStreamWriter{stream: stream}, streamBufferSize)
+	manifest := &archiveManifest{StoreKey: req.GetStoreKey(), RecordKey: req.GetRecordKey()}
+	if err := writeArchive(ctx, w, s.blobStore, manifest, entries); err != nil {
+		log.Errorf("ArchiveRecordBlobs failed for store (%v), record (%v): %v", req.GetStoreKey(), req.GetRecordKey(), err)
+		return err
+	}
+	return w.Flush()
+}
+
+// archiveEntries returns the entries of the inline blob of r and the Ready
+// blobs in blobs, with unique names.
+func (s *openSavesServer) archiveEntries(ctx context.Context, r *record.Record, blobs []*blobref.BlobRef) ([]*archiveEntry, error) {
+	var entries []*archiveEntry
+	names := make(map[string]bool)
+	if len(r.Blob) > 0 {
+		entries = append(entries, &archiveEntry{
+			name:   uniqueArchiveName(names, r.Key, "inline"),
+			size:   int64(len(r.Blob)),
+			inline: r.Blob,
+		})
+	}
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].Timestamps.CreatedAt.Before(blobs[j].Timestamps.CreatedAt)
+	})
+	for _, b := range blobs {
+		if b.Status != blobref.StatusReady {
+			continue
+		}
+		e := &archiveEntry{
+			name:    uniqueArchiveName(names, blobArchiveName(b), b.Key.String()),
+			blobKey: b.Key.String(),
+			size:    b.Size,
+		}
+		if b.Chunked {
+			paths, err := s.readyChunkPaths(ctx, b)
+			if err != nil {
+				return nil, err
+			}
+			e.paths = paths
+		} else {
+			e.paths = []string{b.ObjectPath()}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readyChunkPaths returns the object paths of the Ready chunks of b in order.
+func (s *openSavesServer) readyChunkPaths(ctx context.Context, b *blobref.BlobRef) ([]string, error) {
+	var chunks []*chunkref.ChunkRef
+	cur := s.metaDB.GetChildChunkRefs(ctx, b.Key)
+	for {
+		chunk, err := cur.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Errorf("ArchiveRecordBlobs failed to list the chunks of blob (%v): %v", b.Key, err)
+			return nil, err
+		}
+		if chunk.Status == blobref.StatusReady {
+			chunks = append(chunks, chunk)
+		}
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Number < chunks[j].Number })
+	paths := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		paths = append(paths, c.ObjectPath())
+	}
+	return paths, nil
+}
+
+// blobArchiveName returns the logical name of b: the filename of its
+// Content-Disposition, or the "filename" entry of its metadata.
+func blobArchiveName(b *blobref.BlobRef) string {
+	if _, params, err := mime.ParseMediaType(b.ContentDisposition); err == nil && params["filename"] != "" {
+		return params["filename"]
+	}
+	return b.Metadata["filename"]
+}
+
+// uniqueArchiveName returns the base name of name, or fallback if name is not
+// usable as a file name, prefixed with fallback if a previous entry has it.
+func uniqueArchiveName(used map[string]bool, name, fallback string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	switch name {
+	case ".", "..", "/", archiveManifestName:
+		name = ""
+	}
+	if name == "" {
+		name = fallback
+	}
+	if used[name] {
+		name = fallback + "-" + name
+	}
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d", fallback, i)
+	}
+	used[name] = true
+	return name
+}
+
+// writeArchive writes a zip archive of entries to w, ending with the manifest.
+// Entries whose objects are missing are recorded in the manifest. Other errors
+// abort the archive.
+func writeArchive(ctx context.Context, w io.Writer, bs blob.BlobStore, manifest *archiveManifest, entries []*archiveEntry) error {
+	zw := zip.NewWriter(w)
+	manifest.Blobs = []*archiveManifestEntry{}
+	for _, e := range entries {
+		m := &archiveManifestEntry{Name: e.name, BlobKey: e.blobKey, Size: e.size}
+		manifest.Blobs = append(manifest.Blobs, m)
+		if err := writeArchiveEntry(ctx, zw, bs, e, m); err != nil {
+			return err
+		}
+	}
+	mw, err := zw.Create(archiveManifestName)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeArchiveEntry writes e to zw, and marks m as missing if an object of e
+// doesn't exist. The entry is not created if the first object is missing.
+func writeArchiveEntry(ctx context.Context, zw *zip.Writer, bs blob.BlobStore, e *archiveEntry, m *archiveManifestEntry) error {
+	if e.paths == nil {
+		fw, err := zw.Create(e.name)
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(e.inline)
+		return err
+	}
+	var fw io.Writer
+	for i, p := range e.paths {
+		reader, err := bs.NewReader(ctx, p)
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			log.Warnf("ArchiveRecordBlobs: object (%v) of blob (%v) was not found", p, e.blobKey)
+			m.Missing = true
+			if i == 0 {
+				m.Error = fmt.Sprintf("object (%s) was not found", p)
+			} else {
+				m.Error = fmt.Sprintf("object (%s) was not found; the content is truncated", p)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if fw == nil {
+			if fw, err = zw.Create(e.name); err != nil {
+				reader.Close()
+				return err
+			}
+		}
+		_, err = io.Copy(fw, reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if fw == nil {
+		// A chunked blob without Ready chunks is an empty file.
+		_, err := zw.Create(e.name)
+		return err
+	}
+	return nil
+}
+
+// archiveStreamWriter sends the bytes written to it to the stream.
+type archiveStreamWriter struct {
+	stream pb.OpenSaves_ArchiveRecordBlobsServer
+}
+
+func (w *archiveStreamWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&pb.ArchiveRecordBlobsResponse{Content: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
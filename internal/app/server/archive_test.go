@@ -0,0 +1,156 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/memblob"
+)
+
+// readArchive returns the content of each entry of the zip archive and the
+// decoded manifest.
+func readArchive(t *testing.T, archive []byte) (map[string][]byte, *archiveManifest) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+		files[f.Name] = content
+	}
+	manifest := new(archiveManifest)
+	require.Contains(t, files, archiveManifestName)
+	require.NoError(t, json.Unmarshal(files[archiveManifestName], manifest))
+	delete(files, archiveManifestName)
+	return files, manifest
+}
+
+func TestArchive_WriteArchive(t *testing.T) {
+	ctx := context.Background()
+	bs, err := blob.NewBlobGCP(ctx, "mem://")
+	require.NoError(t, err)
+	t.Cleanup(func() { bs.Close() })
+	require.NoError(t, bs.Put(ctx, "external", []byte("external content")))
+	require.NoError(t, bs.Put(ctx, "chunk-0", []byte("first,")))
+	require.NoError(t, bs.Put(ctx, "chunk-1", []byte("second")))
+	require.NoError(t, bs.Put(ctx, "partial-0", []byte("partial")))
+
+	entries := []*archiveEntry{
+		{name: "inline.bin", size: 6, inline: []byte("inline")},
+		{name: "save.dat", blobKey: "external-key", size: 16, paths: []string{"external"}},
+		{name: "chunked.dat", blobKey: "chunked-key", size: 12, paths: []string{"chunk-0", "chunk-1"}},
+		{name: "missing.dat", blobKey: "missing-key", size: 10, paths: []string{"missing"}},
+		{name: "partial.dat", blobKey: "partial-key", size: 20, paths: []string{"partial-0", "partial-1"}},
+	}
+	buf := new(bytes.Buffer)
+	manifest := &archiveManifest{StoreKey: "store", RecordKey: "record"}
+	require.NoError(t, writeArchive(ctx, buf, bs, manifest, entries))
+
+	files, got := readArchive(t, buf.Bytes())
+	assert.Equal(t, map[string][]byte{
+		"inline.bin":  []byte("inline"),
+		"save.dat":    []byte("external content"),
+		"chunked.dat": []byte("first,second"),
+		"partial.dat": []byte("partial"),
+	}, files)
+	assert.Equal(t, "store", got.StoreKey)
+	assert.Equal(t, "record", got.RecordKey)
+	if assert.Len(t, got.Blobs, 5) {
+		for _, m := range got.Blobs[:3] {
+			assert.False(t, m.Missing, m.Name)
+		}
+		assert.Equal(t, "missing.dat", got.Blobs[3].Name)
+		assert.Equal(t, "missing-key", got.Blobs[3].BlobKey)
+		assert.True(t, got.Blobs[3].Missing)
+		assert.NotEmpty(t, got.Blobs[3].Error)
+		assert.True(t, got.Blobs[4].Missing, "a missing chunk truncates the entry")
+		assert.Contains(t, got.Blobs[4].Error, "truncated")
+	}
+}
+
+func TestArchive_EmptyArchive(t *testing.T) {
+	ctx := context.Background()
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeArchive(ctx, buf, nil, &archiveManifest{}, nil))
+	files, manifest := readArchive(t, buf.Bytes())
+	assert.Empty(t, files)
+	assert.Empty(t, manifest.Blobs)
+}
+
+func TestArchive_Names(t *testing.T) {
+	b := &blobref.BlobRef{ContentDisposition: `attachment; filename="save.dat"`}
+	assert.Equal(t, "save.dat", blobArchiveName(b))
+	b = &blobref.BlobRef{Metadata: map[string]string{"filename": "meta.json"}}
+	assert.Equal(t, "meta.json", blobArchiveName(b))
+	assert.Empty(t, blobArchiveName(&blobref.BlobRef{}))
+
+	used := make(map[string]bool)
+	assert.Equal(t, "save.dat", uniqueArchiveName(used, "dir/save.dat", "key1"))
+	assert.Equal(t, "key2-save.dat", uniqueArchiveName(used, "save.dat", "key2"))
+	assert.Equal(t, "key3", uniqueArchiveName(used, "..", "key3"))
+	assert.Equal(t, "key4", uniqueArchiveName(used, `..\..\`, "key4"))
+	assert.Equal(t, "key5", uniqueArchiveName(used, archiveManifestName, "key5"))
+	assert.Equal(t, "key6", uniqueArchiveName(used, "", "key6"))
+}
+
+func TestOpenSaves_ArchiveRecordBlobs(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	t.Cleanup(func() { cleanupBlobs(ctx, t, store.Key, record.Key) })
+	content := make([]byte, server.MaxInlineSize+1)
+	content[0] = 42
+	createBlob(ctx, t, client, store.Key, record.Key, content)
+
+	stream, err := client.ArchiveRecordBlobs(ctx, &pb.ArchiveRecordBlobsRequest{StoreKey: store.Key, RecordKey: record.Key})
+	require.NoError(t, err)
+	archive := new(bytes.Buffer)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		archive.Write(res.GetContent())
+	}
+
+	files, manifest := readArchive(t, archive.Bytes())
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{r.ExternalBlob.String(): content}, files)
+	if assert.Len(t, manifest.Blobs, 1) {
+		assert.Equal(t, r.ExternalBlob.String(), manifest.Blobs[0].BlobKey)
+		assert.False(t, manifest.Blobs[0].Missing)
+	}
+}
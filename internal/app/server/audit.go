@@ -0,0 +1,46 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	log "github.com/sirupsen/logrus"
+)
+
+// audit sends an audit entry of action on the record to the audit sink of s.
+// before and after are the record before and after the mutation, and either
+// may be nil. The write is best-effort: sink errors are logged and don't fail
+// the request, which has already been applied.
+func (s *openSavesServer) audit(ctx context.Context, storeKey, recordKey string, action metadb.AuditAction, before, after *record.Record) {
+	if s.auditSink == nil {
+		return
+	}
+	entry := metadb.NewAuditEntry(storeKey, recordKey, action, callerIdentity(ctx), before, after)
+	if err := s.auditSink.RecordAudit(ctx, entry); err != nil {
+		log.Errorf("Failed to write the audit entry of %v for store (%s), record (%s): %v",
+			action, storeKey, recordKey, err)
+	}
+}
+
+// auditSnapshot returns a copy of r to audit as the record before a mutation
+// that modifies the properties of r in place.
+func auditSnapshot(r *record.Record) record.Record {
+	c := *r
+	c.Properties = r.Properties.Clone()
+	return c
+}
@@ -0,0 +1,213 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditSink records the audit entries, and fails with err if set.
+type recordingAuditSink struct {
+	mu      sync.Mutex
+	entries []*metadb.AuditEntry
+	err     error
+}
+
+func (s *recordingAuditSink) RecordAudit(ctx context.Context, entry *metadb.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return s.err
+}
+
+func TestOpenSaves_AuditEntries(t *testing.T) {
+	ctx := context.Background()
+	impl, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	sink := new(recordingAuditSink)
+	impl.auditSink = sink
+
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	// The handlers are called directly with the identity that the
+	// Authenticator of the server would set.
+	ctx = withCallerIdentity(ctx, "player")
+	recordKey := uuid.NewString()
+	_, err := impl.CreateRecord(ctx, &pb.CreateRecordRequest{
+		StoreKey: store.Key,
+		Record: &pb.Record{
+			Key:        recordKey,
+			Properties: map[string]*pb.Property{"level": {Type: pb.Property_INTEGER, Value: &pb.Property_IntegerValue{IntegerValue: 1}}},
+		},
+	})
+	require.NoError(t, err)
+	_, err = impl.UpdateRecord(ctx, &pb.UpdateRecordRequest{
+		StoreKey: store.Key,
+		Record: &pb.Record{
+			Key:        recordKey,
+			Tags:       []string{"tag"},
+			Properties: map[string]*pb.Property{"level": {Type: pb.Property_INTEGER, Value: &pb.Property_IntegerValue{IntegerValue: 2}}},
+		},
+	})
+	require.NoError(t, err)
+	_, err = impl.DeleteRecord(ctx, &pb.DeleteRecordRequest{StoreKey: store.Key, Key: recordKey})
+	require.NoError(t, err)
+
+	require.Len(t, sink.entries, 3)
+	for i, action := range []metadb.AuditAction{metadb.AuditActionCreate, metadb.AuditActionUpdate, metadb.AuditActionDelete} {
+		e := sink.entries[i]
+		assert.Equal(t, action, e.Action)
+		assert.Equal(t, store.Key, e.StoreKey)
+		assert.Equal(t, recordKey, e.RecordKey)
+		assert.Equal(t, "player", e.Actor)
+		assert.False(t, e.Timestamp.IsZero())
+	}
+	assert.Equal(t, []metadb.AuditChange{
+		{Field: "Properties.level", New: "integer:1"},
+	}, sink.entries[0].Diff)
+	assert.Equal(t, []metadb.AuditChange{
+		{Field: "Properties.level", Old: "integer:1", New: "integer:2"},
+		{Field: "Tags", New: "tag"},
+	}, sink.entries[1].Diff)
+	// The delete entry has the record as it was before the deletion.
+	assert.Equal(t, []metadb.AuditChange{
+		{Field: "Properties.level", Old: "integer:2"},
+		{Field: "Tags", Old: "tag"},
+	}, sink.entries[2].Diff)
+
+	// Deleting a missing record is not audited.
+	_, err = impl.DeleteRecord(ctx, &pb.DeleteRecordRequest{StoreKey: store.Key, Key: recordKey})
+	require.NoError(t, err)
+	assert.Len(t, sink.entries, 3)
+}
+
+func TestOpenSaves_AuditSinkError(t *testing.T) {
+	ctx := context.Background()
+	impl, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	sink := &recordingAuditSink{err: errors.New("sink is down")}
+	impl.auditSink = sink
+
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+	got, err := client.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: store.Key, Key: record.Key})
+	require.NoError(t, err, "the record must be written even if the sink fails")
+	assert.Equal(t, record.Key, got.Key)
+	assert.Len(t, sink.entries, 1)
+}
+
+func TestOpenSaves_AuditAtomicUpdates(t *testing.T) {
+	ctx := context.Background()
+	impl, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	sink := new(recordingAuditSink)
+	impl.auditSink = sink
+
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{
+		Key: uuid.NewString(),
+		Properties: map[string]*pb.Property{
+			"level": {Type: pb.Property_INTEGER, Value: &pb.Property_IntegerValue{IntegerValue: 1}},
+			"name":  {Type: pb.Property_STRING, Value: &pb.Property_StringValue{StringValue: "hero"}},
+		},
+	})
+	intRequest := func(value int64) *pb.AtomicIntRequest {
+		return &pb.AtomicIntRequest{StoreKey: store.Key, RecordKey: record.Key, PropertyName: "level", Value: value}
+	}
+
+	_, err := client.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{
+		StoreKey: store.Key, RecordKey: record.Key, PropertyName: "name",
+		Value:    &pb.Property{Type: pb.Property_STRING, Value: &pb.Property_StringValue{StringValue: "villain"}},
+		OldValue: &pb.Property{Type: pb.Property_STRING, Value: &pb.Property_StringValue{StringValue: "hero"}},
+	})
+	require.NoError(t, err)
+	_, err = client.CompareAndSwapGreaterInt(ctx, intRequest(5))
+	require.NoError(t, err)
+	_, err = client.CompareAndSwapLessInt(ctx, intRequest(3))
+	require.NoError(t, err)
+	_, err = client.AtomicAddInt(ctx, intRequest(2))
+	require.NoError(t, err)
+	_, err = client.AtomicSubInt(ctx, intRequest(1))
+	require.NoError(t, err)
+	_, err = client.AtomicInc(ctx, &pb.AtomicIncRequest{StoreKey: store.Key, RecordKey: record.Key, PropertyName: "level", UpperBound: 10})
+	require.NoError(t, err)
+	_, err = client.AtomicDec(ctx, &pb.AtomicIncRequest{StoreKey: store.Key, RecordKey: record.Key, PropertyName: "level", UpperBound: 10})
+	require.NoError(t, err)
+
+	// The first entry is of setupTestRecord.
+	require.Len(t, sink.entries, 8)
+	for _, e := range sink.entries[1:] {
+		assert.Equal(t, metadb.AuditActionUpdate, e.Action)
+		assert.Equal(t, store.Key, e.StoreKey)
+		assert.Equal(t, record.Key, e.RecordKey)
+	}
+	var diffs [][]metadb.AuditChange
+	for _, e := range sink.entries[1:] {
+		diffs = append(diffs, e.Diff)
+	}
+	level := func(old, new string) []metadb.AuditChange {
+		return []metadb.AuditChange{{Field: "Properties.level", Old: "integer:" + old, New: "integer:" + new}}
+	}
+	assert.Equal(t, [][]metadb.AuditChange{
+		{{Field: "Properties.name", Old: `string:"hero"`, New: `string:"villain"`}},
+		level("1", "5"),
+		level("5", "3"),
+		level("3", "5"),
+		level("5", "4"),
+		level("4", "5"),
+		level("5", "4"),
+	}, diffs)
+
+	// Swaps that don't update the record are not audited.
+	_, err = client.CompareAndSwapGreaterInt(ctx, intRequest(0))
+	require.NoError(t, err)
+	assert.Len(t, sink.entries, 8)
+}
+
+func TestOpenSaves_AuditExternalizeBlob(t *testing.T) {
+	ctx := context.Background()
+	impl, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	sink := new(recordingAuditSink)
+	impl.auditSink = sink
+
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	createBlob(ctx, t, client, store.Key, record.Key, []byte("inline content"))
+	t.Cleanup(func() { cleanupBlobs(ctx, t, store.Key, record.Key) })
+
+	res, err := client.ExternalizeBlob(ctx, &pb.ExternalizeBlobRequest{
+		StoreKey: store.Key, RecordKey: record.Key, Name: "save.dat",
+	})
+	require.NoError(t, err)
+
+	e := sink.entries[len(sink.entries)-1]
+	assert.Equal(t, metadb.AuditActionUpdate, e.Action)
+	assert.Equal(t, record.Key, e.RecordKey)
+	assert.Equal(t, []metadb.AuditChange{{Field: "ExternalBlob", New: res.GetBlobKey()}}, e.Diff)
+}
@@ -0,0 +1,315 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/x509"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Action is the kind of access a request makes to a store.
+type Action string
+
+const (
+	// ActionRead is the access of requests that don't modify stores.
+	ActionRead Action = "read"
+	// ActionWrite is the access of requests that create, modify, or delete
+	// stores or their contents.
+	ActionWrite Action = "write"
+)
+
+// authorizedServicePrefix is the prefix of the full method names authorized
+// by authInterceptor. Other services, such as health checks, are not authorized.
+const authorizedServicePrefix = "/opensaves.OpenSaves/"
+
+// unscopedMethods is the set of the RPC methods that don't touch any store and
+// are not authorized.
+var unscopedMethods = map[string]bool{
+	"Ping": true,
+}
+
+// readMethods is the set of the RPC methods that only read stores.
+// All other methods are treated as ActionWrite.
+var readMethods = map[string]bool{
+	"GetStore":           true,
+	"ListStores":         true,
+	"GetRecord":          true,
+	"GetRecords":         true,
+	"QueryRecords":       true,
+	"GetBlob":            true,
+	"GetBlobChunk":       true,
+	"ArchiveRecordBlobs": true,
+	"CheckBlobExists":    true,
+	"Ping":               true,
+}
+
+// Authorizer decides whether the caller with identity may perform action on
+// the store with storeKey. It returns nil to allow the request, and any error
+// to reject it. identity is the one returned by the Authenticator, and is
+// empty if there is no Authenticator. Requests that are not scoped to a store,
+// such as ListStores and QueryRecords across stores, are rejected without
+// calling the Authorizer, and Ping is not authorized.
+type Authorizer func(ctx context.Context, identity, storeKey string, action Action) error
+
+// Authenticator returns the identity of the caller of the request with ctx,
+// verified with credentials of the connection or the request, such as a
+// client certificate. It returns an error to reject the request with
+// Unauthenticated.
+type Authenticator func(ctx context.Context) (string, error)
+
+// TLSClientIdentity is an Authenticator that returns the identity in the
+// verified TLS client certificate of the caller: the first URI subject
+// alternative name, such as a SPIFFE ID, or else the subject common name.
+// It rejects callers without a verified client certificate, so the server must
+// be started with TLS credentials that verify client certificates.
+func TLSClientIdentity(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "the peer of the request is unknown")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", status.Error(codes.Unauthenticated, "a verified client certificate is required")
+	}
+	if id := certificateIdentity(info.State.VerifiedChains[0][0]); id != "" {
+		return id, nil
+	}
+	return "", status.Error(codes.Unauthenticated, "the client certificate doesn't have an identity")
+}
+
+func certificateIdentity(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return cert.Subject.CommonName
+}
+
+// identityKey is the context key of the identity of the caller.
+type identityKey struct{}
+
+// withCallerIdentity returns a copy of ctx with the authenticated identity of
+// the caller.
+func withCallerIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// callerIdentity returns the identity of the caller authenticated by the
+// Authenticator of the server, or an empty string if there is none.
+func callerIdentity(ctx context.Context) string {
+	identity, _ := ctx.Value(identityKey{}).(string)
+	return identity
+}
+
+// sessionStoreFunc returns the key of the store that a chunked upload session
+// belongs to.
+type sessionStoreFunc func(ctx context.Context, sessionID string) (string, error)
+
+// authInterceptor authenticates the caller of every request, and authorizes
+// the request against the stores it touches before the handler runs.
+// Either authenticate or authorize may be nil to skip the step.
+type authInterceptor struct {
+	authenticate Authenticator
+	authorize    Authorizer
+	sessionStore sessionStoreFunc
+}
+
+// newAuthInterceptor returns an authInterceptor that consults authenticate and
+// authorize, and looks up the stores of chunked upload sessions in the
+// metadata of s.
+func newAuthInterceptor(authenticate Authenticator, authorize Authorizer, s *openSavesServer) *authInterceptor {
+	return &authInterceptor{
+		authenticate: authenticate,
+		authorize:    authorize,
+		sessionStore: func(ctx context.Context, sessionID string) (string, error) {
+			key, err := uuid.Parse(sessionID)
+			if err != nil {
+				return "", status.Errorf(codes.InvalidArgument, "SessionId is not a valid UUID string: %v", err)
+			}
+			blob, err := s.metaDB.GetBlobRef(ctx, key)
+			if err != nil {
+				return "", err
+			}
+			return blob.StoreKey, nil
+		},
+	}
+}
+
+// Unary returns the unary server interceptor.
+func (a *authInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, authorizedServicePrefix) {
+			return handler(ctx, req)
+		}
+		ctx, err := a.authenticated(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.check(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor. Streaming requests carry the
+// store in their first message, so the stream is authorized when the handler
+// receives it.
+func (a *authInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, authorizedServicePrefix) {
+			return handler(srv, ss)
+		}
+		ctx, err := a.authenticated(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx, interceptor: a, method: info.FullMethod})
+	}
+}
+
+// authenticated returns a copy of ctx with the identity of the caller, or an
+// Unauthenticated error if the Authenticator rejects the caller.
+func (a *authInterceptor) authenticated(ctx context.Context) (context.Context, error) {
+	if a.authenticate == nil {
+		return ctx, nil
+	}
+	identity, err := a.authenticate(ctx)
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Errorf(codes.Unauthenticated, "failed to authenticate the caller: %v", err)
+		}
+		return nil, err
+	}
+	return withCallerIdentity(ctx, identity), nil
+}
+
+// check authorizes req of method, returning a PermissionDenied error if the
+// Authorizer rejects it or req is not scoped to a store.
+func (a *authInterceptor) check(ctx context.Context, method string, req interface{}) error {
+	if a.authorize == nil || unscopedMethods[path.Base(method)] {
+		return nil
+	}
+	stores, err := a.storeKeys(ctx, req)
+	if err != nil {
+		return err
+	}
+	identity := callerIdentity(ctx)
+	action := methodAction(method)
+	for _, storeKey := range stores {
+		if storeKey == "" {
+			log.Warnf("%v: denied %v access for caller (%v) to a request that is not scoped to a store", method, action, identity)
+			return status.Errorf(codes.PermissionDenied, "%v access without a store key is denied", action)
+		}
+		if err := a.authorize(ctx, identity, storeKey, action); err != nil {
+			log.Warnf("%v: denied %v access to store (%v) for caller (%v): %v", method, action, storeKey, identity, err)
+			return status.Errorf(codes.PermissionDenied, "%v access to store (%v) is denied: %v", action, storeKey, err)
+		}
+	}
+	return nil
+}
+
+// storeKeys returns the keys of the stores that req touches.
+// It returns a single empty key for requests that are not scoped to a store.
+func (a *authInterceptor) storeKeys(ctx context.Context, req interface{}) ([]string, error) {
+	switch r := req.(type) {
+	case *pb.CreateStoreRequest:
+		return []string{r.GetStore().GetKey()}, nil
+	case *pb.GetStoreRequest:
+		return []string{r.GetKey()}, nil
+	case *pb.DeleteStoreRequest:
+		return []string{r.GetKey()}, nil
+	case *pb.GetRecordsRequest:
+		if len(r.GetStoreKeys()) == 0 {
+			return []string{""}, nil
+		}
+		return r.GetStoreKeys(), nil
+	case *pb.CreateBlobRequest:
+		if r.GetMetadata() == nil {
+			return nil, status.Error(codes.InvalidArgument, "The first message must be metadata.")
+		}
+		return []string{r.GetMetadata().GetStoreKey()}, nil
+	case *pb.UploadChunkRequest:
+		if r.GetMetadata() == nil {
+			return nil, status.Error(codes.InvalidArgument, "The first message must be metadata.")
+		}
+		return a.sessionStoreKeys(ctx, r.GetMetadata().GetSessionId())
+	case *pb.CommitChunkedUploadRequest:
+		return a.sessionStoreKeys(ctx, r.GetSessionId())
+	case *pb.AbortChunkedUploadRequest:
+		return a.sessionStoreKeys(ctx, r.GetSessionId())
+	case interface{ GetStoreKey() string }:
+		return []string{r.GetStoreKey()}, nil
+	}
+	return []string{""}, nil
+}
+
+func (a *authInterceptor) sessionStoreKeys(ctx context.Context, sessionID string) ([]string, error) {
+	storeKey, err := a.sessionStore(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return []string{storeKey}, nil
+}
+
+// methodAction returns the Action of the full RPC method name, e.g.
+// /opensaves.OpenSaves/GetRecord.
+func methodAction(fullMethod string) Action {
+	if readMethods[path.Base(fullMethod)] {
+		return ActionRead
+	}
+	return ActionWrite
+}
+
+// authServerStream authorizes the first message received from the client, and
+// has the identity of the caller in its context.
+// Once the stream is rejected, all subsequent receives fail with the same error.
+type authServerStream struct {
+	grpc.ServerStream
+	ctx         context.Context
+	interceptor *authInterceptor
+	method      string
+	checked     bool
+	err         error
+}
+
+func (s *authServerStream) RecvMsg(m interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.checked {
+		return nil
+	}
+	s.checked = true
+	s.err = s.interceptor.check(s.Context(), s.method, m)
+	return s.err
+}
+
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
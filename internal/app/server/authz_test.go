@@ -0,0 +1,285 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"net/url"
+	"sync"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// authTestServer is an OpenSavesServer that counts the handler calls.
+type authTestServer struct {
+	pb.UnimplementedOpenSavesServer
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *authTestServer) called() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+}
+
+func (s *authTestServer) CreateRecord(ctx context.Context, req *pb.CreateRecordRequest) (*pb.Record, error) {
+	s.called()
+	return req.GetRecord(), nil
+}
+
+func (s *authTestServer) DeleteStore(ctx context.Context, req *pb.DeleteStoreRequest) (*empty.Empty, error) {
+	s.called()
+	return new(empty.Empty), nil
+}
+
+func (s *authTestServer) CommitChunkedUpload(ctx context.Context, req *pb.CommitChunkedUploadRequest) (*pb.BlobMetadata, error) {
+	s.called()
+	return new(pb.BlobMetadata), nil
+}
+
+func (s *authTestServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	s.called()
+	return &pb.PingResponse{Pong: req.GetPing()}, nil
+}
+
+func (s *authTestServer) GetBlob(req *pb.GetBlobRequest, stream pb.OpenSaves_GetBlobServer) error {
+	s.called()
+	return stream.Send(&pb.GetBlobResponse{Response: &pb.GetBlobResponse_Content{Content: []byte("content")}})
+}
+
+func (s *authTestServer) CreateBlob(stream pb.OpenSaves_CreateBlobServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	s.called()
+	return stream.SendAndClose(req.GetMetadata())
+}
+
+// authCall is a call to the test Authorizer.
+type authCall struct {
+	identity, storeKey string
+	action             Action
+}
+
+// recordingAuthorizer records the calls and rejects the stores in deny.
+type recordingAuthorizer struct {
+	mu    sync.Mutex
+	calls []authCall
+	deny  map[string]bool
+}
+
+func (a *recordingAuthorizer) authorize(ctx context.Context, identity, storeKey string, action Action) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, authCall{identity, storeKey, action})
+	if a.deny[storeKey] {
+		return errors.New("not the owner")
+	}
+	return nil
+}
+
+// testIdentityKey is the metadata key of the identity that testAuthenticator
+// trusts. Real Authenticators verify the identity instead.
+const testIdentityKey = "x-test-identity"
+
+// testAuthenticator returns the identity in the testIdentityKey metadata, and
+// rejects callers without one.
+func testAuthenticator(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(testIdentityKey); len(v) > 0 {
+		return v[0], nil
+	}
+	return "", errors.New("no identity")
+}
+
+func getAuthTestClient(ctx context.Context, t *testing.T, authenticate Authenticator, authorizer *recordingAuthorizer) (pb.OpenSavesClient, *authTestServer) {
+	t.Helper()
+	auth := &authInterceptor{
+		authenticate: authenticate,
+		authorize:    authorizer.authorize,
+		sessionStore: func(ctx context.Context, sessionID string) (string, error) {
+			return "session-store", nil
+		},
+	}
+	impl := new(authTestServer)
+	server := grpc.NewServer(grpc.UnaryInterceptor(auth.Unary()), grpc.StreamInterceptor(auth.Stream()))
+	pb.RegisterOpenSavesServer(server, impl)
+	listener := bufconn.Listen(testBufferSize)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	_, client := getTestClient(ctx, t, listener)
+	return client, impl
+}
+
+// authTestCalls makes RPCs of several types to store, and returns the errors.
+func authTestCalls(ctx context.Context, t *testing.T, client pb.OpenSavesClient, store string) map[string]error {
+	t.Helper()
+	errs := make(map[string]error)
+	_, errs["CreateRecord"] = client.CreateRecord(ctx, &pb.CreateRecordRequest{StoreKey: store, Record: &pb.Record{Key: "record"}})
+	_, errs["DeleteStore"] = client.DeleteStore(ctx, &pb.DeleteStoreRequest{Key: store})
+
+	gbc, err := client.GetBlob(ctx, &pb.GetBlobRequest{StoreKey: store, RecordKey: "record"})
+	require.NoError(t, err)
+	for {
+		if _, err = gbc.Recv(); err != nil {
+			break
+		}
+	}
+	if err != io.EOF {
+		errs["GetBlob"] = err
+	} else {
+		errs["GetBlob"] = nil
+	}
+
+	cbc, err := client.CreateBlob(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cbc.Send(&pb.CreateBlobRequest{Request: &pb.CreateBlobRequest_Metadata{
+		Metadata: &pb.BlobMetadata{StoreKey: store, RecordKey: "record"},
+	}}))
+	_, errs["CreateBlob"] = cbc.CloseAndRecv()
+	return errs
+}
+
+func TestAuthInterceptor_Permissive(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), testIdentityKey, "player")
+	authorizer := new(recordingAuthorizer)
+	client, server := getAuthTestClient(ctx, t, testAuthenticator, authorizer)
+
+	for method, err := range authTestCalls(ctx, t, client, "store") {
+		assert.NoError(t, err, method)
+	}
+	_, err := client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: "session"})
+	assert.NoError(t, err)
+	_, err = client.Ping(ctx, &pb.PingRequest{Ping: "ping"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 6, server.calls)
+	assert.ElementsMatch(t, []authCall{
+		{"player", "store", ActionWrite},
+		{"player", "store", ActionWrite},
+		{"player", "store", ActionRead},
+		{"player", "store", ActionWrite},
+		{"player", "session-store", ActionWrite},
+	}, authorizer.calls, "Ping must not be authorized")
+}
+
+func TestAuthInterceptor_Denying(t *testing.T) {
+	ctx := context.Background()
+	authorizer := &recordingAuthorizer{deny: map[string]bool{"forbidden": true, "session-store": true}}
+	client, server := getAuthTestClient(ctx, t, nil, authorizer)
+
+	for method, err := range authTestCalls(ctx, t, client, "forbidden") {
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), method)
+	}
+	_, err := client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: "session"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Zero(t, server.calls, "handlers must not run for denied requests")
+
+	// Other stores are still allowed.
+	for method, err := range authTestCalls(ctx, t, client, "allowed") {
+		assert.NoError(t, err, method)
+	}
+	assert.Equal(t, 4, server.calls)
+	for _, call := range authorizer.calls {
+		assert.Empty(t, call.identity, "no identity was sent")
+	}
+}
+
+func TestAuthInterceptor_Unscoped(t *testing.T) {
+	ctx := context.Background()
+	authorizer := new(recordingAuthorizer)
+	client, server := getAuthTestClient(ctx, t, nil, authorizer)
+
+	_, err := client.ListStores(ctx, new(pb.ListStoresRequest))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "ListStores")
+	_, err = client.QueryRecords(ctx, new(pb.QueryRecordsRequest))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "QueryRecords")
+	_, err = client.GetRecords(ctx, new(pb.GetRecordsRequest))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "GetRecords")
+	for method, err := range authTestCalls(ctx, t, client, "") {
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), method)
+	}
+	assert.Zero(t, server.calls, "handlers must not run for unscoped requests")
+	assert.Empty(t, authorizer.calls, "unscoped requests must be rejected without the Authorizer")
+}
+
+func TestAuthInterceptor_Unauthenticated(t *testing.T) {
+	authorizer := new(recordingAuthorizer)
+	client, server := getAuthTestClient(context.Background(), t, testAuthenticator, authorizer)
+
+	// The identity of the caller can't be set with other metadata.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-open-saves-identity", "player")
+	for method, err := range authTestCalls(ctx, t, client, "store") {
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), method)
+	}
+	_, err := client.Ping(ctx, &pb.PingRequest{Ping: "ping"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Zero(t, server.calls, "handlers must not run for unauthenticated requests")
+	assert.Empty(t, authorizer.calls)
+}
+
+func TestTLSClientIdentity(t *testing.T) {
+	withCert := func(cert *x509.Certificate) context.Context {
+		state := tls.ConnectionState{}
+		if cert != nil {
+			state.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+	spiffe, err := url.Parse("spiffe://example.org/player")
+	require.NoError(t, err)
+
+	id, err := TLSClientIdentity(withCert(&x509.Certificate{Subject: pkix.Name{CommonName: "player"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, "player", id)
+	id, err = TLSClientIdentity(withCert(&x509.Certificate{Subject: pkix.Name{CommonName: "player"}, URIs: []*url.URL{spiffe}}))
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/player", id)
+
+	for name, ctx := range map[string]context.Context{
+		"no peer":                context.Background(),
+		"insecure":               peer.NewContext(context.Background(), &peer.Peer{}),
+		"unverified":             withCert(nil),
+		"certificate without ID": withCert(new(x509.Certificate)),
+	} {
+		_, err := TLSClientIdentity(ctx)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), name)
+	}
+}
+
+func TestMethodAction(t *testing.T) {
+	assert.Equal(t, ActionRead, methodAction("/opensaves.OpenSaves/GetRecord"))
+	assert.Equal(t, ActionRead, methodAction("/opensaves.OpenSaves/QueryRecords"))
+	assert.Equal(t, ActionWrite, methodAction("/opensaves.OpenSaves/UpdateRecord"))
+	assert.Equal(t, ActionWrite, methodAction("/opensaves.OpenSaves/CreateBlob"))
+}
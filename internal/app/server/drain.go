@@ -0,0 +1,223 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/googleforgames/open-saves/api"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errDraining = status.Error(codes.Unavailable, "the server is shutting down and doesn't accept new uploads")
+
+// defaultDrainCancelWait is the time that drain waits for canceled uploads to
+// exit if uploadTracker.cancelWait is not positive.
+const defaultDrainCancelWait = 5 * time.Second
+
+// uploadTracker keeps track of in-flight uploads so that they can be drained
+// on shutdown. The zero value is ready to use.
+type uploadTracker struct {
+	mu       sync.Mutex
+	draining bool
+	nextID   uint64
+	inflight map[uint64]*upload
+	// idle is closed when there are no in-flight uploads while draining.
+	idle chan struct{}
+	// cancelWait is the time that drain waits for canceled uploads to exit.
+	cancelWait time.Duration
+}
+
+// upload is a handle of an in-flight upload.
+type upload struct {
+	tracker *uploadTracker
+	id      uint64
+
+	mu     sync.Mutex
+	cancel func()
+}
+
+// begin registers a new in-flight upload. It returns errDraining if the
+// tracker is draining. The caller must call done when the upload exits.
+func (t *uploadTracker) begin() (*upload, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return nil, errDraining
+	}
+	if t.inflight == nil {
+		t.inflight = make(map[uint64]*upload)
+	}
+	t.nextID++
+	u := &upload{tracker: t, id: t.nextID}
+	t.inflight[u.id] = u
+	return u, nil
+}
+
+// onCancel sets a callback to abort the upload when the drain times out,
+// typically the cancel function of the context of the upload stream, which
+// also unblocks a pending Recv (see recvContext). The upload must then exit and
+// mark its blob as failed itself, so that the blob is never marked while the
+// upload may still promote it.
+func (u *upload) onCancel(fn func()) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.cancel = fn
+}
+
+// done unregisters the upload.
+func (u *upload) done() {
+	t := u.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.inflight, u.id)
+	if t.draining && len(t.inflight) == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// drain stops accepting new uploads and waits until in-flight uploads finish or
+// ctx is done. Uploads remaining after ctx is done are canceled, and drain waits
+// up to cancelWait for them to exit before it returns ctx.Err().
+func (t *uploadTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	if len(t.inflight) == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	remaining := make([]*upload, 0, len(t.inflight))
+	for _, u := range t.inflight {
+		remaining = append(remaining, u)
+	}
+	t.mu.Unlock()
+
+	log.Warnf("Drain timed out, canceling %d in-flight upload(s)", len(remaining))
+	for _, u := range remaining {
+		u.mu.Lock()
+		cancel := u.cancel
+		u.mu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+	}
+	wait := t.cancelWait
+	if wait <= 0 {
+		wait = defaultDrainCancelWait
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-idle:
+	case <-timer.C:
+		t.mu.Lock()
+		n := len(t.inflight)
+		t.mu.Unlock()
+		log.Warnf("%d canceled upload(s) haven't exited", n)
+	}
+	return ctx.Err()
+}
+
+// Drain stops accepting new blob uploads and waits for in-flight uploads to finish
+// until ctx is done. Uploads that don't finish in time are canceled and mark their
+// blobs as failed when they exit so that the garbage collector can clean them up,
+// and ctx.Err() is returned.
+// Pending LastAccessedAt updates of blobs are written before Drain returns.
+func (s *openSavesServer) Drain(ctx context.Context) error {
+	err := s.uploads.drain(ctx)
+	if s.touches != nil {
+		if terr := s.touches.Close(ctx); terr != nil {
+			log.Warnf("Failed to write pending blob touches: %v", terr)
+			if err == nil {
+				err = terr
+			}
+		}
+	}
+	return err
+}
+
+// recvContext calls recv and returns its result, or a Canceled or
+// DeadlineExceeded error as soon as ctx is done.
+// The Recv of a gRPC server stream only returns when a message arrives or the
+// stream is canceled, and the handler can't cancel its own stream, so a canceled
+// upload would otherwise block until the client sends the next message.
+// recv keeps running after ctx is done until gRPC cancels the stream when the
+// handler returns, so the caller must not call recv again.
+func recvContext[T any](ctx context.Context, recv func() (T, error)) (T, error) {
+	type result struct {
+		msg T
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		msg, err := recv()
+		ch <- result{msg, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.msg, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// cancelableCreateBlobStream is a CreateBlob stream whose Context is ctx and
+// whose Recv returns when ctx is canceled.
+type cancelableCreateBlobStream struct {
+	pb.OpenSaves_CreateBlobServer
+	ctx context.Context
+}
+
+func (s *cancelableCreateBlobStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *cancelableCreateBlobStream) Recv() (*pb.CreateBlobRequest, error) {
+	return recvContext(s.ctx, s.OpenSaves_CreateBlobServer.Recv)
+}
+
+// cancelableUploadChunkStream is an UploadChunk stream whose Context is ctx and
+// whose Recv returns when ctx is canceled.
+type cancelableUploadChunkStream struct {
+	pb.OpenSaves_UploadChunkServer
+	ctx context.Context
+}
+
+func (s *cancelableUploadChunkStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *cancelableUploadChunkStream) Recv() (*pb.UploadChunkRequest, error) {
+	return recvContext(s.ctx, s.OpenSaves_UploadChunkServer.Recv)
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDrain_NoUploads(t *testing.T) {
+	t.Parallel()
+
+	s := new(openSavesServer)
+	assert.NoError(t, s.Drain(context.Background()))
+
+	// New blob RPCs should be rejected after Drain.
+	assert.Equal(t, codes.Unavailable, status.Code(s.CreateBlob(nil)))
+	assert.Equal(t, codes.Unavailable, status.Code(s.UploadChunk(nil)))
+}
+
+func TestDrain_InFlightUploadCompletes(t *testing.T) {
+	t.Parallel()
+
+	s := new(openSavesServer)
+	upload, err := s.uploads.begin()
+	require.NoError(t, err)
+	var canceled atomic.Bool
+	upload.onCancel(func() { canceled.Store(true) })
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		upload.done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.NoError(t, s.Drain(ctx))
+	assert.False(t, canceled.Load())
+}
+
+func TestDrain_InFlightUploadTimesOut(t *testing.T) {
+	t.Parallel()
+
+	s := new(openSavesServer)
+	upload, err := s.uploads.begin()
+	require.NoError(t, err)
+	exited := make(chan struct{})
+	upload.onCancel(func() {
+		// The upload exits some time after it is canceled.
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			upload.done()
+			close(exited)
+		}()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Drain(ctx), context.DeadlineExceeded)
+	select {
+	case <-exited:
+	default:
+		t.Error("Drain returned before the canceled upload exited")
+	}
+
+	_, err = s.uploads.begin()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestDrain_CanceledUploadDoesNotExit(t *testing.T) {
+	t.Parallel()
+
+	s := new(openSavesServer)
+	s.uploads.cancelWait = 50 * time.Millisecond
+	upload, err := s.uploads.begin()
+	require.NoError(t, err)
+	defer upload.done()
+	var canceled atomic.Bool
+	upload.onCancel(func() { canceled.Store(true) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Drain(ctx), context.DeadlineExceeded)
+	assert.True(t, canceled.Load())
+}
+
+func TestDrain_CanceledUploadUnblocksRecv(t *testing.T) {
+	t.Parallel()
+
+	s := new(openSavesServer)
+	s.uploads.cancelWait = 10 * time.Second
+	upload, err := s.uploads.begin()
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	upload.onCancel(cancel)
+
+	// recv blocks like the Recv of a stream whose client doesn't send anything.
+	block := make(chan struct{})
+	defer close(block)
+	recv := func() (*pb.CreateBlobRequest, error) {
+		<-block
+		return nil, nil
+	}
+	recvErr := make(chan error, 1)
+	go func() {
+		defer upload.done()
+		_, err := recvContext(ctx, recv)
+		recvErr <- err
+	}()
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer drainCancel()
+	assert.ErrorIs(t, s.Drain(drainCtx), context.DeadlineExceeded)
+	select {
+	case err := <-recvErr:
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	default:
+		t.Error("Drain returned before the pending Recv was unblocked")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// recordKeyField is always kept by applyRecordFieldMask.
+const recordKeyField = "key"
+
+// recordFieldToProperty maps Record proto field names to Datastore property names
+// for fields that can be read with a projection query.
+var recordFieldToProperty = map[string]string{
+	"blob_size":   "BlobSize",
+	"chunked":     "Chunked",
+	"chunk_count": "ChunkCount",
+	"owner_id":    "OwnerID",
+	"tags":        "Tags",
+}
+
+// validateRecordFieldMask returns an InvalidArgument error if mask contains
+// a path that is not a field of Record.
+func validateRecordFieldMask(mask *fieldmaskpb.FieldMask) error {
+	if !mask.IsValid(&pb.Record{}) {
+		return status.Errorf(codes.InvalidArgument, "field mask has unknown Record fields: %v", mask.GetPaths())
+	}
+	return nil
+}
+
+// projectionProperty returns the Datastore property to project if mask selects a single
+// projectable field other than key. Otherwise it returns false.
+func projectionProperty(mask *fieldmaskpb.FieldMask) (string, bool) {
+	property := ""
+	for _, p := range mask.GetPaths() {
+		if p == recordKeyField {
+			continue
+		}
+		prop, ok := recordFieldToProperty[p]
+		if !ok || (property != "" && property != prop) {
+			return "", false
+		}
+		property = prop
+	}
+	return property, property != "" && metadb.IsProjectableRecordProperty(property)
+}
+
+// applyRecordFieldMask clears fields of p that are not in mask except key.
+func applyRecordFieldMask(p *pb.Record, mask *fieldmaskpb.FieldMask) {
+	keep := map[protoreflect.Name]bool{recordKeyField: true}
+	for _, path := range mask.GetPaths() {
+		keep[protoreflect.Name(path)] = true
+	}
+	m := p.ProtoReflect()
+	var cleared []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !keep[fd.Name()] {
+			cleared = append(cleared, fd)
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
+
+// getRecordWithFieldMask returns a record with only the fields specified by the field mask
+// of req. A projection query is used if the field mask selects a single indexed field
+// and the record is not cached, otherwise the full record is read and masked.
+func (s *openSavesServer) getRecordWithFieldMask(ctx context.Context, req *pb.GetRecordRequest) (*pb.Record, error) {
+	mask := req.GetFieldMask()
+	if err := validateRecordFieldMask(mask); err != nil {
+		return nil, err
+	}
+
+	var r *record.Record
+	if property, ok := projectionProperty(mask); ok {
+		cached := new(record.Record)
+		if shouldCheckCache(req.GetHint()) &&
+			s.cacheStore.Get(ctx, record.CacheKey(req.GetStoreKey(), req.GetKey()), cached) == nil {
+			log.Debug("cache hit")
+			if cached.IsExpired(time.Now()) {
+				return nil, metadb.ErrRecordExpired
+			}
+			r = cached
+		} else {
+			var err error
+			r, err = s.metaDB.GetRecordProjection(ctx, req.GetStoreKey(), req.GetKey(), property)
+			if err != nil {
+				log.Warnf("GetRecordProjection failed for store (%s), record (%s): %v",
+					req.GetStoreKey(), req.GetKey(), err)
+				return nil, status.Convert(err).Err()
+			}
+		}
+	} else {
+		var err error
+		r, err = s.getRecordAndCache(ctx, req.GetStoreKey(), req.GetKey(), req.GetHint())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p := r.ToProto()
+	applyRecordFieldMask(p, mask)
+	return p, nil
+}
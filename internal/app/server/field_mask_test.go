@@ -0,0 +1,82 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestFieldMask_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateRecordFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"tags", "owner_id"}}))
+	err := validateRecordFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"tags", "no_such_field"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestFieldMask_ProjectionProperty(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		paths []string
+		want  string
+		ok    bool
+	}{
+		{"tags", []string{"tags"}, "Tags", true},
+		{"tags with key", []string{"key", "tags"}, "Tags", true},
+		{"multiple fields", []string{"tags", "owner_id"}, "", false},
+		{"not indexed", []string{"opaque_string"}, "", false},
+		{"key only", []string{"key"}, "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := projectionProperty(&fieldmaskpb.FieldMask{Paths: tc.paths})
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestFieldMask_ApplyRecordFieldMask(t *testing.T) {
+	t.Parallel()
+
+	p := &pb.Record{
+		Key:          "key",
+		BlobSize:     123,
+		OwnerId:      "owner",
+		Tags:         []string{"a", "b"},
+		OpaqueString: "opaque",
+		Properties: map[string]*pb.Property{
+			"prop": {Type: pb.Property_INTEGER, Value: &pb.Property_IntegerValue{IntegerValue: 1}},
+		},
+	}
+	applyRecordFieldMask(p, &fieldmaskpb.FieldMask{Paths: []string{"tags"}})
+	want := &pb.Record{
+		Key:  "key",
+		Tags: []string{"a", "b"},
+	}
+	if diff := cmp.Diff(want, p, protocmp.Transform()); diff != "" {
+		t.Errorf("applyRecordFieldMask() = (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createBlobWithToken uploads content to the record with the idempotency token.
+func createBlobWithToken(ctx context.Context, t *testing.T, client pb.OpenSavesClient,
+	storeKey, recordKey, token string, content []byte) (*pb.BlobMetadata, error) {
+	t.Helper()
+	cbc, err := client.CreateBlob(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+		Request: &pb.CreateBlobRequest_Metadata{Metadata: &pb.BlobMetadata{
+			StoreKey:         storeKey,
+			RecordKey:        recordKey,
+			Size:             int64(len(content)),
+			IdempotencyToken: token,
+		}},
+	}))
+	// The server reads all content of a retry before it responds.
+	half := len(content) / 2
+	for _, c := range [][]byte{content[:half], content[half:]} {
+		require.NoError(t, cbc.Send(&pb.CreateBlobRequest{Request: &pb.CreateBlobRequest_Content{Content: c}}))
+	}
+	return cbc.CloseAndRecv()
+}
+
+func TestOpenSaves_CreateBlobIdempotencyToken(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.IdempotencyWindow = time.Hour
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	t.Cleanup(func() { cleanupBlobs(ctx, t, store.Key, record.Key) })
+	first := make([]byte, server.MaxInlineSize+1)
+	first[0] = 1
+	token := uuid.NewString()
+
+	meta, err := createBlobWithToken(ctx, t, client, store.Key, record.Key, token, first)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(first)), meta.GetSize())
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	firstBlob := r.ExternalBlob
+
+	// The retry returns the first blob and doesn't replace its content.
+	retry := make([]byte, server.MaxInlineSize+1)
+	meta, err = createBlobWithToken(ctx, t, client, store.Key, record.Key, token, retry)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(first)), meta.GetSize())
+	assert.Equal(t, store.Key, meta.GetStoreKey())
+	r, err = server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	assert.Equal(t, firstBlob, r.ExternalBlob)
+	verifyBlob(ctx, t, client, store.Key, record.Key, first)
+
+	// A different token starts a new upload.
+	_, err = createBlobWithToken(ctx, t, client, store.Key, record.Key, uuid.NewString(), retry)
+	require.NoError(t, err)
+	r, err = server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstBlob, r.ExternalBlob)
+	verifyBlob(ctx, t, client, store.Key, record.Key, retry)
+}
+
+func TestOpenSaves_CreateBlobExpiredIdempotencyToken(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	const window = 100 * time.Millisecond
+	server.BlobConfig.IdempotencyWindow = window
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	t.Cleanup(func() { cleanupBlobs(ctx, t, store.Key, record.Key) })
+	content := make([]byte, server.MaxInlineSize+1)
+	token := uuid.NewString()
+
+	_, err := createBlobWithToken(ctx, t, client, store.Key, record.Key, token, content)
+	require.NoError(t, err)
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	firstBlob := r.ExternalBlob
+	time.Sleep(2 * window)
+
+	content[0] = 1
+	_, err = createBlobWithToken(ctx, t, client, store.Key, record.Key, token, content)
+	require.NoError(t, err)
+	r, err = server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstBlob, r.ExternalBlob, "an expired token must start a new upload")
+	verifyBlob(ctx, t, client, store.Key, record.Key, content)
+}
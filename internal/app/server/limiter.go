@@ -0,0 +1,67 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// byteLimiter bounds the total declared size of in-flight uploads to avoid
+// running out of memory. A nil *byteLimiter doesn't limit anything.
+type byteLimiter struct {
+	limit int64
+
+	mu    sync.Mutex
+	inUse int64
+}
+
+// newByteLimiter returns a byteLimiter that allows up to limit bytes in flight,
+// or nil if limit is not positive.
+func newByteLimiter(limit int64) *byteLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &byteLimiter{limit: limit}
+}
+
+// acquire reserves n bytes and returns a function to release them, which must
+// be called when the upload finishes or fails. It returns ResourceExhausted
+// without waiting if reserving n bytes exceeds the limit.
+func (l *byteLimiter) acquire(n int64) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if n < 0 {
+		n = 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inUse+n > l.limit {
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"too many uploads in flight: requested %d bytes, %d of %d bytes in use", n, l.inUse, l.limit)
+	}
+	l.inUse += n
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.inUse -= n
+		})
+	}, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestByteLimiter_RejectsOverBudget(t *testing.T) {
+	l := newByteLimiter(100)
+	release, err := l.acquire(60)
+	require.NoError(t, err)
+	defer release()
+
+	_, err = l.acquire(41)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = l.acquire(101)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	r, err := l.acquire(40)
+	require.NoError(t, err)
+	r()
+}
+
+func TestByteLimiter_ReleaseFreesCapacity(t *testing.T) {
+	l := newByteLimiter(100)
+	release, err := l.acquire(100)
+	require.NoError(t, err)
+	_, err = l.acquire(1)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	release()
+	// Releasing twice must not free more than acquired.
+	release()
+	r, err := l.acquire(100)
+	require.NoError(t, err)
+	_, err = l.acquire(1)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	r()
+}
+
+func TestByteLimiter_Unlimited(t *testing.T) {
+	l := newByteLimiter(0)
+	assert.Nil(t, l)
+	release, err := l.acquire(1 << 40)
+	require.NoError(t, err)
+	release()
+}
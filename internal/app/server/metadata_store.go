@@ -0,0 +1,67 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+)
+
+// metadataStore is the metadata store that the server depends on: the
+// operations of metadb.MetadataStore and the ones beyond it that the RPCs use.
+// *metadb.MetaDB is the only implementation.
+type metadataStore interface {
+	metadb.MetadataStore
+
+	FindStoreByName(ctx context.Context, name string) (*store.Store, error)
+
+	GetRecords(ctx context.Context, storeKeys, recordKeys []string) ([]*record.Record, error)
+	GetRecordProjection(ctx context.Context, storeKey, key, property string) (*record.Record, error)
+	GetRecordWithBlobs(ctx context.Context, storeKey, recordKey string) (*record.Record, []*blobref.BlobRef, error)
+	GetAndDeleteRecord(ctx context.Context, storeKey, key string) (*record.Record, error)
+	QueryRecords(ctx context.Context, req *pb.QueryRecordsRequest) ([]*record.Record, error)
+
+	NewBlobRef(size int64, storeKey, recordKey string) *blobref.BlobRef
+	NewChunkedBlobRef(storeKey, recordKey string, chunkCount int64) *blobref.BlobRef
+	InsertBlobRefWithToken(ctx context.Context, blob *blobref.BlobRef, token string, window time.Duration) (*blobref.BlobRef, bool, error)
+	InsertExternalizedBlobRef(ctx context.Context, storeKey, recordKey string, key uuid.UUID) (*record.Record, *blobref.BlobRef, error)
+	GetCurrentBlobRef(ctx context.Context, storeKey, recordKey string) (*blobref.BlobRef, error)
+	FindBlobRefByChecksum(ctx context.Context, storeKey string, md5 []byte, size int64, consistency metadb.Consistency) (*blobref.BlobRef, error)
+	FindBlobRefByContentHash(ctx context.Context, storeKey string, algorithm checksums.HashAlgorithm,
+		hash []byte, size int64, consistency metadb.Consistency) (*blobref.BlobRef, error)
+	PromoteBlobRefToCurrent(ctx context.Context, blob *blobref.BlobRef) (*record.Record, *blobref.BlobRef, error)
+	PromoteBlobRefWithRecordUpdater(ctx context.Context, blob *blobref.BlobRef, updateTo *record.Record, updater metadb.RecordUpdater) (*record.Record, *blobref.BlobRef, error)
+	RemoveBlobFromRecord(ctx context.Context, storeKey string, recordKey string) (*record.Record, *blobref.BlobRef, error)
+	MarkUncommittedBlobForDeletion(ctx context.Context, key uuid.UUID) error
+	DeleteBlobAs(ctx context.Context, bs blob.BlobStore, blobKey uuid.UUID, ownerID string) error
+
+	InsertChunkRef(ctx context.Context, blob *blobref.BlobRef, chunk *chunkref.ChunkRef) error
+	ValidateChunkRefPreconditions(ctx context.Context, chunk *chunkref.ChunkRef) (*blobref.BlobRef, error)
+	FindChunkRefByNumber(ctx context.Context, storeKey, recordKey string, number int32) (*chunkref.ChunkRef, error)
+	GetChildChunkRefs(ctx context.Context, blobKey uuid.UUID) *chunkref.ChunkRefCursor
+}
+
+// Assert MetaDB implements the metadataStore interface.
+var _ metadataStore = new(metadb.MetaDB)
@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"cloud.google.com/go/datastore"
 	"context"
+	"crypto/md5"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	pb "github.com/googleforgames/open-saves/api"
@@ -31,12 +33,18 @@ import (
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/googleforgames/open-saves/internal/pkg/metrics"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/metric/global"
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 	"io"
+	"time"
 )
 
 // TODO(hongalex): make this a configurable field for users.
@@ -49,10 +57,27 @@ const (
 type openSavesServer struct {
 	cloud      string
 	blobStore  blob.BlobStore
-	metaDB     *metadb.MetaDB
+	metaDB     metadataStore
 	cacheStore *cache.Cache
 	config.ServiceConfig
 
+	// uploads keeps track of in-flight uploads for Drain.
+	uploads uploadTracker
+	// uploadBytes limits the total size of in-flight CreateBlob uploads.
+	uploadBytes *byteLimiter
+	// auditSink receives an audit entry for every record mutation.
+	auditSink metadb.AuditSink
+	// touches buffers LastAccessedAt updates of blobs that are read.
+	// It is nil if BlobConfig.TouchWindow is not positive.
+	touches *metadb.TouchBatcher
+	// pathEncoding is the object path encoding of new blobs.
+	pathEncoding blobref.PathEncoding
+	// hashAlgorithm is the content hash algorithm of new blobs.
+	hashAlgorithm checksums.HashAlgorithm
+	// replicaStore is the replica that verified reads heal corrupt objects
+	// from. It is nil if BlobConfig.ReplicaBucket is empty.
+	replicaStore blob.BlobStore
+
 	pb.UnimplementedOpenSavesServer
 }
 
@@ -64,25 +89,113 @@ func newOpenSavesServer(ctx context.Context, cfg *config.ServiceConfig) (*openSa
 	log.Infof("Creating a new Open Saves server instance: cloud = %v, project = %v, bucket = %v, cache address = %v",
 		cfg.ServerConfig.Cloud, cfg.ServerConfig.Project, cfg.ServerConfig.Bucket, cfg.RedisConfig.Address)
 
+	if cfg.ServerConfig.Replicas > 1 && cfg.ServerConfig.CursorKey == "" {
+		return nil, fmt.Errorf("%s is required to run %d replicas", config.DatastoreCursorKey, cfg.ServerConfig.Replicas)
+	}
+	propertyNameMode, err := record.ParsePropertyNameMode(cfg.RecordConfig.PropertyNameMode)
+	if err != nil {
+		return nil, err
+	}
+	pathEncoding, err := blobref.ParsePathEncoding(cfg.BlobConfig.PathEncoding)
+	if err != nil {
+		return nil, err
+	}
+	keyGenerator, err := blobref.ParseKeyGenerator(cfg.BlobConfig.KeyGenerator)
+	if err != nil {
+		return nil, err
+	}
+	hashAlgorithm, err := checksums.ParseHashAlgorithm(cfg.BlobConfig.ContentHashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	keyValidator, err := metadb.NewKeyValidator(cfg.RecordConfig.KeyMaxLength, cfg.RecordConfig.KeyPattern, cfg.RecordConfig.KeyPermissive)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := record.NewCodec(cfg.CacheConfig.RecordCodec)
+	if err != nil {
+		return nil, err
+	}
+
 	switch cfg.ServerConfig.Cloud {
 	case "gcp":
 		log.Infoln("Instantiating Open Saves server on GCP")
-		gcs, err := blob.NewBlobGCP(ctx, cfg.ServerConfig.Bucket)
+		syncMode, err := blob.ParseSyncMode(cfg.BlobConfig.StoreSyncMode)
+		if err != nil {
+			return nil, err
+		}
+		bs, err := newBlobStore(ctx, cfg, syncMode)
+		if err != nil {
+			return nil, err
+		}
+		// Instrument the backend itself, so that calls served by the wrappers
+		// below, e.g. existence cache hits, are not recorded.
+		collector, err := metrics.NewOTelCollector(global.Meter(tracing.ServiceName))
 		if err != nil {
 			return nil, err
 		}
-		metadb, err := metadb.NewMetaDB(ctx, cfg.ServerConfig.Project)
+		bs = blob.NewInstrumentedBlobStore(bs, collector)
+		if cfg.BlobConfig.OperationTimeout > 0 {
+			bs = blob.NewTimeoutBlobStore(bs, cfg.BlobConfig.OperationTimeout)
+		}
+		if cfg.BlobConfig.CircuitBreakerThreshold > 0 {
+			bs = blob.NewCircuitBreakerBlobStore(bs, cfg.BlobConfig.CircuitBreakerThreshold, cfg.BlobConfig.CircuitBreakerCoolDown)
+		}
+		if cfg.BlobConfig.ExistsCachePositiveTTL > 0 {
+			bs = blob.NewExistenceCache(bs, cfg.BlobConfig.ExistsCachePositiveTTL, cfg.BlobConfig.ExistsCacheNegativeTTL)
+		}
+		if err := blob.EnsureReady(ctx, bs); err != nil {
+			log.Errorf("Blob store is not ready: %v", err)
+			return nil, err
+		}
+		var replica blob.BlobStore
+		if cfg.BlobConfig.ReplicaBucket != "" {
+			if replica, err = blob.NewBlobGCP(ctx, cfg.BlobConfig.ReplicaBucket); err != nil {
+				return nil, err
+			}
+		}
+		metaDB, err := metadb.NewMetaDB(ctx, cfg.ServerConfig.Project)
 		if err != nil {
 			log.Fatalf("Failed to create a MetaDB instance: %v", err)
 			return nil, err
 		}
+		metaDB.SetMaxConcurrentTransactions(cfg.ServerConfig.MaxConcurrentTransactions)
+		metaDB.KeyValidator = keyValidator
+		metaDB.StorePolicyTTL = cfg.ServerConfig.StorePolicyTTL
+		metaDB.CursorKey = []byte(cfg.ServerConfig.CursorKey)
+		if cfg.ServerConfig.SignatureKey != "" {
+			metaDB.Signer = timestamps.NewHMACSigner([]byte(cfg.ServerConfig.SignatureKey))
+		}
+		metaDB.PathEncoding = pathEncoding
+		metaDB.ShardBlobsByStore = len(cfg.BlobConfig.ShardBuckets) > 0
+		metaDB.BlobKeyGenerator = keyGenerator
+		metaDB.ContentHashAlgorithm = hashAlgorithm
+		metaDB.PropertyNameMode = propertyNameMode
+		metaDB.MaxEntitySize = cfg.RecordConfig.MaxEntitySize
+		if cfg.ServerConfig.QueryEventualConsistency {
+			metaDB.QueryConsistency = metadb.ConsistencyEventual
+		}
+		guard := cache.NewReadGuard(&cfg.CacheConfig, redis.IsMiss)
 		cache := cache.New(redis.NewRedisWithConfig(&cfg.RedisConfig), &cfg.CacheConfig)
+		cache.Codec = codec
+		cache.Guard = guard
 		server := &openSavesServer{
 			cloud:         cfg.ServerConfig.Cloud,
-			blobStore:     gcs,
-			metaDB:        metadb,
+			blobStore:     bs,
+			replicaStore:  replica,
+			metaDB:        metaDB,
 			cacheStore:    cache,
 			ServiceConfig: *cfg,
+			uploadBytes:   newByteLimiter(cfg.BlobConfig.MaxInFlightUploadBytes),
+			auditSink:     metadb.NopAuditSink{},
+			pathEncoding:  pathEncoding,
+			hashAlgorithm: hashAlgorithm,
+		}
+		if cfg.ServerConfig.EnableAuditLog {
+			server.auditSink = metadb.DatastoreAuditSink{MetaDB: metaDB}
+		}
+		if cfg.BlobConfig.TouchWindow > 0 {
+			server.touches = metaDB.NewTouchBatcher(cfg.BlobConfig.TouchWindow, 0)
 		}
 		return server, nil
 	default:
@@ -96,6 +209,9 @@ func (s *openSavesServer) CreateStore(ctx context.Context, req *pb.CreateStoreRe
 		Name:    req.Store.Name,
 		Tags:    req.Store.Tags,
 		OwnerID: req.Store.OwnerId,
+
+		DefaultBlobTTL:   req.Store.GetDefaultBlobTtl().AsDuration(),
+		DefaultRecordTTL: req.Store.GetDefaultRecordTtl().AsDuration(),
 	}
 	newStore, err := s.metaDB.CreateStore(ctx, &store)
 	if err != nil {
@@ -113,6 +229,8 @@ func (s *openSavesServer) CreateRecord(ctx context.Context, req *pb.CreateRecord
 		log.Errorf("Invalid record proto for store (%s), record (%s): %v", req.GetStoreKey(), req.GetRecord().GetKey(), err)
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid record proto: %v", err)
 	}
+	// InsertRecord applies the default TTL of the store if ttl is not set.
+	record.SetTTL(req.GetRecord().GetTtl().AsDuration(), 0, time.Now())
 	newRecord, err := s.metaDB.InsertRecord(ctx, req.StoreKey, record)
 	if err != nil {
 		log.Warnf("CreateRecord failed for store (%s), record (%s): %v",
@@ -121,11 +239,12 @@ func (s *openSavesServer) CreateRecord(ctx context.Context, req *pb.CreateRecord
 	}
 
 	s.cacheRecord(ctx, newRecord, req.GetHint())
+	s.audit(ctx, req.GetStoreKey(), newRecord.Key, metadb.AuditActionCreate, nil, newRecord)
 	return newRecord.ToProto(), nil
 }
 
 func (s *openSavesServer) DeleteRecord(ctx context.Context, req *pb.DeleteRecordRequest) (*empty.Empty, error) {
-	err := s.metaDB.DeleteRecord(ctx, req.GetStoreKey(), req.GetKey())
+	deleted, err := s.metaDB.GetAndDeleteRecord(ctx, req.GetStoreKey(), req.GetKey())
 	if err != nil {
 		log.Warnf("DeleteRecord failed for store (%s), record (%s): %v",
 			req.GetStoreKey(), req.GetKey(), err)
@@ -133,6 +252,9 @@ func (s *openSavesServer) DeleteRecord(ctx context.Context, req *pb.DeleteRecord
 	}
 	log.Debugf("Deleted record: store (%s), record (%s)",
 		req.GetStoreKey(), req.GetKey())
+	if deleted != nil {
+		s.audit(ctx, req.GetStoreKey(), req.GetKey(), metadb.AuditActionDelete, deleted, nil)
+	}
 
 	// Purge record from cache store.
 	if err := s.cacheStore.Delete(ctx, record.CacheKey(req.GetStoreKey(), req.GetKey())); err != nil {
@@ -175,6 +297,9 @@ func (s *openSavesServer) DeleteStore(ctx context.Context, req *pb.DeleteStoreRe
 }
 
 func (s *openSavesServer) GetRecord(ctx context.Context, req *pb.GetRecordRequest) (*pb.Record, error) {
+	if len(req.GetFieldMask().GetPaths()) > 0 {
+		return s.getRecordWithFieldMask(ctx, req)
+	}
 	record, err := s.getRecordAndCache(ctx, req.GetStoreKey(), req.GetKey(), req.GetHint())
 	if err != nil {
 		return nil, err
@@ -189,16 +314,23 @@ func (s *openSavesServer) UpdateRecord(ctx context.Context, req *pb.UpdateRecord
 		log.Errorf("Invalid proto for store (%s), record (%s): %v", req.GetStoreKey(), req.GetRecord().GetKey(), err)
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid record proto: %v", err)
 	}
+	var before record.Record
 	newRecord, err := s.metaDB.UpdateRecord(ctx, req.GetStoreKey(), updateTo.Key,
 		func(r *record.Record) (*record.Record, error) {
 			if updateTo.Timestamps.Signature != uuid.Nil && r.Timestamps.Signature != updateTo.Timestamps.Signature {
 				return nil, status.Errorf(codes.Aborted, "Signature mismatch: expected (%v), actual (%v)",
 					updateTo.Timestamps.Signature.String(), r.Timestamps.Signature.String())
 			}
+			// The fields below are replaced rather than modified, so a shallow
+			// copy keeps the previous values for the audit entry.
+			before = *r
 			r.OwnerID = updateTo.OwnerID
 			r.Properties = updateTo.Properties
 			r.Tags = updateTo.Tags
 			r.OpaqueString = updateTo.OpaqueString
+			if ttl := req.GetRecord().GetTtl().AsDuration(); ttl > 0 {
+				r.SetTTL(ttl, 0, time.Now())
+			}
 			return r, nil
 		})
 	if err != nil {
@@ -209,6 +341,7 @@ func (s *openSavesServer) UpdateRecord(ctx context.Context, req *pb.UpdateRecord
 
 	// Update cache store.
 	s.cacheRecord(ctx, newRecord, req.GetHint())
+	s.audit(ctx, req.GetStoreKey(), newRecord.Key, metadb.AuditActionUpdate, &before, newRecord)
 
 	return newRecord.ToProto(), nil
 }
@@ -269,11 +402,7 @@ func (s *openSavesServer) insertInlineBlob(ctx context.Context, stream pb.OpenSa
 	// Receive the blob
 	size := meta.GetSize()
 	buffer := new(bytes.Buffer)
-	recvd := 0
 	for {
-		if int64(recvd) > size {
-			break
-		}
 		req, err := stream.Recv()
 		if err == io.EOF {
 			break
@@ -285,11 +414,12 @@ func (s *openSavesServer) insertInlineBlob(ctx context.Context, stream pb.OpenSa
 		if fragment == nil {
 			return status.Error(codes.InvalidArgument, "Subsequent input messages must contain blob content")
 		}
-		n, err := buffer.Write(fragment)
-		if err != nil {
+		if err := checkBlobOverflow(int64(buffer.Len()), fragment, size); err != nil {
+			return err
+		}
+		if _, err := buffer.Write(fragment); err != nil {
 			return err
 		}
-		recvd += n
 	}
 
 	if int64(buffer.Len()) != size {
@@ -317,12 +447,63 @@ func (s *openSavesServer) insertInlineBlob(ctx context.Context, stream pb.OpenSa
 			return record, nil
 		})
 	if err != nil {
-		return nil
+		return err
 	}
 	s.cacheRecord(ctx, record, meta.GetHint())
 	return stream.SendAndClose(meta)
 }
 
+// checkBlobOverflow returns an InvalidArgument error if fragment makes the
+// content received so far exceed the size declared in the metadata, so that
+// the upload is aborted before the overflow is buffered or written.
+func checkBlobOverflow(received int64, fragment []byte, size int64) error {
+	if received+int64(len(fragment)) <= size {
+		return nil
+	}
+	log.Errorf("Received blob content exceeds the size in metadata (%v)", size)
+	return status.Errorf(codes.InvalidArgument,
+		"Received blob content (at least %v bytes) exceeds the size in metadata (%v)",
+		received+int64(len(fragment)), size)
+}
+
+// newBlobStore returns the blob store of cfg. If shard buckets are configured,
+// it is a MultiBucketBlobStore over ServerConfig.Bucket (or StoreRootDir) and
+// the shard buckets, where objects without a store segment stay in the former.
+func newBlobStore(ctx context.Context, cfg *config.ServiceConfig, syncMode blob.SyncMode) (blob.BlobStore, error) {
+	openBucket := func(bucket string) (blob.BlobStore, error) {
+		return blob.NewBlobStore(ctx, cfg.BlobConfig.StoreKind, blob.BlobStoreConfig{
+			BucketURL:  bucket,
+			KMSKeyName: cfg.BlobConfig.KMSKeyName,
+			RootDir:    bucket,
+			SyncMode:   syncMode,
+		})
+	}
+	primary := cfg.ServerConfig.Bucket
+	if cfg.BlobConfig.StoreKind == blob.KindFilesystem {
+		primary = cfg.BlobConfig.StoreRootDir
+	}
+	bs, err := openBucket(primary)
+	if err != nil || len(cfg.BlobConfig.ShardBuckets) == 0 {
+		return bs, err
+	}
+	// The primary bucket comes first so that paths without a store segment
+	// are routed to it.
+	router, err := blob.NewBucketRouter(append([]string{primary}, cfg.BlobConfig.ShardBuckets...))
+	if err != nil {
+		return nil, err
+	}
+	stores := map[string]blob.BlobStore{primary: bs}
+	for _, b := range router.Buckets() {
+		if stores[b] != nil {
+			continue
+		}
+		if stores[b], err = openBucket(b); err != nil {
+			return nil, err
+		}
+	}
+	return blob.NewMultiBucketBlobStore(router, stores)
+}
+
 func (s *openSavesServer) blobRefFail(ctx context.Context, blobref *blobref.BlobRef) {
 	blobref.Fail()
 	_, err := s.metaDB.UpdateBlobRef(ctx, blobref)
@@ -333,13 +514,40 @@ func (s *openSavesServer) blobRefFail(ctx context.Context, blobref *blobref.Blob
 
 func (s *openSavesServer) insertExternalBlob(ctx context.Context, stream pb.OpenSaves_CreateBlobServer, meta *pb.BlobMetadata) error {
 	log.Debugf("Inserting external blob: %v\n", meta)
-	// Create a blob reference based on the metadata.
-	blobref := blobref.NewBlobRef(meta.GetSize(), meta.GetStoreKey(), meta.GetRecordKey())
-	blobref, err := s.metaDB.InsertBlobRef(ctx, blobref)
+	store, err := s.getStoreAndCache(ctx, meta.GetStoreKey())
 	if err != nil {
 		return err
 	}
-	writer, err := s.blobStore.NewWriter(ctx, blobref.ObjectPath())
+	// Create a blob reference based on the metadata.
+	blobref := s.metaDB.NewBlobRef(meta.GetSize(), meta.GetStoreKey(), meta.GetRecordKey())
+	blobref.PathEncoding = s.pathEncoding
+	blobref.SetTTL(meta.GetTtl().AsDuration(), store.DefaultBlobTTL)
+	blobref.Metadata = meta.GetMetadata()
+	blobref.KMSKeyName = blob.KMSKeyName(s.blobStore)
+	blobref.ContentDisposition = meta.GetContentDisposition()
+	blobref.CacheControl = meta.GetCacheControl()
+	blobref.ContentType = s.blobContentType(meta)
+	if err := blobref.ValidateMetadata(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if token := meta.GetIdempotencyToken(); token != "" && s.BlobConfig.IdempotencyWindow > 0 {
+		existing, found, err := s.metaDB.InsertBlobRefWithToken(ctx, blobref, token, s.BlobConfig.IdempotencyWindow)
+		if err != nil {
+			return err
+		}
+		if found {
+			return s.sendExistingBlob(stream, existing)
+		}
+		blobref = existing
+	} else {
+		blobref, err = s.metaDB.InsertBlobRef(ctx, blobref)
+		if err != nil {
+			return err
+		}
+	}
+	writerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	writer, err := blob.NewWriterWithHeaders(writerCtx, s.blobStore, blobref.ObjectPath(), objectHeaders(blobref))
 	if err != nil {
 		return err
 	}
@@ -347,12 +555,13 @@ func (s *openSavesServer) insertExternalBlob(ctx context.Context, stream pb.Open
 		if writer != nil {
 			writer.Close()
 			// This means an abnormal exit, so make sure to mark the blob as Fail.
-			s.blobRefFail(ctx, blobref)
+			// ctx may be canceled by the client or the server shutdown.
+			s.blobRefFail(context.Background(), blobref)
 		}
 	}()
 
 	written := int64(0)
-	digest := checksums.NewDigest()
+	digest := checksums.NewDigestWithContentHash(s.hashAlgorithm)
 	for {
 		req, err := stream.Recv()
 		if err == io.EOF {
@@ -366,6 +575,10 @@ func (s *openSavesServer) insertExternalBlob(ctx context.Context, stream pb.Open
 		if fragment == nil {
 			return status.Error(codes.InvalidArgument, "Subsequent input messages must contain blob content")
 		}
+		// The deferred function marks the blob as Failed.
+		if err := checkBlobOverflow(written, fragment, meta.GetSize()); err != nil {
+			return err
+		}
 		n, err := writer.Write(fragment)
 		if err != nil {
 			log.Errorf("CreateBlob BlobStore write error: %v", err)
@@ -391,7 +604,7 @@ func (s *openSavesServer) insertExternalBlob(ctx context.Context, stream pb.Open
 		return status.Errorf(codes.DataLoss,
 			"Written byte length (%v) != blob length in metadata sent from client (%v)", written, meta.GetSize())
 	}
-	blobref.Checksums = digest.Checksums()
+	blobref.SetChecksums(digest)
 	if err := blobref.ValidateIfPresent(meta); err != nil {
 		log.Error(err)
 		return err
@@ -406,9 +619,42 @@ func (s *openSavesServer) insertExternalBlob(ctx context.Context, stream pb.Open
 	return stream.SendAndClose(meta)
 }
 
+// sendExistingBlob responds to a retried CreateBlob upload with the blob of
+// the first upload with the same idempotency token. The content of the retry
+// is read and discarded, so that the client can finish sending it before it
+// receives the response.
+func (s *openSavesServer) sendExistingBlob(stream pb.OpenSaves_CreateBlobServer, existing *blobref.BlobRef) error {
+	if existing.Status != blobref.StatusReady {
+		return status.Errorf(codes.Aborted,
+			"an upload with the same idempotency token is in progress for blob (%v)", existing.Key)
+	}
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Errorf("CreateBlob stream recv error while discarding a retried upload: %v", err)
+			return err
+		}
+	}
+	log.Debugf("Returning blob (%v) uploaded with the same idempotency token", existing.Key)
+	return stream.SendAndClose(existing.ToProto())
+}
+
 func (s *openSavesServer) CreateBlob(stream pb.OpenSaves_CreateBlobServer) error {
 	log.Debug("Creating blob stream\n")
-	ctx := stream.Context()
+	upload, err := s.uploads.begin()
+	if err != nil {
+		return err
+	}
+	defer upload.done()
+	// Canceling the stream on drain unblocks a pending Recv and aborts the
+	// blob writer, so the upload exits below.
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	upload.onCancel(cancel)
+	stream = &cancelableCreateBlobStream{OpenSaves_CreateBlobServer: stream, ctx: ctx}
 
 	// The first message must be metadata.
 	req, err := stream.Recv()
@@ -424,6 +670,13 @@ func (s *openSavesServer) CreateBlob(stream pb.OpenSaves_CreateBlobServer) error
 	log.Debugf("Got metadata from stream: store(%s), record(%s), blob size(%d)\n",
 		meta.GetStoreKey(), meta.GetRecordKey(), meta.GetSize())
 
+	release, err := s.uploadBytes.acquire(meta.GetSize())
+	if err != nil {
+		log.Warnf("CreateBlob: rejected upload for store(%s), record(%s): %v", meta.GetStoreKey(), meta.GetRecordKey(), err)
+		return err
+	}
+	defer release()
+
 	if meta.GetSize() <= int64(s.BlobConfig.MaxInlineSize) {
 		return s.insertInlineBlob(ctx, stream, meta)
 	}
@@ -438,12 +691,19 @@ func (s *openSavesServer) getExternalBlob(ctx context.Context, req *pb.GetBlobRe
 		return err
 	}
 
+	if err := validateBlobOffset(req.GetOffset(), blobref.Size); err != nil {
+		return err
+	}
+	s.touchBlob(blobref.Key)
 	meta := blobref.ToProto()
 	stream.Send(&pb.GetBlobResponse{Response: &pb.GetBlobResponse_Metadata{Metadata: meta}})
 
-	reader, err := s.blobStore.NewReader(ctx, blobref.ObjectPath())
+	if s.verifiesRead(blobref, req.GetOffset()) {
+		return s.sendVerifiedBlob(ctx, stream, blobref)
+	}
+	reader, err := s.blobStore.NewRangeReader(ctx, blobref.ObjectPath(), req.GetOffset(), -1)
 	if err != nil {
-		log.Errorf("BlobStore.NewReader returned error for object (%v): %v", blobref.ObjectPath(), err)
+		log.Errorf("BlobStore.NewRangeReader returned error for object (%v): %v", blobref.ObjectPath(), err)
 		return err
 	}
 	defer reader.Close()
@@ -467,10 +727,46 @@ func (s *openSavesServer) getExternalBlob(ctx context.Context, req *pb.GetBlobRe
 		}
 		sent += int64(n)
 	}
-	if sent != blobref.Size {
-		log.Errorf("GetBlob: Blob size sent (%v) and stored in the metadata (%v) don't match.", sent, blobref.Size)
+	if req.GetOffset()+sent != blobref.Size {
+		log.Errorf("GetBlob: Blob size sent (%v) from offset (%v) and stored in the metadata (%v) don't match.",
+			sent, req.GetOffset(), blobref.Size)
 		return status.Errorf(codes.DataLoss,
-			"GetBlob: Blob size sent (%v) and stored in the metadata (%v) don't match.", sent, blobref.Size)
+			"GetBlob: Blob size sent (%v) from offset (%v) and stored in the metadata (%v) don't match.",
+			sent, req.GetOffset(), blobref.Size)
+	}
+	return nil
+}
+
+// verifiesRead reports whether a read of blob from offset is verified against
+// the checksums of the blob. See BlobConfig.VerifiedReadMaxSize.
+func (s *openSavesServer) verifiesRead(b *blobref.BlobRef, offset int64) bool {
+	max := s.BlobConfig.VerifiedReadMaxSize
+	return max > 0 && offset == 0 && b.Size <= max && (len(b.MD5) != 0 || b.HasCRC32C)
+}
+
+// sendVerifiedBlob sends the content of blob after verifying it against the
+// checksums of the blob, healing the object from the replica if it is corrupt.
+func (s *openSavesServer) sendVerifiedBlob(ctx context.Context, stream pb.OpenSaves_GetBlobServer, blobref *blobref.BlobRef) error {
+	data, err := blob.GetVerified(ctx, s.blobStore, s.replicaStore, blobref.ObjectPath(), blobref.Checksums)
+	if errors.Is(err, blob.ErrChecksumMismatch) {
+		log.Errorf("GetBlob: object (%v) is corrupt: %v", blobref.ObjectPath(), err)
+		return status.Errorf(codes.DataLoss, "GetBlob: the blob content is corrupt: %v", err)
+	}
+	if err != nil {
+		log.Errorf("GetBlob: BlobStore returned error for object (%v): %v", blobref.ObjectPath(), err)
+		return err
+	}
+	for len(data) > 0 {
+		n := len(data)
+		if n > streamBufferSize {
+			n = streamBufferSize
+		}
+		err := stream.Send(&pb.GetBlobResponse{Response: &pb.GetBlobResponse_Content{Content: data[:n]}})
+		if err != nil {
+			log.Errorf("GetBlob: Stream send error for object (%v): %v", blobref.ObjectPath(), err)
+			return err
+		}
+		data = data[n:]
 	}
 	return nil
 }
@@ -490,24 +786,164 @@ func (s *openSavesServer) GetBlob(req *pb.GetBlobRequest, stream pb.OpenSaves_Ge
 	}
 
 	// Handle the inline blob here.
+	if err := validateBlobOffset(req.GetOffset(), int64(len(rr.Blob))); err != nil {
+		return err
+	}
 	meta := rr.GetInlineBlobMetadata()
 	stream.Send(&pb.GetBlobResponse{Response: &pb.GetBlobResponse_Metadata{Metadata: meta}})
-	err = stream.Send(&pb.GetBlobResponse{Response: &pb.GetBlobResponse_Content{Content: rr.Blob}})
+	err = stream.Send(&pb.GetBlobResponse{Response: &pb.GetBlobResponse_Content{Content: rr.Blob[req.GetOffset():]}})
 	if err != nil {
 		log.Errorf("GetBlob: Stream send error for store (%v), record (%v): %v", req.GetRecordKey(), rr.Key, err)
 	}
 	return err
 }
 
+// validateBlobOffset returns InvalidArgument if offset is out of the range of
+// a blob of size.
+func validateBlobOffset(offset, size int64) error {
+	if offset < 0 || offset > size {
+		return status.Errorf(codes.InvalidArgument, "offset (%v) must be between 0 and the blob size (%v)", offset, size)
+	}
+	return nil
+}
+
+// DeleteBlob deletes the blob of the record. External blobs are deleted with
+// DeleteBlobAs on behalf of the caller authenticated by the Authenticator of
+// the server, so a blob that has an owner can only be deleted by the owner.
+// Inline blobs are part of the record, and without an Authenticator there is
+// no caller to check, so in those cases the blob is only detached from the
+// record and the garbage collector deletes the objects.
 func (s *openSavesServer) DeleteBlob(ctx context.Context, req *pb.DeleteBlobRequest) (*empty.Empty, error) {
-	rr, _, err := s.metaDB.RemoveBlobFromRecord(ctx, req.GetStoreKey(), req.GetRecordKey())
+	rr, err := s.metaDB.GetRecord(ctx, req.GetStoreKey(), req.GetRecordKey())
 	if err != nil {
-		log.Errorf("DeleteBlob: RemoveBlobFromRecord failed, store = %v, record = %v: %v",
+		log.Errorf("DeleteBlob: GetRecord failed, store = %v, record = %v: %v",
 			req.GetStoreKey(), req.GetRecordKey(), err)
-	} else {
+		return new(empty.Empty), err
+	}
+	identity := callerIdentity(ctx)
+	if rr.ExternalBlob == uuid.Nil || identity == "" {
+		rr, _, err = s.metaDB.RemoveBlobFromRecord(ctx, req.GetStoreKey(), req.GetRecordKey())
+		if err != nil {
+			log.Errorf("DeleteBlob: RemoveBlobFromRecord failed, store = %v, record = %v: %v",
+				req.GetStoreKey(), req.GetRecordKey(), err)
+			return new(empty.Empty), err
+		}
 		s.cacheRecord(ctx, rr, req.GetHint())
+		return new(empty.Empty), nil
+	}
+	if err := s.metaDB.DeleteBlobAs(ctx, s.blobStore, rr.ExternalBlob, identity); err != nil {
+		log.Errorf("DeleteBlob: DeleteBlobAs failed, store = %v, record = %v, blob = %v: %v",
+			req.GetStoreKey(), req.GetRecordKey(), rr.ExternalBlob, err)
+		// The record may have been detached from the blob before the failure.
+		if err := s.cacheStore.Delete(ctx, rr.CacheKey()); err != nil {
+			log.Errorf("DeleteBlob: failed to purge cache for store (%s), record (%s): %v", rr.StoreKey, rr.Key, err)
+		}
+		return new(empty.Empty), err
 	}
-	return new(empty.Empty), err
+	if rr, err = s.metaDB.GetRecord(ctx, req.GetStoreKey(), req.GetRecordKey()); err != nil {
+		log.Errorf("DeleteBlob: GetRecord failed after deleting the blob, store = %v, record = %v: %v",
+			req.GetStoreKey(), req.GetRecordKey(), err)
+		return new(empty.Empty), err
+	}
+	s.cacheRecord(ctx, rr, req.GetHint())
+	return new(empty.Empty), nil
+}
+
+func (s *openSavesServer) ExternalizeBlob(ctx context.Context, req *pb.ExternalizeBlobRequest) (*pb.ExternalizeBlobResponse, error) {
+	key := blobref.DeriveKey(req.GetStoreKey(), req.GetRecordKey(), req.GetName())
+	if req.GetBlobKey() != "" {
+		var err error
+		if key, err = uuid.Parse(req.GetBlobKey()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "blob_key is not a valid UUID string: %v", err)
+		}
+	}
+	rr, b, err := s.metaDB.InsertExternalizedBlobRef(ctx, req.GetStoreKey(), req.GetRecordKey(), key)
+	if err != nil {
+		log.Errorf("ExternalizeBlob: InsertExternalizedBlobRef failed, store = %v, record = %v, blob = %v: %v",
+			req.GetStoreKey(), req.GetRecordKey(), key, err)
+		return nil, err
+	}
+	b.KMSKeyName = blob.KMSKeyName(s.blobStore)
+	if err := s.blobStore.Put(ctx, b.ObjectPath(), rr.Blob); err != nil {
+		log.Errorf("ExternalizeBlob: BlobStore.Put failed for object %v: %v", b.ObjectPath(), err)
+		s.blobRefFail(ctx, b)
+		return nil, err
+	}
+	// The signature check aborts the promotion if the inline blob was changed
+	// after it was copied.
+	promoted, _, err := s.metaDB.PromoteBlobRefWithRecordUpdater(ctx, b, rr, func(r *record.Record) (*record.Record, error) {
+		return r, nil
+	})
+	if err != nil {
+		log.Errorf("ExternalizeBlob: PromoteBlobRefWithRecordUpdater failed for object %v: %v", key, err)
+		s.blobRefFail(ctx, b)
+		return nil, err
+	}
+	s.cacheRecord(ctx, promoted, req.GetHint())
+	s.audit(ctx, req.GetStoreKey(), req.GetRecordKey(), metadb.AuditActionUpdate, rr, promoted)
+	return &pb.ExternalizeBlobResponse{BlobKey: b.Key.String(), Metadata: b.ToProto()}, nil
+}
+
+func (s *openSavesServer) CheckBlobExists(ctx context.Context, req *pb.CheckBlobExistsRequest) (*pb.CheckBlobExistsResponse, error) {
+	if req.GetSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "size must not be negative, got %d", req.GetSize())
+	}
+	var b *blobref.BlobRef
+	var err error
+	if len(req.GetContentHash()) != 0 {
+		algorithm, perr := checksums.ParseHashAlgorithm(req.GetContentHashAlgorithm())
+		if perr != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", perr)
+		}
+		if len(req.GetContentHash()) != algorithm.Size() {
+			return nil, status.Errorf(codes.InvalidArgument, "content_hash must be %d bytes long for %v, got %d",
+				algorithm.Size(), algorithm, len(req.GetContentHash()))
+		}
+		b, err = s.metaDB.FindBlobRefByContentHash(ctx, req.GetStoreKey(), algorithm, req.GetContentHash(), req.GetSize(), s.dedupConsistency())
+	} else {
+		if len(req.GetMd5()) != md5.Size {
+			return nil, status.Errorf(codes.InvalidArgument, "md5 must be %d bytes long, got %d", md5.Size, len(req.GetMd5()))
+		}
+		b, err = s.metaDB.FindBlobRefByChecksum(ctx, req.GetStoreKey(), req.GetMd5(), req.GetSize(), s.dedupConsistency())
+	}
+	if status.Code(err) == codes.NotFound {
+		return new(pb.CheckBlobExistsResponse), nil
+	}
+	if err != nil {
+		log.Errorf("CheckBlobExists: blob lookup failed for store (%v): %v", req.GetStoreKey(), err)
+		return nil, err
+	}
+	return &pb.CheckBlobExistsResponse{BlobKey: b.Key.String()}, nil
+}
+
+// objectHeaders returns the HTTP headers of the objects of b.
+func objectHeaders(b *blobref.BlobRef) blob.ObjectHeaders {
+	return blob.ObjectHeaders{
+		ContentDisposition: b.ContentDisposition,
+		CacheControl:       b.CacheControl,
+		ContentType:        b.ContentType,
+	}
+}
+
+// blobContentType returns the content type of the blob described by meta.
+// An explicit content type always wins. Otherwise, it is inferred from the
+// extension of the filename, or left empty if there is no filename.
+func (s *openSavesServer) blobContentType(meta *pb.BlobMetadata) string {
+	if t := meta.GetContentType(); t != "" {
+		return t
+	}
+	if meta.GetFilename() == "" {
+		return ""
+	}
+	return blob.InferContentType(meta.GetFilename(), s.BlobConfig.ContentTypes)
+}
+
+// dedupConsistency returns the consistency of the queries made by CheckBlobExists.
+func (s *openSavesServer) dedupConsistency() metadb.Consistency {
+	if s.BlobConfig.DedupEventualConsistency {
+		return metadb.ConsistencyEventual
+	}
+	return metadb.ConsistencyStrong
 }
 
 func (s *openSavesServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
@@ -517,7 +953,15 @@ func (s *openSavesServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.Pi
 }
 
 func (s *openSavesServer) CreateChunkedBlob(ctx context.Context, req *pb.CreateChunkedBlobRequest) (*pb.CreateChunkedBlobResponse, error) {
-	b := blobref.NewChunkedBlobRef(req.GetStoreKey(), req.GetRecordKey(), req.GetChunkCount())
+	// Chunks of chunk_size bytes must all meet the minimum except the final one.
+	if req.GetChunkSize() > 0 {
+		if err := chunkref.ValidateChunkSize(0, req.GetChunkCount(), req.GetChunkSize(), s.BlobConfig.MinChunkSize); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "CreateChunkedBlob: chunk_size (%v) is too small: %v", req.GetChunkSize(), err)
+		}
+	}
+	b := s.metaDB.NewChunkedBlobRef(req.GetStoreKey(), req.GetRecordKey(), req.GetChunkCount())
+	b.PathEncoding = s.pathEncoding
+	b.KMSKeyName = blob.KMSKeyName(s.blobStore)
 	b, err := s.metaDB.InsertBlobRef(ctx, b)
 	if err != nil {
 		log.Errorf("CreateChunkedBlob failed for store (%v), record (%v): %v", req.GetStoreKey(), req.GetRecordKey(), err)
@@ -552,7 +996,7 @@ func (s *openSavesServer) CreateChunkUrls(ctx context.Context, req *pb.CreateChu
 			continue
 		}
 
-		url, err := s.blobStore.SignUrl(ctx, chunk.Key.String(), req.GetTtlInSeconds(), "GET")
+		url, err := blob.SignUrlWithHeaders(ctx, s.blobStore, chunk.Key.String(), req.GetTtlInSeconds(), "GET", objectHeaders(blobRef))
 		if err != nil {
 			log.Errorf("CreateChunkUrls failed to get sign url for chunkNumber(%v), Bucket(%v), ChunkKey(%v) :%v", chunk.Number, s.ServerConfig.Bucket, chunk.Key, err)
 			return nil, err
@@ -569,7 +1013,18 @@ func (s *openSavesServer) CreateChunkUrls(ctx context.Context, req *pb.CreateChu
 }
 
 func (s *openSavesServer) UploadChunk(stream pb.OpenSaves_UploadChunkServer) error {
-	ctx := stream.Context()
+	upload, err := s.uploads.begin()
+	if err != nil {
+		return err
+	}
+	defer upload.done()
+	// Canceling the stream on drain unblocks a pending Recv and aborts the
+	// chunk writer.
+	ctx, cancelStream := context.WithCancel(stream.Context())
+	defer cancelStream()
+	upload.onCancel(cancelStream)
+	stream = &cancelableUploadChunkStream{OpenSaves_UploadChunkServer: stream, ctx: ctx}
+
 	req, err := stream.Recv()
 	if err != nil {
 		log.Errorf("Recv() returned error: %v", err)
@@ -588,12 +1043,23 @@ func (s *openSavesServer) UploadChunk(stream pb.OpenSaves_UploadChunkServer) err
 
 	// Create a chunk reference based on the metadata. Do not add to blobref right away to minimize writes
 	chunk := chunkref.New(blobKey, int32(meta.GetNumber()))
+	if meta.GetOffset() < 0 || meta.GetLength() < 0 {
+		return status.Errorf(codes.InvalidArgument, "Offset (%v) and Length (%v) must not be negative", meta.GetOffset(), meta.GetLength())
+	}
+	chunk.Offset = meta.GetOffset()
+	chunk.Length = meta.GetLength()
 	blob, err := s.metaDB.ValidateChunkRefPreconditions(ctx, chunk)
 	if err != nil {
 		return err
 	}
+	if chunk.Length != 0 {
+		if err := chunkref.ValidateChunkSize(chunk.Number, blob.ChunkCount, chunk.Length, s.BlobConfig.MinChunkSize); err != nil {
+			return status.Errorf(codes.InvalidArgument, "UploadChunk: %v", err)
+		}
+	}
 
 	contextWithCancel, cancel := context.WithCancel(ctx)
+	// Canceling the writer context discards the partially written object.
 	writer, err := s.blobStore.NewWriter(contextWithCancel, chunk.ObjectPath())
 	if err != nil {
 		cancel()
@@ -643,6 +1109,19 @@ func (s *openSavesServer) UploadChunk(stream pb.OpenSaves_UploadChunkServer) err
 		return err
 	}
 
+	if chunk.Length != 0 && chunk.Length != int64(written) {
+		_ = s.deleteObjectOnExit(ctx, chunk.ObjectPath())
+		err := status.Errorf(codes.InvalidArgument, "UploadChunk: received chunk size (%v) doesn't match the declared length (%v)", written, chunk.Length)
+		log.Error(err)
+		return err
+	}
+
+	if err := chunkref.ValidateChunkSize(chunk.Number, blob.ChunkCount, int64(written), s.BlobConfig.MinChunkSize); err != nil {
+		_ = s.deleteObjectOnExit(ctx, chunk.ObjectPath())
+		log.Errorf("UploadChunk: %v", err)
+		return status.Errorf(codes.InvalidArgument, "UploadChunk: %v", err)
+	}
+
 	// Update the chunk size based on the actual bytes written
 	chunk.Size = int32(written)
 	chunk.Checksums = digest.Checksums()
@@ -655,8 +1134,14 @@ func (s *openSavesServer) UploadChunk(stream pb.OpenSaves_UploadChunkServer) err
 	chunk.Timestamps.Update()
 
 	if err := chunk.ValidateIfPresent(meta); err != nil {
-		_ = s.deleteObjectOnExit(ctx, chunk.ObjectPath())
-		log.Error(err)
+		// Record the corrupt chunk as failed so that only this chunk needs to be
+		// uploaded again. The garbage collector deletes the object later.
+		log.Errorf("Chunk (%v) of blobref (%v) failed validation: %v", chunk.Number, chunk.BlobRef, err)
+		chunk.Fail()
+		if err := s.metaDB.InsertChunkRef(ctx, blob, chunk); err != nil {
+			_ = s.deleteObjectOnExit(ctx, chunk.ObjectPath())
+			log.Errorf("Failed to insert chunkref metadata (%v), blobref (%v): %v", chunk.Key, chunk.BlobRef, err)
+		}
 		return err
 	}
 
@@ -710,6 +1195,9 @@ func (s *openSavesServer) CommitChunkedUpload(ctx context.Context, req *pb.Commi
 			r.Properties = updateTo.Properties
 			r.Tags = updateTo.Tags
 			r.OpaqueString = updateTo.OpaqueString
+			if ttl := req.GetRecord().GetTtl().AsDuration(); ttl > 0 {
+				r.SetTTL(ttl, 0, time.Now())
+			}
 			return r, nil
 		})
 		if err != nil {
@@ -800,6 +1288,7 @@ func (s *openSavesServer) CompareAndSwap(ctx context.Context, req *pb.CompareAnd
 	log.Infof("CompareAndSwap: store (%v), record (%v), property (%v)",
 		req.GetStoreKey(), req.GetRecordKey(), req.GetPropertyName())
 	res := &pb.CompareAndSwapResponse{Updated: false}
+	var before record.Record
 	updatedRecord, err := s.metaDB.UpdateRecord(ctx, req.GetStoreKey(), req.GetRecordKey(),
 		func(r *record.Record) (*record.Record, error) {
 			property, ok := r.Properties[req.GetPropertyName()]
@@ -810,6 +1299,9 @@ func (s *openSavesServer) CompareAndSwap(ctx context.Context, req *pb.CompareAnd
 			res.Value = property.ToProto()
 			oldValue := record.NewPropertyValueFromProto(req.GetOldValue())
 			value := record.NewPropertyValueFromProto(req.GetValue())
+			if err := value.ValidateList(); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid value: %v", err)
+			}
 
 			if property.Type == oldValue.Type {
 				switch property.Type {
@@ -825,6 +1317,14 @@ func (s *openSavesServer) CompareAndSwap(ctx context.Context, req *pb.CompareAnd
 					if property.StringValue == oldValue.StringValue {
 						res.Updated = true
 					}
+				case pb.Property_BYTES:
+					if bytes.Equal(property.BytesValue, oldValue.BytesValue) {
+						res.Updated = true
+					}
+				case pb.Property_LIST:
+					if proto.Equal(property.ToProto(), oldValue.ToProto()) {
+						res.Updated = true
+					}
 				}
 			}
 
@@ -832,6 +1332,7 @@ func (s *openSavesServer) CompareAndSwap(ctx context.Context, req *pb.CompareAnd
 				// ErrNoUpdate aborts the transaction safely.
 				return nil, metadb.ErrNoUpdate
 			}
+			before = auditSnapshot(r)
 			r.Properties[req.GetPropertyName()] = value
 			return r, nil
 		})
@@ -841,6 +1342,7 @@ func (s *openSavesServer) CompareAndSwap(ctx context.Context, req *pb.CompareAnd
 	}
 	if res.GetUpdated() {
 		s.cacheRecord(ctx, updatedRecord, req.GetHint())
+		s.audit(ctx, req.GetStoreKey(), req.GetRecordKey(), metadb.AuditActionUpdate, &before, updatedRecord)
 	}
 	return res, nil
 }
@@ -853,6 +1355,7 @@ func (s *openSavesServer) doAtomicUpdatePropertyInt(ctx context.Context, req *pb
 	res := &pb.AtomicIntResponse{
 		Updated: false,
 	}
+	var before record.Record
 	updatedRecord, err := s.metaDB.UpdateRecord(ctx, req.GetStoreKey(), req.GetRecordKey(),
 		func(r *record.Record) (*record.Record, error) {
 			property, ok := r.Properties[req.GetPropertyName()]
@@ -871,6 +1374,7 @@ func (s *openSavesServer) doAtomicUpdatePropertyInt(ctx context.Context, req *pb
 				return nil, metadb.ErrNoUpdate
 			}
 			res.Updated = updated
+			before = auditSnapshot(r)
 			property.IntegerValue = newValue
 			return r, nil
 		})
@@ -880,6 +1384,7 @@ func (s *openSavesServer) doAtomicUpdatePropertyInt(ctx context.Context, req *pb
 	}
 	if res.GetUpdated() {
 		s.cacheRecord(ctx, updatedRecord, req.GetHint())
+		s.audit(ctx, req.GetStoreKey(), req.GetRecordKey(), metadb.AuditActionUpdate, &before, updatedRecord)
 	}
 	return res, nil
 }
@@ -930,6 +1435,7 @@ func (s *openSavesServer) doAtomicIncDec(ctx context.Context, req *pb.AtomicIncR
 	res := &pb.AtomicIntResponse{
 		Updated: true,
 	}
+	var before record.Record
 	updatedRecord, err := s.metaDB.UpdateRecord(ctx, req.GetStoreKey(), req.GetRecordKey(),
 		func(r *record.Record) (*record.Record, error) {
 			property, ok := r.Properties[req.GetPropertyName()]
@@ -942,6 +1448,7 @@ func (s *openSavesServer) doAtomicIncDec(ctx context.Context, req *pb.AtomicIncR
 			}
 			// Save the old value.
 			res.Value = property.IntegerValue
+			before = auditSnapshot(r)
 			property.IntegerValue = callback(property.IntegerValue, req.GetLowerBound(), req.GetUpperBound())
 			return r, nil
 		})
@@ -950,6 +1457,7 @@ func (s *openSavesServer) doAtomicIncDec(ctx context.Context, req *pb.AtomicIncR
 		return nil, err
 	}
 	s.cacheRecord(ctx, updatedRecord, req.GetHint())
+	s.audit(ctx, req.GetStoreKey(), req.GetRecordKey(), metadb.AuditActionUpdate, &before, updatedRecord)
 	return res, nil
 }
 
@@ -980,20 +1488,50 @@ func (s *openSavesServer) getRecordAndCache(ctx context.Context, storeKey, key s
 		r := new(record.Record)
 		if err := s.cacheStore.Get(ctx, record.CacheKey(storeKey, key), r); err == nil {
 			log.Debug("cache hit")
+			if r.IsExpired(time.Now()) {
+				return nil, metadb.ErrRecordExpired
+			}
 			return r, nil
 		}
 		log.Debug("cache miss")
 	}
 
-	r, err := s.metaDB.GetRecord(ctx, storeKey, key)
+	v, err := s.loadAfterCacheMiss(ctx, record.CacheKey(storeKey, key), func(ctx context.Context) (interface{}, error) {
+		r, err := s.metaDB.GetRecord(ctx, storeKey, key)
+		if err != nil {
+			log.Warnf("GetRecord failed for store (%s), record (%s): %v",
+				storeKey, key, err)
+			return nil, status.Convert(err).Err()
+		}
+		log.Tracef("Got record %+v", r)
+		s.cacheRecord(ctx, r, hint)
+		return r, nil
+	})
 	if err != nil {
-		log.Warnf("GetRecord failed for store (%s), record (%s): %v",
-			storeKey, key, err)
-		return nil, status.Convert(err).Err()
+		return nil, err
+	}
+	return v.(*record.Record), nil
+}
+
+// loadAfterCacheMiss calls load to read the object of the cache key from
+// Datastore, coalescing and delaying concurrent loads while the cache is
+// failing. See cache.ReadGuard.
+func (s *openSavesServer) loadAfterCacheMiss(ctx context.Context, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if s.cacheStore.Guard == nil {
+		return load(ctx)
+	}
+	return s.cacheStore.Guard.Load(ctx, key, load)
+}
+
+// touchBlob updates LastAccessedAt of the blob of key in the next batch of
+// s.touches, if enabled.
+func (s *openSavesServer) touchBlob(key uuid.UUID) {
+	if s.touches == nil {
+		return
+	}
+	if err := s.touches.Touch(key, time.Now()); err != nil {
+		log.Debugf("Failed to touch blob (%v): %v", key, err)
 	}
-	log.Tracef("Got record %+v", r)
-	s.cacheRecord(ctx, r, hint)
-	return r, nil
 }
 
 func (s *openSavesServer) cacheRecord(ctx context.Context, r *record.Record, hint *pb.Hint) error {
@@ -1020,15 +1558,21 @@ func (s *openSavesServer) getStoreAndCache(ctx context.Context, storeKey string)
 	}
 	log.Debug("store cache miss")
 
-	str, err := s.metaDB.GetStore(ctx, storeKey)
+	v, err := s.loadAfterCacheMiss(ctx, store.CacheKey(storeKey), func(ctx context.Context) (interface{}, error) {
+		str, err := s.metaDB.GetStore(ctx, storeKey)
+		if err != nil {
+			log.Warnf("GetStore failed for store (%s): %v",
+				storeKey, err)
+			return nil, status.Convert(err).Err()
+		}
+		s.storeCache(ctx, str)
+		log.Tracef("Got store %+v", s)
+		return str, nil
+	})
 	if err != nil {
-		log.Warnf("GetStore failed for store (%s): %v",
-			storeKey, err)
-		return nil, status.Convert(err).Err()
+		return nil, err
 	}
-	s.storeCache(ctx, str)
-	log.Tracef("Got store %+v", s)
-	return str, nil
+	return v.(*store.Store), nil
 }
 
 func (s *openSavesServer) storeCache(ctx context.Context, st *store.Store) error {
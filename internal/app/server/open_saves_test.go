@@ -15,9 +15,13 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"hash/crc32"
 	"io"
 	"net"
 	"reflect"
@@ -32,11 +36,16 @@ import (
 	"github.com/google/uuid"
 	pb "github.com/googleforgames/open-saves/api"
 	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/cache"
+	"github.com/googleforgames/open-saves/internal/pkg/cache/redis"
 	"github.com/googleforgames/open-saves/internal/pkg/cmd"
 	"github.com/googleforgames/open-saves/internal/pkg/config"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums/checksumstest"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -48,12 +57,16 @@ import (
 	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
-	testProject             = "triton-for-games-dev"
-	testBucket              = "gs://triton-integration"
+	testProject = "triton-for-games-dev"
+	// The in-memory bucket keeps tests from writing to a real bucket. Each
+	// server opens its own bucket, so objects are only visible through it.
+	testBucket              = "mem://"
 	testPort                = "8000"
 	testBufferSize          = 1024 * 1024
 	testShutdownGracePeriod = "1s"
@@ -157,6 +170,14 @@ func TestOpenSaves_RunServer(t *testing.T) {
 }
 
 func getOpenSavesServer(ctx context.Context, t *testing.T, cloud string) (*openSavesServer, *bufconn.Listener) {
+	t.Helper()
+	return getOpenSavesServerWithConfig(ctx, t, cloud, nil)
+}
+
+// getOpenSavesServerWithConfig is the same as getOpenSavesServer, but calls
+// configure with the config before the server is created if it is not nil.
+func getOpenSavesServerWithConfig(ctx context.Context, t *testing.T, cloud string,
+	configure func(cfg *config.ServiceConfig)) (*openSavesServer, *bufconn.Listener) {
 	t.Helper()
 	r := miniredis.RunT(t)
 
@@ -174,6 +195,9 @@ func getOpenSavesServer(ctx context.Context, t *testing.T, cloud string) (*openS
 			MaxConnAge:  0,
 		},
 	}
+	if configure != nil {
+		configure(cfg)
+	}
 	impl, err := newOpenSavesServer(ctx, cfg)
 	if err != nil {
 		t.Fatalf("Failed to create a new Open Saves server instance: %v", err)
@@ -287,6 +311,8 @@ func setupTestStore(ctx context.Context, t *testing.T, client pb.OpenSavesClient
 	}
 }
 
+// cleanupBlobs deletes the BlobRefs of the record. The objects are freed with
+// the in-memory bucket of the server.
 func cleanupBlobs(ctx context.Context, t *testing.T, storeKey, recordkey string) {
 	t.Helper()
 	client, err := datastore.NewClient(ctx, testProject)
@@ -294,11 +320,6 @@ func cleanupBlobs(ctx context.Context, t *testing.T, storeKey, recordkey string)
 		t.Errorf("datastore.NewClient failed during cleanup: %v", err)
 		return
 	}
-	blobClient, err := blob.NewBlobGCP(ctx, testBucket)
-	if err != nil {
-		t.Errorf("NewBlobGCP returned error: %v", err)
-		blobClient = nil
-	}
 	query := datastore.NewQuery(blobKind).Filter("StoreKey =", storeKey).Filter("RecordKey =", recordkey)
 	iter := client.Run(ctx, query)
 
@@ -312,9 +333,6 @@ func cleanupBlobs(ctx context.Context, t *testing.T, storeKey, recordkey string)
 			t.Errorf("iterator.Next returned error: %v", err)
 			break
 		}
-		if blobClient != nil {
-			blobClient.Delete(ctx, blobRef.ObjectPath())
-		}
 		if err := client.Delete(ctx, key); err != nil {
 			t.Errorf("Delete for key (%v) returned error: %v", key.String(), err)
 		}
@@ -371,6 +389,51 @@ func funcValueToName(f any) string {
 	return s[strings.LastIndex(s, ".")+1:]
 }
 
+func TestNewOpenSavesServer_CursorKeyRequired(t *testing.T) {
+	cfg := &config.ServiceConfig{
+		ServerConfig: config.ServerConfig{Cloud: "gcp", Bucket: testBucket, Project: testProject, Replicas: 2},
+	}
+	_, err := newOpenSavesServer(context.Background(), cfg)
+	assert.ErrorContains(t, err, config.DatastoreCursorKey)
+}
+
+func TestOpenSaves_SignatureKey(t *testing.T) {
+	ctx := context.Background()
+	const key = "signature key"
+	server, listener := getOpenSavesServerWithConfig(ctx, t, "gcp", func(cfg *config.ServiceConfig) {
+		cfg.ServerConfig.SignatureKey = key
+	})
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	signer := timestamps.NewHMACSigner([]byte(key))
+
+	created := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	r, err := server.metaDB.GetRecord(ctx, store.Key, created.Key)
+	require.NoError(t, err)
+	assert.True(t, r.Timestamps.VerifySignature(signer), "created records must be signed")
+	assert.Equal(t, r.Timestamps.Signature[:], created.GetSignature())
+
+	updated, err := client.UpdateRecord(ctx, &pb.UpdateRecordRequest{
+		StoreKey: store.Key,
+		Record:   &pb.Record{Key: created.Key, OpaqueString: "updated"},
+	})
+	require.NoError(t, err)
+	r, err = server.metaDB.GetRecord(ctx, store.Key, created.Key)
+	require.NoError(t, err)
+	assert.True(t, r.Timestamps.VerifySignature(signer), "updated records must be signed")
+	assert.Equal(t, r.Timestamps.Signature[:], updated.GetSignature())
+
+	createBlob(ctx, t, client, store.Key, created.Key, bytes.Repeat([]byte{1}, 1<<10))
+	t.Cleanup(func() { cleanupBlobs(ctx, t, store.Key, created.Key) })
+	b, err := server.metaDB.GetCurrentBlobRef(ctx, store.Key, created.Key)
+	require.NoError(t, err)
+	assert.True(t, b.Timestamps.VerifySignature(signer), "BlobRefs must be signed")
+	r, err = server.metaDB.GetRecord(ctx, store.Key, created.Key)
+	require.NoError(t, err)
+	assert.True(t, r.Timestamps.VerifySignature(signer), "records must be signed when blobs are attached")
+}
+
 func TestOpenSaves_CreateGetDeleteStore(t *testing.T) {
 	ctx := context.Background()
 	_, listener := getOpenSavesServer(ctx, t, "gcp")
@@ -454,6 +517,117 @@ func TestOpenSaves_CreateGetDeleteRecord(t *testing.T) {
 	assert.Equal(t, record.GetUpdatedAt(), record2.GetUpdatedAt())
 }
 
+func TestOpenSaves_RecordTTL(t *testing.T) {
+	ctx := context.Background()
+	_, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	storeKey := uuid.NewString()
+	setupTestStore(ctx, t, client, &pb.Store{Key: storeKey, DefaultRecordTtl: durationpb.New(time.Hour)})
+
+	defaulted := setupTestRecord(ctx, t, client, storeKey, &pb.Record{Key: uuid.NewString()})
+	assert.Equal(t, time.Hour, defaulted.GetExpiresAt().AsTime().Sub(defaulted.GetCreatedAt().AsTime()))
+
+	own := setupTestRecord(ctx, t, client, storeKey, &pb.Record{Key: uuid.NewString(), Ttl: durationpb.New(time.Minute)})
+	assert.WithinDuration(t, time.Now().Add(time.Minute), own.GetExpiresAt().AsTime(), 10*time.Second)
+
+	// UpdateRecord extends the expiry with a new ttl.
+	updated, err := client.UpdateRecord(ctx, &pb.UpdateRecordRequest{
+		StoreKey: storeKey,
+		Record:   &pb.Record{Key: own.GetKey(), Ttl: durationpb.New(2 * time.Hour)},
+	})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), updated.GetExpiresAt().AsTime(), 10*time.Second)
+
+	// Expired records are reported distinctly from missing ones.
+	expired := setupTestRecord(ctx, t, client, storeKey, &pb.Record{Key: uuid.NewString(), Ttl: durationpb.New(time.Millisecond)})
+	time.Sleep(10 * time.Millisecond)
+	_, err = client.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: storeKey, Key: expired.GetKey()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.True(t, metadb.IsRecordExpired(err))
+}
+
+func TestOpenSaves_GetRecordCacheOutage(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	rec := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+	// Point the server at a cache that is down.
+	down := miniredis.RunT(t)
+	down.Close()
+	cacheConfig := &config.CacheConfig{ErrorRateThreshold: 0.5, BackpressureMaxDelay: time.Millisecond}
+	outage := cache.New(redis.NewRedis(down.Addr()), cacheConfig)
+	outage.Codec = server.cacheStore.Codec
+	outage.Guard = cache.NewReadGuard(cacheConfig, redis.IsMiss)
+	server.cacheStore = outage
+
+	// Reads fail open to Datastore.
+	for i := 0; i < 20; i++ {
+		got, err := client.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: store.Key, Key: rec.Key})
+		if assert.NoError(t, err) {
+			assert.Equal(t, rec.Key, got.GetKey())
+		}
+	}
+	assert.True(t, outage.Guard.Engaged())
+	_, err := client.GetStore(ctx, &pb.GetStoreRequest{Key: store.Key})
+	assert.NoError(t, err)
+}
+
+func TestOpenSaves_GetRecordWithFieldMask(t *testing.T) {
+	ctx := context.Background()
+	_, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	storeKey := uuid.NewString()
+	setupTestStore(ctx, t, client, &pb.Store{Key: storeKey})
+
+	record := &pb.Record{
+		Key:          uuid.NewString(),
+		Tags:         []string{"tag1", "tag2"},
+		OwnerId:      "owner",
+		OpaqueString: "Lorem ipsum dolor sit amet, consectetur adipiscing elit,",
+		Properties: map[string]*pb.Property{
+			"prop1": {
+				Type:  pb.Property_INTEGER,
+				Value: &pb.Property_IntegerValue{IntegerValue: -42},
+			},
+		},
+	}
+	setupTestRecord(ctx, t, client, storeKey, record)
+
+	// Tags only, read from Datastore using a projection query.
+	got, err := client.GetRecord(ctx, &pb.GetRecordRequest{
+		StoreKey:  storeKey,
+		Key:       record.Key,
+		Hint:      &pb.Hint{SkipCache: true},
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"tags"}},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, record.Key, got.Key)
+		assert.ElementsMatch(t, record.Tags, got.Tags)
+		assert.Empty(t, got.OwnerId)
+		assert.Empty(t, got.OpaqueString)
+		assert.Empty(t, got.Properties)
+		assert.Nil(t, got.CreatedAt)
+	}
+
+	// Full fetch without a field mask.
+	got, err = client.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: storeKey, Key: record.Key})
+	if assert.NoError(t, err) {
+		assertEqualRecord(t, record, got)
+	}
+
+	// Unknown field.
+	got, err = client.GetRecord(ctx, &pb.GetRecordRequest{
+		StoreKey:  storeKey,
+		Key:       record.Key,
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"no_such_field"}},
+	})
+	assert.Nil(t, got)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestOpenSaves_UpdateRecordSimple(t *testing.T) {
 	ctx := context.Background()
 	_, listener := getOpenSavesServer(ctx, t, "gcp")
@@ -916,6 +1090,617 @@ func TestOpenSaves_ExternalBlobSimple(t *testing.T) {
 	verifyBlob(ctx, t, client, store.Key, record.Key, make([]byte, 0))
 }
 
+func TestOpenSaves_ZeroByteInlineBlob(t *testing.T) {
+	ctx := context.Background()
+	_, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+	// Replace an external blob to make sure the empty blob isn't ignored.
+	createBlob(ctx, t, client, store.Key, record.Key, make([]byte, 16))
+	createBlob(ctx, t, client, store.Key, record.Key, []byte{})
+	verifyBlob(ctx, t, client, store.Key, record.Key, []byte{})
+
+	gbc, err := client.GetBlob(ctx, &pb.GetBlobRequest{StoreKey: store.Key, RecordKey: record.Key})
+	require.NoError(t, err)
+	res, err := gbc.Recv()
+	require.NoError(t, err)
+	digest := checksums.NewDigest()
+	checksumstest.AssertProtoEqual(t, digest.Checksums(), res.GetMetadata())
+
+	_, err = client.DeleteBlob(ctx, &pb.DeleteBlobRequest{StoreKey: store.Key, RecordKey: record.Key})
+	assert.NoError(t, err)
+}
+
+func TestOpenSaves_ZeroByteExternalBlob(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.MaxInlineSize = -1
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+	createBlob(ctx, t, client, store.Key, record.Key, []byte{})
+
+	blob, err := server.metaDB.GetCurrentBlobRef(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusReady, blob.Status)
+	assert.Zero(t, blob.Size)
+	digest := checksums.NewDigest()
+	assert.Equal(t, digest.Checksums(), blob.Checksums)
+	content, err := server.blobStore.Get(ctx, blob.ObjectPath())
+	if assert.NoError(t, err, "the empty object should be created") {
+		assert.Empty(t, content)
+	}
+
+	verifyBlob(ctx, t, client, store.Key, record.Key, []byte{})
+}
+
+// getBlobFrom reads the content of the blob from offset with GetBlob.
+func getBlobFrom(ctx context.Context, client pb.OpenSavesClient, storeKey, recordKey string, offset int64) ([]byte, error) {
+	gbc, err := client.GetBlob(ctx, &pb.GetBlobRequest{StoreKey: storeKey, RecordKey: recordKey, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	var content []byte
+	for {
+		res, err := gbc.Recv()
+		if err == io.EOF {
+			return content, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, res.GetContent()...)
+	}
+}
+
+func TestOpenSaves_GetBlobOffset(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.MaxInlineSize = 16
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+
+	for name, content := range map[string][]byte{
+		"inline":   []byte("inline content"),
+		"external": []byte("external content larger than the inline size"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+			createBlob(ctx, t, client, store.Key, record.Key, content)
+
+			for _, offset := range []int64{0, 5, int64(len(content))} {
+				got, err := getBlobFrom(ctx, client, store.Key, record.Key, offset)
+				if assert.NoError(t, err, "offset = %v", offset) {
+					assert.Equal(t, string(content[offset:]), string(got), "offset = %v", offset)
+				}
+			}
+			for _, offset := range []int64{-1, int64(len(content)) + 1} {
+				_, err := getBlobFrom(ctx, client, store.Key, record.Key, offset)
+				assert.Equal(t, codes.InvalidArgument, status.Code(err), "offset = %v", offset)
+			}
+		})
+	}
+}
+
+func TestOpenSaves_GetBlobVerifiedRead(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.MaxInlineSize = 16
+	server.BlobConfig.VerifiedReadMaxSize = 1024
+	replica, err := blob.NewBlobGCP(ctx, testBucket)
+	require.NoError(t, err)
+	t.Cleanup(func() { replica.Close() })
+	server.replicaStore = replica
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	content := []byte("external content larger than the inline size")
+	createBlob(ctx, t, client, store.Key, record.Key, content)
+
+	rr, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	blobref, err := server.metaDB.GetBlobRef(ctx, rr.ExternalBlob)
+	require.NoError(t, err)
+	corrupt := []byte(strings.ToUpper(string(content)))
+	require.NoError(t, server.blobStore.Put(ctx, blobref.ObjectPath(), corrupt))
+	require.NoError(t, replica.Put(ctx, blobref.ObjectPath(), content))
+
+	// The corrupt object is healed from the replica.
+	got, err := getBlobFrom(ctx, client, store.Key, record.Key, 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(content), string(got))
+	}
+	healed, err := server.blobStore.Get(ctx, blobref.ObjectPath())
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(content), string(healed))
+	}
+
+	// DataLoss is returned if no good copy is found.
+	require.NoError(t, server.blobStore.Put(ctx, blobref.ObjectPath(), corrupt))
+	require.NoError(t, replica.Put(ctx, blobref.ObjectPath(), corrupt))
+	_, err = getBlobFrom(ctx, client, store.Key, record.Key, 0)
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+}
+
+func TestOpenSaves_ExternalBlobTTL(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+
+	testCases := []struct {
+		name       string
+		defaultTTL time.Duration
+		ttl        time.Duration
+		want       time.Duration
+	}{
+		{"InheritStoreDefault", time.Hour, 0, time.Hour},
+		{"OverrideStoreDefault", time.Hour, 10 * time.Minute, 10 * time.Minute},
+		{"NoDefault", 0, 0, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &pb.Store{Key: uuid.NewString()}
+			if tc.defaultTTL > 0 {
+				store.DefaultBlobTtl = durationpb.New(tc.defaultTTL)
+			}
+			setupTestStore(ctx, t, client, store)
+			record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+			testBlob := make([]byte, server.MaxInlineSize+1)
+			meta := &pb.BlobMetadata{
+				StoreKey:  store.Key,
+				RecordKey: record.Key,
+				Size:      int64(len(testBlob)),
+			}
+			if tc.ttl > 0 {
+				meta.Ttl = durationpb.New(tc.ttl)
+			}
+			cbc, err := client.CreateBlob(ctx)
+			require.NoError(t, err)
+			require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+				Request: &pb.CreateBlobRequest_Metadata{Metadata: meta},
+			}))
+			require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+				Request: &pb.CreateBlobRequest_Content{Content: testBlob},
+			}))
+			_, err = cbc.CloseAndRecv()
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				client.DeleteBlob(ctx, &pb.DeleteBlobRequest{StoreKey: store.Key, RecordKey: record.Key})
+			})
+
+			r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+			require.NoError(t, err)
+			blob, err := server.metaDB.GetBlobRef(ctx, r.ExternalBlob)
+			require.NoError(t, err)
+			if tc.want == 0 {
+				assert.True(t, blob.ExpiresAt.IsZero())
+			} else {
+				assert.Equal(t, blob.Timestamps.CreatedAt.Add(tc.want), blob.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestOpenSaves_CreateBlobKMSKeyName(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	const kmsKeyName = "projects/p/locations/l/keyRings/r/cryptoKeys/k"
+	gcs, err := blob.NewBlobGCPWithKMSKey(ctx, testBucket, kmsKeyName)
+	require.NoError(t, err)
+	t.Cleanup(func() { gcs.Close() })
+	server.blobStore = gcs
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	content := make([]byte, server.MaxInlineSize+1)
+
+	createBlob(ctx, t, client, store.Key, record.Key, content)
+	verifyBlob(ctx, t, client, store.Key, record.Key, content)
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	b, err := server.metaDB.GetBlobRef(ctx, r.ExternalBlob)
+	require.NoError(t, err)
+	assert.Equal(t, kmsKeyName, b.KMSKeyName)
+
+	chunked, err := client.CreateChunkedBlob(ctx, &pb.CreateChunkedBlobRequest{StoreKey: store.Key, RecordKey: record.Key})
+	require.NoError(t, err)
+	b, err = server.metaDB.GetBlobRef(ctx, uuid.MustParse(chunked.GetSessionId()))
+	require.NoError(t, err)
+	assert.Equal(t, kmsKeyName, b.KMSKeyName)
+}
+
+func TestOpenSaves_CreateBlobObjectHeaders(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	content := make([]byte, server.MaxInlineSize+1)
+	const (
+		contentDisposition = `attachment; filename="save.dat"`
+		cacheControl       = "private, max-age=3600"
+	)
+
+	cbc, err := client.CreateBlob(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+		Request: &pb.CreateBlobRequest_Metadata{
+			Metadata: &pb.BlobMetadata{
+				StoreKey:           store.Key,
+				RecordKey:          record.Key,
+				Size:               int64(len(content)),
+				ContentDisposition: contentDisposition,
+				CacheControl:       cacheControl,
+			},
+		},
+	}))
+	require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+		Request: &pb.CreateBlobRequest_Content{Content: content},
+	}))
+	meta, err := cbc.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, contentDisposition, meta.GetContentDisposition())
+	assert.Equal(t, cacheControl, meta.GetCacheControl())
+	verifyBlob(ctx, t, client, store.Key, record.Key, content)
+
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	b, err := server.metaDB.GetBlobRef(ctx, r.ExternalBlob)
+	require.NoError(t, err)
+	assert.Equal(t, contentDisposition, b.ContentDisposition)
+	assert.Equal(t, cacheControl, b.CacheControl)
+}
+
+func TestOpenSaves_CreateBlobContentType(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.ContentTypes = map[string]string{".sav": "application/x-game-save"}
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	content := make([]byte, server.MaxInlineSize+1)
+
+	testCases := []struct {
+		name        string
+		filename    string
+		contentType string
+		want        string
+	}{
+		{"known extension", "thumbnail.png", "", "image/png"},
+		{"configured extension", "slot1.sav", "", "application/x-game-save"},
+		{"explicit type", "thumbnail.png", "image/webp", "image/webp"},
+		{"unknown extension", "save.unknownext", "", "application/octet-stream"},
+		{"no filename", "", "", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+			cbc, err := client.CreateBlob(ctx)
+			require.NoError(t, err)
+			require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+				Request: &pb.CreateBlobRequest_Metadata{
+					Metadata: &pb.BlobMetadata{
+						StoreKey:    store.Key,
+						RecordKey:   record.Key,
+						Size:        int64(len(content)),
+						Filename:    tc.filename,
+						ContentType: tc.contentType,
+					},
+				},
+			}))
+			require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+				Request: &pb.CreateBlobRequest_Content{Content: content},
+			}))
+			meta, err := cbc.CloseAndRecv()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, meta.GetContentType())
+
+			r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+			require.NoError(t, err)
+			b, err := server.metaDB.GetBlobRef(ctx, r.ExternalBlob)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, b.ContentType)
+		})
+	}
+}
+
+func TestOpenSaves_CheckBlobExists(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+	testBlob := make([]byte, server.MaxInlineSize+1)
+	for i := range testBlob {
+		testBlob[i] = byte(i % 256)
+	}
+	createBlob(ctx, t, client, store.Key, record.Key, testBlob)
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	digest := checksums.NewDigest()
+	digest.Write(testBlob)
+	md5 := digest.Checksums().MD5
+
+	testCases := []struct {
+		name string
+		req  *pb.CheckBlobExistsRequest
+		want string
+	}{
+		{"Match", &pb.CheckBlobExistsRequest{StoreKey: store.Key, Md5: md5, Size: int64(len(testBlob))}, r.ExternalBlob.String()},
+		{"DifferentMD5", &pb.CheckBlobExistsRequest{StoreKey: store.Key, Md5: make([]byte, 16), Size: int64(len(testBlob))}, ""},
+		{"SameMD5DifferentSize", &pb.CheckBlobExistsRequest{StoreKey: store.Key, Md5: md5, Size: int64(len(testBlob)) + 1}, ""},
+		{"DifferentStore", &pb.CheckBlobExistsRequest{StoreKey: uuid.NewString(), Md5: md5, Size: int64(len(testBlob))}, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := client.CheckBlobExists(ctx, tc.req)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.want, res.GetBlobKey())
+			}
+		})
+	}
+
+	_, err = client.CheckBlobExists(ctx, &pb.CheckBlobExistsRequest{StoreKey: store.Key, Md5: md5[:4], Size: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestOpenSaves_CheckBlobExistsContentHash(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.hashAlgorithm = checksums.HashAlgorithmSHA256
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+	testBlob := make([]byte, server.MaxInlineSize+1)
+	for i := range testBlob {
+		testBlob[i] = byte(i % 256)
+	}
+	createBlob(ctx, t, client, store.Key, record.Key, testBlob)
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	b, err := server.metaDB.GetBlobRef(ctx, r.ExternalBlob)
+	require.NoError(t, err)
+	assert.Equal(t, checksums.HashAlgorithmSHA256, b.ContentHashAlgorithm)
+	sha := sha256.Sum256(testBlob)
+	assert.Equal(t, sha[:], b.ContentHash)
+	md5Sum := md5.Sum(testBlob)
+
+	testCases := []struct {
+		name string
+		req  *pb.CheckBlobExistsRequest
+		want string
+	}{
+		{"SHA256Match", &pb.CheckBlobExistsRequest{StoreKey: store.Key, ContentHash: sha[:],
+			ContentHashAlgorithm: "sha256", Size: int64(len(testBlob))}, r.ExternalBlob.String()},
+		{"DifferentSHA256", &pb.CheckBlobExistsRequest{StoreKey: store.Key, ContentHash: make([]byte, 32),
+			ContentHashAlgorithm: "sha256", Size: int64(len(testBlob))}, ""},
+		{"MD5ContentHash", &pb.CheckBlobExistsRequest{StoreKey: store.Key, ContentHash: md5Sum[:],
+			Size: int64(len(testBlob))}, ""},
+		// The MD5 checksum is still available for existing clients.
+		{"MD5Checksum", &pb.CheckBlobExistsRequest{StoreKey: store.Key, Md5: md5Sum[:], Size: int64(len(testBlob))}, r.ExternalBlob.String()},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := client.CheckBlobExists(ctx, tc.req)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.want, res.GetBlobKey())
+			}
+		})
+	}
+
+	_, err = client.CheckBlobExists(ctx, &pb.CheckBlobExistsRequest{StoreKey: store.Key, ContentHash: sha[:16],
+		ContentHashAlgorithm: "sha256", Size: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.CheckBlobExists(ctx, &pb.CheckBlobExistsRequest{StoreKey: store.Key, ContentHash: sha[:],
+		ContentHashAlgorithm: "sha1", Size: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestOpenSaves_ExternalizeBlob(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	content := []byte("inline content")
+	createBlob(ctx, t, client, store.Key, record.Key, content)
+	t.Cleanup(func() { cleanupBlobs(ctx, t, store.Key, record.Key) })
+
+	wantKey := blobref.DeriveKey(store.Key, record.Key, "save.dat")
+	res, err := client.ExternalizeBlob(ctx, &pb.ExternalizeBlobRequest{
+		StoreKey: store.Key, RecordKey: record.Key, Name: "save.dat",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, wantKey.String(), res.GetBlobKey())
+	assert.Equal(t, int64(len(content)), res.GetMetadata().GetSize())
+
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	assert.Equal(t, wantKey, r.ExternalBlob)
+	assert.Empty(t, r.Blob)
+	verifyBlob(ctx, t, client, store.Key, record.Key, content)
+
+	// The record no longer has an inline blob.
+	_, err = client.ExternalizeBlob(ctx, &pb.ExternalizeBlobRequest{StoreKey: store.Key, RecordKey: record.Key, Name: "other.dat"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Another record can't use the same key.
+	other := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	createBlob(ctx, t, client, store.Key, other.Key, content)
+	_, err = client.ExternalizeBlob(ctx, &pb.ExternalizeBlobRequest{
+		StoreKey: store.Key, RecordKey: other.Key, BlobKey: wantKey.String(),
+	})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	verifyBlob(ctx, t, client, store.Key, other.Key, content)
+
+	_, err = client.ExternalizeBlob(ctx, &pb.ExternalizeBlobRequest{StoreKey: store.Key, RecordKey: other.Key, BlobKey: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestOpenSaves_CheckBlobExistsEventualConsistency(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.DedupEventualConsistency = true
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+
+	testBlob := make([]byte, server.MaxInlineSize+1)
+	createBlob(ctx, t, client, store.Key, record.Key, testBlob)
+	r, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+	require.NoError(t, err)
+	digest := checksums.NewDigest()
+	digest.Write(testBlob)
+
+	// The blob may not be visible yet, but a match must be the uploaded blob.
+	res, err := client.CheckBlobExists(ctx, &pb.CheckBlobExistsRequest{
+		StoreKey: store.Key, Md5: digest.Checksums().MD5, Size: int64(len(testBlob)),
+	})
+	if assert.NoError(t, err) && res.GetBlobKey() != "" {
+		assert.Equal(t, r.ExternalBlob.String(), res.GetBlobKey())
+	}
+	res, err = client.CheckBlobExists(ctx, &pb.CheckBlobExistsRequest{
+		StoreKey: store.Key, Md5: make([]byte, 16), Size: int64(len(testBlob)),
+	})
+	if assert.NoError(t, err) {
+		assert.Empty(t, res.GetBlobKey())
+	}
+}
+
+func TestOpenSaves_DedupConsistency(t *testing.T) {
+	s := new(openSavesServer)
+	assert.Equal(t, metadb.ConsistencyStrong, s.dedupConsistency())
+	s.BlobConfig.DedupEventualConsistency = true
+	assert.Equal(t, metadb.ConsistencyEventual, s.dedupConsistency())
+}
+
+func TestOpenSaves_CreateBlobInFlightLimit(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.uploadBytes = newByteLimiter(100)
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	content := make([]byte, 50)
+
+	// Another upload holds most of the budget.
+	release, err := server.uploadBytes.acquire(60)
+	require.NoError(t, err)
+	cbc, err := client.CreateBlob(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+		Request: &pb.CreateBlobRequest_Metadata{
+			Metadata: &pb.BlobMetadata{StoreKey: store.Key, RecordKey: record.Key, Size: int64(len(content))},
+		},
+	}))
+	_, err = cbc.CloseAndRecv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Finishing the other upload frees the budget for the next one.
+	release()
+	createBlob(ctx, t, client, store.Key, record.Key, content)
+	verifyBlob(ctx, t, client, store.Key, record.Key, content)
+
+	// The completed upload releases its bytes too, possibly after the client
+	// receives the response.
+	assert.Eventually(t, func() bool {
+		release, err := server.uploadBytes.acquire(100)
+		if err != nil {
+			return false
+		}
+		release()
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
+// sendBlob uploads fragments to the record with size in the metadata, and
+// returns the error of the upload.
+func sendBlob(ctx context.Context, t *testing.T, client pb.OpenSavesClient,
+	storeKey, recordKey string, size int64, fragments ...[]byte) error {
+	t.Helper()
+	cbc, err := client.CreateBlob(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cbc.Send(&pb.CreateBlobRequest{
+		Request: &pb.CreateBlobRequest_Metadata{
+			Metadata: &pb.BlobMetadata{StoreKey: storeKey, RecordKey: recordKey, Size: size},
+		},
+	}))
+	for _, f := range fragments {
+		// Send returns io.EOF once the server aborts the stream, and the
+		// error is returned by CloseAndRecv.
+		if err := cbc.Send(&pb.CreateBlobRequest{Request: &pb.CreateBlobRequest_Content{Content: f}}); err != nil {
+			break
+		}
+	}
+	_, err = cbc.CloseAndRecv()
+	return err
+}
+
+func TestOpenSaves_CreateBlobContentLength(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.MaxInlineSize = 16
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+
+	for name, tc := range map[string]struct {
+		size      int64
+		underCode codes.Code
+	}{
+		"inline":   {10, codes.InvalidArgument},
+		"external": {40, codes.DataLoss},
+	} {
+		t.Run(name, func(t *testing.T) {
+			size := tc.size
+			content := make([]byte, size)
+			half := content[:size/2]
+
+			record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+			assert.NoError(t, sendBlob(ctx, t, client, store.Key, record.Key, size, half, content[size/2:]))
+			verifyBlob(ctx, t, client, store.Key, record.Key, content)
+			t.Cleanup(func() {
+				client.DeleteBlob(ctx, &pb.DeleteBlobRequest{StoreKey: store.Key, RecordKey: record.Key})
+			})
+
+			// Over delivery is rejected on the fragment that exceeds the size.
+			record = setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+			err := sendBlob(ctx, t, client, store.Key, record.Key, size, half, half, []byte("overflow"))
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			_, err = server.metaDB.GetCurrentBlobRef(ctx, store.Key, record.Key)
+			assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			rec, err := server.metaDB.GetRecord(ctx, store.Key, record.Key)
+			if assert.NoError(t, err) {
+				assert.Empty(t, rec.Blob)
+				assert.Zero(t, rec.BlobSize)
+			}
+
+			// Under delivery fails at the end of the stream.
+			err = sendBlob(ctx, t, client, store.Key, record.Key, size, half)
+			assert.Equal(t, tc.underCode, status.Code(err))
+			_, err = server.metaDB.GetCurrentBlobRef(ctx, store.Key, record.Key)
+			assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		})
+	}
+}
+
 func TestOpenSaves_QueryRecords_EqualityFilter(t *testing.T) {
 	ctx := context.Background()
 	_, listener := getOpenSavesServer(ctx, t, "gcp")
@@ -2158,6 +2943,191 @@ func TestOpenSaves_UploadChunkedBlobWithUpdateRecord(t *testing.T) {
 	verifyBlob(ctx, t, client, store.Key, record.Key, make([]byte, 0))
 }
 
+// uploadChunkWithCRC32C uploads content as chunk number with crc32c as the
+// expected checksum and returns the error of the upload.
+func uploadChunkWithCRC32C(ctx context.Context, t *testing.T, client pb.OpenSavesClient,
+	sessionId string, number int64, content []byte, crc32c uint32) error {
+	t.Helper()
+	ucc, err := client.UploadChunk(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ucc.Send(&pb.UploadChunkRequest{
+		Request: &pb.UploadChunkRequest_Metadata{
+			Metadata: &pb.ChunkMetadata{
+				SessionId: sessionId,
+				Number:    number,
+				Crc32C:    crc32c,
+				HasCrc32C: true,
+			},
+		},
+	}))
+	require.NoError(t, ucc.Send(&pb.UploadChunkRequest{
+		Request: &pb.UploadChunkRequest_Content{Content: content},
+	}))
+	_, err = ucc.CloseAndRecv()
+	return err
+}
+
+// setupChunkCRC32CTest creates a chunked upload session of chunkCount chunks
+// and returns the store, record, and session ID.
+func setupChunkCRC32CTest(ctx context.Context, t *testing.T, client pb.OpenSavesClient, chunkCount int64) (*pb.Store, *pb.Record, string) {
+	t.Helper()
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	record := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString()})
+	res, err := client.CreateChunkedBlob(ctx, &pb.CreateChunkedBlobRequest{
+		StoreKey:   store.Key,
+		RecordKey:  record.Key,
+		ChunkCount: chunkCount,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		client.DeleteBlob(ctx, &pb.DeleteBlobRequest{StoreKey: store.Key, RecordKey: record.Key})
+	})
+	return store, record, res.SessionId
+}
+
+func TestOpenSaves_UploadChunkCRC32CValid(t *testing.T) {
+	ctx := context.Background()
+	_, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store, record, sessionId := setupChunkCRC32CTest(ctx, t, client, 1)
+
+	content := []byte("valid chunk")
+	assert.NoError(t, uploadChunkWithCRC32C(ctx, t, client, sessionId, 0, content, crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli))))
+	_, err := client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: sessionId})
+	require.NoError(t, err)
+	verifyChunk(ctx, t, client, store.Key, record.Key, sessionId, 0, content)
+}
+
+func TestOpenSaves_UploadChunkCRC32CMismatch(t *testing.T) {
+	ctx := context.Background()
+	_, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store, record, sessionId := setupChunkCRC32CTest(ctx, t, client, 2)
+
+	content := []byte("chunk content")
+	table := crc32.MakeTable(crc32.Castagnoli)
+	uploadChunk(ctx, t, client, sessionId, 0, content)
+	err := uploadChunkWithCRC32C(ctx, t, client, sessionId, 1, content, crc32.Checksum(content, table)+1)
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+
+	// The failed chunk doesn't complete the upload.
+	_, err = client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: sessionId})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// A corrupt retry of an uploaded chunk doesn't replace it.
+	err = uploadChunkWithCRC32C(ctx, t, client, sessionId, 0, []byte("corrupt"), crc32.Checksum(content, table))
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+	uploadChunk(ctx, t, client, sessionId, 1, content)
+	_, err = client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: sessionId})
+	require.NoError(t, err)
+	verifyChunk(ctx, t, client, store.Key, record.Key, sessionId, 0, content)
+}
+
+func TestOpenSaves_UploadChunkCRC32CReupload(t *testing.T) {
+	ctx := context.Background()
+	_, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store, record, sessionId := setupChunkCRC32CTest(ctx, t, client, 2)
+
+	chunks := [][]byte{[]byte("first chunk"), []byte("second chunk")}
+	table := crc32.MakeTable(crc32.Castagnoli)
+	uploadChunk(ctx, t, client, sessionId, 0, chunks[0])
+	err := uploadChunkWithCRC32C(ctx, t, client, sessionId, 1, []byte("corrupt"), crc32.Checksum(chunks[1], table))
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+
+	// Only the failed chunk is uploaded again.
+	assert.NoError(t, uploadChunkWithCRC32C(ctx, t, client, sessionId, 1, chunks[1], crc32.Checksum(chunks[1], table)))
+	meta, err := client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: sessionId})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(chunks[0])+len(chunks[1])), meta.Size)
+	for i, c := range chunks {
+		verifyChunk(ctx, t, client, store.Key, record.Key, sessionId, int64(i), c)
+	}
+}
+
+// uploadChunkWithRange uploads content as chunk number with the declared
+// byte range and returns the error of the upload.
+func uploadChunkWithRange(ctx context.Context, t *testing.T, client pb.OpenSavesClient,
+	sessionId string, number int64, content []byte, offset, length int64) error {
+	t.Helper()
+	ucc, err := client.UploadChunk(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ucc.Send(&pb.UploadChunkRequest{
+		Request: &pb.UploadChunkRequest_Metadata{
+			Metadata: &pb.ChunkMetadata{
+				SessionId: sessionId,
+				Number:    number,
+				Offset:    offset,
+				Length:    length,
+			},
+		},
+	}))
+	require.NoError(t, ucc.Send(&pb.UploadChunkRequest{
+		Request: &pb.UploadChunkRequest_Content{Content: content},
+	}))
+	_, err = ucc.CloseAndRecv()
+	return err
+}
+
+func TestOpenSaves_UploadChunkRanges(t *testing.T) {
+	ctx := context.Background()
+	_, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store, record, sessionId := setupChunkCRC32CTest(ctx, t, client, 2)
+
+	chunks := [][]byte{[]byte("first chunk"), []byte("second chunk")}
+	first, second := int64(len(chunks[0])), int64(len(chunks[1]))
+	err := uploadChunkWithRange(ctx, t, client, sessionId, 0, chunks[0], 0, first+1)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "the content must match the declared length")
+
+	// The second chunk overlaps the first one.
+	require.NoError(t, uploadChunkWithRange(ctx, t, client, sessionId, 0, chunks[0], 0, first))
+	require.NoError(t, uploadChunkWithRange(ctx, t, client, sessionId, 1, chunks[1], first-1, second))
+	_, err = client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: sessionId})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	require.NoError(t, uploadChunkWithRange(ctx, t, client, sessionId, 1, chunks[1], first, second))
+	meta, err := client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: sessionId})
+	require.NoError(t, err)
+	assert.Equal(t, first+second, meta.Size)
+	for i, c := range chunks {
+		verifyChunk(ctx, t, client, store.Key, record.Key, sessionId, int64(i), c)
+	}
+}
+
+func TestOpenSaves_UploadChunkMinSize(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.MinChunkSize = 8
+	_, client := getTestClient(ctx, t, listener)
+	store, record, sessionId := setupChunkCRC32CTest(ctx, t, client, 3)
+
+	// An undersized non-final chunk is rejected up front if its length is
+	// declared, and after it is received otherwise.
+	err := uploadChunkWithRange(ctx, t, client, sessionId, 1, []byte("small"), 8, 5)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = uploadChunkWithRange(ctx, t, client, sessionId, 1, []byte("small"), 0, 0)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	chunks := [][]byte{[]byte("chunk #0"), []byte("chunk #1"), []byte("end")}
+	for i, c := range chunks {
+		require.NoError(t, uploadChunkWithRange(ctx, t, client, sessionId, int64(i), c, 0, 0), "chunk %d", i)
+	}
+	meta, err := client.CommitChunkedUpload(ctx, &pb.CommitChunkedUploadRequest{SessionId: sessionId})
+	require.NoError(t, err)
+	assert.Equal(t, int64(19), meta.Size)
+	for i, c := range chunks {
+		verifyChunk(ctx, t, client, store.Key, record.Key, sessionId, int64(i), c)
+	}
+
+	// The planned chunk size is validated when the upload starts.
+	_, err = client.CreateChunkedBlob(ctx, &pb.CreateChunkedBlobRequest{
+		StoreKey: store.Key, RecordKey: record.Key, ChunkSize: 4, ChunkCount: 3,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestOpenSaves_LongOpaqueStrings(t *testing.T) {
 	t.Parallel()
 
@@ -2292,3 +3262,102 @@ func TestOpenSaves_SignUrl(t *testing.T) {
 	require.NotNil(t, resp)
 	require.Len(t, resp.ChunkUrls, 1)
 }
+
+func TestNewBlobStore_ShardBuckets(t *testing.T) {
+	ctx := context.Background()
+	dirs := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+	cfg := &config.ServiceConfig{BlobConfig: config.BlobConfig{
+		StoreKind:    blob.KindFilesystem,
+		StoreRootDir: dirs[0],
+		ShardBuckets: dirs[1:],
+	}}
+	bs, err := newBlobStore(ctx, cfg, blob.SyncNone)
+	require.NoError(t, err)
+	multi, ok := bs.(*blob.MultiBucketBlobStore)
+	require.True(t, ok, "newBlobStore() must return a MultiBucketBlobStore with shard buckets")
+
+	dirStores := make(map[string]blob.BlobStore)
+	for _, d := range dirs {
+		dirStores[d], err = blob.NewBlobStore(ctx, blob.KindFilesystem, blob.BlobStoreConfig{RootDir: d})
+		require.NoError(t, err)
+	}
+	routed := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		b := blobref.NewBlobRef(1, fmt.Sprintf("store-%d", i), "record")
+		b.ShardedByStore = true
+		bucket := multi.BucketFor(b.ObjectPath())
+		routed[bucket] = true
+		require.NoError(t, bs.Put(ctx, b.ObjectPath(), []byte("data")))
+		for d, ds := range dirStores {
+			_, err := ds.Get(ctx, b.ObjectPath())
+			assert.Equal(t, d == bucket, err == nil, "object %v in %v", b.ObjectPath(), d)
+		}
+	}
+	assert.Greater(t, len(routed), 1, "blobs must be spread across buckets")
+
+	// Unsharded objects stay in the primary bucket.
+	legacy := blobref.NewBlobRef(1, "store", "record")
+	assert.Equal(t, dirs[0], multi.BucketFor(legacy.ObjectPath()))
+}
+
+func TestOpenSaves_RecordLeaseHeld(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	created := setupTestRecord(ctx, t, client, store.Key, &pb.Record{
+		Key:        uuid.NewString(),
+		Properties: map[string]*pb.Property{"counter": record.NewIntegerPropertyProto(0)},
+	})
+	metaDB := server.metaDB.(*metadb.MetaDB)
+	leaseID, err := metaDB.AcquireRecordLease(ctx, store.Key, created.Key, time.Minute)
+	require.NoError(t, err)
+
+	_, err = client.UpdateRecord(ctx, &pb.UpdateRecordRequest{
+		StoreKey: store.Key,
+		Record:   &pb.Record{Key: created.Key, OpaqueString: "updated"},
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = client.AtomicInc(ctx, &pb.AtomicIncRequest{
+		StoreKey: store.Key, RecordKey: created.Key, PropertyName: "counter", UpperBound: 10,
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = client.DeleteRecord(ctx, &pb.DeleteRecordRequest{StoreKey: store.Key, Key: created.Key})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	require.NoError(t, metaDB.ReleaseRecordLease(ctx, store.Key, created.Key, leaseID))
+	_, err = client.AtomicInc(ctx, &pb.AtomicIncRequest{
+		StoreKey: store.Key, RecordKey: created.Key, PropertyName: "counter", UpperBound: 10,
+	})
+	assert.NoError(t, err)
+}
+
+func TestOpenSaves_DeleteBlobOwner(t *testing.T) {
+	ctx := context.Background()
+	server, listener := getOpenSavesServer(ctx, t, "gcp")
+	server.BlobConfig.MaxInlineSize = -1
+	_, client := getTestClient(ctx, t, listener)
+	store := &pb.Store{Key: uuid.NewString()}
+	setupTestStore(ctx, t, client, store)
+	created := setupTestRecord(ctx, t, client, store.Key, &pb.Record{Key: uuid.NewString(), OwnerId: "player"})
+	createBlob(ctx, t, client, store.Key, created.Key, []byte("content"))
+	b, err := server.metaDB.GetCurrentBlobRef(ctx, store.Key, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "player", b.OwnerID)
+
+	// The handler is called directly with the identity that the
+	// Authenticator of the server would set.
+	req := &pb.DeleteBlobRequest{StoreKey: store.Key, RecordKey: created.Key}
+	_, err = server.DeleteBlob(withCallerIdentity(ctx, "someone else"), req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	verifyBlob(ctx, t, client, store.Key, created.Key, []byte("content"))
+
+	_, err = server.DeleteBlob(withCallerIdentity(ctx, "player"), req)
+	require.NoError(t, err)
+	_, err = server.metaDB.GetBlobRef(ctx, b.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	exists, err := blob.Exists(ctx, server.blobStore, b.ObjectPath())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -24,9 +24,9 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/googleforgames/open-saves/internal/pkg/config"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb"
 
 	pb "github.com/googleforgames/open-saves/api"
 	log "github.com/sirupsen/logrus"
@@ -40,10 +40,60 @@ const (
 	serviceName = "grpc.health.v1.opensaves"
 )
 
+// RunOption is an option of Run.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	authenticator Authenticator
+	authorizer    Authorizer
+	auditSink     metadb.AuditSink
+	grpcOptions   []grpc.ServerOption
+}
+
+// WithAuthenticator makes the server authenticate the caller of every request
+// with authenticate, and reject the request with Unauthenticated before the
+// handler runs if it returns an error. The identity is passed to the
+// Authorizer and recorded in audit entries. See TLSClientIdentity.
+func WithAuthenticator(authenticate Authenticator) RunOption {
+	return func(o *runOptions) {
+		o.authenticator = authenticate
+	}
+}
+
+// WithAuthorizer makes the server authorize every request with authorize,
+// and reject the request with PermissionDenied before the handler runs if it
+// returns an error.
+func WithAuthorizer(authorize Authorizer) RunOption {
+	return func(o *runOptions) {
+		o.authorizer = authorize
+	}
+}
+
+// WithAuditSink makes the server send an audit entry of every record mutation
+// to sink, instead of the sink chosen by the EnableAuditLog configuration.
+func WithAuditSink(sink metadb.AuditSink) RunOption {
+	return func(o *runOptions) {
+		o.auditSink = sink
+	}
+}
+
+// WithGRPCServerOptions adds opts to the options of the gRPC server, such as
+// grpc.Creds to serve with TLS.
+func WithGRPCServerOptions(opts ...grpc.ServerOption) RunOption {
+	return func(o *runOptions) {
+		o.grpcOptions = append(o.grpcOptions, opts...)
+	}
+}
+
 // Run starts the Open Saves gRPC service.
-func Run(ctx context.Context, network string, cfg *config.ServiceConfig) error {
+func Run(ctx context.Context, network string, cfg *config.ServiceConfig, opts ...RunOption) error {
 	log.Infof("starting server on %s %s", network, cfg.ServerConfig.Address)
 
+	var o runOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	lis, err := net.Listen(network, cfg.ServerConfig.Address)
 	if err != nil {
 		return err
@@ -61,14 +111,23 @@ func Run(ctx context.Context, network string, cfg *config.ServiceConfig) error {
 			Timeout:               cfg.GRPCServerConfig.Timeout,
 		}),
 	}
+	grpcOptions = append(grpcOptions, o.grpcOptions...)
+
+	server, err := newOpenSavesServer(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if o.auditSink != nil {
+		server.auditSink = o.auditSink
+	}
 
 	var tracer *trace.TracerProvider
 	if cfg.EnableTrace {
 		log.Printf("Enabling CloudTrace exporter with sample rate: %f\n", cfg.ServerConfig.TraceSampleRate)
 
 		grpcOptions = append(grpcOptions,
-			grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
-			grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()))
+			grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()))
 
 		tracer, err = tracing.InitTracer(cfg.ServerConfig.TraceSampleRate, cfg.ServerConfig.EnableGRPCCollector, cfg.ServerConfig.EnableHTTPCollector, cfg.TraceServiceName)
 		if err != nil {
@@ -84,15 +143,18 @@ func Run(ctx context.Context, network string, cfg *config.ServiceConfig) error {
 		}
 	}()
 
+	if o.authenticator != nil || o.authorizer != nil {
+		auth := newAuthInterceptor(o.authenticator, o.authorizer, server)
+		grpcOptions = append(grpcOptions,
+			grpc.ChainUnaryInterceptor(auth.Unary()),
+			grpc.ChainStreamInterceptor(auth.Stream()))
+	}
+
 	s := grpc.NewServer(grpcOptions...)
 
 	healthcheck := health.NewServer()
 	healthcheck.SetServingStatus(serviceName, healthgrpc.HealthCheckResponse_SERVING)
 	healthgrpc.RegisterHealthServer(s, healthcheck)
-	server, err := newOpenSavesServer(ctx, cfg)
-	if err != nil {
-		return err
-	}
 	pb.RegisterOpenSavesServer(s, server)
 	reflection.Register(s)
 
@@ -106,8 +168,14 @@ func Run(ctx context.Context, network string, cfg *config.ServiceConfig) error {
 		log.Infoln("set health check to not serving")
 		healthcheck.SetServingStatus(serviceName, healthgrpc.HealthCheckResponse_NOT_SERVING)
 
-		log.Infoln("starting server shutdown grace period")
-		time.Sleep(cfg.ShutdownGracePeriod)
+		// In-flight uploads get the grace period to finish. Drain returns as
+		// soon as they do, and cancels the remaining ones after that.
+		log.Infoln("starting server shutdown grace period, draining in-flight uploads")
+		drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+		if err := server.Drain(drainCtx); err != nil {
+			log.Warnf("failed to drain in-flight uploads: %v", err)
+		}
+		cancel()
 
 		log.Infoln("stopping open saves server gracefully")
 		s.GracefulStop()
@@ -23,9 +23,17 @@ import (
 // Use one of the structs defined in the package.
 // Currently available drivers:
 // - BlobGCP: Google Cloud Storage
+// - BlobFS: local filesystem
+// NewBlobStore returns one of them by kind.
 type BlobStore interface {
 	Put(ctx context.Context, path string, data []byte) error
 
+	// PutIfAbsent writes the content of r to a new object at path unless an object
+	// already exists there. It returns created = false without an error if the
+	// object exists with the same content, ErrObjectPathCollision if it exists with
+	// different content, and the number of bytes written otherwise.
+	PutIfAbsent(ctx context.Context, path string, r io.Reader) (created bool, size int64, err error)
+
 	// NewWriter creates a new object with path and returns an io.WriteCloser
 	// instance for the object.
 	// Make sure to close the writer after all operations to the writer.
@@ -42,5 +50,78 @@ type BlobStore interface {
 	NewRangeReader(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
 	Delete(ctx context.Context, path string) error
 
+	// DeleteMulti deletes objects at paths in parallel with bounded concurrency
+	// and returns the subset of paths that failed to be deleted so that the caller
+	// can retry them. Objects that don't exist are considered deleted.
+	// If any path fails, the returned error is a *bulk.BatchError with the error of
+	// each failed path. Paths not attempted because ctx is done have ctx.Err().
+	DeleteMulti(ctx context.Context, paths []string) (failed []string, err error)
+
+	// List returns paths of all objects that begin with prefix in
+	// lexicographical order. An empty prefix lists all objects.
+	List(ctx context.Context, prefix string) ([]string, error)
+
 	SignUrl(ctx context.Context, key string, ttlInSeconds int64, method string) (string, error)
 }
+
+// KMSKeyNamer is implemented by BlobStores that encrypt new objects with a
+// customer-managed encryption key.
+type KMSKeyNamer interface {
+	KMSKeyName() string
+}
+
+// KMSKeyName returns the name of the key bs encrypts new objects with, or an
+// empty string if bs doesn't implement KMSKeyNamer.
+func KMSKeyName(bs BlobStore) string {
+	if k, ok := bs.(KMSKeyNamer); ok {
+		return k.KMSKeyName()
+	}
+	return ""
+}
+
+// Appender is implemented by BlobStores that can append to an existing object
+// without the caller rewriting it.
+type Appender interface {
+	Append(ctx context.Context, path string, r io.Reader) (int64, error)
+}
+
+// Append appends the content of r to the existing object at path in bs and
+// returns the number of bytes appended. It uses bs.Append if bs implements
+// Appender, and otherwise rewrites the object with r at the end.
+// Returns a NotFound error if the object doesn't exist.
+func Append(ctx context.Context, bs BlobStore, path string, r io.Reader) (int64, error) {
+	if a, ok := bs.(Appender); ok {
+		return a.Append(ctx, path, r)
+	}
+	return rewriteAppend(ctx, bs, path, r, ObjectHeaders{})
+}
+
+// rewriteAppend writes the existing object at path followed by r to path with
+// headers. The existing object is kept if writing fails.
+func rewriteAppend(ctx context.Context, bs BlobStore, path string, r io.Reader, headers ObjectHeaders) (int64, error) {
+	old, err := bs.NewReader(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	defer old.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w, err := NewWriterWithHeaders(ctx, bs, path, headers)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(w, old); err != nil {
+		// Canceling the context aborts the write.
+		cancel()
+		w.Close()
+		return 0, err
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		cancel()
+		w.Close()
+		return 0, err
+	}
+	return n, w.Close()
+}
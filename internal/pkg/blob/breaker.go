@@ -0,0 +1,366 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gocloud.dev/gcerrors"
+)
+
+// ErrCircuitOpen is returned by CircuitBreakerBlobStore without calling the
+// underlying store while the circuit is open.
+var ErrCircuitOpen = errors.New("the blob store circuit breaker is open")
+
+// circuitState is the state of a CircuitBreakerBlobStore.
+type circuitState int
+
+const (
+	// circuitClosed passes all calls through.
+	circuitClosed circuitState = iota
+	// circuitOpen rejects all calls until the cool-down period passes.
+	circuitOpen
+	// circuitHalfOpen passes one probe call through at a time.
+	circuitHalfOpen
+)
+
+// CircuitBreakerBlobStore wraps a BlobStore and stops calling it after a
+// number of consecutive failures, so that an outage of the store doesn't pile
+// up calls waiting for it. The circuit opens after threshold consecutive
+// failures, and calls fail with ErrCircuitOpen for the cool-down period.
+// Then a single probe call is let through: the circuit closes if it succeeds,
+// and opens again for another cool-down period otherwise. A probe that hasn't
+// finished within the cool-down period, such as a writer that is never closed,
+// is abandoned and another probe is let through.
+//
+// The optional interfaces, such as Statter, HeaderWriter, GenerationDeleter,
+// KMSKeyNamer, Appender, ExistenceChecker, and ReadinessChecker, are forwarded
+// to the underlying store, and the package functions fall back the same way as
+// for the underlying store if it doesn't implement them.
+//
+// Errors that the store returns when it is working, such as NotFound and
+// precondition failures, and cancellation by the caller are not failures.
+// Streaming writes are recorded when the writer is closed, and streaming reads
+// when the reader is opened.
+type CircuitBreakerBlobStore struct {
+	BlobStore
+	threshold int
+	coolDown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	probeID   uint64
+	probeTime time.Time
+}
+
+// Assert CircuitBreakerBlobStore implements the BlobStore and optional interfaces.
+var _ BlobStore = new(CircuitBreakerBlobStore)
+var _ Statter = new(CircuitBreakerBlobStore)
+var _ HeaderWriter = new(CircuitBreakerBlobStore)
+var _ GenerationDeleter = new(CircuitBreakerBlobStore)
+var _ KMSKeyNamer = new(CircuitBreakerBlobStore)
+var _ Appender = new(CircuitBreakerBlobStore)
+var _ ExistenceChecker = new(CircuitBreakerBlobStore)
+var _ ReadinessChecker = new(CircuitBreakerBlobStore)
+
+// NewCircuitBreakerBlobStore returns a CircuitBreakerBlobStore in front of bs
+// that opens after threshold consecutive failures for coolDown.
+// A non-positive threshold disables the breaker.
+func NewCircuitBreakerBlobStore(bs BlobStore, threshold int, coolDown time.Duration) *CircuitBreakerBlobStore {
+	return &CircuitBreakerBlobStore{
+		BlobStore: bs,
+		threshold: threshold,
+		coolDown:  coolDown,
+		now:       time.Now,
+	}
+}
+
+// isCircuitFailure reports whether err indicates that the store is not working.
+func isCircuitFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrObjectPathCollision) || errors.Is(err, ErrGenerationMismatch) ||
+		errors.Is(err, ErrObjectNotFound) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	switch gcerrors.Code(err) {
+	case gcerrors.NotFound, gcerrors.AlreadyExists, gcerrors.FailedPrecondition,
+		gcerrors.InvalidArgument, gcerrors.PermissionDenied, gcerrors.Unimplemented:
+		return false
+	}
+	return true
+}
+
+// allow returns ErrCircuitOpen if the call must not be made. Otherwise the
+// caller must call done with probe and the result of the call. probe is the
+// non-zero ID of the probe if the call is the probe of the half-open circuit.
+func (b *CircuitBreakerBlobStore) allow() (probe uint64, err error) {
+	if b.threshold <= 0 {
+		return 0, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.coolDown {
+			return 0, ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		fallthrough
+	case circuitHalfOpen:
+		if b.probing && b.now().Sub(b.probeTime) < b.coolDown {
+			return 0, ErrCircuitOpen
+		}
+		if b.probing {
+			log.Warn("Blob store circuit breaker probe timed out, starting another probe")
+		}
+		b.probing = true
+		b.probeID++
+		b.probeTime = b.now()
+		return b.probeID, nil
+	}
+	return 0, nil
+}
+
+// done records the result of a call allowed by allow. Results of calls made
+// before the circuit opened, and of abandoned probes, only count while it is
+// closed.
+func (b *CircuitBreakerBlobStore) done(probeID uint64, err error) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := probeID != 0 && b.probing && probeID == b.probeID
+	if probe {
+		b.probing = false
+	}
+	if !isCircuitFailure(err) {
+		if probe {
+			log.Info("Blob store circuit breaker closed")
+			b.state = circuitClosed
+		}
+		if b.state == circuitClosed {
+			b.failures = 0
+		}
+		return
+	}
+	if b.state == circuitClosed || probe {
+		b.failures++
+	}
+	if probe || (b.state == circuitClosed && b.failures >= b.threshold) {
+		log.Warnf("Blob store circuit breaker opened after %v consecutive failures: %v", b.failures, err)
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// call runs f if the circuit allows it and records its result.
+func (b *CircuitBreakerBlobStore) call(f func() error) error {
+	probe, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = f()
+	b.done(probe, err)
+	return err
+}
+
+// Put inserts a blob at the given path.
+func (b *CircuitBreakerBlobStore) Put(ctx context.Context, path string, data []byte) error {
+	return b.call(func() error { return b.BlobStore.Put(ctx, path, data) })
+}
+
+// PutIfAbsent writes the content of r to path only if the object doesn't exist.
+func (b *CircuitBreakerBlobStore) PutIfAbsent(ctx context.Context, path string, r io.Reader) (created bool, n int64, err error) {
+	err = b.call(func() error {
+		var err error
+		created, n, err = b.BlobStore.PutIfAbsent(ctx, path, r)
+		return err
+	})
+	return created, n, err
+}
+
+// NewWriter creates a new object with path and returns an io.WriteCloser
+// instance that records the result of the write when closed.
+func (b *CircuitBreakerBlobStore) NewWriter(ctx context.Context, path string) (io.WriteCloser, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	w, err := b.BlobStore.NewWriter(ctx, path)
+	if err != nil {
+		b.done(probe, err)
+		return nil, err
+	}
+	return &circuitBreakerWriter{WriteCloser: w, breaker: b, probe: probe}, nil
+}
+
+// Get retrieves the data given a blob path.
+func (b *CircuitBreakerBlobStore) Get(ctx context.Context, path string) (data []byte, err error) {
+	err = b.call(func() error {
+		var err error
+		data, err = b.BlobStore.Get(ctx, path)
+		return err
+	})
+	return data, err
+}
+
+// NewReader is an alias to NewRangeReader(ctx, path, 0, -1).
+func (b *CircuitBreakerBlobStore) NewReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	return b.NewRangeReader(ctx, path, 0, -1)
+}
+
+// NewRangeReader returns an io.ReadCloser instance for the object specified by path.
+func (b *CircuitBreakerBlobStore) NewRangeReader(ctx context.Context, path string, offset, length int64) (r io.ReadCloser, err error) {
+	err = b.call(func() error {
+		var err error
+		r, err = b.BlobStore.NewRangeReader(ctx, path, offset, length)
+		return err
+	})
+	return r, err
+}
+
+// Delete deletes the blob at the given path.
+func (b *CircuitBreakerBlobStore) Delete(ctx context.Context, path string) error {
+	return b.call(func() error { return b.BlobStore.Delete(ctx, path) })
+}
+
+// DeleteMulti deletes the blobs at the given paths and returns the paths that
+// failed. All paths fail with ErrCircuitOpen while the circuit is open.
+func (b *CircuitBreakerBlobStore) DeleteMulti(ctx context.Context, paths []string) (failed []string, err error) {
+	probe, err := b.allow()
+	if err != nil {
+		return paths, err
+	}
+	failed, err = b.BlobStore.DeleteMulti(ctx, paths)
+	b.done(probe, err)
+	return failed, err
+}
+
+// List returns paths of all objects that begin with prefix.
+func (b *CircuitBreakerBlobStore) List(ctx context.Context, prefix string) (paths []string, err error) {
+	err = b.call(func() error {
+		var err error
+		paths, err = b.BlobStore.List(ctx, prefix)
+		return err
+	})
+	return paths, err
+}
+
+// Stat returns the attributes of the object at path.
+func (b *CircuitBreakerBlobStore) Stat(ctx context.Context, path string) (attrs ObjectAttrs, err error) {
+	err = b.call(func() error {
+		var err error
+		attrs, err = Stat(ctx, b.BlobStore, path)
+		return err
+	})
+	return attrs, err
+}
+
+// PutWithHeaders inserts a blob at path with headers.
+func (b *CircuitBreakerBlobStore) PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error {
+	return b.call(func() error { return PutWithHeaders(ctx, b.BlobStore, path, data, headers) })
+}
+
+// NewWriterWithHeaders creates a new object at path with headers and returns
+// an io.WriteCloser instance that records the result of the write when closed.
+func (b *CircuitBreakerBlobStore) NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriterWithHeaders(ctx, b.BlobStore, path, headers)
+	if err != nil {
+		b.done(probe, err)
+		return nil, err
+	}
+	return &circuitBreakerWriter{WriteCloser: w, breaker: b, probe: probe}, nil
+}
+
+// SignUrlWithHeaders returns a signed URL for key with headers. Signing
+// doesn't call the store, so it is not affected by the circuit.
+func (b *CircuitBreakerBlobStore) SignUrlWithHeaders(ctx context.Context, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error) {
+	return SignUrlWithHeaders(ctx, b.BlobStore, key, ttlInSeconds, method, headers)
+}
+
+// Generation returns the generation of the object at path.
+func (b *CircuitBreakerBlobStore) Generation(ctx context.Context, path string) (gen int64, err error) {
+	err = b.call(func() error {
+		var err error
+		gen, err = Generation(ctx, b.BlobStore, path)
+		return err
+	})
+	return gen, err
+}
+
+// DeleteIfGeneration deletes the object at path if its generation is generation.
+func (b *CircuitBreakerBlobStore) DeleteIfGeneration(ctx context.Context, path string, generation int64) error {
+	return b.call(func() error { return DeleteIfGeneration(ctx, b.BlobStore, path, generation) })
+}
+
+// KMSKeyName returns the name of the key the underlying store encrypts new
+// objects with.
+func (b *CircuitBreakerBlobStore) KMSKeyName() string {
+	return KMSKeyName(b.BlobStore)
+}
+
+// Append appends the content of r to the existing object at path.
+func (b *CircuitBreakerBlobStore) Append(ctx context.Context, path string, r io.Reader) (n int64, err error) {
+	err = b.call(func() error {
+		var err error
+		n, err = Append(ctx, b.BlobStore, path, r)
+		return err
+	})
+	return n, err
+}
+
+// Exists reports whether the object at path exists.
+func (b *CircuitBreakerBlobStore) Exists(ctx context.Context, path string) (exists bool, err error) {
+	err = b.call(func() error {
+		var err error
+		exists, err = Exists(ctx, b.BlobStore, path)
+		return err
+	})
+	return exists, err
+}
+
+// EnsureReady verifies that the underlying store is ready to store objects.
+// It bypasses the circuit because it is called at startup.
+func (b *CircuitBreakerBlobStore) EnsureReady(ctx context.Context) error {
+	return EnsureReady(ctx, b.BlobStore)
+}
+
+type circuitBreakerWriter struct {
+	io.WriteCloser
+	breaker *CircuitBreakerBlobStore
+	probe   uint64
+}
+
+func (w *circuitBreakerWriter) Close() error {
+	err := w.WriteCloser.Close()
+	w.breaker.done(w.probe, err)
+	return err
+}
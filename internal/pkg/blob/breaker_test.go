@@ -0,0 +1,257 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gocloud.dev/gcerrors"
+)
+
+// failingBlobStore fails Get and Put with err and counts the calls.
+type failingBlobStore struct {
+	BlobStore
+	err   error
+	calls int
+}
+
+func (b *failingBlobStore) Get(ctx context.Context, path string) ([]byte, error) {
+	b.calls++
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.BlobStore.Get(ctx, path)
+}
+
+func (b *failingBlobStore) Put(ctx context.Context, path string, data []byte) error {
+	b.calls++
+	if b.err != nil {
+		return b.err
+	}
+	return b.BlobStore.Put(ctx, path, data)
+}
+
+func newTestBreaker(ctx context.Context, t *testing.T, threshold int) (*CircuitBreakerBlobStore, *failingBlobStore, *time.Time) {
+	t.Helper()
+	failing := &failingBlobStore{BlobStore: mustGetBucket(ctx, t)}
+	if err := failing.Put(ctx, "object", []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	failing.calls = 0
+	now := time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreakerBlobStore(failing, threshold, time.Minute)
+	cb.now = func() time.Time { return now }
+	return cb, failing, &now
+}
+
+func TestCircuitBreakerBlobStore_Trip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cb, failing, now := newTestBreaker(ctx, t, 3)
+	outage := errors.New("service unavailable")
+	failing.err = outage
+
+	for i := 0; i < 3; i++ {
+		if _, err := cb.Get(ctx, "object"); !errors.Is(err, outage) {
+			t.Errorf("Get() = %v, want %v", err, outage)
+		}
+	}
+	// The circuit is open, so calls are short-circuited.
+	for i := 0; i < 5; i++ {
+		if _, err := cb.Get(ctx, "object"); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Get() = %v, want %v", err, ErrCircuitOpen)
+		}
+		if err := cb.Put(ctx, "object", []byte("new")); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Put() = %v, want %v", err, ErrCircuitOpen)
+		}
+	}
+	if failing.calls != 3 {
+		t.Errorf("the store was called %v times, want 3", failing.calls)
+	}
+
+	// A failed probe opens the circuit for another cool-down period.
+	*now = now.Add(time.Minute)
+	if _, err := cb.Get(ctx, "object"); !errors.Is(err, outage) {
+		t.Errorf("Get() = %v, want %v on the probe", err, outage)
+	}
+	if _, err := cb.Get(ctx, "object"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get() = %v, want %v after a failed probe", err, ErrCircuitOpen)
+	}
+
+	// A successful probe closes the circuit.
+	*now = now.Add(time.Minute)
+	failing.err = nil
+	for i := 0; i < 3; i++ {
+		if got, err := cb.Get(ctx, "object"); err != nil || string(got) != "content" {
+			t.Errorf("Get() = (%q, %v), want (\"content\", nil) after recovery", got, err)
+		}
+	}
+	if failing.calls != 7 {
+		t.Errorf("the store was called %v times, want 7", failing.calls)
+	}
+}
+
+func TestCircuitBreakerBlobStore_HalfOpenSingleProbe(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cb, failing, now := newTestBreaker(ctx, t, 1)
+	failing.err = errors.New("outage")
+	cb.Get(ctx, "object")
+	*now = now.Add(time.Minute)
+
+	// The probe is in progress while the writer is open.
+	w, err := cb.NewWriter(ctx, "probe")
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := cb.Get(ctx, "object"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get() = %v, want %v during the probe", err, ErrCircuitOpen)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	failing.err = nil
+	if _, err := cb.Get(ctx, "object"); err != nil {
+		t.Errorf("Get() = %v, want nil after a successful probe", err)
+	}
+}
+
+func TestCircuitBreakerBlobStore_NonFailures(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cb, failing, _ := newTestBreaker(ctx, t, 2)
+	for i := 0; i < 5; i++ {
+		if _, err := cb.Get(ctx, "missing"); gcerrors.Code(err) != gcerrors.NotFound {
+			t.Errorf("Get() = %v, want NotFound", err)
+		}
+	}
+	failing.err = context.Canceled
+	for i := 0; i < 5; i++ {
+		cb.Get(ctx, "object")
+	}
+	// Successes reset the count of consecutive failures.
+	failing.err = errors.New("outage")
+	cb.Get(ctx, "object")
+	failing.err = nil
+	cb.Get(ctx, "object")
+	failing.err = errors.New("outage")
+	cb.Get(ctx, "object")
+	if _, err := cb.Get(ctx, "object"); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get() = %v, want the circuit to be closed", err)
+	}
+}
+
+func TestCircuitBreakerBlobStore_Disabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cb, failing, _ := newTestBreaker(ctx, t, 0)
+	failing.err = errors.New("outage")
+	for i := 0; i < 10; i++ {
+		if _, err := cb.Get(ctx, "object"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Get() = %v, want the breaker to be disabled", err)
+		}
+	}
+}
+
+func TestCircuitBreakerBlobStore_ProbeTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cb, failing, now := newTestBreaker(ctx, t, 1)
+	failing.err = errors.New("outage")
+	cb.Get(ctx, "object")
+	*now = now.Add(time.Minute)
+
+	// The writer of the probe is never closed.
+	if _, err := cb.NewWriter(ctx, "probe"); err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := cb.Get(ctx, "object"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get() = %v, want %v during the probe", err, ErrCircuitOpen)
+	}
+	// The abandoned probe is replaced after the cool-down period.
+	*now = now.Add(time.Minute)
+	failing.err = nil
+	if _, err := cb.Get(ctx, "object"); err != nil {
+		t.Errorf("Get() = %v, want nil after the probe timed out", err)
+	}
+	if _, err := cb.Get(ctx, "object"); err != nil {
+		t.Errorf("Get() = %v, want the circuit to be closed", err)
+	}
+}
+
+// mustGetKMSBucket returns a BlobGCP on the test bucket that encrypts objects
+// with testKMSKeyName.
+func mustGetKMSBucket(ctx context.Context, t *testing.T) *BlobGCP {
+	t.Helper()
+	gcs, err := NewBlobGCPWithKMSKey(ctx, testBucket, testKMSKeyName)
+	if err != nil {
+		t.Fatalf("NewBlobGCPWithKMSKey() failed: %v", err)
+	}
+	t.Cleanup(func() { gcs.Close() })
+	return gcs
+}
+
+// checkOptionalInterfaces checks that bs forwards the optional interfaces to
+// an underlying store returned by mustGetKMSBucket.
+func checkOptionalInterfaces(ctx context.Context, t *testing.T, bs BlobStore) {
+	t.Helper()
+
+	if got := KMSKeyName(bs); got != testKMSKeyName {
+		t.Errorf("KMSKeyName() = %q, want %q", got, testKMSKeyName)
+	}
+	const path = "store/object"
+	headers := ObjectHeaders{ContentType: "text/plain"}
+	if err := PutWithHeaders(ctx, bs, path, []byte("content"), headers); err != nil {
+		t.Fatalf("PutWithHeaders() failed: %v", err)
+	}
+	if n, err := Append(ctx, bs, path, strings.NewReader("-more")); err != nil || n != 5 {
+		t.Errorf("Append() = (%v, %v), want (5, nil)", n, err)
+	}
+	if exists, err := Exists(ctx, bs, path); err != nil || !exists {
+		t.Errorf("Exists() = (%v, %v), want (true, nil)", exists, err)
+	}
+	gen, err := Generation(ctx, bs, path)
+	if err != nil || gen == 0 {
+		t.Fatalf("Generation() = (%v, %v), want a non-zero generation", gen, err)
+	}
+	if err := DeleteIfGeneration(ctx, bs, path, gen+1); !errors.Is(err, ErrGenerationMismatch) {
+		t.Errorf("DeleteIfGeneration() = %v, want %v", err, ErrGenerationMismatch)
+	}
+	if err := DeleteIfGeneration(ctx, bs, path, gen); err != nil {
+		t.Errorf("DeleteIfGeneration() failed: %v", err)
+	}
+	if exists, err := Exists(ctx, bs, path); err != nil || exists {
+		t.Errorf("Exists() after DeleteIfGeneration() = (%v, %v), want (false, nil)", exists, err)
+	}
+	if err := EnsureReady(ctx, bs); err != nil {
+		t.Errorf("EnsureReady() failed: %v", err)
+	}
+}
+
+func TestCircuitBreakerBlobStore_OptionalInterfaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	checkOptionalInterfaces(ctx, t, NewCircuitBreakerBlobStore(mustGetKMSBucket(ctx, t), 1, time.Minute))
+}
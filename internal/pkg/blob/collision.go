@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+)
+
+// ErrObjectPathCollision is returned by PutIfAbsent when an object with
+// different content already exists at the path. This means either that two
+// different keys map to the same object path, or that the object was
+// overwritten by someone else, and the existing object must not be treated as
+// the one being written.
+var ErrObjectPathCollision = errors.New("a different object already exists at the path")
+
+// newCollisionHash returns the hash used to compare new and existing objects.
+func newCollisionHash() hash.Hash {
+	return sha256.New()
+}
+
+// checkCollision is called when PutIfAbsent finds an existing object at path.
+// h has the hash of the part of r that was already consumed. checkCollision
+// hashes the rest of r and the existing object, and returns nil if they are
+// the same content, e.g. a retried write, or ErrObjectPathCollision otherwise.
+func (b *BlobGCP) checkCollision(ctx context.Context, path string, r io.Reader, h hash.Hash) error {
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	existing, err := b.bucket.NewReader(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	defer existing.Close()
+	eh := newCollisionHash()
+	if _, err := io.Copy(eh, existing); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), eh.Sum(nil)) {
+		return ErrObjectPathCollision
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"mime"
+	"path"
+	"strings"
+)
+
+// DefaultContentType is the content type of objects with unknown extensions.
+const DefaultContentType = "application/octet-stream"
+
+// contentTypeOverrides maps extensions to content types regardless of the
+// system MIME tables used by mime.TypeByExtension, which differ between
+// platforms and may not be installed in containers.
+var contentTypeOverrides = map[string]string{
+	".bin":  DefaultContentType,
+	".dat":  DefaultContentType,
+	".sav":  DefaultContentType,
+	".gz":   "application/gzip",
+	".zip":  "application/zip",
+	".json": "application/json",
+	".txt":  "text/plain; charset=utf-8",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".webp": "image/webp",
+}
+
+// InferContentType returns the content type of a file named filename by its
+// extension. Extensions are matched case-insensitively against overrides
+// first, then a built-in table of common types, and then mime.TypeByExtension.
+// It returns DefaultContentType if the extension is unknown or missing.
+func InferContentType(filename string, overrides map[string]string) string {
+	ext := strings.ToLower(path.Ext(filename))
+	if ext == "" {
+		return DefaultContentType
+	}
+	if t, ok := overrides[ext]; ok {
+		return t
+	}
+	if t, ok := contentTypeOverrides[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return DefaultContentType
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import "testing"
+
+func TestInferContentType(t *testing.T) {
+	t.Parallel()
+
+	overrides := map[string]string{
+		".sav":  "application/x-game-save",
+		".mesh": "model/x-mesh",
+	}
+	testCases := []struct {
+		name      string
+		filename  string
+		overrides map[string]string
+		want      string
+	}{
+		{"json", "profile.json", nil, "application/json"},
+		{"png", "screenshots/thumb.png", nil, "image/png"},
+		{"case insensitive", "THUMB.JPG", nil, "image/jpeg"},
+		{"built-in binary", "slot1.sav", nil, DefaultContentType},
+		{"system mime", "index.html", nil, "text/html; charset=utf-8"},
+		{"override", "slot1.SAV", overrides, "application/x-game-save"},
+		{"override unknown", "level.mesh", overrides, "model/x-mesh"},
+		{"unknown", "save.unknownext", overrides, DefaultContentType},
+		{"no extension", "save", nil, DefaultContentType},
+		{"empty", "", nil, DefaultContentType},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := InferContentType(tc.filename, tc.overrides); got != tc.want {
+				t.Errorf("InferContentType(%q) = %q, want %q", tc.filename, got, tc.want)
+			}
+		})
+	}
+}
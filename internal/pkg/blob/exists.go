@@ -0,0 +1,273 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"gocloud.dev/gcerrors"
+)
+
+// ExistenceChecker is implemented by BlobStores that can check whether an
+// object exists more efficiently than opening a reader.
+type ExistenceChecker interface {
+	Exists(ctx context.Context, path string) (bool, error)
+}
+
+// Exists reports whether the object at path exists in bs.
+// It uses bs.Exists if bs implements ExistenceChecker.
+func Exists(ctx context.Context, bs BlobStore, path string) (bool, error) {
+	if c, ok := bs.(ExistenceChecker); ok {
+		return c.Exists(ctx, path)
+	}
+	return readerExists(ctx, bs, path)
+}
+
+// readerExists checks the existence of an object by opening an empty range reader.
+func readerExists(ctx context.Context, bs BlobStore, path string) (bool, error) {
+	r, err := bs.NewRangeReader(ctx, path, 0, 0)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, r.Close()
+}
+
+// existenceCacheMaxEntries is the number of entries above which ExistenceCache
+// drops expired entries.
+const existenceCacheMaxEntries = 10000
+
+// ExistenceCache wraps a BlobStore and caches the results of Exists for a
+// short time to avoid repeated round trips for the same object.
+// Writes through the cache mark the object as existing, and deletes through
+// the cache remove the cached entry. Changes made directly to the underlying
+// store are not seen until the entries expire.
+// The optional interfaces, such as Statter, HeaderWriter, GenerationDeleter,
+// KMSKeyNamer, Appender, and ReadinessChecker, are forwarded to the underlying
+// store, and the package functions fall back the same way as for the
+// underlying store if it doesn't implement them.
+type ExistenceCache struct {
+	BlobStore
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]existenceEntry
+}
+
+type existenceEntry struct {
+	exists    bool
+	expiresAt time.Time
+}
+
+// Assert ExistenceCache implements the BlobStore and optional interfaces.
+var _ BlobStore = new(ExistenceCache)
+var _ Statter = new(ExistenceCache)
+var _ HeaderWriter = new(ExistenceCache)
+var _ GenerationDeleter = new(ExistenceCache)
+var _ KMSKeyNamer = new(ExistenceCache)
+var _ Appender = new(ExistenceCache)
+var _ ExistenceChecker = new(ExistenceCache)
+var _ ReadinessChecker = new(ExistenceCache)
+
+// NewExistenceCache returns an ExistenceCache in front of bs that caches
+// existing objects for positiveTTL and missing objects for negativeTTL.
+// negativeTTL is usually shorter as missing objects are likely being uploaded.
+func NewExistenceCache(bs BlobStore, positiveTTL, negativeTTL time.Duration) *ExistenceCache {
+	return &ExistenceCache{
+		BlobStore:   bs,
+		positiveTTL: positiveTTL,
+		negativeTTL: negativeTTL,
+		now:         time.Now,
+		entries:     make(map[string]existenceEntry),
+	}
+}
+
+// Exists reports whether the object at path exists, using the cached result
+// if it hasn't expired. Errors are not cached.
+func (c *ExistenceCache) Exists(ctx context.Context, path string) (bool, error) {
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	if ok && c.now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.exists, nil
+	}
+	c.mu.Unlock()
+
+	exists, err := Exists(ctx, c.BlobStore, path)
+	if err != nil {
+		return false, err
+	}
+	c.set(path, exists)
+	return exists, nil
+}
+
+func (c *ExistenceCache) set(path string, exists bool) {
+	ttl := c.negativeTTL
+	if exists {
+		ttl = c.positiveTTL
+	}
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= existenceCacheMaxEntries {
+		for p, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, p)
+			}
+		}
+	}
+	c.entries[path] = existenceEntry{exists: exists, expiresAt: now.Add(ttl)}
+}
+
+func (c *ExistenceCache) invalidate(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, path)
+}
+
+// Put inserts a blob at the given path and caches it as existing.
+func (c *ExistenceCache) Put(ctx context.Context, path string, data []byte) error {
+	if err := c.BlobStore.Put(ctx, path, data); err != nil {
+		c.invalidate(path)
+		return err
+	}
+	c.set(path, true)
+	return nil
+}
+
+// PutIfAbsent writes the content of r to path only if the object doesn't exist,
+// and caches the object as existing in either case.
+func (c *ExistenceCache) PutIfAbsent(ctx context.Context, path string, r io.Reader) (bool, int64, error) {
+	created, n, err := c.BlobStore.PutIfAbsent(ctx, path, r)
+	if err != nil {
+		c.invalidate(path)
+		return created, n, err
+	}
+	c.set(path, true)
+	return created, n, nil
+}
+
+// NewWriter creates a new object with path and returns an io.WriteCloser
+// instance that caches the object as existing when closed successfully.
+func (c *ExistenceCache) NewWriter(ctx context.Context, path string) (io.WriteCloser, error) {
+	c.invalidate(path)
+	w, err := c.BlobStore.NewWriter(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return &existenceCacheWriter{WriteCloser: w, cache: c, path: path}, nil
+}
+
+// Delete deletes the blob at the given path and removes the cached entry.
+func (c *ExistenceCache) Delete(ctx context.Context, path string) error {
+	defer c.invalidate(path)
+	return c.BlobStore.Delete(ctx, path)
+}
+
+// DeleteMulti deletes objects at paths and removes their cached entries.
+func (c *ExistenceCache) DeleteMulti(ctx context.Context, paths []string) ([]string, error) {
+	defer func() {
+		for _, p := range paths {
+			c.invalidate(p)
+		}
+	}()
+	return c.BlobStore.DeleteMulti(ctx, paths)
+}
+
+// Stat returns the attributes of the object at path. The result is not cached.
+func (c *ExistenceCache) Stat(ctx context.Context, path string) (ObjectAttrs, error) {
+	return Stat(ctx, c.BlobStore, path)
+}
+
+// PutWithHeaders inserts a blob at path with headers and caches it as existing.
+func (c *ExistenceCache) PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error {
+	if err := PutWithHeaders(ctx, c.BlobStore, path, data, headers); err != nil {
+		c.invalidate(path)
+		return err
+	}
+	c.set(path, true)
+	return nil
+}
+
+// NewWriterWithHeaders is the same as NewWriter, but it creates the object with headers.
+func (c *ExistenceCache) NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	c.invalidate(path)
+	w, err := NewWriterWithHeaders(ctx, c.BlobStore, path, headers)
+	if err != nil {
+		return nil, err
+	}
+	return &existenceCacheWriter{WriteCloser: w, cache: c, path: path}, nil
+}
+
+// SignUrlWithHeaders returns a signed URL for key with headers.
+func (c *ExistenceCache) SignUrlWithHeaders(ctx context.Context, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error) {
+	return SignUrlWithHeaders(ctx, c.BlobStore, key, ttlInSeconds, method, headers)
+}
+
+// Generation returns the generation of the object at path.
+func (c *ExistenceCache) Generation(ctx context.Context, path string) (int64, error) {
+	return Generation(ctx, c.BlobStore, path)
+}
+
+// DeleteIfGeneration deletes the object at path if its generation is
+// generation, and removes the cached entry.
+func (c *ExistenceCache) DeleteIfGeneration(ctx context.Context, path string, generation int64) error {
+	defer c.invalidate(path)
+	return DeleteIfGeneration(ctx, c.BlobStore, path, generation)
+}
+
+// KMSKeyName returns the name of the key the underlying store encrypts new
+// objects with.
+func (c *ExistenceCache) KMSKeyName() string {
+	return KMSKeyName(c.BlobStore)
+}
+
+// Append appends the content of r to the existing object at path and caches
+// the object as existing.
+func (c *ExistenceCache) Append(ctx context.Context, path string, r io.Reader) (int64, error) {
+	n, err := Append(ctx, c.BlobStore, path, r)
+	if err != nil {
+		c.invalidate(path)
+		return n, err
+	}
+	c.set(path, true)
+	return n, nil
+}
+
+// EnsureReady verifies that the underlying store is ready to store objects.
+func (c *ExistenceCache) EnsureReady(ctx context.Context) error {
+	return EnsureReady(ctx, c.BlobStore)
+}
+
+type existenceCacheWriter struct {
+	io.WriteCloser
+	cache *ExistenceCache
+	path  string
+}
+
+func (w *existenceCacheWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	w.cache.set(w.path, true)
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// countingBlobStore counts the calls to NewRangeReader, which Exists uses.
+type countingBlobStore struct {
+	BlobStore
+	reads int
+}
+
+func (c *countingBlobStore) NewRangeReader(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	c.reads++
+	return c.BlobStore.NewRangeReader(ctx, path, offset, length)
+}
+
+func newTestExistenceCache(ctx context.Context, t *testing.T) (*ExistenceCache, *countingBlobStore, *time.Time) {
+	t.Helper()
+	store := &countingBlobStore{BlobStore: mustGetBucket(ctx, t)}
+	c := NewExistenceCache(store, time.Minute, time.Second)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+	return c, store, &now
+}
+
+func mustExist(ctx context.Context, t *testing.T, c *ExistenceCache, path string, want bool) {
+	t.Helper()
+	got, err := c.Exists(ctx, path)
+	if err != nil {
+		t.Fatalf("Exists(%q) failed: %v", path, err)
+	}
+	if got != want {
+		t.Errorf("Exists(%q) = %v, want %v", path, got, want)
+	}
+}
+
+func TestExists(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	if err := gcs.Put(ctx, "exists", []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	for path, want := range map[string]bool{"exists": true, "missing": false} {
+		got, err := Exists(ctx, gcs, path)
+		if err != nil || got != want {
+			t.Errorf("Exists(%q) = (%v, %v), want (%v, nil)", path, got, err, want)
+		}
+	}
+}
+
+func TestExistenceCache_CachedHit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, store, now := newTestExistenceCache(ctx, t)
+	if err := store.BlobStore.Put(ctx, "path", []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	mustExist(ctx, t, c, "path", true)
+	mustExist(ctx, t, c, "path", true)
+	if store.reads != 1 {
+		t.Errorf("Exists() read the store %d times, want 1", store.reads)
+	}
+
+	*now = now.Add(time.Minute)
+	mustExist(ctx, t, c, "path", true)
+	if store.reads != 2 {
+		t.Errorf("Exists() read the store %d times after the entry expired, want 2", store.reads)
+	}
+}
+
+func TestExistenceCache_NegativeExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, store, now := newTestExistenceCache(ctx, t)
+
+	mustExist(ctx, t, c, "path", false)
+	// Written directly to the store, so the cache doesn't know.
+	if err := store.BlobStore.Put(ctx, "path", []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	mustExist(ctx, t, c, "path", false)
+	if store.reads != 1 {
+		t.Errorf("Exists() read the store %d times, want 1", store.reads)
+	}
+
+	// Negative entries expire before positive ones would.
+	*now = now.Add(time.Second)
+	mustExist(ctx, t, c, "path", true)
+	if store.reads != 2 {
+		t.Errorf("Exists() read the store %d times after the entry expired, want 2", store.reads)
+	}
+}
+
+func TestExistenceCache_InvalidateOnWrite(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, store, _ := newTestExistenceCache(ctx, t)
+
+	mustExist(ctx, t, c, "put", false)
+	if err := c.Put(ctx, "put", []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	mustExist(ctx, t, c, "put", true)
+
+	mustExist(ctx, t, c, "writer", false)
+	w, err := c.NewWriter(ctx, "writer")
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if _, err := w.Write([]byte("content")); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	mustExist(ctx, t, c, "writer", true)
+	if store.reads != 2 {
+		t.Errorf("Exists() read the store %d times, want 2 for the initial lookups", store.reads)
+	}
+
+	if err := c.Delete(ctx, "put"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	mustExist(ctx, t, c, "put", false)
+	if store.reads != 3 {
+		t.Errorf("Exists() read the store %d times, want 3 after Delete()", store.reads)
+	}
+}
+
+func TestExistenceCache_OptionalInterfaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	checkOptionalInterfaces(ctx, t, NewExistenceCache(mustGetKMSBucket(ctx, t), time.Minute, time.Minute))
+}
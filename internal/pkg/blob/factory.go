@@ -0,0 +1,65 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kinds of BlobStore backends accepted by NewBlobStore.
+const (
+	// KindGCS is Google Cloud Storage, opened with BlobStoreConfig.BucketURL.
+	KindGCS = "gcs"
+	// KindFilesystem is a local directory, opened with BlobStoreConfig.RootDir.
+	KindFilesystem = "filesystem"
+)
+
+// BlobStoreConfig has the settings used by NewBlobStore. Only the fields of
+// the selected kind are used.
+type BlobStoreConfig struct {
+	// BucketURL is the URL of the bucket, e.g. gs://bucket-name, for KindGCS.
+	BucketURL string
+	// KMSKeyName is the Cloud KMS key that new objects are encrypted with for
+	// KindGCS. The bucket's default encryption is used if empty.
+	KMSKeyName string
+	// RootDir is the root directory of objects for KindFilesystem.
+	RootDir string
+	// SyncMode is how written objects are flushed for KindFilesystem.
+	// The zero value is SyncFile.
+	SyncMode SyncMode
+}
+
+// NewBlobStore returns a BlobStore of kind configured by cfg. An empty kind
+// is KindGCS.
+// Backends of other clouds, such as S3, can be added here when their gocloud
+// drivers are linked into the binaries.
+func NewBlobStore(ctx context.Context, kind string, cfg BlobStoreConfig) (BlobStore, error) {
+	switch kind {
+	case KindGCS, "":
+		if cfg.BucketURL == "" {
+			return nil, errors.New("BucketURL is required for the gcs blob store")
+		}
+		return NewBlobGCPWithKMSKey(ctx, cfg.BucketURL, cfg.KMSKeyName)
+	case KindFilesystem:
+		if cfg.RootDir == "" {
+			return nil, errors.New("RootDir is required for the filesystem blob store")
+		}
+		return NewBlobFSWithSyncMode(ctx, cfg.RootDir, cfg.SyncMode)
+	default:
+		return nil, fmt.Errorf("blob store kind(%q) is not supported", kind)
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+)
+
+// SyncMode controls how BlobFS flushes written objects to stable storage before
+// the writes return, trading throughput for durability.
+type SyncMode int32
+
+const (
+	// SyncFile fsyncs the object file before it is renamed into place and its
+	// directory after, so that the object survives a crash once the write
+	// returns. This is the default.
+	SyncFile SyncMode = iota
+	// SyncFileAndDir is SyncFile, but it also fsyncs the parent directories
+	// up to the root, so that newly created directories survive a crash too.
+	SyncFileAndDir
+	// SyncNone leaves flushing to the operating system, which is the fastest,
+	// but recently written objects may be lost or empty after a crash.
+	SyncNone
+)
+
+// ParseSyncMode returns the mode named s, one of "file", "file_and_dir", and
+// "none". An empty string is SyncFile.
+func ParseSyncMode(s string) (SyncMode, error) {
+	switch s {
+	case "", "file":
+		return SyncFile, nil
+	case "file_and_dir":
+		return SyncFileAndDir, nil
+	case "none":
+		return SyncNone, nil
+	default:
+		return SyncFile, fmt.Errorf("unknown sync mode: %q", s)
+	}
+}
+
+// BlobFS is a BlobStore implementation backed by a local directory, for running
+// Open Saves locally or on hosts without Cloud Storage.
+// Object paths are used as paths relative to the root directory, and
+// intermediate directories are created as needed. Writes go to a temporary
+// file that is renamed to the object path on close, so concurrent writers never
+// observe or leave partial objects; the last writer to close wins.
+// PutIfAbsent is only serialized within the process. Signed URLs are not supported.
+// Writes return after the object is flushed to stable storage as configured by
+// the SyncMode.
+type BlobFS struct {
+	*BlobGCP
+	root     string
+	syncMode SyncMode
+	// syncFile flushes f to stable storage. It is (*os.File).Sync except in tests.
+	syncFile func(f *os.File) error
+}
+
+// attrsExt is the extension of the attributes files that fileblob writes next
+// to objects.
+const attrsExt = ".attrs"
+
+// Assert BlobFS implements the BlobStore interface.
+var _ BlobStore = new(BlobFS)
+
+// NewBlobFS returns a new BlobFS instance rooted at root with SyncFile.
+// The directory is created if it doesn't exist.
+func NewBlobFS(ctx context.Context, root string) (*BlobFS, error) {
+	return NewBlobFSWithSyncMode(ctx, root, SyncFile)
+}
+
+// NewBlobFSWithSyncMode returns a new BlobFS instance rooted at root that
+// flushes written objects as specified by mode.
+// The directory is created if it doesn't exist.
+func NewBlobFSWithSyncMode(ctx context.Context, root string, mode SyncMode) (*BlobFS, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobFS.NewBlobFS")
+	defer span.End()
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := fileblob.OpenBucket(root, &fileblob.Options{CreateDir: true})
+	if err != nil {
+		return nil, err
+	}
+	return &BlobFS{
+		BlobGCP:  &BlobGCP{bucket: bucket},
+		root:     root,
+		syncMode: mode,
+		syncFile: (*os.File).Sync,
+	}, nil
+}
+
+// Root returns the absolute path of the root directory.
+func (b *BlobFS) Root() string {
+	return b.root
+}
+
+// SyncMode returns the SyncMode of b.
+func (b *BlobFS) SyncMode() SyncMode {
+	return b.syncMode
+}
+
+// Put inserts a blob at the given path and flushes it.
+func (b *BlobFS) Put(ctx context.Context, path string, data []byte) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobFS.Put")
+	defer span.End()
+
+	_, err := b.write(ctx, path, bytes.NewReader(data), ObjectHeaders{})
+	return err
+}
+
+// PutWithHeaders inserts a blob at the given path with headers and flushes it.
+func (b *BlobFS) PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobFS.PutWithHeaders")
+	defer span.End()
+
+	_, err := b.write(ctx, path, bytes.NewReader(data), headers)
+	return err
+}
+
+// PutIfAbsent writes the content of r to path only if the object doesn't exist,
+// and flushes the new object.
+// If the object exists, its content is compared with r, and PutIfAbsent returns
+// ErrObjectPathCollision if they differ.
+func (b *BlobFS) PutIfAbsent(ctx context.Context, path string, r io.Reader) (bool, int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobFS.PutIfAbsent")
+	defer span.End()
+
+	b.emulateMu.Lock()
+	defer b.emulateMu.Unlock()
+	exists, err := b.bucket.Exists(ctx, path)
+	if err != nil {
+		return false, 0, err
+	}
+	if exists {
+		return false, 0, b.checkCollision(ctx, path, r, newCollisionHash())
+	}
+	n, err := b.write(ctx, path, r, ObjectHeaders{})
+	if err != nil {
+		return false, 0, err
+	}
+	return true, n, nil
+}
+
+// Append appends the content of r to the existing object at path and flushes it.
+// The object is rewritten with the content of r at the end and the headers of
+// the existing object, so appending to the same object concurrently loses data,
+// and callers must serialize the calls.
+func (b *BlobFS) Append(ctx context.Context, path string, r io.Reader) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobFS.Append")
+	defer span.End()
+
+	attrs, err := b.bucket.Attributes(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	return rewriteAppend(ctx, b, path, r, attributesHeaders(attrs))
+}
+
+// NewWriter creates a new object with path and returns an io.WriteCloser
+// instance for the object. Close flushes the object.
+func (b *BlobFS) NewWriter(ctx context.Context, path string) (io.WriteCloser, error) {
+	return b.newWriter(ctx, path, ObjectHeaders{})
+}
+
+// NewWriterWithHeaders is the same as NewWriter, but it creates the object with headers.
+func (b *BlobFS) NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	return b.newWriter(ctx, path, headers)
+}
+
+// write copies r to a new object at path. The object is not committed if
+// reading from r fails.
+func (b *BlobFS) write(ctx context.Context, path string, r io.Reader, headers ObjectHeaders) (int64, error) {
+	w, err := b.newWriter(ctx, path, headers)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		w.abort()
+		return 0, err
+	}
+	return n, w.Close()
+}
+
+// newWriter returns a writer of a new object at path. fileblob writes the
+// object to a temporary file and renames it to path on close, so the writer
+// keeps the temporary file to flush it before the rename.
+// Objects without a content type header are written as DefaultContentType.
+func (b *BlobFS) newWriter(ctx context.Context, path string, headers ObjectHeaders) (*syncWriter, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	opts := b.headerWriterOptions(headers)
+	if opts.ContentType == "" {
+		// fileblob creates the temporary file when the writer is created only
+		// if the content type is known, instead of after sniffing the content
+		// on the first writes or on close.
+		opts.ContentType = DefaultContentType
+	}
+	w := &syncWriter{fs: b, ctx: ctx, cancel: cancel, path: path}
+	opts.BeforeWrite = func(as func(interface{}) bool) error {
+		as(&w.file)
+		return nil
+	}
+	bw, err := b.bucket.NewWriter(ctx, path, opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	w.Writer = bw
+	if w.file == nil {
+		w.abort()
+		return nil, fmt.Errorf("BlobFS: failed to get the temporary file of (%v)", path)
+	}
+	return w, nil
+}
+
+// syncWriter flushes the temporary file of the object before it is renamed on
+// close, and the directories of the object after.
+type syncWriter struct {
+	*blob.Writer
+	file   *os.File
+	fs     *BlobFS
+	ctx    context.Context
+	cancel context.CancelFunc
+	path   string
+}
+
+// abort closes the writer without committing the object.
+func (w *syncWriter) abort() {
+	// Canceling the context makes fileblob discard the temporary file.
+	w.cancel()
+	w.Writer.Close()
+}
+
+func (w *syncWriter) Close() error {
+	defer w.cancel()
+	if w.fs.syncMode != SyncNone {
+		if err := w.fs.syncFile(w.file); err != nil {
+			w.abort()
+			return fmt.Errorf("BlobFS: failed to sync (%v): %w", w.file.Name(), err)
+		}
+	}
+	if err := w.Writer.Close(); err != nil {
+		return err
+	}
+	return w.fs.sync(w.ctx, w.path)
+}
+
+// sync flushes the attributes file that fileblob writes next to the object at
+// path and the directory of the object to stable storage, after the object is
+// renamed to path. Its parent directories up to the root are flushed too for
+// SyncFileAndDir.
+// Object paths are assumed to be valid file paths, which is the case for paths
+// of Open Saves objects. Paths that fileblob escapes, such as ones with
+// control characters, fail to sync.
+func (b *BlobFS) sync(ctx context.Context, path string) error {
+	if b.syncMode == SyncNone {
+		return nil
+	}
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobFS.sync")
+	defer span.End()
+
+	name := filepath.Join(b.root, filepath.FromSlash(path))
+	if err := b.syncPath(name + attrsExt); err != nil {
+		return err
+	}
+	for dir := filepath.Dir(name); strings.HasPrefix(dir, b.root); dir = filepath.Dir(dir) {
+		if err := b.syncPath(dir); err != nil {
+			return err
+		}
+		// Directories created for the object are new entries of their parents.
+		if b.syncMode != SyncFileAndDir || dir == b.root {
+			break
+		}
+	}
+	return nil
+}
+
+func (b *BlobFS) syncPath(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("BlobFS: failed to open (%v) to sync: %w", name, err)
+	}
+	defer f.Close()
+	if err := b.syncFile(f); err != nil {
+		return fmt.Errorf("BlobFS: failed to sync (%v): %w", name, err)
+	}
+	return nil
+}
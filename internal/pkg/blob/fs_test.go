@@ -0,0 +1,364 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gocloud.dev/gcerrors"
+)
+
+func mustGetFS(ctx context.Context, t *testing.T, root string) *BlobFS {
+	t.Helper()
+
+	fs, err := NewBlobFS(ctx, root)
+	if err != nil {
+		t.Fatalf("NewBlobFS() failed: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := fs.Close(); err != nil {
+			t.Errorf("Close() failed: %v", err)
+		}
+	})
+	return fs
+}
+
+func TestBlobFS_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "new-root")
+	fs := mustGetFS(ctx, t, root)
+	const path = "store/nested/object"
+	data := []byte("hello world")
+
+	if err := fs.Put(ctx, path, data); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	// The object is written relative to the root with intermediate directories.
+	if got, err := os.ReadFile(filepath.Join(root, "store", "nested", "object")); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("ReadFile() = (%q, %v), want %q", got, err, data)
+	}
+	if got, err := fs.Get(ctx, path); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get() = (%q, %v), want %q", got, err, data)
+	}
+
+	r, err := fs.NewRangeReader(ctx, path, 6, 3)
+	if err != nil {
+		t.Fatalf("NewRangeReader() failed: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(r); err != nil {
+		t.Errorf("ReadFrom() failed: %v", err)
+	}
+	r.Close()
+	if got := buf.String(); got != "wor" {
+		t.Errorf("NewRangeReader() read %q, want %q", got, "wor")
+	}
+
+	if err := fs.Delete(ctx, path); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+	if _, err := fs.Get(ctx, path); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("Get() after Delete() = %v, want NotFound", err)
+	}
+	if err := fs.Delete(ctx, path); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("Delete() of a missing object = %v, want NotFound", err)
+	}
+}
+
+func TestBlobFS_PutIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := mustGetFS(ctx, t, t.TempDir())
+	const path = "object"
+
+	if created, n, err := fs.PutIfAbsent(ctx, path, bytes.NewReader([]byte("first"))); err != nil || !created || n != 5 {
+		t.Errorf("PutIfAbsent() = (%v, %v, %v), want (true, 5, nil)", created, n, err)
+	}
+	if created, _, err := fs.PutIfAbsent(ctx, path, bytes.NewReader([]byte("first"))); err != nil || created {
+		t.Errorf("PutIfAbsent() = (%v, %v), want (false, nil)", created, err)
+	}
+	if created, _, err := fs.PutIfAbsent(ctx, path, bytes.NewReader([]byte("second"))); !errors.Is(err, ErrObjectPathCollision) || created {
+		t.Errorf("PutIfAbsent() = (%v, %v), want (false, %v)", created, err, ErrObjectPathCollision)
+	}
+	if got, err := fs.Get(ctx, path); err != nil || string(got) != "first" {
+		t.Errorf("Get() = (%q, %v), want %q", got, err, "first")
+	}
+}
+
+func TestBlobFS_List(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := mustGetFS(ctx, t, t.TempDir())
+	paths := []string{"a/2", "a/1", "b/1", "c"}
+	for _, p := range paths {
+		if err := fs.Put(ctx, p, []byte(p)); err != nil {
+			t.Fatalf("Put(%q) failed: %v", p, err)
+		}
+	}
+
+	testCases := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"a/1", "a/2", "b/1", "c"}},
+		{"a/", []string{"a/1", "a/2"}},
+		{"b", []string{"b/1"}},
+		{"missing/", []string{}},
+	}
+	for _, tc := range testCases {
+		got, err := fs.List(ctx, tc.prefix)
+		if err != nil {
+			t.Errorf("List(%q) failed: %v", tc.prefix, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("List(%q) (-want +got):\n%s", tc.prefix, diff)
+		}
+	}
+
+	failed, err := fs.DeleteMulti(ctx, append(paths, "missing"))
+	if err != nil || len(failed) != 0 {
+		t.Errorf("DeleteMulti() = (%v, %v), want no failures", failed, err)
+	}
+	if got, err := fs.List(ctx, ""); err != nil || len(got) != 0 {
+		t.Errorf("List() after DeleteMulti() = (%v, %v), want empty", got, err)
+	}
+}
+
+func TestBlobFS_ConcurrentPut(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := mustGetFS(ctx, t, t.TempDir())
+	const path = "dir/object"
+	const writers = 16
+	contents := make(map[string]bool)
+	for i := 0; i < writers; i++ {
+		contents[fmt.Sprintf("content from writer %02d", i)] = true
+	}
+
+	var wg sync.WaitGroup
+	for c := range contents {
+		wg.Add(1)
+		go func(c string) {
+			defer wg.Done()
+			if err := fs.Put(ctx, path, []byte(c)); err != nil {
+				t.Errorf("Put() failed: %v", err)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	// One of the writes wins in its entirety.
+	got, err := fs.Get(ctx, path)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if !contents[string(got)] {
+		t.Errorf("Get() = %q, want one of the written contents", got)
+	}
+	if paths, err := fs.List(ctx, ""); err != nil || len(paths) != 1 {
+		t.Errorf("List() = (%v, %v), want only %q", paths, err, path)
+	}
+}
+
+func TestNewBlobStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bs, err := NewBlobStore(ctx, KindFilesystem, BlobStoreConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewBlobStore(filesystem) failed: %v", err)
+	}
+	if _, ok := bs.(*BlobFS); !ok {
+		t.Errorf("NewBlobStore(filesystem) = %T, want *BlobFS", bs)
+	}
+	bs.(*BlobFS).Close()
+
+	// An empty kind is gcs.
+	bs, err = NewBlobStore(ctx, "", BlobStoreConfig{BucketURL: "mem://", KMSKeyName: testKMSKeyName})
+	if err != nil {
+		t.Fatalf("NewBlobStore(gcs) failed: %v", err)
+	}
+	if _, ok := bs.(*BlobGCP); !ok {
+		t.Errorf("NewBlobStore(gcs) = %T, want *BlobGCP", bs)
+	}
+	if got := KMSKeyName(bs); got != testKMSKeyName {
+		t.Errorf("KMSKeyName(NewBlobStore(gcs)) = %q, want %q", got, testKMSKeyName)
+	}
+	bs.(*BlobGCP).Close()
+
+	for _, tc := range []struct {
+		kind string
+		cfg  BlobStoreConfig
+	}{
+		{KindGCS, BlobStoreConfig{RootDir: t.TempDir()}},
+		{KindFilesystem, BlobStoreConfig{BucketURL: "mem://"}},
+		{"s3", BlobStoreConfig{BucketURL: "s3://bucket"}},
+	} {
+		if bs, err := NewBlobStore(ctx, tc.kind, tc.cfg); err == nil {
+			t.Errorf("NewBlobStore(%q, %+v) = %v, want error", tc.kind, tc.cfg, bs)
+		}
+	}
+}
+
+// recordSyncs replaces the syncFile function of fs to record the synced files
+// in order. Temporary files of fileblob are recorded as the object path with
+// ".tmp", or "renamed" if they were renamed before they are synced.
+func recordSyncs(fs *BlobFS) func() []string {
+	var mu sync.Mutex
+	var synced []string
+	fs.syncFile = func(f *os.File) error {
+		mu.Lock()
+		defer mu.Unlock()
+		name := f.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			// fileblob names temporary files "<path>.<time>.tmp".
+			name = strings.TrimSuffix(name, ".tmp")
+			name = strings.TrimSuffix(name, filepath.Ext(name)) + ".tmp"
+			if _, err := os.Stat(f.Name()); err != nil {
+				name = "renamed"
+			}
+		}
+		synced = append(synced, name)
+		return f.Sync()
+	}
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return synced
+	}
+}
+
+func TestBlobFS_SyncModes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for _, mode := range []SyncMode{SyncFile, SyncFileAndDir, SyncNone} {
+		root := filepath.Join(t.TempDir(), "root")
+		fs, err := NewBlobFSWithSyncMode(ctx, root, mode)
+		if err != nil {
+			t.Fatalf("NewBlobFSWithSyncMode(%v) failed: %v", mode, err)
+		}
+		synced := recordSyncs(fs)
+		file := filepath.Join(root, "store", "object")
+		if err := fs.Put(ctx, "store/object", []byte("put")); err != nil {
+			t.Errorf("Put() failed: %v", err)
+		}
+		w, err := fs.NewWriter(ctx, "store/object")
+		if err != nil {
+			t.Fatalf("NewWriter() failed: %v", err)
+		}
+		if _, err := w.Write([]byte("writer")); err != nil {
+			t.Errorf("Write() failed: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("Close() failed: %v", err)
+		}
+		if _, err := fs.Append(ctx, "store/object", bytes.NewReader([]byte("+append"))); err != nil {
+			t.Errorf("Append() failed: %v", err)
+		}
+		if got, err := os.ReadFile(file); err != nil || string(got) != "writer+append" {
+			t.Errorf("ReadFile() = (%q, %v), want %q (mode %v)", got, err, "writer+append", mode)
+		}
+
+		// The temporary file is synced before it is renamed to the object,
+		// and the directory after.
+		var write []string
+		switch mode {
+		case SyncFile:
+			write = []string{file + ".tmp", file + attrsExt, filepath.Dir(file)}
+		case SyncFileAndDir:
+			write = []string{file + ".tmp", file + attrsExt, filepath.Dir(file), root}
+		}
+		var want []string
+		for i := 0; i < 3; i++ {
+			want = append(want, write...)
+		}
+		if diff := cmp.Diff(want, synced()); diff != "" {
+			t.Errorf("synced files (mode %v) (-want +got):\n%s", mode, diff)
+		}
+		if err := fs.Close(); err != nil {
+			t.Errorf("Close() failed: %v", err)
+		}
+	}
+}
+
+func TestBlobFS_SyncPutIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := t.TempDir()
+	fs := mustGetFS(ctx, t, root)
+	synced := recordSyncs(fs)
+	for i := 0; i < 2; i++ {
+		if _, _, err := fs.PutIfAbsent(ctx, "object", bytes.NewReader([]byte("content"))); err != nil {
+			t.Errorf("PutIfAbsent() failed: %v", err)
+		}
+	}
+	// Only the write that created the object is synced.
+	file := filepath.Join(root, "object")
+	if diff := cmp.Diff([]string{file + ".tmp", file + attrsExt, root}, synced()); diff != "" {
+		t.Errorf("synced files (-want +got):\n%s", diff)
+	}
+}
+
+func TestBlobFS_SyncError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := t.TempDir()
+	fs := mustGetFS(ctx, t, root)
+	syncErr := errors.New("sync failed")
+	fs.syncFile = func(*os.File) error { return syncErr }
+	if err := fs.Put(ctx, "object", []byte("content")); !errors.Is(err, syncErr) {
+		t.Errorf("Put() = %v, want %v", err, syncErr)
+	}
+	// The object is not committed, and the temporary file is removed.
+	if entries, err := os.ReadDir(root); err != nil || len(entries) != 0 {
+		t.Errorf("ReadDir() = (%v, %v), want empty", entries, err)
+	}
+}
+
+func TestParseSyncMode(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[string]SyncMode{
+		"":             SyncFile,
+		"file":         SyncFile,
+		"file_and_dir": SyncFileAndDir,
+		"none":         SyncNone,
+	} {
+		if got, err := ParseSyncMode(s); err != nil || got != want {
+			t.Errorf("ParseSyncMode(%q) = (%v, %v), want (%v, nil)", s, got, err, want)
+		}
+	}
+	if _, err := ParseSyncMode("always"); err == nil {
+		t.Error("ParseSyncMode(\"always\") succeeded, want error")
+	}
+}
@@ -15,11 +15,19 @@
 package blob
 
 import (
+	"cloud.google.com/go/storage"
 	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/bulk"
 	"github.com/googleforgames/open-saves/internal/pkg/tracing"
 	"go.opentelemetry.io/otel"
 	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
 	"io"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	// Register the gocloud blob GCS driver
@@ -29,13 +37,33 @@ import (
 // BlobGCP is the GCP implementation of blob.BlobStore using Cloud Storage.
 type BlobGCP struct {
 	bucket *blob.Bucket
+	// bucketName is the name of the Cloud Storage bucket, used by Append.
+	bucketName string
+	// kmsKeyName is the Cloud KMS key used to encrypt new objects (CMEK).
+	// Objects are encrypted with the bucket's default key if empty.
+	kmsKeyName string
+
+	// emulateMu serializes PutIfAbsent and DeleteIfGeneration for drivers without
+	// preconditions.
+	emulateMu sync.Mutex
 }
 
-// Assert BlobGCP implements the Blob interface
+// Assert BlobGCP implements the Blob, KMSKeyNamer, Appender, and HeaderWriter interfaces
 var _ BlobStore = new(BlobGCP)
+var _ KMSKeyNamer = new(BlobGCP)
+var _ Appender = new(BlobGCP)
+var _ HeaderWriter = new(BlobGCP)
 
 // NewBlobGCP returns a new BlobGCP instance.
 func NewBlobGCP(ctx context.Context, bucketURL string) (*BlobGCP, error) {
+	return NewBlobGCPWithKMSKey(ctx, bucketURL, "")
+}
+
+// NewBlobGCPWithKMSKey returns a new BlobGCP instance that writes objects
+// encrypted with the Cloud KMS key kmsKeyName, in the format of
+// projects/P/locations/L/keyRings/R/cryptoKeys/K. Existing objects are
+// readable regardless of the key they were written with.
+func NewBlobGCPWithKMSKey(ctx context.Context, bucketURL, kmsKeyName string) (*BlobGCP, error) {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.NewBlobGCP")
 	defer span.End()
 
@@ -47,19 +75,194 @@ func NewBlobGCP(ctx context.Context, bucketURL string) (*BlobGCP, error) {
 
 	// bucket is guaranteed not to be nil if OpenBucket succeeds.
 	// same for bucketHandle and storage.NewClient
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		bucket.Close()
+		return nil, err
+	}
 	gcs := &BlobGCP{
-		bucket: bucket,
+		bucket:     bucket,
+		bucketName: u.Host,
+		kmsKeyName: kmsKeyName,
 	}
 
 	return gcs, nil
 }
 
+// KMSKeyName returns the Cloud KMS key name used to encrypt new objects.
+// It returns an empty string if the bucket's default key is used.
+func (b *BlobGCP) KMSKeyName() string {
+	return b.kmsKeyName
+}
+
+// writerOptions returns the options to write a new object with the KMS key,
+// adding the DoesNotExist precondition if doesNotExist is true.
+func (b *BlobGCP) writerOptions(doesNotExist bool) *blob.WriterOptions {
+	if b.kmsKeyName == "" && !doesNotExist {
+		return nil
+	}
+	return &blob.WriterOptions{
+		BeforeWrite: func(as func(interface{}) bool) error {
+			// **storage.ObjectHandle must be accessed before *storage.Writer.
+			var obj **storage.ObjectHandle
+			if doesNotExist && as(&obj) {
+				*obj = (*obj).If(storage.Conditions{DoesNotExist: true})
+			}
+			var w *storage.Writer
+			if b.kmsKeyName != "" && as(&w) {
+				w.KMSKeyName = b.kmsKeyName
+			}
+			return nil
+		},
+	}
+}
+
+// headerWriterOptions returns the options to write a new object with headers.
+func (b *BlobGCP) headerWriterOptions(headers ObjectHeaders) *blob.WriterOptions {
+	opts := b.writerOptions(false)
+	if opts == nil {
+		opts = new(blob.WriterOptions)
+	}
+	opts.ContentType = headers.ContentType
+	opts.ContentDisposition = headers.ContentDisposition
+	opts.CacheControl = headers.CacheControl
+	return opts
+}
+
 // Put inserts a blob at the given path.
 func (b *BlobGCP) Put(ctx context.Context, path string, data []byte) error {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.Put")
 	defer span.End()
 
-	return b.bucket.WriteAll(ctx, path, data, nil)
+	return b.bucket.WriteAll(ctx, path, data, b.writerOptions(false))
+}
+
+// PutWithHeaders inserts a blob at the given path with headers.
+func (b *BlobGCP) PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.PutWithHeaders")
+	defer span.End()
+
+	return b.bucket.WriteAll(ctx, path, data, b.headerWriterOptions(headers))
+}
+
+// PutIfAbsent writes the content of r to path only if the object doesn't exist.
+// It uses the DoesNotExist generation precondition on Cloud Storage. For other
+// drivers, such as the in-memory driver used for testing, the precondition is
+// emulated by serializing PutIfAbsent calls within the process.
+// If the object exists, its content is compared with r, and PutIfAbsent returns
+// ErrObjectPathCollision if they differ.
+func (b *BlobGCP) PutIfAbsent(ctx context.Context, path string, r io.Reader) (bool, int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.PutIfAbsent")
+	defer span.End()
+
+	var client *storage.Client
+	if !b.bucket.As(&client) {
+		return b.putIfAbsentEmulated(ctx, path, r)
+	}
+	h := newCollisionHash()
+	n, err := b.write(ctx, path, io.TeeReader(r, h), b.writerOptions(true))
+	if gcerrors.Code(err) == gcerrors.FailedPrecondition {
+		// The precondition failed anywhere during the upload, so the rest of
+		// r is hashed by checkCollision.
+		return false, 0, b.checkCollision(ctx, path, r, h)
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	return true, n, nil
+}
+
+func (b *BlobGCP) putIfAbsentEmulated(ctx context.Context, path string, r io.Reader) (bool, int64, error) {
+	b.emulateMu.Lock()
+	defer b.emulateMu.Unlock()
+	exists, err := b.bucket.Exists(ctx, path)
+	if err != nil {
+		return false, 0, err
+	}
+	if exists {
+		return false, 0, b.checkCollision(ctx, path, r, newCollisionHash())
+	}
+	n, err := b.write(ctx, path, r, b.writerOptions(false))
+	if err != nil {
+		return false, 0, err
+	}
+	return true, n, nil
+}
+
+// write copies r to a new object at path. The object is not committed if
+// reading from r fails.
+func (b *BlobGCP) write(ctx context.Context, path string, r io.Reader, opts *blob.WriterOptions) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w, err := b.bucket.NewWriter(ctx, path, opts)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		// Canceling the context aborts the write.
+		cancel()
+		w.Close()
+		return 0, err
+	}
+	return n, w.Close()
+}
+
+// Append appends the content of r to the existing object at path and returns
+// the number of bytes appended. On Cloud Storage, r is uploaded to a temporary
+// object and composed with the existing object, which then becomes a composite
+// object without an MD5 hash. Cloud Storage limits the number of components of
+// a composite object, so an object can be appended to at most 1023 times.
+// For other drivers, the object is rewritten with the content of r at the end.
+// The headers and the metadata of the existing object are kept in both cases.
+// Appending to the same object concurrently loses data, so callers must
+// serialize the calls.
+func (b *BlobGCP) Append(ctx context.Context, path string, r io.Reader) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.Append")
+	defer span.End()
+
+	attrs, err := b.bucket.Attributes(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	var client *storage.Client
+	if !b.bucket.As(&client) {
+		return rewriteAppend(ctx, b, path, r, attributesHeaders(attrs))
+	}
+	tmp := fmt.Sprintf("%s.append.%s", path, uuid.NewString())
+	n, err := b.write(ctx, tmp, r, b.writerOptions(false))
+	if err != nil {
+		return 0, err
+	}
+	defer b.bucket.Delete(ctx, tmp)
+
+	bucket := client.Bucket(b.bucketName)
+	dst := bucket.Object(path)
+	composer := dst.ComposerFrom(dst, bucket.Object(tmp))
+	composer.KMSKeyName = b.kmsKeyName
+	// The composed object only has the attributes set on the composer.
+	composer.ContentType = attrs.ContentType
+	composer.ContentDisposition = attrs.ContentDisposition
+	composer.CacheControl = attrs.CacheControl
+	composer.ContentEncoding = attrs.ContentEncoding
+	composer.ContentLanguage = attrs.ContentLanguage
+	var oa storage.ObjectAttrs
+	if attrs.As(&oa) {
+		composer.Metadata = oa.Metadata
+	}
+	if _, err := composer.Run(ctx); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// attributesHeaders returns the ObjectHeaders of an object with attrs.
+func attributesHeaders(attrs *blob.Attributes) ObjectHeaders {
+	return ObjectHeaders{
+		ContentType:        attrs.ContentType,
+		ContentDisposition: attrs.ContentDisposition,
+		CacheControl:       attrs.CacheControl,
+	}
 }
 
 // NewWriter creates a new object with path and returns an io.WriteCloser
@@ -69,7 +272,15 @@ func (b *BlobGCP) NewWriter(ctx context.Context, path string) (io.WriteCloser, e
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.NewWriter")
 	defer span.End()
 
-	return b.bucket.NewWriter(ctx, path, nil)
+	return b.bucket.NewWriter(ctx, path, b.writerOptions(false))
+}
+
+// NewWriterWithHeaders is the same as NewWriter, but it creates the object with headers.
+func (b *BlobGCP) NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.NewWriterWithHeaders")
+	defer span.End()
+
+	return b.bucket.NewWriter(ctx, path, b.headerWriterOptions(headers))
 }
 
 // Get retrives the data given a blob path.
@@ -107,6 +318,119 @@ func (b *BlobGCP) Delete(ctx context.Context, path string) error {
 	return b.bucket.Delete(ctx, path)
 }
 
+// deleteMultiConcurrency is the maximum number of concurrent deletes issued by DeleteMulti.
+const deleteMultiConcurrency = 16
+
+// DeleteMulti deletes objects at paths in parallel and returns the paths that
+// failed to be deleted along with a *bulk.BatchError describing each failure.
+// Objects that don't exist are considered deleted.
+func (b *BlobGCP) DeleteMulti(ctx context.Context, paths []string) ([]string, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.DeleteMulti")
+	defer span.End()
+
+	return deleteMulti(ctx, paths, deleteMultiConcurrency, b.bucket.Delete)
+}
+
+// deleteMulti calls del for each path with at most concurrency calls running at once.
+// Paths for which del returns an error other than NotFound are returned in the
+// original order, along with a *bulk.BatchError that has the error of each path.
+// If ctx is done, remaining paths are not attempted and are also returned as
+// failed with ctx.Err() as their errors.
+func deleteMulti(ctx context.Context, paths []string, concurrency int,
+	del func(ctx context.Context, path string) error) ([]string, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	errs := make([]error, len(paths))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		if !acquire(ctx, sem) {
+			for j := i; j < len(paths); j++ {
+				errs[j] = ctx.Err()
+			}
+			break
+		}
+		wg.Add(1)
+		go func(i int, path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := del(ctx, path); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+				errs[i] = err
+			}
+		}(i, path)
+	}
+	wg.Wait()
+
+	var failed []string
+	batchErr := new(bulk.BatchError)
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, paths[i])
+			batchErr.Add(i, paths[i], err)
+		}
+	}
+	return failed, batchErr.ErrOrNil()
+}
+
+// acquire sends to sem unless ctx is done first. Returns false if ctx is done.
+func acquire(ctx context.Context, sem chan<- struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// listPageSize is the number of objects requested per page by List.
+const listPageSize = 1000
+
+// List returns paths of all objects that begin with prefix.
+// It fetches all pages from the bucket before returning.
+func (b *BlobGCP) List(ctx context.Context, prefix string) ([]string, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.List")
+	defer span.End()
+
+	opts := &blob.ListOptions{Prefix: prefix}
+	return listAllPages(ctx, func(ctx context.Context, token []byte) ([]string, []byte, error) {
+		objs, next, err := b.bucket.ListPage(ctx, token, listPageSize, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		paths := make([]string, 0, len(objs))
+		for _, o := range objs {
+			paths = append(paths, o.Key)
+		}
+		return paths, next, nil
+	})
+}
+
+// pageLister returns a page of object paths for token and the token for the next page.
+// An empty next token means there are no more pages.
+type pageLister func(ctx context.Context, token []byte) (paths []string, next []byte, err error)
+
+// listAllPages calls list repeatedly, starting from blob.FirstPageToken, until it
+// returns an empty token and concatenates the results.
+func listAllPages(ctx context.Context, list pageLister) ([]string, error) {
+	paths := []string{}
+	token := blob.FirstPageToken
+	for len(token) != 0 {
+		page, next, err := list(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, page...)
+		token = next
+	}
+	return paths, nil
+}
+
 // Close releases any resources used by the instance.
 func (b *BlobGCP) Close() error {
 	return b.bucket.Close()
@@ -117,10 +441,58 @@ func (b *BlobGCP) SignUrl(ctx context.Context, key string, ttlInSeconds int64, m
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.SignUrl")
 	defer span.End()
 
+	return b.bucket.SignedURL(ctx, key, signedURLOptions(ttlInSeconds, method, ObjectHeaders{}))
+}
+
+// SignUrlWithHeaders returns a signed url for the given blob key with headers.
+// A GET URL overrides the headers of the response, and a PUT URL requires the
+// uploader to send the headers, which are then stored with the object.
+func (b *BlobGCP) SignUrlWithHeaders(ctx context.Context, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.SignUrlWithHeaders")
+	defer span.End()
+
+	return b.bucket.SignedURL(ctx, key, signedURLOptions(ttlInSeconds, method, headers))
+}
+
+// signedURLOptions returns the options to sign a URL with headers.
+func signedURLOptions(ttlInSeconds int64, method string, headers ObjectHeaders) *blob.SignedURLOptions {
 	opts := &blob.SignedURLOptions{
 		Expiry: time.Duration(ttlInSeconds) * time.Second,
 		Method: method,
 	}
-
-	return b.bucket.SignedURL(ctx, key, opts)
+	if headers.IsZero() {
+		return opts
+	}
+	if method == http.MethodPut {
+		opts.ContentType = headers.ContentType
+	}
+	opts.BeforeSign = func(as func(interface{}) bool) error {
+		var so *storage.SignedURLOptions
+		if !as(&so) {
+			return nil
+		}
+		if method == http.MethodPut {
+			if headers.ContentDisposition != "" {
+				so.Headers = append(so.Headers, "Content-Disposition:"+headers.ContentDisposition)
+			}
+			if headers.CacheControl != "" {
+				so.Headers = append(so.Headers, "Cache-Control:"+headers.CacheControl)
+			}
+			return nil
+		}
+		if so.QueryParameters == nil {
+			so.QueryParameters = make(url.Values)
+		}
+		if headers.ContentType != "" {
+			so.QueryParameters.Set("response-content-type", headers.ContentType)
+		}
+		if headers.ContentDisposition != "" {
+			so.QueryParameters.Set("response-content-disposition", headers.ContentDisposition)
+		}
+		if headers.CacheControl != "" {
+			so.QueryParameters.Set("response-cache-control", headers.CacheControl)
+		}
+		return nil
+	}
+	return opts
 }
@@ -15,13 +15,22 @@
 package blob
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"testing/iotest"
+	"time"
 
+	"cloud.google.com/go/storage"
 	"github.com/google/go-cmp/cmp"
+	"github.com/googleforgames/open-saves/internal/pkg/bulk"
+	"gocloud.dev/blob"
 	_ "gocloud.dev/blob/memblob"
 	"gocloud.dev/gcerrors"
 )
@@ -180,3 +189,465 @@ func TestGCS_SimpleStreamTests(t *testing.T) {
 		testReader(t, "NewRangeReader", reader, testBlob[offset:offset+length])
 	}
 }
+
+func TestGCS_List(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	for _, p := range []string{"record1/a", "record1/b", "record2/a"} {
+		if err := gcs.Put(ctx, p, []byte(p)); err != nil {
+			t.Fatalf("Put(%q) failed: %v", p, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{"matching prefix", "record1/", []string{"record1/a", "record1/b"}},
+		{"non-matching prefix", "record3/", []string{}},
+		{"all", "", []string{"record1/a", "record1/b", "record2/a"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := gcs.List(ctx, tc.prefix)
+			if err != nil {
+				t.Errorf("List(%q) failed: %v", tc.prefix, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("List(%q) = (-want, +got):\n%s", tc.prefix, diff)
+			}
+		})
+	}
+}
+
+func TestGCS_ListEmptyBucket(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	got, err := gcs.List(ctx, "")
+	if err != nil {
+		t.Errorf("List() failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("List() = %v, want empty", got)
+	}
+}
+
+func TestGCS_ListAllPages(t *testing.T) {
+	t.Parallel()
+
+	pages := [][]string{{"a", "b"}, {"c"}, {}, {"d"}}
+	calls := 0
+	fake := func(ctx context.Context, token []byte) ([]string, []byte, error) {
+		// The token is the index of the page except the first one.
+		i := 0
+		if calls > 0 {
+			i = int(token[0])
+		}
+		calls++
+		var next []byte
+		if i+1 < len(pages) {
+			next = []byte{byte(i + 1)}
+		}
+		return pages[i], next, nil
+	}
+
+	got, err := listAllPages(context.Background(), fake)
+	if err != nil {
+		t.Errorf("listAllPages() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c", "d"}, got); diff != "" {
+		t.Errorf("listAllPages() = (-want, +got):\n%s", diff)
+	}
+	if calls != len(pages) {
+		t.Errorf("listAllPages() called the lister %d times, want %d", calls, len(pages))
+	}
+
+	wantErr := errors.New("list error")
+	_, err = listAllPages(context.Background(), func(context.Context, []byte) ([]string, []byte, error) {
+		return nil, nil, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("listAllPages() = %v, want %v", err, wantErr)
+	}
+}
+
+func TestGCS_DeleteMulti(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	paths := []string{"record1/a", "record1/b", "record2/a"}
+	for _, p := range paths {
+		if err := gcs.Put(ctx, p, []byte(p)); err != nil {
+			t.Fatalf("Put(%q) failed: %v", p, err)
+		}
+	}
+
+	// Missing objects count as deleted.
+	failed, err := gcs.DeleteMulti(ctx, append(paths, "missing"))
+	if err != nil {
+		t.Errorf("DeleteMulti() failed: %v", err)
+	}
+	if len(failed) != 0 {
+		t.Errorf("DeleteMulti() = %v, want no failed paths", failed)
+	}
+	got, err := gcs.List(ctx, "")
+	if err != nil {
+		t.Errorf("List() failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("List() = %v, want empty after DeleteMulti()", got)
+	}
+}
+
+func TestGCS_DeleteMultiPartialFailure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	paths := []string{"a", "b", "c", "d", "e"}
+	del := func(ctx context.Context, path string) error {
+		switch path {
+		case "b", "e":
+			return errors.New("delete error")
+		case "c":
+			// Returns NotFound as the object doesn't exist.
+			return gcs.bucket.Delete(ctx, path)
+		}
+		return nil
+	}
+	failed, err := deleteMulti(ctx, paths, 2, del)
+	if diff := cmp.Diff([]string{"b", "e"}, failed); diff != "" {
+		t.Errorf("deleteMulti() = (-want, +got):\n%s", diff)
+	}
+	var batchErr *bulk.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("deleteMulti() returned %v, want a *bulk.BatchError", err)
+	}
+	var got []bulk.ItemError
+	for _, item := range batchErr.Items {
+		got = append(got, bulk.ItemError{Index: item.Index, Key: item.Key})
+		if item.Err == nil || item.Err.Error() != "delete error" {
+			t.Errorf("deleteMulti() returned %v for path %q, want delete error", item.Err, item.Key)
+		}
+	}
+	want := []bulk.ItemError{{Index: 1, Key: "b"}, {Index: 4, Key: "e"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("deleteMulti() BatchError items = (-want, +got):\n%s", diff)
+	}
+}
+
+func TestGCS_DeleteMultiConcurrency(t *testing.T) {
+	t.Parallel()
+
+	const concurrency = 3
+	paths := make([]string, 50)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("path%d", i)
+	}
+	var active, maxActive, calls int32
+	del := func(ctx context.Context, path string) error {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		atomic.AddInt32(&calls, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	failed, err := deleteMulti(context.Background(), paths, concurrency, del)
+	if err != nil || len(failed) != 0 {
+		t.Errorf("deleteMulti() = (%v, %v), want no failures", failed, err)
+	}
+	if calls != int32(len(paths)) {
+		t.Errorf("deleteMulti() called del %d times, want %d", calls, len(paths))
+	}
+	if maxActive > concurrency {
+		t.Errorf("deleteMulti() ran %d deletes at once, want at most %d", maxActive, concurrency)
+	}
+}
+
+func TestGCS_DeleteMultiCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	paths := []string{"a", "b"}
+	failed, err := deleteMulti(ctx, paths, 1, func(context.Context, string) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("deleteMulti() = %v, want %v", err, context.Canceled)
+	}
+	if diff := cmp.Diff(paths, failed); diff != "" {
+		t.Errorf("deleteMulti() = (-want, +got):\n%s", diff)
+	}
+}
+
+func TestGCS_PutIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	const path = "put-if-absent.txt"
+
+	created, size, err := gcs.PutIfAbsent(ctx, path, bytes.NewReader([]byte("first")))
+	if err != nil {
+		t.Fatalf("PutIfAbsent() failed: %v", err)
+	}
+	if !created || size != 5 {
+		t.Errorf("PutIfAbsent() = (%v, %v), want (true, 5)", created, size)
+	}
+
+	created, size, err = gcs.PutIfAbsent(ctx, path, bytes.NewReader([]byte("first")))
+	if err != nil {
+		t.Fatalf("PutIfAbsent() failed: %v", err)
+	}
+	if created || size != 0 {
+		t.Errorf("PutIfAbsent() = (%v, %v), want (false, 0) for an existing object", created, size)
+	}
+	created, _, err = gcs.PutIfAbsent(ctx, path, bytes.NewReader([]byte("second")))
+	if !errors.Is(err, ErrObjectPathCollision) || created {
+		t.Errorf("PutIfAbsent() = (%v, %v), want (false, %v) for different content", created, err, ErrObjectPathCollision)
+	}
+	got, err := gcs.Get(ctx, path)
+	if err != nil {
+		t.Errorf("Get() failed: %v", err)
+	}
+	if string(got) != "first" {
+		t.Errorf("Get() = %q, want the first content to be kept", got)
+	}
+}
+
+func TestGCS_CheckCollision(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	const path = "check-collision.txt"
+	if err := gcs.Put(ctx, path, []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	// A precondition failure can happen after part of r was uploaded.
+	for consumed := 0; consumed <= len("content"); consumed++ {
+		for data, want := range map[string]error{"content": nil, "contest": ErrObjectPathCollision, "content+": ErrObjectPathCollision} {
+			h := newCollisionHash()
+			h.Write([]byte(data[:consumed]))
+			if err := gcs.checkCollision(ctx, path, strings.NewReader(data[consumed:]), h); !errors.Is(err, want) {
+				t.Errorf("checkCollision(%q, consumed = %d) = %v, want %v", data, consumed, err, want)
+			}
+		}
+	}
+
+	if err := gcs.checkCollision(ctx, "missing", strings.NewReader("content"), newCollisionHash()); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("checkCollision() = %v, want NotFound for a missing object", err)
+	}
+}
+
+func TestGCS_PutIfAbsentReadError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	const path = "put-if-absent-error.txt"
+	wantErr := errors.New("read error")
+
+	created, _, err := gcs.PutIfAbsent(ctx, path, iotest.ErrReader(wantErr))
+	if !errors.Is(err, wantErr) || created {
+		t.Errorf("PutIfAbsent() = (%v, %v), want (false, %v)", created, err, wantErr)
+	}
+	if _, err := gcs.Get(ctx, path); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("Get() = %v, want NotFound after a failed write", err)
+	}
+}
+
+func TestGCS_PutIfAbsentConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	const (
+		path    = "put-if-absent-concurrent.txt"
+		writers = 10
+	)
+
+	var created int32
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, _, err := gcs.PutIfAbsent(ctx, path, bytes.NewReader([]byte(fmt.Sprintf("writer %d", i))))
+			if err != nil && !errors.Is(err, ErrObjectPathCollision) {
+				t.Errorf("PutIfAbsent() failed: %v", err)
+			}
+			if ok {
+				atomic.AddInt32(&created, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if created != 1 {
+		t.Errorf("%d writers created the object, want exactly 1", created)
+	}
+}
+
+const testKMSKeyName = "projects/p/locations/l/keyRings/r/cryptoKeys/k"
+
+// stubBeforeWrite calls the BeforeWrite option of opts with an As function
+// that exposes obj and w like the gcsblob driver.
+func stubBeforeWrite(t *testing.T, opts *blob.WriterOptions, obj *storage.ObjectHandle, w *storage.Writer) {
+	t.Helper()
+	if opts == nil || opts.BeforeWrite == nil {
+		t.Fatal("writerOptions() returned no BeforeWrite option")
+	}
+	err := opts.BeforeWrite(func(i interface{}) bool {
+		switch p := i.(type) {
+		case ***storage.ObjectHandle:
+			*p = &obj
+			return true
+		case **storage.Writer:
+			*p = w
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		t.Fatalf("BeforeWrite() failed: %v", err)
+	}
+}
+
+func TestGCS_WriterOptionsKMSKey(t *testing.T) {
+	t.Parallel()
+
+	gcs := &BlobGCP{kmsKeyName: testKMSKeyName}
+	for _, doesNotExist := range []bool{false, true} {
+		w := new(storage.Writer)
+		stubBeforeWrite(t, gcs.writerOptions(doesNotExist), new(storage.ObjectHandle), w)
+		if w.KMSKeyName != testKMSKeyName {
+			t.Errorf("writerOptions(%v) set KMSKeyName = %q, want %q", doesNotExist, w.KMSKeyName, testKMSKeyName)
+		}
+	}
+}
+
+func TestGCS_WriterOptionsNoKMSKey(t *testing.T) {
+	t.Parallel()
+
+	gcs := new(BlobGCP)
+	if opts := gcs.writerOptions(false); opts != nil {
+		t.Errorf("writerOptions(false) = %v, want nil", opts)
+	}
+	w := new(storage.Writer)
+	stubBeforeWrite(t, gcs.writerOptions(true), new(storage.ObjectHandle), w)
+	if w.KMSKeyName != "" {
+		t.Errorf("writerOptions(true) set KMSKeyName = %q, want empty", w.KMSKeyName)
+	}
+}
+
+func TestGCS_KMSKeyReadable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	plain := mustGetBucket(ctx, t)
+	if err := plain.Put(ctx, "plain", []byte("plain")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	// Share the in-memory bucket with a store that writes with the key.
+	gcs := &BlobGCP{bucket: plain.bucket, kmsKeyName: testKMSKeyName}
+	if got := gcs.KMSKeyName(); got != testKMSKeyName {
+		t.Errorf("KMSKeyName() = %q, want %q", got, testKMSKeyName)
+	}
+	if err := gcs.Put(ctx, "encrypted", []byte("encrypted")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	for path, want := range map[string]string{"plain": "plain", "encrypted": "encrypted"} {
+		got, err := gcs.Get(ctx, path)
+		if err != nil {
+			t.Errorf("Get(%q) failed: %v", path, err)
+		} else if string(got) != want {
+			t.Errorf("Get(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestGCS_Append(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	if err := gcs.Put(ctx, "append", []byte("hello")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	n, err := Append(ctx, gcs, "append", bytes.NewReader([]byte(" world")))
+	if err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	if n != 6 {
+		t.Errorf("Append() = %d, want 6", n)
+	}
+	got, err := gcs.Get(ctx, "append")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if string(got) != "hello world" {
+		t.Errorf("Get() = %q, want %q", got, "hello world")
+	}
+}
+
+func TestGCS_AppendKeepsHeaders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	headers := ObjectHeaders{
+		ContentType:        "text/plain",
+		ContentDisposition: `attachment; filename="save.txt"`,
+		CacheControl:       "no-cache",
+	}
+	if err := gcs.PutWithHeaders(ctx, "append", []byte("hello"), headers); err != nil {
+		t.Fatalf("PutWithHeaders() failed: %v", err)
+	}
+	if _, err := gcs.Append(ctx, "append", bytes.NewReader([]byte(" world"))); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	attrs, err := gcs.bucket.Attributes(ctx, "append")
+	if err != nil {
+		t.Fatalf("Attributes() failed: %v", err)
+	}
+	if got := attributesHeaders(attrs); got != headers {
+		t.Errorf("headers after Append() = %+v, want %+v", got, headers)
+	}
+}
+
+func TestGCS_AppendErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	if _, err := gcs.Append(ctx, "missing", bytes.NewReader([]byte("data"))); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("Append() = %v, want NotFound", err)
+	}
+
+	// The object is kept if reading the appended content fails.
+	if err := gcs.Put(ctx, "append", []byte("hello")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if _, err := gcs.Append(ctx, "append", iotest.ErrReader(errors.New("read error"))); err == nil {
+		t.Error("Append() succeeded, want error")
+	}
+	got, err := gcs.Get(ctx, "append")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("Get() = %q, want %q", got, "hello")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"cloud.google.com/go/storage"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"gocloud.dev/gcerrors"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrGenerationMismatch is returned by DeleteIfGeneration when the object at
+// the path has a different generation, i.e. it was replaced by a newer object.
+var ErrGenerationMismatch = errors.New("the object has a different generation")
+
+// GenerationDeleter is implemented by BlobStores that keep a generation number
+// for each version of an object and can delete an object only if it is still
+// the same version.
+type GenerationDeleter interface {
+	// Generation returns the generation of the object at path.
+	Generation(ctx context.Context, path string) (int64, error)
+
+	// DeleteIfGeneration deletes the object at path if its generation is
+	// generation. It returns nil if the object doesn't exist, and
+	// ErrGenerationMismatch if a different generation exists.
+	DeleteIfGeneration(ctx context.Context, path string, generation int64) error
+}
+
+// Assert BlobGCP implements GenerationDeleter.
+var _ GenerationDeleter = new(BlobGCP)
+
+// Generation returns the generation of the object at path in bs, or 0 if bs
+// doesn't implement GenerationDeleter.
+func Generation(ctx context.Context, bs BlobStore, path string) (int64, error) {
+	if gd, ok := bs.(GenerationDeleter); ok {
+		return gd.Generation(ctx, path)
+	}
+	return 0, nil
+}
+
+// DeleteIfGeneration deletes the object at path in bs with bs.DeleteIfGeneration.
+// It deletes the object unconditionally with bs.Delete if generation is 0 or bs
+// doesn't implement GenerationDeleter.
+func DeleteIfGeneration(ctx context.Context, bs BlobStore, path string, generation int64) error {
+	if gd, ok := bs.(GenerationDeleter); ok && generation != 0 {
+		return gd.DeleteIfGeneration(ctx, path, generation)
+	}
+	return bs.Delete(ctx, path)
+}
+
+// Generation returns the generation of the object at path.
+// Drivers other than Cloud Storage, such as the in-memory driver used for
+// testing, don't have generations, so they are emulated with the modification
+// time of the object.
+func (b *BlobGCP) Generation(ctx context.Context, path string) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.Generation")
+	defer span.End()
+
+	attrs, err := b.bucket.Attributes(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+	var oa storage.ObjectAttrs
+	if attrs.As(&oa) {
+		return oa.Generation, nil
+	}
+	return attrs.ModTime.UnixNano(), nil
+}
+
+// DeleteIfGeneration deletes the object at path if its generation is generation.
+// It uses the GenerationMatch precondition on Cloud Storage. For other drivers,
+// the precondition is emulated by serializing the check and the delete within
+// the process.
+func (b *BlobGCP) DeleteIfGeneration(ctx context.Context, path string, generation int64) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.DeleteIfGeneration")
+	defer span.End()
+
+	var client *storage.Client
+	if !b.bucket.As(&client) {
+		return b.deleteIfGenerationEmulated(ctx, path, generation)
+	}
+	obj := client.Bucket(b.bucketName).Object(path).If(storage.Conditions{GenerationMatch: generation})
+	err := obj.Delete(ctx)
+	var gerr *googleapi.Error
+	switch {
+	case errors.Is(err, storage.ErrObjectNotExist):
+		return nil
+	case errors.As(err, &gerr) && gerr.Code == http.StatusPreconditionFailed:
+		return ErrGenerationMismatch
+	}
+	return err
+}
+
+func (b *BlobGCP) deleteIfGenerationEmulated(ctx context.Context, path string, generation int64) error {
+	b.emulateMu.Lock()
+	defer b.emulateMu.Unlock()
+	current, err := b.Generation(ctx, path)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if current != generation {
+		return ErrGenerationMismatch
+	}
+	if err := b.bucket.Delete(ctx, path); gcerrors.Code(err) != gcerrors.NotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gocloud.dev/gcerrors"
+)
+
+func mustGetGeneration(ctx context.Context, t *testing.T, gcs *BlobGCP, path string) int64 {
+	t.Helper()
+	g, err := gcs.Generation(ctx, path)
+	if err != nil {
+		t.Fatalf("Generation(%q) failed: %v", path, err)
+	}
+	return g
+}
+
+func TestGCS_DeleteIfGeneration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	const path = "delete-if-generation.txt"
+	if err := gcs.Put(ctx, path, []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if err := gcs.DeleteIfGeneration(ctx, path, mustGetGeneration(ctx, t, gcs, path)); err != nil {
+		t.Errorf("DeleteIfGeneration() failed: %v", err)
+	}
+	if _, err := gcs.Get(ctx, path); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("Get() = %v, want NotFound after DeleteIfGeneration()", err)
+	}
+}
+
+func TestGCS_DeleteIfGenerationRetryAfterReupload(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	const path = "delete-if-generation-retry.txt"
+	if err := gcs.Put(ctx, path, []byte("old")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	old := mustGetGeneration(ctx, t, gcs, path)
+	if err := gcs.DeleteIfGeneration(ctx, path, old); err != nil {
+		t.Fatalf("DeleteIfGeneration() failed: %v", err)
+	}
+	if err := gcs.Put(ctx, path, []byte("new")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if mustGetGeneration(ctx, t, gcs, path) == old {
+		t.Fatal("Generation() didn't change after the object was replaced")
+	}
+
+	// The retry must not delete the new object.
+	if err := gcs.DeleteIfGeneration(ctx, path, old); !errors.Is(err, ErrGenerationMismatch) {
+		t.Errorf("DeleteIfGeneration() = %v, want %v", err, ErrGenerationMismatch)
+	}
+	got, err := gcs.Get(ctx, path)
+	if err != nil || string(got) != "new" {
+		t.Errorf("Get() = (%q, %v), want (\"new\", nil)", got, err)
+	}
+}
+
+func TestGCS_DeleteIfGenerationMissing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	if err := gcs.DeleteIfGeneration(ctx, "missing.txt", 42); err != nil {
+		t.Errorf("DeleteIfGeneration() = %v, want nil for a missing object", err)
+	}
+	if _, err := gcs.Generation(ctx, "missing.txt"); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("Generation() = %v, want NotFound", err)
+	}
+}
+
+func TestDeleteIfGeneration_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &countingBlobStore{BlobStore: mustGetBucket(ctx, t)}
+	if err := store.Put(ctx, "path", []byte("content")); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if g, err := Generation(ctx, store, "path"); g != 0 || err != nil {
+		t.Errorf("Generation() = (%v, %v), want (0, nil) for stores without generations", g, err)
+	}
+	if err := DeleteIfGeneration(ctx, store, "path", 0); err != nil {
+		t.Errorf("DeleteIfGeneration() failed: %v", err)
+	}
+	if _, err := store.Get(ctx, "path"); gcerrors.Code(err) != gcerrors.NotFound {
+		t.Errorf("Get() = %v, want NotFound", err)
+	}
+}
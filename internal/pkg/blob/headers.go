@@ -0,0 +1,88 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ObjectHeaders are HTTP headers stored with an object and returned when the
+// object is downloaded, e.g. with a signed URL. Empty headers are not set.
+type ObjectHeaders struct {
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+}
+
+// IsZero reports whether no header is set.
+func (h ObjectHeaders) IsZero() bool {
+	return h == ObjectHeaders{}
+}
+
+// ErrHeadersNotSupported is returned when headers are given for a BlobStore
+// that doesn't implement HeaderWriter.
+var ErrHeadersNotSupported = errors.New("the blob store doesn't support object headers")
+
+// HeaderWriter is implemented by BlobStores that can set ObjectHeaders on
+// objects and signed URLs.
+type HeaderWriter interface {
+	PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error
+	NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error)
+	SignUrlWithHeaders(ctx context.Context, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error)
+}
+
+// PutWithHeaders inserts a blob at path in bs with headers.
+// It returns ErrHeadersNotSupported if headers are set and bs doesn't
+// implement HeaderWriter.
+func PutWithHeaders(ctx context.Context, bs BlobStore, path string, data []byte, headers ObjectHeaders) error {
+	if headers.IsZero() {
+		return bs.Put(ctx, path, data)
+	}
+	hw, ok := bs.(HeaderWriter)
+	if !ok {
+		return ErrHeadersNotSupported
+	}
+	return hw.PutWithHeaders(ctx, path, data, headers)
+}
+
+// NewWriterWithHeaders creates a new object at path in bs with headers.
+// It returns ErrHeadersNotSupported if headers are set and bs doesn't
+// implement HeaderWriter.
+func NewWriterWithHeaders(ctx context.Context, bs BlobStore, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	if headers.IsZero() {
+		return bs.NewWriter(ctx, path)
+	}
+	hw, ok := bs.(HeaderWriter)
+	if !ok {
+		return nil, ErrHeadersNotSupported
+	}
+	return hw.NewWriterWithHeaders(ctx, path, headers)
+}
+
+// SignUrlWithHeaders returns a signed URL for key in bs with headers.
+// It returns ErrHeadersNotSupported if headers are set and bs doesn't
+// implement HeaderWriter.
+func SignUrlWithHeaders(ctx context.Context, bs BlobStore, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error) {
+	if headers.IsZero() {
+		return bs.SignUrl(ctx, key, ttlInSeconds, method)
+	}
+	hw, ok := bs.(HeaderWriter)
+	if !ok {
+		return "", ErrHeadersNotSupported
+	}
+	return hw.SignUrlWithHeaders(ctx, key, ttlInSeconds, method, headers)
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+)
+
+var testHeaders = ObjectHeaders{
+	ContentType:        "application/json",
+	ContentDisposition: `attachment; filename="save.dat"`,
+	CacheControl:       "private, max-age=3600",
+}
+
+func assertObjectHeaders(ctx context.Context, t *testing.T, gcs *BlobGCP, path string, want ObjectHeaders) {
+	t.Helper()
+	attrs, err := gcs.bucket.Attributes(ctx, path)
+	require.NoError(t, err)
+	got := ObjectHeaders{ContentDisposition: attrs.ContentDisposition, CacheControl: attrs.CacheControl}
+	// The content type is detected from the content if not set.
+	if want.ContentType != "" {
+		got.ContentType = attrs.ContentType
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestGCS_PutWithHeaders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+
+	require.NoError(t, PutWithHeaders(ctx, gcs, "headers", []byte("content"), testHeaders))
+	assertObjectHeaders(ctx, t, gcs, "headers", testHeaders)
+
+	require.NoError(t, PutWithHeaders(ctx, gcs, "no-headers", []byte("content"), ObjectHeaders{}))
+	assertObjectHeaders(ctx, t, gcs, "no-headers", ObjectHeaders{})
+}
+
+func TestGCS_NewWriterWithHeaders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+
+	w, err := NewWriterWithHeaders(ctx, gcs, "writer", testHeaders)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("content"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assertObjectHeaders(ctx, t, gcs, "writer", testHeaders)
+}
+
+func TestGCS_HeaderWriterOptionsKeepKMSKey(t *testing.T) {
+	t.Parallel()
+
+	gcs := &BlobGCP{kmsKeyName: testKMSKeyName}
+	opts := gcs.headerWriterOptions(testHeaders)
+	assert.Equal(t, testHeaders.ContentDisposition, opts.ContentDisposition)
+	assert.Equal(t, testHeaders.CacheControl, opts.CacheControl)
+	w := new(storage.Writer)
+	stubBeforeWrite(t, opts, nil, w)
+	assert.Equal(t, testKMSKeyName, w.KMSKeyName)
+}
+
+// signWithOptions calls the BeforeSign option of opts like the gcsblob driver.
+func signWithOptions(t *testing.T, opts *blob.SignedURLOptions) *storage.SignedURLOptions {
+	t.Helper()
+	so := new(storage.SignedURLOptions)
+	if opts.BeforeSign == nil {
+		return so
+	}
+	err := opts.BeforeSign(func(i interface{}) bool {
+		p, ok := i.(**storage.SignedURLOptions)
+		if ok {
+			*p = so
+		}
+		return ok
+	})
+	require.NoError(t, err)
+	return so
+}
+
+func TestSignedURLOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := signedURLOptions(60, http.MethodGet, ObjectHeaders{})
+	assert.Nil(t, opts.BeforeSign)
+
+	opts = signedURLOptions(60, http.MethodGet, testHeaders)
+	assert.Empty(t, opts.ContentType)
+	so := signWithOptions(t, opts)
+	assert.Equal(t, testHeaders.ContentType, so.QueryParameters.Get("response-content-type"))
+	assert.Equal(t, testHeaders.ContentDisposition, so.QueryParameters.Get("response-content-disposition"))
+	assert.Equal(t, testHeaders.CacheControl, so.QueryParameters.Get("response-cache-control"))
+	assert.Empty(t, so.Headers)
+
+	opts = signedURLOptions(60, http.MethodPut, testHeaders)
+	// The uploader must send the signed content type.
+	assert.Equal(t, testHeaders.ContentType, opts.ContentType)
+	so = signWithOptions(t, opts)
+	assert.ElementsMatch(t, []string{
+		"Content-Disposition:" + testHeaders.ContentDisposition,
+		"Cache-Control:" + testHeaders.CacheControl,
+	}, so.Headers)
+	assert.Empty(t, so.QueryParameters)
+
+	so = signWithOptions(t, signedURLOptions(60, http.MethodGet, ObjectHeaders{CacheControl: "no-cache"}))
+	assert.False(t, so.QueryParameters.Has("response-content-disposition"))
+	assert.False(t, so.QueryParameters.Has("response-content-type"))
+}
+
+// noHeaderBlobStore hides the HeaderWriter implementation of BlobStore.
+type noHeaderBlobStore struct {
+	BlobStore
+}
+
+func TestHeaders_NotSupported(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bs := noHeaderBlobStore{mustGetBucket(ctx, t)}
+
+	err := PutWithHeaders(ctx, bs, "path", []byte("content"), testHeaders)
+	assert.True(t, errors.Is(err, ErrHeadersNotSupported))
+	_, err = NewWriterWithHeaders(ctx, bs, "path", testHeaders)
+	assert.True(t, errors.Is(err, ErrHeadersNotSupported))
+	_, err = SignUrlWithHeaders(ctx, bs, "path", 60, http.MethodGet, testHeaders)
+	assert.True(t, errors.Is(err, ErrHeadersNotSupported))
+
+	// No headers are needed for plain writes.
+	assert.NoError(t, PutWithHeaders(ctx, bs, "path", []byte("content"), ObjectHeaders{}))
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+)
+
+// ErrChecksumMismatch is returned by GetVerified when the content of the
+// object doesn't match the expected checksums.
+var ErrChecksumMismatch = errors.New("the object content doesn't match the checksums")
+
+// verifyChecksums returns an error wrapping ErrChecksumMismatch if data
+// doesn't match c. Empty MD5 and missing CRC32C are not checked.
+func verifyChecksums(c checksums.Checksums, data []byte) error {
+	if len(c.MD5) != 0 {
+		if sum := md5.Sum(data); !bytes.Equal(sum[:], c.MD5) {
+			return fmt.Errorf("%w: MD5 is %x, want %x", ErrChecksumMismatch, sum[:], c.MD5)
+		}
+	}
+	if c.HasCRC32C {
+		if crc := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)); crc != c.GetCRC32C() {
+			return fmt.Errorf("%w: CRC32C is %v, want %v", ErrChecksumMismatch, crc, c.GetCRC32C())
+		}
+	}
+	return nil
+}
+
+// GetVerified reads the object at path from primary and checks it against want.
+// If the content doesn't match and replica is not nil, it reads the object
+// from replica instead. If the replica copy matches, GetVerified rewrites the
+// primary object with it and returns it. The primary is not modified if the
+// replica copy doesn't match or can't be read.
+//
+// It returns an error wrapping ErrChecksumMismatch if no verified copy is
+// found, and the errors of primary.Get as-is without trying the replica.
+func GetVerified(ctx context.Context, primary, replica BlobStore, path string, want checksums.Checksums) ([]byte, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "blob.GetVerified")
+	defer span.End()
+
+	data, err := primary.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	verr := verifyChecksums(want, data)
+	if verr == nil {
+		return data, nil
+	}
+	if replica == nil {
+		return nil, verr
+	}
+
+	log.Warnf("Object (%v) is corrupt in the primary store, reading the replica: %v", path, verr)
+	good, err := replica.Get(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("%w in the primary store, and reading the replica failed: %v", verr, err)
+	}
+	if err := verifyChecksums(want, good); err != nil {
+		return nil, fmt.Errorf("%w in the primary store, and the replica is also corrupt: %v", verr, err)
+	}
+	if err := primary.Put(ctx, path, good); err != nil {
+		// The good copy can still be served; the object is healed next time.
+		log.Errorf("Failed to heal object (%v) in the primary store: %v", path, err)
+		return good, nil
+	}
+	log.Infof("Healed object (%v) in the primary store from the replica", path)
+	return good, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+)
+
+func checksumsOf(data []byte) checksums.Checksums {
+	sum := md5.Sum(data)
+	c := checksums.Checksums{MD5: sum[:]}
+	c.SetCRC32C(crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+	return c
+}
+
+func mustPut(ctx context.Context, t *testing.T, bs BlobStore, path string, content []byte) {
+	t.Helper()
+	if err := bs.Put(ctx, path, content); err != nil {
+		t.Fatalf("Put(%q) failed: %v", path, err)
+	}
+}
+
+func TestGetVerified_Heal(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	primary, replica := mustGetBucket(ctx, t), mustGetBucket(ctx, t)
+	const path = "heal.txt"
+	good := []byte("good content")
+	mustPut(ctx, t, primary, path, []byte("bad content"))
+	mustPut(ctx, t, replica, path, good)
+
+	got, err := GetVerified(ctx, primary, replica, path, checksumsOf(good))
+	if err != nil || string(got) != string(good) {
+		t.Fatalf("GetVerified() = (%q, %v), want (%q, nil)", got, err, good)
+	}
+	if healed, err := primary.Get(ctx, path); err != nil || string(healed) != string(good) {
+		t.Errorf("primary.Get() = (%q, %v), want (%q, nil) after healing", healed, err, good)
+	}
+}
+
+func TestGetVerified_BadReplica(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	primary, replica := mustGetBucket(ctx, t), mustGetBucket(ctx, t)
+	const path = "bad-replica.txt"
+	mustPut(ctx, t, primary, path, []byte("bad primary"))
+	mustPut(ctx, t, replica, path, []byte("bad replica"))
+
+	want := checksumsOf([]byte("good content"))
+	if got, err := GetVerified(ctx, primary, replica, path, want); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("GetVerified() = (%q, %v), want %v", got, err, ErrChecksumMismatch)
+	}
+	if got, err := primary.Get(ctx, path); err != nil || string(got) != "bad primary" {
+		t.Errorf("primary.Get() = (%q, %v), want the primary unchanged", got, err)
+	}
+
+	// A missing replica copy doesn't heal either.
+	replica = mustGetBucket(ctx, t)
+	if got, err := GetVerified(ctx, primary, replica, path, want); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("GetVerified() = (%q, %v), want %v", got, err, ErrChecksumMismatch)
+	}
+}
+
+func TestGetVerified_NoReplica(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	primary := mustGetBucket(ctx, t)
+	const path = "no-replica.txt"
+	content := []byte("content")
+	mustPut(ctx, t, primary, path, content)
+
+	got, err := GetVerified(ctx, primary, nil, path, checksumsOf(content))
+	if err != nil || string(got) != string(content) {
+		t.Errorf("GetVerified() = (%q, %v), want (%q, nil)", got, err, content)
+	}
+	// Empty checksums are not checked.
+	if _, err := GetVerified(ctx, primary, nil, path, checksums.Checksums{}); err != nil {
+		t.Errorf("GetVerified() with no checksums failed: %v", err)
+	}
+	if _, err := GetVerified(ctx, primary, nil, path, checksumsOf([]byte("other"))); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("GetVerified() = %v, want %v", err, ErrChecksumMismatch)
+	}
+}
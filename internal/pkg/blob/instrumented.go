@@ -0,0 +1,304 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metrics"
+)
+
+// Operation names used as metric labels by InstrumentedBlobStore.
+const (
+	OpPut            = "put"
+	OpPutIfAbsent    = "put_if_absent"
+	OpWrite          = "write"
+	OpGet            = "get"
+	OpRead           = "read"
+	OpDelete         = "delete"
+	OpDeleteMulti    = "delete_multi"
+	OpList           = "list"
+	OpStat           = "stat"
+	OpSignURL        = "sign_url"
+	OpNewWriter      = "new_writer"
+	OpNewRangeReader = "new_range_reader"
+	OpGeneration     = "generation"
+	OpDeleteIfGen    = "delete_if_generation"
+	OpAppend         = "append"
+	OpExists         = "exists"
+	OpEnsureReady    = "ensure_ready"
+)
+
+// InstrumentedBlobStore wraps a BlobStore and records latency and bytes transferred
+// of each operation to a metrics.Collector.
+// Streaming writes and reads are recorded when the writer or reader is closed.
+// The optional interfaces, such as Statter, HeaderWriter, GenerationDeleter,
+// KMSKeyNamer, Appender, ExistenceChecker, and ReadinessChecker, are recorded
+// and forwarded to the underlying store, and the package functions fall back
+// the same way as for the underlying store if it doesn't implement them.
+type InstrumentedBlobStore struct {
+	BlobStore
+	collector metrics.Collector
+}
+
+// Assert InstrumentedBlobStore implements the BlobStore and optional interfaces.
+var _ BlobStore = new(InstrumentedBlobStore)
+var _ Statter = new(InstrumentedBlobStore)
+var _ HeaderWriter = new(InstrumentedBlobStore)
+var _ GenerationDeleter = new(InstrumentedBlobStore)
+var _ KMSKeyNamer = new(InstrumentedBlobStore)
+var _ Appender = new(InstrumentedBlobStore)
+var _ ExistenceChecker = new(InstrumentedBlobStore)
+var _ ReadinessChecker = new(InstrumentedBlobStore)
+
+// NewInstrumentedBlobStore returns a BlobStore that records metrics of bs to collector.
+// A nil collector discards all measurements.
+func NewInstrumentedBlobStore(bs BlobStore, collector metrics.Collector) *InstrumentedBlobStore {
+	if collector == nil {
+		collector = metrics.NopCollector{}
+	}
+	return &InstrumentedBlobStore{BlobStore: bs, collector: collector}
+}
+
+func (b *InstrumentedBlobStore) record(op string, start time.Time, n int64, err error) {
+	outcome := metrics.OutcomeOf(err)
+	b.collector.ObserveLatency(op, outcome, time.Since(start))
+	if n > 0 {
+		b.collector.AddBytes(op, outcome, n)
+	}
+}
+
+// Put inserts a blob at the given path.
+func (b *InstrumentedBlobStore) Put(ctx context.Context, path string, data []byte) error {
+	start := time.Now()
+	err := b.BlobStore.Put(ctx, path, data)
+	b.record(OpPut, start, int64(len(data)), err)
+	return err
+}
+
+// PutIfAbsent writes the content of r to path only if the object doesn't exist.
+func (b *InstrumentedBlobStore) PutIfAbsent(ctx context.Context, path string, r io.Reader) (bool, int64, error) {
+	start := time.Now()
+	created, n, err := b.BlobStore.PutIfAbsent(ctx, path, r)
+	b.record(OpPutIfAbsent, start, n, err)
+	return created, n, err
+}
+
+// NewWriter creates a new object with path and returns an io.WriteCloser
+// instance that records the write when closed.
+func (b *InstrumentedBlobStore) NewWriter(ctx context.Context, path string) (io.WriteCloser, error) {
+	start := time.Now()
+	w, err := b.BlobStore.NewWriter(ctx, path)
+	if err != nil {
+		b.record(OpNewWriter, start, 0, err)
+		return nil, err
+	}
+	return &instrumentedWriter{w: w, store: b, start: start}, nil
+}
+
+// Get retrieves the data given a blob path.
+func (b *InstrumentedBlobStore) Get(ctx context.Context, path string) ([]byte, error) {
+	start := time.Now()
+	data, err := b.BlobStore.Get(ctx, path)
+	b.record(OpGet, start, int64(len(data)), err)
+	return data, err
+}
+
+// NewReader is an alias to NewRangeReader(ctx, path, 0, -1).
+func (b *InstrumentedBlobStore) NewReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	return b.NewRangeReader(ctx, path, 0, -1)
+}
+
+// NewRangeReader returns an io.ReadCloser instance that records the read when closed.
+func (b *InstrumentedBlobStore) NewRangeReader(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	start := time.Now()
+	r, err := b.BlobStore.NewRangeReader(ctx, path, offset, length)
+	if err != nil {
+		b.record(OpNewRangeReader, start, 0, err)
+		return nil, err
+	}
+	return &instrumentedReader{r: r, store: b, start: start}, nil
+}
+
+// Delete deletes the blob at the given path.
+func (b *InstrumentedBlobStore) Delete(ctx context.Context, path string) error {
+	start := time.Now()
+	err := b.BlobStore.Delete(ctx, path)
+	b.record(OpDelete, start, 0, err)
+	return err
+}
+
+// DeleteMulti deletes the blobs at the given paths and returns the paths that failed.
+// A call with any failed path is recorded as an error.
+func (b *InstrumentedBlobStore) DeleteMulti(ctx context.Context, paths []string) ([]string, error) {
+	start := time.Now()
+	failed, err := b.BlobStore.DeleteMulti(ctx, paths)
+	b.record(OpDeleteMulti, start, 0, err)
+	return failed, err
+}
+
+// List returns paths of all objects that begin with prefix.
+func (b *InstrumentedBlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	start := time.Now()
+	paths, err := b.BlobStore.List(ctx, prefix)
+	b.record(OpList, start, 0, err)
+	return paths, err
+}
+
+// Stat returns the attributes of the object at path.
+func (b *InstrumentedBlobStore) Stat(ctx context.Context, path string) (ObjectAttrs, error) {
+	start := time.Now()
+	attrs, err := Stat(ctx, b.BlobStore, path)
+	b.record(OpStat, start, 0, err)
+	return attrs, err
+}
+
+// SignUrl returns a signed URL for the given blob key.
+func (b *InstrumentedBlobStore) SignUrl(ctx context.Context, key string, ttlInSeconds int64, method string) (string, error) {
+	start := time.Now()
+	url, err := b.BlobStore.SignUrl(ctx, key, ttlInSeconds, method)
+	b.record(OpSignURL, start, 0, err)
+	return url, err
+}
+
+// PutWithHeaders inserts a blob at path with headers and records it as OpPut.
+func (b *InstrumentedBlobStore) PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error {
+	start := time.Now()
+	err := PutWithHeaders(ctx, b.BlobStore, path, data, headers)
+	b.record(OpPut, start, int64(len(data)), err)
+	return err
+}
+
+// NewWriterWithHeaders is the same as NewWriter, but it creates the object with headers.
+func (b *InstrumentedBlobStore) NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	start := time.Now()
+	w, err := NewWriterWithHeaders(ctx, b.BlobStore, path, headers)
+	if err != nil {
+		b.record(OpNewWriter, start, 0, err)
+		return nil, err
+	}
+	return &instrumentedWriter{w: w, store: b, start: start}, nil
+}
+
+// SignUrlWithHeaders returns a signed URL for key with headers and records it
+// as OpSignURL.
+func (b *InstrumentedBlobStore) SignUrlWithHeaders(ctx context.Context, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error) {
+	start := time.Now()
+	url, err := SignUrlWithHeaders(ctx, b.BlobStore, key, ttlInSeconds, method, headers)
+	b.record(OpSignURL, start, 0, err)
+	return url, err
+}
+
+// Generation returns the generation of the object at path.
+func (b *InstrumentedBlobStore) Generation(ctx context.Context, path string) (int64, error) {
+	start := time.Now()
+	gen, err := Generation(ctx, b.BlobStore, path)
+	b.record(OpGeneration, start, 0, err)
+	return gen, err
+}
+
+// DeleteIfGeneration deletes the object at path if its generation is generation.
+func (b *InstrumentedBlobStore) DeleteIfGeneration(ctx context.Context, path string, generation int64) error {
+	start := time.Now()
+	err := DeleteIfGeneration(ctx, b.BlobStore, path, generation)
+	b.record(OpDeleteIfGen, start, 0, err)
+	return err
+}
+
+// KMSKeyName returns the name of the key the underlying store encrypts new
+// objects with.
+func (b *InstrumentedBlobStore) KMSKeyName() string {
+	return KMSKeyName(b.BlobStore)
+}
+
+// Append appends the content of r to the existing object at path.
+func (b *InstrumentedBlobStore) Append(ctx context.Context, path string, r io.Reader) (int64, error) {
+	start := time.Now()
+	n, err := Append(ctx, b.BlobStore, path, r)
+	b.record(OpAppend, start, n, err)
+	return n, err
+}
+
+// Exists reports whether the object at path exists.
+func (b *InstrumentedBlobStore) Exists(ctx context.Context, path string) (bool, error) {
+	start := time.Now()
+	exists, err := Exists(ctx, b.BlobStore, path)
+	b.record(OpExists, start, 0, err)
+	return exists, err
+}
+
+// EnsureReady verifies that the underlying store is ready to store objects.
+func (b *InstrumentedBlobStore) EnsureReady(ctx context.Context) error {
+	start := time.Now()
+	err := EnsureReady(ctx, b.BlobStore)
+	b.record(OpEnsureReady, start, 0, err)
+	return err
+}
+
+type instrumentedWriter struct {
+	w     io.WriteCloser
+	store *InstrumentedBlobStore
+	start time.Time
+	n     int64
+	err   error
+}
+
+func (w *instrumentedWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *instrumentedWriter) Close() error {
+	closeErr := w.w.Close()
+	err := closeErr
+	if err == nil {
+		err = w.err
+	}
+	w.store.record(OpWrite, w.start, w.n, err)
+	return closeErr
+}
+
+type instrumentedReader struct {
+	r     io.ReadCloser
+	store *InstrumentedBlobStore
+	start time.Time
+	n     int64
+	err   error
+}
+
+func (r *instrumentedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+func (r *instrumentedReader) Close() error {
+	closeErr := r.r.Close()
+	err := closeErr
+	if err == nil {
+		err = r.err
+	}
+	r.store.record(OpRead, r.start, r.n, err)
+	return closeErr
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleforgames/open-saves/internal/pkg/metrics"
+)
+
+type fakeMeasurement struct {
+	Op      string
+	Outcome metrics.Outcome
+	Bytes   int64
+}
+
+type fakeCollector struct {
+	mu        sync.Mutex
+	latencies []fakeMeasurement
+	bytes     []fakeMeasurement
+}
+
+func (c *fakeCollector) ObserveLatency(op string, outcome metrics.Outcome, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latencies = append(c.latencies, fakeMeasurement{Op: op, Outcome: outcome})
+}
+
+func (c *fakeCollector) AddBytes(op string, outcome metrics.Outcome, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bytes = append(c.bytes, fakeMeasurement{Op: op, Outcome: outcome, Bytes: n})
+}
+
+func TestInstrumentedBlobStore_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	collector := new(fakeCollector)
+	bs := NewInstrumentedBlobStore(mustGetBucket(ctx, t), collector)
+	const path = "instrumented.txt"
+	data := []byte("hello world")
+
+	if err := bs.Put(ctx, path, data); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if _, err := bs.Get(ctx, path); err != nil {
+		t.Errorf("Get() failed: %v", err)
+	}
+	if err := bs.Delete(ctx, path); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+	// Deleting again should record an error.
+	if err := bs.Delete(ctx, path); err == nil {
+		t.Errorf("Delete() should fail for a non-existent object")
+	}
+
+	wantLatencies := []fakeMeasurement{
+		{Op: OpPut, Outcome: metrics.Success},
+		{Op: OpGet, Outcome: metrics.Success},
+		{Op: OpDelete, Outcome: metrics.Success},
+		{Op: OpDelete, Outcome: metrics.Error},
+	}
+	if diff := cmp.Diff(wantLatencies, collector.latencies); diff != "" {
+		t.Errorf("ObserveLatency() calls = (-want, +got):\n%s", diff)
+	}
+	wantBytes := []fakeMeasurement{
+		{Op: OpPut, Outcome: metrics.Success, Bytes: int64(len(data))},
+		{Op: OpGet, Outcome: metrics.Success, Bytes: int64(len(data))},
+	}
+	if diff := cmp.Diff(wantBytes, collector.bytes); diff != "" {
+		t.Errorf("AddBytes() calls = (-want, +got):\n%s", diff)
+	}
+}
+
+func TestInstrumentedBlobStore_Streams(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	collector := new(fakeCollector)
+	bs := NewInstrumentedBlobStore(mustGetBucket(ctx, t), collector)
+	const path = "instrumented-stream.txt"
+	data := []byte("Lorem ipsum dolor sit amet")
+
+	w, err := bs.NewWriter(ctx, path)
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	w.Write(data[:10])
+	w.Write(data[10:])
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	r, err := bs.NewRangeReader(ctx, path, 5, 10)
+	if err != nil {
+		t.Fatalf("NewRangeReader() failed: %v", err)
+	}
+	if _, err := io.ReadAll(r); err != nil {
+		t.Errorf("ReadAll() failed: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+
+	wantBytes := []fakeMeasurement{
+		{Op: OpWrite, Outcome: metrics.Success, Bytes: int64(len(data))},
+		{Op: OpRead, Outcome: metrics.Success, Bytes: 10},
+	}
+	if diff := cmp.Diff(wantBytes, collector.bytes); diff != "" {
+		t.Errorf("AddBytes() calls = (-want, +got):\n%s", diff)
+	}
+}
+
+func TestInstrumentedBlobStore_OptionalInterfaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	collector := new(fakeCollector)
+	checkOptionalInterfaces(ctx, t, NewInstrumentedBlobStore(mustGetKMSBucket(ctx, t), collector))
+
+	want := []string{OpPut, OpAppend, OpExists, OpGeneration, OpDeleteIfGen, OpDeleteIfGen, OpExists, OpEnsureReady}
+	var got []string
+	for _, m := range collector.latencies {
+		got = append(got, m.Op)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("recorded operations (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/googleforgames/open-saves/internal/pkg/bulk"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+)
+
+// StoreObjectPath returns the path of objectPath prefixed by the store segment
+// that MultiBucketBlobStore uses to route operations. It is
+// blobref.StoreObjectPath, which BlobRefs with ShardedByStore use.
+func StoreObjectPath(storeKey, objectPath string) string {
+	return blobref.StoreObjectPath(storeKey, objectPath)
+}
+
+// MultiBucketBlobStore composes BlobStores of multiple buckets and routes
+// each operation to a bucket by the store segment of the path, as returned by
+// StoreObjectPath, using a BucketRouter.
+// Paths without a store segment, such as object paths written before sharding
+// was enabled and chunk objects, are routed to the first bucket of the router.
+// The optional interfaces, such as Statter, HeaderWriter, GenerationDeleter,
+// Appender, and ExistenceChecker, are routed the same way, and the package
+// functions fall back the same way as for the store of the bucket if it
+// doesn't implement them.
+type MultiBucketBlobStore struct {
+	router *BucketRouter
+	stores map[string]BlobStore
+}
+
+// Assert MultiBucketBlobStore implements the BlobStore and optional interfaces.
+var _ BlobStore = new(MultiBucketBlobStore)
+var _ Statter = new(MultiBucketBlobStore)
+var _ HeaderWriter = new(MultiBucketBlobStore)
+var _ GenerationDeleter = new(MultiBucketBlobStore)
+var _ KMSKeyNamer = new(MultiBucketBlobStore)
+var _ Appender = new(MultiBucketBlobStore)
+var _ ExistenceChecker = new(MultiBucketBlobStore)
+
+// NewMultiBucketBlobStore returns a MultiBucketBlobStore that routes operations
+// with router to stores, which maps bucket names to BlobStores.
+// Every bucket of router must have a BlobStore in stores.
+func NewMultiBucketBlobStore(router *BucketRouter, stores map[string]BlobStore) (*MultiBucketBlobStore, error) {
+	m := &MultiBucketBlobStore{router: router, stores: make(map[string]BlobStore)}
+	for _, b := range router.Buckets() {
+		bs, ok := stores[b]
+		if !ok || bs == nil {
+			return nil, fmt.Errorf("no BlobStore is given for bucket %q", b)
+		}
+		m.stores[b] = bs
+	}
+	return m, nil
+}
+
+// BucketFor returns the name of the bucket that path is routed to.
+func (m *MultiBucketBlobStore) BucketFor(path string) string {
+	if storeKey, ok := blobref.ParseStoreObjectPath(path); ok {
+		return m.router.Route(storeKey)
+	}
+	return m.router.buckets[0]
+}
+
+func (m *MultiBucketBlobStore) route(path string) BlobStore {
+	return m.stores[m.BucketFor(path)]
+}
+
+// Put inserts a blob at the given path.
+func (m *MultiBucketBlobStore) Put(ctx context.Context, path string, data []byte) error {
+	return m.route(path).Put(ctx, path, data)
+}
+
+// PutIfAbsent writes the content of r to path only if the object doesn't exist.
+func (m *MultiBucketBlobStore) PutIfAbsent(ctx context.Context, path string, r io.Reader) (bool, int64, error) {
+	return m.route(path).PutIfAbsent(ctx, path, r)
+}
+
+// NewWriter creates a new object with path and returns an io.WriteCloser
+// instance for the object.
+func (m *MultiBucketBlobStore) NewWriter(ctx context.Context, path string) (io.WriteCloser, error) {
+	return m.route(path).NewWriter(ctx, path)
+}
+
+// Get retrieves the data given a blob path.
+func (m *MultiBucketBlobStore) Get(ctx context.Context, path string) ([]byte, error) {
+	return m.route(path).Get(ctx, path)
+}
+
+// NewReader is an alias to NewRangeReader(ctx, path, 0, -1).
+func (m *MultiBucketBlobStore) NewReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	return m.route(path).NewReader(ctx, path)
+}
+
+// NewRangeReader returns an io.ReadCloser instance for the object specified by path.
+func (m *MultiBucketBlobStore) NewRangeReader(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	return m.route(path).NewRangeReader(ctx, path, offset, length)
+}
+
+// Delete deletes the blob at the given path.
+func (m *MultiBucketBlobStore) Delete(ctx context.Context, path string) error {
+	return m.route(path).Delete(ctx, path)
+}
+
+// Stat returns the attributes of the object at path.
+func (m *MultiBucketBlobStore) Stat(ctx context.Context, path string) (ObjectAttrs, error) {
+	return Stat(ctx, m.route(path), path)
+}
+
+// DeleteMulti groups paths by bucket and deletes them with DeleteMulti of
+// each bucket. Returns the failed paths of all buckets in the original order,
+// and a *bulk.BatchError with indexes into paths.
+func (m *MultiBucketBlobStore) DeleteMulti(ctx context.Context, paths []string) ([]string, error) {
+	groups := make(map[string][]string)
+	indexes := make(map[string][]int)
+	for i, p := range paths {
+		b := m.BucketFor(p)
+		groups[b] = append(groups[b], p)
+		indexes[b] = append(indexes[b], i)
+	}
+	batchErr := new(bulk.BatchError)
+	for _, b := range m.router.buckets {
+		if len(groups[b]) == 0 {
+			continue
+		}
+		_, err := m.stores[b].DeleteMulti(ctx, groups[b])
+		if err == nil {
+			continue
+		}
+		if be, ok := err.(*bulk.BatchError); ok {
+			for _, item := range be.Items {
+				batchErr.Add(indexes[b][item.Index], item.Key, item.Err)
+			}
+			continue
+		}
+		for j, p := range groups[b] {
+			batchErr.Add(indexes[b][j], p, err)
+		}
+	}
+	err := batchErr.ErrOrNil()
+	var failed []string
+	for _, item := range batchErr.Items {
+		failed = append(failed, item.Key)
+	}
+	return failed, err
+}
+
+// List returns paths of all objects that begin with prefix. If prefix has
+// a store segment, only the bucket of the store is listed, otherwise the results
+// of all buckets are merged in lexicographical order.
+func (m *MultiBucketBlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	if _, ok := blobref.ParseStoreObjectPath(prefix); ok {
+		return m.route(prefix).List(ctx, prefix)
+	}
+	paths := []string{}
+	for _, b := range m.router.buckets {
+		p, err := m.stores[b].List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, p...)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// SignUrl returns a signed URL of the object in the bucket that key is routed to.
+func (m *MultiBucketBlobStore) SignUrl(ctx context.Context, key string, ttlInSeconds int64, method string) (string, error) {
+	return m.route(key).SignUrl(ctx, key, ttlInSeconds, method)
+}
+
+// PutWithHeaders inserts a blob at path with headers in the bucket of path.
+func (m *MultiBucketBlobStore) PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error {
+	return PutWithHeaders(ctx, m.route(path), path, data, headers)
+}
+
+// NewWriterWithHeaders is the same as NewWriter, but it creates the object with headers.
+func (m *MultiBucketBlobStore) NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	return NewWriterWithHeaders(ctx, m.route(path), path, headers)
+}
+
+// SignUrlWithHeaders returns a signed URL for key with headers in the bucket of key.
+func (m *MultiBucketBlobStore) SignUrlWithHeaders(ctx context.Context, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error) {
+	return SignUrlWithHeaders(ctx, m.route(key), key, ttlInSeconds, method, headers)
+}
+
+// Generation returns the generation of the object at path.
+func (m *MultiBucketBlobStore) Generation(ctx context.Context, path string) (int64, error) {
+	return Generation(ctx, m.route(path), path)
+}
+
+// DeleteIfGeneration deletes the object at path if its generation is generation.
+func (m *MultiBucketBlobStore) DeleteIfGeneration(ctx context.Context, path string, generation int64) error {
+	return DeleteIfGeneration(ctx, m.route(path), path, generation)
+}
+
+// KMSKeyName returns the name of the key new objects are encrypted with if the
+// stores of all buckets use the same key, and an empty string otherwise.
+func (m *MultiBucketBlobStore) KMSKeyName() string {
+	name := KMSKeyName(m.stores[m.router.buckets[0]])
+	for _, b := range m.router.buckets[1:] {
+		if KMSKeyName(m.stores[b]) != name {
+			return ""
+		}
+	}
+	return name
+}
+
+// Append appends the content of r to the existing object at path.
+func (m *MultiBucketBlobStore) Append(ctx context.Context, path string, r io.Reader) (int64, error) {
+	return Append(ctx, m.route(path), path, r)
+}
+
+// Exists reports whether the object at path exists.
+func (m *MultiBucketBlobStore) Exists(ctx context.Context, path string) (bool, error) {
+	return Exists(ctx, m.route(path), path)
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+)
+
+func newTestMultiBucketBlobStore(ctx context.Context, t *testing.T, n int) (*MultiBucketBlobStore, map[string]BlobStore) {
+	t.Helper()
+	buckets := testBuckets(n)
+	stores := make(map[string]BlobStore)
+	for _, b := range buckets {
+		stores[b] = mustGetBucket(ctx, t)
+	}
+	m, err := NewMultiBucketBlobStore(mustNewBucketRouter(t, buckets), stores)
+	if err != nil {
+		t.Fatalf("NewMultiBucketBlobStore() failed: %v", err)
+	}
+	return m, stores
+}
+
+// assertOnlyIn checks that path exists in the bucket and no other buckets.
+func assertOnlyIn(ctx context.Context, t *testing.T, stores map[string]BlobStore, bucket, path string) {
+	t.Helper()
+	for b, bs := range stores {
+		_, err := bs.Get(ctx, path)
+		if b == bucket && err != nil {
+			t.Errorf("Get(%q) failed in bucket %q: %v", path, b, err)
+		}
+		if b != bucket && err == nil {
+			t.Errorf("object %q was found in bucket %q, want only in %q", path, b, bucket)
+		}
+	}
+}
+
+func TestMultiBucketBlobStore_MissingStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	stores := map[string]BlobStore{"a": mustGetBucket(ctx, t)}
+	if _, err := NewMultiBucketBlobStore(mustNewBucketRouter(t, []string{"a", "b"}), stores); err == nil {
+		t.Error("NewMultiBucketBlobStore() should fail when a bucket doesn't have a BlobStore")
+	}
+}
+
+func TestMultiBucketBlobStore_Routing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m, stores := newTestMultiBucketBlobStore(ctx, t, 3)
+
+	var paths []string
+	for i := 0; i < 20; i++ {
+		storeKey := fmt.Sprintf("store-%d", i)
+		path := StoreObjectPath(storeKey, "object")
+		bucket := m.router.Route(storeKey)
+		if got := m.BucketFor(path); got != bucket {
+			t.Errorf("BucketFor(%q) = %q, want %q", path, got, bucket)
+		}
+
+		if i%2 == 0 {
+			if err := m.Put(ctx, path, []byte(path)); err != nil {
+				t.Fatalf("Put(%q) failed: %v", path, err)
+			}
+		} else {
+			w, err := m.NewWriter(ctx, path)
+			if err != nil {
+				t.Fatalf("NewWriter(%q) failed: %v", path, err)
+			}
+			w.Write([]byte(path))
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() failed: %v", err)
+			}
+		}
+		assertOnlyIn(ctx, t, stores, bucket, path)
+
+		if got, err := m.Get(ctx, path); err != nil || string(got) != path {
+			t.Errorf("Get(%q) = (%q, %v), want %q", path, got, err, path)
+		}
+		r, err := m.NewRangeReader(ctx, path, 0, 5)
+		if err != nil {
+			t.Fatalf("NewRangeReader(%q) failed: %v", path, err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil || string(got) != path[:5] {
+			t.Errorf("NewRangeReader(%q) read (%q, %v), want %q", path, got, err, path[:5])
+		}
+		prefix := StoreObjectPath(storeKey, "")
+		list, err := m.List(ctx, prefix)
+		if err != nil {
+			t.Errorf("List(%q) failed: %v", prefix, err)
+		}
+		if diff := cmp.Diff([]string{path}, list); diff != "" {
+			t.Errorf("List(%q) = (-want, +got):\n%s", prefix, diff)
+		}
+		paths = append(paths, path)
+	}
+
+	all, err := m.List(ctx, "")
+	if err != nil {
+		t.Errorf("List() failed: %v", err)
+	}
+	if len(all) != len(paths) {
+		t.Errorf("List() returned %d paths, want %d", len(all), len(paths))
+	}
+
+	if err := m.Delete(ctx, paths[0]); err != nil {
+		t.Errorf("Delete(%q) failed: %v", paths[0], err)
+	}
+	failed, err := m.DeleteMulti(ctx, paths)
+	if err != nil || len(failed) != 0 {
+		t.Errorf("DeleteMulti() = (%v, %v), want no failures", failed, err)
+	}
+	for b, bs := range stores {
+		if list, _ := bs.List(ctx, ""); len(list) != 0 {
+			t.Errorf("bucket %q still has objects after DeleteMulti(): %v", b, list)
+		}
+	}
+}
+
+func TestMultiBucketBlobStore_PathWithoutStoreSegment(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m, stores := newTestMultiBucketBlobStore(ctx, t, 3)
+	const path = "4d1c4ebb-6bd6-4a6c-9b9c-5c1f9c56d4e1"
+	first := m.router.Buckets()[0]
+	// Hash prefixes of object paths are not store segments.
+	for _, p := range []string{path, blobref.ObjectPathWithPrefix(blobref.PathEncodingEscape, path), "stores/", "stores//" + path} {
+		if got := m.BucketFor(p); got != first {
+			t.Errorf("BucketFor(%q) = %q, want the first bucket %q", p, got, first)
+		}
+	}
+	if err := m.Put(ctx, path, []byte("legacy")); err != nil {
+		t.Fatalf("Put(%q) failed: %v", path, err)
+	}
+	assertOnlyIn(ctx, t, stores, first, path)
+}
+
+func TestMultiBucketBlobStore_ShardedBlobRef(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m, stores := newTestMultiBucketBlobStore(ctx, t, 3)
+	routed := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		// Slashes in store keys don't change the store segment.
+		storeKey := fmt.Sprintf("team/store-%d", i)
+		b := blobref.NewBlobRef(1, storeKey, "record")
+		b.PathEncoding = blobref.PathEncodingEscape
+		b.ShardedByStore = true
+		path := b.ObjectPath()
+		bucket := m.router.Route(storeKey)
+		routed[bucket] = true
+		if got := m.BucketFor(path); got != bucket {
+			t.Errorf("BucketFor(%q) = %q, want %q", path, got, bucket)
+		}
+		if err := m.Put(ctx, path, []byte("data")); err != nil {
+			t.Fatalf("Put(%q) failed: %v", path, err)
+		}
+		assertOnlyIn(ctx, t, stores, bucket, path)
+	}
+	if len(routed) < 2 {
+		t.Errorf("BlobRefs of 20 stores were routed to %d bucket(s), want more", len(routed))
+	}
+}
+
+func TestMultiBucketBlobStore_OptionalInterfaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	buckets := testBuckets(2)
+	stores := map[string]BlobStore{buckets[0]: mustGetKMSBucket(ctx, t), buckets[1]: mustGetKMSBucket(ctx, t)}
+	m, err := NewMultiBucketBlobStore(mustNewBucketRouter(t, buckets), stores)
+	if err != nil {
+		t.Fatalf("NewMultiBucketBlobStore() failed: %v", err)
+	}
+	checkOptionalInterfaces(ctx, t, m)
+
+	// The stores of the buckets use different keys.
+	stores[buckets[1]] = mustGetBucket(ctx, t)
+	if m, err = NewMultiBucketBlobStore(mustNewBucketRouter(t, buckets), stores); err != nil {
+		t.Fatalf("NewMultiBucketBlobStore() failed: %v", err)
+	}
+	if got := KMSKeyName(m); got != "" {
+		t.Errorf("KMSKeyName() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+// ErrNotReady is returned by EnsureReady when the store can't be written to.
+var ErrNotReady = errors.New("the blob store is not ready")
+
+// readinessSentinelPrefix is the prefix of the sentinel objects written by
+// EnsureReady. Each call writes a sentinel with a random name so that
+// concurrent starts don't interfere with each other.
+const readinessSentinelPrefix = ".open-saves-ready/"
+
+// ReadinessChecker is implemented by BlobStores that can verify, and prepare
+// if needed, their root location before use.
+type ReadinessChecker interface {
+	// EnsureReady returns nil if objects can be written to and deleted from
+	// the store, and an error wrapping ErrNotReady otherwise.
+	EnsureReady(ctx context.Context) error
+}
+
+// Assert BlobGCP, BlobFS, and MultiBucketBlobStore implement ReadinessChecker.
+var _ ReadinessChecker = new(BlobGCP)
+var _ ReadinessChecker = new(BlobFS)
+var _ ReadinessChecker = new(MultiBucketBlobStore)
+
+// EnsureReady verifies that bs is ready to store objects. It is meant to be
+// called at startup so that a misconfigured deployment fails fast instead of
+// on the first upload. It uses bs.EnsureReady if bs implements
+// ReadinessChecker, and otherwise writes and deletes a sentinel object.
+func EnsureReady(ctx context.Context, bs BlobStore) error {
+	if rc, ok := bs.(ReadinessChecker); ok {
+		return rc.EnsureReady(ctx)
+	}
+	return sentinelRoundTrip(ctx, bs)
+}
+
+// sentinelRoundTrip writes a sentinel object to bs and deletes it.
+func sentinelRoundTrip(ctx context.Context, bs BlobStore) error {
+	path := readinessSentinelPrefix + uuid.NewString()
+	if err := bs.Put(ctx, path, []byte("ready")); err != nil {
+		return fmt.Errorf("%w: failed to write the sentinel object (%v): %v", ErrNotReady, path, err)
+	}
+	if err := bs.Delete(ctx, path); err != nil {
+		return fmt.Errorf("%w: failed to delete the sentinel object (%v): %v", ErrNotReady, path, err)
+	}
+	return nil
+}
+
+// EnsureReady confirms that the bucket exists and is writable by writing and
+// deleting a sentinel object.
+func (b *BlobGCP) EnsureReady(ctx context.Context) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.EnsureReady")
+	defer span.End()
+
+	ok, err := b.bucket.IsAccessible(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: failed to access the bucket: %v", ErrNotReady, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: the bucket doesn't exist", ErrNotReady)
+	}
+	return sentinelRoundTrip(ctx, b)
+}
+
+// EnsureReady creates the root directory if it doesn't exist, for example
+// because it was removed after b was created, and confirms that it is
+// writable by writing and deleting a sentinel object.
+func (b *BlobFS) EnsureReady(ctx context.Context) error {
+	if err := os.MkdirAll(b.root, 0o755); err != nil {
+		return fmt.Errorf("%w: failed to create the root directory (%v): %v", ErrNotReady, b.root, err)
+	}
+	info, err := os.Stat(b.root)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotReady, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: the root (%v) is not a directory", ErrNotReady, b.root)
+	}
+	return sentinelRoundTrip(ctx, b)
+}
+
+// EnsureReady verifies that the BlobStores of all buckets are ready.
+func (m *MultiBucketBlobStore) EnsureReady(ctx context.Context) error {
+	for _, b := range m.router.Buckets() {
+		if err := EnsureReady(ctx, m.stores[b]); err != nil {
+			return fmt.Errorf("bucket %q: %w", b, err)
+		}
+	}
+	return nil
+}
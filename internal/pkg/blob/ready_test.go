@@ -0,0 +1,144 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sentinelBlobStore records the paths written and deleted, and fails Put with
+// putErr if set.
+type sentinelBlobStore struct {
+	BlobStore
+	mu      sync.Mutex
+	puts    []string
+	deletes []string
+	putErr  error
+}
+
+func (s *sentinelBlobStore) Put(ctx context.Context, path string, data []byte) error {
+	s.mu.Lock()
+	s.puts = append(s.puts, path)
+	s.mu.Unlock()
+	if s.putErr != nil {
+		return s.putErr
+	}
+	return s.BlobStore.Put(ctx, path, data)
+}
+
+func (s *sentinelBlobStore) Delete(ctx context.Context, path string) error {
+	s.mu.Lock()
+	s.deletes = append(s.deletes, path)
+	s.mu.Unlock()
+	return s.BlobStore.Delete(ctx, path)
+}
+
+func mustHaveNoSentinels(ctx context.Context, t *testing.T, bs BlobStore) {
+	t.Helper()
+	paths, err := bs.List(ctx, readinessSentinelPrefix)
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("List() = %v, want no sentinel objects left", paths)
+	}
+}
+
+func TestBlobFS_EnsureReadyCreatesRoot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "root")
+	fs := mustGetFS(ctx, t, root)
+	// Simulate a fresh volume mounted after the store was created.
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatalf("RemoveAll() failed: %v", err)
+	}
+
+	if err := EnsureReady(ctx, fs); err != nil {
+		t.Fatalf("EnsureReady() failed: %v", err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		t.Errorf("Stat(%q) = (%v, %v), want a directory", root, info, err)
+	}
+	mustHaveNoSentinels(ctx, t, fs)
+}
+
+func TestBlobFS_EnsureReadyRootIsFile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "root")
+	fs := mustGetFS(ctx, t, root)
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatalf("RemoveAll() failed: %v", err)
+	}
+	if err := os.WriteFile(root, []byte("file"), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := EnsureReady(ctx, fs); !errors.Is(err, ErrNotReady) {
+		t.Errorf("EnsureReady() = %v, want %v", err, ErrNotReady)
+	}
+}
+
+func TestGCS_EnsureReady(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	if err := EnsureReady(ctx, gcs); err != nil {
+		t.Fatalf("EnsureReady() failed: %v", err)
+	}
+	mustHaveNoSentinels(ctx, t, gcs)
+}
+
+func TestEnsureReady_SentinelRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &sentinelBlobStore{BlobStore: mustGetBucket(ctx, t)}
+	// Wrappers that don't implement ReadinessChecker fall back to the sentinel
+	// round trip through the wrapper.
+	if err := EnsureReady(ctx, NewTimeoutBlobStore(store, 0)); err != nil {
+		t.Fatalf("EnsureReady() failed: %v", err)
+	}
+	if len(store.puts) != 1 || !strings.HasPrefix(store.puts[0], readinessSentinelPrefix) {
+		t.Fatalf("Put() was called with %v, want a single sentinel", store.puts)
+	}
+	if len(store.deletes) != 1 || store.deletes[0] != store.puts[0] {
+		t.Errorf("Delete() was called with %v, want [%v]", store.deletes, store.puts[0])
+	}
+	mustHaveNoSentinels(ctx, t, store)
+}
+
+func TestEnsureReady_NotWritable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &sentinelBlobStore{BlobStore: mustGetBucket(ctx, t), putErr: errors.New("permission denied")}
+	err := EnsureReady(ctx, store)
+	if !errors.Is(err, ErrNotReady) {
+		t.Errorf("EnsureReady() = %v, want %v", err, ErrNotReady)
+	}
+	if len(store.deletes) != 0 {
+		t.Errorf("Delete() was called with %v, want no calls after a failed write", store.deletes)
+	}
+}
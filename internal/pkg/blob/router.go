@@ -0,0 +1,99 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// routerReplicas is the number of points each bucket has on the hash ring.
+// More points spread stores more evenly at the cost of memory.
+const routerReplicas = 128
+
+// ErrNoBuckets is returned by NewBucketRouter when the bucket list is empty.
+var ErrNoBuckets = errors.New("at least one bucket is required")
+
+// BucketRouter maps store keys to buckets by consistent hashing.
+//
+// Each bucket is placed on a hash ring at multiple points and a store is
+// assigned to the first bucket point that follows the hash of the store key.
+// When a bucket is added, only the stores that fall on the new bucket's points
+// are remapped, which is about 1/N of the stores for N buckets, and they all
+// move to the new bucket. Removing a bucket only remaps the stores that were
+// on it. The router doesn't move any objects: existing objects of the remapped
+// stores stay in the previous bucket until they are copied over, so a bucket
+// should only be added together with a migration of the affected stores.
+type BucketRouter struct {
+	buckets []string
+	ring    []ringPoint
+}
+
+type ringPoint struct {
+	hash   uint64
+	bucket string
+}
+
+// NewBucketRouter returns a BucketRouter over buckets. Duplicates are ignored.
+// The order of buckets doesn't affect routing.
+func NewBucketRouter(buckets []string) (*BucketRouter, error) {
+	r := &BucketRouter{}
+	seen := make(map[string]bool)
+	for _, b := range buckets {
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		r.buckets = append(r.buckets, b)
+		for i := 0; i < routerReplicas; i++ {
+			r.ring = append(r.ring, ringPoint{hash: hashKey(b + "#" + strconv.Itoa(i)), bucket: b})
+		}
+	}
+	if len(r.buckets) == 0 {
+		return nil, ErrNoBuckets
+	}
+	sort.Slice(r.ring, func(i, j int) bool {
+		if r.ring[i].hash == r.ring[j].hash {
+			return r.ring[i].bucket < r.ring[j].bucket
+		}
+		return r.ring[i].hash < r.ring[j].hash
+	})
+	return r, nil
+}
+
+// Buckets returns the buckets of the router in the configured order.
+func (r *BucketRouter) Buckets() []string {
+	return append([]string{}, r.buckets...)
+}
+
+// Route returns the bucket for storeKey.
+func (r *BucketRouter) Route(storeKey string) string {
+	h := hashKey(storeKey)
+	i := sort.Search(len(r.ring), func(i int) bool { return r.ring[i].hash >= h })
+	if i == len(r.ring) {
+		i = 0
+	}
+	return r.ring[i].bucket
+}
+
+// hashKey returns the first 8 bytes of the SHA-256 digest of key.
+// Simpler hashes such as FNV don't spread similar keys well enough over the ring.
+func hashKey(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
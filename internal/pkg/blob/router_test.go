@@ -0,0 +1,99 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func mustNewBucketRouter(t *testing.T, buckets []string) *BucketRouter {
+	t.Helper()
+	r, err := NewBucketRouter(buckets)
+	if err != nil {
+		t.Fatalf("NewBucketRouter(%v) failed: %v", buckets, err)
+	}
+	return r
+}
+
+func testBuckets(n int) []string {
+	buckets := make([]string, n)
+	for i := range buckets {
+		buckets[i] = fmt.Sprintf("bucket-%d", i)
+	}
+	return buckets
+}
+
+func TestBucketRouter_NoBuckets(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewBucketRouter(nil); !errors.Is(err, ErrNoBuckets) {
+		t.Errorf("NewBucketRouter(nil) = %v, want %v", err, ErrNoBuckets)
+	}
+}
+
+func TestBucketRouter_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	buckets := testBuckets(4)
+	r1 := mustNewBucketRouter(t, buckets)
+	// The order of buckets must not matter.
+	r2 := mustNewBucketRouter(t, []string{buckets[3], buckets[1], buckets[0], buckets[2], buckets[1]})
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		storeKey := fmt.Sprintf("store-%d", i)
+		got := r1.Route(storeKey)
+		if again := r1.Route(storeKey); again != got {
+			t.Fatalf("Route(%q) = %q, then %q", storeKey, got, again)
+		}
+		if other := r2.Route(storeKey); other != got {
+			t.Errorf("Route(%q) = %q with reordered buckets, want %q", storeKey, other, got)
+		}
+		counts[got]++
+	}
+	for _, b := range buckets {
+		if counts[b] < 100 {
+			t.Errorf("bucket %q got %d of 1000 stores, want a roughly even spread: %v", b, counts[b], counts)
+		}
+	}
+}
+
+func TestBucketRouter_AddBucketMinimallyRemaps(t *testing.T) {
+	t.Parallel()
+
+	const stores = 2000
+	buckets := testBuckets(10)
+	before := mustNewBucketRouter(t, buckets)
+	after := mustNewBucketRouter(t, append(buckets, "bucket-new"))
+
+	moved := 0
+	for i := 0; i < stores; i++ {
+		storeKey := fmt.Sprintf("store-%d", i)
+		from, to := before.Route(storeKey), after.Route(storeKey)
+		if from == to {
+			continue
+		}
+		moved++
+		if to != "bucket-new" {
+			t.Errorf("Route(%q) moved from %q to %q, want only moves to the new bucket", storeKey, from, to)
+		}
+	}
+	// About 1/11 of the stores are expected to move.
+	if moved == 0 || moved > stores/5 {
+		t.Errorf("%d of %d stores were remapped, want about %d", moved, stores, stores/11)
+	}
+}
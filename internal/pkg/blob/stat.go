@@ -0,0 +1,137 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"gocloud.dev/gcerrors"
+)
+
+// ErrObjectNotFound is returned by Stat when no object exists at the path.
+var ErrObjectNotFound = errors.New("the object doesn't exist")
+
+// ObjectAttrs is the metadata of an object returned by Stat.
+type ObjectAttrs struct {
+	// Size is the length of the object in bytes.
+	Size int64
+	// CRC32C is the CRC32C (Castagnoli) checksum of the object.
+	CRC32C uint32
+	// MD5 is the MD5 hash of the object.
+	MD5 []byte
+	// Generation is the generation of the object, or 0 if the store doesn't
+	// keep generations. See GenerationDeleter.
+	Generation int64
+	// ContentType is the Content-Type header of the object.
+	ContentType string
+	// Updated is the last modification time of the object.
+	Updated time.Time
+}
+
+// Statter is implemented by BlobStores that can return the metadata of an
+// object without reading its content.
+type Statter interface {
+	// Stat returns the attributes of the object at path, or
+	// ErrObjectNotFound if it doesn't exist.
+	Stat(ctx context.Context, path string) (ObjectAttrs, error)
+}
+
+// Assert BlobGCP implements Statter.
+var _ Statter = new(BlobGCP)
+
+// Stat returns the attributes of the object at path in bs.
+// It uses bs.Stat if bs implements Statter. Otherwise, it reads the object to
+// compute the size and checksums, and the other attributes are left empty.
+func Stat(ctx context.Context, bs BlobStore, path string) (ObjectAttrs, error) {
+	if s, ok := bs.(Statter); ok {
+		return s.Stat(ctx, path)
+	}
+	r, err := bs.NewReader(ctx, path)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return ObjectAttrs{}, ErrObjectNotFound
+	}
+	if err != nil {
+		return ObjectAttrs{}, err
+	}
+	defer r.Close()
+	var attrs ObjectAttrs
+	err = readChecksums(r, &attrs)
+	return attrs, err
+}
+
+// readChecksums reads r to EOF and sets the size and checksums of attrs.
+func readChecksums(r io.Reader, attrs *ObjectAttrs) error {
+	md5Hash := md5.New()
+	crc := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	n, err := io.Copy(io.MultiWriter(md5Hash, crc), r)
+	if err != nil {
+		return err
+	}
+	attrs.Size = n
+	attrs.MD5 = md5Hash.Sum(nil)
+	attrs.CRC32C = crc.Sum32()
+	return nil
+}
+
+// Stat returns the attributes of the object at path.
+// Drivers other than Cloud Storage don't keep CRC32C checksums, so they are
+// computed by reading the object, which is local for those drivers.
+// Generation is emulated as described in Generation.
+func (b *BlobGCP) Stat(ctx context.Context, path string) (ObjectAttrs, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "BlobGCP.Stat")
+	defer span.End()
+
+	attrs, err := b.bucket.Attributes(ctx, path)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return ObjectAttrs{}, ErrObjectNotFound
+	}
+	if err != nil {
+		return ObjectAttrs{}, err
+	}
+	result := ObjectAttrs{
+		Size:        attrs.Size,
+		MD5:         attrs.MD5,
+		ContentType: attrs.ContentType,
+		Updated:     attrs.ModTime,
+	}
+	var oa storage.ObjectAttrs
+	if attrs.As(&oa) {
+		result.CRC32C = oa.CRC32C
+		result.Generation = oa.Generation
+		return result, nil
+	}
+	result.Generation = attrs.ModTime.UnixNano()
+
+	r, err := b.bucket.NewReader(ctx, path, nil)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return ObjectAttrs{}, ErrObjectNotFound
+	}
+	if err != nil {
+		return ObjectAttrs{}, err
+	}
+	defer r.Close()
+	if err := readChecksums(r, &result); err != nil {
+		return ObjectAttrs{}, err
+	}
+	return result, nil
+}
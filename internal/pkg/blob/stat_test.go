@@ -0,0 +1,125 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+func checkStat(ctx context.Context, t *testing.T, bs BlobStore, path string, content []byte) ObjectAttrs {
+	t.Helper()
+	attrs, err := Stat(ctx, bs, path)
+	if err != nil {
+		t.Fatalf("Stat(%q) failed: %v", path, err)
+	}
+	if attrs.Size != int64(len(content)) {
+		t.Errorf("Stat(%q).Size = %v, want %v", path, attrs.Size, len(content))
+	}
+	if want := md5.Sum(content); !bytes.Equal(attrs.MD5, want[:]) {
+		t.Errorf("Stat(%q).MD5 = %x, want %x", path, attrs.MD5, want)
+	}
+	if want := crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli)); attrs.CRC32C != want {
+		t.Errorf("Stat(%q).CRC32C = %v, want %v", path, attrs.CRC32C, want)
+	}
+	return attrs
+}
+
+func TestGCS_Stat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	const path = "stat.txt"
+	content := []byte("stat content")
+	before := time.Now().Add(-time.Minute)
+	if err := gcs.PutWithHeaders(ctx, path, content, ObjectHeaders{ContentType: "text/plain"}); err != nil {
+		t.Fatalf("PutWithHeaders() failed: %v", err)
+	}
+	attrs := checkStat(ctx, t, gcs, path, content)
+	if attrs.ContentType != "text/plain" {
+		t.Errorf("Stat().ContentType = %q, want %q", attrs.ContentType, "text/plain")
+	}
+	if attrs.Updated.Before(before) {
+		t.Errorf("Stat().Updated = %v, want after %v", attrs.Updated, before)
+	}
+	if g := mustGetGeneration(ctx, t, gcs, path); attrs.Generation != g {
+		t.Errorf("Stat().Generation = %v, want %v", attrs.Generation, g)
+	}
+
+	if _, err := gcs.Stat(ctx, "missing.txt"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Stat() = %v, want %v", err, ErrObjectNotFound)
+	}
+}
+
+func TestBlobFS_Stat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fs := mustGetFS(ctx, t, t.TempDir())
+	content := []byte("filesystem content")
+	if err := fs.Put(ctx, "store/object", content); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if attrs := checkStat(ctx, t, fs, "store/object", content); attrs.Generation == 0 {
+		t.Error("Stat().Generation = 0, want the emulated generation")
+	}
+	if _, err := fs.Stat(ctx, "store/missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Stat() = %v, want %v", err, ErrObjectNotFound)
+	}
+}
+
+func TestStat_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &countingBlobStore{BlobStore: mustGetBucket(ctx, t)}
+	content := []byte("fallback content")
+	if err := store.Put(ctx, "path", content); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if attrs := checkStat(ctx, t, store, "path", content); attrs.Generation != 0 {
+		t.Errorf("Stat().Generation = %v, want 0 for stores without Stat", attrs.Generation)
+	}
+	if _, err := Stat(ctx, store, "missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Stat() = %v, want %v", err, ErrObjectNotFound)
+	}
+}
+
+func TestStat_Wrappers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	gcs := mustGetBucket(ctx, t)
+	content := []byte("wrapped content")
+	if err := gcs.Put(ctx, "wrapped", content); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	for _, bs := range []BlobStore{
+		NewInstrumentedBlobStore(gcs, nil),
+		NewTimeoutBlobStore(gcs, time.Minute),
+		NewExistenceCache(gcs, time.Minute, time.Second),
+	} {
+		if _, ok := bs.(Statter); !ok {
+			t.Errorf("%T doesn't implement Statter", bs)
+		}
+		checkStat(ctx, t, bs, "wrapped", content)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// TimeoutBlobStore wraps a BlobStore and applies a per-operation timeout to
+// Put, PutIfAbsent, Get, Delete, and Stat so that a single stuck call fails fast.
+// The timeout is applied to a child of the passed context, so the earlier of
+// the parent deadline and the timeout takes effect.
+// Streaming operations are not affected as the context controls the lifetime
+// of the writer or reader.
+// The optional interfaces, such as Statter, HeaderWriter, GenerationDeleter,
+// KMSKeyNamer, Appender, ExistenceChecker, and ReadinessChecker, are forwarded
+// to the underlying store, and the package functions fall back the same way as
+// for the underlying store if it doesn't implement them. PutWithHeaders,
+// Generation, DeleteIfGeneration, Append, Exists, and EnsureReady are limited
+// to the timeout too.
+type TimeoutBlobStore struct {
+	BlobStore
+	timeout time.Duration
+}
+
+// Assert TimeoutBlobStore implements the BlobStore and optional interfaces.
+var _ BlobStore = new(TimeoutBlobStore)
+var _ Statter = new(TimeoutBlobStore)
+var _ HeaderWriter = new(TimeoutBlobStore)
+var _ GenerationDeleter = new(TimeoutBlobStore)
+var _ KMSKeyNamer = new(TimeoutBlobStore)
+var _ Appender = new(TimeoutBlobStore)
+var _ ExistenceChecker = new(TimeoutBlobStore)
+var _ ReadinessChecker = new(TimeoutBlobStore)
+
+// NewTimeoutBlobStore returns a BlobStore that limits each Put, PutIfAbsent, Get, Delete, and Stat
+// call to bs to timeout. A non-positive timeout disables the limit.
+func NewTimeoutBlobStore(bs BlobStore, timeout time.Duration) *TimeoutBlobStore {
+	return &TimeoutBlobStore{BlobStore: bs, timeout: timeout}
+}
+
+func (b *TimeoutBlobStore) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, b.timeout)
+}
+
+// Put inserts a blob at the given path.
+func (b *TimeoutBlobStore) Put(ctx context.Context, path string, data []byte) error {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return b.BlobStore.Put(ctx, path, data)
+}
+
+// PutIfAbsent writes the content of r to path only if the object doesn't exist.
+func (b *TimeoutBlobStore) PutIfAbsent(ctx context.Context, path string, r io.Reader) (bool, int64, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return b.BlobStore.PutIfAbsent(ctx, path, r)
+}
+
+// Get retrieves the data given a blob path.
+func (b *TimeoutBlobStore) Get(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return b.BlobStore.Get(ctx, path)
+}
+
+// Delete deletes the blob at the given path.
+func (b *TimeoutBlobStore) Delete(ctx context.Context, path string) error {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return b.BlobStore.Delete(ctx, path)
+}
+
+// Stat returns the attributes of the object at path.
+func (b *TimeoutBlobStore) Stat(ctx context.Context, path string) (ObjectAttrs, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return Stat(ctx, b.BlobStore, path)
+}
+
+// PutWithHeaders inserts a blob at path with headers.
+func (b *TimeoutBlobStore) PutWithHeaders(ctx context.Context, path string, data []byte, headers ObjectHeaders) error {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return PutWithHeaders(ctx, b.BlobStore, path, data, headers)
+}
+
+// NewWriterWithHeaders is the same as NewWriter, but it creates the object with
+// headers. The writer is not limited to the timeout.
+func (b *TimeoutBlobStore) NewWriterWithHeaders(ctx context.Context, path string, headers ObjectHeaders) (io.WriteCloser, error) {
+	return NewWriterWithHeaders(ctx, b.BlobStore, path, headers)
+}
+
+// SignUrlWithHeaders returns a signed URL for key with headers.
+func (b *TimeoutBlobStore) SignUrlWithHeaders(ctx context.Context, key string, ttlInSeconds int64, method string, headers ObjectHeaders) (string, error) {
+	return SignUrlWithHeaders(ctx, b.BlobStore, key, ttlInSeconds, method, headers)
+}
+
+// Generation returns the generation of the object at path.
+func (b *TimeoutBlobStore) Generation(ctx context.Context, path string) (int64, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return Generation(ctx, b.BlobStore, path)
+}
+
+// DeleteIfGeneration deletes the object at path if its generation is generation.
+func (b *TimeoutBlobStore) DeleteIfGeneration(ctx context.Context, path string, generation int64) error {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return DeleteIfGeneration(ctx, b.BlobStore, path, generation)
+}
+
+// KMSKeyName returns the name of the key the underlying store encrypts new
+// objects with.
+func (b *TimeoutBlobStore) KMSKeyName() string {
+	return KMSKeyName(b.BlobStore)
+}
+
+// Append appends the content of r to the existing object at path.
+func (b *TimeoutBlobStore) Append(ctx context.Context, path string, r io.Reader) (int64, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return Append(ctx, b.BlobStore, path, r)
+}
+
+// Exists reports whether the object at path exists.
+func (b *TimeoutBlobStore) Exists(ctx context.Context, path string) (bool, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return Exists(ctx, b.BlobStore, path)
+}
+
+// EnsureReady verifies that the underlying store is ready to store objects.
+func (b *TimeoutBlobStore) EnsureReady(ctx context.Context) error {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	return EnsureReady(ctx, b.BlobStore)
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowBlobStore blocks Put, Get, and Delete for delay or until the context is done.
+type slowBlobStore struct {
+	BlobStore
+	delay time.Duration
+	// deadline is the deadline of the context of the last call.
+	deadline time.Time
+}
+
+func (b *slowBlobStore) wait(ctx context.Context) error {
+	b.deadline, _ = ctx.Deadline()
+	select {
+	case <-time.After(b.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *slowBlobStore) Put(ctx context.Context, path string, data []byte) error {
+	return b.wait(ctx)
+}
+
+func (b *slowBlobStore) Get(ctx context.Context, path string) ([]byte, error) {
+	return nil, b.wait(ctx)
+}
+
+func (b *slowBlobStore) Delete(ctx context.Context, path string) error {
+	return b.wait(ctx)
+}
+
+func TestTimeoutBlobStore_FastOperation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bs := NewTimeoutBlobStore(mustGetBucket(ctx, t), time.Minute)
+	const path = "timeout.txt"
+	data := []byte("hello world")
+
+	if err := bs.Put(ctx, path, data); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if got, err := bs.Get(ctx, path); err != nil || string(got) != string(data) {
+		t.Errorf("Get() = (%q, %v), want %q", got, err, data)
+	}
+	if err := bs.Delete(ctx, path); err != nil {
+		t.Errorf("Delete() failed: %v", err)
+	}
+}
+
+func TestTimeoutBlobStore_SlowOperation(t *testing.T) {
+	t.Parallel()
+
+	slow := &slowBlobStore{delay: time.Minute}
+	bs := NewTimeoutBlobStore(slow, 10*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	if err := bs.Put(ctx, "path", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Put() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := bs.Get(ctx, "path"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := bs.Delete(ctx, "path"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Delete() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("operations took %v, want them to time out quickly", elapsed)
+	}
+}
+
+func TestTimeoutBlobStore_ParentDeadline(t *testing.T) {
+	t.Parallel()
+
+	slow := &slowBlobStore{delay: time.Minute}
+	bs := NewTimeoutBlobStore(slow, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	parent, _ := ctx.Deadline()
+
+	if err := bs.Put(ctx, "path", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Put() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if slow.deadline.After(parent) {
+		t.Errorf("per-operation deadline %v exceeds the parent deadline %v", slow.deadline, parent)
+	}
+}
+
+func TestTimeoutBlobStore_NoTimeout(t *testing.T) {
+	t.Parallel()
+
+	slow := &slowBlobStore{}
+	bs := NewTimeoutBlobStore(slow, 0)
+	if err := bs.Put(context.Background(), "path", nil); err != nil {
+		t.Errorf("Put() failed: %v", err)
+	}
+	if !slow.deadline.IsZero() {
+		t.Errorf("Put() set a deadline %v, want none", slow.deadline)
+	}
+}
+
+func TestTimeoutBlobStore_OptionalInterfaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	checkOptionalInterfaces(ctx, t, NewTimeoutBlobStore(mustGetKMSBucket(ctx, t), time.Minute))
+}
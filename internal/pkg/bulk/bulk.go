@@ -0,0 +1,105 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bulk provides error reporting for operations on multiple items.
+package bulk
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ItemError is an error for an item of a bulk operation.
+type ItemError struct {
+	// Index is the index of the item in the input of the operation.
+	Index int
+	// Key identifies the item, e.g. an entity key or an object path.
+	Key string
+	Err error
+}
+
+// BatchError is returned by bulk operations when some of the items fail.
+// Items contains an entry for each failed item in the order of Index.
+type BatchError struct {
+	Items []ItemError
+}
+
+// Add appends an error for the item at index.
+func (e *BatchError) Add(index int, key string, err error) {
+	e.Items = append(e.Items, ItemError{Index: index, Key: key, Err: err})
+}
+
+// ErrOrNil sorts Items by Index and returns e, or nil if there is no item
+// error. Use it to avoid returning a nil *BatchError as a non-nil error.
+func (e *BatchError) ErrOrNil() error {
+	if e == nil || len(e.Items) == 0 {
+		return nil
+	}
+	sort.SliceStable(e.Items, func(i, j int) bool { return e.Items[i].Index < e.Items[j].Index })
+	return e
+}
+
+func (e *BatchError) Error() string {
+	if len(e.Items) == 0 {
+		return "no errors"
+	}
+	first := e.Items[0]
+	if len(e.Items) == 1 {
+		return fmt.Sprintf("item %d (%s) failed: %v", first.Index, first.Key, first.Err)
+	}
+	return fmt.Sprintf("%d items failed, first item %d (%s): %v", len(e.Items), first.Index, first.Key, first.Err)
+}
+
+// Unwrap returns the error of the first failed item so that errors.Is and
+// errors.As match it.
+func (e *BatchError) Unwrap() error {
+	if len(e.Items) == 0 {
+		return nil
+	}
+	return e.Items[0].Err
+}
+
+// Partition returns the indexes of the n items of a bulk operation that
+// succeeded and failed according to err, which is the error returned by the
+// operation. All items succeeded if err is nil, and all of them failed if err
+// is not a *BatchError.
+func Partition(n int, err error) (succeeded, failed []int) {
+	if err == nil {
+		return indexes(n, nil), nil
+	}
+	be, ok := err.(*BatchError)
+	if !ok {
+		return nil, indexes(n, nil)
+	}
+	isFailed := make(map[int]bool, len(be.Items))
+	for _, item := range be.Items {
+		if !isFailed[item.Index] {
+			isFailed[item.Index] = true
+			failed = append(failed, item.Index)
+		}
+	}
+	sort.Ints(failed)
+	return indexes(n, isFailed), failed
+}
+
+// indexes returns 0 to n-1 excluding those in exclude.
+func indexes(n int, exclude map[int]bool) []int {
+	var result []int
+	for i := 0; i < n; i++ {
+		if !exclude[i] {
+			result = append(result, i)
+		}
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errSentinel = errors.New("sentinel")
+
+func TestBatchError_ReportsItems(t *testing.T) {
+	batchErr := new(BatchError)
+	batchErr.Add(3, "d", errors.New("d failed"))
+	batchErr.Add(1, "b", errors.New("b failed"))
+	err := batchErr.ErrOrNil()
+
+	var be *BatchError
+	if assert.ErrorAs(t, err, &be) && assert.Len(t, be.Items, 2) {
+		assert.Equal(t, 1, be.Items[0].Index)
+		assert.Equal(t, "b", be.Items[0].Key)
+		assert.EqualError(t, be.Items[0].Err, "b failed")
+		assert.Equal(t, 3, be.Items[1].Index)
+		assert.Equal(t, "d", be.Items[1].Key)
+		assert.EqualError(t, be.Items[1].Err, "d failed")
+	}
+	assert.EqualError(t, err, "2 items failed, first item 1 (b): b failed")
+}
+
+func TestBatchError_ErrOrNil(t *testing.T) {
+	assert.NoError(t, new(BatchError).ErrOrNil())
+	var batchErr *BatchError
+	assert.NoError(t, batchErr.ErrOrNil())
+}
+
+func TestBatchError_Is(t *testing.T) {
+	batchErr := new(BatchError)
+	batchErr.Add(2, "c", errors.New("other"))
+	batchErr.Add(0, "a", fmt.Errorf("wrapped: %w", errSentinel))
+	err := fmt.Errorf("bulk operation: %w", batchErr.ErrOrNil())
+
+	assert.ErrorIs(t, err, errSentinel)
+	assert.EqualError(t, batchErr, "2 items failed, first item 0 (a): wrapped: sentinel")
+
+	// Only the first error is unwrapped.
+	other := new(BatchError)
+	other.Add(0, "a", errors.New("other"))
+	other.Add(1, "b", errSentinel)
+	assert.NotErrorIs(t, other.ErrOrNil(), errSentinel)
+}
+
+func TestPartition(t *testing.T) {
+	batchErr := new(BatchError)
+	batchErr.Add(3, "d", errSentinel)
+	batchErr.Add(1, "b", errSentinel)
+
+	testCases := []struct {
+		name          string
+		err           error
+		wantSucceeded []int
+		wantFailed    []int
+	}{
+		{"nil", nil, []int{0, 1, 2, 3}, nil},
+		{"BatchError", batchErr.ErrOrNil(), []int{0, 2}, []int{1, 3}},
+		{"other error", errSentinel, nil, []int{0, 1, 2, 3}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			succeeded, failed := Partition(4, tc.err)
+			assert.Equal(t, tc.wantSucceeded, succeeded)
+			assert.Equal(t, tc.wantFailed, failed)
+		})
+	}
+}
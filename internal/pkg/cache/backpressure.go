@@ -0,0 +1,225 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/googleforgames/open-saves/internal/pkg/config"
+)
+
+const (
+	// defaultErrorRateWindow is the error rate window used if
+	// CacheConfig.ErrorRateWindow is not positive.
+	defaultErrorRateWindow = 10 * time.Second
+	// minGuardLookups is the number of lookups in a window needed before the
+	// error rate is considered, so that a few errors don't engage the guard.
+	minGuardLookups = 10
+	// defaultLoadTimeout is the timeout of coalesced loads used if
+	// CacheConfig.BackpressureTimeout is not positive.
+	defaultLoadTimeout = 10 * time.Second
+)
+
+// ReadGuard protects the database behind the cache from a thundering herd when
+// the cache fails. It tracks the error rate of cache lookups, and once the
+// rate reaches the threshold, loads from the database after cache misses are
+// coalesced so that only one load per key is in flight, and each load is
+// delayed by a small random jitter to spread the loads of different keys.
+// Reads always fall through to the database (fail-open), so a persistent
+// cache outage slows reads down but never rejects them.
+type ReadGuard struct {
+	threshold float64
+	window    time.Duration
+	maxDelay  time.Duration
+	// loadTimeout is the timeout of coalesced loads.
+	loadTimeout time.Duration
+	// isMiss reports whether an error returned by Driver.Get only means the
+	// key was not found, in which case it doesn't count as a cache error.
+	isMiss func(error) bool
+
+	mu sync.Mutex
+	// windowStart is when the current window started. The error rate is
+	// calculated over the current and the previous windows.
+	windowStart               time.Time
+	lookups, failures         int
+	prevLookups, prevFailures int
+	flights                   map[string]*flight
+	now                       func() time.Time
+	sleep                     func(ctx context.Context, d time.Duration) error
+}
+
+// flight is a database load in progress that other loads of the same key wait for.
+type flight struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewReadGuard returns a ReadGuard configured by ErrorRateThreshold,
+// ErrorRateWindow, BackpressureMaxDelay, and BackpressureTimeout of cfg. The guard never engages
+// if ErrorRateThreshold is not positive. isMiss reports whether an error
+// returned by Driver.Get means a cache miss rather than a cache failure;
+// all errors are failures if it is nil.
+func NewReadGuard(cfg *config.CacheConfig, isMiss func(error) bool) *ReadGuard {
+	window := cfg.ErrorRateWindow
+	if window <= 0 {
+		window = defaultErrorRateWindow
+	}
+	loadTimeout := cfg.BackpressureTimeout
+	if loadTimeout <= 0 {
+		loadTimeout = defaultLoadTimeout
+	}
+	if isMiss == nil {
+		isMiss = func(error) bool { return false }
+	}
+	return &ReadGuard{
+		threshold:   cfg.ErrorRateThreshold,
+		window:      window,
+		maxDelay:    cfg.BackpressureMaxDelay,
+		loadTimeout: loadTimeout,
+		isMiss:      isMiss,
+		flights:     make(map[string]*flight),
+		now:         time.Now,
+		sleep:       sleepContext,
+	}
+}
+
+// Observe records the result of a cache lookup. err is the error returned by
+// Driver.Get, or nil if the lookup succeeded.
+func (g *ReadGuard) Observe(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rollWindow()
+	g.lookups++
+	if err != nil && !g.isMiss(err) {
+		g.failures++
+	}
+}
+
+// Engaged reports whether the cache error rate has reached the threshold, and
+// Load coalesces and delays database loads.
+func (g *ReadGuard) Engaged() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.engaged()
+}
+
+func (g *ReadGuard) engaged() bool {
+	if g.threshold <= 0 {
+		return false
+	}
+	g.rollWindow()
+	lookups := g.lookups + g.prevLookups
+	if lookups < minGuardLookups {
+		return false
+	}
+	return float64(g.failures+g.prevFailures)/float64(lookups) >= g.threshold
+}
+
+// rollWindow starts a new window if the current one has ended.
+// g.mu must be held.
+func (g *ReadGuard) rollWindow() {
+	now := g.now()
+	elapsed := now.Sub(g.windowStart)
+	if elapsed < g.window {
+		return
+	}
+	if elapsed < 2*g.window {
+		g.prevLookups, g.prevFailures = g.lookups, g.failures
+	} else {
+		g.prevLookups, g.prevFailures = 0, 0
+	}
+	g.lookups, g.failures = 0, 0
+	g.windowStart = now
+}
+
+// Load calls load to read the object of the cache key from the database after
+// a cache miss, and returns its result. If the guard is engaged, concurrent
+// calls with the same key share the result of a single call of load, which
+// starts after a random delay of up to BackpressureMaxDelay. Shared results
+// must not be modified by the callers.
+// The shared load runs with the values of the context of the first caller but
+// not its cancellation, and times out after BackpressureTimeout, so that a
+// canceled caller doesn't fail the others. Each caller stops waiting when its
+// own ctx is done.
+func (g *ReadGuard) Load(ctx context.Context, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if !g.engaged() {
+		g.mu.Unlock()
+		return load(ctx)
+	}
+	f, ok := g.flights[key]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		g.flights[key] = f
+		go g.fly(ctx, key, f, load)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fly runs the shared load of f for key on a context detached from ctx.
+func (g *ReadGuard) fly(ctx context.Context, key string, f *flight, load func(ctx context.Context) (interface{}, error)) {
+	ctx, cancel := context.WithTimeout(detachedContext{parent: ctx}, g.loadTimeout)
+	defer cancel()
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	if g.maxDelay > 0 {
+		if f.err = g.sleep(ctx, time.Duration(rand.Int63n(int64(g.maxDelay)))); f.err != nil {
+			return
+		}
+	}
+	f.value, f.err = load(ctx)
+}
+
+// detachedContext has the values of its parent, such as the trace span, but
+// is never canceled and has no deadline. context.WithoutCancel is not
+// available before Go 1.21.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/googleforgames/open-saves/internal/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errTestMiss   = errors.New("miss")
+	errTestOutage = errors.New("connection refused")
+)
+
+// failingDriver is a Driver whose Get always fails with err.
+type failingDriver struct {
+	Driver
+	err error
+}
+
+func (d *failingDriver) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, d.err
+}
+
+func newTestReadGuard(cfg *config.CacheConfig) *ReadGuard {
+	g := NewReadGuard(cfg, func(err error) bool { return errors.Is(err, errTestMiss) })
+	g.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return g
+}
+
+// engage makes g observe enough cache errors to reach any threshold.
+func engage(t *testing.T, g *ReadGuard) {
+	t.Helper()
+	for i := 0; i < minGuardLookups; i++ {
+		g.Observe(errTestOutage)
+	}
+	require.True(t, g.Engaged())
+}
+
+type testValue struct{ key string }
+
+func TestReadGuard_FailOpen(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestReadGuard(&config.CacheConfig{ErrorRateThreshold: 0.5})
+	c := New(&failingDriver{err: errTestOutage}, &config.CacheConfig{})
+	c.Guard = guard
+
+	// A persistent outage engages the guard, but every read still loads.
+	for i := 0; i < 3*minGuardLookups; i++ {
+		err := c.Get(ctx, "key", nil)
+		require.ErrorIs(t, err, errTestOutage)
+		v, err := guard.Load(ctx, "key", func(ctx context.Context) (interface{}, error) {
+			return &testValue{key: "key"}, nil
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, &testValue{key: "key"}, v)
+		}
+	}
+	assert.True(t, guard.Engaged())
+
+	// Load errors are returned as is.
+	_, err := guard.Load(ctx, "key", func(ctx context.Context) (interface{}, error) {
+		return nil, errTestMiss
+	})
+	assert.ErrorIs(t, err, errTestMiss)
+}
+
+func TestReadGuard_CoalescesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestReadGuard(&config.CacheConfig{ErrorRateThreshold: 0.5})
+	engage(t, guard)
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return &testValue{key: "key"}, nil
+	}
+
+	const callers = 10
+	results := make([]interface{}, callers)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = guard.Load(ctx, "key", load)
+	}()
+	<-started
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			results[i], err = guard.Load(ctx, "key", load)
+			assert.NoError(t, err)
+		}(i)
+	}
+	// Let the other callers join the load in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, r := range results {
+		assert.Same(t, results[0], r)
+	}
+
+	// Other keys and later calls load again.
+	_, err := guard.Load(ctx, "other", func(ctx context.Context) (interface{}, error) {
+		calls.Add(1)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestReadGuard_CanceledCallerDoesNotFailOthers(t *testing.T) {
+	guard := newTestReadGuard(&config.CacheConfig{ErrorRateThreshold: 0.5})
+	engage(t, guard)
+
+	type ctxKey struct{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, errors.New("the shared load has no deadline")
+		}
+		return ctx.Value(ctxKey{}), nil
+	}
+
+	firstCtx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "first"))
+	firstErr := make(chan error)
+	go func() {
+		_, err := guard.Load(firstCtx, "key", load)
+		firstErr <- err
+	}()
+	<-started
+	second := make(chan interface{})
+	go func() {
+		v, err := guard.Load(context.Background(), "key", load)
+		assert.NoError(t, err)
+		second <- v
+	}()
+	// Let the second caller join the load in flight.
+	time.Sleep(50 * time.Millisecond)
+
+	// The first caller stops waiting, but the load goes on for the second.
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+	assert.Equal(t, "first", <-second)
+}
+
+func TestReadGuard_LoadTimeout(t *testing.T) {
+	guard := newTestReadGuard(&config.CacheConfig{
+		ErrorRateThreshold:  0.5,
+		BackpressureTimeout: 10 * time.Millisecond,
+	})
+	engage(t, guard)
+
+	_, err := guard.Load(context.Background(), "key", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestReadGuard_Threshold(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	guard := newTestReadGuard(&config.CacheConfig{
+		ErrorRateThreshold:   0.5,
+		ErrorRateWindow:      time.Minute,
+		BackpressureMaxDelay: 10 * time.Millisecond,
+	})
+	guard.now = func() time.Time { return now }
+	var delays []time.Duration
+	guard.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	load := func(ctx context.Context) (interface{}, error) { return nil, nil }
+
+	// Too few lookups to consider the rate.
+	for i := 0; i < minGuardLookups-1; i++ {
+		guard.Observe(errTestOutage)
+	}
+	assert.False(t, guard.Engaged())
+
+	// Misses and hits don't count as errors.
+	for i := 0; i < minGuardLookups; i++ {
+		guard.Observe(errTestMiss)
+		guard.Observe(nil)
+	}
+	assert.False(t, guard.Engaged(), "9 errors in 29 lookups")
+	_, err := guard.Load(ctx, "key", load)
+	assert.NoError(t, err)
+	assert.Empty(t, delays, "loads must not be delayed below the threshold")
+
+	for i := 0; i < 12; i++ {
+		guard.Observe(errTestOutage)
+	}
+	assert.True(t, guard.Engaged(), "21 errors in 41 lookups")
+	_, err = guard.Load(ctx, "key", load)
+	assert.NoError(t, err)
+	if assert.Len(t, delays, 1) {
+		assert.Less(t, delays[0], 10*time.Millisecond)
+	}
+
+	// The previous window still counts...
+	now = now.Add(time.Minute)
+	assert.True(t, guard.Engaged())
+	// ...but the guard disengages once the errors are out of both windows.
+	now = now.Add(time.Minute)
+	assert.False(t, guard.Engaged())
+}
+
+func TestReadGuard_Disabled(t *testing.T) {
+	guard := newTestReadGuard(&config.CacheConfig{})
+	for i := 0; i < 2*minGuardLookups; i++ {
+		guard.Observe(errTestOutage)
+	}
+	assert.False(t, guard.Engaged())
+}
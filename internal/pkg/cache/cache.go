@@ -18,6 +18,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/googleforgames/open-saves/internal/pkg/config"
@@ -25,11 +26,23 @@ import (
 
 const defaultMaxSizeToCache int = 10 * 1024 * 1024 // 10 MB
 
+// ErrFormatMismatch is returned by Get when the cached entry was written with
+// a different Codec than the one the Cache currently uses.
+var ErrFormatMismatch = errors.New("cached entry has a different serialization format")
+
 // Cache defines operations for a cache service.
 type Cache struct {
 	driver         Driver
 	MaxSizeToCache int
 	Config         *config.CacheConfig
+	// Codec serializes cached objects if set. Entries are prefixed with the
+	// format tag of the Codec so that entries written in another format are
+	// not mis-decoded. Objects are encoded with EncodeBytes and entries are
+	// not tagged if Codec is nil.
+	Codec Codec
+	// Guard, if set, observes the results of Driver.Get so that callers can
+	// protect the database with Guard.Load while the cache is failing.
+	Guard *ReadGuard
 }
 
 func New(driver Driver, config *config.CacheConfig) *Cache {
@@ -44,7 +57,7 @@ func New(driver Driver, config *config.CacheConfig) *Cache {
 // set the value into the cache if it's under MaxSizeToCache.
 // If the object exceeds MaxSizeToCache, Set deletes the object from the cache.
 func (c *Cache) Set(ctx context.Context, object Cacheable) error {
-	encoded, err := object.EncodeBytes()
+	encoded, err := c.encode(object)
 	if err != nil {
 		return err
 	}
@@ -59,10 +72,34 @@ func (c *Cache) Set(ctx context.Context, object Cacheable) error {
 // Driver.Get.
 func (c *Cache) Get(ctx context.Context, key string, dest Cacheable) error {
 	stored, err := c.driver.Get(ctx, key)
+	if c.Guard != nil {
+		c.Guard.Observe(err)
+	}
 	if err != nil {
 		return err
 	}
-	return dest.DecodeBytes(stored)
+	return c.decode(stored, dest)
+}
+
+func (c *Cache) encode(object Cacheable) ([]byte, error) {
+	if c.Codec == nil {
+		return object.EncodeBytes()
+	}
+	data, err := c.Codec.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{c.Codec.Format()}, data...), nil
+}
+
+func (c *Cache) decode(stored []byte, dest Cacheable) error {
+	if c.Codec == nil {
+		return dest.DecodeBytes(stored)
+	}
+	if len(stored) == 0 || stored[0] != c.Codec.Format() {
+		return ErrFormatMismatch
+	}
+	return c.Codec.Unmarshal(stored[1:], dest)
 }
 
 // Deletes deletes an object identified by key from the cache.
@@ -86,6 +123,17 @@ type Cacheable interface {
 	EncodeBytes() ([]byte, error)
 }
 
+// Codec is an interface for pluggable serialization formats of cached objects.
+type Codec interface {
+	// Format returns the tag stored at the beginning of each cache entry.
+	// Each Codec must have a unique tag.
+	Format() byte
+	// Marshal returns a serialized byte slice of object.
+	Marshal(object Cacheable) ([]byte, error)
+	// Unmarshal deserializes data into dest.
+	Unmarshal(data []byte, dest Cacheable) error
+}
+
 // Driver interface defines common operations for the cache store.
 type Driver interface {
 	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
@@ -16,6 +16,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"github.com/go-redis/redis/extra/redisotel/v8"
 	"github.com/go-redis/redis/v8"
 	"time"
@@ -55,6 +56,12 @@ func NewRedisWithConfig(cfg *config.RedisConfig) *Redis {
 	}
 }
 
+// IsMiss reports whether err returned by Get means the key doesn't exist,
+// as opposed to a failure of the redis instance.
+func IsMiss(err error) bool {
+	return errors.Is(err, redis.Nil)
+}
+
 // Set adds a key-value pair to the redis instance.
 func (r *Redis) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
 	return r.c.Set(ctx, key, string(value), expiration).Err()
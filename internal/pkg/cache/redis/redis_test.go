@@ -41,6 +41,7 @@ func TestRedis_All(t *testing.T) {
 
 	_, err = r.Get(ctx, "unknown")
 	assert.Error(t, err)
+	assert.True(t, IsMiss(err))
 
 	by := []byte("byte")
 	assert.NoError(t, r.Set(ctx, "hello", by, 0))
@@ -68,3 +69,14 @@ func TestRedis_All(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, keys)
 }
+
+func TestRedis_IsMissOnOutage(t *testing.T) {
+	ctx := context.Background()
+	s := miniredis.RunT(t)
+	r := NewRedis(s.Addr())
+	s.Close()
+
+	_, err := r.Get(ctx, "key")
+	assert.Error(t, err)
+	assert.False(t, IsMiss(err), "connection errors are not misses")
+}
@@ -96,16 +96,23 @@ func Load(path string) (*ServiceConfig, error) {
 	}
 
 	serverConfig := ServerConfig{
-		Address:             fmt.Sprintf(":%d", viper.GetUint(OpenSavesPort)),
-		Cloud:               viper.GetString(OpenSavesCloud),
-		Bucket:              viper.GetString(OpenSavesBucket),
-		Project:             viper.GetString(OpenSavesProject),
-		ShutdownGracePeriod: viper.GetDuration(ShutdownGracePeriod),
-		EnableTrace:         viper.GetBool(EnableTrace),
-		TraceSampleRate:     viper.GetFloat64(TraceSampleRate),
-		TraceServiceName:    viper.GetString(TraceServiceName),
-		EnableGRPCCollector: viper.GetBool(TraceEnableGRPCCollector),
-		EnableHTTPCollector: viper.GetBool(TraceEnableHTTPCollector),
+		Address:                   fmt.Sprintf(":%d", viper.GetUint(OpenSavesPort)),
+		Cloud:                     viper.GetString(OpenSavesCloud),
+		Bucket:                    viper.GetString(OpenSavesBucket),
+		Project:                   viper.GetString(OpenSavesProject),
+		ShutdownGracePeriod:       viper.GetDuration(ShutdownGracePeriod),
+		Replicas:                  viper.GetInt(OpenSavesReplicas),
+		CursorKey:                 viper.GetString(DatastoreCursorKey),
+		SignatureKey:              viper.GetString(DatastoreSignatureKey),
+		QueryEventualConsistency:  viper.GetBool(DatastoreQueryEventualConsistency),
+		MaxConcurrentTransactions: viper.GetInt(DatastoreMaxConcurrentTransactions),
+		StorePolicyTTL:            viper.GetDuration(DatastoreStorePolicyTTL),
+		EnableAuditLog:            viper.GetBool(EnableAuditLog),
+		EnableTrace:               viper.GetBool(EnableTrace),
+		TraceSampleRate:           viper.GetFloat64(TraceSampleRate),
+		TraceServiceName:          viper.GetString(TraceServiceName),
+		EnableGRPCCollector:       viper.GetBool(TraceEnableGRPCCollector),
+		EnableHTTPCollector:       viper.GetBool(TraceEnableHTTPCollector),
 	}
 
 	// Cloud Run environment populates the PORT env var, so check for it here.
@@ -118,7 +125,12 @@ func Load(path string) (*ServiceConfig, error) {
 	}
 
 	cacheConfig := CacheConfig{
-		DefaultTTL: viper.GetDuration(CacheDefaultTTL),
+		DefaultTTL:           viper.GetDuration(CacheDefaultTTL),
+		RecordCodec:          viper.GetString(CacheRecordCodec),
+		ErrorRateThreshold:   viper.GetFloat64(CacheErrorRateThreshold),
+		ErrorRateWindow:      viper.GetDuration(CacheErrorRateWindow),
+		BackpressureMaxDelay: viper.GetDuration(CacheBackpressureMaxDelay),
+		BackpressureTimeout:  viper.GetDuration(CacheBackpressureTimeout),
 	}
 
 	// Redis configuration
@@ -134,7 +146,36 @@ func Load(path string) (*ServiceConfig, error) {
 	}
 
 	blobConfig := BlobConfig{
-		MaxInlineSize: viper.GetInt(BlobMaxInlineSize),
+		MaxInlineSize:            viper.GetInt(BlobMaxInlineSize),
+		DedupEventualConsistency: viper.GetBool(BlobDedupEventualConsistency),
+		MaxInFlightUploadBytes:   viper.GetInt64(BlobMaxInFlightUploadBytes),
+		KMSKeyName:               viper.GetString(BlobKMSKeyName),
+		ContentTypes:             viper.GetStringMapString(BlobContentTypes),
+		IdempotencyWindow:        viper.GetDuration(BlobIdempotencyWindow),
+		MinChunkSize:             viper.GetInt64(BlobMinChunkSize),
+		TouchWindow:              viper.GetDuration(BlobTouchWindow),
+		PathEncoding:             viper.GetString(BlobPathEncoding),
+		KeyGenerator:             viper.GetString(BlobKeyGenerator),
+		ContentHashAlgorithm:     viper.GetString(BlobContentHashAlgorithm),
+		StoreKind:                viper.GetString(BlobStoreKind),
+		StoreRootDir:             viper.GetString(BlobStoreRootDir),
+		StoreSyncMode:            viper.GetString(BlobStoreSyncMode),
+		OperationTimeout:         viper.GetDuration(BlobOperationTimeout),
+		CircuitBreakerThreshold:  viper.GetInt(BlobCircuitBreakerThreshold),
+		CircuitBreakerCoolDown:   viper.GetDuration(BlobCircuitBreakerCoolDown),
+		ReplicaBucket:            viper.GetString(BlobReplicaBucket),
+		ShardBuckets:             viper.GetStringSlice(BlobShardBuckets),
+		VerifiedReadMaxSize:      viper.GetInt64(BlobVerifiedReadMaxSize),
+		ExistsCachePositiveTTL:   viper.GetDuration(BlobExistsCachePositiveTTL),
+		ExistsCacheNegativeTTL:   viper.GetDuration(BlobExistsCacheNegativeTTL),
+	}
+
+	recordConfig := RecordConfig{
+		PropertyNameMode: viper.GetString(RecordPropertyNameMode),
+		KeyMaxLength:     viper.GetInt(RecordKeyMaxLength),
+		KeyPattern:       viper.GetString(RecordKeyPattern),
+		KeyPermissive:    viper.GetBool(RecordKeyPermissive),
+		MaxEntitySize:    viper.GetInt64(RecordMaxEntitySize),
 	}
 
 	grpcServerConfig := GRPCServerConfig{
@@ -150,6 +191,7 @@ func Load(path string) (*ServiceConfig, error) {
 		CacheConfig:      cacheConfig,
 		RedisConfig:      redisConfig,
 		BlobConfig:       blobConfig,
+		RecordConfig:     recordConfig,
 		GRPCServerConfig: grpcServerConfig,
 	}, nil
 }
@@ -21,10 +21,23 @@ const (
 	OpenSavesCloud      = "open_saves_cloud"
 	OpenSavesBucket     = "open_saves_bucket"
 	OpenSavesProject    = "open_saves_project"
+	OpenSavesReplicas   = "open_saves_replicas"
 	LogLevel            = "log_level"
 	ShutdownGracePeriod = "shutdown_grace_period"
 
-	CacheDefaultTTL = "cache_default_ttl"
+	DatastoreMaxConcurrentTransactions = "datastore_max_concurrent_transactions"
+	DatastoreStorePolicyTTL            = "datastore_store_policy_ttl"
+	DatastoreCursorKey                 = "datastore_cursor_key"
+	DatastoreSignatureKey              = "datastore_signature_key"
+	DatastoreQueryEventualConsistency  = "datastore_query_eventual_consistency"
+	EnableAuditLog                     = "enable_audit_log"
+
+	CacheDefaultTTL           = "cache_default_ttl"
+	CacheRecordCodec          = "cache_record_codec"
+	CacheErrorRateThreshold   = "cache_error_rate_threshold"
+	CacheErrorRateWindow      = "cache_error_rate_window"
+	CacheBackpressureMaxDelay = "cache_backpressure_max_delay"
+	CacheBackpressureTimeout  = "cache_backpressure_timeout"
 
 	RedisAddress         = "redis_address"
 	RedisMinIdleConns    = "redis_min_idle_conns"
@@ -35,7 +48,34 @@ const (
 	RedisMinRetryBackoff = "redis_min_retry_backoff"
 	RedisMaxRetryBackoff = "redis_max_retry_backoff"
 
-	BlobMaxInlineSize = "blob_max_inline_size"
+	BlobMaxInlineSize            = "blob_max_inline_size"
+	BlobDedupEventualConsistency = "blob_dedup_eventual_consistency"
+	BlobMaxInFlightUploadBytes   = "blob_max_inflight_upload_bytes"
+	BlobKMSKeyName               = "blob_kms_key_name"
+	BlobContentTypes             = "blob_content_types"
+	BlobIdempotencyWindow        = "blob_idempotency_window"
+	BlobMinChunkSize             = "blob_min_chunk_size"
+	BlobTouchWindow              = "blob_touch_window"
+	BlobPathEncoding             = "blob_path_encoding"
+	BlobKeyGenerator             = "blob_key_generator"
+	BlobContentHashAlgorithm     = "blob_content_hash_algorithm"
+	BlobStoreKind                = "blob_store_kind"
+	BlobStoreRootDir             = "blob_store_root_dir"
+	BlobStoreSyncMode            = "blob_store_sync_mode"
+	BlobOperationTimeout         = "blob_operation_timeout"
+	BlobCircuitBreakerThreshold  = "blob_circuit_breaker_threshold"
+	BlobCircuitBreakerCoolDown   = "blob_circuit_breaker_cool_down"
+	BlobReplicaBucket            = "blob_replica_bucket"
+	BlobShardBuckets             = "blob_shard_buckets"
+	BlobVerifiedReadMaxSize      = "blob_verified_read_max_size"
+	BlobExistsCachePositiveTTL   = "blob_exists_cache_positive_ttl"
+	BlobExistsCacheNegativeTTL   = "blob_exists_cache_negative_ttl"
+
+	RecordPropertyNameMode = "record_property_name_mode"
+	RecordKeyMaxLength     = "record_key_max_length"
+	RecordKeyPattern       = "record_key_pattern"
+	RecordKeyPermissive    = "record_key_permissive"
+	RecordMaxEntitySize    = "record_max_entity_size"
 
 	GRPCKeepAliveMaxConnectionIdle     = "grpc_keepalive_max_connection_idle"
 	GRPCKeepAliveMaxConnectionAge      = "grpc_keepalive_max_connection_age"
@@ -55,6 +95,7 @@ type ServiceConfig struct {
 	CacheConfig
 	RedisConfig
 	BlobConfig
+	RecordConfig
 	GRPCServerConfig
 }
 
@@ -65,6 +106,30 @@ type ServerConfig struct {
 	Bucket              string
 	Project             string
 	ShutdownGracePeriod time.Duration
+	// Replicas is the number of servers that share the database.
+	Replicas int
+	// CursorKey is the secret key that query cursors are signed with. It must
+	// be the same on all replicas, and is required if Replicas is more than one.
+	// A random key is generated at startup if empty.
+	CursorKey string
+	// SignatureKey is the secret key that the timestamp signatures of records
+	// and blobs are computed with by HMAC-SHA256, so that modified timestamps
+	// can be detected. It must be the same on all replicas. Signatures are
+	// random if empty.
+	SignatureKey string
+	// QueryEventualConsistency makes record and blob queries, such as
+	// QueryRecords, eventually consistent, which is faster but may miss recent
+	// writes. Key-based reads and reads in transactions stay strongly consistent.
+	QueryEventualConsistency bool
+	// MaxConcurrentTransactions is the maximum number of Datastore
+	// transactions that run at once. There is no limit if it is not positive.
+	MaxConcurrentTransactions int
+	// StorePolicyTTL is the time that store policies are cached before they
+	// are read from Datastore again. The MetaDB default is used if not positive.
+	StorePolicyTTL time.Duration
+	// EnableAuditLog makes the server write an audit entry to Datastore for
+	// every record mutation.
+	EnableAuditLog bool
 
 	// The following enables OpenTelemetry Tracing
 	// It is EXPERIMENTAL and subject to change or removal without notice.
@@ -80,6 +145,23 @@ type ServerConfig struct {
 type CacheConfig struct {
 	// DefaultTTL is the default TTL for cached data.
 	DefaultTTL time.Duration
+	// RecordCodec is the serialization format of cached records,
+	// one of "msgpack", "proto", "json", and "gob".
+	RecordCodec string
+	// ErrorRateThreshold is the rate of failed cache lookups, between 0 and 1,
+	// at which loads from Datastore after cache misses are coalesced per key
+	// and delayed. Reads always fall through to Datastore regardless.
+	// The protection is disabled if it is not positive. See cache.ReadGuard.
+	ErrorRateThreshold float64
+	// ErrorRateWindow is the window over which the error rate is measured.
+	ErrorRateWindow time.Duration
+	// BackpressureMaxDelay is the maximum random delay of Datastore loads while
+	// the error rate is above the threshold. There is no delay if it is not positive.
+	BackpressureMaxDelay time.Duration
+	// BackpressureTimeout is the timeout of coalesced Datastore loads, which
+	// are not canceled with the requests waiting for them. A default of
+	// 10 seconds is used if it is not positive.
+	BackpressureTimeout time.Duration
 }
 
 // RedisConfig as defined in https://pkg.go.dev/github.com/go-redis/redis/v8#Options
@@ -99,6 +181,114 @@ type RedisConfig struct {
 // BlobConfig has Open Saves blob related configurations.
 type BlobConfig struct {
 	MaxInlineSize int
+	// DedupEventualConsistency makes CheckBlobExists use eventually consistent
+	// queries, which are faster but may miss blobs uploaded just before.
+	DedupEventualConsistency bool
+	// MaxInFlightUploadBytes is the maximum total declared size of blobs being
+	// uploaded with CreateBlob at once. There is no limit if it is not positive.
+	MaxInFlightUploadBytes int64
+	// KMSKeyName is the Cloud KMS key used to encrypt new blob objects (CMEK).
+	// The bucket's default encryption is used if empty.
+	KMSKeyName string
+	// ContentTypes maps file extensions, such as ".sav", to the content types
+	// of blobs uploaded with a filename but without a content type. They take
+	// precedence over the built-in table and the system MIME types.
+	ContentTypes map[string]string
+	// IdempotencyWindow is how long the idempotency token of a CreateBlob
+	// upload is kept, so that retries with the same token within the window
+	// return the blob of the first upload. Tokens are ignored if it is not positive.
+	IdempotencyWindow time.Duration
+	// MinChunkSize is the minimum byte size of the chunks of chunked uploads
+	// other than the final chunk. It is only enforced for uploads that declare
+	// chunk_count, because the final chunk can't be identified otherwise.
+	// There is no minimum if it is not positive.
+	MinChunkSize int64
+	// TouchWindow is how long LastAccessedAt updates of blobs that are read
+	// are buffered and coalesced before they are written in a batch.
+	// LastAccessedAt is not updated if it is not positive.
+	TouchWindow time.Duration
+	// PathEncoding is the object path encoding of new blobs, either "key"
+	// (default), "escape", or "base64". See blobref.PathEncoding. Existing
+	// blobs keep the encoding they were created with.
+	PathEncoding string
+	// KeyGenerator generates the keys of new blobs, either "random" (default)
+	// or "uuidv7" for time-ordered keys. See blobref.ParseKeyGenerator.
+	// MetaDB.EstimateStoreSize and the scrubber of the collector sample blobs
+	// in key order and are biased towards old blobs with "uuidv7".
+	KeyGenerator string
+	// ContentHashAlgorithm is the algorithm of the content hashes of new blobs,
+	// either "md5" (default) or "sha256". See checksums.HashAlgorithm.
+	ContentHashAlgorithm string
+	// StoreKind is the kind of the blob store backend, either "gcs" (default),
+	// which uses ServerConfig.Bucket, or "filesystem", which uses StoreRootDir.
+	// See blob.NewBlobStore.
+	StoreKind string
+	// StoreRootDir is the root directory of objects of the filesystem backend.
+	StoreRootDir string
+	// StoreSyncMode is how the filesystem backend flushes written objects,
+	// either "file" (default), "file_and_dir", or "none". See blob.SyncMode.
+	StoreSyncMode string
+	// OperationTimeout limits each Put, Get, Delete, and Stat call to the blob
+	// store, so that a stuck call fails and counts towards the circuit breaker.
+	// Streaming reads and writes are not limited. There is no limit if it is
+	// not positive. See blob.TimeoutBlobStore.
+	OperationTimeout time.Duration
+	// CircuitBreakerThreshold is the number of consecutive blob store failures
+	// after which calls to the store fail fast for CircuitBreakerCoolDown.
+	// The circuit breaker is disabled if it is not positive.
+	// See blob.CircuitBreakerBlobStore.
+	CircuitBreakerThreshold int
+	// CircuitBreakerCoolDown is how long the circuit breaker stays open before
+	// it lets a probe call through.
+	CircuitBreakerCoolDown time.Duration
+	// ReplicaBucket is the URL of a bucket that has a copy of the blob objects.
+	// Corrupt objects found by verified reads are healed from it. There is no
+	// replica if empty.
+	ReplicaBucket string
+	// ShardBuckets are the buckets that blobs are spread across by store in
+	// addition to ServerConfig.Bucket, or the root directories in addition to
+	// StoreRootDir for the filesystem backend. New blobs are put in the
+	// bucket of their store with blob.MultiBucketBlobStore, and existing
+	// blobs and chunk objects stay in ServerConfig.Bucket. Blobs are not
+	// sharded if empty. Adding a bucket remaps some stores, see
+	// blob.BucketRouter.
+	ShardBuckets []string
+	// VerifiedReadMaxSize is the maximum byte size of blobs whose content
+	// GetBlob verifies against their checksums before sending it. Only reads
+	// from the beginning are verified, and the blob is read into memory.
+	// Reads are not verified if it is not positive.
+	VerifiedReadMaxSize int64
+	// ExistsCachePositiveTTL is how long the server caches that a blob object
+	// exists, e.g. when chunked uploads are checked for missing chunks.
+	// Existence is not cached if it is not positive. See blob.ExistenceCache.
+	ExistsCachePositiveTTL time.Duration
+	// ExistsCacheNegativeTTL is how long the server caches that a blob object
+	// doesn't exist. It is usually shorter as missing objects are likely
+	// being uploaded.
+	ExistsCacheNegativeTTL time.Duration
+}
+
+// RecordConfig has Open Saves record related configurations.
+type RecordConfig struct {
+	// PropertyNameMode is how invalid property names are handled, either
+	// "reject" (default) or "escape". See record.PropertyNameMode.
+	PropertyNameMode string
+	// KeyMaxLength is the maximum byte length of new store and record keys.
+	// It can only tighten the limit of the base validator, and has no effect if
+	// it is not positive.
+	KeyMaxLength int
+	// KeyPattern is a regular expression that new store and record keys must
+	// fully match. Empty means no pattern.
+	KeyPattern string
+	// KeyPermissive validates new store and record keys, and the keys of the
+	// records that blobs are created for, with metadb.PermissiveKeyValidator
+	// instead of metadb.SafeKeyValidator, which only allows ASCII letters,
+	// digits, and "-_.~:@+=". It is for existing data with other keys.
+	KeyPermissive bool
+	// MaxEntitySize is the maximum estimated size of saved records in bytes.
+	// It can only tighten the Datastore limit of record.MaxEntitySize, and
+	// has no effect if it is not positive.
+	MaxEntitySize int64
 }
 
 // GRPCServerConfig has the configurations for grpc server, for now keepAlive parameters
@@ -0,0 +1,190 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"hash/crc32"
+	"io"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// appendLeaseTTL is how long an append holds the BlobRef before another
+// append can take over, e.g. after the server crashed while appending.
+const appendLeaseTTL = 5 * time.Minute
+
+var (
+	ErrAppendInProgress = status.Error(codes.FailedPrecondition, "another append to the blob is in progress")
+	ErrAppendLeaseLost  = status.Error(codes.Aborted, "the append lease on the blob expired and was taken over")
+
+	castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// AppendBlob appends the content of r to the object of the Ready, non-chunked
+// BlobRef blobKey and returns the new size of the blob.
+// It updates Size and CRC32C of the BlobRef, and BlobSize of the record if the
+// blob is current. MD5 and the content hash are removed as they can't be
+// extended without reading the entire object, so the blob is no longer found
+// by FindBlobRefByContentHash until BackfillChecksums computes the hash again.
+// Concurrent appends to the same blob are rejected with ErrAppendInProgress.
+// Returned errors:
+//   - NotFound: the BlobRef is not found
+//   - FailedPrecondition: the BlobRef is chunked or not Ready, or ErrAppendInProgress
+//   - Aborted (ErrAppendLeaseLost): the append took longer than appendLeaseTTL
+//     and another append started
+func (m *MetaDB) AppendBlob(ctx context.Context, bs blob.BlobStore, blobKey uuid.UUID, r io.Reader) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.AppendBlob")
+	defer span.End()
+
+	b, err := m.acquireAppendLease(ctx, blobKey)
+	if err != nil {
+		return 0, err
+	}
+	crc := b.GetCRC32C()
+	tee := io.TeeReader(r, crc32Writer{&crc})
+	n, err := blob.Append(ctx, bs, b.ObjectPath(), tee)
+	if err != nil {
+		m.releaseAppendLease(ctx, b)
+		return 0, err
+	}
+
+	var newSize int64
+	_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		cur, err := m.getBlobRef(ctx, tx, blobKey)
+		if err != nil {
+			return err
+		}
+		if cur.AppendID != b.AppendID {
+			return ErrAppendLeaseLost
+		}
+		cur.AppendID = ""
+		cur.AppendExpiresAt = time.Time{}
+		cur.Size += n
+		cur.MD5 = nil
+		cur.ContentHash = nil
+		cur.ContentHashAlgorithm = ""
+		if cur.HasCRC32C {
+			cur.SetCRC32C(crc)
+		}
+		if err := m.updateTimestamps(&cur.Timestamps); err != nil {
+			return err
+		}
+		if err := m.mutateSingleInTransaction(tx, ds.NewUpdate(m.createBlobKey(cur.Key), cur)); err != nil {
+			return err
+		}
+		newSize = cur.Size
+		return m.updateCurrentBlobSize(ctx, tx, cur)
+	})
+	if err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	return newSize, nil
+}
+
+// acquireAppendLease checks that the blob can be appended to and marks it as
+// being appended.
+func (m *MetaDB) acquireAppendLease(ctx context.Context, blobKey uuid.UUID) (*blobref.BlobRef, error) {
+	var b *blobref.BlobRef
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		var err error
+		b, err = m.getBlobRef(ctx, tx, blobKey)
+		if err != nil {
+			return err
+		}
+		if b.Chunked {
+			return status.Errorf(codes.FailedPrecondition, "BlobRef (%v) is chunked and can't be appended to", blobKey)
+		}
+		if b.Status != blobref.StatusReady {
+			return status.Errorf(codes.FailedPrecondition, "BlobRef (%v) is not ready: %v", blobKey, b.Status)
+		}
+		now := time.Now()
+		if b.AppendID != "" && now.Before(b.AppendExpiresAt) {
+			return ErrAppendInProgress
+		}
+		b.AppendID = uuid.NewString()
+		b.AppendExpiresAt = now.Add(appendLeaseTTL)
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(m.createBlobKey(b.Key), b))
+	})
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return b, nil
+}
+
+// releaseAppendLease clears the append lease of b after a failed append so that
+// it can be retried immediately. Failures are logged as the lease expires anyway.
+func (m *MetaDB) releaseAppendLease(ctx context.Context, b *blobref.BlobRef) {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		cur, err := m.getBlobRef(ctx, tx, b.Key)
+		if err != nil {
+			return err
+		}
+		if cur.AppendID != b.AppendID {
+			return nil
+		}
+		cur.AppendID = ""
+		cur.AppendExpiresAt = time.Time{}
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(m.createBlobKey(cur.Key), cur))
+	})
+	if err != nil {
+		log.Errorf("Failed to release the append lease of BlobRef (%v): %v", b.Key, err)
+	}
+}
+
+// updateCurrentBlobSize sets BlobSize of the record to the size of b if b is
+// the current external blob of the record.
+func (m *MetaDB) updateCurrentBlobSize(ctx context.Context, tx *ds.Transaction, b *blobref.BlobRef) error {
+	rkey := m.createRecordKey(b.StoreKey, b.RecordKey)
+	r := new(record.Record)
+	if err := tx.Get(rkey, r); err != nil {
+		if err == ds.ErrNoSuchEntity {
+			return nil
+		}
+		return err
+	}
+	if r.ExternalBlob != b.Key {
+		return nil
+	}
+	r.BlobSize = b.Size
+	if err := m.updateTimestamps(&r.Timestamps); err != nil {
+		return err
+	}
+	entity, err := m.recordEntity(ctx, b.StoreKey, r)
+	if err != nil {
+		return err
+	}
+	return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity))
+}
+
+// crc32Writer extends the CRC32C checksum in sum with the written data.
+type crc32Writer struct {
+	sum *uint32
+}
+
+func (w crc32Writer) Write(p []byte) (int, error) {
+	*w.sum = crc32.Update(*w.sum, castagnoliTable, p)
+	return len(p), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"bytes"
+	"context"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setupAppendBlob creates a Ready, current BlobRef and its object with content.
+func setupAppendBlob(ctx context.Context, t *testing.T, metaDB *m.MetaDB, bs blob.BlobStore, content []byte) *blobref.BlobRef {
+	t.Helper()
+	_, _, b := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	require.NoError(t, bs.Put(ctx, b.ObjectPath(), content))
+	digest := checksums.NewDigest()
+	digest.Write(content)
+	b.Size = int64(len(content))
+	b.SetChecksums(digest)
+	_, b, err := metaDB.PromoteBlobRefToCurrent(ctx, b)
+	require.NoError(t, err)
+	return b
+}
+
+func TestMetaDB_AppendBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	b := setupAppendBlob(ctx, t, metaDB, bs, []byte("hello"))
+
+	size, err := metaDB.AppendBlob(ctx, bs, b.Key, bytes.NewReader([]byte(" world")))
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), size)
+
+	got, err := bs.Get(ctx, b.ObjectPath())
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(got))
+	updated, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), updated.Size)
+	assert.True(t, updated.HasCRC32C)
+	assert.Equal(t, crc32.Checksum(got, crc32.MakeTable(crc32.Castagnoli)), updated.GetCRC32C())
+	assert.Empty(t, updated.MD5)
+	assert.Empty(t, updated.ContentHash)
+	assert.Empty(t, updated.ContentHashAlgorithm)
+	assert.Empty(t, updated.AppendID)
+	r, err := metaDB.GetRecord(ctx, b.StoreKey, b.RecordKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), r.BlobSize)
+}
+
+func TestMetaDB_AppendBlobNotReady(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	_, _, b := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+
+	_, err := metaDB.AppendBlob(ctx, bs, b.Key, bytes.NewReader([]byte("data")))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// blockingReader signals started on the first Read and blocks until release is closed.
+type blockingReader struct {
+	started chan struct{}
+	release chan struct{}
+	r       io.Reader
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	select {
+	case <-b.started:
+	default:
+		close(b.started)
+	}
+	<-b.release
+	return b.r.Read(p)
+}
+
+func TestMetaDB_AppendBlobConcurrent(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	b := setupAppendBlob(ctx, t, metaDB, bs, []byte("hello"))
+
+	first := &blockingReader{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		r:       bytes.NewReader([]byte(" world")),
+	}
+	done := make(chan error)
+	go func() {
+		_, err := metaDB.AppendBlob(ctx, bs, b.Key, first)
+		done <- err
+	}()
+	<-first.started
+
+	_, err := metaDB.AppendBlob(ctx, bs, b.Key, bytes.NewReader([]byte(" again")))
+	assert.ErrorIs(t, err, m.ErrAppendInProgress)
+
+	close(first.release)
+	require.NoError(t, <-done)
+	got, err := bs.Get(ctx, b.ObjectPath())
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(got))
+
+	// The blob can be appended to again after the first append finishes.
+	size, err := metaDB.AppendBlob(ctx, bs, b.Key, bytes.NewReader([]byte(" again")))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("hello world again")), size)
+}
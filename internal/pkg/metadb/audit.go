@@ -0,0 +1,240 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+const auditKind = "audit"
+
+// AuditAction is the kind of record mutation in an AuditEntry.
+type AuditAction string
+
+// Actions of the record mutations.
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditChange is a change of a record field in an AuditEntry.
+// Properties are reported as "Properties.<name>". Old or New is empty if the
+// field or property didn't exist before or after the mutation.
+type AuditChange struct {
+	Field string `datastore:",noindex"`
+	Old   string `datastore:",noindex"`
+	New   string `datastore:",noindex"`
+}
+
+// AuditEntry is an entry of the audit trail of record mutations.
+// Entries are never updated once written.
+type AuditEntry struct {
+	// Key is the unique key of the entry.
+	Key       uuid.UUID `datastore:"-"`
+	StoreKey  string
+	RecordKey string
+	Action    AuditAction
+	// Actor is the identity of the caller, or empty if unknown.
+	Actor     string
+	Timestamp time.Time
+	// Diff is the list of the changed fields, sorted by Field.
+	Diff []AuditChange `datastore:",noindex"`
+}
+
+// Assert AuditEntry implements both PropertyLoadSave and KeyLoader.
+var _ ds.PropertyLoadSaver = new(AuditEntry)
+var _ ds.KeyLoader = new(AuditEntry)
+
+// Load implements the Datastore PropertyLoadSaver interface.
+func (a *AuditEntry) Load(ps []ds.Property) error {
+	return ds.LoadStruct(a, ps)
+}
+
+// Save implements the Datastore PropertyLoadSaver interface.
+func (a *AuditEntry) Save() ([]ds.Property, error) {
+	return ds.SaveStruct(a)
+}
+
+// LoadKey implements the Datastore KeyLoader interface.
+func (a *AuditEntry) LoadKey(k *ds.Key) error {
+	key, err := uuid.Parse(k.Name)
+	if err != nil {
+		return err
+	}
+	a.Key = key
+	return nil
+}
+
+// AuditSink receives an AuditEntry for every record mutation.
+// Implementations must be safe for concurrent use.
+type AuditSink interface {
+	RecordAudit(ctx context.Context, entry *AuditEntry) error
+}
+
+// NopAuditSink is an AuditSink that discards all entries.
+type NopAuditSink struct{}
+
+// RecordAudit does nothing.
+func (NopAuditSink) RecordAudit(context.Context, *AuditEntry) error {
+	return nil
+}
+
+// DatastoreAuditSink is an AuditSink that writes entries to the audit kind
+// with InsertAuditEntry.
+type DatastoreAuditSink struct {
+	MetaDB *MetaDB
+}
+
+// RecordAudit writes entry to Datastore.
+func (s DatastoreAuditSink) RecordAudit(ctx context.Context, entry *AuditEntry) error {
+	return s.MetaDB.InsertAuditEntry(ctx, entry)
+}
+
+func (m *MetaDB) createAuditKey(key uuid.UUID) *ds.Key {
+	k := ds.NameKey(auditKind, key.String(), nil)
+	k.Namespace = m.Namespace
+	return k
+}
+
+// InsertAuditEntry writes entry to the audit kind. A new Key is generated if
+// entry.Key is uuid.Nil. It returns AlreadyExists if an entry with the same
+// key exists, as entries are immutable.
+func (m *MetaDB) InsertAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.InsertAuditEntry")
+	defer span.End()
+
+	if entry.Key == uuid.Nil {
+		entry.Key = uuid.New()
+	}
+	return m.mutateSingle(ctx, ds.NewInsert(m.createAuditKey(entry.Key), entry))
+}
+
+// ListAuditEntries returns the audit entries of the record, oldest first.
+func (m *MetaDB) ListAuditEntries(ctx context.Context, storeKey, recordKey string) ([]*AuditEntry, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ListAuditEntries")
+	defer span.End()
+
+	query := m.newQuery(auditKind).Filter("StoreKey =", storeKey).Filter("RecordKey =", recordKey)
+	var entries []*AuditEntry
+	if _, err := m.client.GetAll(ctx, query, &entries); err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	// Sorted here to avoid requiring a composite index.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// NewAuditEntry returns an AuditEntry of action on the record, changing it
+// from before to after. before is nil for creations and after is nil for
+// deletions. Only fields that clients can change are compared.
+func NewAuditEntry(storeKey, recordKey string, action AuditAction, actor string, before, after *record.Record) *AuditEntry {
+	return &AuditEntry{
+		Key:       uuid.New(),
+		StoreKey:  storeKey,
+		RecordKey: recordKey,
+		Action:    action,
+		Actor:     actor,
+		Timestamp: time.Now(),
+		Diff:      AuditDiff(before, after),
+	}
+}
+
+// AuditDiff returns the changes of the client-visible fields between before
+// and after, either of which may be nil, sorted by Field.
+func AuditDiff(before, after *record.Record) []AuditChange {
+	b, a := auditFields(before), auditFields(after)
+	var diff []AuditChange
+	for field, old := range b {
+		if v, ok := a[field]; !ok || v != old {
+			diff = append(diff, AuditChange{Field: field, Old: old, New: v})
+		}
+	}
+	for field, v := range a {
+		if _, ok := b[field]; !ok {
+			diff = append(diff, AuditChange{Field: field, New: v})
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Field < diff[j].Field })
+	return diff
+}
+
+// auditFields returns the formatted client-visible fields of r. Empty fields
+// are omitted.
+func auditFields(r *record.Record) map[string]string {
+	fields := make(map[string]string)
+	if r == nil {
+		return fields
+	}
+	set := func(name, v string) {
+		if v != "" {
+			fields[name] = v
+		}
+	}
+	set("OwnerID", r.OwnerID)
+	set("OpaqueString", r.OpaqueString)
+	set("Category", r.Category)
+	if len(r.Tags) > 0 {
+		set("Tags", strings.Join(r.Tags, ","))
+	}
+	if r.BlobSize != 0 {
+		set("BlobSize", strconv.FormatInt(r.BlobSize, 10))
+	}
+	if r.ExternalBlob != uuid.Nil {
+		set("ExternalBlob", r.ExternalBlob.String())
+	}
+	for name, p := range r.Properties {
+		if p != nil {
+			set(propertiesField+"."+name, formatPropertyValue(p))
+		}
+	}
+	return fields
+}
+
+// formatPropertyValue returns a string representation of p including its type.
+func formatPropertyValue(p *record.PropertyValue) string {
+	switch p.Type {
+	case pb.Property_BOOLEAN:
+		return fmt.Sprintf("boolean:%v", p.BooleanValue)
+	case pb.Property_INTEGER:
+		return fmt.Sprintf("integer:%v", p.IntegerValue)
+	case pb.Property_STRING:
+		return fmt.Sprintf("string:%q", p.StringValue)
+	case pb.Property_BYTES:
+		return "bytes:" + base64.StdEncoding.EncodeToString(p.BytesValue)
+	case pb.Property_LIST:
+		elements := make([]string, 0, len(p.ListValue))
+		for _, e := range p.ListValue {
+			elements = append(elements, formatPropertyValue(e))
+		}
+		return "list:[" + strings.Join(elements, ",") + "]"
+	}
+	return p.Type.String()
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before := &record.Record{
+		OwnerID: "owner",
+		Tags:    []string{"a"},
+		Properties: record.PropertyMap{
+			"level": {Type: pb.Property_INTEGER, IntegerValue: 1},
+			"name":  {Type: pb.Property_STRING, StringValue: "hero"},
+		},
+	}
+	after := &record.Record{
+		OwnerID: "owner",
+		Tags:    []string{"a", "b"},
+		Properties: record.PropertyMap{
+			"level": {Type: pb.Property_INTEGER, IntegerValue: 2},
+			"flag":  {Type: pb.Property_BOOLEAN, BooleanValue: true},
+		},
+	}
+	assert.Equal(t, []m.AuditChange{
+		{Field: "Properties.flag", New: "boolean:true"},
+		{Field: "Properties.level", Old: "integer:1", New: "integer:2"},
+		{Field: "Properties.name", Old: `string:"hero"`},
+		{Field: "Tags", Old: "a", New: "a,b"},
+	}, m.AuditDiff(before, after))
+
+	assert.Equal(t, []m.AuditChange{{Field: "OwnerID", New: "owner"}}, m.AuditDiff(nil, &record.Record{OwnerID: "owner"}))
+	external := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	assert.Equal(t, []m.AuditChange{{Field: "ExternalBlob", New: external.String()}},
+		m.AuditDiff(&record.Record{}, &record.Record{ExternalBlob: external}))
+	assert.Empty(t, m.AuditDiff(before, before))
+	assert.Empty(t, m.AuditDiff(nil, nil))
+}
+
+func TestMetaDB_InsertAuditEntry(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, recordKey := newStoreKey(), newRecordKey()
+
+	create := m.NewAuditEntry(storeKey, recordKey, m.AuditActionCreate, "player", nil,
+		&record.Record{Properties: record.PropertyMap{"level": {Type: pb.Property_INTEGER, IntegerValue: 1}}})
+	del := m.NewAuditEntry(storeKey, recordKey, m.AuditActionDelete, "", nil, nil)
+	del.Timestamp = create.Timestamp.Add(time.Second)
+	client := newDatastoreClient(ctx, t)
+	for _, e := range []*m.AuditEntry{del, create} {
+		require.NoError(t, metaDB.InsertAuditEntry(ctx, e))
+		key := datastore.NameKey("audit", e.Key.String(), nil)
+		key.Namespace = metaDB.Namespace
+		t.Cleanup(func() { client.Delete(ctx, key) })
+	}
+	err := metaDB.InsertAuditEntry(ctx, create)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err), "audit entries must not be overwritten")
+
+	entries, err := metaDB.ListAuditEntries(ctx, storeKey, recordKey)
+	require.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, create.Key, entries[0].Key)
+		assert.Equal(t, m.AuditActionCreate, entries[0].Action)
+		assert.Equal(t, "player", entries[0].Actor)
+		assert.Equal(t, create.Diff, entries[0].Diff)
+		assert.Equal(t, del.Key, entries[1].Key)
+		assert.Empty(t, entries[1].Diff)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxTransactionEntityGroups is the maximum number of entity groups that a
+// single Datastore transaction can touch.
+const MaxTransactionEntityGroups = 25
+
+// UpdateRecordsOptions configures UpdateRecords.
+type UpdateRecordsOptions struct {
+	// SkipMissing makes UpdateRecords ignore the records that don't exist
+	// instead of failing the whole batch.
+	SkipMissing bool
+}
+
+// UpdateRecords atomically updates multiple records in the store with storeKey.
+// updaters maps record keys to the callbacks that change the records, as in
+// UpdateRecord. All records are read and saved in a single transaction, so
+// either all or none of the updates are applied. An updater returning
+// ErrNoUpdate skips saving its record, and any other error aborts the batch.
+// Returns the updated records keyed by the record keys.
+//
+// The records share the store's entity group, but each external blob that is
+// replaced by an inline blob is another entity group. The batch fails with
+// FailedPrecondition without saving anything if the updates would touch more
+// than MaxTransactionEntityGroups entity groups.
+// Returned errors:
+//   - NotFound: a record doesn't exist and opts.SkipMissing is false, or
+//     ErrRecordExpired if it has expired, which SkipMissing skips as well
+//   - FailedPrecondition: the transaction would touch too many entity groups,
+//     or ErrLeaseHeld if a lease is held on a record
+func (m *MetaDB) UpdateRecords(ctx context.Context, storeKey string, updaters map[string]RecordUpdater,
+	opts UpdateRecordsOptions) (map[string]*record.Record, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.UpdateRecords")
+	defer span.End()
+
+	if len(updaters) == 0 {
+		return map[string]*record.Record{}, nil
+	}
+	recordKeys := make([]string, 0, len(updaters))
+	for key, updater := range updaters {
+		if updater == nil {
+			return nil, status.Errorf(codes.Internal, "updater for record (%v) cannot be nil", key)
+		}
+		recordKeys = append(recordKeys, key)
+	}
+	// Sort the keys to make the order of the updater calls deterministic.
+	sort.Strings(recordKeys)
+	dsKeys := make([]*ds.Key, len(recordKeys))
+	for i, key := range recordKeys {
+		dsKeys[i] = m.createRecordKey(storeKey, key)
+	}
+	saveOptions, err := m.recordSaveOptions(ctx, storeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated map[string]*record.Record
+	_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		updated = make(map[string]*record.Record, len(recordKeys))
+		now := time.Now()
+		records := make([]*record.Record, len(recordKeys))
+		for i := range records {
+			records[i] = new(record.Record)
+		}
+		var missing ds.MultiError
+		if err := tx.GetMulti(dsKeys, records); err != nil && !errors.As(err, &missing) {
+			return err
+		}
+
+		var muts []*ds.Mutation
+		blobGroups := make(map[uuid.UUID]bool)
+		for i, key := range recordKeys {
+			if missing != nil && missing[i] != nil {
+				switch {
+				case missing[i] != ds.ErrNoSuchEntity:
+					return missing[i]
+				case opts.SkipMissing:
+					continue
+				}
+				return status.Errorf(codes.NotFound, "record (%v) was not found in store (%v)", key, storeKey)
+			}
+			if records[i].IsExpired(now) {
+				if opts.SkipMissing {
+					continue
+				}
+				return ErrRecordExpired
+			}
+			oldExternalBlob := records[i].ExternalBlob
+			r, err := m.applyRecordUpdater(ctx, tx, records[i], "", updaters[key])
+			if err == ErrNoUpdate {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if r.ExternalBlob != oldExternalBlob {
+				blobGroups[oldExternalBlob] = true
+			}
+			// One entity group for the store and one for each blob.
+			if 1+len(blobGroups) > MaxTransactionEntityGroups {
+				return status.Errorf(codes.FailedPrecondition,
+					"UpdateRecords: the batch touches more than %v entity groups; split it into smaller batches",
+					MaxTransactionEntityGroups)
+			}
+			m.deriveProperties(storeKey, r)
+			muts = append(muts, ds.NewUpdate(dsKeys[i], r.WithSaveOptions(saveOptions)))
+			updated[key] = r
+		}
+		if len(muts) == 0 {
+			return nil
+		}
+		if _, err := tx.Mutate(muts...); err != nil {
+			return firstMultiError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return updated, nil
+}
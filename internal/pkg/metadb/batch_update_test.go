@@ -0,0 +1,187 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setScore(score int64) m.RecordUpdater {
+	return func(r *record.Record) (*record.Record, error) {
+		r.Properties["score"] = &record.PropertyValue{Type: pb.Property_INTEGER, IntegerValue: score}
+		return r, nil
+	}
+}
+
+func setupBatchUpdateRecords(ctx context.Context, t *testing.T, metaDB *m.MetaDB, n int) (*store.Store, []*record.Record) {
+	t.Helper()
+	s, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+	records := make([]*record.Record, n)
+	for i := range records {
+		records[i] = setupTestRecord(ctx, t, metaDB, s.Key, &record.Record{
+			Key:        newRecordKey(),
+			Properties: make(record.PropertyMap),
+		})
+	}
+	return s, records
+}
+
+func TestMetaDB_UpdateRecords(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	s, records := setupBatchUpdateRecords(ctx, t, metaDB, 3)
+
+	updated, err := metaDB.UpdateRecords(ctx, s.Key, map[string]m.RecordUpdater{
+		records[0].Key: setScore(10),
+		records[1].Key: setScore(20),
+		records[2].Key: func(r *record.Record) (*record.Record, error) {
+			return r, m.ErrNoUpdate
+		},
+	}, m.UpdateRecordsOptions{})
+	require.NoError(t, err)
+	assert.Len(t, updated, 2)
+
+	for i, want := range []int64{10, 20} {
+		assertIntProperty(ctx, t, metaDB, records[i], "score", want)
+		if assert.Contains(t, updated, records[i].Key) {
+			assert.Equal(t, want, updated[records[i].Key].Properties["score"].IntegerValue)
+		}
+	}
+	got, err := metaDB.GetRecord(ctx, s.Key, records[2].Key)
+	require.NoError(t, err)
+	assert.NotContains(t, got.Properties, "score")
+	assert.Equal(t, records[2].Timestamps, got.Timestamps)
+}
+
+func TestMetaDB_UpdateRecordsAbortsOnMissingRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	s, records := setupBatchUpdateRecords(ctx, t, metaDB, 2)
+	missing := newRecordKey()
+	updaters := map[string]m.RecordUpdater{
+		records[0].Key: setScore(10),
+		records[1].Key: setScore(20),
+		missing:        setScore(30),
+	}
+
+	_, err := metaDB.UpdateRecords(ctx, s.Key, updaters, m.UpdateRecordsOptions{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	for _, r := range records {
+		got, err := metaDB.GetRecord(ctx, s.Key, r.Key)
+		require.NoError(t, err)
+		assert.NotContains(t, got.Properties, "score", "no record must be updated")
+	}
+
+	updated, err := metaDB.UpdateRecords(ctx, s.Key, updaters, m.UpdateRecordsOptions{SkipMissing: true})
+	require.NoError(t, err)
+	assert.Len(t, updated, 2)
+	assert.NotContains(t, updated, missing)
+	assertIntProperty(ctx, t, metaDB, records[0], "score", 10)
+	assertIntProperty(ctx, t, metaDB, records[1], "score", 20)
+}
+
+func TestMetaDB_UpdateRecordsUpdaterErrorAborts(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	s, records := setupBatchUpdateRecords(ctx, t, metaDB, 2)
+
+	_, err := metaDB.UpdateRecords(ctx, s.Key, map[string]m.RecordUpdater{
+		records[0].Key: setScore(10),
+		records[1].Key: func(r *record.Record) (*record.Record, error) {
+			return nil, status.Error(codes.InvalidArgument, "invalid update")
+		},
+	}, m.UpdateRecordsOptions{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	got, err := metaDB.GetRecord(ctx, s.Key, records[0].Key)
+	require.NoError(t, err)
+	assert.NotContains(t, got.Properties, "score")
+}
+
+func TestMetaDB_UpdateRecordsSaveErrorAborts(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	s, records := setupBatchUpdateRecords(ctx, t, metaDB, 2)
+	// Updaters are called in the order of the keys, so that the second
+	// mutation of the transaction is the invalid one.
+	sort.Slice(records, func(i, j int) bool { return records[i].Key < records[j].Key })
+
+	_, err := metaDB.UpdateRecords(ctx, s.Key, map[string]m.RecordUpdater{
+		records[0].Key: setScore(10),
+		records[1].Key: func(r *record.Record) (*record.Record, error) {
+			r.Properties["invalid.name"] = &record.PropertyValue{Type: pb.Property_INTEGER, IntegerValue: 1}
+			return r, nil
+		},
+	}, m.UpdateRecordsOptions{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	for _, r := range records {
+		got, err := metaDB.GetRecord(ctx, s.Key, r.Key)
+		require.NoError(t, err)
+		assert.NotContains(t, got.Properties, "score", "no record must be updated")
+	}
+}
+
+func TestMetaDB_UpdateRecordsEntityGroupLimit(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	s, records := setupBatchUpdateRecords(ctx, t, metaDB, m.MaxTransactionEntityGroups)
+
+	// Replacing every external blob with an inline blob touches the store and
+	// all of the blobs, one more entity group than the limit.
+	updaters := make(map[string]m.RecordUpdater)
+	blobs := make([]*blobref.BlobRef, len(records))
+	for i, r := range records {
+		blobs[i] = setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, s.Key, r.Key))
+		_, _, err := metaDB.PromoteBlobRefToCurrent(ctx, blobs[i])
+		require.NoError(t, err)
+		updaters[r.Key] = func(r *record.Record) (*record.Record, error) {
+			r.Blob = []byte("inline")
+			r.BlobSize = int64(len(r.Blob))
+			return r, nil
+		}
+	}
+
+	_, err := metaDB.UpdateRecords(ctx, s.Key, updaters, m.UpdateRecordsOptions{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	for i, r := range records {
+		got, err := metaDB.GetRecord(ctx, s.Key, r.Key)
+		require.NoError(t, err)
+		assert.Equal(t, blobs[i].Key, got.ExternalBlob)
+		b, err := metaDB.GetBlobRef(ctx, blobs[i].Key)
+		require.NoError(t, err)
+		assert.Equal(t, blobref.StatusReady, b.Status)
+	}
+
+	// The batch succeeds without one of the records.
+	delete(updaters, records[0].Key)
+	updated, err := metaDB.UpdateRecords(ctx, s.Key, updaters, m.UpdateRecordsOptions{})
+	require.NoError(t, err)
+	assert.Len(t, updated, len(records)-1)
+	for _, r := range updated {
+		assert.Equal(t, uuid.Nil, r.ExternalBlob)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeleteBlobAs deletes the blob specified by blobKey on behalf of ownerID.
+// The blob is detached from the record if it is the current blob of the record,
+// then its objects are deleted from bs, and finally the BlobRef is deleted.
+// If the objects can't be deleted, the BlobRef is left marked for deletion so that
+// the garbage collector can retry.
+// Returned errors:
+//   - NotFound: the blobref object is not found
+//   - PermissionDenied (ErrPermissionDenied): the blob has an owner and it is not ownerID
+//   - Internal: some objects couldn't be deleted
+func (m *MetaDB) DeleteBlobAs(ctx context.Context, bs blob.BlobStore, blobKey uuid.UUID, ownerID string) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteBlobAs")
+	defer span.End()
+
+	err := m.RunWithDeferredBlobDeletes(ctx, bs, func(tx *ds.Transaction) ([]string, error) {
+		b, err := m.getBlobRef(ctx, tx, blobKey)
+		if err != nil {
+			return nil, err
+		}
+		if b.OwnerID != "" && b.OwnerID != ownerID {
+			return nil, ErrPermissionDenied
+		}
+		if err := m.detachBlobRefForDeletion(ctx, tx, b); err != nil {
+			return nil, err
+		}
+		return m.blobObjectPaths(ctx, b)
+	})
+	if err != nil {
+		return err
+	}
+	return m.DeleteBlobRef(ctx, blobKey)
+}
+
+// detachBlobRefForDeletion removes b from its record if b is the current blob
+// of the record, and marks b for deletion in tx.
+func (m *MetaDB) detachBlobRefForDeletion(ctx context.Context, tx *ds.Transaction, b *blobref.BlobRef) error {
+	rkey := m.createRecordKey(b.StoreKey, b.RecordKey)
+	r := new(record.Record)
+	err := tx.Get(rkey, r)
+	if err != nil && err != ds.ErrNoSuchEntity {
+		return err
+	}
+	if err == nil && r.ExternalBlob == b.Key {
+		r.BlobSize = 0
+		r.Chunked = false
+		r.ChunkCount = 0
+		r, err = m.markBlobRefForDeletion(tx, r, b, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		entity, err := m.recordEntity(ctx, b.StoreKey, r)
+		if err != nil {
+			return err
+		}
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity))
+	}
+	if b.Status == blobref.StatusPendingDeletion || b.Status == blobref.StatusError {
+		// Already uncommitted.
+		return nil
+	}
+	if err := b.MarkForDeletion(); err != nil {
+		return status.Errorf(codes.Internal, "failed to transition the blob state for deletion: current = %v", b.Status)
+	}
+	if err := m.updateTimestamps(&b.Timestamps); err != nil {
+		return err
+	}
+	return m.mutateSingleInTransaction(tx, ds.NewUpdate(m.createBlobKey(b.Key), b))
+}
+
+// blobObjectPaths returns the paths of objects that belong to b.
+func (m *MetaDB) blobObjectPaths(ctx context.Context, b *blobref.BlobRef) ([]string, error) {
+	if !b.Chunked {
+		return []string{b.ObjectPath()}, nil
+	}
+	paths := []string{}
+	cur := m.GetChildChunkRefs(ctx, b.Key)
+	for {
+		chunk, err := cur.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, datastoreErrToGRPCStatus(err)
+		}
+		paths = append(paths, chunk.ObjectPath())
+	}
+	return paths, nil
+}
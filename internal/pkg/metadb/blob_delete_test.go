@@ -0,0 +1,110 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setupOwnedBlob creates a record owned by ownerID with a current blob whose
+// object is written to bs.
+func setupOwnedBlob(ctx context.Context, t *testing.T, metaDB *m.MetaDB, bs blob.BlobStore, ownerID string) (*record.Record, *blobref.BlobRef) {
+	t.Helper()
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), OwnerID: ownerID, Properties: make(record.PropertyMap)})
+	b := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(4, st.Key, r.Key))
+	assert.Equal(t, ownerID, b.OwnerID, "InsertBlobRef() should copy OwnerID of the record")
+	_, b, err := metaDB.PromoteBlobRefToCurrent(ctx, b)
+	require.NoError(t, err)
+	require.NoError(t, bs.Put(ctx, b.ObjectPath(), []byte("data")))
+	return r, b
+}
+
+func assertBlobDeleted(ctx context.Context, t *testing.T, metaDB *m.MetaDB, bs blob.BlobStore, r *record.Record, b *blobref.BlobRef) {
+	t.Helper()
+	_, err := metaDB.GetBlobRef(ctx, b.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	got, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uuid.Nil, got.ExternalBlob)
+		assert.Zero(t, got.BlobSize)
+	}
+	_, err = bs.Get(ctx, b.ObjectPath())
+	assert.Error(t, err, "the object should be deleted")
+}
+
+func TestMetaDB_DeleteBlobAsOwner(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	r, b := setupOwnedBlob(ctx, t, metaDB, bs, "owner")
+
+	assert.NoError(t, metaDB.DeleteBlobAs(ctx, bs, b.Key, "owner"))
+	assertBlobDeleted(ctx, t, metaDB, bs, r, b)
+}
+
+func TestMetaDB_DeleteBlobAsDenied(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	r, b := setupOwnedBlob(ctx, t, metaDB, bs, "owner")
+
+	err := metaDB.DeleteBlobAs(ctx, bs, b.Key, "someone else")
+	assert.ErrorIs(t, err, m.ErrPermissionDenied)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	got, err := metaDB.GetBlobRef(ctx, b.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blobref.StatusReady, got.Status)
+	}
+	gotRecord, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, b.Key, gotRecord.ExternalBlob)
+	}
+	_, err = bs.Get(ctx, b.ObjectPath())
+	assert.NoError(t, err)
+}
+
+func TestMetaDB_DeleteBlobAsLegacyBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	// Blobs of records without an owner don't have an owner either.
+	r, b := setupOwnedBlob(ctx, t, metaDB, bs, "")
+
+	assert.NoError(t, metaDB.DeleteBlobAs(ctx, bs, b.Key, "anyone"))
+	assertBlobDeleted(ctx, t, metaDB, bs, r, b)
+}
+
+func TestMetaDB_DeleteBlobAsNonExistent(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+
+	err := metaDB.DeleteBlobAs(ctx, bs, uuid.New(), "owner")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import "github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+
+// NewBlobRef returns blobref.NewBlobRef with the key generated by
+// BlobKeyGenerator and ShardedByStore set to ShardBlobsByStore.
+func (m *MetaDB) NewBlobRef(size int64, storeKey, recordKey string) *blobref.BlobRef {
+	b := blobref.NewBlobRef(size, storeKey, recordKey)
+	if m.BlobKeyGenerator != nil {
+		b.Key = m.BlobKeyGenerator.New()
+	}
+	b.ShardedByStore = m.ShardBlobsByStore
+	return b
+}
+
+// NewChunkedBlobRef returns blobref.NewChunkedBlobRef with the key generated
+// by BlobKeyGenerator and ShardedByStore set to ShardBlobsByStore.
+// The chunk objects are not sharded, see MultiBucketBlobStore.
+func (m *MetaDB) NewChunkedBlobRef(storeKey, recordKey string, chunkCount int64) *blobref.BlobRef {
+	b := blobref.NewChunkedBlobRef(storeKey, recordKey, chunkCount)
+	if m.BlobKeyGenerator != nil {
+		b.Key = m.BlobKeyGenerator.New()
+	}
+	b.ShardedByStore = m.ShardBlobsByStore
+	return b
+}
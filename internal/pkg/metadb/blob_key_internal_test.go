@@ -0,0 +1,45 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedKeyGenerator struct {
+	key uuid.UUID
+}
+
+func (g fixedKeyGenerator) New() uuid.UUID {
+	return g.key
+}
+
+func TestMetaDB_NewBlobRefKeyGenerator(t *testing.T) {
+	want := uuid.MustParse("d13c289f-8d6a-4c8f-9a0c-7a5e6c1b8d2e")
+	m := &MetaDB{BlobKeyGenerator: fixedKeyGenerator{key: want}}
+
+	assert.Equal(t, want, m.NewBlobRef(0, "store", "record").Key)
+	chunked := m.NewChunkedBlobRef("store", "record", 1)
+	assert.Equal(t, want, chunked.Key)
+	assert.True(t, chunked.Chunked)
+
+	// Keys are random without a generator.
+	m = new(MetaDB)
+	assert.NotEqual(t, want, m.NewBlobRef(0, "store", "record").Key)
+	assert.NotEqual(t, m.NewBlobRef(0, "store", "record").Key, m.NewBlobRef(0, "store", "record").Key)
+}
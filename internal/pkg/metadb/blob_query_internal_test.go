@@ -0,0 +1,40 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobRefsQuery_UpdatedBefore(t *testing.T) {
+	t.Parallel()
+
+	m := new(MetaDB)
+	before := time.Now()
+	got, err := m.blobRefsQuery(BlobRefFilter{StoreKey: "store", UpdatedBefore: before, Limit: 2})
+	require.NoError(t, err)
+	want := m.newQuery(blobKind).
+		Filter("StoreKey =", "store").
+		Filter("Timestamps.UpdatedAt <", before).
+		Limit(2)
+	assert.Equal(t, want, got, "UpdatedBefore and Limit must be pushed down to the query")
+
+	_, err = m.blobRefsQuery(BlobRefFilter{UpdatedBefore: before, ExpiresBefore: before})
+	assert.ErrorIs(t, err, ErrInequalityFilterConflict)
+}
@@ -15,6 +15,11 @@
 package blobref
 
 import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
 	"cloud.google.com/go/datastore"
 	"github.com/google/uuid"
 	pb "github.com/googleforgames/open-saves/api"
@@ -36,17 +41,76 @@ type BlobRef struct {
 	// It can be non-existent (e.g. deleted already) but then the Status
 	// should not be Blob StatusReady.
 	RecordKey string
+	// OwnerID is the owner of the record at the time the blob was created or
+	// became the current blob of the record.
+	// An empty OwnerID means the blob was created before ownership was recorded,
+	// and any caller is allowed to delete it.
+	OwnerID string `datastore:",omitempty"`
+	// KMSKeyName is the name of the Cloud KMS key the blob objects were
+	// encrypted with. It is kept for auditing, and empty if the default key is used.
+	KMSKeyName string `datastore:",omitempty,noindex"`
+	// ContentDisposition and CacheControl are the HTTP headers set on the blob
+	// objects and their signed URLs. No header is set if empty.
+	ContentDisposition string `datastore:",omitempty,noindex"`
+	CacheControl       string `datastore:",omitempty,noindex"`
+	// ContentType is the media type of the blob objects. It is either given by
+	// the client or inferred from the filename, and is empty if neither is known.
+	// It is indexed for BlobUsageByContentType.
+	ContentType string `datastore:",omitempty"`
+	// ObjectGeneration is the generation of the blob object, captured when the
+	// garbage collector first tries to delete it so that a retry doesn't delete a
+	// newer object at the same path. It is 0 until then.
+	ObjectGeneration int64 `datastore:",omitempty,noindex"`
 	// Chunked is whether the BlobRef is chunked or not.
 	Chunked bool
 	// ChunkCount is the number of chunks that should be associated to the BlobRef.
 	// It is set by either the client when starting a chunk upload or
 	// the server when committing a chunked upload.
 	ChunkCount int64
+	// ExpiresAt is the point in time when the blob expires.
+	// The zero value means the blob never expires.
+	ExpiresAt time.Time `datastore:",omitempty"`
+	// AppendID identifies the append to the blob object in progress, which is
+	// valid until AppendExpiresAt. Only one append can be in progress at a time.
+	AppendID        string    `datastore:",omitempty,noindex"`
+	AppendExpiresAt time.Time `datastore:",omitempty,noindex"`
+	// LastAccessedAt is the last time the blob was read. It is updated in
+	// batches by metadb.TouchBatcher, so it may lag behind by a short window.
+	LastAccessedAt time.Time `datastore:",omitempty,noindex"`
+	// FailureReason describes why the blob was found to be broken, e.g. by
+	// a checksum mismatch detected by the scrubber. It is empty otherwise.
+	FailureReason string `datastore:",omitempty,noindex"`
+	// PathEncoding is how ObjectPath builds the object path. It is set when
+	// the BlobRef is created and must not be changed after the object is
+	// created.
+	PathEncoding PathEncoding `datastore:",omitempty,noindex"`
+	// ShardedByStore makes ObjectPath put the object in the store segment of
+	// StoreKey with StoreObjectPath, so that blob.MultiBucketBlobStore routes
+	// it to the bucket of the store. Like PathEncoding, it is set when the
+	// BlobRef is created and must not be changed after the object is created.
+	ShardedByStore bool `datastore:",omitempty,noindex"`
+	// Metadata is a small set of opaque key-value pairs attached to the blob.
+	// Each entry is saved as a separate Datastore property prefixed by
+	// MetadataPropertyPrefix.
+	Metadata map[string]string `datastore:"-"`
+	// SchemaVersion is the version of the schema the BlobRef was saved with.
+	// Entities of older versions are upgraded by the migrations registered with
+	// RegisterMigration when loaded.
+	SchemaVersion int `datastore:",omitempty,noindex"`
+	// AllowMissingKeys disables the check for empty StoreKey and RecordKey in Save.
+	// It is meant for tests that save zero-valued BlobRefs and is not persisted.
+	AllowMissingKeys bool `datastore:"-"`
 
 	// Checksums have checksums for each blob object associated with the BlobRef entity.
 	// Record.{MD5,CRC32C} must be used for inline blobs, and
 	// ChunkRef.{MD5,CRC32C} must be used for chunked blobs.
 	checksums.Checksums `datastore:",flatten"`
+	// ContentHash is the hash of the whole blob content calculated with
+	// ContentHashAlgorithm, used to find existing blobs with the same content.
+	// Both are empty for chunked blobs and blobs saved before content hashes
+	// were introduced, and are indexed for MetaDB.FindBlobRefByContentHash.
+	ContentHash          []byte                  `datastore:",omitempty"`
+	ContentHashAlgorithm checksums.HashAlgorithm `datastore:",omitempty"`
 
 	// Timestamps keeps track of creation and modification times and stores a randomly
 	// generated UUID to maintain consistency.
@@ -57,6 +121,19 @@ type BlobRef struct {
 var _ datastore.PropertyLoadSaver = new(BlobRef)
 var _ datastore.KeyLoader = new(BlobRef)
 
+const (
+	// MetadataPropertyPrefix is the reserved Datastore property name prefix
+	// for Metadata entries.
+	MetadataPropertyPrefix = "Metadata."
+	// MaxMetadataSize is the maximum total byte length of Metadata keys and values.
+	MaxMetadataSize = 4 * 1024
+)
+
+var (
+	ErrMetadataTooLarge = errors.New("blob metadata exceeds the maximum size")
+	ErrMissingKey       = errors.New("blob StoreKey and RecordKey must not be empty")
+)
+
 // These functions need to be implemented here instead of the datastore package because
 // go doesn't permit to define additional receivers in another package.
 // Save and Load replicates the default behaviors, however, they are required
@@ -64,14 +141,76 @@ var _ datastore.KeyLoader = new(BlobRef)
 
 // Save implements the Datastore PropertyLoadSaver interface and converts the properties
 // field in the struct to separate Datastore properties.
+// Save has no side effects, so it can be called to preview the entity.
 func (b *BlobRef) Save() ([]datastore.Property, error) {
-	return datastore.SaveStruct(b)
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	properties, err := datastore.SaveStruct(b)
+	if err != nil {
+		return nil, err
+	}
+	// UpdatedAt is indexed for the UpdatedBefore filter of QueryBlobRefs.
+	timestamps.IndexProperties(properties, "UpdatedAt")
+	keys := make([]string, 0, len(b.Metadata))
+	for k := range b.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		properties = append(properties, datastore.Property{
+			Name:    MetadataPropertyPrefix + k,
+			Value:   b.Metadata[k],
+			NoIndex: true,
+		})
+	}
+	return properties, nil
 }
 
 // Load implements the Datastore PropertyLoadSaver interface and converts Datstore
 // properties to the Properties field.
 func (b *BlobRef) Load(ps []datastore.Property) error {
-	return datastore.LoadStruct(b, ps)
+	b.Metadata = nil
+	rest := make([]datastore.Property, 0, len(ps))
+	for _, p := range ps {
+		if !strings.HasPrefix(p.Name, MetadataPropertyPrefix) {
+			rest = append(rest, p)
+			continue
+		}
+		if b.Metadata == nil {
+			b.Metadata = make(map[string]string)
+		}
+		v, _ := p.Value.(string)
+		b.Metadata[strings.TrimPrefix(p.Name, MetadataPropertyPrefix)] = v
+	}
+	if err := datastore.LoadStruct(b, rest); err != nil {
+		return err
+	}
+	b.Migrate()
+	return nil
+}
+
+// Validate checks that the BlobRef can be saved. It returns ErrMissingKey if
+// either StoreKey or RecordKey is empty, unless AllowMissingKeys is set, and
+// the result of ValidateMetadata otherwise.
+func (b *BlobRef) Validate() error {
+	if !b.AllowMissingKeys && (b.StoreKey == "" || b.RecordKey == "") {
+		return ErrMissingKey
+	}
+	return b.ValidateMetadata()
+}
+
+// ValidateMetadata returns ErrMetadataTooLarge if the total byte length of
+// Metadata keys and values exceeds MaxMetadataSize.
+func (b *BlobRef) ValidateMetadata() error {
+	size := 0
+	for k, v := range b.Metadata {
+		size += len(k) + len(v)
+	}
+	if size > MaxMetadataSize {
+		return ErrMetadataTooLarge
+	}
+	return nil
 }
 
 // LoadKey implements the KeyLoader interface and sets the value to the Key field.
@@ -84,18 +223,20 @@ func (b *BlobRef) LoadKey(k *datastore.Key) error {
 }
 
 // NewBlobRef creates a new BlobRef as follows:
-//   - Set a new UUID to Key
+//   - Set a new random UUID to Key
 //   - Initialize Size and ObjectName as specified
 //   - Set Status to BlobRefStatusInitializing
 //   - Set current time to Timestamps (both created and updated at)
+//   - Set SchemaVersion to the latest version
 func NewBlobRef(size int64, storeKey, recordKey string) *BlobRef {
 	return &BlobRef{
-		Key:        uuid.New(),
-		Size:       size,
-		Status:     StatusInitializing,
-		StoreKey:   storeKey,
-		RecordKey:  recordKey,
-		Timestamps: timestamps.New(),
+		Key:           uuid.New(),
+		Size:          size,
+		Status:        StatusInitializing,
+		StoreKey:      storeKey,
+		RecordKey:     recordKey,
+		Timestamps:    timestamps.New(),
+		SchemaVersion: LatestSchemaVersion(),
 	}
 }
 
@@ -109,9 +250,61 @@ func NewChunkedBlobRef(storeKey, recordKey string, chunkCount int64) *BlobRef {
 	return b
 }
 
+// SetTTL sets ExpiresAt to ttl after the creation time of the BlobRef.
+// defaultTTL is used instead if ttl is not positive, and ExpiresAt is cleared
+// if neither is positive, meaning the blob never expires.
+func (b *BlobRef) SetTTL(ttl, defaultTTL time.Duration) {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if ttl <= 0 {
+		b.ExpiresAt = time.Time{}
+		return
+	}
+	b.ExpiresAt = b.Timestamps.CreatedAt.Add(ttl)
+}
+
+// Age returns how long ago the BlobRef was created at now.
+// It is negative if now is before the creation time.
+func (b *BlobRef) Age(now time.Time) time.Duration {
+	return now.Sub(b.Timestamps.CreatedAt)
+}
+
+// Reinitialize changes Status from StatusReady back to StatusInitializing so
+// that the blob can be uploaded again with the same key. It also clears Size
+// Checksums, and the content hash, and updates Timestamps.
+// It returns ErrReinitializeNotReady if the current Status is not StatusReady.
+func (b *BlobRef) Reinitialize() error {
+	if err := b.Status.Reinitialize(); err != nil {
+		return err
+	}
+	b.Size = 0
+	b.Checksums = checksums.Checksums{}
+	b.ContentHash = nil
+	b.ContentHashAlgorithm = ""
+	b.Timestamps.Update()
+	return nil
+}
+
+// SetChecksums sets the checksums and the content hash calculated by d.
+func (b *BlobRef) SetChecksums(d *checksums.Digest) {
+	b.Checksums = d.Checksums()
+	b.ContentHashAlgorithm, b.ContentHash = d.ContentHash()
+}
+
 // ObjectPath returns an object path for the backend blob storage.
+// It is the key as is for PathEncodingKey, and the key with a hash prefix
+// returned by ObjectPathWithPrefix otherwise, in the store segment returned by
+// StoreObjectPath if ShardedByStore is set.
 func (b *BlobRef) ObjectPath() string {
-	return b.Key.String()
+	path := b.Key.String()
+	if b.PathEncoding != PathEncodingKey {
+		path = ObjectPathWithPrefix(b.PathEncoding, path)
+	}
+	if b.ShardedByStore {
+		return StoreObjectPath(b.StoreKey, path)
+	}
+	return path
 }
 
 // ToProto returns a BlobMetadata representation of the object.
@@ -125,5 +318,11 @@ func (b *BlobRef) ToProto() *pb.BlobMetadata {
 		ChunkCount: b.ChunkCount,
 		Crc32C:     b.GetCRC32C(),
 		HasCrc32C:  b.HasCRC32C,
+		ExpiresAt:  timestamps.TimeToProto(b.ExpiresAt),
+		Metadata:   b.Metadata,
+
+		ContentDisposition: b.ContentDisposition,
+		CacheControl:       b.CacheControl,
+		ContentType:        b.ContentType,
 	}
 }
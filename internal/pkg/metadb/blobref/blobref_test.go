@@ -15,6 +15,10 @@
 package blobref
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,6 +51,105 @@ func TestBlobRef_New(t *testing.T) {
 	}
 }
 
+func TestBlobRef_SetTTL(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name       string
+		ttl        time.Duration
+		defaultTTL time.Duration
+		want       time.Time
+	}{
+		{"store default", 0, time.Hour, createdAt.Add(time.Hour)},
+		{"override", time.Minute, time.Hour, createdAt.Add(time.Minute)},
+		{"blob only", time.Minute, 0, createdAt.Add(time.Minute)},
+		{"never expires", 0, 0, time.Time{}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b := &BlobRef{Timestamps: timestamps.Timestamps{CreatedAt: createdAt}}
+			b.SetTTL(tc.ttl, tc.defaultTTL)
+			if diff := cmp.Diff(tc.want, b.ExpiresAt); diff != "" {
+				t.Errorf("SetTTL() ExpiresAt = (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBlobRef_Age(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC)
+	b := &BlobRef{Timestamps: timestamps.Timestamps{CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour)}}
+	if got := b.Age(createdAt.Add(90 * time.Minute)); got != 90*time.Minute {
+		t.Errorf("Age() = %v, want %v", got, 90*time.Minute)
+	}
+	if got := b.Age(createdAt); got != 0 {
+		t.Errorf("Age() = %v, want 0", got)
+	}
+	// now before the creation time, e.g. due to clock skew.
+	if got := b.Age(createdAt.Add(-time.Minute)); got != -time.Minute {
+		t.Errorf("Age() = %v, want %v", got, -time.Minute)
+	}
+}
+
+func TestBlobRef_Reinitialize(t *testing.T) {
+	t.Parallel()
+
+	b := NewBlobRef(123, "store", "record")
+	b.Checksums = checksums.Checksums{MD5: []byte{1, 2, 3}, CRC32C: 42, HasCRC32C: true}
+	b.ContentHash, b.ContentHashAlgorithm = []byte{1, 2, 3}, checksums.HashAlgorithmMD5
+	b.Timestamps.UpdatedAt = b.Timestamps.UpdatedAt.Add(-time.Hour)
+	if err := b.Ready(); err != nil {
+		t.Fatalf("Ready() failed: %v", err)
+	}
+	before := b.Timestamps
+	if err := b.Reinitialize(); err != nil {
+		t.Fatalf("Reinitialize() failed: %v", err)
+	}
+	if b.Status != StatusInitializing {
+		t.Errorf("Reinitialize() changed status to %v, want = %v", b.Status, StatusInitializing)
+	}
+	if b.Size != 0 {
+		t.Errorf("Reinitialize() should clear Size, got = %v", b.Size)
+	}
+	if diff := cmp.Diff(checksums.Checksums{}, b.Checksums); diff != "" {
+		t.Errorf("Reinitialize() should clear Checksums (-want, +got):\n%s", diff)
+	}
+	if b.ContentHash != nil || b.ContentHashAlgorithm != "" {
+		t.Errorf("Reinitialize() should clear the content hash, got = (%v, %q)", b.ContentHash, b.ContentHashAlgorithm)
+	}
+	if !b.Timestamps.UpdatedAt.After(before.UpdatedAt) {
+		t.Errorf("Reinitialize() should update UpdatedAt, got = %v, before = %v", b.Timestamps.UpdatedAt, before.UpdatedAt)
+	}
+	if b.Timestamps.Signature == before.Signature {
+		t.Errorf("Reinitialize() should update Signature")
+	}
+	if !b.Timestamps.CreatedAt.Equal(before.CreatedAt) {
+		t.Errorf("Reinitialize() should not change CreatedAt, got = %v, want = %v", b.Timestamps.CreatedAt, before.CreatedAt)
+	}
+}
+
+func TestBlobRef_ReinitializePendingDeletion(t *testing.T) {
+	t.Parallel()
+
+	b := NewBlobRef(123, "store", "record")
+	b.Checksums = checksums.Checksums{MD5: []byte{1, 2, 3}}
+	if err := b.MarkForDeletion(); err != nil {
+		t.Fatalf("MarkForDeletion() failed: %v", err)
+	}
+	want := *b
+	if err := b.Reinitialize(); err != ErrReinitializeNotReady {
+		t.Errorf("Reinitialize() returned %v, want = %v", err, ErrReinitializeNotReady)
+	}
+	if diff := cmp.Diff(&want, b); diff != "" {
+		t.Errorf("Reinitialize() should not change the BlobRef (-want, +got):\n%s", diff)
+	}
+}
+
 func TestBlobRef_LoadKey(t *testing.T) {
 	t.Parallel()
 
@@ -160,9 +263,8 @@ func TestBlobRef_Save(t *testing.T) {
 						NoIndex: true,
 					},
 					{
-						Name:    "UpdatedAt",
-						Value:   time.Date(1992, 11, 27, 1, 3, 11, 0, time.UTC),
-						NoIndex: true,
+						Name:  "UpdatedAt",
+						Value: time.Date(1992, 11, 27, 1, 3, 11, 0, time.UTC),
 					},
 					{
 						Name:    "Signature",
@@ -287,6 +389,208 @@ func TestBlobRef_Load(t *testing.T) {
 	}
 }
 
+func TestBlobRef_MetadataRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		metadata map[string]string
+	}{
+		{"populated", map[string]string{"filename": "save.dat", "uploader": "player 1"}},
+		{"empty", map[string]string{}},
+		{"nil", nil},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			blob := &BlobRef{
+				Size:      123,
+				Status:    StatusReady,
+				StoreKey:  "store",
+				RecordKey: "record",
+				Metadata:  tc.metadata,
+			}
+			ps, err := blob.Save()
+			if err != nil {
+				t.Fatalf("Save() failed: %v", err)
+			}
+			got := new(BlobRef)
+			if err := got.Load(ps); err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if diff := cmp.Diff(blob, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Load() = (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBlobRef_KMSKeyNameRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	blob := &BlobRef{
+		StoreKey:   "store",
+		RecordKey:  "record",
+		KMSKeyName: "projects/p/locations/l/keyRings/r/cryptoKeys/k",
+	}
+	ps, err := blob.Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	got := new(BlobRef)
+	if err := got.Load(ps); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if got.KMSKeyName != blob.KMSKeyName {
+		t.Errorf("Load() KMSKeyName = %q, want %q", got.KMSKeyName, blob.KMSKeyName)
+	}
+}
+
+func TestBlobRef_ContentHashRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	blob := &BlobRef{
+		StoreKey:             "store",
+		RecordKey:            "record",
+		ContentHash:          []byte("0123456789abcdef0123456789abcdef"),
+		ContentHashAlgorithm: checksums.HashAlgorithmSHA256,
+	}
+	ps, err := blob.Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	for _, p := range ps {
+		if p.Name == "ContentHashAlgorithm" && p.NoIndex {
+			t.Error("Save() should index ContentHashAlgorithm for lookups")
+		}
+	}
+	got := new(BlobRef)
+	if err := got.Load(ps); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if got.ContentHashAlgorithm != checksums.HashAlgorithmSHA256 {
+		t.Errorf("Load() ContentHashAlgorithm = %q, want %q", got.ContentHashAlgorithm, checksums.HashAlgorithmSHA256)
+	}
+	if !bytes.Equal(got.ContentHash, blob.ContentHash) {
+		t.Errorf("Load() ContentHash = %v, want %v", got.ContentHash, blob.ContentHash)
+	}
+}
+
+func TestBlobRef_ObjectHeadersRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	blob := &BlobRef{
+		StoreKey:           "store",
+		RecordKey:          "record",
+		ContentDisposition: `attachment; filename="save.dat"`,
+		CacheControl:       "private, max-age=3600",
+	}
+	ps, err := blob.Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	got := new(BlobRef)
+	if err := got.Load(ps); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if got.ContentDisposition != blob.ContentDisposition || got.CacheControl != blob.CacheControl {
+		t.Errorf("Load() = (%q, %q), want (%q, %q)", got.ContentDisposition, got.CacheControl, blob.ContentDisposition, blob.CacheControl)
+	}
+	if p := got.ToProto(); p.GetContentDisposition() != blob.ContentDisposition || p.GetCacheControl() != blob.CacheControl {
+		t.Errorf("ToProto() = (%q, %q), want (%q, %q)", p.GetContentDisposition(), p.GetCacheControl(), blob.ContentDisposition, blob.CacheControl)
+	}
+
+	// Unset headers are not saved.
+	ps, err = (&BlobRef{StoreKey: "store", RecordKey: "record"}).Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	for _, p := range ps {
+		if p.Name == "ContentDisposition" || p.Name == "CacheControl" {
+			t.Errorf("Save() saved an empty %s property", p.Name)
+		}
+	}
+}
+
+func TestBlobRef_SaveMetadataProperties(t *testing.T) {
+	t.Parallel()
+
+	blob := &BlobRef{
+		StoreKey:  "store",
+		RecordKey: "record",
+		Metadata:  map[string]string{"b": "2", "a": "1"},
+	}
+	ps, err := blob.Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	want := []datastore.Property{
+		{Name: MetadataPropertyPrefix + "a", Value: "1", NoIndex: true},
+		{Name: MetadataPropertyPrefix + "b", Value: "2", NoIndex: true},
+	}
+	if diff := cmp.Diff(want, ps[len(ps)-2:]); diff != "" {
+		t.Errorf("Save() = (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBlobRef_SaveOversizedMetadata(t *testing.T) {
+	t.Parallel()
+
+	blob := &BlobRef{
+		StoreKey:  "store",
+		RecordKey: "record",
+		Metadata: map[string]string{
+			"large": string(make([]byte, MaxMetadataSize)),
+		},
+	}
+	if err := blob.ValidateMetadata(); err != ErrMetadataTooLarge {
+		t.Errorf("ValidateMetadata() = %v, want %v", err, ErrMetadataTooLarge)
+	}
+	if _, err := blob.Save(); err != ErrMetadataTooLarge {
+		t.Errorf("Save() = %v, want %v", err, ErrMetadataTooLarge)
+	}
+}
+
+func TestBlobRef_SaveMissingKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		storeKey  string
+		recordKey string
+		allow     bool
+		wantErr   error
+	}{
+		{"both set", "store", "record", false, nil},
+		{"empty store key", "", "record", false, ErrMissingKey},
+		{"empty record key", "store", "", false, ErrMissingKey},
+		{"both empty", "", "", false, ErrMissingKey},
+		{"both empty but allowed", "", "", true, nil},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			blob := &BlobRef{
+				StoreKey:         tc.storeKey,
+				RecordKey:        tc.recordKey,
+				AllowMissingKeys: tc.allow,
+			}
+			if err := blob.Validate(); err != tc.wantErr {
+				t.Errorf("Validate() = %v, want %v", err, tc.wantErr)
+			}
+			ps, err := blob.Save()
+			if err != tc.wantErr {
+				t.Errorf("Save() = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && len(ps) == 0 {
+				t.Error("Save() returned no properties")
+			}
+		})
+	}
+}
+
 func TestBlobRef_GetObjectPath(t *testing.T) {
 	t.Parallel()
 
@@ -330,3 +634,59 @@ func TestBlobRef_ToProto(t *testing.T) {
 		t.Errorf("ToProto() = (-want, +got):\n%s", diff)
 	}
 }
+
+func TestObjectPathWithPrefix_Injective(t *testing.T) {
+	t.Parallel()
+
+	const n = 100000
+	seen := make(map[string]uuid.UUID, n)
+	for i := 0; i < n; i++ {
+		key := uuid.New()
+		path := ObjectPathWithPrefix(PathEncodingEscape, key.String())
+		if other, ok := seen[path]; ok && other != key {
+			t.Fatalf("ObjectPathWithPrefix() returned %q for both %v and %v", path, other, key)
+		}
+		seen[path] = key
+
+		prefix, rest, ok := strings.Cut(path, "/")
+		if !ok || len(prefix) != objectPathPrefixLen {
+			t.Fatalf("ObjectPathWithPrefix(%v) = %q, want a %d digit prefix", key, path, objectPathPrefixLen)
+		}
+		// The key is recoverable from the path, which makes the mapping injective.
+		if got, err := uuid.Parse(rest); err != nil || got != key {
+			t.Fatalf("ObjectPathWithPrefix(%v) = %q, want the path to end with the key", key, path)
+		}
+	}
+	if got := ObjectPathWithPrefix(PathEncodingEscape, uuid.Nil.String()); got != ObjectPathWithPrefix(PathEncodingEscape, uuid.Nil.String()) {
+		t.Errorf("ObjectPathWithPrefix() is not deterministic: %q", got)
+	}
+}
+
+func TestObjectPathWithPrefix_UUIDPrefix(t *testing.T) {
+	key := uuid.MustParse("397f94f5-f851-4969-8bd8-7828abc473a6")
+	// The prefix of a UUID key is the hash of its 16 bytes in any encoding.
+	sum := sha256.Sum256(key[:])
+	want := hex.EncodeToString(sum[:])[:objectPathPrefixLen]
+	for _, e := range []PathEncoding{PathEncodingEscape, PathEncodingBase64} {
+		if prefix, _, _ := strings.Cut(ObjectPathWithPrefix(e, key.String()), "/"); prefix != want {
+			t.Errorf("ObjectPathWithPrefix(%v, %v) has prefix %q, want %q", e, key, prefix, want)
+		}
+	}
+}
+
+func TestBlobRef_ObjectPath(t *testing.T) {
+	b := NewBlobRef(0, "store", "record")
+	if got := b.ObjectPath(); got != b.Key.String() {
+		t.Errorf("ObjectPath() = %q, want %q for PathEncodingKey", got, b.Key.String())
+	}
+	b.PathEncoding = PathEncodingBase64
+	segments, err := ParseObjectPath(PathEncodingBase64, b.ObjectPath())
+	if err != nil || len(segments) != 1 || segments[0] != b.Key.String() {
+		t.Errorf("ParseObjectPath(%q) = (%q, %v), want the key", b.ObjectPath(), segments, err)
+	}
+	unsharded := b.ObjectPath()
+	b.ShardedByStore = true
+	if got, want := b.ObjectPath(), StoreObjectPath("store", unsharded); got != want {
+		t.Errorf("ObjectPath() = %q, want %q with ShardedByStore", got, want)
+	}
+}
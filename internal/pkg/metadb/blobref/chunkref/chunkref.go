@@ -36,6 +36,10 @@ type ChunkRef struct {
 	Number int32
 	// Size is the byte size of the chunk.
 	Size int32
+	// Offset and Length are the byte range of the chunk in the blob declared
+	// by the client. Length is 0 if the client didn't declare the range.
+	Offset int64 `datastore:",omitempty,noindex"`
+	Length int64 `datastore:",omitempty,noindex"`
 	// Status is the current status of the chunk.
 	blobref.Status
 
@@ -138,5 +142,7 @@ func (c *ChunkRef) ToProto() *pb.ChunkMetadata {
 		Md5:       c.MD5,
 		Crc32C:    c.GetCRC32C(),
 		HasCrc32C: c.HasCRC32C,
+		Offset:    c.Offset,
+		Length:    c.Length,
 	}
 }
@@ -108,12 +108,15 @@ func TestChunkRef_ToProto(t *testing.T) {
 	blobKey := uuid.New()
 	c := chunkref.New(blobKey, 42)
 	c.Size = 12345
+	c.Offset, c.Length = 100, 12345
 	c.Checksums = checksumstest.RandomChecksums(t)
 	proto := c.ToProto()
 	if assert.NotNil(t, proto) {
 		assert.Equal(t, blobKey.String(), proto.GetSessionId())
 		assert.EqualValues(t, c.Size, proto.GetSize())
 		assert.EqualValues(t, c.Number, proto.GetNumber())
+		assert.Equal(t, c.Offset, proto.GetOffset())
+		assert.Equal(t, c.Length, proto.GetLength())
 		checksumstest.AssertProtoEqual(t, c.Checksums, proto)
 	}
 }
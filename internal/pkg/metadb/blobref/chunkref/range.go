@@ -0,0 +1,80 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkref
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrChunkRangeOverlap is returned by ValidateChunkRanges when two chunks
+	// overlap.
+	ErrChunkRangeOverlap = errors.New("chunk ranges overlap")
+	// ErrChunkRangeGap is returned by ValidateChunkRanges when a part of the
+	// blob is not covered by any chunk.
+	ErrChunkRangeGap = errors.New("chunk ranges have a gap")
+	// ErrChunkRangeOutOfBounds is returned by ValidateChunkRanges when a chunk
+	// has a negative offset or length, or extends beyond the blob size.
+	ErrChunkRangeOutOfBounds = errors.New("chunk range is out of bounds")
+)
+
+// HasRanges reports whether any of chunks declares its byte range.
+func HasRanges(chunks []*ChunkRef) bool {
+	for _, c := range chunks {
+		if c.Length != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateChunkRanges checks that the declared ranges [Offset, Offset+Length)
+// of chunks tile [0, totalSize) exactly. Chunks with zero Length are ignored.
+// The order of chunks doesn't matter and chunks is not modified.
+// It returns an error wrapping ErrChunkRangeOutOfBounds, ErrChunkRangeOverlap,
+// or ErrChunkRangeGap describing the first problem found.
+func ValidateChunkRanges(chunks []*ChunkRef, totalSize int64) error {
+	sorted := make([]*ChunkRef, 0, len(chunks))
+	for _, c := range chunks {
+		if c.Offset < 0 || c.Length < 0 || c.Offset > totalSize || c.Length > totalSize-c.Offset {
+			return fmt.Errorf("%w: chunk (%v) has range [%v, %v+%v), blob size is %v",
+				ErrChunkRangeOutOfBounds, c.Number, c.Offset, c.Offset, c.Length, totalSize)
+		}
+		if c.Length > 0 {
+			sorted = append(sorted, c)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Offset < sorted[j].Offset
+	})
+
+	end := int64(0)
+	for _, c := range sorted {
+		switch {
+		case c.Offset < end:
+			return fmt.Errorf("%w: chunk (%v) starts at %v before the end of the previous chunk at %v",
+				ErrChunkRangeOverlap, c.Number, c.Offset, end)
+		case c.Offset > end:
+			return fmt.Errorf("%w: [%v, %v) is not covered by any chunk", ErrChunkRangeGap, end, c.Offset)
+		}
+		end = c.Offset + c.Length
+	}
+	if end != totalSize {
+		return fmt.Errorf("%w: [%v, %v) is not covered by any chunk", ErrChunkRangeGap, end, totalSize)
+	}
+	return nil
+}
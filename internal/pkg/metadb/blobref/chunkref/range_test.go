@@ -0,0 +1,72 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkref_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRangedChunks(ranges ...[2]int64) []*chunkref.ChunkRef {
+	blobKey := uuid.New()
+	var chunks []*chunkref.ChunkRef
+	for i, r := range ranges {
+		c := chunkref.New(blobKey, int32(i))
+		c.Offset, c.Length = r[0], r[1]
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+func TestValidateChunkRanges(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ranges    [][2]int64
+		totalSize int64
+		want      error
+	}{
+		{"tiling", [][2]int64{{0, 10}, {10, 5}, {15, 1}}, 16, nil},
+		{"unordered tiling", [][2]int64{{10, 6}, {0, 10}}, 16, nil},
+		{"empty blob", nil, 0, nil},
+		{"zero length ignored", [][2]int64{{0, 16}, {16, 0}}, 16, nil},
+		{"overlap", [][2]int64{{0, 10}, {8, 8}}, 16, chunkref.ErrChunkRangeOverlap},
+		{"duplicate", [][2]int64{{0, 8}, {0, 8}, {8, 8}}, 16, chunkref.ErrChunkRangeOverlap},
+		{"gap", [][2]int64{{0, 8}, {10, 6}}, 16, chunkref.ErrChunkRangeGap},
+		{"missing head", [][2]int64{{2, 14}}, 16, chunkref.ErrChunkRangeGap},
+		{"missing tail", [][2]int64{{0, 8}}, 16, chunkref.ErrChunkRangeGap},
+		{"exceeds total size", [][2]int64{{0, 10}, {10, 7}}, 16, chunkref.ErrChunkRangeOutOfBounds},
+		{"negative offset", [][2]int64{{-1, 17}}, 16, chunkref.ErrChunkRangeOutOfBounds},
+		{"negative length", [][2]int64{{0, 16}, {4, -4}}, 16, chunkref.ErrChunkRangeOutOfBounds},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := chunkref.ValidateChunkRanges(newRangedChunks(tc.ranges...), tc.totalSize)
+			if tc.want == nil {
+				assert.NoError(t, err)
+			} else if !errors.Is(err, tc.want) {
+				t.Errorf("ValidateChunkRanges() = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestHasRanges(t *testing.T) {
+	assert.False(t, chunkref.HasRanges(newRangedChunks([2]int64{0, 0}, [2]int64{0, 0})))
+	assert.True(t, chunkref.HasRanges(newRangedChunks([2]int64{0, 0}, [2]int64{0, 8})))
+}
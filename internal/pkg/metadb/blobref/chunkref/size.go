@@ -0,0 +1,43 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkref
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrChunkTooSmall is returned by ValidateChunkSize when a chunk other than
+// the final one is smaller than the minimum chunk size.
+var ErrChunkTooSmall = errors.New("chunk is smaller than the minimum chunk size")
+
+// IsFinalChunk reports whether the chunk with number is the last one of a blob
+// with chunkCount chunks. Any chunk may be the final one if chunkCount is 0,
+// i.e. the number of chunks was not declared.
+func IsFinalChunk(number int32, chunkCount int64) bool {
+	return chunkCount <= 0 || int64(number) >= chunkCount-1
+}
+
+// ValidateChunkSize checks that the chunk with number and size bytes of a blob
+// with chunkCount chunks is at least minSize bytes, unless it is the final
+// chunk (see IsFinalChunk). There is no minimum if minSize is not positive.
+// It returns an error wrapping ErrChunkTooSmall otherwise.
+func ValidateChunkSize(number int32, chunkCount, size, minSize int64) error {
+	if minSize <= 0 || size >= minSize || IsFinalChunk(number, chunkCount) {
+		return nil
+	}
+	return fmt.Errorf("%w: chunk (%v) of %v is %v bytes, but must be at least %v bytes unless it is the final chunk",
+		ErrChunkTooSmall, number, chunkCount, size, minSize)
+}
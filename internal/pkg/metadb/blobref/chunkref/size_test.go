@@ -0,0 +1,66 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chunkref
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsFinalChunk(t *testing.T) {
+	for _, tc := range []struct {
+		number     int32
+		chunkCount int64
+		want       bool
+	}{
+		{0, 1, true},
+		{0, 3, false},
+		{1, 3, false},
+		{2, 3, true},
+		{0, 0, true},
+		{5, 0, true},
+	} {
+		if got := IsFinalChunk(tc.number, tc.chunkCount); got != tc.want {
+			t.Errorf("IsFinalChunk(%v, %v) = %v, want %v", tc.number, tc.chunkCount, got, tc.want)
+		}
+	}
+}
+
+func TestValidateChunkSize(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		number     int32
+		chunkCount int64
+		size       int64
+		minSize    int64
+		wantErr    bool
+	}{
+		{"valid chunk", 0, 3, 100, 100, false},
+		{"undersized non-final chunk", 1, 3, 99, 100, true},
+		{"small final chunk", 2, 3, 1, 100, false},
+		{"empty final chunk", 2, 3, 0, 100, false},
+		{"unknown chunk count", 1, 0, 1, 100, false},
+		{"no minimum", 0, 3, 1, 0, false},
+	} {
+		err := ValidateChunkSize(tc.number, tc.chunkCount, tc.size, tc.minSize)
+		if tc.wantErr {
+			if !errors.Is(err, ErrChunkTooSmall) {
+				t.Errorf("%s: ValidateChunkSize() = %v, want %v", tc.name, err, ErrChunkTooSmall)
+			}
+		} else if err != nil {
+			t.Errorf("%s: ValidateChunkSize() = %v, want nil", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobref
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyGenerator generates keys of new BlobRefs, e.g. for MetaDB.BlobKeyGenerator.
+// NewBlobRef generates random (version 4) UUIDs.
+type KeyGenerator interface {
+	// New returns a new unique key.
+	New() uuid.UUID
+}
+
+// ParseKeyGenerator returns the KeyGenerator named s, either "random" or
+// "uuidv7" (NewUUIDv7Generator). An empty string is "random", for which it
+// returns nil as NewBlobRef already generates random keys.
+func ParseKeyGenerator(s string) (KeyGenerator, error) {
+	switch s {
+	case "", "random":
+		return nil, nil
+	case "uuidv7":
+		return NewUUIDv7Generator(), nil
+	default:
+		return nil, fmt.Errorf("unknown key generator: %q", s)
+	}
+}
+
+// uuidV7Generator generates time-ordered (version 7) UUIDs as defined in RFC 9562.
+// The 12-bit rand_a field is used as a counter so that keys generated within
+// the same millisecond are still ordered.
+type uuidV7Generator struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	rand   io.Reader
+	lastMs int64
+	seq    uint16
+}
+
+// NewUUIDv7Generator returns a KeyGenerator that generates time-ordered UUIDs
+// for better key locality.
+func NewUUIDv7Generator() KeyGenerator {
+	return &uuidV7Generator{now: time.Now, rand: rand.Reader}
+}
+
+// maxV7Seq is the maximum value of the 12-bit counter.
+const maxV7Seq = 0xfff
+
+func (g *uuidV7Generator) New() uuid.UUID {
+	var u uuid.UUID
+	if _, err := io.ReadFull(g.rand, u[6:]); err != nil {
+		// Fall back to a random UUID, losing ordering but not uniqueness.
+		return uuid.New()
+	}
+
+	g.mu.Lock()
+	ms := g.now().UnixMilli()
+	if ms <= g.lastMs {
+		// Keep the order if the clock didn't move or went backwards.
+		ms = g.lastMs
+		if g.seq < maxV7Seq {
+			g.seq++
+		} else {
+			ms++
+			g.seq = 0
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMs = ms
+	seq := g.seq
+	g.mu.Unlock()
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(u[:6], ts[2:])
+	binary.BigEndian.PutUint16(u[6:8], 0x7000|seq)
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return u
+}
+
+// derivedKeyNamespace is the UUID namespace of the keys returned by DeriveKey.
+var derivedKeyNamespace = uuid.MustParse("6f1a3c2e-5b7d-4e8f-9a0b-1c2d3e4f5a6b")
+
+// DeriveKey returns a name-based (version 5) UUID for the blob named name of
+// the record with storeKey and recordKey. The same arguments always derive the
+// same key, so clients can compute the key of a blob before it is created.
+// Each argument is prefixed with its length so that different arguments
+// never collide by concatenation.
+func DeriveKey(storeKey, recordKey, name string) uuid.UUID {
+	var b []byte
+	for _, s := range []string{storeKey, recordKey, name} {
+		b = strconv.AppendInt(b, int64(len(s)), 10)
+		b = append(b, ':')
+		b = append(b, s...)
+	}
+	return uuid.NewSHA1(derivedKeyNamespace, b)
+}
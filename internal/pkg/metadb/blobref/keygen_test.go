@@ -0,0 +1,114 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobref
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+)
+
+func TestKeyGenerator_UUIDv7(t *testing.T) {
+	g := NewUUIDv7Generator()
+
+	prev := g.New()
+	for i := 0; i < 10000; i++ {
+		key := g.New()
+		if key.Version() != 7 {
+			t.Fatalf("Version() = %v, want 7", key.Version())
+		}
+		if key.Variant() != uuid.RFC4122 {
+			t.Fatalf("Variant() = %v, want %v", key.Variant(), uuid.RFC4122)
+		}
+		if bytes.Compare(prev[:], key[:]) >= 0 {
+			t.Fatalf("keys are not increasing: %v, then %v", prev, key)
+		}
+		prev = key
+
+		// The key must survive a datastore round trip.
+		loaded := new(BlobRef)
+		if err := loaded.LoadKey(datastore.NameKey("blob", key.String(), nil)); err != nil {
+			t.Fatalf("LoadKey() failed: %v", err)
+		}
+		if loaded.Key != key {
+			t.Fatalf("LoadKey() = %v, want %v", loaded.Key, key)
+		}
+	}
+}
+
+func TestKeyGenerator_UUIDv7ClockGoesBackwards(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	g := &uuidV7Generator{now: func() time.Time { return now }, rand: rand.Reader}
+
+	first := g.New()
+	now = now.Add(-time.Second)
+	second := g.New()
+	if bytes.Compare(first[:], second[:]) >= 0 {
+		t.Errorf("keys are not increasing: %v, then %v", first, second)
+	}
+
+	// Exhaust the counter within the same millisecond.
+	prev := second
+	for i := 0; i < 2*maxV7Seq; i++ {
+		key := g.New()
+		if bytes.Compare(prev[:], key[:]) >= 0 {
+			t.Fatalf("keys are not increasing: %v, then %v", prev, key)
+		}
+		prev = key
+	}
+}
+
+func TestParseKeyGenerator(t *testing.T) {
+	for _, s := range []string{"", "random"} {
+		if g, err := ParseKeyGenerator(s); err != nil || g != nil {
+			t.Errorf("ParseKeyGenerator(%q) = (%v, %v), want nil", s, g, err)
+		}
+	}
+	g, err := ParseKeyGenerator("uuidv7")
+	if err != nil || g == nil {
+		t.Fatalf("ParseKeyGenerator(\"uuidv7\") = (%v, %v), want a generator", g, err)
+	}
+	if v := g.New().Version(); v != 7 {
+		t.Errorf("ParseKeyGenerator(\"uuidv7\").New() is version %d, want 7", v)
+	}
+	if _, err := ParseKeyGenerator("sequential"); err == nil {
+		t.Error("ParseKeyGenerator(\"sequential\") should fail")
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	key := DeriveKey("store", "record", "save.dat")
+	if got := DeriveKey("store", "record", "save.dat"); got != key {
+		t.Errorf("DeriveKey() = %v, want the same key %v when derived again", got, key)
+	}
+	if key.Version() != 5 {
+		t.Errorf("DeriveKey().Version() = %v, want 5", key.Version())
+	}
+	for _, args := range [][3]string{
+		{"store", "record", "other.dat"},
+		{"store", "other", "save.dat"},
+		{"other", "record", "save.dat"},
+		// Concatenations of the arguments are the same as above.
+		{"storer", "ecord", "save.dat"},
+	} {
+		if got := DeriveKey(args[0], args[1], args[2]); got == key {
+			t.Errorf("DeriveKey(%q) = %v, want a different key", args, got)
+		}
+	}
+}
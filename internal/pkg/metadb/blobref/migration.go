@@ -0,0 +1,82 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobref
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MigrationFunc upgrades b from the schema version it was registered for to
+// the next version, e.g. by filling in a new field for entities saved before
+// it was added. It must be idempotent as an entity may be migrated again if it
+// is read and not saved.
+type MigrationFunc func(b *BlobRef)
+
+var migrations = struct {
+	sync.RWMutex
+	funcs  map[int]MigrationFunc
+	latest int
+}{funcs: make(map[int]MigrationFunc)}
+
+// RegisterMigration registers fn to upgrade BlobRefs from fromVersion to
+// fromVersion + 1. The latest schema version is one above the highest
+// registered fromVersion, and versions without a migration in between are
+// upgraded without changes.
+// It is meant to be called at startup, e.g. in init functions, and panics if
+// fromVersion is negative or already has a migration.
+func RegisterMigration(fromVersion int, fn MigrationFunc) {
+	migrations.Lock()
+	defer migrations.Unlock()
+	if fromVersion < 0 {
+		panic(fmt.Sprintf("blobref: invalid migration version %d", fromVersion))
+	}
+	if fn == nil {
+		panic("blobref: nil migration")
+	}
+	if _, ok := migrations.funcs[fromVersion]; ok {
+		panic(fmt.Sprintf("blobref: migration from version %d is already registered", fromVersion))
+	}
+	migrations.funcs[fromVersion] = fn
+	if fromVersion >= migrations.latest {
+		migrations.latest = fromVersion + 1
+	}
+}
+
+// LatestSchemaVersion returns the schema version that BlobRefs are upgraded
+// to when loaded, and that new BlobRefs are created with.
+func LatestSchemaVersion() int {
+	migrations.RLock()
+	defer migrations.RUnlock()
+	return migrations.latest
+}
+
+// Migrate applies the registered migrations to b in order, from
+// b.SchemaVersion to the latest version. It is called by Load, so that the
+// upgraded entity is written with the latest version when it is saved next.
+// It returns true if b was upgraded. BlobRefs of newer versions are not changed.
+func (b *BlobRef) Migrate() bool {
+	migrations.RLock()
+	defer migrations.RUnlock()
+	if b.SchemaVersion >= migrations.latest {
+		return false
+	}
+	for ; b.SchemaVersion < migrations.latest; b.SchemaVersion++ {
+		if fn := migrations.funcs[b.SchemaVersion]; fn != nil {
+			fn(b)
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobref
+
+import (
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	"github.com/google/go-cmp/cmp"
+)
+
+// resetMigrations removes the registered migrations when the test finishes.
+func resetMigrations(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		migrations.Lock()
+		defer migrations.Unlock()
+		migrations.funcs = make(map[int]MigrationFunc)
+		migrations.latest = 0
+	})
+}
+
+func TestBlobRef_MigrateOnLoad(t *testing.T) {
+	resetMigrations(t)
+	var calls []int
+	RegisterMigration(1, func(b *BlobRef) {
+		calls = append(calls, 1)
+		if b.ContentType == "" {
+			b.ContentType = "application/octet-stream"
+		}
+	})
+	RegisterMigration(0, func(b *BlobRef) {
+		calls = append(calls, 0)
+		if b.OwnerID == "" {
+			b.OwnerID = "legacy"
+		}
+	})
+	if got := LatestSchemaVersion(); got != 2 {
+		t.Errorf("LatestSchemaVersion() = %v, want 2", got)
+	}
+
+	// An entity saved before SchemaVersion was added.
+	ps := []datastore.Property{
+		{Name: "Size", Value: int64(5)},
+		{Name: "StoreKey", Value: "store"},
+		{Name: "RecordKey", Value: "record"},
+	}
+	b := new(BlobRef)
+	if err := b.Load(ps); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if diff := cmp.Diff([]int{0, 1}, calls); diff != "" {
+		t.Errorf("migrations were applied out of order (-want +got):\n%s", diff)
+	}
+	want := &BlobRef{
+		Size:          5,
+		StoreKey:      "store",
+		RecordKey:     "record",
+		OwnerID:       "legacy",
+		ContentType:   "application/octet-stream",
+		SchemaVersion: 2,
+	}
+	if diff := cmp.Diff(want, b); diff != "" {
+		t.Errorf("Load() = (-want +got):\n%s", diff)
+	}
+
+	// The upgraded entity is saved with the latest version and not migrated again.
+	saved, err := b.Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	reloaded := new(BlobRef)
+	if err := reloaded.Load(saved); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if diff := cmp.Diff(want, reloaded); diff != "" {
+		t.Errorf("Load() after Save() = (-want +got):\n%s", diff)
+	}
+	if len(calls) != 2 {
+		t.Errorf("migrations were called %v times, want 2", len(calls))
+	}
+
+	// Migrations are idempotent.
+	again := &BlobRef{OwnerID: "owner"}
+	if !again.Migrate() {
+		t.Error("Migrate() = false, want true for a v0 BlobRef")
+	}
+	if again.Migrate() {
+		t.Error("Migrate() = true, want false for a migrated BlobRef")
+	}
+	if again.OwnerID != "owner" || again.SchemaVersion != 2 {
+		t.Errorf("Migrate() = %+v, want OwnerID = owner, SchemaVersion = 2", again)
+	}
+}
+
+func TestBlobRef_MigrateGap(t *testing.T) {
+	resetMigrations(t)
+	RegisterMigration(2, func(b *BlobRef) { b.CacheControl = "no-store" })
+
+	// Versions 0 and 1 have no migration.
+	b := &BlobRef{SchemaVersion: 1}
+	b.Migrate()
+	if b.SchemaVersion != 3 || b.CacheControl != "no-store" {
+		t.Errorf("Migrate() = %+v, want SchemaVersion = 3, CacheControl = no-store", b)
+	}
+	// BlobRefs of newer versions, e.g. written by a newer server, are kept.
+	newer := &BlobRef{SchemaVersion: 5}
+	if newer.Migrate() || newer.SchemaVersion != 5 {
+		t.Errorf("Migrate() changed a newer BlobRef: %+v", newer)
+	}
+	if got := NewBlobRef(0, "store", "record").SchemaVersion; got != 3 {
+		t.Errorf("NewBlobRef().SchemaVersion = %v, want 3", got)
+	}
+}
+
+func TestRegisterMigration_Panics(t *testing.T) {
+	resetMigrations(t)
+	RegisterMigration(0, func(*BlobRef) {})
+	for name, register := range map[string]func(){
+		"duplicate": func() { RegisterMigration(0, func(*BlobRef) {}) },
+		"negative":  func() { RegisterMigration(-1, func(*BlobRef) {}) },
+		"nil":       func() { RegisterMigration(1, nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterMigration() didn't panic for %v", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
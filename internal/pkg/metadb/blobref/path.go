@@ -0,0 +1,187 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobref
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PathEncoding is how the object path of a BlobRef is built. It is saved
+// with each BlobRef, so changing the encoding of new BlobRefs doesn't move
+// existing objects.
+type PathEncoding int32
+
+const (
+	// PathEncodingKey uses the key as the object path as is, without a hash
+	// prefix. BlobRefs saved before path encodings were introduced have it.
+	PathEncodingKey PathEncoding = iota
+	// PathEncodingEscape prefixes the path with a hash and percent-encodes the
+	// characters of the key segments that are not unreserved in URL paths,
+	// such as '/', spaces, and non-ASCII characters. UUID keys are kept as is.
+	PathEncodingEscape
+	// PathEncodingBase64 prefixes the path with a hash and encodes the key
+	// segments with the URL-safe base64 alphabet without padding, which keeps
+	// object paths ASCII alphanumeric.
+	PathEncodingBase64
+)
+
+// ErrInvalidObjectPath is returned by ParseObjectPath for paths that
+// ObjectPathWithPrefix doesn't return.
+var ErrInvalidObjectPath = errors.New("invalid object path")
+
+// ParsePathEncoding returns the encoding named s, either "key", "escape", or
+// "base64". An empty string is PathEncodingKey.
+func ParsePathEncoding(s string) (PathEncoding, error) {
+	switch s {
+	case "", "key":
+		return PathEncodingKey, nil
+	case "escape":
+		return PathEncodingEscape, nil
+	case "base64":
+		return PathEncodingBase64, nil
+	default:
+		return PathEncodingKey, fmt.Errorf("unknown path encoding: %q", s)
+	}
+}
+
+// objectPathPrefixLen is the number of hex digits of the hash prefix.
+const objectPathPrefixLen = 4
+
+// ObjectPathWithPrefix returns an object path for the key segments that starts
+// with a short hash of them, e.g. "3f2a/397f94f5-f851-4969-8bd8-7828abc473a6",
+// to spread objects with sequential keys across the key space of the backend
+// storage. Each segment is encoded with e, so the path has exactly one level
+// per segment and ParseObjectPath returns the segments. PathEncodingKey is
+// treated as PathEncodingEscape. Segments must not be empty.
+func ObjectPathWithPrefix(e PathEncoding, segments ...string) string {
+	encoded := make([]string, len(segments))
+	for i, s := range segments {
+		encoded[i] = encodePathSegment(e, s)
+	}
+	return objectPathPrefix(segments) + "/" + strings.Join(encoded, "/")
+}
+
+// ParseObjectPath returns the key segments of path returned by
+// ObjectPathWithPrefix with e. It returns ErrInvalidObjectPath if path is malformed,
+// the prefix doesn't match the segments, or the segments are not encoded the
+// way ObjectPathWithPrefix encodes them.
+func ParseObjectPath(e PathEncoding, path string) ([]string, error) {
+	prefix, rest, ok := strings.Cut(path, "/")
+	if !ok || rest == "" {
+		return nil, fmt.Errorf("%w: %q doesn't have key segments", ErrInvalidObjectPath, path)
+	}
+	encoded := strings.Split(rest, "/")
+	segments := make([]string, len(encoded))
+	for i, es := range encoded {
+		s, err := decodePathSegment(e, es)
+		if err != nil {
+			return nil, fmt.Errorf("%w: can't decode segment %q of %q: %v", ErrInvalidObjectPath, es, path, err)
+		}
+		segments[i] = s
+	}
+	if prefix != objectPathPrefix(segments) {
+		return nil, fmt.Errorf("%w: the prefix of %q doesn't match the key segments", ErrInvalidObjectPath, path)
+	}
+	// Reject alternative encodings of the segments, such as "%61" for "a",
+	// so that each key has exactly one path.
+	if ObjectPathWithPrefix(e, segments...) != path {
+		return nil, fmt.Errorf("%w: %q is not the canonical path of the key segments", ErrInvalidObjectPath, path)
+	}
+	return segments, nil
+}
+
+// objectPathPrefix returns the hash prefix of the key segments. The hash
+// doesn't depend on the encoding. A single segment that is a UUID in the
+// canonical form is hashed as the 16 bytes of the UUID, as the prefixes of
+// UUID keys have always been. Other segments are hashed with their lengths
+// so that different segments never hash the same input by concatenation.
+func objectPathPrefix(segments []string) string {
+	var b []byte
+	if key, err := uuid.Parse(segments[0]); len(segments) == 1 && err == nil && key.String() == segments[0] {
+		b = key[:]
+	} else {
+		for _, s := range segments {
+			b = strconv.AppendInt(b, int64(len(s)), 10)
+			b = append(b, ':')
+			b = append(b, s...)
+		}
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])[:objectPathPrefixLen]
+}
+
+// storePathPrefix starts the object paths returned by StoreObjectPath, so that
+// they can't be confused with other paths, which start with a hash prefix or
+// a UUID.
+const storePathPrefix = "stores/"
+
+// StoreObjectPath returns objectPath in the store segment of storeKey, e.g.
+// "stores/my-store/3f2a/397f94f5-f851-4969-8bd8-7828abc473a6", which
+// ParseStoreObjectPath returns storeKey of. storeKey is encoded with
+// PathEncodingEscape, so keys with slashes or other special characters are
+// a single segment regardless of the encoding of objectPath.
+func StoreObjectPath(storeKey, objectPath string) string {
+	return storePathPrefix + encodePathSegment(PathEncodingEscape, storeKey) + "/" + objectPath
+}
+
+// ParseStoreObjectPath returns the store key of path returned by
+// StoreObjectPath, or false if path doesn't have a store segment.
+func ParseStoreObjectPath(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, storePathPrefix)
+	if !ok {
+		return "", false
+	}
+	segment, _, ok := strings.Cut(rest, "/")
+	if !ok || segment == "" {
+		return "", false
+	}
+	storeKey, err := decodePathSegment(PathEncodingEscape, segment)
+	if err != nil || encodePathSegment(PathEncodingEscape, storeKey) != segment {
+		return "", false
+	}
+	return storeKey, true
+}
+
+func encodePathSegment(e PathEncoding, s string) string {
+	if e == PathEncodingBase64 {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	// PathEscape keeps dots, so escape the segments that filesystems treat
+	// as the current or parent directory.
+	if strings.Trim(s, ".") == "" {
+		return strings.Repeat("%2E", len(s))
+	}
+	return url.PathEscape(s)
+}
+
+func decodePathSegment(e PathEncoding, s string) (string, error) {
+	if s == "" {
+		return "", errors.New("the segment is empty")
+	}
+	if e == PathEncodingBase64 {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		return string(b), err
+	}
+	return url.PathUnescape(s)
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobref
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var pathTestKeys = [][]string{
+	{"a/b"},
+	{"store", "record/with/slashes", "name"},
+	{"with space", "tab\ttab"},
+	{"日本語", "セーブ"},
+	{"emoji 🎮"},
+	{".", "..", "..."},
+	{"100%", "%2F", "a+b?c#d"},
+	{uuid.MustParse("397F94F5-F851-4969-8BD8-7828ABC473A6").String()},
+}
+
+func TestObjectPathWithPrefix_RoundTrip(t *testing.T) {
+	for _, e := range []PathEncoding{PathEncodingEscape, PathEncodingBase64} {
+		for _, segments := range pathTestKeys {
+			path := ObjectPathWithPrefix(e, segments...)
+			// One level for the prefix plus one per segment.
+			if got, want := strings.Count(path, "/"), len(segments); got != want {
+				t.Errorf("ObjectPathWithPrefix(%q) = %q, want %d slashes in encoding %v", segments, path, want, e)
+			}
+			for _, level := range strings.Split(path, "/") {
+				if level == "." || level == ".." {
+					t.Errorf("ObjectPathWithPrefix(%q) = %q, want no dot levels in encoding %v", segments, path, e)
+				}
+			}
+			if again := ObjectPathWithPrefix(e, segments...); again != path {
+				t.Errorf("ObjectPathWithPrefix(%q) is not stable: %q, %q", segments, path, again)
+			}
+			got, err := ParseObjectPath(e, path)
+			if err != nil {
+				t.Errorf("ParseObjectPath(%q) failed in encoding %v: %v", path, e, err)
+				continue
+			}
+			if strings.Join(got, "\x00") != strings.Join(segments, "\x00") {
+				t.Errorf("ParseObjectPath(%q) = %q, want %q in encoding %v", path, got, segments, e)
+			}
+		}
+	}
+}
+
+func TestObjectPathWithPrefix_EscapeKeepsUUIDs(t *testing.T) {
+	key := uuid.New().String()
+	if path := ObjectPathWithPrefix(PathEncodingEscape, key); !strings.HasSuffix(path, "/"+key) {
+		t.Errorf("ObjectPathWithPrefix(%q) = %q, want the key as is", key, path)
+	}
+	if path := ObjectPathWithPrefix(PathEncodingEscape, "a/b"); !strings.HasSuffix(path, "/a%2Fb") {
+		t.Errorf("ObjectPathWithPrefix(\"a/b\") = %q, want the slash escaped", path)
+	}
+}
+
+func TestParseObjectPath_Invalid(t *testing.T) {
+	valid := ObjectPathWithPrefix(PathEncodingEscape, "a", "b")
+	prefix, _, _ := strings.Cut(valid, "/")
+	for _, path := range []string{
+		"",
+		"nosegments",
+		prefix + "/",
+		"0000/a/b",
+		prefix + "/a//b",
+		// A malformed escape.
+		objectPathPrefix([]string{"%zz"}) + "/%zz",
+		// An alternative encoding of "a".
+		objectPathPrefix([]string{"a"}) + "/%61",
+	} {
+		if _, err := ParseObjectPath(PathEncodingEscape, path); !errors.Is(err, ErrInvalidObjectPath) {
+			t.Errorf("ParseObjectPath(%q) = %v, want %v", path, err, ErrInvalidObjectPath)
+		}
+	}
+}
+
+func TestStoreObjectPath_RoundTrip(t *testing.T) {
+	for _, storeKey := range []string{"store", "a/b", "with space", "日本語", ".."} {
+		path := StoreObjectPath(storeKey, "3f2a/object")
+		if got := strings.Count(path, "/"); got != 3 {
+			t.Errorf("StoreObjectPath(%q) = %q, want 4 levels", storeKey, path)
+		}
+		if got, ok := ParseStoreObjectPath(path); !ok || got != storeKey {
+			t.Errorf("ParseStoreObjectPath(%q) = (%q, %v), want %q", path, got, ok, storeKey)
+		}
+	}
+	for _, path := range []string{"", "3f2a/object", "stores/", "stores/store", "stores//object", "stores/%zz/object", "stores/%61/object"} {
+		if got, ok := ParseStoreObjectPath(path); ok {
+			t.Errorf("ParseStoreObjectPath(%q) = %q, want no store segment", path, got)
+		}
+	}
+}
+
+func TestParsePathEncoding(t *testing.T) {
+	for s, want := range map[string]PathEncoding{"": PathEncodingKey, "key": PathEncodingKey, "escape": PathEncodingEscape, "base64": PathEncodingBase64} {
+		if got, err := ParsePathEncoding(s); err != nil || got != want {
+			t.Errorf("ParsePathEncoding(%q) = (%v, %v), want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParsePathEncoding("hex"); err == nil {
+		t.Error("ParsePathEncoding(\"hex\") should fail")
+	}
+}
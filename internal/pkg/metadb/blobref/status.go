@@ -14,7 +14,10 @@
 
 package blobref
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Status represents a current blob status.
 //
@@ -32,6 +35,9 @@ import "errors"
 //	   delete the record |          -------[StatusPendingDeletion]
 //	                     v                  /
 //	[Delete the blob entity] <-------------/   Garbage collection
+//
+// A Ready blob can also go back to StatusInitializing to be uploaded again
+// with the same key (see Reinitialize).
 type Status int16
 
 const (
@@ -54,6 +60,7 @@ const (
 var (
 	ErrReadyNotInitializing    = errors.New("Ready was called when Status is not Initializing")
 	ErrMarkForDeletionNotReady = errors.New("MarkForDeletion was called when Status is not either Initializing or Ready")
+	ErrReinitializeNotReady    = errors.New("Reinitialize was called when Status is not Ready")
 )
 
 // Ready changes Status to StatusReady.
@@ -76,8 +83,56 @@ func (s *Status) MarkForDeletion() error {
 	return nil
 }
 
+// Reinitialize changes Status back to StatusInitializing so that the blob
+// can be uploaded again.
+// It returns an error if the current Status is not StatusReady.
+func (s *Status) Reinitialize() error {
+	if *s != StatusReady {
+		return ErrReinitializeNotReady
+	}
+	*s = StatusInitializing
+	return nil
+}
+
 // Fail marks the Status as StatusError.
 // Any state can transition to StatusError.
 func (s *Status) Fail() {
 	*s = StatusError
 }
+
+// AllowedTransitions maps each Status to the statuses it can legally move to,
+// mirroring Ready, MarkForDeletion, Reinitialize, and Fail.
+var AllowedTransitions = map[Status][]Status{
+	StatusUnknown:         {StatusError},
+	StatusInitializing:    {StatusReady, StatusPendingDeletion, StatusError},
+	StatusReady:           {StatusInitializing, StatusPendingDeletion, StatusError},
+	StatusPendingDeletion: {StatusError},
+	StatusError:           {StatusError},
+}
+
+// ErrIllegalTransition is returned when a Status cannot move to the target Status.
+var ErrIllegalTransition = errors.New("illegal blob status transition")
+
+// CanTransition reports whether from can legally move to to according to
+// AllowedTransitions.
+func CanTransition(from, to Status) bool {
+	for _, s := range AllowedTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransitions checks that every BlobRef in refs can move to target
+// before any of them is written. It returns an error wrapping
+// ErrIllegalTransition for each ref that can't, or nil if all of them can.
+func ValidateTransitions(refs []*BlobRef, target Status) []error {
+	var errs []error
+	for _, b := range refs {
+		if !CanTransition(b.Status, target) {
+			errs = append(errs, fmt.Errorf("blob %v: %w from %d to %d", b.Key, ErrIllegalTransition, b.Status, target))
+		}
+	}
+	return errs
+}
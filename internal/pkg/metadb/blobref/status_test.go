@@ -15,6 +15,8 @@
 package blobref
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -108,6 +110,51 @@ func TestStatus_MarkForDeletion(t *testing.T) {
 	}
 }
 
+func TestStatus_Reinitialize(t *testing.T) {
+	testCases := []struct {
+		name    string
+		start   Status
+		want    Status
+		wantErr error
+	}{
+		{
+			name:    "StatusInitializing",
+			start:   StatusInitializing,
+			want:    StatusInitializing,
+			wantErr: ErrReinitializeNotReady,
+		},
+		{
+			name:    "StatusPendingDeletion",
+			start:   StatusPendingDeletion,
+			want:    StatusPendingDeletion,
+			wantErr: ErrReinitializeNotReady,
+		},
+		{
+			name:    "StatusReady",
+			start:   StatusReady,
+			want:    StatusInitializing,
+			wantErr: nil,
+		},
+		{
+			name:    "StatusError",
+			start:   StatusError,
+			want:    StatusError,
+			wantErr: ErrReinitializeNotReady,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.start
+			if err := got.Reinitialize(); err != tc.wantErr {
+				t.Errorf("Reinitialize() returned %v, want = %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Reinitialize() changed status to %v, want = %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestStatus_Fail(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -129,3 +176,56 @@ func TestStatus_Fail(t *testing.T) {
 		})
 	}
 }
+
+func TestCanTransition_MatchesMethods(t *testing.T) {
+	statuses := []Status{StatusUnknown, StatusInitializing, StatusReady, StatusPendingDeletion, StatusError}
+	methods := map[Status]func(*Status) error{
+		StatusReady:           (*Status).Ready,
+		StatusPendingDeletion: (*Status).MarkForDeletion,
+		StatusInitializing:    (*Status).Reinitialize,
+		StatusError:           func(s *Status) error { s.Fail(); return nil },
+	}
+	for _, from := range statuses {
+		for to, method := range methods {
+			s := from
+			want := method(&s) == nil
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%d, %d) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestValidateTransitions(t *testing.T) {
+	newRef := func(s Status) *BlobRef {
+		b := NewBlobRef(0, "store", "record")
+		b.Status = s
+		return b
+	}
+	refs := []*BlobRef{
+		newRef(StatusInitializing),
+		newRef(StatusReady),
+		newRef(StatusPendingDeletion),
+		newRef(StatusError),
+	}
+
+	errs := ValidateTransitions(refs, StatusPendingDeletion)
+	if len(errs) != 2 {
+		t.Fatalf("ValidateTransitions() returned %d errors, want 2: %v", len(errs), errs)
+	}
+	for i, b := range []*BlobRef{refs[2], refs[3]} {
+		if !errors.Is(errs[i], ErrIllegalTransition) {
+			t.Errorf("ValidateTransitions()[%d] = %v, want ErrIllegalTransition", i, errs[i])
+		}
+		if !strings.Contains(errs[i].Error(), b.Key.String()) {
+			t.Errorf("ValidateTransitions()[%d] = %v, want the blob key %v", i, errs[i], b.Key)
+		}
+	}
+
+	if errs := ValidateTransitions(refs, StatusError); errs != nil {
+		t.Errorf("ValidateTransitions() to StatusError = %v, want nil", errs)
+	}
+	if errs := ValidateTransitions(nil, StatusReady); errs != nil {
+		t.Errorf("ValidateTransitions(nil) = %v, want nil", errs)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QueryRecordsByCategory returns up to pageSize records in the store whose
+// Category is category, and a cursor to pass to the next call for the next page.
+// The returned cursor is empty after the last page, although the page after a
+// full page may be empty. An empty cursor starts from the beginning.
+// All matching records are returned if pageSize is 0.
+// An empty category matches records without a category. Records saved before
+// Category was added don't have the property and are not returned until they
+// are updated.
+// Records are sorted by orders in priority order if given.
+// The cursor must be used with the same store, category, and orders, otherwise
+// ErrCursorMismatch is returned.
+func (m *MetaDB) QueryRecordsByCategory(ctx context.Context, storeKey, category string, pageSize int, cursor string, orders ...*pb.SortOrder) ([]*record.Record, string, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.QueryRecordsByCategory")
+	defer span.End()
+
+	if pageSize < 0 {
+		return nil, "", status.Errorf(codes.InvalidArgument, "page size must not be negative, got %d", pageSize)
+	}
+	query, err := m.orderRecordQuery(m.newQuery(recordKind).Ancestor(m.createStoreKey(storeKey)).Filter("Category =", category), orders)
+	if err != nil {
+		return nil, "", err
+	}
+	if pageSize > 0 {
+		query = query.Limit(pageSize)
+	}
+	fp := m.cursorFingerprint(append([]string{"QueryRecordsByCategory", storeKey, category}, sortOrderStrings(orders)...)...)
+	if cursor != "" {
+		c, err := m.decodeCursor(cursor, fp)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Start(c)
+	}
+
+	var records []*record.Record
+	iter := m.client.Run(ctx, query)
+	for {
+		r := new(record.Record)
+		_, err := iter.Next(r)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", datastoreErrToGRPCStatus(err)
+		}
+		records = append(records, r)
+	}
+	if pageSize == 0 || len(records) < pageSize {
+		return records, "", nil
+	}
+	next, err := iter.Cursor()
+	if err != nil {
+		return nil, "", datastoreErrToGRPCStatus(err)
+	}
+	return records, m.encodeCursor(next, fp), nil
+}
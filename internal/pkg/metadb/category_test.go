@@ -0,0 +1,131 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setupCategoryRecords creates a store with a record for each of categories
+// and returns the store key and the record keys by category.
+func setupCategoryRecords(ctx context.Context, t *testing.T, metaDB *m.MetaDB, categories ...string) (string, map[string][]string) {
+	t.Helper()
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+	keys := make(map[string][]string)
+	for _, c := range categories {
+		r := setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{
+			Key: newRecordKey(), Category: c, Properties: make(record.PropertyMap),
+		})
+		keys[c] = append(keys[c], r.Key)
+	}
+	return st.Key, keys
+}
+
+func recordKeys(records []*record.Record) []string {
+	var keys []string
+	for _, r := range records {
+		keys = append(keys, r.Key)
+	}
+	return keys
+}
+
+func TestMetaDB_QueryRecordsByCategory(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, keys := setupCategoryRecords(ctx, t, metaDB, "weapons", "armor", "weapons", "")
+
+	got, cursor, err := metaDB.QueryRecordsByCategory(ctx, storeKey, "weapons", 0, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, keys["weapons"], recordKeys(got))
+	assert.Empty(t, cursor)
+	for _, r := range got {
+		assert.Equal(t, "weapons", r.Category)
+		assert.Equal(t, storeKey, r.StoreKey)
+	}
+
+	got, _, err = metaDB.QueryRecordsByCategory(ctx, storeKey, "potions", 0, "")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestMetaDB_QueryRecordsByCategoryEmpty(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, keys := setupCategoryRecords(ctx, t, metaDB, "", "armor", "")
+
+	got, _, err := metaDB.QueryRecordsByCategory(ctx, storeKey, "", 0, "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, keys[""], recordKeys(got))
+}
+
+func TestMetaDB_QueryRecordsByCategoryPagination(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, keys := setupCategoryRecords(ctx, t, metaDB, "a", "a", "a", "a", "a", "b")
+
+	var all []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 4, "too many pages")
+		got, next, err := metaDB.QueryRecordsByCategory(ctx, storeKey, "a", 2, cursor)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(got), 2)
+		all = append(all, recordKeys(got)...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.ElementsMatch(t, keys["a"], all)
+
+	_, _, err := metaDB.QueryRecordsByCategory(ctx, storeKey, "a", 2, "invalid cursor")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, _, err = metaDB.QueryRecordsByCategory(ctx, storeKey, "a", -1, "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMetaDB_QueryRecordsByCategoryCursorMismatch(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, _ := setupCategoryRecords(ctx, t, metaDB, "a", "a", "a")
+	otherStoreKey, _ := setupCategoryRecords(ctx, t, metaDB, "a", "a", "a")
+
+	_, cursor, err := metaDB.QueryRecordsByCategory(ctx, storeKey, "a", 1, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, cursor)
+
+	// The cursor continues the same query with a different page size.
+	got, _, err := metaDB.QueryRecordsByCategory(ctx, storeKey, "a", 2, cursor)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	_, _, err = metaDB.QueryRecordsByCategory(ctx, otherStoreKey, "a", 1, cursor)
+	assert.Equal(t, m.ErrCursorMismatch, err)
+	_, _, err = metaDB.QueryRecordsByCategory(ctx, storeKey, "b", 1, cursor)
+	assert.Equal(t, m.ErrCursorMismatch, err)
+	_, _, err = metaDB.QueryRecordsByCategory(ctx, storeKey, "a", 1, cursor,
+		&pb.SortOrder{Property: pb.SortOrder_CREATED_AT})
+	assert.Equal(t, m.ErrCursorMismatch, err)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checksums
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+)
+
+// HashAlgorithm is the hash function of content hashes, which identify blob
+// content for deduplication. It is saved with the hash values so that hashes
+// of different algorithms are never compared.
+type HashAlgorithm string
+
+const (
+	// HashAlgorithmMD5 is MD5. It is the default for compatibility with
+	// clients that look up blobs by MD5, but is not collision resistant.
+	HashAlgorithmMD5 HashAlgorithm = "md5"
+	// HashAlgorithmSHA256 is SHA-256.
+	HashAlgorithmSHA256 HashAlgorithm = "sha256"
+)
+
+// ParseHashAlgorithm returns the algorithm named s, either "md5" or "sha256".
+// An empty string is HashAlgorithmMD5.
+func ParseHashAlgorithm(s string) (HashAlgorithm, error) {
+	switch HashAlgorithm(s) {
+	case "", HashAlgorithmMD5:
+		return HashAlgorithmMD5, nil
+	case HashAlgorithmSHA256:
+		return HashAlgorithmSHA256, nil
+	default:
+		return HashAlgorithmMD5, fmt.Errorf("unknown content hash algorithm: %q", s)
+	}
+}
+
+// New returns a new hash.Hash of the algorithm, or nil if the algorithm is unknown.
+func (a HashAlgorithm) New() hash.Hash {
+	switch a {
+	case HashAlgorithmMD5:
+		return md5.New()
+	case HashAlgorithmSHA256:
+		return sha256.New()
+	}
+	return nil
+}
+
+// Size returns the byte length of hash values of the algorithm, or 0 if the
+// algorithm is unknown.
+func (a HashAlgorithm) Size() int {
+	switch a {
+	case HashAlgorithmMD5:
+		return md5.Size
+	case HashAlgorithmSHA256:
+		return sha256.Size
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checksums_test
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHashAlgorithm(t *testing.T) {
+	for s, want := range map[string]checksums.HashAlgorithm{
+		"":       checksums.HashAlgorithmMD5,
+		"md5":    checksums.HashAlgorithmMD5,
+		"sha256": checksums.HashAlgorithmSHA256,
+	} {
+		got, err := checksums.ParseHashAlgorithm(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, want, got, s)
+		}
+	}
+	_, err := checksums.ParseHashAlgorithm("sha1")
+	assert.Error(t, err)
+
+	assert.Equal(t, md5.Size, checksums.HashAlgorithmMD5.Size())
+	assert.Equal(t, sha256.Size, checksums.HashAlgorithmSHA256.Size())
+	assert.Zero(t, checksums.HashAlgorithm("sha1").Size())
+}
+
+func TestDigest_ContentHash(t *testing.T) {
+	const testString = "Lorem ipsum dolor sit amet, consectetur adipiscing elit"
+
+	d := checksums.NewDigest()
+	d.Write([]byte(testString))
+	algorithm, hash := d.ContentHash()
+	assert.Equal(t, checksums.HashAlgorithmMD5, algorithm)
+	assert.Equal(t, d.Checksums().MD5, hash)
+
+	d = checksums.NewDigestWithContentHash(checksums.HashAlgorithmSHA256)
+	d.Write([]byte(testString))
+	algorithm, hash = d.ContentHash()
+	assert.Equal(t, checksums.HashAlgorithmSHA256, algorithm)
+	want := sha256.Sum256([]byte(testString))
+	assert.Equal(t, want[:], hash)
+	// MD5 is still calculated for the checksums.
+	wantMD5 := md5.Sum([]byte(testString))
+	assert.Equal(t, wantMD5[:], d.Checksums().MD5)
+
+	d.Reset()
+	_, hash = d.ContentHash()
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, empty[:], hash)
+
+	// Unknown algorithms fall back to MD5.
+	d = checksums.NewDigestWithContentHash("sha1")
+	algorithm, _ = d.ContentHash()
+	assert.Equal(t, checksums.HashAlgorithmMD5, algorithm)
+}
@@ -26,22 +26,39 @@ var crc32cTable *crc32.Table
 var tableInitOnce = new(sync.Once)
 
 // Digest calculates MD5 and CRC32C values as you call Write and
-// returns the values as a Checksums variable.
+// returns the values as a Checksums variable. It also calculates a content
+// hash with the algorithm passed to NewDigestWithContentHash, or MD5.
 type Digest struct {
 	md5    hash.Hash
 	crc32c hash.Hash32
+	// content is nil if the content hash algorithm is MD5, in which case
+	// the MD5 hash is reused.
+	content   hash.Hash
+	algorithm HashAlgorithm
 }
 
 // Assert that Digest implements io.Writer.
 var _ io.Writer = new(Digest)
 
-// NewDigest creates a new instance of Digest.
+// NewDigest creates a new instance of Digest whose content hash is MD5.
 func NewDigest() *Digest {
+	return NewDigestWithContentHash(HashAlgorithmMD5)
+}
+
+// NewDigestWithContentHash creates a new instance of Digest whose content hash
+// is calculated with algorithm. MD5 is used if algorithm is empty or unknown.
+func NewDigestWithContentHash(algorithm HashAlgorithm) *Digest {
 	tableInitOnce.Do(func() { crc32cTable = crc32.MakeTable(crc32.Castagnoli) })
-	return &Digest{
-		md5:    md5.New(),
-		crc32c: crc32.New(crc32cTable),
+	d := &Digest{
+		md5:       md5.New(),
+		crc32c:    crc32.New(crc32cTable),
+		algorithm: HashAlgorithmMD5,
 	}
+	if h := algorithm.New(); h != nil && algorithm != HashAlgorithmMD5 {
+		d.content = h
+		d.algorithm = algorithm
+	}
+	return d
 }
 
 // Write adds more data to the running hashes.
@@ -51,6 +68,9 @@ func (d *Digest) Write(p []byte) (int, error) {
 	// https://pkg.go.dev/hash#Hash
 	d.crc32c.Write(p)
 	d.md5.Write(p)
+	if d.content != nil {
+		d.content.Write(p)
+	}
 	return len(p), nil
 }
 
@@ -58,6 +78,9 @@ func (d *Digest) Write(p []byte) (int, error) {
 func (d *Digest) Reset() {
 	d.md5.Reset()
 	d.crc32c.Reset()
+	if d.content != nil {
+		d.content.Reset()
+	}
 }
 
 // Checksums returns a new Checksums variable with the calculated hash values.
@@ -68,3 +91,11 @@ func (d *Digest) Checksums() Checksums {
 		HasCRC32C: true,
 	}
 }
+
+// ContentHash returns the algorithm and the value of the content hash.
+func (d *Digest) ContentHash() (HashAlgorithm, []byte) {
+	if d.content == nil {
+		return d.algorithm, d.md5.Sum(nil)
+	}
+	return d.algorithm, d.content.Sum(nil)
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/api/iterator"
+)
+
+// DefaultCompactionBatchSize is the number of records passed to each call of
+// the CompactColdRecords callback unless specified otherwise.
+const DefaultCompactionBatchSize = 100
+
+// CompactionOptions configures CompactColdRecordsWithOptions.
+type CompactionOptions struct {
+	// BatchSize is the maximum number of records in a batch.
+	// DefaultCompactionBatchSize is used if it is not positive.
+	BatchSize int
+	// Cursor resumes a previous run from CompactionError.Cursor or a cursor
+	// passed to Checkpoint. An empty cursor starts from the beginning of the store.
+	Cursor string
+	// Checkpoint, if set, is called with the cursor of the next batch each time
+	// fn succeeds on a full batch, so that the progress can be persisted and
+	// resumed after a restart.
+	Checkpoint func(cursor string) error
+}
+
+// CompactionError is returned by CompactColdRecords when the callback, the
+// checkpoint or reading records fails, including when ctx is done.
+// Cursor points to the beginning of the first unprocessed batch and can be
+// passed to CompactionOptions to resume.
+type CompactionError struct {
+	Cursor string
+	Err    error
+}
+
+func (e *CompactionError) Error() string {
+	return fmt.Sprintf("compaction stopped: %v", e.Err)
+}
+
+func (e *CompactionError) Unwrap() error {
+	return e.Err
+}
+
+// CompactColdRecords is an alias to CompactColdRecordsWithOptions with the default options.
+func (m *MetaDB) CompactColdRecords(ctx context.Context, storeKey string, coldBefore time.Time, fn func([]*record.Record) error) error {
+	return m.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore, CompactionOptions{}, fn)
+}
+
+// CompactColdRecordsWithOptions pages through records in the store that have not
+// been updated since coldBefore and calls fn with batches of them, e.g. to archive
+// the records. It doesn't modify records by itself.
+// Records are queried by Timestamps.UpdatedAt in ascending order, so records that were
+// saved before Timestamps.UpdatedAt was indexed are not returned until they are updated.
+// Cursors are bound to coldBefore and can't resume a run with a different coldBefore.
+// If fn returns an error, or the scan fails or times out, CompactColdRecordsWithOptions
+// stops and returns a *CompactionError that has the cursor to retry the first
+// unprocessed batch.
+func (m *MetaDB) CompactColdRecordsWithOptions(ctx context.Context, storeKey string, coldBefore time.Time,
+	opts CompactionOptions, fn func([]*record.Record) error) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.CompactColdRecords")
+	defer span.End()
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultCompactionBatchSize
+	}
+	query := m.newQuery(recordKind).Ancestor(m.createStoreKey(storeKey)).
+		Filter("Timestamps.UpdatedAt <", coldBefore).Order("Timestamps.UpdatedAt")
+	fp := m.cursorFingerprint("CompactColdRecords", storeKey, coldBefore.UTC().Format(time.RFC3339Nano))
+	if opts.Cursor != "" {
+		cursor, err := m.decodeCursor(opts.Cursor, fp)
+		if err != nil {
+			return err
+		}
+		query = query.Start(cursor)
+	}
+
+	iter := m.client.Run(ctx, query)
+	batchStart := opts.Cursor
+	batch := make([]*record.Record, 0, batchSize)
+	for {
+		r := new(record.Record)
+		_, err := iter.Next(r)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return &CompactionError{Cursor: batchStart, Err: datastoreErrToGRPCStatus(err)}
+		}
+		batch = append(batch, r)
+		if len(batch) < batchSize {
+			continue
+		}
+		if err := fn(batch); err != nil {
+			return &CompactionError{Cursor: batchStart, Err: err}
+		}
+		cursor, err := iter.Cursor()
+		if err != nil {
+			return &CompactionError{Cursor: batchStart, Err: datastoreErrToGRPCStatus(err)}
+		}
+		batchStart = m.encodeCursor(cursor, fp)
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint(batchStart); err != nil {
+				return &CompactionError{Cursor: batchStart, Err: err}
+			}
+		}
+		batch = make([]*record.Record, 0, batchSize)
+	}
+	if len(batch) > 0 {
+		if err := fn(batch); err != nil {
+			return &CompactionError{Cursor: batchStart, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupColdRecords creates cold records, then warm records updated after the
+// returned coldBefore time. Returns the sorted keys of the cold records.
+func setupColdRecords(ctx context.Context, t *testing.T, metaDB *m.MetaDB, cold, warm int) (string, time.Time, []string) {
+	t.Helper()
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+	var coldKeys []string
+	for i := 0; i < cold; i++ {
+		r := setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+		coldKeys = append(coldKeys, r.Key)
+	}
+	time.Sleep(10 * time.Millisecond)
+	coldBefore := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < warm; i++ {
+		setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	}
+	sort.Strings(coldKeys)
+	return st.Key, coldBefore, coldKeys
+}
+
+func batchKeys(batch []*record.Record) []string {
+	keys := make([]string, 0, len(batch))
+	for _, r := range batch {
+		keys = append(keys, r.Key)
+	}
+	return keys
+}
+
+func TestMetaDB_CompactColdRecords(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, coldBefore, coldKeys := setupColdRecords(ctx, t, metaDB, 5, 3)
+
+	var batches [][]string
+	err := metaDB.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore, m.CompactionOptions{BatchSize: 2},
+		func(batch []*record.Record) error {
+			batches = append(batches, batchKeys(batch))
+			return nil
+		})
+	require.NoError(t, err)
+
+	var got []string
+	for _, b := range batches {
+		assert.LessOrEqual(t, len(b), 2)
+		got = append(got, b...)
+	}
+	sort.Strings(got)
+	assert.Equal(t, coldKeys, got)
+	assert.Len(t, batches, 3)
+}
+
+func TestMetaDB_CompactColdRecordsResume(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, coldBefore, coldKeys := setupColdRecords(ctx, t, metaDB, 5, 2)
+
+	// Fail the second batch.
+	wantErr := errors.New("archive failed")
+	var processed []string
+	calls := 0
+	err := metaDB.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore, m.CompactionOptions{BatchSize: 2},
+		func(batch []*record.Record) error {
+			calls++
+			if calls == 2 {
+				return wantErr
+			}
+			processed = append(processed, batchKeys(batch)...)
+			return nil
+		})
+	var compactionErr *m.CompactionError
+	require.ErrorAs(t, err, &compactionErr)
+	assert.ErrorIs(t, err, wantErr)
+	assert.NotEmpty(t, compactionErr.Cursor)
+	assert.Len(t, processed, 2)
+
+	// Resuming should start from the failed batch and not repeat the processed one.
+	err = metaDB.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore,
+		m.CompactionOptions{BatchSize: 2, Cursor: compactionErr.Cursor},
+		func(batch []*record.Record) error {
+			processed = append(processed, batchKeys(batch)...)
+			return nil
+		})
+	require.NoError(t, err)
+	sort.Strings(processed)
+	assert.Equal(t, coldKeys, processed)
+}
+
+func TestMetaDB_CompactColdRecordsDoesNotModify(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, coldBefore, coldKeys := setupColdRecords(ctx, t, metaDB, 1, 0)
+
+	before, err := metaDB.GetRecord(ctx, storeKey, coldKeys[0])
+	require.NoError(t, err)
+	err = metaDB.CompactColdRecords(ctx, storeKey, coldBefore, func(batch []*record.Record) error {
+		for _, r := range batch {
+			r.OpaqueString = "modified"
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	after, err := metaDB.GetRecord(ctx, storeKey, coldKeys[0])
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
+
+func TestMetaDB_CompactColdRecordsCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, coldBefore, coldKeys := setupColdRecords(ctx, t, metaDB, 5, 0)
+
+	// Stop after the first batch as if the process was restarted.
+	var checkpoint string
+	var processed []string
+	stop := errors.New("stopped")
+	err := metaDB.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore,
+		m.CompactionOptions{
+			BatchSize: 2,
+			Checkpoint: func(cursor string) error {
+				checkpoint = cursor
+				return stop
+			},
+		},
+		func(batch []*record.Record) error {
+			processed = append(processed, batchKeys(batch)...)
+			return nil
+		})
+	var compactionErr *m.CompactionError
+	require.ErrorAs(t, err, &compactionErr)
+	assert.ErrorIs(t, err, stop)
+	require.NotEmpty(t, checkpoint)
+	assert.Equal(t, checkpoint, compactionErr.Cursor)
+	assert.Len(t, processed, 2)
+
+	err = metaDB.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore,
+		m.CompactionOptions{BatchSize: 2, Cursor: checkpoint},
+		func(batch []*record.Record) error {
+			processed = append(processed, batchKeys(batch)...)
+			return nil
+		})
+	require.NoError(t, err)
+	sort.Strings(processed)
+	assert.Equal(t, coldKeys, processed)
+}
+
+func TestMetaDB_CompactColdRecordsCursorBoundToColdBefore(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, coldBefore, _ := setupColdRecords(ctx, t, metaDB, 3, 0)
+
+	// Fail the second batch to get a cursor.
+	stop := errors.New("stopped")
+	calls := 0
+	err := metaDB.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore, m.CompactionOptions{BatchSize: 2},
+		func([]*record.Record) error {
+			calls++
+			if calls == 2 {
+				return stop
+			}
+			return nil
+		})
+	var compactionErr *m.CompactionError
+	require.ErrorAs(t, err, &compactionErr)
+	require.NotEmpty(t, compactionErr.Cursor)
+
+	err = metaDB.CompactColdRecordsWithOptions(ctx, storeKey, coldBefore.Add(time.Second),
+		m.CompactionOptions{BatchSize: 2, Cursor: compactionErr.Cursor},
+		func([]*record.Record) error { return nil })
+	assert.ErrorIs(t, err, m.ErrCursorMismatch)
+}
+
+func TestMetaDB_CompactColdRecordsCanceled(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, coldBefore, _ := setupColdRecords(ctx, t, metaDB, 1, 0)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	err := metaDB.CompactColdRecords(canceled, storeKey, coldBefore, func([]*record.Record) error {
+		return nil
+	})
+	var compactionErr *m.CompactionError
+	assert.ErrorAs(t, err, &compactionErr)
+}
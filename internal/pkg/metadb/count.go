@@ -0,0 +1,72 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CountRecords returns the number of records in the store with storeKey, or
+// all stores if empty, that match filter. It uses a Datastore count
+// aggregation query, so records are counted without being read, and falls
+// back to a keys-only query if aggregation queries are not supported.
+// Counts are eventually consistent and may not include recent writes.
+func (m *MetaDB) CountRecords(ctx context.Context, storeKey string, filter RecordFilter) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.CountRecords")
+	defer span.End()
+
+	query, err := m.recordFilterQuery(storeKey, filter)
+	if err != nil {
+		return 0, err
+	}
+	return m.countWithFallback(ctx, query)
+}
+
+// CountBlobRefs returns the number of BlobRefs that match filter, up to
+// filter.Limit if positive. It counts like CountRecords.
+func (m *MetaDB) CountBlobRefs(ctx context.Context, filter BlobRefFilter) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.CountBlobRefs")
+	defer span.End()
+
+	query, err := m.blobRefFilterQuery(filter)
+	if err != nil {
+		return 0, err
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return m.countWithFallback(ctx, query)
+}
+
+// countWithFallback returns the number of entities that match query with an
+// aggregation query, or by reading the keys of the entities if Datastore
+// doesn't support aggregation queries.
+func (m *MetaDB) countWithFallback(ctx context.Context, query *ds.Query) (int64, error) {
+	n, err := m.count(ctx, query)
+	if status.Code(err) != codes.Unimplemented {
+		return n, err
+	}
+	keys, err := m.client.GetAll(ctx, query.KeysOnly(), nil)
+	if err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	return int64(len(keys)), nil
+}
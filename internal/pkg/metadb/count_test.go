@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaDB_CountRecords(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+	for i := 0; i < 5; i++ {
+		r := &record.Record{
+			Key:        newRecordKey(),
+			OwnerID:    "owner",
+			Properties: record.PropertyMap{"level": {Type: pb.Property_INTEGER, IntegerValue: int64(i)}},
+		}
+		if i%2 == 0 {
+			r.Tags = []string{"even"}
+			r.OwnerID = "other"
+		}
+		setupTestRecord(ctx, t, metaDB, st.Key, r)
+	}
+
+	for name, filter := range map[string]m.RecordFilter{
+		"all":   {},
+		"tag":   {Tags: []string{"even"}},
+		"owner": {OwnerID: "owner"},
+		"property": {Filters: []*pb.QueryFilter{{
+			PropertyName: "level",
+			Operator:     pb.FilterOperator_GREATER_OR_EQUAL,
+			Value:        &pb.Property{Type: pb.Property_INTEGER, Value: &pb.Property_IntegerValue{IntegerValue: 3}},
+		}}},
+		"none": {Tags: []string{"missing"}},
+	} {
+		records, err := metaDB.QueryRecords(ctx, &pb.QueryRecordsRequest{
+			StoreKey: st.Key,
+			OwnerId:  filter.OwnerID,
+			Tags:     filter.Tags,
+			Filters:  filter.Filters,
+		})
+		require.NoError(t, err, name)
+		count, err := metaDB.CountRecords(ctx, st.Key, filter)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, int64(len(records)), count, name)
+		}
+	}
+	count, err := metaDB.CountRecords(ctx, st.Key, m.RecordFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestMetaDB_CountBlobRefs(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	ready := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(100, st.Key, r.Key))
+	_, _, err := metaDB.PromoteBlobRefToCurrent(ctx, ready)
+	require.NoError(t, err)
+	for _, size := range []int64{1, 20, 300} {
+		setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(size, st.Key, r.Key))
+	}
+
+	initializing := blobref.StatusInitializing
+	for name, filter := range map[string]m.BlobRefFilter{
+		"store":  {StoreKey: st.Key},
+		"status": {StoreKey: st.Key, Status: &initializing},
+		"size":   {StoreKey: st.Key, MinSize: 10, MaxSize: 200},
+		"limit":  {StoreKey: st.Key, Limit: 2},
+	} {
+		blobs, err := metaDB.QueryBlobRefs(ctx, filter)
+		require.NoError(t, err, name)
+		count, err := metaDB.CountBlobRefs(ctx, filter)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, int64(len(blobs)), count, name)
+		}
+	}
+
+	_, err = metaDB.CountBlobRefs(ctx, m.BlobRefFilter{StoreKey: st.Key, MinSize: 1, ExpiresBefore: ready.Timestamps.CreatedAt})
+	assert.ErrorIs(t, err, m.ErrInequalityFilterConflict)
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"sync"
+
+	ds "cloud.google.com/go/datastore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCursorMismatch is returned when a cursor is passed to a query other than
+// the one that returned it, e.g. a query of a different store or filter.
+var ErrCursorMismatch = status.Error(codes.InvalidArgument, "the cursor was returned by a different query")
+
+const (
+	// cursorFingerprintSize is the number of bytes of the query fingerprint
+	// in cursor tokens.
+	cursorFingerprintSize = 16
+	cursorMACSize         = sha256.Size
+)
+
+var (
+	defaultCursorKeyOnce sync.Once
+	defaultCursorKey     []byte
+)
+
+// cursorKey returns the key to sign cursor tokens with.
+func (m *MetaDB) cursorKey() []byte {
+	if len(m.CursorKey) > 0 {
+		return m.CursorKey
+	}
+	defaultCursorKeyOnce.Do(func() {
+		defaultCursorKey = make([]byte, sha256.Size)
+		if _, err := rand.Read(defaultCursorKey); err != nil {
+			panic("failed to generate the cursor key: " + err.Error())
+		}
+	})
+	return defaultCursorKey
+}
+
+// cursorFingerprint identifies the query that a cursor belongs to.
+type cursorFingerprint [cursorFingerprintSize]byte
+
+// cursorFingerprint returns the fingerprint of the query in the namespace of m
+// described by parts, such as the method name, the store key, and the filter values.
+func (m *MetaDB) cursorFingerprint(parts ...string) cursorFingerprint {
+	h := sha256.New()
+	for _, p := range append([]string{m.Namespace}, parts...) {
+		// Length prefixes make the fingerprints of ("ab", "c") and ("a", "bc") differ.
+		var l [binary.MaxVarintLen64]byte
+		h.Write(l[:binary.PutUvarint(l[:], uint64(len(p)))])
+		h.Write([]byte(p))
+	}
+	var fp cursorFingerprint
+	copy(fp[:], h.Sum(nil))
+	return fp
+}
+
+// encodeCursor returns a token of c that is signed and bound to the query of fp.
+func (m *MetaDB) encodeCursor(c ds.Cursor, fp cursorFingerprint) string {
+	payload := append(fp[:], c.String()...)
+	mac := hmac.New(sha256.New, m.cursorKey())
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(payload))
+}
+
+// decodeCursor returns the cursor of token created by encodeCursor.
+// It returns InvalidArgument if token is malformed or its signature doesn't
+// match, and ErrCursorMismatch if it was created for a query other than fp.
+func (m *MetaDB) decodeCursor(token string, fp cursorFingerprint) (ds.Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < cursorFingerprintSize+cursorMACSize {
+		return ds.Cursor{}, status.Error(codes.InvalidArgument, "invalid cursor: malformed token")
+	}
+	payload, sum := b[:len(b)-cursorMACSize], b[len(b)-cursorMACSize:]
+	mac := hmac.New(sha256.New, m.cursorKey())
+	mac.Write(payload)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return ds.Cursor{}, status.Error(codes.InvalidArgument, "invalid cursor: signature mismatch")
+	}
+	if !hmac.Equal(payload[:cursorFingerprintSize], fp[:]) {
+		return ds.Cursor{}, ErrCursorMismatch
+	}
+	c, err := ds.DecodeCursor(string(payload[cursorFingerprintSize:]))
+	if err != nil {
+		return ds.Cursor{}, status.Errorf(codes.InvalidArgument, "invalid cursor: %v", err)
+	}
+	return c, nil
+}
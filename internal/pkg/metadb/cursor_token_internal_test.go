@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func mustDecodeTestCursor(t *testing.T) ds.Cursor {
+	t.Helper()
+	c, err := ds.DecodeCursor(base64.URLEncoding.EncodeToString([]byte("datastore cursor")))
+	require.NoError(t, err)
+	return c
+}
+
+func TestMetaDB_CursorTokenRoundTrip(t *testing.T) {
+	m := &MetaDB{CursorKey: []byte("secret")}
+	c := mustDecodeTestCursor(t)
+	fp := m.cursorFingerprint("Query", "store", "filter")
+
+	token := m.encodeCursor(c, fp)
+	got, err := m.decodeCursor(token, fp)
+	require.NoError(t, err)
+	assert.Equal(t, c.String(), got.String())
+
+	// Another server sharing the key accepts the token.
+	got, err = (&MetaDB{CursorKey: []byte("secret")}).decodeCursor(token, fp)
+	require.NoError(t, err)
+	assert.Equal(t, c.String(), got.String())
+
+	// The default key works within the process.
+	m = new(MetaDB)
+	got, err = m.decodeCursor(m.encodeCursor(c, fp), fp)
+	require.NoError(t, err)
+	assert.Equal(t, c.String(), got.String())
+}
+
+func TestMetaDB_CursorTokenMismatch(t *testing.T) {
+	m := &MetaDB{CursorKey: []byte("secret")}
+	token := m.encodeCursor(mustDecodeTestCursor(t), m.cursorFingerprint("Query", "store", "filter"))
+
+	for name, fp := range map[string]cursorFingerprint{
+		"store":     m.cursorFingerprint("Query", "other store", "filter"),
+		"filter":    m.cursorFingerprint("Query", "store", "other filter"),
+		"method":    m.cursorFingerprint("OtherQuery", "store", "filter"),
+		"split":     m.cursorFingerprint("Query", "sto", "refilter"),
+		"namespace": (&MetaDB{Namespace: "ns", CursorKey: m.CursorKey}).cursorFingerprint("Query", "store", "filter"),
+	} {
+		_, err := m.decodeCursor(token, fp)
+		assert.True(t, errors.Is(err, ErrCursorMismatch), "%s: decodeCursor() = %v, want ErrCursorMismatch", name, err)
+	}
+}
+
+func TestMetaDB_CursorTokenTampered(t *testing.T) {
+	m := &MetaDB{CursorKey: []byte("secret")}
+	fp := m.cursorFingerprint("Query", "store")
+	token := m.encodeCursor(mustDecodeTestCursor(t), fp)
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+
+	tampered := append([]byte{}, b...)
+	tampered[cursorFingerprintSize] ^= 1
+	// A token for another store with a forged fingerprint.
+	forged := append([]byte{}, b...)
+	otherFP := m.cursorFingerprint("Query", "other store")
+	copy(forged, otherFP[:])
+
+	for name, token := range map[string]string{
+		"tampered cursor": base64.RawURLEncoding.EncodeToString(tampered),
+		"forged store":    base64.RawURLEncoding.EncodeToString(forged),
+		"truncated":       token[:10],
+		"raw cursor":      mustDecodeTestCursor(t).String(),
+		"not base64":      "invalid cursor!",
+		"another key":     (&MetaDB{CursorKey: []byte("other")}).encodeCursor(mustDecodeTestCursor(t), fp),
+	} {
+		fp := fp
+		if name == "forged store" {
+			fp = otherFP
+		}
+		_, err := m.decodeCursor(token, fp)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+		assert.False(t, errors.Is(err, ErrCursorMismatch), name)
+	}
+}
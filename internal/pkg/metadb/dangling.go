@@ -0,0 +1,101 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+// existenceCheckBatchSize is the number of keys looked up per call to GetMulti,
+// which is the maximum Datastore allows.
+const existenceCheckBatchSize = 1000
+
+// existenceProbe is used to look up entities without decoding their properties.
+type existenceProbe struct{}
+
+func (*existenceProbe) Load([]ds.Property) error     { return nil }
+func (*existenceProbe) Save() ([]ds.Property, error) { return nil, nil }
+
+// ListDanglingBlobRefs returns BlobRefs in the store whose record doesn't exist,
+// regardless of their status, for garbage collection to clean up. The records
+// are looked up in batches.
+func (m *MetaDB) ListDanglingBlobRefs(ctx context.Context, storeKey string) ([]*blobref.BlobRef, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ListDanglingBlobRefs")
+	defer span.End()
+
+	blobs, err := m.QueryBlobRefs(ctx, BlobRefFilter{StoreKey: storeKey})
+	if err != nil {
+		return nil, err
+	}
+	var recordKeys []string
+	seen := make(map[string]bool)
+	for _, b := range blobs {
+		if !seen[b.RecordKey] {
+			seen[b.RecordKey] = true
+			recordKeys = append(recordKeys, b.RecordKey)
+		}
+	}
+	exists, err := m.recordsExist(ctx, storeKey, recordKeys)
+	if err != nil {
+		return nil, err
+	}
+	var dangling []*blobref.BlobRef
+	for _, b := range blobs {
+		if !exists[b.RecordKey] {
+			dangling = append(dangling, b)
+		}
+	}
+	return dangling, nil
+}
+
+// recordsExist returns the set of keys of existing records among recordKeys.
+func (m *MetaDB) recordsExist(ctx context.Context, storeKey string, recordKeys []string) (map[string]bool, error) {
+	exists := make(map[string]bool, len(recordKeys))
+	for start := 0; start < len(recordKeys); start += existenceCheckBatchSize {
+		end := start + existenceCheckBatchSize
+		if end > len(recordKeys) {
+			end = len(recordKeys)
+		}
+		batch := recordKeys[start:end]
+		keys := make([]*ds.Key, len(batch))
+		for i, k := range batch {
+			keys[i] = m.createRecordKey(storeKey, k)
+		}
+		probes := make([]*existenceProbe, len(batch))
+		for i := range probes {
+			probes[i] = new(existenceProbe)
+		}
+		err := m.entities.GetMulti(ctx, keys, probes)
+		merr, isMulti := err.(ds.MultiError)
+		if err != nil && !isMulti {
+			return nil, datastoreErrToGRPCStatus(err)
+		}
+		for i, k := range batch {
+			if isMulti && merr[i] != nil {
+				if merr[i] != ds.ErrNoSuchEntity {
+					return nil, datastoreErrToGRPCStatus(merr[i])
+				}
+				continue
+			}
+			exists[k] = true
+		}
+	}
+	return exists, nil
+}
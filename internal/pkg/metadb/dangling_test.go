@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaDB_ListDanglingBlobRefs(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	other := setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	otherStore, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name() + "-other"}, nil)
+
+	valid := []*blobref.BlobRef{
+		setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, st.Key, r.Key)),
+		setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, st.Key, other.Key)),
+	}
+	missing := newRecordKey()
+	ready := blobref.NewBlobRef(0, st.Key, newRecordKey())
+	ready.Ready()
+	dangling := []*blobref.BlobRef{
+		setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, st.Key, missing)),
+		setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, st.Key, missing)),
+		setupTestBlobRef(ctx, t, metaDB, ready),
+	}
+	// A BlobRef in another store is not returned even though its record is missing.
+	setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, otherStore.Key, newRecordKey()))
+
+	got, err := metaDB.ListDanglingBlobRefs(ctx, st.Key)
+	require.NoError(t, err)
+	gotKeys := blobRefKeys(got)
+	assert.ElementsMatch(t, blobRefKeys(dangling), gotKeys)
+	for _, b := range valid {
+		assert.NotContains(t, gotKeys, b.Key)
+	}
+
+	got, err = metaDB.ListDanglingBlobRefs(ctx, newStoreKey())
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
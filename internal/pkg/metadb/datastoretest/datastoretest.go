@@ -0,0 +1,461 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package datastoretest provides an in-memory fake of the Datastore client
+// for unit tests that don't need the emulator.
+//
+// Client implements the subset of *datastore.Client used by Open Saves with
+// the same method signatures where possible: Get, GetMulti, Put, PutMulti,
+// Delete, DeleteMulti, RunInTransaction, Run, and GetAll. Entities are saved
+// and loaded with their PropertyLoadSaver and KeyLoader implementations, so
+// BlobRef, Record, and other entities round-trip the same as with Datastore.
+// Missing entities return datastore.ErrNoSuchEntity and datastore.MultiError
+// like the real client.
+//
+// Queries are built with NewQuery, since datastore.Query can't be inspected.
+// They support kind, namespace, ancestor, equality and inequality filters on
+// indexed properties, including nested properties such as "Properties.level"
+// and the "__key__" pseudo-property, orders, limits, offsets, cursors, and
+// keys-only queries. Results are strongly consistent and deterministic.
+//
+// Transactions and queries of MetaDB use the concrete *datastore.Transaction
+// and *datastore.Iterator types, so they still need the emulator. MetaDB gets,
+// puts, and deletes entities by key through a small interface that Client
+// implements, so that those methods can be tested with Client.
+package datastoretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	ds "cloud.google.com/go/datastore"
+)
+
+// maxAttempts is the number of attempts RunInTransaction makes before it
+// returns datastore.ErrConcurrentTransaction, the same as Datastore.
+const maxAttempts = 3
+
+// entity is an entity stored in Client.
+type entity struct {
+	key   *ds.Key
+	props []ds.Property
+}
+
+// Client is an in-memory fake of *datastore.Client. The zero value is not
+// usable; use NewClient instead. It is safe for concurrent use.
+type Client struct {
+	mu       sync.Mutex
+	entities map[string]entity
+	// versions is incremented every time the entity with the key is written
+	// or deleted, to detect conflicting transactions.
+	versions map[string]int64
+	nextID   int64
+}
+
+// NewClient returns a new empty Client.
+func NewClient() *Client {
+	return &Client{
+		entities: make(map[string]entity),
+		versions: make(map[string]int64),
+	}
+}
+
+// Close is a no-op for compatibility with *datastore.Client.
+func (c *Client) Close() error {
+	return nil
+}
+
+func keyString(key *ds.Key) string {
+	return key.Encode()
+}
+
+// saveEntity returns the properties of src, a PropertyLoadSaver or a pointer to a struct.
+func saveEntity(src interface{}) ([]ds.Property, error) {
+	if pls, ok := src.(ds.PropertyLoadSaver); ok {
+		return pls.Save()
+	}
+	return ds.SaveStruct(src)
+}
+
+// loadEntity loads props and key into dst, a PropertyLoadSaver or a pointer to a struct.
+func loadEntity(dst interface{}, key *ds.Key, props []ds.Property) error {
+	props = copyProperties(props)
+	var err error
+	if pls, ok := dst.(ds.PropertyLoadSaver); ok {
+		err = pls.Load(props)
+	} else {
+		err = ds.LoadStruct(dst, props)
+	}
+	if err != nil {
+		return err
+	}
+	if kl, ok := dst.(ds.KeyLoader); ok {
+		return kl.LoadKey(key)
+	}
+	return nil
+}
+
+// copyProperties returns a deep copy of props so that stored entities are not
+// shared with callers.
+func copyProperties(props []ds.Property) []ds.Property {
+	if props == nil {
+		return nil
+	}
+	out := make([]ds.Property, len(props))
+	for i, p := range props {
+		out[i] = p
+		out[i].Value = copyValue(p.Value)
+	}
+	return out
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return append([]byte(nil), v...)
+	case *ds.Entity:
+		if v == nil {
+			return v
+		}
+		return &ds.Entity{Key: v.Key, Properties: copyProperties(v.Properties)}
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = copyValue(e)
+		}
+		return out
+	}
+	return v
+}
+
+// checkKey returns an error like Datastore for invalid keys.
+func checkKey(key *ds.Key) error {
+	if key == nil || key.Incomplete() {
+		return ds.ErrInvalidKey
+	}
+	return nil
+}
+
+// completeKey returns key, or a copy of key with a new ID if it is incomplete.
+// c.mu must be held.
+func (c *Client) completeKey(key *ds.Key) (*ds.Key, error) {
+	if key == nil || key.Kind == "" {
+		return nil, ds.ErrInvalidKey
+	}
+	if !key.Incomplete() {
+		return key, nil
+	}
+	c.nextID++
+	k := *key
+	k.ID = c.nextID
+	return &k, nil
+}
+
+// get loads the entity with key into dst. c.mu must be held.
+func (c *Client) get(key *ds.Key, dst interface{}) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	e, ok := c.entities[keyString(key)]
+	if !ok {
+		return ds.ErrNoSuchEntity
+	}
+	return loadEntity(dst, e.key, e.props)
+}
+
+// put stores props with key. c.mu must be held.
+func (c *Client) put(key *ds.Key, props []ds.Property) {
+	k := keyString(key)
+	c.entities[k] = entity{key: key, props: copyProperties(props)}
+	c.versions[k]++
+}
+
+// delete deletes the entity with key. c.mu must be held.
+func (c *Client) delete(key *ds.Key) {
+	k := keyString(key)
+	if _, ok := c.entities[k]; ok {
+		delete(c.entities, k)
+		c.versions[k]++
+	}
+}
+
+// Get loads the entity with key into dst. It returns datastore.ErrNoSuchEntity
+// if the entity doesn't exist.
+func (c *Client) Get(ctx context.Context, key *ds.Key, dst interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(key, dst)
+}
+
+// GetMulti is a batch version of Get. dst must be a slice of the same length
+// as keys. It returns a datastore.MultiError if any entity fails to load.
+func (c *Client) GetMulti(ctx context.Context, keys []*ds.Key, dst interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getMulti(keys, dst)
+}
+
+// getMulti implements GetMulti. c.mu must be held.
+func (c *Client) getMulti(keys []*ds.Key, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Slice || v.Len() != len(keys) {
+		return errors.New("datastoretest: dst must be a slice of the same length as keys")
+	}
+	errs := make(ds.MultiError, len(keys))
+	failed := false
+	for i, key := range keys {
+		if errs[i] = c.get(key, sliceElem(v, i)); errs[i] != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return errs
+	}
+	return nil
+}
+
+// sliceElem returns a pointer to, or the value of if it is a pointer or an
+// interface, the i-th element of v, allocating nil pointers.
+func sliceElem(v reflect.Value, i int) interface{} {
+	e := v.Index(i)
+	switch e.Kind() {
+	case reflect.Ptr:
+		if e.IsNil() {
+			e.Set(reflect.New(e.Type().Elem()))
+		}
+		return e.Interface()
+	case reflect.Interface:
+		return e.Interface()
+	}
+	return e.Addr().Interface()
+}
+
+// Put saves src with key and returns the complete key. A new ID is allocated
+// if key is incomplete.
+func (c *Client) Put(ctx context.Context, key *ds.Key, src interface{}) (*ds.Key, error) {
+	keys, err := c.PutMulti(ctx, []*ds.Key{key}, []interface{}{src})
+	if err != nil {
+		if me, ok := err.(ds.MultiError); ok {
+			return nil, me[0]
+		}
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+// PutMulti is a batch version of Put. src must be a slice of the same length
+// as keys. Either all entities are saved or none of them.
+func (c *Client) PutMulti(ctx context.Context, keys []*ds.Key, src interface{}) ([]*ds.Key, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Slice || v.Len() != len(keys) {
+		return nil, errors.New("datastoretest: src must be a slice of the same length as keys")
+	}
+	props := make([][]ds.Property, len(keys))
+	errs := make(ds.MultiError, len(keys))
+	failed := false
+	for i := range keys {
+		if props[i], errs[i] = saveEntity(sliceElem(v, i)); errs[i] != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return nil, errs
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	complete := make([]*ds.Key, len(keys))
+	for i, key := range keys {
+		k, err := c.completeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		complete[i] = k
+	}
+	for i, k := range complete {
+		c.put(k, props[i])
+	}
+	return complete, nil
+}
+
+// Delete deletes the entity with key. Deleting a missing entity is not an error.
+func (c *Client) Delete(ctx context.Context, key *ds.Key) error {
+	return c.DeleteMulti(ctx, []*ds.Key{key})
+}
+
+// DeleteMulti is a batch version of Delete.
+func (c *Client) DeleteMulti(ctx context.Context, keys []*ds.Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := checkKey(key); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		c.delete(key)
+	}
+	return nil
+}
+
+// Len returns the number of stored entities, for assertions in tests.
+func (c *Client) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entities)
+}
+
+// mutation is a write buffered in a Transaction.
+type mutation struct {
+	key   *ds.Key
+	props []ds.Property
+	del   bool
+}
+
+// Transaction is a fake of *datastore.Transaction. Reads see the entities
+// committed before the transaction, not its own writes, like Datastore.
+type Transaction struct {
+	c         *Client
+	reads     map[string]int64
+	mutations []mutation
+}
+
+func (tx *Transaction) recordRead(key *ds.Key) {
+	k := keyString(key)
+	if _, ok := tx.reads[k]; !ok {
+		tx.reads[k] = tx.c.versions[k]
+	}
+}
+
+// Get loads the entity with key into dst.
+func (tx *Transaction) Get(key *ds.Key, dst interface{}) error {
+	tx.c.mu.Lock()
+	defer tx.c.mu.Unlock()
+	if key != nil {
+		tx.recordRead(key)
+	}
+	return tx.c.get(key, dst)
+}
+
+// GetMulti is a batch version of Get.
+func (tx *Transaction) GetMulti(keys []*ds.Key, dst interface{}) error {
+	tx.c.mu.Lock()
+	defer tx.c.mu.Unlock()
+	for _, key := range keys {
+		if key != nil {
+			tx.recordRead(key)
+		}
+	}
+	return tx.c.getMulti(keys, dst)
+}
+
+// Put saves src with key when the transaction commits. Unlike Datastore, an
+// incomplete key is completed immediately, and the complete key is returned.
+func (tx *Transaction) Put(key *ds.Key, src interface{}) (*ds.Key, error) {
+	props, err := saveEntity(src)
+	if err != nil {
+		return nil, err
+	}
+	tx.c.mu.Lock()
+	k, err := tx.c.completeKey(key)
+	tx.c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	tx.mutations = append(tx.mutations, mutation{key: k, props: props})
+	return k, nil
+}
+
+// Delete deletes the entity with key when the transaction commits.
+func (tx *Transaction) Delete(key *ds.Key) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	tx.mutations = append(tx.mutations, mutation{key: key, del: true})
+	return nil
+}
+
+// commit applies the mutations unless an entity read by the transaction was
+// changed after it was read, in which case it returns ErrConcurrentTransaction.
+func (tx *Transaction) commit() error {
+	c := tx.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, v := range tx.reads {
+		if c.versions[k] != v {
+			return ds.ErrConcurrentTransaction
+		}
+	}
+	for _, m := range tx.mutations {
+		if m.del {
+			c.delete(m.key)
+		} else {
+			c.put(m.key, m.props)
+		}
+	}
+	return nil
+}
+
+// RunInTransaction runs f in a transaction and commits the writes if f
+// returns nil. f is retried if the transaction conflicts with another write,
+// and ErrConcurrentTransaction is returned if all attempts conflict.
+// Transaction options are ignored.
+func (c *Client) RunInTransaction(ctx context.Context, f func(tx *Transaction) error, opts ...ds.TransactionOption) (*ds.Commit, error) {
+	for i := 0; i < maxAttempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		tx := &Transaction{c: c, reads: make(map[string]int64)}
+		if err := f(tx); err != nil {
+			return nil, err
+		}
+		err := tx.commit()
+		if err == nil {
+			return new(ds.Commit), nil
+		}
+		if err != ds.ErrConcurrentTransaction {
+			return nil, err
+		}
+	}
+	return nil, ds.ErrConcurrentTransaction
+}
+
+// String returns a description of the Client for debugging.
+func (c *Client) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.entities))
+	for _, e := range c.entities {
+		keys = append(keys, e.key.String())
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("datastoretest.Client{%s}", strings.Join(keys, ", "))
+}
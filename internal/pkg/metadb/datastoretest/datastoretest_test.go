@@ -0,0 +1,235 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastoretest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/iterator"
+)
+
+const blobKind = "blob"
+
+func putBlobRef(ctx context.Context, t *testing.T, c *Client, b *blobref.BlobRef) *ds.Key {
+	t.Helper()
+	key, err := c.Put(ctx, ds.NameKey(blobKind, b.Key.String(), nil), b)
+	require.NoError(t, err)
+	return key
+}
+
+func TestClient_GetPutDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	b := blobref.NewBlobRef(42, "store", "record")
+	key := putBlobRef(ctx, t, c, b)
+
+	got := new(blobref.BlobRef)
+	require.NoError(t, c.Get(ctx, key, got))
+	assert.Equal(t, b.Key, got.Key, "LoadKey must be called")
+	assert.Equal(t, b.Size, got.Size)
+	assert.Equal(t, b.StoreKey, got.StoreKey)
+
+	require.NoError(t, c.Delete(ctx, key))
+	assert.True(t, errors.Is(c.Get(ctx, key, got), ds.ErrNoSuchEntity))
+	assert.NoError(t, c.Delete(ctx, key), "deleting a missing entity is not an error")
+}
+
+func TestClient_IncompleteKey(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	k1, err := c.Put(ctx, ds.IncompleteKey("counter", nil), &struct{ N int }{1})
+	require.NoError(t, err)
+	k2, err := c.Put(ctx, ds.IncompleteKey("counter", nil), &struct{ N int }{2})
+	require.NoError(t, err)
+	assert.False(t, k1.Incomplete())
+	assert.NotEqual(t, k1.ID, k2.ID)
+	assert.True(t, errors.Is(c.Get(ctx, ds.IncompleteKey("counter", nil), &struct{ N int }{}), ds.ErrInvalidKey))
+}
+
+func TestClient_GetMulti(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	b := blobref.NewBlobRef(1, "store", "record")
+	key := putBlobRef(ctx, t, c, b)
+	missing := ds.NameKey(blobKind, uuid.NewString(), nil)
+
+	dst := make([]*blobref.BlobRef, 2)
+	err := c.GetMulti(ctx, []*ds.Key{key, missing}, dst)
+	var me ds.MultiError
+	require.True(t, errors.As(err, &me), "GetMulti() = %v, want MultiError", err)
+	assert.NoError(t, me[0])
+	assert.Equal(t, ds.ErrNoSuchEntity, me[1])
+	assert.Equal(t, b.Key, dst[0].Key)
+}
+
+func TestClient_PutMultiQuery(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	var keys []*ds.Key
+	var blobs []*blobref.BlobRef
+	for i, store := range []string{"a", "b", "a", "a"} {
+		b := blobref.NewBlobRef(int64(i), store, "record")
+		if i == 3 {
+			require.NoError(t, b.Ready())
+		}
+		keys = append(keys, ds.NameKey(blobKind, b.Key.String(), nil))
+		blobs = append(blobs, b)
+	}
+	_, err := c.PutMulti(ctx, keys, blobs)
+	require.NoError(t, err)
+	assert.Equal(t, 4, c.Len())
+
+	q := NewQuery(blobKind).Filter("StoreKey =", "a").Order("-Size")
+	var got []*blobref.BlobRef
+	_, err = c.GetAll(ctx, q, &got)
+	require.NoError(t, err)
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, []int64{3, 2, 0}, []int64{got[0].Size, got[1].Size, got[2].Size})
+	}
+
+	q = q.Filter("Status =", int64(blobref.StatusInitializing))
+	keysOnly, err := c.GetAll(ctx, q.KeysOnly(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*ds.Key{keys[2], keys[0]}, keysOnly)
+
+	// KMSKeyName is not indexed, so it can't be filtered just like Datastore.
+	blobs[0].KMSKeyName = "key"
+	_, err = c.Put(ctx, keys[0], blobs[0])
+	require.NoError(t, err)
+	keysOnly, err = c.GetAll(ctx, NewQuery(blobKind).Filter("KMSKeyName =", "key").KeysOnly(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, keysOnly)
+}
+
+func TestClient_QueryCursor(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	type item struct{ N int }
+	for i := 0; i < 5; i++ {
+		_, err := c.Put(ctx, ds.IDKey("item", int64(i+1), nil), &item{i})
+		require.NoError(t, err)
+	}
+
+	q := NewQuery("item").Filter("N >=", 1).Order("N").Limit(2)
+	var got []int
+	var cursor Cursor
+	for {
+		it := c.Run(ctx, q.Start(cursor))
+		n := 0
+		for {
+			var e item
+			_, err := it.Next(&e)
+			if err == iterator.Done {
+				break
+			}
+			require.NoError(t, err)
+			got = append(got, e.N)
+			n++
+		}
+		if n == 0 {
+			break
+		}
+		var err error
+		cursor, err = it.Cursor()
+		require.NoError(t, err)
+		cursor, err = DecodeCursor(cursor.String())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, got)
+}
+
+func TestClient_RunInTransaction(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	b := blobref.NewBlobRef(1, "store", "record")
+	key := putBlobRef(ctx, t, c, b)
+
+	_, err := c.RunInTransaction(ctx, func(tx *Transaction) error {
+		got := new(blobref.BlobRef)
+		if err := tx.Get(key, got); err != nil {
+			return err
+		}
+		if err := got.Ready(); err != nil {
+			return err
+		}
+		_, err := tx.Put(key, got)
+		return err
+	})
+	require.NoError(t, err)
+	got := new(blobref.BlobRef)
+	require.NoError(t, c.Get(ctx, key, got))
+	assert.Equal(t, blobref.StatusReady, got.Status)
+
+	// Writes are discarded when f fails.
+	errRollback := errors.New("rollback")
+	_, err = c.RunInTransaction(ctx, func(tx *Transaction) error {
+		if err := tx.Delete(key); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	assert.Equal(t, errRollback, err)
+	assert.NoError(t, c.Get(ctx, key, got))
+}
+
+func TestClient_RunInTransactionConflict(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	type counter struct{ N int }
+	key := ds.NameKey("counter", "c", nil)
+	_, err := c.Put(ctx, key, &counter{})
+	require.NoError(t, err)
+
+	// A concurrent write after the first read makes the first attempt retry.
+	attempts := 0
+	_, err = c.RunInTransaction(ctx, func(tx *Transaction) error {
+		attempts++
+		var v counter
+		if err := tx.Get(key, &v); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			if _, err := c.Put(ctx, key, &counter{N: 10}); err != nil {
+				return err
+			}
+		}
+		v.N++
+		_, err := tx.Put(key, &v)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	var v counter
+	require.NoError(t, c.Get(ctx, key, &v))
+	assert.Equal(t, 11, v.N)
+
+	// Conflicting on every attempt fails with ErrConcurrentTransaction.
+	_, err = c.RunInTransaction(ctx, func(tx *Transaction) error {
+		var v counter
+		if err := tx.Get(key, &v); err != nil {
+			return err
+		}
+		_, err := c.Put(ctx, key, &v)
+		return err
+	})
+	assert.Equal(t, ds.ErrConcurrentTransaction, err)
+}
@@ -0,0 +1,552 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastoretest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"google.golang.org/api/iterator"
+)
+
+// keyProperty is the pseudo-property name of entity keys in filters and orders.
+const keyProperty = "__key__"
+
+// filter is a property filter of a Query.
+type filter struct {
+	path  []string
+	op    string
+	value interface{}
+}
+
+// order is a sort order of a Query.
+type order struct {
+	path []string
+	desc bool
+}
+
+// Query is a query for Client.Run and Client.GetAll. Like datastore.Query,
+// the builder methods return a derived Query and don't modify the receiver.
+type Query struct {
+	kind      string
+	namespace string
+	ancestor  *ds.Key
+	filters   []filter
+	orders    []order
+	limit     int
+	offset    int
+	start     int
+	keysOnly  bool
+	err       error
+}
+
+// NewQuery returns a new Query of entities of kind.
+func NewQuery(kind string) *Query {
+	return &Query{kind: kind, limit: -1}
+}
+
+func (q *Query) clone() *Query {
+	c := *q
+	c.filters = append([]filter(nil), q.filters...)
+	c.orders = append([]order(nil), q.orders...)
+	return &c
+}
+
+// Namespace returns a derived query that returns entities in namespace.
+func (q *Query) Namespace(namespace string) *Query {
+	q = q.clone()
+	q.namespace = namespace
+	return q
+}
+
+// Ancestor returns a derived query that returns descendants of ancestor.
+func (q *Query) Ancestor(ancestor *ds.Key) *Query {
+	q = q.clone()
+	q.ancestor = ancestor
+	return q
+}
+
+// Filter returns a derived query with a property filter. filterStr is a
+// property name, optionally dotted for nested entities, followed by one of
+// the operators =, !=, <, <=, >, or >=, e.g. "Properties.level >".
+func (q *Query) Filter(filterStr string, value interface{}) *Query {
+	q = q.clone()
+	fields := strings.Fields(filterStr)
+	if len(fields) != 2 {
+		q.err = fmt.Errorf("datastoretest: invalid filter %q", filterStr)
+		return q
+	}
+	switch fields[1] {
+	case "=", "!=", "<", "<=", ">", ">=":
+	default:
+		q.err = fmt.Errorf("datastoretest: invalid operator %q in filter %q", fields[1], filterStr)
+		return q
+	}
+	q.filters = append(q.filters, filter{path: strings.Split(fields[0], "."), op: fields[1], value: value})
+	return q
+}
+
+// Order returns a derived query sorted by fieldName, in descending order if
+// fieldName has the "-" prefix.
+func (q *Query) Order(fieldName string) *Query {
+	q = q.clone()
+	o := order{}
+	if strings.HasPrefix(fieldName, "-") {
+		o.desc = true
+		fieldName = strings.TrimPrefix(fieldName, "-")
+	}
+	o.path = strings.Split(strings.TrimSpace(fieldName), ".")
+	q.orders = append(q.orders, o)
+	return q
+}
+
+// Limit returns a derived query that returns at most limit entities.
+// A negative limit means unlimited.
+func (q *Query) Limit(limit int) *Query {
+	q = q.clone()
+	q.limit = limit
+	return q
+}
+
+// Offset returns a derived query that skips the first offset entities.
+func (q *Query) Offset(offset int) *Query {
+	q = q.clone()
+	q.offset = offset
+	return q
+}
+
+// Start returns a derived query that starts at cursor, as returned by
+// Iterator.Cursor.
+func (q *Query) Start(cursor Cursor) *Query {
+	q = q.clone()
+	n, err := cursor.position()
+	if err != nil {
+		q.err = err
+	}
+	q.start = n
+	return q
+}
+
+// KeysOnly returns a derived query that returns only keys.
+func (q *Query) KeysOnly() *Query {
+	q = q.clone()
+	q.keysOnly = true
+	return q
+}
+
+// Cursor is a position in the results of a Query.
+type Cursor struct {
+	s string
+}
+
+// String returns the opaque string representation of the cursor.
+func (c Cursor) String() string {
+	return c.s
+}
+
+// DecodeCursor decodes a cursor from its String representation.
+func DecodeCursor(s string) (Cursor, error) {
+	c := Cursor{s: s}
+	if _, err := c.position(); err != nil {
+		return Cursor{}, err
+	}
+	return c, nil
+}
+
+func newCursor(n int) Cursor {
+	return Cursor{s: base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(n)))}
+}
+
+func (c Cursor) position() (int, error) {
+	if c.s == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(c.s)
+	if err != nil {
+		return 0, fmt.Errorf("datastoretest: invalid cursor: %w", err)
+	}
+	n, err := strconv.Atoi(string(b))
+	if err != nil || n < 0 {
+		return 0, errors.New("datastoretest: invalid cursor")
+	}
+	return n, nil
+}
+
+// Iterator is the result of Client.Run.
+type Iterator struct {
+	entities []entity
+	// pos is the position of the next entity in the full results of the
+	// query, for cursors.
+	pos      int
+	keysOnly bool
+	err      error
+}
+
+// Next loads the next entity into dst and returns its key. dst may be nil for
+// keys-only queries. It returns iterator.Done when there are no more results.
+func (it *Iterator) Next(dst interface{}) (*ds.Key, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	if len(it.entities) == 0 {
+		return nil, iterator.Done
+	}
+	e := it.entities[0]
+	it.entities = it.entities[1:]
+	it.pos++
+	if dst != nil && !it.keysOnly {
+		if err := loadEntity(dst, e.key, e.props); err != nil {
+			return nil, err
+		}
+	}
+	return e.key, nil
+}
+
+// Cursor returns a cursor for the position after the last result returned by Next.
+func (it *Iterator) Cursor() (Cursor, error) {
+	if it.err != nil {
+		return Cursor{}, it.err
+	}
+	return newCursor(it.pos), nil
+}
+
+// Run runs q and returns an Iterator over the results.
+func (c *Client) Run(ctx context.Context, q *Query) *Iterator {
+	if err := ctx.Err(); err != nil {
+		return &Iterator{err: err}
+	}
+	if q.err != nil {
+		return &Iterator{err: q.err}
+	}
+
+	c.mu.Lock()
+	var results []entity
+	for _, e := range c.entities {
+		if q.matches(e) {
+			results = append(results, entity{key: e.key, props: copyProperties(e.props)})
+		}
+	}
+	c.mu.Unlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return q.less(results[i], results[j])
+	})
+	start := q.start + q.offset
+	if start > len(results) {
+		start = len(results)
+	}
+	results = results[start:]
+	if q.limit >= 0 && q.limit < len(results) {
+		results = results[:q.limit]
+	}
+	return &Iterator{entities: results, pos: start, keysOnly: q.keysOnly}
+}
+
+// GetAll runs q and appends the results to dst, a pointer to a slice of
+// structs, struct pointers, or PropertyLoadSavers. dst may be nil for
+// keys-only queries. It returns the keys of the results.
+func (c *Client) GetAll(ctx context.Context, q *Query, dst interface{}) ([]*ds.Key, error) {
+	var sv reflect.Value
+	if dst != nil {
+		sv = reflect.ValueOf(dst)
+		if sv.Kind() != reflect.Ptr || sv.Elem().Kind() != reflect.Slice {
+			return nil, errors.New("datastoretest: dst must be a pointer to a slice")
+		}
+		sv = sv.Elem()
+	}
+	var keys []*ds.Key
+	it := c.Run(ctx, q)
+	for {
+		var elem reflect.Value
+		var target interface{}
+		if sv.IsValid() && !q.keysOnly {
+			et := sv.Type().Elem()
+			if et.Kind() == reflect.Ptr {
+				elem = reflect.New(et.Elem())
+				target = elem.Interface()
+			} else {
+				elem = reflect.New(et)
+				target = elem.Interface()
+				elem = elem.Elem()
+			}
+		}
+		key, err := it.Next(target)
+		if err == iterator.Done {
+			return keys, nil
+		}
+		if err != nil {
+			return keys, err
+		}
+		keys = append(keys, key)
+		if elem.IsValid() {
+			sv.Set(reflect.Append(sv, elem))
+		}
+	}
+}
+
+// matches reports whether e is a result of q.
+func (q *Query) matches(e entity) bool {
+	if e.key.Kind != q.kind || e.key.Namespace != q.namespace {
+		return false
+	}
+	if q.ancestor != nil && !hasAncestor(e.key, q.ancestor) {
+		return false
+	}
+	for _, f := range q.filters {
+		if !f.matches(e) {
+			return false
+		}
+	}
+	// Like Datastore, entities without an indexed value of an order property
+	// are not returned.
+	for _, o := range q.orders {
+		if _, ok := indexedValue(e, o.path); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAncestor(key, ancestor *ds.Key) bool {
+	for k := key; k != nil; k = k.Parent {
+		if k.Equal(ancestor) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether any indexed value of the property of f in e
+// satisfies f.
+func (f filter) matches(e entity) bool {
+	for _, v := range indexedValues(e, f.path) {
+		c := compareValues(v, f.value)
+		var ok bool
+		switch f.op {
+		case "=":
+			ok = c == 0
+		case "!=":
+			ok = c != 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// less orders entities by the orders of q, then by key so that the results
+// are deterministic.
+func (q *Query) less(a, b entity) bool {
+	for _, o := range q.orders {
+		av, _ := indexedValue(a, o.path)
+		bv, _ := indexedValue(b, o.path)
+		c := compareValues(av, bv)
+		if o.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+	}
+	return compareKeys(a.key, b.key) < 0
+}
+
+// indexedValue returns the first indexed value of the property at path in e.
+func indexedValue(e entity, path []string) (interface{}, bool) {
+	values := indexedValues(e, path)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// indexedValues returns the indexed values of the property at path in e.
+// Array properties return their elements.
+func indexedValues(e entity, path []string) []interface{} {
+	if len(path) == 1 && path[0] == keyProperty {
+		return []interface{}{e.key}
+	}
+	return propertyValues(e.props, path)
+}
+
+func propertyValues(props []ds.Property, path []string) []interface{} {
+	var out []interface{}
+	for _, p := range props {
+		if p.Name != path[0] || p.NoIndex {
+			continue
+		}
+		values := []interface{}{p.Value}
+		if a, ok := p.Value.([]interface{}); ok {
+			values = a
+		}
+		for _, v := range values {
+			if len(path) == 1 {
+				out = append(out, v)
+				continue
+			}
+			if ne, ok := v.(*ds.Entity); ok && ne != nil {
+				out = append(out, propertyValues(ne.Properties, path[1:])...)
+			}
+		}
+	}
+	return out
+}
+
+// typeRank returns the rank of the type of v in the Datastore value ordering.
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return 1
+	case time.Time:
+		return 2
+	case bool:
+		return 3
+	case []byte:
+		return 4
+	case string:
+		return 5
+	case float32, float64:
+		return 6
+	case ds.GeoPoint:
+		return 7
+	case *ds.Key:
+		return 8
+	}
+	return 9
+}
+
+func toInt64(v interface{}) int64 {
+	return reflect.ValueOf(v).Convert(reflect.TypeOf(int64(0))).Int()
+}
+
+func toFloat64(v interface{}) float64 {
+	return reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0))).Float()
+}
+
+// compareValues compares Datastore values a and b, returning a negative
+// number, zero, or a positive number. Values of different types are ordered
+// by type like Datastore.
+func compareValues(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch av := a.(type) {
+	case nil:
+		return 0
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		}
+		return 1
+	case []byte:
+		return bytes.Compare(av, b.([]byte))
+	case string:
+		return strings.Compare(av, b.(string))
+	case ds.GeoPoint:
+		bv := b.(ds.GeoPoint)
+		if c := compareFloat(av.Lat, bv.Lat); c != 0 {
+			return c
+		}
+		return compareFloat(av.Lng, bv.Lng)
+	case *ds.Key:
+		return compareKeys(av, b.(*ds.Key))
+	}
+	switch ra {
+	case 1:
+		ai, bi := toInt64(a), toInt64(b)
+		switch {
+		case ai < bi:
+			return -1
+		case ai > bi:
+			return 1
+		}
+		return 0
+	case 6:
+		return compareFloat(toFloat64(a), toFloat64(b))
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareKeys orders keys by their paths from the root, comparing kinds,
+// then IDs before names, like Datastore.
+func compareKeys(a, b *ds.Key) int {
+	pa, pb := keyPath(a), keyPath(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		x, y := pa[i], pb[i]
+		if c := strings.Compare(x.Kind, y.Kind); c != 0 {
+			return c
+		}
+		switch {
+		case x.Name == "" && y.Name != "":
+			return -1
+		case x.Name != "" && y.Name == "":
+			return 1
+		case x.ID != y.ID:
+			if x.ID < y.ID {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(x.Name, y.Name); c != 0 {
+			return c
+		}
+	}
+	return len(pa) - len(pb)
+}
+
+func keyPath(k *ds.Key) []*ds.Key {
+	var path []*ds.Key
+	for ; k != nil; k = k.Parent {
+		path = append([]*ds.Key{k}, path...)
+	}
+	return path
+}
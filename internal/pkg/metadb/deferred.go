@@ -0,0 +1,68 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeferredDeleteFunc is a callback for RunWithDeferredBlobDeletes. It runs in
+// a transaction and returns the object paths to delete once tx commits.
+type DeferredDeleteFunc func(tx *ds.Transaction) ([]string, error)
+
+// RunWithDeferredBlobDeletes runs fn in a transaction and deletes the object
+// paths returned by fn from bs only after the transaction commits, so that
+// objects are never removed while the metadata still references them.
+// No objects are deleted if fn returns an error or the transaction fails to
+// commit. fn can be called more than once if the transaction is retried, in which
+// case only the paths returned by the committed attempt are deleted.
+// Returned errors:
+//   - Errors returned by fn or the transaction
+//   - Internal: the transaction committed but some objects couldn't be deleted
+func (m *MetaDB) RunWithDeferredBlobDeletes(ctx context.Context, bs blob.BlobStore, fn DeferredDeleteFunc) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.RunWithDeferredBlobDeletes")
+	defer span.End()
+
+	var paths []string
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		p, err := fn(tx)
+		if err != nil {
+			return err
+		}
+		paths = p
+		return nil
+	})
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	failed, err := bs.DeleteMulti(ctx, paths)
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return status.Errorf(codes.Internal, "failed to delete %d object(s) after commit: %v", len(failed), err)
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupDeferredObjects(ctx context.Context, t *testing.T, bs blob.BlobStore) []string {
+	t.Helper()
+	paths := []string{uuid.NewString(), uuid.NewString()}
+	for _, p := range paths {
+		require.NoError(t, bs.Put(ctx, p, []byte("data")))
+	}
+	return paths
+}
+
+func TestMetaDB_RunWithDeferredBlobDeletesCommit(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	paths := setupDeferredObjects(ctx, t, bs)
+
+	err := metaDB.RunWithDeferredBlobDeletes(ctx, bs, func(tx *ds.Transaction) ([]string, error) {
+		// Objects must not be deleted before the transaction commits.
+		for _, p := range paths {
+			_, err := bs.Get(ctx, p)
+			assert.NoError(t, err)
+		}
+		return paths, nil
+	})
+	require.NoError(t, err)
+	for _, p := range paths {
+		_, err := bs.Get(ctx, p)
+		assert.Error(t, err, "object %v should be deleted after commit", p)
+	}
+}
+
+func TestMetaDB_RunWithDeferredBlobDeletesRollback(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	paths := setupDeferredObjects(ctx, t, bs)
+
+	want := status.Error(codes.FailedPrecondition, "rollback")
+	err := metaDB.RunWithDeferredBlobDeletes(ctx, bs, func(tx *ds.Transaction) ([]string, error) {
+		return paths, want
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	for _, p := range paths {
+		_, err := bs.Get(ctx, p)
+		assert.NoError(t, err, "object %v should not be deleted on rollback", p)
+	}
+}
+
+func TestMetaDB_RunWithDeferredBlobDeletesMissingObject(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+
+	// Objects that are already gone are considered deleted.
+	err := metaDB.RunWithDeferredBlobDeletes(ctx, bs, func(tx *ds.Transaction) ([]string, error) {
+		return []string{uuid.NewString()}, nil
+	})
+	assert.NoError(t, err)
+}
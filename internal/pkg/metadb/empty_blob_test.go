@@ -0,0 +1,81 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setEmptyInlineBlob stores a zero-byte inline blob the same way the server does.
+func setEmptyInlineBlob(ctx context.Context, t *testing.T, metaDB *m.MetaDB, storeKey, recordKey string) *record.Record {
+	t.Helper()
+	r, err := metaDB.UpdateRecord(ctx, storeKey, recordKey, func(r *record.Record) (*record.Record, error) {
+		r.Blob = nil
+		r.BlobSize = 0
+		r.Checksums = checksums.NewDigest().Checksums()
+		return r, nil
+	})
+	require.NoError(t, err)
+	return r
+}
+
+func TestMetaDB_EmptyInlineBlobReplacesExternalBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	store, r, blob := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	_, _, err := metaDB.PromoteBlobRefToCurrent(ctx, blob)
+	require.NoError(t, err)
+
+	got := setEmptyInlineBlob(ctx, t, metaDB, store.Key, r.Key)
+	assert.Equal(t, uuid.Nil, got.ExternalBlob)
+	assert.True(t, got.HasInlineBlob())
+
+	old, err := metaDB.GetBlobRef(ctx, blob.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blobref.StatusPendingDeletion, old.Status)
+	}
+}
+
+func TestMetaDB_RemoveEmptyInlineBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	store, r, _ := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+
+	// A record without a blob has nothing to remove.
+	_, _, err := metaDB.RemoveBlobFromRecord(ctx, store.Key, r.Key)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	setEmptyInlineBlob(ctx, t, metaDB, store.Key, r.Key)
+	got, blob, err := metaDB.RemoveBlobFromRecord(ctx, store.Key, r.Key)
+	require.NoError(t, err)
+	assert.False(t, got.HasInlineBlob())
+	assert.Equal(t, new(blobref.BlobRef), blob)
+
+	reloaded, err := metaDB.GetRecord(ctx, store.Key, r.Key)
+	if assert.NoError(t, err) {
+		assert.False(t, reloaded.HasInlineBlob())
+	}
+}
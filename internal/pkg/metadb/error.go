@@ -15,11 +15,31 @@
 package metadb
 
 import (
+	"errors"
+
 	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// firstMultiError returns the first non-nil error of err if it is a
+// ds.MultiError, e.g. returned by Mutate or PutMulti for the entities that
+// failed, and err otherwise. ds.MultiError doesn't implement Unwrap, so its
+// errors must be extracted before they are converted to gRPC status.
+func firstMultiError(err error) error {
+	var merr ds.MultiError
+	if !errors.As(err, &merr) {
+		return err
+	}
+	for _, e := range merr {
+		if e != nil {
+			return e
+		}
+	}
+	return err
+}
+
 func datastoreErrToGRPCStatus(err error) (grpcErr error) {
 	switch err {
 	case nil:
@@ -36,6 +56,16 @@ func datastoreErrToGRPCStatus(err error) (grpcErr error) {
 	case ds.ErrInvalidKey:
 		grpcErr = status.Error(codes.InvalidArgument, err.Error())
 	default:
+		if errors.Is(err, record.ErrInvalidRecord) {
+			// Records are validated when saved, and invalid ones are the result
+			// of server bugs rather than bad requests.
+			grpcErr = status.Error(codes.Internal, err.Error())
+			break
+		}
+		if errors.Is(err, record.ErrEntityTooLarge) || errors.Is(err, record.ErrInvalidPropertyName) {
+			grpcErr = status.Error(codes.InvalidArgument, err.Error())
+			break
+		}
 		grpcErr = status.Convert(err).Err()
 	}
 	return
@@ -0,0 +1,36 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"errors"
+	"testing"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFirstMultiError(t *testing.T) {
+	err := firstMultiError(ds.MultiError{nil, record.ErrEntityTooLarge, errors.New("other")})
+	assert.ErrorIs(t, err, record.ErrEntityTooLarge)
+	assert.Equal(t, codes.InvalidArgument, status.Code(datastoreErrToGRPCStatus(err)))
+
+	plain := errors.New("plain")
+	assert.Equal(t, plain, firstMultiError(plain))
+	assert.Nil(t, firstMultiError(nil))
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"math"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EstimateStoreSize returns an estimate of the total Size of the BlobRefs of
+// the store, in any status, without reading all of them. It reads sampleSize
+// BlobRefs, counts all BlobRefs of the store with an aggregation query, and
+// scales the mean size of the sample by the count. If the store has sampleSize
+// BlobRefs or fewer, it returns the exact sum instead.
+//
+// The first sampleSize BlobRefs in key order are used as the sample, which is a
+// simple random sample of the store only if BlobRef keys are random UUIDs, the
+// default. With a time-ordered BlobKeyGenerator, such as blobref.NewUUIDv7Generator,
+// the sample is the oldest BlobRefs of the store, and the estimate is biased
+// towards their sizes and the bound below doesn't hold.
+// The sample is the same across calls until BlobRefs are added or deleted.
+// With N BlobRefs in the store, and s the standard deviation of their sizes,
+// the standard error of the estimate is
+//
+//	N * s / sqrt(sampleSize) * sqrt((N - sampleSize) / (N - 1))
+//
+// and the estimate is within 2 standard errors of the true total about 95% of
+// the time. Like StoreStats, the count is eventually consistent.
+// Returns InvalidArgument if sampleSize is not positive.
+func (m *MetaDB) EstimateStoreSize(ctx context.Context, storeKey string, sampleSize int) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.EstimateStoreSize")
+	defer span.End()
+
+	if sampleSize <= 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "sampleSize must be positive, got %d", sampleSize)
+	}
+	// Read one more than sampleSize to tell if the store is small.
+	var sample []*blobref.BlobRef
+	if _, err := m.client.GetAll(ctx, m.storeBlobsQuery(storeKey).Limit(sampleSize+1), &sample); err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	var sum int64
+	if len(sample) <= sampleSize {
+		for _, b := range sample {
+			sum += b.Size
+		}
+		return sum, nil
+	}
+	sample = sample[:sampleSize]
+	for _, b := range sample {
+		sum += b.Size
+	}
+
+	count, err := m.count(ctx, m.storeBlobsQuery(storeKey))
+	if status.Code(err) == codes.Unimplemented {
+		// Fall back to counting keys.
+		keys, kerr := m.client.GetAll(ctx, m.storeBlobsQuery(storeKey).KeysOnly(), nil)
+		count, err = int64(len(keys)), datastoreErrToGRPCStatus(kerr)
+	}
+	if err != nil {
+		return 0, err
+	}
+	mean := float64(sum) / float64(len(sample))
+	return int64(math.Round(mean * float64(count))), nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_EstimateStoreSize(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	const blobCount = 30
+	var total int64
+	for i := 0; i < blobCount; i++ {
+		size := int64(1000 + i)
+		setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(size, st.Key, r.Key))
+		total += size
+	}
+
+	estimate, err := metaDB.EstimateStoreSize(ctx, st.Key, 10)
+	require.NoError(t, err)
+	assert.InEpsilon(t, total, estimate, 0.05)
+
+	// The exact sum is returned if the sample covers the whole store.
+	for _, n := range []int{blobCount, blobCount + 1} {
+		estimate, err := metaDB.EstimateStoreSize(ctx, st.Key, n)
+		require.NoError(t, err)
+		assert.Equal(t, total, estimate)
+	}
+}
+
+func TestMetaDB_EstimateStoreSizeEmpty(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+
+	estimate, err := metaDB.EstimateStoreSize(ctx, st.Key, 10)
+	assert.NoError(t, err)
+	assert.Zero(t, estimate)
+
+	_, err = metaDB.EstimateStoreSize(ctx, st.Key, 0)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InsertExternalizedBlobRef inserts an Initializing BlobRef with key for the
+// inline blob of the record, so that the caller can write the inline content
+// to the blob object and promote the BlobRef with
+// PromoteBlobRefWithRecordUpdater. The size, checksums, and owner of the
+// BlobRef are copied from the record, and its content hash is calculated from
+// the inline blob. The record is not modified.
+// It returns the record and the new BlobRef.
+// Returned errors:
+//   - NotFound: the record was not found
+//   - FailedPrecondition: the record doesn't have an inline blob
+//   - InvalidArgument (ErrInvalidKey): the KeyValidator rejects storeKey or recordKey
+//   - AlreadyExists (ErrAlreadyExists): a blob with key already exists
+func (m *MetaDB) InsertExternalizedBlobRef(ctx context.Context, storeKey, recordKey string, key uuid.UUID) (*record.Record, *blobref.BlobRef, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.InsertExternalizedBlobRef")
+	defer span.End()
+
+	if key == uuid.Nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "the blob key must not be nil")
+	}
+	if err := m.validateKeys(storeKey, recordKey); err != nil {
+		return nil, nil, err
+	}
+	r := new(record.Record)
+	blob := m.NewBlobRef(0, storeKey, recordKey)
+	blob.Key = key
+	blob.PathEncoding = m.PathEncoding
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		if err := tx.Get(m.createRecordKey(storeKey, recordKey), r); err != nil {
+			return err
+		}
+		if r.ExternalBlob != uuid.Nil || !r.HasInlineBlob() {
+			return status.Errorf(codes.FailedPrecondition, "record (%v) doesn't have an inline blob to externalize", recordKey)
+		}
+		switch err := tx.Get(m.createBlobKey(key), new(blobref.BlobRef)); err {
+		case nil:
+			return ErrAlreadyExists
+		case ds.ErrNoSuchEntity:
+		default:
+			return err
+		}
+		blob.Size = int64(len(r.Blob))
+		blob.Checksums = r.Checksums
+		digest := checksums.NewDigestWithContentHash(m.ContentHashAlgorithm)
+		digest.Write(r.Blob)
+		blob.ContentHashAlgorithm, blob.ContentHash = digest.ContentHash()
+		blob.OwnerID = r.OwnerID
+		_, err := tx.Mutate(ds.NewInsert(m.createBlobKey(key), blob))
+		return err
+	})
+	if err != nil {
+		return nil, nil, datastoreErrToGRPCStatus(err)
+	}
+	return r, blob, nil
+}
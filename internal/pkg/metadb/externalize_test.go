@@ -0,0 +1,83 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_InsertExternalizedBlobRef(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	content := []byte("inline content")
+	digest := checksums.NewDigest()
+	digest.Write(content)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey()}, &record.Record{
+		Key:        newRecordKey(),
+		OwnerID:    "owner",
+		Blob:       content,
+		BlobSize:   int64(len(content)),
+		Checksums:  digest.Checksums(),
+		Properties: make(record.PropertyMap),
+	})
+
+	key := blobref.DeriveKey(st.Key, r.Key, "save.dat")
+	got, blob, err := metaDB.InsertExternalizedBlobRef(ctx, st.Key, r.Key, key)
+	require.NoError(t, err)
+	t.Cleanup(func() { newDatastoreClient(ctx, t).Delete(ctx, blobRefKey(key)) })
+	assert.Equal(t, content, got.Blob)
+	assert.Equal(t, key, blob.Key)
+	assert.Equal(t, blobref.StatusInitializing, blob.Status)
+	assert.Equal(t, int64(len(content)), blob.Size)
+	assert.Equal(t, digest.Checksums(), blob.Checksums)
+	assert.Equal(t, "owner", blob.OwnerID)
+
+	// The record is not modified until the blob is promoted.
+	unchanged, err := metaDB.GetRecord(ctx, st.Key, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, unchanged.ExternalBlob)
+	assert.Equal(t, content, unchanged.Blob)
+
+	// The same key collides with the existing blob.
+	_, _, err = metaDB.InsertExternalizedBlobRef(ctx, st.Key, r.Key, blobref.DeriveKey(st.Key, r.Key, "save.dat"))
+	assert.True(t, errors.Is(err, m.ErrAlreadyExists), "got %v, want %v", err, m.ErrAlreadyExists)
+
+	promoted, _, err := metaDB.PromoteBlobRefWithRecordUpdater(ctx, blob, got, func(r *record.Record) (*record.Record, error) {
+		return r, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, key, promoted.ExternalBlob)
+	assert.Empty(t, promoted.Blob)
+
+	// The record has no inline blob anymore.
+	_, _, err = metaDB.InsertExternalizedBlobRef(ctx, st.Key, r.Key, uuid.New())
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, _, err = metaDB.InsertExternalizedBlobRef(ctx, st.Key, newRecordKey(), uuid.New())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"errors"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// finalizeChunkBatchSize is the maximum number of ChunkRefs deleted in a
+// transaction by FinalizeChunkedBlob, leaving room for the BlobRef and the
+// record under the Datastore limit of 500 entities per commit.
+const finalizeChunkBatchSize = 400
+
+// FinalizeChunkedBlob turns the Ready chunked blob specified by blobKey into a
+// non-chunked blob after its chunks have been composed into a single object at
+// the ObjectPath of the BlobRef in bs. The composed object must exist and have
+// the size of the blob.
+//
+// In a transaction, the BlobRef and the record, if the blob is its current
+// blob, are marked as not chunked and their ChunkCount is cleared, and the
+// ChunkRefs are deleted. Blobs with more than finalizeChunkBatchSize chunks
+// have the remaining ChunkRefs deleted in subsequent transactions. The chunk
+// objects are deleted from bs after each transaction commits.
+//
+// FinalizeChunkedBlob is idempotent: for blobs that are already non-chunked,
+// it only deletes ChunkRefs left by an interrupted call.
+// Returned errors:
+//   - NotFound: the BlobRef is not found
+//   - FailedPrecondition: the blob is chunked but not Ready, or the composed
+//     object doesn't exist or has a different size
+//   - Internal: the metadata was updated but some chunk objects couldn't be deleted
+func (m *MetaDB) FinalizeChunkedBlob(ctx context.Context, bs blob.BlobStore, blobKey uuid.UUID) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.FinalizeChunkedBlob")
+	defer span.End()
+
+	b, err := m.getBlobRef(ctx, nil, blobKey)
+	if err != nil {
+		return err
+	}
+	if b.Chunked {
+		if err := checkComposedObject(ctx, bs, b); err != nil {
+			return err
+		}
+	}
+
+	for done := false; !done; {
+		err := m.RunWithDeferredBlobDeletes(ctx, bs, func(tx *ds.Transaction) ([]string, error) {
+			b, err := m.getBlobRef(ctx, tx, blobKey)
+			if err != nil {
+				return nil, err
+			}
+			if b.Chunked {
+				if err := m.unmarkChunked(ctx, tx, b); err != nil {
+					return nil, err
+				}
+			}
+			paths, err := m.deleteChunkRefBatch(ctx, tx, blobKey)
+			done = len(paths) < finalizeChunkBatchSize
+			return paths, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkComposedObject returns FailedPrecondition unless the object of b in bs
+// exists and has the size of b.
+func checkComposedObject(ctx context.Context, bs blob.BlobStore, b *blobref.BlobRef) error {
+	if b.Status != blobref.StatusReady {
+		return status.Errorf(codes.FailedPrecondition, "chunked BlobRef (%v) cannot be finalized in status (%v)", b.Key, b.Status)
+	}
+	attrs, err := blob.Stat(ctx, bs, b.ObjectPath())
+	if errors.Is(err, blob.ErrObjectNotFound) {
+		return status.Errorf(codes.FailedPrecondition, "the composed object of BlobRef (%v) doesn't exist", b.Key)
+	}
+	if err != nil {
+		return err
+	}
+	if attrs.Size != b.Size {
+		return status.Errorf(codes.FailedPrecondition, "the composed object of BlobRef (%v) has %v bytes, want %v",
+			b.Key, attrs.Size, b.Size)
+	}
+	return nil
+}
+
+// unmarkChunked clears Chunked and ChunkCount of b and of its record if b is
+// the current blob of the record in tx.
+func (m *MetaDB) unmarkChunked(ctx context.Context, tx *ds.Transaction, b *blobref.BlobRef) error {
+	if b.Status != blobref.StatusReady {
+		return status.Errorf(codes.FailedPrecondition, "chunked BlobRef (%v) cannot be finalized in status (%v)", b.Key, b.Status)
+	}
+	b.Chunked = false
+	b.ChunkCount = 0
+	if err := m.updateTimestamps(&b.Timestamps); err != nil {
+		return err
+	}
+	muts := []*ds.Mutation{ds.NewUpdate(m.createBlobKey(b.Key), b)}
+
+	rkey := m.createRecordKey(b.StoreKey, b.RecordKey)
+	r := new(record.Record)
+	err := tx.Get(rkey, r)
+	if err != nil && err != ds.ErrNoSuchEntity {
+		return err
+	}
+	if err == nil && r.ExternalBlob == b.Key {
+		r.Chunked = false
+		r.ChunkCount = 0
+		if err := m.updateTimestamps(&r.Timestamps); err != nil {
+			return err
+		}
+		entity, err := m.recordEntity(ctx, b.StoreKey, r)
+		if err != nil {
+			return err
+		}
+		muts = append(muts, ds.NewUpdate(rkey, entity))
+	}
+	_, err = tx.Mutate(muts...)
+	return firstMultiError(err)
+}
+
+// deleteChunkRefBatch deletes up to finalizeChunkBatchSize ChunkRefs of the
+// blob in tx and returns the paths of their objects.
+func (m *MetaDB) deleteChunkRefBatch(ctx context.Context, tx *ds.Transaction, blobKey uuid.UUID) ([]string, error) {
+	query := m.newQuery(chunkKind).Ancestor(m.createBlobKey(blobKey)).Transaction(tx).KeysOnly().Limit(finalizeChunkBatchSize)
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if err := tx.DeleteMulti(keys); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(keys))
+	for _, k := range keys {
+		// ChunkRef.ObjectPath is the key of the ChunkRef.
+		paths = append(paths, k.Name)
+	}
+	return paths, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setupComposedBlob creates a current chunked blob with two chunks and writes
+// the composed object of the chunks to bs.
+func setupComposedBlob(ctx context.Context, t *testing.T, metaDB *m.MetaDB, bs blob.BlobStore) (*record.Record, *blobref.BlobRef, []*chunkref.ChunkRef) {
+	t.Helper()
+	r, b := setupRepairBlob(ctx, t, metaDB, 2)
+	chunks := []*chunkref.ChunkRef{
+		uploadRepairChunk(ctx, t, metaDB, bs, b, 0),
+		uploadRepairChunk(ctx, t, metaDB, bs, b, 1),
+	}
+	_, b, err := metaDB.PromoteBlobRefToCurrent(ctx, b)
+	require.NoError(t, err)
+	require.NoError(t, bs.Put(ctx, b.ObjectPath(), make([]byte, 2*repairChunkSize)))
+	return r, b, chunks
+}
+
+func assertFinalized(ctx context.Context, t *testing.T, metaDB *m.MetaDB, bs blob.BlobStore, r *record.Record, b *blobref.BlobRef, chunks []*chunkref.ChunkRef) {
+	t.Helper()
+	got, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusReady, got.Status)
+	assert.False(t, got.Chunked)
+	assert.Zero(t, got.ChunkCount)
+	assert.Equal(t, int64(2*repairChunkSize), got.Size)
+
+	gotRecord, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, b.Key, gotRecord.ExternalBlob)
+	assert.False(t, gotRecord.Chunked)
+	assert.Zero(t, gotRecord.ChunkCount)
+
+	assert.Empty(t, chunkStatuses(ctx, t, metaDB, b), "the ChunkRefs must be deleted")
+	for _, c := range chunks {
+		exists, err := blob.Exists(ctx, bs, c.ObjectPath())
+		require.NoError(t, err)
+		assert.False(t, exists, "the chunk object (%v) must be deleted", c.ObjectPath())
+	}
+	exists, err := blob.Exists(ctx, bs, b.ObjectPath())
+	require.NoError(t, err)
+	assert.True(t, exists, "the composed object must be kept")
+}
+
+func TestMetaDB_FinalizeChunkedBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	r, b, chunks := setupComposedBlob(ctx, t, metaDB, bs)
+
+	require.NoError(t, metaDB.FinalizeChunkedBlob(ctx, bs, b.Key))
+	assertFinalized(ctx, t, metaDB, bs, r, b, chunks)
+
+	// Calling it again is a no-op.
+	require.NoError(t, metaDB.FinalizeChunkedBlob(ctx, bs, b.Key))
+	assertFinalized(ctx, t, metaDB, bs, r, b, chunks)
+}
+
+func TestMetaDB_FinalizeChunkedBlobPreconditions(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+
+	// The chunks are not composed yet.
+	_, b := setupRepairBlob(ctx, t, metaDB, 1)
+	uploadRepairChunk(ctx, t, metaDB, bs, b, 0)
+	_, b, err := metaDB.PromoteBlobRefToCurrent(ctx, b)
+	require.NoError(t, err)
+	err = metaDB.FinalizeChunkedBlob(ctx, bs, b.Key)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// The composed object is truncated.
+	require.NoError(t, bs.Put(ctx, b.ObjectPath(), make([]byte, 1)))
+	err = metaDB.FinalizeChunkedBlob(ctx, bs, b.Key)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	got, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.True(t, got.Chunked, "the blob must stay chunked")
+	assert.Len(t, chunkStatuses(ctx, t, metaDB, b), 1)
+
+	// The upload is not committed.
+	_, initializing := setupRepairBlob(ctx, t, metaDB, 1)
+	err = metaDB.FinalizeChunkedBlob(ctx, bs, initializing.Key)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	leaseKind = "lease"
+	// gcLeaseName is the key name of the lease held by the garbage collector.
+	gcLeaseName = "gc"
+)
+
+var (
+	// ErrGCLeaseHeld is returned by AcquireGCLease when another holder owns
+	// an unexpired garbage collection lease.
+	ErrGCLeaseHeld = status.Error(codes.FailedPrecondition, "the garbage collection lease is held by another holder")
+	// ErrGCLeaseInvalid is returned by ReleaseGCLease when the holder doesn't
+	// own the garbage collection lease, e.g. because it expired.
+	ErrGCLeaseInvalid = status.Error(codes.FailedPrecondition, "the garbage collection lease is not held by the holder")
+)
+
+// GCLease is the lease that a garbage collector process holds while it runs.
+type GCLease struct {
+	// Holder identifies the process that holds the lease.
+	Holder string `datastore:",noindex"`
+	// ExpiresAt is when the lease expires if it is not renewed, e.g. because
+	// the holder died.
+	ExpiresAt time.Time `datastore:",noindex"`
+}
+
+// Active returns true if the lease hasn't expired at now.
+func (l *GCLease) Active(now time.Time) bool {
+	return l.Holder != "" && now.Before(l.ExpiresAt)
+}
+
+func (m *MetaDB) createGCLeaseKey() *ds.Key {
+	k := ds.NameKey(leaseKind, gcLeaseName, nil)
+	k.Namespace = m.Namespace
+	return k
+}
+
+// AcquireGCLease acquires the garbage collection lease for holder that expires
+// after ttl, so that only one garbage collector acts at a time.
+// A holder renews its lease by calling AcquireGCLease again before it expires.
+// Returned errors:
+//   - InvalidArgument: holder is empty or ttl is not positive
+//   - FailedPrecondition (ErrGCLeaseHeld): another holder owns an unexpired lease
+func (m *MetaDB) AcquireGCLease(ctx context.Context, holder string, ttl time.Duration) (*GCLease, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.AcquireGCLease")
+	defer span.End()
+
+	if holder == "" {
+		return nil, status.Error(codes.InvalidArgument, "lease holder must not be empty")
+	}
+	if ttl <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "lease TTL must be positive")
+	}
+	lease := new(GCLease)
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		key := m.createGCLeaseKey()
+		if err := tx.Get(key, lease); err != nil && err != ds.ErrNoSuchEntity {
+			return err
+		}
+		now := time.Now()
+		if lease.Holder != holder && lease.Active(now) {
+			return ErrGCLeaseHeld
+		}
+		lease.Holder = holder
+		lease.ExpiresAt = now.Add(ttl)
+		return m.mutateSingleInTransaction(tx, ds.NewUpsert(key, lease))
+	})
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return lease, nil
+}
+
+// ReleaseGCLease releases the garbage collection lease of holder so that
+// another holder can acquire it immediately.
+// Returns FailedPrecondition (ErrGCLeaseInvalid) if holder doesn't hold the
+// lease, e.g. because it expired and was acquired by another holder.
+func (m *MetaDB) ReleaseGCLease(ctx context.Context, holder string) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ReleaseGCLease")
+	defer span.End()
+
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		key := m.createGCLeaseKey()
+		lease := new(GCLease)
+		if err := tx.Get(key, lease); err != nil {
+			if err == ds.ErrNoSuchEntity {
+				return ErrGCLeaseInvalid
+			}
+			return err
+		}
+		if holder == "" || lease.Holder != holder {
+			return ErrGCLeaseInvalid
+		}
+		return tx.Delete(key)
+	})
+	return datastoreErrToGRPCStatus(err)
+}
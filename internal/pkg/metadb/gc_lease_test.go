@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newGCLeaseMetaDB returns a MetaDB in a namespace of its own, as there is only
+// one garbage collection lease per namespace.
+func newGCLeaseMetaDB(ctx context.Context, t *testing.T) *m.MetaDB {
+	t.Helper()
+	metaDB := newMetaDB(ctx, t)
+	metaDB.Namespace = testNamespace + "-gclease-" + uuid.NewString()
+	return metaDB
+}
+
+func TestMetaDB_AcquireReleaseGCLease(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newGCLeaseMetaDB(ctx, t)
+
+	lease, err := metaDB.AcquireGCLease(ctx, "holder", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "holder", lease.Holder)
+	assert.True(t, lease.Active(time.Now()))
+
+	// The holder renews its own lease.
+	renewed, err := metaDB.AcquireGCLease(ctx, "holder", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, renewed.ExpiresAt.After(lease.ExpiresAt))
+
+	require.NoError(t, metaDB.ReleaseGCLease(ctx, "holder"))
+	assert.ErrorIs(t, metaDB.ReleaseGCLease(ctx, "holder"), m.ErrGCLeaseInvalid)
+
+	_, err = metaDB.AcquireGCLease(ctx, "other", time.Minute)
+	require.NoError(t, err)
+	assert.NoError(t, metaDB.ReleaseGCLease(ctx, "other"))
+}
+
+func TestMetaDB_GCLeaseHeld(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newGCLeaseMetaDB(ctx, t)
+
+	_, err := metaDB.AcquireGCLease(ctx, "holder", time.Minute)
+	require.NoError(t, err)
+	t.Cleanup(func() { metaDB.ReleaseGCLease(ctx, "holder") })
+
+	_, err = metaDB.AcquireGCLease(ctx, "other", time.Minute)
+	assert.ErrorIs(t, err, m.ErrGCLeaseHeld)
+	assert.ErrorIs(t, metaDB.ReleaseGCLease(ctx, "other"), m.ErrGCLeaseInvalid)
+}
+
+func TestMetaDB_ReacquireExpiredGCLease(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newGCLeaseMetaDB(ctx, t)
+
+	const ttl = 100 * time.Millisecond
+	_, err := metaDB.AcquireGCLease(ctx, "holder", ttl)
+	require.NoError(t, err)
+	time.Sleep(2 * ttl)
+
+	lease, err := metaDB.AcquireGCLease(ctx, "other", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "other", lease.Holder)
+	t.Cleanup(func() { metaDB.ReleaseGCLease(ctx, "other") })
+
+	// The previous holder can't release the lease it lost.
+	assert.ErrorIs(t, metaDB.ReleaseGCLease(ctx, "holder"), m.ErrGCLeaseInvalid)
+}
+
+func TestMetaDB_AcquireGCLeaseInvalidArguments(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newGCLeaseMetaDB(ctx, t)
+
+	_, err := metaDB.AcquireGCLease(ctx, "", time.Minute)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = metaDB.AcquireGCLease(ctx, "holder", 0)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
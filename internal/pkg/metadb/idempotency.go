@@ -0,0 +1,178 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	idempotencyTokenKind = "idempotencytoken"
+
+	// idempotencyTokenDeleteBatchSize is the number of tokens deleted per call
+	// to DeleteMulti.
+	idempotencyTokenDeleteBatchSize = 500
+)
+
+// idempotencyToken records the BlobRef created by the upload with the token.
+// It is a child entity of the record that the blob belongs to, keyed by the
+// token, so that a token only matches uploads to the same record.
+// ExpiresAt is indexed for ReapIdempotencyTokens.
+type idempotencyToken struct {
+	BlobKey   string `datastore:",noindex"`
+	ExpiresAt time.Time
+}
+
+func (m *MetaDB) createIdempotencyTokenKey(storeKey, recordKey, token string) *ds.Key {
+	k := ds.NameKey(idempotencyTokenKind, token, m.createRecordKey(storeKey, recordKey))
+	k.Namespace = m.Namespace
+	return k
+}
+
+// InsertBlobRefWithToken is the same as InsertBlobRef, but records blob against
+// the idempotency token for window, so that a retry of the upload can find it.
+// If the token was recorded for the record of blob within window and the
+// BlobRef of the first upload is still Initializing or Ready, it returns that
+// BlobRef and true without inserting blob. Otherwise, e.g. if the token expired
+// or the first upload failed, it inserts blob and returns it with false.
+func (m *MetaDB) InsertBlobRefWithToken(ctx context.Context, blob *blobref.BlobRef, token string, window time.Duration) (*blobref.BlobRef, bool, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.InsertBlobRefWithToken")
+	defer span.End()
+
+	if token == "" {
+		return nil, false, status.Error(codes.InvalidArgument, "idempotency token must not be empty")
+	}
+	if window <= 0 {
+		return nil, false, status.Error(codes.InvalidArgument, "idempotency window must be positive")
+	}
+	if err := m.validateKeys(blob.StoreKey, blob.RecordKey); err != nil {
+		return nil, false, err
+	}
+	var existing *blobref.BlobRef
+	tkey := m.createIdempotencyTokenKey(blob.StoreKey, blob.RecordKey, token)
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		existing = nil
+		now := time.Now()
+		var t idempotencyToken
+		switch err := tx.Get(tkey, &t); err {
+		case nil:
+			if now.Before(t.ExpiresAt) {
+				found, err := m.tokenBlobRef(tx, t.BlobKey)
+				if err != nil {
+					return err
+				}
+				if found != nil {
+					existing = found
+					return nil
+				}
+			}
+		case ds.ErrNoSuchEntity:
+		default:
+			return err
+		}
+		ts, err := m.newTimestamps()
+		if err != nil {
+			return err
+		}
+		blob.Timestamps = ts
+		if err := m.insertBlobRefInTransaction(tx, blob); err != nil {
+			return err
+		}
+		t = idempotencyToken{BlobKey: blob.Key.String(), ExpiresAt: now.Add(window)}
+		_, err = tx.Mutate(ds.NewUpsert(tkey, &t))
+		return err
+	})
+	if err != nil {
+		return nil, false, datastoreErrToGRPCStatus(err)
+	}
+	if existing != nil {
+		return existing, true, nil
+	}
+	return blob, false, nil
+}
+
+// tokenBlobRef returns the BlobRef recorded for a token if it is Initializing or
+// Ready, or nil if it doesn't exist or has any other status.
+func (m *MetaDB) tokenBlobRef(tx *ds.Transaction, blobKey string) (*blobref.BlobRef, error) {
+	key, err := uuid.Parse(blobKey)
+	if err != nil {
+		return nil, nil
+	}
+	blob := new(blobref.BlobRef)
+	if err := tx.Get(m.createBlobKey(key), blob); err != nil {
+		if err == ds.ErrNoSuchEntity {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if blob.Status != blobref.StatusInitializing && blob.Status != blobref.StatusReady {
+		return nil, nil
+	}
+	return blob, nil
+}
+
+// ReapIdempotencyTokens deletes the idempotency tokens of all records that
+// have expired at now, and returns the number of deleted tokens. Tokens saved
+// before ExpiresAt was indexed are not found.
+func (m *MetaDB) ReapIdempotencyTokens(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ReapIdempotencyTokens")
+	defer span.End()
+
+	query := m.newQuery(idempotencyTokenKind).KeysOnly().Filter("ExpiresAt <=", now)
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	return m.deleteIdempotencyTokens(ctx, keys)
+}
+
+// deleteRecordTokens deletes the idempotency tokens of the record of rkey,
+// after the record is deleted.
+func (m *MetaDB) deleteRecordTokens(ctx context.Context, rkey *ds.Key) error {
+	query := m.newQuery(idempotencyTokenKind).KeysOnly().Ancestor(rkey)
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	_, err = m.deleteIdempotencyTokens(ctx, keys)
+	return err
+}
+
+// deleteIdempotencyTokens deletes the tokens of keys in batches and returns
+// the number of deleted tokens.
+func (m *MetaDB) deleteIdempotencyTokens(ctx context.Context, keys []*ds.Key) (int, error) {
+	deleted := 0
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > idempotencyTokenDeleteBatchSize {
+			n = idempotencyTokenDeleteBatchSize
+		}
+		if err := m.entities.DeleteMulti(ctx, keys[:n]); err != nil {
+			return deleted, datastoreErrToGRPCStatus(err)
+		}
+		deleted += n
+		keys = keys[n:]
+	}
+	return deleted, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// insertBlobRefWithToken inserts a new BlobRef for the record with token and
+// deletes it on cleanup.
+func insertBlobRefWithToken(ctx context.Context, t *testing.T, metaDB *m.MetaDB,
+	storeKey, recordKey, token string, window time.Duration) (*blobref.BlobRef, bool) {
+	t.Helper()
+	blob := blobref.NewBlobRef(0, storeKey, recordKey)
+	got, found, err := metaDB.InsertBlobRefWithToken(ctx, blob, token, window)
+	require.NoError(t, err)
+	if !found {
+		t.Cleanup(func() { metaDB.DeleteBlobRef(ctx, got.Key) })
+	}
+	return got, found
+}
+
+func setupIdempotencyRecord(ctx context.Context, t *testing.T, metaDB *m.MetaDB) (string, string) {
+	t.Helper()
+	storeKey := newStoreKey()
+	recordKey := newRecordKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()},
+		&record.Record{Key: recordKey, Properties: make(record.PropertyMap)})
+	return storeKey, recordKey
+}
+
+func TestMetaDB_InsertBlobRefWithToken(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, recordKey := setupIdempotencyRecord(ctx, t, metaDB)
+	token := uuid.NewString()
+
+	first, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	assert.False(t, found)
+	got, err := metaDB.GetBlobRef(ctx, first.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusInitializing, got.Status)
+
+	// A retry while the first upload is in progress finds it.
+	retry, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	assert.True(t, found)
+	assert.Equal(t, first.Key, retry.Key)
+
+	require.NoError(t, first.Ready())
+	_, err = metaDB.UpdateBlobRef(ctx, first)
+	require.NoError(t, err)
+	retry, found = insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	assert.True(t, found)
+	assert.Equal(t, first.Key, retry.Key)
+	assert.Equal(t, blobref.StatusReady, retry.Status)
+
+	// The token only matches uploads to the same record.
+	otherRecord := newRecordKey()
+	setupTestRecord(ctx, t, metaDB, storeKey, &record.Record{Key: otherRecord, Properties: make(record.PropertyMap)})
+	other, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, otherRecord, token, time.Hour)
+	assert.False(t, found)
+	assert.NotEqual(t, first.Key, other.Key)
+}
+
+func TestMetaDB_InsertBlobRefWithExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, recordKey := setupIdempotencyRecord(ctx, t, metaDB)
+	token := uuid.NewString()
+
+	const window = 100 * time.Millisecond
+	first, _ := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, window)
+	time.Sleep(2 * window)
+
+	second, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	assert.False(t, found)
+	assert.NotEqual(t, first.Key, second.Key)
+
+	// The token now refers to the new blob.
+	retry, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	assert.True(t, found)
+	assert.Equal(t, second.Key, retry.Key)
+}
+
+func TestMetaDB_InsertBlobRefWithTokenAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, recordKey := setupIdempotencyRecord(ctx, t, metaDB)
+	token := uuid.NewString()
+
+	first, _ := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	first.Fail()
+	_, err := metaDB.UpdateBlobRef(ctx, first)
+	require.NoError(t, err)
+
+	second, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	assert.False(t, found, "a failed upload must be retried")
+	assert.NotEqual(t, first.Key, second.Key)
+}
+
+func TestMetaDB_ReapIdempotencyTokens(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, recordKey := setupIdempotencyRecord(ctx, t, metaDB)
+
+	const window = 100 * time.Millisecond
+	insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, uuid.NewString(), window)
+	live := uuid.NewString()
+	first, _ := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, live, time.Hour)
+	time.Sleep(2 * window)
+
+	n, err := metaDB.ReapIdempotencyTokens(ctx, time.Now())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+	retry, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, live, time.Hour)
+	assert.True(t, found, "tokens within the window must be kept")
+	assert.Equal(t, first.Key, retry.Key)
+}
+
+func TestMetaDB_DeleteRecordDeletesIdempotencyTokens(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, recordKey := setupIdempotencyRecord(ctx, t, metaDB)
+	token := uuid.NewString()
+	first, _ := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+
+	require.NoError(t, metaDB.DeleteRecord(ctx, storeKey, recordKey))
+	setupTestRecord(ctx, t, metaDB, storeKey, &record.Record{Key: recordKey, Properties: make(record.PropertyMap)})
+	second, found := insertBlobRefWithToken(ctx, t, metaDB, storeKey, recordKey, token, time.Hour)
+	assert.False(t, found, "the token must be deleted with the record")
+	assert.NotEqual(t, first.Key, second.Key)
+}
+
+func TestMetaDB_InsertBlobRefWithTokenInvalidArguments(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	blob := blobref.NewBlobRef(0, newStoreKey(), newRecordKey())
+	_, _, err := metaDB.InsertBlobRefWithToken(ctx, blob, "", time.Hour)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, _, err = metaDB.InsertBlobRefWithToken(ctx, blob, "token", 0)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"math"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IncrementProperty atomically adds delta to the integer property propName of
+// the record and returns the new value. A negative delta decrements the property.
+// The property is created with delta as the value if the record doesn't have it.
+// Returned errors:
+//   - NotFound: the record is not found
+//   - InvalidArgument: the property is not an integer
+//   - OutOfRange: the new value overflows int64
+func (m *MetaDB) IncrementProperty(ctx context.Context, storeKey, recordKey, propName string, delta int64) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.IncrementProperty")
+	defer span.End()
+
+	var newValue int64
+	_, err := m.UpdateRecord(ctx, storeKey, recordKey, func(r *record.Record) (*record.Record, error) {
+		p, ok := r.Properties[propName]
+		if !ok {
+			newValue = delta
+			r.Properties[propName] = &record.PropertyValue{Type: pb.Property_INTEGER, IntegerValue: delta}
+			return r, nil
+		}
+		if p.Type != pb.Property_INTEGER {
+			return nil, status.Errorf(codes.InvalidArgument, "the value type of property (%v) was not integer: %v",
+				propName, p.Type.String())
+		}
+		if (delta > 0 && p.IntegerValue > math.MaxInt64-delta) || (delta < 0 && p.IntegerValue < math.MinInt64-delta) {
+			return nil, status.Errorf(codes.OutOfRange, "adding %v to property (%v) overflows: current value = %v",
+				delta, propName, p.IntegerValue)
+		}
+		p.IntegerValue += delta
+		newValue = p.IntegerValue
+		return r, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newValue, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupIncrementRecord(ctx context.Context, t *testing.T, metaDB *m.MetaDB) *record.Record {
+	t.Helper()
+	_, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{
+			Key: newRecordKey(),
+			Properties: record.PropertyMap{
+				"score": {Type: pb.Property_INTEGER, IntegerValue: 10},
+				"name":  {Type: pb.Property_STRING, StringValue: "player"},
+			},
+		})
+	return r
+}
+
+func assertIntProperty(ctx context.Context, t *testing.T, metaDB *m.MetaDB, r *record.Record, name string, want int64) {
+	t.Helper()
+	got, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+	if assert.Contains(t, got.Properties, name) {
+		assert.Equal(t, pb.Property_INTEGER, got.Properties[name].Type)
+		assert.Equal(t, want, got.Properties[name].IntegerValue)
+	}
+}
+
+func TestMetaDB_IncrementProperty(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+
+	v, err := metaDB.IncrementProperty(ctx, r.StoreKey, r.Key, "score", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), v)
+	assertIntProperty(ctx, t, metaDB, r, "score", 15)
+
+	v, err = metaDB.IncrementProperty(ctx, r.StoreKey, r.Key, "score", -20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-5), v)
+	assertIntProperty(ctx, t, metaDB, r, "score", -5)
+}
+
+func TestMetaDB_IncrementPropertyCreatesAbsent(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+
+	v, err := metaDB.IncrementProperty(ctx, r.StoreKey, r.Key, "kills", 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+	assertIntProperty(ctx, t, metaDB, r, "kills", 3)
+	assertIntProperty(ctx, t, metaDB, r, "score", 10)
+}
+
+func TestMetaDB_IncrementPropertyErrors(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+
+	_, err := metaDB.IncrementProperty(ctx, r.StoreKey, r.Key, "name", 1)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = metaDB.IncrementProperty(ctx, r.StoreKey, r.Key, "score", math.MaxInt64)
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	assertIntProperty(ctx, t, metaDB, r, "score", 10)
+
+	_, err = metaDB.IncrementProperty(ctx, r.StoreKey, newRecordKey(), "score", 1)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMetaDB_IncrementPropertyStampsModifiedTime(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+	created, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+
+	_, err = metaDB.IncrementProperty(ctx, r.StoreKey, r.Key, "score", 1)
+	require.NoError(t, err)
+	got, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+
+	assert.Equal(t, got.Timestamps.UpdatedAt, got.PropertyModifiedAt("score"))
+	assert.Equal(t, created.Timestamps.UpdatedAt, got.PropertyModifiedAt("name"))
+	assert.Equal(t, []string{"score"}, got.ChangedPropertiesSince(created.Timestamps.UpdatedAt))
+}
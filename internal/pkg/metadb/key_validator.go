@@ -0,0 +1,146 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxKeyLength is the maximum byte length of store and record keys allowed
+// by SafeKeyValidator.
+const MaxKeyLength = 256
+
+// MaxPermissiveKeyLength is the maximum byte length of store and record keys
+// allowed by PermissiveKeyValidator, which is the limit of Datastore key names.
+const MaxPermissiveKeyLength = 1500
+
+// ErrInvalidKey is wrapped by the errors returned for store and record keys
+// that are rejected by the KeyValidator of MetaDB.
+var ErrInvalidKey = status.Error(codes.InvalidArgument, "invalid key")
+
+// KeyValidator checks a client supplied store or record key before a store,
+// record, or blob is created with it. It returns nil if key is valid.
+type KeyValidator func(key string) error
+
+// Assert the validators are KeyValidators.
+var (
+	_ KeyValidator = SafeKeyValidator
+	_ KeyValidator = PermissiveKeyValidator
+)
+
+// SafeKeyValidator is the default KeyValidator of MetaDB. It allows keys of 1
+// to MaxKeyLength bytes that consist of ASCII letters, digits, and the
+// characters "-_.~:@+=". Keys that Datastore reserves (__*__) and the path
+// segments "." and ".." are rejected, so that valid keys can be used in
+// Datastore keys and object paths as they are.
+// It returns an error wrapping ErrInvalidKey.
+func SafeKeyValidator(key string) error {
+	if err := PermissiveKeyValidator(key); err != nil {
+		return err
+	}
+	switch {
+	case len(key) > MaxKeyLength:
+		return fmt.Errorf("%w: the key is %d bytes, longer than the maximum of %d", ErrInvalidKey, len(key), MaxKeyLength)
+	case key == "." || key == "..":
+		return fmt.Errorf("%w: %q is a reserved path segment", ErrInvalidKey, key)
+	}
+	for i := 0; i < len(key); i++ {
+		if !isSafeKeyChar(key[i]) {
+			return fmt.Errorf("%w: %q has an illegal character %q at %d", ErrInvalidKey, key, key[i], i)
+		}
+	}
+	return nil
+}
+
+func isSafeKeyChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-_.~:@+=", c) >= 0
+}
+
+// PermissiveKeyValidator only rejects the keys that Datastore can't store:
+// empty keys, keys longer than MaxPermissiveKeyLength bytes, and reserved keys
+// (__*__). It is for deployments with existing keys that SafeKeyValidator
+// rejects (see the record_key_permissive setting). Its keys may have slashes
+// and other characters that are special in object paths.
+// It returns an error wrapping ErrInvalidKey.
+func PermissiveKeyValidator(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: the key is empty", ErrInvalidKey)
+	case len(key) > MaxPermissiveKeyLength:
+		return fmt.Errorf("%w: the key is %d bytes, longer than the maximum of %d", ErrInvalidKey, len(key), MaxPermissiveKeyLength)
+	case len(key) >= 4 && strings.HasPrefix(key, "__") && strings.HasSuffix(key, "__"):
+		return fmt.Errorf("%w: %q is reserved by Datastore", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// NewKeyValidator returns a KeyValidator that tightens SafeKeyValidator, or
+// PermissiveKeyValidator if permissive is true, for stricter deployments. Keys
+// must be at most maxLength bytes long if maxLength is positive, and fully
+// match pattern if it is not empty. The limits of the base validator always
+// apply, even if maxLength is larger.
+func NewKeyValidator(maxLength int, pattern string, permissive bool) (KeyValidator, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile("^(?:" + pattern + ")$"); err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+	}
+	base := SafeKeyValidator
+	if permissive {
+		base = PermissiveKeyValidator
+	}
+	return func(key string) error {
+		if err := base(key); err != nil {
+			return err
+		}
+		if maxLength > 0 && len(key) > maxLength {
+			return fmt.Errorf("%w: the key is %d bytes, longer than the maximum of %d", ErrInvalidKey, len(key), maxLength)
+		}
+		if re != nil && !re.MatchString(key) {
+			return fmt.Errorf("%w: %q doesn't match the pattern %q", ErrInvalidKey, key, pattern)
+		}
+		return nil
+	}, nil
+}
+
+// validateKeys checks keys with the KeyValidator of m, or SafeKeyValidator
+// if it is not set. The returned error always wraps ErrInvalidKey.
+func (m *MetaDB) validateKeys(keys ...string) error {
+	validate := m.KeyValidator
+	if validate == nil {
+		validate = SafeKeyValidator
+	}
+	for _, key := range keys {
+		if err := validate(key); err != nil {
+			if !errors.Is(err, ErrInvalidKey) {
+				err = fmt.Errorf("%w: %q: %v", ErrInvalidKey, key, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSafeKeyValidator_Valid(t *testing.T) {
+	for _, key := range []string{
+		"a",
+		"397f94f5-f851-4969-8bd8-7828abc473a6",
+		"player:1@global",
+		"save_slot.2~backup+v=3",
+		strings.Repeat("k", m.MaxKeyLength),
+	} {
+		assert.NoError(t, m.SafeKeyValidator(key), key)
+	}
+}
+
+func TestSafeKeyValidator_Invalid(t *testing.T) {
+	for _, key := range []string{
+		"",
+		"store/record",
+		"has space",
+		"unicode-é",
+		"new\nline",
+		".",
+		"..",
+		"__reserved__",
+		strings.Repeat("k", m.MaxKeyLength+1),
+	} {
+		err := m.SafeKeyValidator(key)
+		assert.ErrorIs(t, err, m.ErrInvalidKey, key)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), key)
+	}
+}
+
+func TestPermissiveKeyValidator_Valid(t *testing.T) {
+	for _, key := range []string{
+		"a",
+		"397f94f5-f851-4969-8bd8-7828abc473a6",
+		"store/record",
+		"has space",
+		"unicode-é",
+		"..",
+		strings.Repeat("k", m.MaxPermissiveKeyLength),
+	} {
+		assert.NoError(t, m.PermissiveKeyValidator(key), key)
+	}
+}
+
+func TestPermissiveKeyValidator_Invalid(t *testing.T) {
+	for _, key := range []string{
+		"",
+		"__reserved__",
+		strings.Repeat("k", m.MaxPermissiveKeyLength+1),
+	} {
+		err := m.PermissiveKeyValidator(key)
+		assert.ErrorIs(t, err, m.ErrInvalidKey, key)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), key)
+	}
+}
+
+func TestNewKeyValidator(t *testing.T) {
+	validate, err := m.NewKeyValidator(8, "[a-z0-9-/]+", false)
+	require.NoError(t, err)
+	assert.NoError(t, validate("abc-123"))
+	assert.ErrorIs(t, validate("ABC"), m.ErrInvalidKey, "the pattern must match")
+	assert.ErrorIs(t, validate("abc-12345"), m.ErrInvalidKey, "the key is too long")
+	assert.ErrorIs(t, validate("a/b"), m.ErrInvalidKey, "the safe charset still applies")
+
+	// The pattern matches whole keys.
+	assert.ErrorIs(t, validate("abc.def"), m.ErrInvalidKey)
+
+	// A larger maximum doesn't loosen the base validator.
+	validate, err = m.NewKeyValidator(m.MaxKeyLength*2, "", false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, validate(strings.Repeat("k", m.MaxKeyLength+1)), m.ErrInvalidKey)
+
+	validate, err = m.NewKeyValidator(8, "[a-z0-9-/]+", true)
+	require.NoError(t, err)
+	assert.NoError(t, validate("a/b"), "the permissive charset is not restricted")
+	assert.ErrorIs(t, validate("abc-12345"), m.ErrInvalidKey, "the key is too long")
+
+	_, err = m.NewKeyValidator(0, "[", false)
+	assert.Error(t, err)
+}
+
+func TestMetaDB_CreateStoreInvalidKey(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	_, err := metaDB.CreateStore(ctx, &store.Store{Key: "store/" + newStoreKey(), Name: t.Name()})
+	assert.ErrorIs(t, err, m.ErrInvalidKey, "SafeKeyValidator is the default")
+	_, err = metaDB.CreateStore(ctx, &store.Store{Key: strings.Repeat("s", m.MaxKeyLength+1), Name: t.Name()})
+	assert.ErrorIs(t, err, m.ErrInvalidKey)
+
+	metaDB.KeyValidator = m.PermissiveKeyValidator
+	_, err = metaDB.CreateStore(ctx, &store.Store{Key: "__" + newStoreKey() + "__", Name: t.Name()})
+	assert.ErrorIs(t, err, m.ErrInvalidKey)
+}
+
+func TestMetaDB_InsertBlobRefInvalidKey(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	// The record was created before the KeyValidator was tightened.
+	metaDB.KeyValidator = m.PermissiveKeyValidator
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: "legacy key " + newRecordKey(), Properties: make(record.PropertyMap)})
+	metaDB.KeyValidator = nil
+
+	_, err := metaDB.InsertBlobRef(ctx, blobref.NewBlobRef(0, st.Key, r.Key))
+	assert.ErrorIs(t, err, m.ErrInvalidKey)
+	_, _, err = metaDB.InsertBlobRefWithToken(ctx, blobref.NewBlobRef(0, st.Key, r.Key), "token", time.Minute)
+	assert.ErrorIs(t, err, m.ErrInvalidKey)
+
+	metaDB.KeyValidator = m.PermissiveKeyValidator
+	blob, err := metaDB.InsertBlobRef(ctx, blobref.NewBlobRef(0, st.Key, r.Key))
+	require.NoError(t, err)
+	t.Cleanup(func() { metaDB.DeleteBlobRef(ctx, blob.Key) })
+}
+
+func TestMetaDB_CustomKeyValidator(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+
+	metaDB.KeyValidator = func(key string) error {
+		if strings.HasPrefix(key, "tmp") {
+			return errors.New("temporary keys are not allowed")
+		}
+		return nil
+	}
+	_, err := metaDB.InsertRecord(ctx, st.Key, &record.Record{Key: "tmp" + newRecordKey(), Properties: make(record.PropertyMap)})
+	assert.ErrorIs(t, err, m.ErrInvalidKey, "errors of custom validators wrap ErrInvalidKey")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "temporary keys are not allowed")
+
+	// The custom validator replaces the default.
+	setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: "has space " + newRecordKey(), Properties: make(record.PropertyMap)})
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AcquireRecordLease acquires a lease on the record for exclusive edits that
+// expires after ttl, and returns the lease ID.
+// Acquiring a lease doesn't change the timestamps of the record.
+// Returned errors:
+//   - NotFound: the record doesn't exist
+//   - FailedPrecondition (ErrLeaseHeld): an unexpired lease is held on the record
+func (m *MetaDB) AcquireRecordLease(ctx context.Context, storeKey, recordKey string, ttl time.Duration) (string, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.AcquireRecordLease")
+	defer span.End()
+
+	if ttl <= 0 {
+		return "", status.Error(codes.InvalidArgument, "lease TTL must be positive")
+	}
+	leaseID := uuid.NewString()
+	err := m.mutateRecordLease(ctx, storeKey, recordKey, func(r *record.Record, now time.Time) error {
+		if r.LeaseActive(now) {
+			return ErrLeaseHeld
+		}
+		r.LeaseID = leaseID
+		r.LeaseExpiresAt = now.Add(ttl)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return leaseID, nil
+}
+
+// ReleaseRecordLease releases the lease leaseID on the record so that others can
+// acquire a new lease immediately. Releasing an expired lease succeeds as long as
+// no one else has acquired the record since.
+// Returns FailedPrecondition (ErrLeaseInvalid) if leaseID is not the current lease.
+func (m *MetaDB) ReleaseRecordLease(ctx context.Context, storeKey, recordKey, leaseID string) error {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ReleaseRecordLease")
+	defer span.End()
+
+	return m.mutateRecordLease(ctx, storeKey, recordKey, func(r *record.Record, now time.Time) error {
+		if leaseID == "" || r.LeaseID != leaseID {
+			return ErrLeaseInvalid
+		}
+		r.LeaseID = ""
+		r.LeaseExpiresAt = time.Time{}
+		return nil
+	})
+}
+
+// UpdateRecordWithLease is the same as UpdateRecord but requires leaseID to be
+// an unexpired lease on the record.
+// Returns FailedPrecondition (ErrLeaseInvalid) otherwise.
+func (m *MetaDB) UpdateRecordWithLease(ctx context.Context, storeKey, key, leaseID string, updater RecordUpdater) (*record.Record, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.UpdateRecordWithLease")
+	defer span.End()
+
+	if leaseID == "" {
+		return nil, ErrLeaseInvalid
+	}
+	return m.updateRecord(ctx, storeKey, key, leaseID, updater)
+}
+
+// checkRecordLease returns ErrLeaseInvalid if leaseID is not empty and isn't
+// the unexpired lease on r, and ErrLeaseHeld if leaseID is empty and a lease
+// is held on r, so that holders of a lease edit the record exclusively.
+// Writes that bypass the record, such as garbage collection of expired
+// records, are not checked.
+func checkRecordLease(r *record.Record, leaseID string, now time.Time) error {
+	if leaseID != "" {
+		if r.LeaseID != leaseID || !r.LeaseActive(now) {
+			return ErrLeaseInvalid
+		}
+		return nil
+	}
+	if r.LeaseActive(now) {
+		return ErrLeaseHeld
+	}
+	return nil
+}
+
+// mutateRecordLease runs fn for the record in a transaction and saves the record
+// if fn succeeds.
+func (m *MetaDB) mutateRecordLease(ctx context.Context, storeKey, recordKey string, fn func(r *record.Record, now time.Time) error) error {
+	rkey := m.createRecordKey(storeKey, recordKey)
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		r := new(record.Record)
+		if err := tx.Get(rkey, r); err != nil {
+			return err
+		}
+		if err := fn(r, time.Now()); err != nil {
+			return err
+		}
+		entity, err := m.recordEntity(ctx, storeKey, r)
+		if err != nil {
+			return err
+		}
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity))
+	})
+	return datastoreErrToGRPCStatus(err)
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRecordLease(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	leased := &record.Record{LeaseID: "lease", LeaseExpiresAt: now.Add(time.Minute)}
+	expired := &record.Record{LeaseID: "lease", LeaseExpiresAt: now.Add(-time.Minute)}
+	unleased := new(record.Record)
+
+	assert.ErrorIs(t, checkRecordLease(leased, "", now), ErrLeaseHeld)
+	assert.NoError(t, checkRecordLease(leased, "lease", now))
+	assert.ErrorIs(t, checkRecordLease(leased, "other", now), ErrLeaseInvalid)
+
+	assert.NoError(t, checkRecordLease(expired, "", now))
+	assert.ErrorIs(t, checkRecordLease(expired, "lease", now), ErrLeaseInvalid)
+
+	assert.NoError(t, checkRecordLease(unleased, "", now))
+	assert.ErrorIs(t, checkRecordLease(unleased, "lease", now), ErrLeaseInvalid)
+}
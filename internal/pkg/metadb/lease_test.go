@@ -0,0 +1,161 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupLeaseRecord(ctx context.Context, t *testing.T, metaDB *m.MetaDB) *record.Record {
+	t.Helper()
+	_, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	// Release the lease left by the test so that the record can be deleted.
+	t.Cleanup(func() {
+		if got, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key); err == nil && got.LeaseID != "" {
+			metaDB.ReleaseRecordLease(ctx, r.StoreKey, r.Key, got.LeaseID)
+		}
+	})
+	return r
+}
+
+func TestMetaDB_AcquireAndReleaseRecordLease(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupLeaseRecord(ctx, t, metaDB)
+
+	leaseID, err := metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, time.Minute)
+	require.NoError(t, err)
+	assert.NotEmpty(t, leaseID)
+
+	got, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, leaseID, got.LeaseID)
+	assert.True(t, got.LeaseActive(time.Now()))
+	assert.Equal(t, r.Timestamps.Signature, got.Timestamps.Signature,
+		"AcquireRecordLease should not update the timestamps")
+
+	// A second holder cannot acquire the lease while it's held.
+	_, err = metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, time.Minute)
+	assert.ErrorIs(t, err, m.ErrLeaseHeld)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	assert.ErrorIs(t, metaDB.ReleaseRecordLease(ctx, r.StoreKey, r.Key, "wrong"), m.ErrLeaseInvalid)
+	require.NoError(t, metaDB.ReleaseRecordLease(ctx, r.StoreKey, r.Key, leaseID))
+	got, err = metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+	assert.Empty(t, got.LeaseID)
+	assert.True(t, got.LeaseExpiresAt.IsZero())
+
+	// The record can be leased again after release.
+	newLeaseID, err := metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, leaseID, newLeaseID)
+	assert.ErrorIs(t, metaDB.ReleaseRecordLease(ctx, r.StoreKey, r.Key, leaseID), m.ErrLeaseInvalid)
+}
+
+func TestMetaDB_ReacquireExpiredRecordLease(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupLeaseRecord(ctx, t, metaDB)
+
+	const ttl = 100 * time.Millisecond
+	leaseID, err := metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, ttl)
+	require.NoError(t, err)
+	time.Sleep(2 * ttl)
+
+	newLeaseID, err := metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, leaseID, newLeaseID)
+	_, err = metaDB.UpdateRecordWithLease(ctx, r.StoreKey, r.Key, leaseID,
+		func(r *record.Record) (*record.Record, error) { return r, nil })
+	assert.ErrorIs(t, err, m.ErrLeaseInvalid)
+}
+
+func TestMetaDB_UpdateRecordWithLease(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupLeaseRecord(ctx, t, metaDB)
+	updater := func(r *record.Record) (*record.Record, error) {
+		r.OpaqueString = "updated"
+		return r, nil
+	}
+
+	// Updates require a lease.
+	_, err := metaDB.UpdateRecordWithLease(ctx, r.StoreKey, r.Key, "", updater)
+	assert.ErrorIs(t, err, m.ErrLeaseInvalid)
+
+	leaseID, err := metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, time.Minute)
+	require.NoError(t, err)
+	_, err = metaDB.UpdateRecordWithLease(ctx, r.StoreKey, r.Key, "wrong", updater)
+	assert.ErrorIs(t, err, m.ErrLeaseInvalid)
+
+	got, err := metaDB.UpdateRecordWithLease(ctx, r.StoreKey, r.Key, leaseID, updater)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", got.OpaqueString)
+	assert.Equal(t, leaseID, got.LeaseID, "the lease should be kept after the update")
+}
+
+func TestMetaDB_RecordLeaseBlocksOtherWriters(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupLeaseRecord(ctx, t, metaDB)
+	updater := func(r *record.Record) (*record.Record, error) {
+		r.OpaqueString = "updated"
+		return r, nil
+	}
+
+	leaseID, err := metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, time.Minute)
+	require.NoError(t, err)
+	_, err = metaDB.UpdateRecord(ctx, r.StoreKey, r.Key, updater)
+	assert.ErrorIs(t, err, m.ErrLeaseHeld)
+	_, err = metaDB.UpdateRecords(ctx, r.StoreKey, map[string]m.RecordUpdater{r.Key: updater}, m.UpdateRecordsOptions{})
+	assert.ErrorIs(t, err, m.ErrLeaseHeld)
+	assert.ErrorIs(t, metaDB.DeleteRecord(ctx, r.StoreKey, r.Key), m.ErrLeaseHeld)
+	got, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+	assert.Empty(t, got.OpaqueString)
+
+	_, err = metaDB.UpdateRecordWithLease(ctx, r.StoreKey, r.Key, leaseID, updater)
+	require.NoError(t, err)
+
+	// Writers don't need the lease after it is released.
+	require.NoError(t, metaDB.ReleaseRecordLease(ctx, r.StoreKey, r.Key, leaseID))
+	_, err = metaDB.UpdateRecord(ctx, r.StoreKey, r.Key, updater)
+	assert.NoError(t, err)
+	assert.NoError(t, metaDB.DeleteRecord(ctx, r.StoreKey, r.Key))
+}
+
+func TestMetaDB_AcquireRecordLeaseErrors(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupLeaseRecord(ctx, t, metaDB)
+
+	_, err := metaDB.AcquireRecordLease(ctx, r.StoreKey, newRecordKey(), time.Minute)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = metaDB.AcquireRecordLease(ctx, r.StoreKey, r.Key, 0)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, codes.NotFound, status.Code(metaDB.ReleaseRecordLease(ctx, r.StoreKey, newRecordKey(), "lease")))
+}
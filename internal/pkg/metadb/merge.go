@@ -0,0 +1,276 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MergeStrategy decides which record wins when both records of MergeRecords
+// have the same property or a blob.
+type MergeStrategy int
+
+const (
+	// MergeDstWins keeps the properties and the blob of the destination record.
+	MergeDstWins MergeStrategy = iota
+	// MergeSrcWins overwrites the properties and the blob of the destination
+	// record with those of the source record.
+	MergeSrcWins
+)
+
+// mergeBlobRefBatchSize is the number of BlobRefs reassigned in a transaction
+// of MergeRecords, leaving room for the records and the current blobs within
+// the limit of 500 entities per transaction.
+const mergeBlobRefBatchSize = 400
+
+// MergeRecords merges the record with srcKey into the record with dstKey in
+// the store, e.g. to consolidate duplicate player accounts:
+//   - Tags are the union of the tags of both records.
+//   - Properties of both records are combined. Properties that exist in both
+//     records are taken from the winner of strategy.
+//   - The blob of the winner becomes the blob of the merged record, or the
+//     blob of the other record if the winner doesn't have one. The other
+//     external blob is marked for deletion.
+//   - All BlobRefs of the source record are reassigned to the destination
+//     record, and their OwnerID is set to the owner of the destination record.
+//   - The source record is deleted, and then its idempotency tokens and
+//     sharded counters.
+//
+// Other fields, including the owner, are kept from the destination record.
+// All of the above happens in a single transaction as long as the source
+// record has up to mergeBlobRefBatchSize BlobRefs. The BlobRefs beyond that
+// are reassigned in subsequent transactions on a best-effort basis: if they
+// fail, MergeRecords returns the error after the records were merged, and the
+// remaining BlobRefs are left pointing to the deleted source record, where the
+// garbage collector finds them as dangling.
+// Returned errors:
+//   - InvalidArgument: dstKey and srcKey are the same, or strategy is unknown
+//   - NotFound: either record was not found
+func (m *MetaDB) MergeRecords(ctx context.Context, storeKey, dstKey, srcKey string, strategy MergeStrategy) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.MergeRecords")
+	defer span.End()
+
+	if dstKey == srcKey {
+		return status.Error(codes.InvalidArgument, "MergeRecords: dstKey and srcKey must be different")
+	}
+	if strategy != MergeDstWins && strategy != MergeSrcWins {
+		return status.Errorf(codes.InvalidArgument, "MergeRecords: unknown strategy (%v)", strategy)
+	}
+
+	// Queries inside a transaction must be ancestor queries, and BlobRefs are
+	// root entities, so find the keys first and re-read them in the transaction.
+	query := m.newQuery(blobKind).KeysOnly().
+		Filter("StoreKey = ", storeKey).Filter("RecordKey = ", srcKey)
+	blobKeys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	batch := blobKeys
+	if len(batch) > mergeBlobRefBatchSize {
+		batch = batch[:mergeBlobRefBatchSize]
+	}
+
+	dstRKey := m.createRecordKey(storeKey, dstKey)
+	srcRKey := m.createRecordKey(storeKey, srcKey)
+	var owner string
+	_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		dst, src := new(record.Record), new(record.Record)
+		if err := tx.Get(dstRKey, dst); err != nil {
+			return err
+		}
+		if err := tx.Get(srcRKey, src); err != nil {
+			return err
+		}
+		owner = dst.OwnerID
+		oldProperties, oldUpdatedAt := dst.Properties.Clone(), dst.Timestamps.UpdatedAt
+		mergeTags(dst, src)
+		mergeProperties(dst, src, strategy)
+
+		// The current blobs are updated here, so exclude them from the batch
+		// as an entity can only be mutated once in a commit.
+		current := map[uuid.UUID]bool{dst.ExternalBlob: true, src.ExternalBlob: true}
+		if err := m.mergeCurrentBlobs(ctx, tx, dst, src, strategy); err != nil {
+			return err
+		}
+		if err := m.reassignBlobRefsInTransaction(tx, batch, current, srcKey, dstKey, owner); err != nil {
+			return err
+		}
+		if err := m.updateTimestamps(&dst.Timestamps); err != nil {
+			return err
+		}
+		dst.StampPropertyChanges(oldProperties, oldUpdatedAt, dst.Timestamps.UpdatedAt)
+		entity, err := m.recordEntity(ctx, storeKey, dst)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Mutate(ds.NewUpdate(dstRKey, entity), ds.NewDelete(srcRKey))
+		return firstMultiError(err)
+	})
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+
+	for rest := blobKeys[len(batch):]; len(rest) > 0; {
+		batch := rest
+		if len(batch) > mergeBlobRefBatchSize {
+			batch = batch[:mergeBlobRefBatchSize]
+		}
+		rest = rest[len(batch):]
+		_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+			return m.reassignBlobRefsInTransaction(tx, batch, nil, srcKey, dstKey, owner)
+		})
+		if err != nil {
+			return datastoreErrToGRPCStatus(err)
+		}
+	}
+	if err := m.deleteRecordTokens(ctx, srcRKey); err != nil {
+		return err
+	}
+	return m.deleteRecordCounters(ctx, storeKey, srcKey)
+}
+
+// mergeTags appends the tags of src that dst doesn't have to dst.
+func mergeTags(dst, src *record.Record) {
+	seen := make(map[string]bool, len(dst.Tags))
+	for _, t := range dst.Tags {
+		seen[t] = true
+	}
+	for _, t := range src.Tags {
+		if !seen[t] {
+			seen[t] = true
+			dst.Tags = append(dst.Tags, t)
+		}
+	}
+}
+
+// mergeProperties copies the properties of src to dst. Properties that exist
+// in both records are overwritten only if strategy is MergeSrcWins.
+func mergeProperties(dst, src *record.Record, strategy MergeStrategy) {
+	if len(src.Properties) == 0 {
+		return
+	}
+	if dst.Properties == nil {
+		dst.Properties = make(record.PropertyMap)
+	}
+	for name, v := range src.Properties {
+		if _, ok := dst.Properties[name]; ok && strategy != MergeSrcWins {
+			continue
+		}
+		dst.Properties[name] = v
+	}
+}
+
+// hasBlob reports whether r has either an inline or an external blob.
+func hasBlob(r *record.Record) bool {
+	return r.ExternalBlob != uuid.Nil || r.HasInlineBlob()
+}
+
+// mergeCurrentBlobs chooses the blob of the merged record as described in
+// MergeRecords and updates dst accordingly. The current external blob of the
+// record that loses is marked for deletion, and the current external blob of
+// src, if kept, is reassigned to dst. Missing BlobRefs are ignored.
+func (m *MetaDB) mergeCurrentBlobs(ctx context.Context, tx *ds.Transaction, dst, src *record.Record, strategy MergeStrategy) error {
+	useSrc := hasBlob(src) && (strategy == MergeSrcWins || !hasBlob(dst))
+	loser := src
+	if useSrc {
+		loser = dst
+	}
+	if loser.ExternalBlob != uuid.Nil {
+		b, err := m.getBlobRef(ctx, tx, loser.ExternalBlob)
+		switch {
+		case err == nil:
+			b.RecordKey = dst.Key
+			if _, err := m.markBlobRefForDeletion(tx, loser, b, uuid.Nil); err != nil {
+				return err
+			}
+		case status.Code(err) != codes.NotFound:
+			return err
+		}
+	}
+	if !useSrc {
+		return nil
+	}
+
+	dst.Blob = src.Blob
+	dst.BlobSize = src.BlobSize
+	dst.Checksums = src.Checksums
+	dst.ExternalBlob = src.ExternalBlob
+	dst.Chunked = src.Chunked
+	dst.ChunkCount = src.ChunkCount
+	if src.ExternalBlob == uuid.Nil {
+		return nil
+	}
+	b, err := m.getBlobRef(ctx, tx, src.ExternalBlob)
+	if err != nil {
+		return err
+	}
+	b.RecordKey = dst.Key
+	b.OwnerID = dst.OwnerID
+	if err := m.updateTimestamps(&b.Timestamps); err != nil {
+		return err
+	}
+	_, err = tx.Mutate(ds.NewUpdate(m.createBlobKey(b.Key), b))
+	return firstMultiError(err)
+}
+
+// reassignBlobRefsInTransaction changes the RecordKey of the BlobRefs with keys
+// that still belong to the record with srcKey to dstKey, and their OwnerID to
+// owner, in tx. BlobRefs in skip and deleted BlobRefs are ignored.
+func (m *MetaDB) reassignBlobRefsInTransaction(tx *ds.Transaction, keys []*ds.Key, skip map[uuid.UUID]bool, srcKey, dstKey, owner string) error {
+	blobs := make([]*blobref.BlobRef, len(keys))
+	for i := range blobs {
+		blobs[i] = new(blobref.BlobRef)
+	}
+	if err := tx.GetMulti(keys, blobs); err != nil {
+		merr, ok := err.(ds.MultiError)
+		if !ok {
+			return err
+		}
+		for i, e := range merr {
+			if e == ds.ErrNoSuchEntity {
+				// Deleted after the query.
+				blobs[i] = nil
+			} else if e != nil {
+				return e
+			}
+		}
+	}
+	var muts []*ds.Mutation
+	for i, b := range blobs {
+		if b == nil || b.RecordKey != srcKey || skip[b.Key] {
+			continue
+		}
+		b.RecordKey = dstKey
+		b.OwnerID = owner
+		if err := m.updateTimestamps(&b.Timestamps); err != nil {
+			return err
+		}
+		muts = append(muts, ds.NewUpdate(keys[i], b))
+	}
+	if len(muts) == 0 {
+		return nil
+	}
+	_, err := tx.Mutate(muts...)
+	return firstMultiError(err)
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeTags(t *testing.T) {
+	dst := &record.Record{Tags: []string{"a", "b"}}
+	mergeTags(dst, &record.Record{Tags: []string{"c", "b", "c", "d"}})
+	assert.Equal(t, []string{"a", "b", "c", "d"}, dst.Tags)
+}
+
+func TestMergeProperties(t *testing.T) {
+	newRecords := func() (*record.Record, *record.Record) {
+		dst := &record.Record{Properties: record.PropertyMap{
+			"both": {Type: pb.Property_STRING, StringValue: "dst"},
+			"dst":  {Type: pb.Property_INTEGER, IntegerValue: 1},
+		}}
+		src := &record.Record{Properties: record.PropertyMap{
+			"both": {Type: pb.Property_STRING, StringValue: "src"},
+			"src":  {Type: pb.Property_BOOLEAN, BooleanValue: true},
+		}}
+		return dst, src
+	}
+
+	dst, src := newRecords()
+	mergeProperties(dst, src, MergeDstWins)
+	assert.Equal(t, record.PropertyMap{
+		"both": {Type: pb.Property_STRING, StringValue: "dst"},
+		"dst":  {Type: pb.Property_INTEGER, IntegerValue: 1},
+		"src":  {Type: pb.Property_BOOLEAN, BooleanValue: true},
+	}, dst.Properties)
+
+	dst, src = newRecords()
+	mergeProperties(dst, src, MergeSrcWins)
+	assert.Equal(t, "src", dst.Properties["both"].StringValue)
+	assert.Len(t, dst.Properties, 3)
+
+	// The destination record may not have properties.
+	_, src = newRecords()
+	dst = new(record.Record)
+	mergeProperties(dst, src, MergeDstWins)
+	assert.Equal(t, src.Properties, dst.Properties)
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newMergeTestRecord(key, owner string, tags []string, level int64) *record.Record {
+	return &record.Record{
+		Key:     key,
+		OwnerID: owner,
+		Tags:    tags,
+		Properties: record.PropertyMap{
+			"level": {Type: pb.Property_INTEGER, IntegerValue: level},
+			owner:   {Type: pb.Property_STRING, StringValue: owner},
+		},
+	}
+}
+
+func TestMetaDB_MergeRecordsProperties(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	for _, tc := range []struct {
+		strategy    m.MergeStrategy
+		wantLevel   int64
+		wantChanged []string
+	}{
+		{m.MergeDstWins, 1, []string{"src"}},
+		{m.MergeSrcWins, 2, []string{"level", "src"}},
+	} {
+		st, dst := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey()},
+			newMergeTestRecord(newRecordKey(), "dst", []string{"a", "b"}, 1))
+		src := setupTestRecord(ctx, t, metaDB, st.Key, newMergeTestRecord(newRecordKey(), "src", []string{"b", "c"}, 2))
+
+		require.NoError(t, metaDB.MergeRecords(ctx, st.Key, dst.Key, src.Key, tc.strategy))
+
+		got, err := metaDB.GetRecord(ctx, st.Key, dst.Key)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, got.Tags)
+		assert.Equal(t, tc.wantLevel, got.Properties["level"].IntegerValue, "strategy %v", tc.strategy)
+		assert.Equal(t, "dst", got.Properties["dst"].StringValue)
+		assert.Equal(t, "src", got.Properties["src"].StringValue)
+		assert.Equal(t, "dst", got.OwnerID)
+		assert.Equal(t, tc.wantChanged, got.ChangedPropertiesSince(dst.Timestamps.UpdatedAt), "strategy %v", tc.strategy)
+
+		_, err = metaDB.GetRecord(ctx, st.Key, src.Key)
+		assert.Equal(t, codes.NotFound, status.Code(err), "the source record must be deleted")
+	}
+}
+
+func TestMetaDB_MergeRecordsBlobs(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, srcKey, srcBlob := setupCurrentBlob(ctx, t, metaDB)
+	pending := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(1, storeKey, srcKey))
+	dst := setupTestRecord(ctx, t, metaDB, storeKey, newMergeTestRecord(newRecordKey(), "dst", nil, 1))
+
+	// The destination record doesn't have a blob, so it gets the source blob
+	// regardless of the strategy.
+	require.NoError(t, metaDB.MergeRecords(ctx, storeKey, dst.Key, srcKey, m.MergeDstWins))
+
+	got, err := metaDB.GetRecord(ctx, storeKey, dst.Key)
+	require.NoError(t, err)
+	assert.Equal(t, srcBlob.Key, got.ExternalBlob)
+	for _, key := range []uuid.UUID{srcBlob.Key, pending.Key} {
+		b, err := metaDB.GetBlobRef(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, dst.Key, b.RecordKey)
+		assert.Equal(t, "dst", b.OwnerID)
+	}
+	b, err := metaDB.GetBlobRef(ctx, srcBlob.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusReady, b.Status)
+	_, err = metaDB.GetRecord(ctx, storeKey, srcKey)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Both records have blobs, and the destination blob wins.
+	other := setupTestRecord(ctx, t, metaDB, storeKey, &record.Record{
+		Key: newRecordKey(), Blob: []byte("inline"), BlobSize: 6, Properties: make(record.PropertyMap),
+	})
+	require.NoError(t, metaDB.MergeRecords(ctx, storeKey, dst.Key, other.Key, m.MergeDstWins))
+	got, err = metaDB.GetRecord(ctx, storeKey, dst.Key)
+	require.NoError(t, err)
+	assert.Equal(t, srcBlob.Key, got.ExternalBlob)
+	assert.Empty(t, got.Blob)
+
+	// The source inline blob wins, and the external blob is deleted.
+	other = setupTestRecord(ctx, t, metaDB, storeKey, &record.Record{
+		Key: newRecordKey(), Blob: []byte("inline"), BlobSize: 6, Properties: make(record.PropertyMap),
+	})
+	require.NoError(t, metaDB.MergeRecords(ctx, storeKey, dst.Key, other.Key, m.MergeSrcWins))
+	got, err = metaDB.GetRecord(ctx, storeKey, dst.Key)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, got.ExternalBlob)
+	assert.Equal(t, []byte("inline"), got.Blob)
+	b, err = metaDB.GetBlobRef(ctx, srcBlob.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusPendingDeletion, b.Status)
+}
+
+func TestMetaDB_MergeRecordsErrors(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+
+	err := metaDB.MergeRecords(ctx, st.Key, r.Key, r.Key, m.MergeDstWins)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = metaDB.MergeRecords(ctx, st.Key, r.Key, newRecordKey(), m.MergeStrategy(42))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = metaDB.MergeRecords(ctx, st.Key, r.Key, newRecordKey(), m.MergeDstWins)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = metaDB.GetRecord(ctx, st.Key, r.Key)
+	assert.NoError(t, err, "the destination record must be kept if the merge fails")
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+)
+
+// MetadataStore is the set of metadata operations that don't depend on the
+// database, so that backends other than Datastore can be plugged in.
+// MetaDB is the Datastore implementation.
+//
+// Implementations must follow the contract of the MetaDB methods of the same
+// names, including the gRPC error codes they return. In particular:
+//   - Get, Update, and Delete methods return NotFound for missing entities,
+//     except DeleteRecord, which succeeds.
+//   - InsertRecord returns FailedPrecondition if the store doesn't exist and
+//     AlreadyExists if the record does.
+//   - InsertBlobRef returns FailedPrecondition if the record doesn't exist.
+//   - DeleteStore returns FailedPrecondition if the store has records.
+//   - DeleteBlobRef returns FailedPrecondition for Ready BlobRefs.
+type MetadataStore interface {
+	CreateStore(ctx context.Context, store *store.Store) (*store.Store, error)
+	GetStore(ctx context.Context, key string) (*store.Store, error)
+	DeleteStore(ctx context.Context, key string) error
+
+	InsertRecord(ctx context.Context, storeKey string, record *record.Record) (*record.Record, error)
+	GetRecord(ctx context.Context, storeKey, key string) (*record.Record, error)
+	// UpdateRecord calls updater with the current record and saves the returned
+	// record atomically. Returning ErrNoUpdate from updater skips saving.
+	UpdateRecord(ctx context.Context, storeKey, key string, updater RecordUpdater) (*record.Record, error)
+	DeleteRecord(ctx context.Context, storeKey, key string) error
+
+	InsertBlobRef(ctx context.Context, blob *blobref.BlobRef) (*blobref.BlobRef, error)
+	GetBlobRef(ctx context.Context, key uuid.UUID) (*blobref.BlobRef, error)
+	UpdateBlobRef(ctx context.Context, blob *blobref.BlobRef) (*blobref.BlobRef, error)
+	DeleteBlobRef(ctx context.Context, key uuid.UUID) error
+	QueryBlobRefs(ctx context.Context, filter BlobRefFilter) ([]*blobref.BlobRef, error)
+
+	// Disconnect releases resources held by the implementation.
+	Disconnect(ctx context.Context) error
+}
+
+// Assert MetaDB implements the MetadataStore interface.
+var _ MetadataStore = new(MetaDB)
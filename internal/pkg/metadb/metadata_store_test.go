@@ -0,0 +1,326 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeRecordKey struct {
+	storeKey, key string
+}
+
+// fakeMetadataStore is an in-memory MetadataStore used to verify the contract.
+type fakeMetadataStore struct {
+	mu      sync.Mutex
+	stores  map[string]store.Store
+	records map[fakeRecordKey]record.Record
+	blobs   map[uuid.UUID]blobref.BlobRef
+}
+
+var _ m.MetadataStore = new(fakeMetadataStore)
+
+func newFakeMetadataStore() *fakeMetadataStore {
+	return &fakeMetadataStore{
+		stores:  make(map[string]store.Store),
+		records: make(map[fakeRecordKey]record.Record),
+		blobs:   make(map[uuid.UUID]blobref.BlobRef),
+	}
+}
+
+func (f *fakeMetadataStore) CreateStore(ctx context.Context, s *store.Store) (*store.Store, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.stores[s.Key]; ok {
+		return nil, status.Error(codes.AlreadyExists, "store already exists")
+	}
+	s.Timestamps = timestamps.New()
+	f.stores[s.Key] = *s
+	return s, nil
+}
+
+func (f *fakeMetadataStore) GetStore(ctx context.Context, key string) (*store.Store, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.stores[key]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "store not found")
+	}
+	return &s, nil
+}
+
+func (f *fakeMetadataStore) DeleteStore(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for k := range f.records {
+		if k.storeKey == key {
+			return status.Error(codes.FailedPrecondition, "store is not empty")
+		}
+	}
+	delete(f.stores, key)
+	return nil
+}
+
+func (f *fakeMetadataStore) InsertRecord(ctx context.Context, storeKey string, r *record.Record) (*record.Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.stores[storeKey]; !ok {
+		return nil, status.Error(codes.FailedPrecondition, "store doesn't exist")
+	}
+	k := fakeRecordKey{storeKey, r.Key}
+	if _, ok := f.records[k]; ok {
+		return nil, status.Error(codes.AlreadyExists, "record already exists")
+	}
+	r.StoreKey = storeKey
+	r.Timestamps = timestamps.New()
+	f.records[k] = *r
+	return r, nil
+}
+
+func (f *fakeMetadataStore) GetRecord(ctx context.Context, storeKey, key string) (*record.Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.records[fakeRecordKey{storeKey, key}]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "record not found")
+	}
+	return &r, nil
+}
+
+func (f *fakeMetadataStore) UpdateRecord(ctx context.Context, storeKey, key string, updater m.RecordUpdater) (*record.Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := fakeRecordKey{storeKey, key}
+	r, ok := f.records[k]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "record not found")
+	}
+	updated, err := updater(&r)
+	if err == m.ErrNoUpdate {
+		return updated, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	updated.Timestamps.Update()
+	f.records[k] = *updated
+	return updated, nil
+}
+
+func (f *fakeMetadataStore) DeleteRecord(ctx context.Context, storeKey, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, fakeRecordKey{storeKey, key})
+	return nil
+}
+
+func (f *fakeMetadataStore) InsertBlobRef(ctx context.Context, b *blobref.BlobRef) (*blobref.BlobRef, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.records[fakeRecordKey{b.StoreKey, b.RecordKey}]
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "record doesn't exist")
+	}
+	if _, ok := f.blobs[b.Key]; ok {
+		return nil, status.Error(codes.AlreadyExists, "blob already exists")
+	}
+	if b.OwnerID == "" {
+		b.OwnerID = r.OwnerID
+	}
+	b.Timestamps = timestamps.New()
+	f.blobs[b.Key] = *b
+	return b, nil
+}
+
+func (f *fakeMetadataStore) GetBlobRef(ctx context.Context, key uuid.UUID) (*blobref.BlobRef, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.blobs[key]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "blob not found")
+	}
+	return &b, nil
+}
+
+func (f *fakeMetadataStore) UpdateBlobRef(ctx context.Context, b *blobref.BlobRef) (*blobref.BlobRef, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old, ok := f.blobs[b.Key]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "blob not found")
+	}
+	b.Timestamps.CreatedAt = old.Timestamps.CreatedAt
+	f.blobs[b.Key] = *b
+	return b, nil
+}
+
+func (f *fakeMetadataStore) DeleteBlobRef(ctx context.Context, key uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.blobs[key]
+	if !ok {
+		return status.Error(codes.NotFound, "blob not found")
+	}
+	if b.Status == blobref.StatusReady {
+		return status.Error(codes.FailedPrecondition, "blob is ready")
+	}
+	delete(f.blobs, key)
+	return nil
+}
+
+func (f *fakeMetadataStore) QueryBlobRefs(ctx context.Context, filter m.BlobRefFilter) ([]*blobref.BlobRef, error) {
+	if (filter.MinSize > 0 || filter.MaxSize > 0) && !filter.ExpiresBefore.IsZero() {
+		return nil, m.ErrInequalityFilterConflict
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var match []*blobref.BlobRef
+	for _, b := range f.blobs {
+		b := b
+		switch {
+		case filter.StoreKey != "" && b.StoreKey != filter.StoreKey,
+			filter.Status != nil && b.Status != *filter.Status,
+			!filter.UpdatedBefore.IsZero() && !b.Timestamps.UpdatedAt.Before(filter.UpdatedBefore),
+			!filter.ExpiresBefore.IsZero() && (b.ExpiresAt.IsZero() || !b.ExpiresAt.Before(filter.ExpiresBefore)),
+			filter.MinSize > 0 && b.Size < filter.MinSize,
+			filter.MaxSize > 0 && b.Size > filter.MaxSize:
+			continue
+		}
+		match = append(match, &b)
+	}
+	// Datastore returns results in key order.
+	sort.Slice(match, func(i, j int) bool { return match[i].Key.String() < match[j].Key.String() })
+	if filter.Limit > 0 && len(match) > filter.Limit {
+		match = match[:filter.Limit]
+	}
+	return match, nil
+}
+
+func (f *fakeMetadataStore) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+// testMetadataStoreContract verifies the behaviors that every MetadataStore must follow.
+func testMetadataStoreContract(ctx context.Context, t *testing.T, ms m.MetadataStore) {
+	t.Helper()
+
+	_, err := ms.GetStore(ctx, newStoreKey())
+	assert.Equal(t, codes.NotFound, status.Code(err), "GetStore() of a missing store")
+
+	st, err := ms.CreateStore(ctx, &store.Store{Key: newStoreKey(), Name: t.Name()})
+	require.NoError(t, err)
+	t.Cleanup(func() { ms.DeleteStore(ctx, st.Key) })
+	gotStore, err := ms.GetStore(ctx, st.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, st.Name, gotStore.Name)
+	}
+
+	_, err = ms.InsertRecord(ctx, newStoreKey(), &record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "InsertRecord() into a missing store")
+	r, err := ms.InsertRecord(ctx, st.Key, &record.Record{Key: newRecordKey(), OwnerID: "owner", Properties: make(record.PropertyMap)})
+	require.NoError(t, err)
+	t.Cleanup(func() { ms.DeleteRecord(ctx, st.Key, r.Key) })
+	assert.Equal(t, st.Key, r.StoreKey)
+	assert.False(t, r.Timestamps.CreatedAt.IsZero())
+	_, err = ms.InsertRecord(ctx, st.Key, &record.Record{Key: r.Key, Properties: make(record.PropertyMap)})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err), "InsertRecord() of an existing record")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(ms.DeleteStore(ctx, st.Key)), "DeleteStore() of a non-empty store")
+
+	updated, err := ms.UpdateRecord(ctx, st.Key, r.Key, func(r *record.Record) (*record.Record, error) {
+		r.OpaqueString = "updated"
+		return r, nil
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, r.Timestamps.Signature, updated.Timestamps.Signature)
+	got, err := ms.GetRecord(ctx, st.Key, r.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "updated", got.OpaqueString)
+	}
+	_, err = ms.UpdateRecord(ctx, st.Key, r.Key, func(r *record.Record) (*record.Record, error) {
+		r.OpaqueString = "discarded"
+		return r, m.ErrNoUpdate
+	})
+	assert.NoError(t, err)
+	got, err = ms.GetRecord(ctx, st.Key, r.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "updated", got.OpaqueString, "ErrNoUpdate should skip saving")
+	}
+	_, err = ms.UpdateRecord(ctx, st.Key, newRecordKey(), func(r *record.Record) (*record.Record, error) { return r, nil })
+	assert.Equal(t, codes.NotFound, status.Code(err), "UpdateRecord() of a missing record")
+
+	_, err = ms.InsertBlobRef(ctx, blobref.NewBlobRef(1, st.Key, newRecordKey()))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "InsertBlobRef() for a missing record")
+	b, err := ms.InsertBlobRef(ctx, blobref.NewBlobRef(10, st.Key, r.Key))
+	require.NoError(t, err)
+	t.Cleanup(func() { ms.DeleteBlobRef(ctx, b.Key) })
+	assert.Equal(t, "owner", b.OwnerID)
+	_, err = ms.GetBlobRef(ctx, uuid.New())
+	assert.Equal(t, codes.NotFound, status.Code(err), "GetBlobRef() of a missing blob")
+
+	require.NoError(t, b.Ready())
+	_, err = ms.UpdateBlobRef(ctx, b)
+	require.NoError(t, err)
+	gotBlob, err := ms.GetBlobRef(ctx, b.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blobref.StatusReady, gotBlob.Status)
+	}
+	_, err = ms.UpdateBlobRef(ctx, blobref.NewBlobRef(1, st.Key, r.Key))
+	assert.Equal(t, codes.NotFound, status.Code(err), "UpdateBlobRef() of a missing blob")
+
+	ready := blobref.StatusReady
+	blobs, err := ms.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: st.Key, Status: &ready})
+	if assert.NoError(t, err) && assert.Len(t, blobs, 1) {
+		assert.Equal(t, b.Key, blobs[0].Key)
+	}
+	blobs, err = ms.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: st.Key, MinSize: 11})
+	assert.NoError(t, err)
+	assert.Empty(t, blobs)
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(ms.DeleteBlobRef(ctx, b.Key)), "DeleteBlobRef() of a Ready blob")
+	require.NoError(t, b.MarkForDeletion())
+	_, err = ms.UpdateBlobRef(ctx, b)
+	require.NoError(t, err)
+	assert.NoError(t, ms.DeleteBlobRef(ctx, b.Key))
+	assert.Equal(t, codes.NotFound, status.Code(ms.DeleteBlobRef(ctx, b.Key)), "DeleteBlobRef() of a missing blob")
+
+	assert.NoError(t, ms.DeleteRecord(ctx, st.Key, r.Key))
+	assert.NoError(t, ms.DeleteRecord(ctx, st.Key, r.Key), "DeleteRecord() of a missing record")
+	_, err = ms.GetRecord(ctx, st.Key, r.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err), "GetRecord() of a deleted record")
+	assert.NoError(t, ms.DeleteStore(ctx, st.Key))
+}
+
+func TestMetadataStore_FakeContract(t *testing.T) {
+	testMetadataStoreContract(context.Background(), t, newFakeMetadataStore())
+}
+
+func TestMetaDB_MetadataStoreContract(t *testing.T) {
+	ctx := context.Background()
+	testMetadataStoreContract(ctx, t, newMetaDB(ctx, t))
+}
@@ -15,17 +15,19 @@
 package metadb
 
 import (
+	"bytes"
 	"context"
 	"errors"
-	"fmt"
 	"github.com/googleforgames/open-saves/internal/pkg/tracing"
 	"go.opentelemetry.io/otel"
-	"strconv"
+	"time"
 
 	ds "cloud.google.com/go/datastore"
 	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/bulk"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
@@ -50,6 +52,15 @@ const (
 
 var (
 	ErrNoUpdate = errors.New("UpdateRecord doesn't need to commit the change")
+
+	ErrAlreadyExists    = status.Error(codes.AlreadyExists, "an entity with the key already exists")
+	ErrPermissionDenied = status.Error(codes.PermissionDenied, "the caller is not the owner")
+
+	ErrLeaseHeld    = status.Error(codes.FailedPrecondition, "the record is leased by another holder")
+	ErrLeaseInvalid = status.Error(codes.FailedPrecondition, "the lease is not held on the record or has expired")
+
+	ErrInequalityFilterConflict = status.Error(codes.InvalidArgument,
+		"BlobRefFilter: inequality filters can only be applied to a single property")
 )
 
 // MetaDB is a metadata database manager of Open Saves.
@@ -63,8 +74,68 @@ var (
 type MetaDB struct {
 	// Datastore namespace for multi-tenancy
 	Namespace string
+	// QueryConsistency is the consistency of the queries of OperationRead
+	// methods, QueryBlobRefs and QueryRecords (see ClassifyOperation).
+	// Key-based reads and reads in transactions are always strongly consistent.
+	QueryConsistency Consistency
+	// CursorKey is the secret key that query cursors returned to clients are
+	// signed with. It must be the same on all servers that share the database
+	// (see the datastore_cursor_key setting). A random key generated at startup
+	// is used if empty, in which case cursors are only valid within the process.
+	CursorKey []byte
+	// KeyValidator checks the keys of new and renamed stores and records, and
+	// the store and record keys of new blobs. SafeKeyValidator is used if nil.
+	KeyValidator KeyValidator
+	// StorePolicyTTL is the time that the policies of stores are cached before
+	// they are read from Datastore again, which bounds the time until a policy
+	// set by another server applies. DefaultStorePolicyTTL is used if not positive.
+	StorePolicyTTL time.Duration
+	// PathEncoding is the object path encoding of the BlobRefs that MetaDB
+	// creates, e.g. in InsertExternalizedBlobRef.
+	PathEncoding blobref.PathEncoding
+	// ShardBlobsByStore sets ShardedByStore of the BlobRefs that MetaDB
+	// creates, so that their objects are routed to the bucket of their store
+	// by blob.MultiBucketBlobStore.
+	ShardBlobsByStore bool
+	// ContentHashAlgorithm is the content hash algorithm of the BlobRefs that
+	// MetaDB creates or backfills. MD5 is used if empty.
+	ContentHashAlgorithm checksums.HashAlgorithm
+	// DerivationFuncs are the DerivationFuncs of the records in the stores,
+	// keyed by store key. MetaDB recomputes the derived properties of a record
+	// every time it saves the record. Records of stores without one have no
+	// derived properties. It must not be modified while MetaDB is in use.
+	DerivationFuncs map[string]record.DerivationFunc
+	// PropertyNameMode is how property names of records that can't be saved to
+	// Datastore as is are handled, in record writes and queries alike.
+	// They are rejected by default.
+	PropertyNameMode record.PropertyNameMode
+	// BlobKeyGenerator generates the keys of the BlobRefs created with
+	// NewBlobRef and NewChunkedBlobRef. Random UUIDs are used if nil.
+	BlobKeyGenerator blobref.KeyGenerator
+	// MaxEntitySize is the maximum estimated entity size of the records that
+	// MetaDB saves. record.MaxEntitySize is used if it is not positive.
+	MaxEntitySize int64
+	// Signer signs the Timestamps of the records and BlobRefs that MetaDB
+	// writes, so that modified timestamps can be detected with
+	// Timestamps.VerifySignature. Signatures are random UUIDs if nil.
+	Signer timestamps.Signer
 
 	client *ds.Client
+	// entities is client for lookups and non-transactional writes by key.
+	entities      entityClient
+	txLimiter     txLimiter
+	storePolicies storePolicyCache
+}
+
+// entityClient is the subset of *ds.Client that gets, puts, and deletes
+// entities by key. It is implemented by datastoretest.Client too, so that
+// MetaDB methods that only use it can be tested without the emulator.
+type entityClient interface {
+	Get(ctx context.Context, key *ds.Key, dst interface{}) error
+	GetMulti(ctx context.Context, keys []*ds.Key, dst interface{}) error
+	PutMulti(ctx context.Context, keys []*ds.Key, src interface{}) ([]*ds.Key, error)
+	Delete(ctx context.Context, key *ds.Key) error
+	DeleteMulti(ctx context.Context, keys []*ds.Key) error
 }
 
 // RecordUpdater is a callback function for record updates.
@@ -86,7 +157,7 @@ func NewMetaDB(ctx context.Context, projectID string, opts ...option.ClientOptio
 	if err != nil {
 		return nil, datastoreErrToGRPCStatus(err)
 	}
-	return &MetaDB{client: client}, nil
+	return &MetaDB{client: client, entities: client}, nil
 }
 
 func (m *MetaDB) newQuery(kind string) *ds.Query {
@@ -130,7 +201,7 @@ func (m *MetaDB) getBlobRef(ctx context.Context, tx *ds.Transaction, key uuid.UU
 	}
 	blob := new(blobref.BlobRef)
 	if tx == nil {
-		if err := m.client.Get(ctx, m.createBlobKey(key), blob); err != nil {
+		if err := m.entities.Get(ctx, m.createBlobKey(key), blob); err != nil {
 			return nil, datastoreErrToGRPCStatus(err)
 		}
 	} else {
@@ -148,7 +219,9 @@ func (m *MetaDB) markBlobRefForDeletion(tx *ds.Transaction,
 		return nil, status.Error(codes.FailedPrecondition, "the record doesn't have an external blob associated")
 	}
 	record.ExternalBlob = newBlobKey
-	record.Timestamps.Update()
+	if err := m.updateTimestamps(&record.Timestamps); err != nil {
+		return nil, err
+	}
 	if blob.MarkForDeletion() != nil {
 		blob.Fail()
 		return nil, status.Errorf(codes.Internal, "failed to transition the blob state for deletion: current = %v", blob.Status)
@@ -205,10 +278,14 @@ func (m *MetaDB) Disconnect(ctx context.Context) error {
 }
 
 // CreateStore creates a new store.
+// Returns ErrInvalidKey if the key is rejected by the KeyValidator.
 func (m *MetaDB) CreateStore(ctx context.Context, store *store.Store) (*store.Store, error) {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.CreateStore")
 	defer span.End()
 
+	if err := m.validateKeys(store.Key); err != nil {
+		return nil, err
+	}
 	store.Timestamps = timestamps.New()
 	key := m.createStoreKey(store.Key)
 	mut := ds.NewInsert(key, store)
@@ -226,7 +303,7 @@ func (m *MetaDB) GetStore(ctx context.Context, key string) (*store.Store, error)
 
 	dskey := m.createStoreKey(key)
 	store := new(store.Store)
-	err := m.client.Get(ctx, dskey, store)
+	err := m.entities.Get(ctx, dskey, store)
 	if err != nil {
 		return nil, datastoreErrToGRPCStatus(err)
 	}
@@ -257,7 +334,7 @@ func (m *MetaDB) DeleteStore(ctx context.Context, key string) error {
 
 	dskey := m.createStoreKey(key)
 
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		query := ds.NewQuery(recordKind).Transaction(tx).KeysOnly().
 			Ancestor(dskey).Limit(1).Namespace(m.Namespace)
 		iter := m.client.Run(ctx, query)
@@ -266,34 +343,49 @@ func (m *MetaDB) DeleteStore(ctx context.Context, key string) error {
 			return status.Errorf(codes.FailedPrecondition,
 				"DeleteStore was called for a non-empty store (%s)", key)
 		}
-		return tx.Delete(dskey)
+		return tx.DeleteMulti([]*ds.Key{m.createStorePolicyKey(key), dskey})
 	})
 	if err != nil {
 		return datastoreErrToGRPCStatus(err)
 	}
+	m.storePolicies.invalidate(m.storePolicyCacheKey(key))
 	return nil
 }
 
 // InsertRecord creates a new Record in the store specified with storeKey.
-// Returns error if there is already a record with the same key.
+// The default record TTL of the store is applied unless ExpiresAt is set.
+// Returns error if there is already a record with the same key, and
+// ErrInvalidKey if the key is rejected by the KeyValidator.
 func (m *MetaDB) InsertRecord(ctx context.Context, storeKey string, record *record.Record) (*record.Record, error) {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.InsertRecord")
 	defer span.End()
 
-	record.Timestamps = timestamps.New()
+	if err := m.validateKeys(record.Key); err != nil {
+		return nil, err
+	}
+	ts, err := m.newTimestamps()
+	if err != nil {
+		return nil, err
+	}
+	record.Timestamps = ts
 	record.StoreKey = storeKey
 	rkey := m.createRecordKey(storeKey, record.Key)
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
-		dskey := m.createStoreKey(storeKey)
-		query := ds.NewQuery(storeKind).Transaction(tx).Namespace(m.Namespace).
-			KeysOnly().Filter("__key__ = ", dskey).Limit(1)
-		iter := m.client.Run(ctx, query)
-		_, err := iter.Next(nil)
-		if err == iterator.Done {
+	_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		st := new(store.Store)
+		if err := tx.Get(m.createStoreKey(storeKey), st); err == ds.ErrNoSuchEntity {
 			return status.Errorf(codes.FailedPrecondition,
 				"InsertRecord was called with a non-existent store (%s)", storeKey)
+		} else if err != nil {
+			return err
+		}
+		if record.ExpiresAt.IsZero() {
+			record.SetTTL(0, st.DefaultRecordTTL, record.Timestamps.CreatedAt)
 		}
-		mut := ds.NewInsert(rkey, record)
+		entity, err := m.recordEntity(ctx, storeKey, record)
+		if err != nil {
+			return err
+		}
+		mut := ds.NewInsert(rkey, entity)
 		return m.mutateSingleInTransaction(tx, mut)
 	})
 	if err != nil {
@@ -305,16 +397,24 @@ func (m *MetaDB) InsertRecord(ctx context.Context, storeKey string, record *reco
 // UpdateRecord updates the record in the store specified with storeKey.
 // Pass a callback function to updater and change values there. The callback
 // will be protected by a transaction.
-// Returns error if the store doesn't have a record with the key provided.
+// Returns error if the store doesn't have a record with the key provided,
+// ErrRecordExpired if the record has expired but hasn't been deleted yet, and
+// ErrLeaseHeld if a lease is held on the record (see UpdateRecordWithLease).
 func (m *MetaDB) UpdateRecord(ctx context.Context, storeKey string, key string, updater RecordUpdater) (*record.Record, error) {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.UpdateRecord")
 	defer span.End()
 
+	return m.updateRecord(ctx, storeKey, key, "", updater)
+}
+
+// updateRecord is UpdateRecord on behalf of the holder of leaseID, or of no
+// lease holder if leaseID is empty.
+func (m *MetaDB) updateRecord(ctx context.Context, storeKey, key, leaseID string, updater RecordUpdater) (*record.Record, error) {
 	if updater == nil {
 		return nil, status.Errorf(codes.Internal, "updater cannot be nil")
 	}
 	var toUpdate *record.Record
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		rkey := m.createRecordKey(storeKey, key)
 
 		// TODO(yuryu): Consider supporting transactions in MetaDB and move
@@ -323,33 +423,20 @@ func (m *MetaDB) UpdateRecord(ctx context.Context, storeKey string, key string,
 		if err := tx.Get(rkey, toUpdate); err != nil {
 			return err
 		}
+		if toUpdate.IsExpired(time.Now()) {
+			return ErrRecordExpired
+		}
 
-		oldExternalBlob := toUpdate.ExternalBlob
-
-		// Update the record entry by calling the updater callback.
 		var err error
-		toUpdate, err = updater(toUpdate)
+		toUpdate, err = m.applyRecordUpdater(ctx, tx, toUpdate, leaseID, updater)
 		if err != nil {
 			return err
 		}
-
-		if oldExternalBlob != toUpdate.ExternalBlob {
-			return status.Error(codes.Internal, "UpdateRecord: ExternalBlob must not be modified in UpdateRecord")
-		}
-		// Deassociate the old blob if an external blob is associated, and a new inline blob is being added.
-		if oldExternalBlob != uuid.Nil && len(toUpdate.Blob) > 0 {
-			oldBlob, err := m.getBlobRef(ctx, tx, toUpdate.ExternalBlob)
-			if err != nil {
-				return err
-			}
-			toUpdate, err = m.markBlobRefForDeletion(tx, toUpdate, oldBlob, uuid.Nil)
-			if err != nil {
-				return err
-			}
+		entity, err := m.recordEntity(ctx, storeKey, toUpdate)
+		if err != nil {
+			return err
 		}
-
-		toUpdate.Timestamps.Update()
-		return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, toUpdate))
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity))
 	})
 	// ErrNoUpdate is expected and not treated as an error.
 	if err != nil && err != ErrNoUpdate {
@@ -358,28 +445,155 @@ func (m *MetaDB) UpdateRecord(ctx context.Context, storeKey string, key string,
 	return toUpdate, nil
 }
 
+// applyRecordUpdater calls updater with toUpdate and returns the record to save.
+// The old external blob is marked for deletion in tx if updater adds an inline blob.
+// The caller must commit the returned record.
+// The update is rejected by checkRecordLease unless the caller holds leaseID.
+func (m *MetaDB) applyRecordUpdater(ctx context.Context, tx *ds.Transaction, toUpdate *record.Record, leaseID string, updater RecordUpdater) (*record.Record, error) {
+	if err := checkRecordLease(toUpdate, leaseID, time.Now()); err != nil {
+		return nil, err
+	}
+	oldExternalBlob := toUpdate.ExternalBlob
+	// Updaters may modify the properties in place, so keep a copy to find
+	// the changed ones.
+	oldProperties, oldUpdatedAt := toUpdate.Properties.Clone(), toUpdate.Timestamps.UpdatedAt
+
+	// Update the record entry by calling the updater callback.
+	// The record is returned with the error so that UpdateRecord can return it
+	// for ErrNoUpdate.
+	toUpdate, err := updater(toUpdate)
+	if err != nil {
+		return toUpdate, err
+	}
+
+	if oldExternalBlob != toUpdate.ExternalBlob {
+		return nil, status.Error(codes.Internal, "UpdateRecord: ExternalBlob must not be modified in UpdateRecord")
+	}
+	// Deassociate the old blob if an external blob is associated, and a new inline blob is being added.
+	// The new inline blob may be empty.
+	if oldExternalBlob != uuid.Nil && toUpdate.HasInlineBlob() {
+		oldBlob, err := m.getBlobRef(ctx, tx, toUpdate.ExternalBlob)
+		if err != nil {
+			return nil, err
+		}
+		toUpdate, err = m.markBlobRefForDeletion(tx, toUpdate, oldBlob, uuid.Nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.updateTimestamps(&toUpdate.Timestamps); err != nil {
+		return nil, err
+	}
+	toUpdate.StampPropertyChanges(oldProperties, oldUpdatedAt, toUpdate.Timestamps.UpdatedAt)
+	return toUpdate, nil
+}
+
 // GetRecord fetches and returns a record with key in store storeKey.
-// Returns error if not found.
+// Returns error if not found, and ErrRecordExpired if the record has expired
+// but hasn't been deleted yet.
 func (m *MetaDB) GetRecord(ctx context.Context, storeKey, key string) (*record.Record, error) {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetRecord")
 	defer span.End()
 
 	rkey := m.createRecordKey(storeKey, key)
 	record := new(record.Record)
-	if err := m.client.Get(ctx, rkey, record); err != nil {
+	if err := m.entities.Get(ctx, rkey, record); err != nil {
 		return nil, datastoreErrToGRPCStatus(err)
 	}
+	if record.IsExpired(time.Now()) {
+		return nil, ErrRecordExpired
+	}
 	return record, nil
 }
 
-// DeleteRecord deletes a record with key in store storeKey.
-// It doesn't return error even if the key is not found in the database.
+// projectableRecordProperties is the set of indexed Record properties that
+// GetRecordProjection can read using a projection query.
+var projectableRecordProperties = map[string]bool{
+	"BlobSize":   true,
+	"Chunked":    true,
+	"ChunkCount": true,
+	"OwnerID":    true,
+	"Tags":       true,
+}
+
+// IsProjectableRecordProperty reports whether GetRecordProjection can read the
+// Record property with a projection query.
+func IsProjectableRecordProperty(property string) bool {
+	return projectableRecordProperties[property]
+}
+
+// GetRecordProjection returns a Record that only has Key, StoreKey, and the property
+// set. It uses a projection query, which is cheaper than reading the whole entity.
+// Returns errors:
+//   - NotFound: the record is not found, or ErrRecordExpired if it has expired.
+//   - InvalidArgument: the property cannot be projected.
+func (m *MetaDB) GetRecordProjection(ctx context.Context, storeKey, key, property string) (*record.Record, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetRecordProjection")
+	defer span.End()
+
+	if !IsProjectableRecordProperty(property) {
+		return nil, status.Errorf(codes.InvalidArgument, "property (%s) cannot be projected", property)
+	}
+	rkey := m.createRecordKey(storeKey, key)
+	query := m.newQuery(recordKind).Filter("__key__ = ", rkey).Project(property)
+	var results []*record.Record
+	if _, err := m.client.GetAll(ctx, query, &results); err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	if expired, err := m.recordExpired(ctx, rkey, time.Now()); err != nil {
+		return nil, err
+	} else if expired {
+		return nil, ErrRecordExpired
+	}
+	if len(results) == 0 {
+		// Entities don't appear in projection results if the property is
+		// empty (e.g. no tags), so check if the record actually exists.
+		if exists, err := m.recordExists(ctx, nil, rkey); err != nil {
+			return nil, datastoreErrToGRPCStatus(err)
+		} else if !exists {
+			return nil, status.Errorf(codes.NotFound, "record (%s) was not found in store (%s)", key, storeKey)
+		}
+		return &record.Record{Key: key, StoreKey: storeKey, Properties: make(record.PropertyMap)}, nil
+	}
+	// Projection queries return one result per value for multi-valued properties.
+	r := results[0]
+	for _, other := range results[1:] {
+		r.Tags = append(r.Tags, other.Tags...)
+	}
+	return r, nil
+}
+
+// DeleteRecord deletes a record with key in store storeKey, and then its
+// idempotency tokens and sharded counters.
+// It doesn't return error even if the key is not found in the database, and
+// returns ErrLeaseHeld if a lease is held on the record.
 func (m *MetaDB) DeleteRecord(ctx context.Context, storeKey, key string) error {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteRecord")
 	defer span.End()
 
+	_, err := m.deleteRecord(ctx, storeKey, key, false)
+	return err
+}
+
+// GetAndDeleteRecord is the same as DeleteRecord, but returns the record as it
+// was before the deletion, or nil if it didn't exist.
+func (m *MetaDB) GetAndDeleteRecord(ctx context.Context, storeKey, key string) (*record.Record, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetAndDeleteRecord")
+	defer span.End()
+
+	return m.deleteRecord(ctx, storeKey, key, false)
+}
+
+// deleteRecord deletes the record and leaves a tombstone in the same transaction
+// if tombstone is true and the record exists. The idempotency tokens and the
+// sharded counters of the record are deleted after the transaction.
+// It returns the deleted record, or nil if the record didn't exist.
+func (m *MetaDB) deleteRecord(ctx context.Context, storeKey, key string, tombstone bool) (*record.Record, error) {
 	rkey := m.createRecordKey(storeKey, key)
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	var deleted *record.Record
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		deleted = nil
 		record := new(record.Record)
 		if err := tx.Get(rkey, record); err != nil {
 			if err == ds.ErrNoSuchEntity {
@@ -388,6 +602,11 @@ func (m *MetaDB) DeleteRecord(ctx context.Context, storeKey, key string) error {
 			}
 			return err
 		}
+		if err := checkRecordLease(record, "", time.Now()); err != nil {
+			return err
+		}
+		// markBlobRefForDeletion modifies the record.
+		before := *record
 		if record.ExternalBlob != uuid.Nil {
 			blob, err := m.getBlobRef(ctx, tx, record.ExternalBlob)
 			if err == nil {
@@ -399,40 +618,170 @@ func (m *MetaDB) DeleteRecord(ctx context.Context, storeKey, key string) error {
 				return err
 			}
 		}
-		return m.mutateSingleInTransaction(tx, ds.NewDelete(rkey))
+		if err := m.mutateSingleInTransaction(tx, ds.NewDelete(rkey)); err != nil {
+			return err
+		}
+		deleted = &before
+		if tombstone {
+			t := &Tombstone{RecordKey: key, DeletedAt: time.Now()}
+			return m.mutateSingleInTransaction(tx, ds.NewUpsert(m.createTombstoneKey(storeKey, key), t))
+		}
+		return nil
 	})
-	return datastoreErrToGRPCStatus(err)
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	if deleted == nil {
+		return nil, nil
+	}
+	if err := m.deleteRecordTokens(ctx, rkey); err != nil {
+		return nil, err
+	}
+	if err := m.deleteRecordCounters(ctx, storeKey, key); err != nil {
+		return nil, err
+	}
+	return deleted, nil
 }
 
-// InsertBlobRef inserts a new BlobRef object to the datastore.
-func (m *MetaDB) InsertBlobRef(ctx context.Context, blob *blobref.BlobRef) (*blobref.BlobRef, error) {
-	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.InsertBlobRef")
+// RenameRecord changes the key of the record from oldKey to newKey. As Datastore keys
+// are immutable, it copies the record to newKey, updates RecordKey of BlobRefs
+// that belong to the record, and deletes the old record in a transaction.
+// The sharded counters of the record are moved to newKey after the transaction,
+// and its idempotency tokens are deleted.
+// Returns errors:
+//   - NotFound: the record of oldKey is not found.
+//   - AlreadyExists (ErrAlreadyExists): a record of newKey already exists.
+//   - InvalidArgument (ErrInvalidKey): newKey is rejected by the KeyValidator.
+func (m *MetaDB) RenameRecord(ctx context.Context, storeKey, oldKey, newKey string) error {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.RenameRecord")
 	defer span.End()
 
-	blob.Timestamps = timestamps.New()
-	rkey := m.createRecordKey(blob.StoreKey, blob.RecordKey)
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
-		if exists, err := m.recordExists(ctx, tx, rkey); err != nil {
+	if oldKey == newKey {
+		return status.Error(codes.InvalidArgument, "RenameRecord: oldKey and newKey must be different")
+	}
+	if err := m.validateKeys(newKey); err != nil {
+		return err
+	}
+
+	// Queries inside a transaction must be ancestor queries, and BlobRefs are
+	// root entities, so find the keys first and re-read them in the transaction.
+	query := m.newQuery(blobKind).KeysOnly().
+		Filter("StoreKey = ", storeKey).Filter("RecordKey = ", oldKey)
+	blobKeys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+
+	oldRKey := m.createRecordKey(storeKey, oldKey)
+	newRKey := m.createRecordKey(storeKey, newKey)
+	_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		if exists, err := m.recordExists(ctx, tx, newRKey); err != nil {
 			return err
-		} else if !exists {
-			return status.Error(codes.FailedPrecondition, "InsertBlob was called for a non-exitent record")
+		} else if exists {
+			return ErrAlreadyExists
 		}
-		_, err := tx.Mutate(ds.NewInsert(m.createBlobKey(blob.Key), blob))
+		r := new(record.Record)
+		if err := tx.Get(oldRKey, r); err != nil {
+			return err
+		}
+		r.Key = newKey
+		if err := m.updateTimestamps(&r.Timestamps); err != nil {
+			return err
+		}
+
+		blobs := make([]*blobref.BlobRef, len(blobKeys))
+		for i := range blobs {
+			blobs[i] = new(blobref.BlobRef)
+		}
+		if err := tx.GetMulti(blobKeys, blobs); err != nil {
+			merr, ok := err.(ds.MultiError)
+			if !ok {
+				return err
+			}
+			for i, e := range merr {
+				if e == ds.ErrNoSuchEntity {
+					// Deleted after the query.
+					blobs[i] = nil
+				} else if e != nil {
+					return e
+				}
+			}
+		}
+		entity, err := m.recordEntity(ctx, storeKey, r)
+		if err != nil {
+			return err
+		}
+		muts := []*ds.Mutation{ds.NewInsert(newRKey, entity), ds.NewDelete(oldRKey)}
+		for i, b := range blobs {
+			if b == nil || b.RecordKey != oldKey {
+				continue
+			}
+			b.RecordKey = newKey
+			if err := m.updateTimestamps(&b.Timestamps); err != nil {
+				return err
+			}
+			muts = append(muts, ds.NewUpdate(blobKeys[i], b))
+		}
+		_, err = tx.Mutate(muts...)
 		return err
 	})
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	if err := m.deleteRecordTokens(ctx, oldRKey); err != nil {
+		return err
+	}
+	return m.moveRecordCounters(ctx, storeKey, oldKey, newKey)
+}
+
+// InsertBlobRef inserts a new BlobRef object to the datastore.
+// OwnerID of the record is copied to the BlobRef unless it is already set.
+// It returns ErrInvalidKey if the store or record key of blob is rejected by
+// the KeyValidator, e.g. for records created before it was tightened.
+func (m *MetaDB) InsertBlobRef(ctx context.Context, blob *blobref.BlobRef) (*blobref.BlobRef, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.InsertBlobRef")
+	defer span.End()
+
+	if err := m.validateKeys(blob.StoreKey, blob.RecordKey); err != nil {
+		return nil, err
+	}
+	ts, err := m.newTimestamps()
+	if err != nil {
+		return nil, err
+	}
+	blob.Timestamps = ts
+	_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		return m.insertBlobRefInTransaction(tx, blob)
+	})
 	if err != nil {
 		return nil, datastoreErrToGRPCStatus(err)
 	}
 	return blob, nil
 }
 
+// insertBlobRefInTransaction inserts blob for its record, which must exist,
+// in tx. The OwnerID of the record is copied to blob if it is not set.
+func (m *MetaDB) insertBlobRefInTransaction(tx *ds.Transaction, blob *blobref.BlobRef) error {
+	record := new(record.Record)
+	if err := tx.Get(m.createRecordKey(blob.StoreKey, blob.RecordKey), record); err == ds.ErrNoSuchEntity {
+		return status.Error(codes.FailedPrecondition, "InsertBlob was called for a non-exitent record")
+	} else if err != nil {
+		return err
+	}
+	if blob.OwnerID == "" {
+		blob.OwnerID = record.OwnerID
+	}
+	_, err := tx.Mutate(ds.NewInsert(m.createBlobKey(blob.Key), blob))
+	return err
+}
+
 // UpdateBlobRef updates a BlobRef object with the new property values.
 // Returns a NotFound error if the key is not found.
 func (m *MetaDB) UpdateBlobRef(ctx context.Context, blob *blobref.BlobRef) (*blobref.BlobRef, error) {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.UpdateBlobRef")
 	defer span.End()
 
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		oldBlob, err := m.getBlobRef(ctx, tx, blob.Key)
 		if err != nil {
 			return err
@@ -449,6 +798,86 @@ func (m *MetaDB) UpdateBlobRef(ctx context.Context, blob *blobref.BlobRef) (*blo
 	return blob, nil
 }
 
+// BlobRefUpdater is a callback function for BlobRef updates.
+// Returning a non-nil error aborts the transaction.
+type BlobRefUpdater func(blob *blobref.BlobRef) (*blobref.BlobRef, error)
+
+// UpdateBlobRefWithUpdater reads the BlobRef with key and saves the BlobRef
+// returned by updater in a transaction, so that updater sees the current
+// BlobRef. Returning ErrNoUpdate from updater leaves the BlobRef unchanged,
+// in which case the current BlobRef is returned without an error.
+func (m *MetaDB) UpdateBlobRefWithUpdater(ctx context.Context, key uuid.UUID, updater BlobRefUpdater) (*blobref.BlobRef, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.UpdateBlobRefWithUpdater")
+	defer span.End()
+
+	if updater == nil {
+		return nil, status.Errorf(codes.Internal, "updater cannot be nil")
+	}
+	var toUpdate *blobref.BlobRef
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		current, err := m.getBlobRef(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		toUpdate = current
+		updated, err := updater(current)
+		if err != nil {
+			return err
+		}
+		updated.Key = key
+		updated.Timestamps.CreatedAt = current.Timestamps.CreatedAt
+		toUpdate = updated
+		_, err = tx.Mutate(ds.NewUpdate(m.createBlobKey(key), updated))
+		return err
+	})
+	// ErrNoUpdate is expected and not treated as an error.
+	if err != nil && err != ErrNoUpdate {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return toUpdate, nil
+}
+
+// SaveBlobRefs saves blobs, overwriting existing entities with the same keys.
+// BlobRefs that are invalid are not saved, and the returned error is a
+// *bulk.BatchError that has an InvalidArgument error for each of them while
+// the valid ones are saved. If saving the valid ones fails, every one of them
+// is reported as failed as Datastore saves them all or none.
+func (m *MetaDB) SaveBlobRefs(ctx context.Context, blobs []*blobref.BlobRef) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.SaveBlobRefs")
+	defer span.End()
+
+	batchErr := new(bulk.BatchError)
+	var indexes []int
+	var keys []*ds.Key
+	var valid []*blobref.BlobRef
+	for i, b := range blobs {
+		// Save validates the BlobRef without side effects.
+		if _, err := b.Save(); err != nil {
+			batchErr.Add(i, b.Key.String(), status.Error(codes.InvalidArgument, err.Error()))
+			continue
+		}
+		indexes = append(indexes, i)
+		keys = append(keys, m.createBlobKey(b.Key))
+		valid = append(valid, b)
+	}
+	if len(valid) > 0 {
+		if _, err := m.entities.PutMulti(ctx, keys, valid); err != nil {
+			merr, isMulti := err.(ds.MultiError)
+			for j, b := range valid {
+				itemErr := datastoreErrToGRPCStatus(err)
+				if isMulti {
+					itemErr = status.Error(codes.Aborted, "the BlobRef was not saved as another one failed")
+					if merr[j] != nil {
+						itemErr = status.Error(codes.InvalidArgument, merr[j].Error())
+					}
+				}
+				batchErr.Add(indexes[j], b.Key.String(), itemErr)
+			}
+		}
+	}
+	return batchErr.ErrOrNil()
+}
+
 // GetBlobRef returns a BlobRef object specified by the key.
 // Returns errors:
 //   - NotFound: the object is not found.
@@ -478,7 +907,7 @@ func (m *MetaDB) GetCurrentBlobRef(ctx context.Context, storeKey, recordKey stri
 	defer span.End()
 
 	var blob *blobref.BlobRef
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		var err error
 		blob, err = m.getCurrentBlobRef(ctx, tx, storeKey, recordKey)
 		return err
@@ -505,6 +934,8 @@ func (m *MetaDB) getReadyChunks(ctx context.Context, tx *ds.Transaction, blob *b
 }
 
 // return size, chunk count, error
+// If the chunks declare their byte ranges, it also checks that the ranges
+// cover the blob exactly and returns FailedPrecondition otherwise.
 func (m *MetaDB) chunkObjectsSizeSum(ctx context.Context, tx *ds.Transaction, blob *blobref.BlobRef) (int64, int64, error) {
 	chunks, err := m.getReadyChunks(ctx, tx, blob)
 	if err != nil {
@@ -514,6 +945,11 @@ func (m *MetaDB) chunkObjectsSizeSum(ctx context.Context, tx *ds.Transaction, bl
 	for _, chunk := range chunks {
 		size += int64(chunk.Size)
 	}
+	if chunkref.HasRanges(chunks) {
+		if err := chunkref.ValidateChunkRanges(chunks, size); err != nil {
+			return 0, 0, status.Errorf(codes.FailedPrecondition, "chunks of blob (%v) don't cover the blob: %v", blob.Key, err)
+		}
+	}
 	return size, int64(len(chunks)), nil
 }
 
@@ -526,67 +962,89 @@ func (m *MetaDB) PromoteBlobRefToCurrent(ctx context.Context, blob *blobref.Blob
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.PromoteBlobRefToCurrent")
 	defer span.End()
 
+	var record *record.Record
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		var err error
+		record, _, err = m.promoteBlobRefInTransaction(ctx, tx, blob)
+		return err
+	})
+	if err != nil {
+		return nil, nil, datastoreErrToGRPCStatus(err)
+	}
+	return record, blob, nil
+}
+
+// promoteBlobRefInTransaction makes blob the current external blob of the record
+// in tx, and marks the previous external blob for deletion.
+// Returns the updated record and the previous BlobRef, which is nil if the record
+// didn't have an external blob.
+func (m *MetaDB) promoteBlobRefInTransaction(ctx context.Context, tx *ds.Transaction, blob *blobref.BlobRef) (*record.Record, *blobref.BlobRef, error) {
 	record := new(record.Record)
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
-		rkey := m.createRecordKey(blob.StoreKey, blob.RecordKey)
-		if err := tx.Get(rkey, record); err != nil {
-			return err
+	rkey := m.createRecordKey(blob.StoreKey, blob.RecordKey)
+	if err := tx.Get(rkey, record); err != nil {
+		return nil, nil, err
+	}
+	var oldBlob *blobref.BlobRef
+	if record.ExternalBlob == uuid.Nil {
+		// Simply add the new blob if previously didn't have a blob
+		record = removeInlineBlob(record)
+	} else {
+		// Mark previous blob for deletion
+		var err error
+		oldBlob, err = m.getBlobRef(ctx, tx, record.ExternalBlob)
+		if err != nil {
+			return nil, nil, err
 		}
-		if record.ExternalBlob == uuid.Nil {
-			// Simply add the new blob if previously didn't have a blob
-			record = removeInlineBlob(record)
-		} else {
-			// Mark previous blob for deletion
-			oldBlob, err := m.getBlobRef(ctx, tx, record.ExternalBlob)
-			if err != nil {
-				return err
-			}
-			record, err = m.markBlobRefForDeletion(tx, record, oldBlob, blob.Key)
-			if err != nil {
-				return err
-			}
+		record, err = m.markBlobRefForDeletion(tx, record, oldBlob, blob.Key)
+		if err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// Update the blob size for chunked uploads
-		if blob.Chunked {
-			// TODO(yuryu): should check if chunks are continuous?
-			size, count, err := m.chunkObjectsSizeSum(ctx, tx, blob)
-			if err != nil {
-				return err
-			}
-			if blob.ChunkCount != 0 && blob.ChunkCount != count {
-				return status.Errorf(codes.FailedPrecondition, "expected chunk count doesn't match: expected (%v), actual (%v)", blob.ChunkCount, count)
-			}
-			blob.ChunkCount = count
-			record.ChunkCount = count
-			blob.Size = size
-		} else {
-			record.ChunkCount = 0
-		}
-		if blob.Status != blobref.StatusReady {
-			if blob.Ready() != nil {
-				return status.Error(codes.Internal, "blob is not ready to become current")
-			}
+	// Update the blob size for chunked uploads
+	if blob.Chunked {
+		size, count, err := m.chunkObjectsSizeSum(ctx, tx, blob)
+		if err != nil {
+			return nil, nil, err
 		}
-		blob.Timestamps.Update()
-		if _, err := tx.Mutate(ds.NewUpdate(m.createBlobKey(blob.Key), blob)); err != nil {
-			return err
+		if blob.ChunkCount != 0 && blob.ChunkCount != count {
+			return nil, nil, status.Errorf(codes.FailedPrecondition, "expected chunk count doesn't match: expected (%v), actual (%v)", blob.ChunkCount, count)
 		}
-
-		record.BlobSize = blob.Size
-		record.ExternalBlob = blob.Key
-		record.Chunked = blob.Chunked
-		record.Timestamps.Update()
-		if err := m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, record)); err != nil {
-			return err
+		blob.ChunkCount = count
+		record.ChunkCount = count
+		blob.Size = size
+	} else {
+		record.ChunkCount = 0
+	}
+	if blob.Status != blobref.StatusReady {
+		if blob.Ready() != nil {
+			return nil, nil, status.Error(codes.Internal, "blob is not ready to become current")
 		}
+	}
+	if blob.OwnerID == "" {
+		blob.OwnerID = record.OwnerID
+	}
+	if err := m.updateTimestamps(&blob.Timestamps); err != nil {
+		return nil, nil, err
+	}
+	if _, err := tx.Mutate(ds.NewUpdate(m.createBlobKey(blob.Key), blob)); err != nil {
+		return nil, nil, err
+	}
 
-		return nil
-	})
+	record.BlobSize = blob.Size
+	record.ExternalBlob = blob.Key
+	record.Chunked = blob.Chunked
+	if err := m.updateTimestamps(&record.Timestamps); err != nil {
+		return nil, nil, err
+	}
+	entity, err := m.recordEntity(ctx, blob.StoreKey, record)
 	if err != nil {
-		return nil, nil, datastoreErrToGRPCStatus(err)
+		return nil, nil, err
 	}
-	return record, blob, nil
+	if err := m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity)); err != nil {
+		return nil, nil, err
+	}
+	return record, oldBlob, nil
 }
 
 // PromoteBlobRefWithRecordUpdater promotes the provided BlobRef object as a current
@@ -599,7 +1057,7 @@ func (m *MetaDB) PromoteBlobRefWithRecordUpdater(ctx context.Context, blob *blob
 	defer span.End()
 
 	record := new(record.Record)
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		rkey := m.createRecordKey(blob.StoreKey, blob.RecordKey)
 		if err := tx.Get(rkey, record); err != nil {
 			return err
@@ -643,17 +1101,28 @@ func (m *MetaDB) PromoteBlobRefWithRecordUpdater(ctx context.Context, blob *blob
 				return status.Error(codes.Internal, "blob is not ready to become current")
 			}
 		}
-		blob.Timestamps.Update()
+		if blob.OwnerID == "" {
+			blob.OwnerID = record.OwnerID
+		}
+		if err := m.updateTimestamps(&blob.Timestamps); err != nil {
+			return err
+		}
 		if _, err := tx.Mutate(ds.NewUpdate(m.createBlobKey(blob.Key), blob)); err != nil {
 			return err
 		}
 
 		// Call the custom defined updater method as well to modify the record.
+		oldProperties, oldUpdatedAt := record.Properties.Clone(), record.Timestamps.UpdatedAt
 		record, err := updater(record)
 		if err != nil {
 			return err
 		}
-		if err := m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, record)); err != nil {
+		record.StampPropertyChanges(oldProperties, oldUpdatedAt, record.Timestamps.UpdatedAt)
+		entity, err := m.recordEntity(ctx, blob.StoreKey, record)
+		if err != nil {
+			return err
+		}
+		if err := m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity)); err != nil {
 			return err
 		}
 
@@ -679,7 +1148,7 @@ func (m *MetaDB) RemoveBlobFromRecord(ctx context.Context, storeKey string, reco
 	rkey := m.createRecordKey(storeKey, recordKey)
 	blob := new(blobref.BlobRef)
 	record := new(record.Record)
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		err := tx.Get(rkey, record)
 		if err != nil {
 			return err
@@ -688,10 +1157,16 @@ func (m *MetaDB) RemoveBlobFromRecord(ctx context.Context, storeKey string, reco
 		record.BlobSize = 0
 		record.Chunked = false
 		record.ChunkCount = 0
-		if record.ExternalBlob == uuid.Nil && len(record.Blob) > 0 {
+		if record.ExternalBlob == uuid.Nil && record.HasInlineBlob() {
 			record = removeInlineBlob(record)
-			record.Timestamps.Update()
-			return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, record))
+			if err := m.updateTimestamps(&record.Timestamps); err != nil {
+				return err
+			}
+			entity, err := m.recordEntity(ctx, storeKey, record)
+			if err != nil {
+				return err
+			}
+			return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity))
 		}
 
 		// Otherwise, clear the association
@@ -723,7 +1198,11 @@ func (m *MetaDB) RemoveBlobFromRecord(ctx context.Context, storeKey string, reco
 		if err != nil {
 			return err
 		}
-		return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, record))
+		entity, err := m.recordEntity(ctx, storeKey, record)
+		if err != nil {
+			return err
+		}
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(rkey, entity))
 	})
 	if err != nil {
 		return nil, nil, datastoreErrToGRPCStatus(err)
@@ -757,7 +1236,7 @@ func (m *MetaDB) DeleteBlobRef(ctx context.Context, key uuid.UUID) error {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteBlobRef")
 	defer span.End()
 
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		blob, err := m.getBlobRef(ctx, tx, key)
 		if err != nil {
 			return err
@@ -783,7 +1262,7 @@ func (m *MetaDB) DeleteChunkRef(ctx context.Context, blobKey, key uuid.UUID) err
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteChunkRef")
 	defer span.End()
 
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		var chunk chunkref.ChunkRef
 		if err := tx.Get(m.createChunkRefKey(blobKey, key), &chunk); err != nil {
 			return err
@@ -807,6 +1286,197 @@ func (m *MetaDB) ListBlobRefsByStatus(ctx context.Context, status blobref.Status
 	return iter, nil
 }
 
+// BlobRefFilter specifies conditions for QueryBlobRefs.
+// Zero values mean the condition is not applied.
+type BlobRefFilter struct {
+	// StoreKey matches BlobRefs that belong to the store.
+	StoreKey string
+	// Status matches BlobRefs with the status.
+	Status *blobref.Status
+	// UpdatedBefore matches BlobRefs last updated before the time.
+	// BlobRefs saved before Timestamps.UpdatedAt was indexed don't match until
+	// they are updated.
+	UpdatedBefore time.Time
+	// ExpiresBefore matches BlobRefs that expire before the time.
+	ExpiresBefore time.Time
+	// MinSize and MaxSize match BlobRefs with MinSize <= Size <= MaxSize.
+	MinSize int64
+	MaxSize int64
+	// StartKey and EndKey match BlobRefs with StartKey <= Key < EndKey, in the
+	// order of the string forms of the keys. Either can be uuid.Nil to leave
+	// the range open on that side.
+	StartKey uuid.UUID
+	EndKey   uuid.UUID
+	// Limit is the maximum number of BlobRefs to return.
+	Limit int
+}
+
+// QueryBlobRefs returns BlobRefs that match all conditions set in filter.
+// Datastore only permits inequality filters on a single property, therefore
+// setting more than one of UpdatedBefore, ExpiresBefore, MinSize or MaxSize, and
+// StartKey or EndKey returns ErrInequalityFilterConflict.
+func (m *MetaDB) QueryBlobRefs(ctx context.Context, filter BlobRefFilter) ([]*blobref.BlobRef, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.QueryBlobRefs")
+	defer span.End()
+
+	query, err := m.blobRefsQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := blobref.NewCursor(m.client.Run(ctx, query))
+	var match []*blobref.BlobRef
+	for {
+		b, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, datastoreErrToGRPCStatus(err)
+		}
+		match = append(match, b)
+	}
+	return match, nil
+}
+
+// blobRefsQuery returns the query that QueryBlobRefs runs for filter.
+func (m *MetaDB) blobRefsQuery(filter BlobRefFilter) (*ds.Query, error) {
+	query, err := m.blobRefFilterQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	return m.readQuery("QueryBlobRefs", query), nil
+}
+
+// blobRefFilterQuery returns the Datastore query of the conditions in filter
+// other than Limit.
+func (m *MetaDB) blobRefFilterQuery(filter BlobRefFilter) (*ds.Query, error) {
+	inequalities := 0
+	for _, has := range []bool{
+		!filter.UpdatedBefore.IsZero(),
+		!filter.ExpiresBefore.IsZero(),
+		filter.MinSize > 0 || filter.MaxSize > 0,
+		filter.StartKey != uuid.Nil || filter.EndKey != uuid.Nil,
+	} {
+		if has {
+			inequalities++
+		}
+	}
+	if inequalities > 1 {
+		return nil, ErrInequalityFilterConflict
+	}
+
+	query := m.newQuery(blobKind)
+	if filter.StoreKey != "" {
+		query = query.Filter("StoreKey =", filter.StoreKey)
+	}
+	if filter.Status != nil {
+		query = query.Filter("Status =", int(*filter.Status))
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		query = query.Filter("Timestamps.UpdatedAt <", filter.UpdatedBefore)
+	}
+	if !filter.ExpiresBefore.IsZero() {
+		query = query.Filter("ExpiresAt <", filter.ExpiresBefore)
+	}
+	if filter.MinSize > 0 {
+		query = query.Filter("Size >=", filter.MinSize)
+	}
+	if filter.MaxSize > 0 {
+		query = query.Filter("Size <=", filter.MaxSize)
+	}
+	if filter.StartKey != uuid.Nil {
+		query = query.Filter("__key__ >=", m.createBlobKey(filter.StartKey))
+	}
+	if filter.EndKey != uuid.Nil {
+		query = query.Filter("__key__ <", m.createBlobKey(filter.EndKey))
+	}
+	return query, nil
+}
+
+// ListBlobsOlderThan returns BlobRefs of the store that have not been updated
+// for age. It uses the UpdatedBefore filter of QueryBlobRefs, so BlobRefs that
+// were created long ago but updated recently are not included.
+func (m *MetaDB) ListBlobsOlderThan(ctx context.Context, storeKey string, age time.Duration) ([]*blobref.BlobRef, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ListBlobsOlderThan")
+	defer span.End()
+
+	return m.QueryBlobRefs(ctx, BlobRefFilter{StoreKey: storeKey, UpdatedBefore: time.Now().Add(-age)})
+}
+
+// Consistency is the read consistency of a query.
+type Consistency int
+
+const (
+	// ConsistencyStrong makes queries see all writes committed before the query.
+	ConsistencyStrong Consistency = iota
+	// ConsistencyEventual makes queries faster, but they may not see recent writes.
+	ConsistencyEventual
+)
+
+func (c Consistency) apply(q *ds.Query) *ds.Query {
+	if c == ConsistencyEventual {
+		return q.EventualConsistency()
+	}
+	return q
+}
+
+// FindBlobRefByChecksum returns a Ready BlobRef in the store whose size and MD5
+// hash match size and md5, for clients to reuse existing content.
+// MD5 hashes are not indexed, so they are compared after querying by size.
+// With ConsistencyEventual, a blob that became Ready just before the call may
+// not be found, in which case the client uploads the content again.
+// Returns NotFound if there is no match.
+func (m *MetaDB) FindBlobRefByChecksum(ctx context.Context, storeKey string, md5 []byte, size int64, consistency Consistency) (*blobref.BlobRef, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.FindBlobRefByChecksum")
+	defer span.End()
+
+	query := consistency.apply(m.newQuery(blobKind).Filter("StoreKey =", storeKey).
+		Filter("Status =", int(blobref.StatusReady)).Filter("Size =", size))
+	iter := blobref.NewCursor(m.client.Run(ctx, query))
+	for {
+		b, err := iter.Next()
+		if err == iterator.Done {
+			return nil, status.Errorf(codes.NotFound, "no blob with the checksum was found in store (%v)", storeKey)
+		}
+		if err != nil {
+			return nil, datastoreErrToGRPCStatus(err)
+		}
+		if len(b.MD5) != 0 && bytes.Equal(b.MD5, md5) {
+			return b, nil
+		}
+	}
+}
+
+// FindBlobRefByContentHash returns a Ready BlobRef in the store whose size and
+// content hash match size and hash, and whose content hash was calculated with
+// algorithm. Blobs hashed with other algorithms never match, even if the hash
+// values happen to be equal. BlobRefs saved while content hashes were not
+// indexed are not found until they are saved again. Consistency is the same
+// as FindBlobRefByChecksum.
+// Returns NotFound if there is no match.
+func (m *MetaDB) FindBlobRefByContentHash(ctx context.Context, storeKey string, algorithm checksums.HashAlgorithm,
+	hash []byte, size int64, consistency Consistency) (*blobref.BlobRef, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.FindBlobRefByContentHash")
+	defer span.End()
+
+	query := consistency.apply(m.newQuery(blobKind).Filter("StoreKey =", storeKey).
+		Filter("Status =", int(blobref.StatusReady)).Filter("Size =", size).
+		Filter("ContentHashAlgorithm =", string(algorithm)).Filter("ContentHash =", hash).Limit(1))
+	iter := blobref.NewCursor(m.client.Run(ctx, query))
+	b, err := iter.Next()
+	if err == iterator.Done {
+		return nil, status.Errorf(codes.NotFound, "no blob with the %v content hash was found in store (%v)", algorithm, storeKey)
+	}
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return b, nil
+}
+
 // ListChunkRefsByStatus returns a cursor that iterates over ChunkRefs
 // where Status = status.
 func (m *MetaDB) ListChunkRefsByStatus(ctx context.Context, status blobref.Status) *chunkref.ChunkRefCursor {
@@ -828,8 +1498,18 @@ func (m *MetaDB) GetChildChunkRefs(ctx context.Context, blobKey uuid.UUID) *chun
 }
 
 // addPropertyFilter augments a query with the QueryFilter operations.
-func addPropertyFilter(q *ds.Query, f *pb.QueryFilter) (*ds.Query, error) {
-	filter := propertiesField + "." + f.PropertyName
+func (m *MetaDB) addPropertyFilter(q *ds.Query, f *pb.QueryFilter) (*ds.Query, error) {
+	switch f.GetValue().GetType() {
+	case pb.Property_BYTES:
+		return nil, status.Errorf(codes.InvalidArgument, "BYTES properties are not indexed and cannot be filtered: %v", f.PropertyName)
+	case pb.Property_LIST:
+		return nil, status.Errorf(codes.InvalidArgument, "LIST values cannot be used in filters, use CONTAINS with an element instead: %v", f.PropertyName)
+	}
+	name, err := record.EncodePropertyName(f.PropertyName, m.PropertyNameMode)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	filter := propertiesField + "." + name
 	switch f.Operator {
 	case pb.FilterOperator_EQUAL:
 		filter += "="
@@ -841,60 +1521,71 @@ func addPropertyFilter(q *ds.Query, f *pb.QueryFilter) (*ds.Query, error) {
 		filter += ">="
 	case pb.FilterOperator_LESS_OR_EQUAL:
 		filter += "<="
+	case pb.FilterOperator_CONTAINS:
+		// An equality filter on an array value matches entities that have
+		// an element equal to the value.
+		filter += "="
 	default:
 		return nil, status.Errorf(codes.Unimplemented, "unknown filter operator detected: %+v", f.Operator)
 	}
 	return q.Filter(filter, record.ExtractValue(f.Value)), nil
 }
 
-// QueryRecords returns a list of records that match the given filters.
-func (m *MetaDB) QueryRecords(ctx context.Context, req *pb.QueryRecordsRequest) ([]*record.Record, error) {
-	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.QueryRecords")
-	defer span.End()
+// RecordFilter has the conditions of QueryRecords and CountRecords.
+// Records match if they have all of the conditions.
+type RecordFilter struct {
+	// OwnerID matches records owned by the owner.
+	OwnerID string
+	// Tags matches records that have all of the tags.
+	Tags []string
+	// Filters matches records whose properties satisfy all of the filters.
+	Filters []*pb.QueryFilter
+}
 
+// recordFilterQuery returns the Datastore query of the records in the store
+// with storeKey, or all stores if empty, that match filter.
+func (m *MetaDB) recordFilterQuery(storeKey string, filter RecordFilter) (*ds.Query, error) {
 	query := m.newQuery(recordKind)
-	if req.GetStoreKey() != "" {
-		dsKey := m.createStoreKey(req.GetStoreKey())
-		query = query.Ancestor(dsKey)
+	if storeKey != "" {
+		query = query.Ancestor(m.createStoreKey(storeKey))
 	}
-	if owner := req.GetOwnerId(); owner != "" {
-		query = query.Filter(ownerField+"=", owner)
+	if filter.OwnerID != "" {
+		query = query.Filter(ownerField+"=", filter.OwnerID)
 	}
-	for _, f := range req.GetFilters() {
-		q, err := addPropertyFilter(query, f)
+	for _, f := range filter.Filters {
+		q, err := m.addPropertyFilter(query, f)
 		if err != nil {
 			return nil, err
 		}
 		query = q
 	}
-	for _, t := range req.GetTags() {
+	for _, t := range filter.Tags {
 		query = query.Filter(tagsField+"=", t)
 	}
-	for _, s := range req.GetSortOrders() {
-		var property string
-		switch s.Property {
-		case pb.SortOrder_CREATED_AT:
-			property = "Timestamps.CreatedAt"
-		case pb.SortOrder_UPDATED_AT:
-			property = "Timestamps.UpdatedAt"
-		case pb.SortOrder_USER_PROPERTY:
-			if s.UserPropertyName == "" {
-				return nil, status.Error(codes.InvalidArgument, "got empty user sort property")
-			}
-			property = fmt.Sprintf("%s.%s", propertiesField, s.UserPropertyName)
-		default:
-			return nil, status.Errorf(codes.InvalidArgument, "got invalid SortOrder property value: %v", s.Property)
-		}
+	return query, nil
+}
 
-		switch s.Direction {
-		case pb.SortOrder_ASC:
-			query = query.Order(strconv.Quote(property))
-		case pb.SortOrder_DESC:
-			query = query.Order("-" + strconv.Quote(property))
-		default:
-			return nil, status.Errorf(codes.InvalidArgument, "got invalid SortOrder direction value: %v", s.Direction)
-		}
+// QueryRecords returns a list of records that match the given filters.
+// Records that have expired are not returned, so fewer records than the limit
+// may be returned.
+func (m *MetaDB) QueryRecords(ctx context.Context, req *pb.QueryRecordsRequest) ([]*record.Record, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.QueryRecords")
+	defer span.End()
+
+	query, err := m.recordFilterQuery(req.GetStoreKey(), RecordFilter{
+		OwnerID: req.GetOwnerId(),
+		Tags:    req.GetTags(),
+		Filters: req.GetFilters(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	query = m.readQuery("QueryRecords", query)
+	query, err = m.orderRecordQuery(query, req.GetSortOrders())
+	if err != nil {
+		return nil, err
 	}
+
 	// Determine if we query keys only based on offset and request params
 
 	if limit := req.GetLimit(); limit > 0 {
@@ -939,21 +1630,94 @@ func (m *MetaDB) QueryRecords(ctx context.Context, req *pb.QueryRecordsRequest)
 	}
 
 	// If an offset was passed and the clients want full records, fetch records by keys
-	var err error
 	if useOffset && !req.GetKeysOnly() {
 		match = make([]*record.Record, len(keys))
-		if err = m.client.GetMulti(ctx, keys, match); err != nil {
+		if err = m.entities.GetMulti(ctx, keys, match); err != nil {
 			if _, ok := err.(ds.MultiError); !ok {
 				// Datastore internal error
 				return nil, datastoreErrToGRPCStatus(err)
 			}
+			return match, m.toGRPCStatus(err)
+		}
+	}
+
+	if req.GetKeysOnly() {
+		return m.removeExpiredRecordKeys(ctx, match, time.Now())
+	}
+	return removeExpiredRecords(match, time.Now()), nil
+}
+
+// removeExpiredRecords returns records without the ones that have expired at
+// now but haven't been deleted by DeleteExpiredRecords yet.
+func removeExpiredRecords(records []*record.Record, now time.Time) []*record.Record {
+	var live []*record.Record
+	for _, r := range records {
+		if !r.IsExpired(now) {
+			live = append(live, r)
 		}
 	}
+	return live
+}
 
-	return match, m.toGRPCStatus(err)
+// removeExpiredRecordKeys is removeExpiredRecords for the records of keys-only
+// queries, which don't have ExpiresAt. It queries the keys of all records that
+// have expired at now, which are only the ones that DeleteExpiredRecords
+// hasn't deleted yet.
+func (m *MetaDB) removeExpiredRecordKeys(ctx context.Context, records []*record.Record, now time.Time) ([]*record.Record, error) {
+	if len(records) == 0 {
+		return records, nil
+	}
+	query := m.newQuery(recordKind).KeysOnly().Filter("ExpiresAt <=", now)
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	if len(keys) == 0 {
+		return records, nil
+	}
+	expired := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		expired[k.String()] = true
+	}
+	var live []*record.Record
+	for _, r := range records {
+		if !expired[m.createRecordKey(r.StoreKey, r.Key).String()] {
+			live = append(live, r)
+		}
+	}
+	return live, nil
+}
+
+// recordExpired reports whether the record of rkey has expired at now, using
+// a projection of ExpiresAt so that the record isn't read in full. Records
+// without ExpiresAt, which is omitted if empty, are not in the result.
+func (m *MetaDB) recordExpired(ctx context.Context, rkey *ds.Key, now time.Time) (bool, error) {
+	query := m.newQuery(recordKind).Filter("__key__ = ", rkey).Project("ExpiresAt")
+	var results []ds.PropertyList
+	if _, err := m.client.GetAll(ctx, query, &results); err != nil {
+		return false, datastoreErrToGRPCStatus(err)
+	}
+	for _, ps := range results {
+		for _, p := range ps {
+			if p.Name != "ExpiresAt" {
+				continue
+			}
+			r := record.Record{}
+			switch v := p.Value.(type) {
+			case time.Time:
+				r.ExpiresAt = v
+			case int64:
+				// Projections may return timestamps as microseconds.
+				r.ExpiresAt = time.UnixMicro(v)
+			}
+			return r.IsExpired(now), nil
+		}
+	}
+	return false, nil
 }
 
 // GetRecords returns records by using the get multi request interface from datastore.
+// Records that have expired are nil with ErrRecordExpired in the MultiError.
 func (m *MetaDB) GetRecords(ctx context.Context, storeKeys, recordKeys []string) ([]*record.Record, error) {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetRecords")
 	defer span.End()
@@ -966,12 +1730,26 @@ func (m *MetaDB) GetRecords(ctx context.Context, storeKeys, recordKeys []string)
 
 	// Query the datastore for the records by keys
 	records := make([]*record.Record, len(keys))
-	if err = m.client.GetMulti(ctx, keys, records); err != nil {
+	if err = m.entities.GetMulti(ctx, keys, records); err != nil {
 		if _, ok := err.(ds.MultiError); !ok {
 			// Datastore internal error
 			return nil, datastoreErrToGRPCStatus(err)
 		}
 	}
+	// Expired records are reported as ErrRecordExpired just like GetRecord.
+	now := time.Now()
+	for i, r := range records {
+		if r == nil || !r.IsExpired(now) {
+			continue
+		}
+		merr, ok := err.(ds.MultiError)
+		if !ok {
+			merr = make(ds.MultiError, len(records))
+			err = merr
+		}
+		records[i] = nil
+		merr[i] = ErrRecordExpired
+	}
 	return records, m.toGRPCStatus(err)
 }
 
@@ -1029,7 +1807,7 @@ func (m *MetaDB) FindChunkRefByNumber(ctx context.Context, storeKey, recordKey s
 	defer span.End()
 
 	chunks := []*chunkref.ChunkRef{}
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		blob, err := m.getCurrentBlobRef(ctx, tx, storeKey, recordKey)
 		if err != nil {
 			return err
@@ -1065,18 +1843,23 @@ func (m *MetaDB) ValidateChunkRefPreconditions(ctx context.Context, chunk *chunk
 }
 
 // InsertChunkRef inserts a new ChunkRef object to the datastore. If the current session has another chunk
-// with the same Number, it will be marked for deletion.
+// with the same Number, it will be marked for deletion, unless chunk is not Ready, e.g. it failed validation.
 func (m *MetaDB) InsertChunkRef(ctx context.Context, blob *blobref.BlobRef, chunk *chunkref.ChunkRef) error {
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.InsertChunkRef")
 	defer span.End()
 
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 
 		mut := ds.NewInsert(m.createChunkRefKey(chunk.BlobRef, chunk.Key), chunk)
 		if err := m.mutateSingleInTransaction(tx, mut); err != nil {
 			return err
 		}
 
+		// A failed chunk doesn't replace the existing one.
+		if chunk.Status != blobref.StatusReady {
+			return nil
+		}
+
 		// Mark any other (should be at most one though) Ready chunks for deletion.
 		otherChunks, err := m.findChunkRefsByNumber(ctx, tx, blob.StoreKey, blob.RecordKey, chunk.BlobRef, chunk.Number)
 		if err != nil {
@@ -1115,7 +1898,7 @@ func (m *MetaDB) MarkUncommittedBlobForDeletion(ctx context.Context, key uuid.UU
 	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.MarkUncommittedBlobForDeletion")
 	defer span.End()
 
-	_, err := m.client.RunInTransaction(ctx, func(tx *ds.Transaction) error {
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
 		blob, err := m.getBlobRef(ctx, tx, key)
 		if err != nil {
 			return err
@@ -1126,7 +1909,9 @@ func (m *MetaDB) MarkUncommittedBlobForDeletion(ctx context.Context, key uuid.UU
 		if err := blob.MarkForDeletion(); err != nil {
 			return err
 		}
-		blob.Timestamps.Update()
+		if err := m.updateTimestamps(&blob.Timestamps); err != nil {
+			return err
+		}
 		return m.mutateSingleInTransaction(tx, ds.NewUpdate(m.createBlobKey(key), blob))
 	})
 	return err
@@ -27,6 +27,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/uuid"
 	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/bulk"
 	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
@@ -709,6 +710,146 @@ func TestMetaDB_UpdateBlobRef(t *testing.T) {
 	}
 }
 
+func TestMetaDB_UpdateBlobRefWithUpdater(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	blob := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(1, st.Key, r.Key))
+
+	updated, err := metaDB.UpdateBlobRefWithUpdater(ctx, blob.Key, func(b *blobref.BlobRef) (*blobref.BlobRef, error) {
+		assert.Equal(t, blobref.StatusInitializing, b.Status)
+		b.FailureReason = "reason"
+		b.Fail()
+		return b, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusError, updated.Status)
+
+	got, err := metaDB.GetBlobRef(ctx, blob.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusError, got.Status)
+	assert.Equal(t, "reason", got.FailureReason)
+
+	// ErrNoUpdate leaves the BlobRef unchanged.
+	updated, err = metaDB.UpdateBlobRefWithUpdater(ctx, blob.Key, func(b *blobref.BlobRef) (*blobref.BlobRef, error) {
+		b.FailureReason = ""
+		return nil, m.ErrNoUpdate
+	})
+	require.NoError(t, err)
+	assert.Equal(t, blob.Key, updated.Key)
+	got, err = metaDB.GetBlobRef(ctx, blob.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "reason", got.FailureReason)
+
+	_, err = metaDB.UpdateBlobRefWithUpdater(ctx, uuid.New(), func(b *blobref.BlobRef) (*blobref.BlobRef, error) {
+		return b, nil
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMetaDB_SaveBlobRefs(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+
+	existing := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(1, st.Key, r.Key))
+	existing.Size = 2
+	added := blobref.NewBlobRef(3, st.Key, r.Key)
+	// Save fails as StoreKey and RecordKey are missing.
+	invalid := blobref.NewBlobRef(4, "", "")
+	t.Cleanup(func() {
+		newDatastoreClient(ctx, t).Delete(ctx, blobRefKey(added.Key))
+	})
+
+	err := metaDB.SaveBlobRefs(ctx, []*blobref.BlobRef{existing, invalid, added})
+	var batchErr *bulk.BatchError
+	if assert.ErrorAs(t, err, &batchErr) && assert.Len(t, batchErr.Items, 1) {
+		assert.Equal(t, 1, batchErr.Items[0].Index)
+		assert.Equal(t, invalid.Key.String(), batchErr.Items[0].Key)
+		assert.Equal(t, codes.InvalidArgument, status.Code(batchErr.Items[0].Err))
+	}
+	succeeded, failed := bulk.Partition(3, err)
+	assert.Equal(t, []int{0, 2}, succeeded)
+	assert.Equal(t, []int{1}, failed)
+
+	for _, b := range []*blobref.BlobRef{existing, added} {
+		got, err := metaDB.GetBlobRef(ctx, b.Key)
+		if assert.NoError(t, err) {
+			assert.Equal(t, b.Size, got.Size)
+		}
+	}
+	_, err = metaDB.GetBlobRef(ctx, invalid.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMetaDB_FindBlobRefByChecksum(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	md5 := []byte("0123456789abcdef")
+	b := blobref.NewBlobRef(10, st.Key, r.Key)
+	b.MD5 = md5
+	require.NoError(t, b.Ready())
+	setupTestBlobRef(ctx, t, metaDB, b)
+
+	got, err := metaDB.FindBlobRefByChecksum(ctx, st.Key, md5, 10, m.ConsistencyStrong)
+	if assert.NoError(t, err) {
+		assert.Equal(t, b.Key, got.Key)
+	}
+	_, err = metaDB.FindBlobRefByChecksum(ctx, st.Key, md5, 11, m.ConsistencyStrong)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = metaDB.FindBlobRefByChecksum(ctx, st.Key, make([]byte, 16), 10, m.ConsistencyStrong)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Eventually consistent lookups may miss the blob, but never return another one.
+	got, err = metaDB.FindBlobRefByChecksum(ctx, st.Key, md5, 10, m.ConsistencyEventual)
+	if err == nil {
+		assert.Equal(t, b.Key, got.Key)
+	} else {
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+	_, err = metaDB.FindBlobRefByChecksum(ctx, st.Key, md5, 11, m.ConsistencyEventual)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMetaDB_FindBlobRefByContentHash(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	hash := []byte("0123456789abcdef0123456789abcdef")
+	b := blobref.NewBlobRef(10, st.Key, r.Key)
+	b.ContentHash = hash
+	b.ContentHashAlgorithm = checksums.HashAlgorithmSHA256
+	require.NoError(t, b.Ready())
+	setupTestBlobRef(ctx, t, metaDB, b)
+
+	// The algorithm tag is persisted.
+	saved, err := metaDB.GetBlobRef(ctx, b.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, checksums.HashAlgorithmSHA256, saved.ContentHashAlgorithm)
+		assert.Equal(t, hash, saved.ContentHash)
+	}
+
+	got, err := metaDB.FindBlobRefByContentHash(ctx, st.Key, checksums.HashAlgorithmSHA256, hash, 10, m.ConsistencyStrong)
+	if assert.NoError(t, err) {
+		assert.Equal(t, b.Key, got.Key)
+	}
+	_, err = metaDB.FindBlobRefByContentHash(ctx, st.Key, checksums.HashAlgorithmSHA256, hash, 11, m.ConsistencyStrong)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = metaDB.FindBlobRefByContentHash(ctx, st.Key, checksums.HashAlgorithmSHA256, make([]byte, 32), 10, m.ConsistencyStrong)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// The same hash value doesn't match blobs hashed with another algorithm.
+	_, err = metaDB.FindBlobRefByContentHash(ctx, st.Key, checksums.HashAlgorithmMD5, hash, 10, m.ConsistencyStrong)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = metaDB.FindBlobRefByChecksum(ctx, st.Key, hash, 10, m.ConsistencyStrong)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func TestMetaDB_BlobInsertShouldFailForNonexistentRecord(t *testing.T) {
 	ctx := context.Background()
 	metaDB := newMetaDB(ctx, t)
@@ -882,6 +1023,197 @@ func TestMetaDB_ListBlobsByStatus(t *testing.T) {
 	}
 }
 
+func setupQueryBlobRefs(ctx context.Context, t *testing.T, metaDB *m.MetaDB) (string, []*blobref.BlobRef) {
+	t.Helper()
+	storeKey := newStoreKey()
+	recordKey := newRecordKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey},
+		&record.Record{Key: recordKey, Properties: make(record.PropertyMap)})
+
+	blobs := []*blobref.BlobRef{
+		{Key: uuid.New(), Size: 10, Status: blobref.StatusReady, StoreKey: storeKey, RecordKey: recordKey},
+		{Key: uuid.New(), Size: 100, Status: blobref.StatusReady, StoreKey: storeKey, RecordKey: recordKey},
+		{Key: uuid.New(), Size: 1000, Status: blobref.StatusReady, StoreKey: storeKey, RecordKey: recordKey},
+		{Key: uuid.New(), Size: 100, Status: blobref.StatusError, StoreKey: storeKey, RecordKey: recordKey},
+	}
+	for _, b := range blobs {
+		setupTestBlobRef(ctx, t, metaDB, b)
+	}
+	return storeKey, blobs
+}
+
+func blobRefKeys(blobs []*blobref.BlobRef) []uuid.UUID {
+	keys := []uuid.UUID{}
+	for _, b := range blobs {
+		keys = append(keys, b.Key)
+	}
+	return keys
+}
+
+func TestMetaDB_QueryBlobRefsSingleFilter(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, blobs := setupQueryBlobRefs(ctx, t, metaDB)
+
+	got, err := metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: storeKey})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, blobRefKeys(blobs), blobRefKeys(got))
+
+	got, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: storeKey, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestMetaDB_QueryBlobRefsCombinedFilters(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, blobs := setupQueryBlobRefs(ctx, t, metaDB)
+
+	ready := blobref.StatusReady
+	got, err := metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{
+		StoreKey: storeKey,
+		Status:   &ready,
+		MinSize:  50,
+		MaxSize:  500,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{blobs[1].Key}, blobRefKeys(got))
+
+	got, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{
+		StoreKey:      storeKey,
+		UpdatedBefore: time.Now().Add(time.Hour),
+		Limit:         2,
+	})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	got, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{
+		StoreKey:      storeKey,
+		UpdatedBefore: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestMetaDB_QueryBlobRefsKeyRange(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, blobs := setupQueryBlobRefs(ctx, t, metaDB)
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Key.String() < blobs[j].Key.String() })
+
+	got, err := metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: storeKey, StartKey: blobs[1].Key})
+	require.NoError(t, err)
+	assert.Equal(t, blobRefKeys(blobs[1:]), blobRefKeys(got))
+
+	got, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: storeKey, EndKey: blobs[1].Key})
+	require.NoError(t, err)
+	assert.Equal(t, blobRefKeys(blobs[:1]), blobRefKeys(got))
+
+	got, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: storeKey, StartKey: blobs[1].Key, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, blobRefKeys(blobs[1:3]), blobRefKeys(got))
+}
+
+func TestMetaDB_ListBlobsOlderThan(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, blobs := setupQueryBlobRefs(ctx, t, metaDB)
+	// Make the first two blobs appear updated two hours ago.
+	client := newDatastoreClient(ctx, t)
+	for _, b := range blobs[:2] {
+		b.Timestamps.UpdatedAt = time.Now().Add(-2 * time.Hour)
+		_, err := client.Put(ctx, blobRefKey(b.Key), b)
+		require.NoError(t, err)
+	}
+
+	got, err := metaDB.ListBlobsOlderThan(ctx, storeKey, time.Hour)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, blobRefKeys(blobs[:2]), blobRefKeys(got))
+
+	got, err = metaDB.ListBlobsOlderThan(ctx, storeKey, 3*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = metaDB.ListBlobsOlderThan(ctx, newStoreKey(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestMetaDB_QueryBlobRefsInequalityConflict(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	got, err := metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{
+		ExpiresBefore: time.Now(),
+		MaxSize:       100,
+	})
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, m.ErrInequalityFilterConflict)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	got, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{
+		StartKey: uuid.New(),
+		MinSize:  100,
+	})
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, m.ErrInequalityFilterConflict)
+
+	got, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{
+		UpdatedBefore: time.Now(),
+		MinSize:       100,
+	})
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, m.ErrInequalityFilterConflict)
+}
+
+func TestMetaDB_RenameRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r, blob := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+
+	newKey := newRecordKey()
+	require.NoError(t, metaDB.RenameRecord(ctx, st.Key, r.Key, newKey))
+	t.Cleanup(func() { metaDB.DeleteRecord(ctx, st.Key, newKey) })
+
+	_, err := metaDB.GetRecord(ctx, st.Key, r.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	got, err := metaDB.GetRecord(ctx, st.Key, newKey)
+	if assert.NoError(t, err) {
+		assert.Equal(t, newKey, got.Key)
+		assert.Equal(t, r.OpaqueString, got.OpaqueString)
+		assert.Equal(t, r.Timestamps.CreatedAt, got.Timestamps.CreatedAt)
+	}
+
+	// The BlobRef should point to the new key.
+	gotBlob, err := metaDB.GetBlobRef(ctx, blob.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, newKey, gotBlob.RecordKey)
+	}
+}
+
+func TestMetaDB_RenameRecordCollision(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r, blob := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	other := setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{
+		Key:        newRecordKey(),
+		Properties: make(record.PropertyMap),
+	})
+
+	err := metaDB.RenameRecord(ctx, st.Key, r.Key, other.Key)
+	assert.ErrorIs(t, err, m.ErrAlreadyExists)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// Nothing should have changed.
+	_, err = metaDB.GetRecord(ctx, st.Key, r.Key)
+	assert.NoError(t, err)
+	gotBlob, err := metaDB.GetBlobRef(ctx, blob.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, r.Key, gotBlob.RecordKey)
+	}
+}
+
 func TestMetaDB_DeleteRecordWithExternalBlob(t *testing.T) {
 	ctx := context.Background()
 	metaDB := newMetaDB(ctx, t)
@@ -919,6 +1251,43 @@ func TestMetaDB_DeleteRecordWithExternalBlob(t *testing.T) {
 	}
 }
 
+func TestMetaDB_GetAndDeleteRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	storeKey := newStoreKey()
+	store := &store.Store{
+		Key:  storeKey,
+		Name: t.Name(),
+	}
+	recordKey := newRecordKey()
+	r := &record.Record{
+		Key:        recordKey,
+		Tags:       []string{t.Name()},
+		Properties: make(record.PropertyMap),
+	}
+	setupTestStoreRecord(ctx, t, metaDB, store, r)
+	blob := blobref.NewBlobRef(0, storeKey, recordKey)
+	setupTestBlobRef(ctx, t, metaDB, blob)
+	r, _, err := metaDB.PromoteBlobRefToCurrent(ctx, blob)
+	require.NoError(t, err)
+
+	// The record is returned as it was before its blob was marked for deletion.
+	deleted, err := metaDB.GetAndDeleteRecord(ctx, storeKey, recordKey)
+	require.NoError(t, err)
+	if assert.NotNil(t, deleted) {
+		assert.Equal(t, []string{t.Name()}, deleted.Tags)
+		assert.Equal(t, blob.Key, deleted.ExternalBlob)
+		assert.Equal(t, r.Timestamps.Signature, deleted.Timestamps.Signature)
+	}
+	_, err = metaDB.GetRecord(ctx, storeKey, recordKey)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	deleted, err = metaDB.GetAndDeleteRecord(ctx, storeKey, recordKey)
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+}
+
 // This case tests if DeleteRecord deletes a record anyway when
 // the associated BlobRef does not exist and the database is inconsistent.
 func TestMetaDB_DeleteRecordWithNonExistentBlobRef(t *testing.T) {
@@ -1389,6 +1758,66 @@ func TestMetaDB_QueryRecords(t *testing.T) {
 	}
 }
 
+func TestMetaDB_QueryRecordsListContains(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	testStore, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey()}, nil)
+
+	tag := uuid.NewString()
+	newList := func(values ...string) *record.PropertyValue {
+		p := &record.PropertyValue{Type: pb.Property_LIST}
+		for _, v := range values {
+			p.ListValue = append(p.ListValue, &record.PropertyValue{Type: pb.Property_STRING, StringValue: v})
+		}
+		return p
+	}
+	records := []*record.Record{
+		{Key: newRecordKey(), Tags: []string{tag}, Properties: record.PropertyMap{"items": newList("sword", "shield")}},
+		{Key: newRecordKey(), Tags: []string{tag}, Properties: record.PropertyMap{"items": newList("bow")}},
+	}
+	for i := range records {
+		records[i] = setupTestRecord(ctx, t, metaDB, testStore.Key, records[i])
+	}
+
+	got, err := metaDB.GetRecord(ctx, testStore.Key, records[0].Key)
+	if assert.NoError(t, err) {
+		items, err := got.Properties["items"].GetStringList()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sword", "shield"}, items)
+	}
+
+	rr, err := metaDB.QueryRecords(ctx, &pb.QueryRecordsRequest{
+		StoreKey: testStore.Key,
+		Tags:     []string{tag},
+		Filters: []*pb.QueryFilter{{
+			PropertyName: "items",
+			Operator:     pb.FilterOperator_CONTAINS,
+			Value:        record.NewStringPropertyProto("shield"),
+		}},
+	})
+	if assert.NoError(t, err) && assert.Len(t, rr, 1) {
+		assert.Equal(t, records[0].Key, rr[0].Key)
+	}
+
+	// The value of CONTAINS must be an element, not a list.
+	_, err = metaDB.QueryRecords(ctx, &pb.QueryRecordsRequest{
+		StoreKey: testStore.Key,
+		Filters: []*pb.QueryFilter{{
+			PropertyName: "items",
+			Operator:     pb.FilterOperator_CONTAINS,
+			Value:        record.NewListPropertyProto(record.NewStringPropertyProto("shield")),
+		}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Lists with mixed types are rejected.
+	mixed := newList("a")
+	mixed.ListValue = append(mixed.ListValue, &record.PropertyValue{Type: pb.Property_INTEGER, IntegerValue: 1})
+	_, err = metaDB.InsertRecord(ctx, testStore.Key, &record.Record{Key: newRecordKey(), Properties: record.PropertyMap{"items": mixed}})
+	assert.Error(t, err)
+}
+
 func TestMetaDB_GetRecords(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
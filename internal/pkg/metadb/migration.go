@@ -0,0 +1,207 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"io"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"gocloud.dev/gcerrors"
+	"google.golang.org/api/iterator"
+)
+
+// BackfillChecksums computes MD5 and CRC32C checksums and content hashes of Ready
+// BlobRefs in the store that don't have them yet by reading the objects from bs,
+// and writes them back. Content hashes are calculated with ContentHashAlgorithm.
+// If ContentHashAlgorithm is MD5 and a BlobRef already has an MD5 checksum, the
+// checksum is copied to the content hash without reading the object.
+// It updates at most limit BlobRefs per call so that the migration can be run
+// incrementally. A non-positive limit means no limit.
+// Chunked BlobRefs are skipped as their checksums are kept in ChunkRefs.
+// BlobRefs whose objects are missing are logged and skipped.
+// Returns the number of BlobRefs updated.
+func (m *MetaDB) BackfillChecksums(ctx context.Context, bs blob.BlobStore, storeKey string, limit int) (int, error) {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.BackfillChecksums")
+	defer span.End()
+
+	query := m.newQuery(blobKind).Filter("StoreKey = ", storeKey).
+		Filter("Status = ", int(blobref.StatusReady))
+	iter := blobref.NewCursor(m.client.Run(ctx, query))
+	updated := 0
+	for limit <= 0 || updated < limit {
+		b, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return updated, datastoreErrToGRPCStatus(err)
+		}
+		if b.Chunked || (hasChecksums(&b.Checksums) && len(b.ContentHash) != 0) {
+			continue
+		}
+		var digest *checksums.Digest
+		if !m.contentHashIsMD5() || len(b.MD5) == 0 {
+			digest, err = m.computeObjectDigest(ctx, bs, b.ObjectPath())
+			if err != nil {
+				if gcerrors.Code(err) == gcerrors.NotFound {
+					log.Warnf("BackfillChecksums: object for blob (%v) is missing, skipping: %v", b.Key, err)
+					continue
+				}
+				return updated, err
+			}
+		}
+		ok, err := m.setBlobRefChecksums(ctx, b.Key, digest)
+		if err != nil {
+			return updated, err
+		}
+		if ok {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func hasChecksums(c *checksums.Checksums) bool {
+	return len(c.MD5) != 0 || c.HasCRC32C
+}
+
+// contentHashIsMD5 returns true if content hashes are MD5, in which case they
+// are the same as the MD5 checksums.
+func (m *MetaDB) contentHashIsMD5() bool {
+	return m.ContentHashAlgorithm == "" || m.ContentHashAlgorithm == checksums.HashAlgorithmMD5
+}
+
+func (m *MetaDB) computeObjectDigest(ctx context.Context, bs blob.BlobStore, path string) (*checksums.Digest, error) {
+	reader, err := bs.NewReader(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	digest := checksums.NewDigestWithContentHash(m.ContentHashAlgorithm)
+	if _, err := io.Copy(digest, reader); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// setBlobRefChecksums sets the checksums and the content hash of digest to the
+// BlobRef if it is still Ready and doesn't have them. Existing values are kept.
+// If digest is nil, the MD5 checksum of the BlobRef is copied to its content
+// hash instead. Returns false if the BlobRef was not updated.
+func (m *MetaDB) setBlobRefChecksums(ctx context.Context, key uuid.UUID, digest *checksums.Digest) (bool, error) {
+	updated := false
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		updated = false
+		b, err := m.getBlobRef(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if b.Status != blobref.StatusReady || (hasChecksums(&b.Checksums) && len(b.ContentHash) != 0) {
+			return nil
+		}
+		switch {
+		case digest != nil:
+			if !hasChecksums(&b.Checksums) {
+				b.Checksums = digest.Checksums()
+			}
+			if len(b.ContentHash) == 0 {
+				b.ContentHashAlgorithm, b.ContentHash = digest.ContentHash()
+			}
+		case len(b.MD5) != 0:
+			b.ContentHashAlgorithm, b.ContentHash = checksums.HashAlgorithmMD5, b.MD5
+		default:
+			// The MD5 checksum was removed after the query.
+			return nil
+		}
+		updated = true
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(m.createBlobKey(key), b))
+	})
+	if err != nil {
+		return false, datastoreErrToGRPCStatus(err)
+	}
+	return updated, nil
+}
+
+// RecomputeDerivedProperties rewrites the records in the store whose derived
+// properties are stale, e.g. after the DerivationFunc of the store is changed
+// in DerivationFuncs. Timestamps are not updated as the records are
+// not changed by clients.
+// It updates at most limit records per call so that the migration can be run
+// incrementally. A non-positive limit means no limit.
+// Returns the number of records updated.
+func (m *MetaDB) RecomputeDerivedProperties(ctx context.Context, storeKey string, limit int) (int, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.RecomputeDerivedProperties")
+	defer span.End()
+
+	query := m.newQuery(recordKind).Ancestor(m.createStoreKey(storeKey))
+	iter := m.client.Run(ctx, query)
+	updated := 0
+	for limit <= 0 || updated < limit {
+		r := new(record.Record)
+		key, err := iter.Next(r)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return updated, datastoreErrToGRPCStatus(err)
+		}
+		if !m.deriveProperties(storeKey, r) {
+			continue
+		}
+		ok, err := m.recomputeRecordDerivedProperties(ctx, key)
+		if err != nil {
+			return updated, err
+		}
+		if ok {
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// recomputeRecordDerivedProperties saves the record with key if its derived
+// properties are stale. Returns false if the record was not updated.
+func (m *MetaDB) recomputeRecordDerivedProperties(ctx context.Context, key *ds.Key) (bool, error) {
+	updated := false
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		updated = false
+		r := new(record.Record)
+		if err := tx.Get(key, r); err != nil {
+			return err
+		}
+		if !m.deriveProperties(key.Parent.Name, r) {
+			return nil
+		}
+		updated = true
+		entity, err := m.recordEntity(ctx, key.Parent.Name, r)
+		if err != nil {
+			return err
+		}
+		return m.mutateSingleInTransaction(tx, ds.NewUpdate(key, entity))
+	})
+	if err != nil {
+		return false, datastoreErrToGRPCStatus(err)
+	}
+	return updated, nil
+}
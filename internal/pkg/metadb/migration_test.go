@@ -0,0 +1,243 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/memblob"
+)
+
+func newMemBlobStore(ctx context.Context, t *testing.T) blob.BlobStore {
+	t.Helper()
+	bs, err := blob.NewBlobGCP(ctx, "mem://")
+	require.NoError(t, err)
+	t.Cleanup(func() { bs.Close() })
+	return bs
+}
+
+// setupReadyBlobsWithoutChecksums creates count Ready BlobRefs without checksums
+// in a new store. Objects are written to bs if withObjects is true.
+func setupReadyBlobsWithoutChecksums(ctx context.Context, t *testing.T, metaDB *m.MetaDB,
+	bs blob.BlobStore, count int, withObjects bool) (string, []*blobref.BlobRef) {
+	t.Helper()
+	storeKey := newStoreKey()
+	recordKey := newRecordKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()},
+		&record.Record{Key: recordKey, Properties: make(record.PropertyMap)})
+
+	blobs := []*blobref.BlobRef{}
+	for i := 0; i < count; i++ {
+		b := &blobref.BlobRef{
+			Key:       uuid.New(),
+			Size:      int64(i + 1),
+			Status:    blobref.StatusReady,
+			StoreKey:  storeKey,
+			RecordKey: recordKey,
+		}
+		setupTestBlobRef(ctx, t, metaDB, b)
+		if withObjects {
+			require.NoError(t, bs.Put(ctx, b.ObjectPath(), []byte(b.Key.String())[:b.Size]))
+		}
+		blobs = append(blobs, b)
+	}
+	return storeKey, blobs
+}
+
+func TestMetaDB_BackfillChecksums(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	storeKey, blobs := setupReadyBlobsWithoutChecksums(ctx, t, metaDB, bs, 3, true)
+
+	updated, err := metaDB.BackfillChecksums(ctx, bs, storeKey, 0)
+	require.NoError(t, err)
+	assert.Equal(t, len(blobs), updated)
+
+	for _, b := range blobs {
+		digest := checksums.NewDigest()
+		digest.Write([]byte(b.Key.String())[:b.Size])
+		got, err := metaDB.GetBlobRef(ctx, b.Key)
+		if assert.NoError(t, err) {
+			assert.Equal(t, digest.Checksums(), got.Checksums)
+			assert.Equal(t, checksums.HashAlgorithmMD5, got.ContentHashAlgorithm)
+			assert.Equal(t, digest.Checksums().MD5, got.ContentHash)
+			assert.Equal(t, blobref.StatusReady, got.Status)
+		}
+	}
+
+	// The second run should find nothing to do.
+	updated, err = metaDB.BackfillChecksums(ctx, bs, storeKey, 0)
+	assert.NoError(t, err)
+	assert.Zero(t, updated)
+}
+
+func TestMetaDB_BackfillChecksumsContentHash(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	metaDB.ContentHashAlgorithm = checksums.HashAlgorithmSHA256
+	bs := newMemBlobStore(ctx, t)
+	storeKey, blobs := setupReadyBlobsWithoutChecksums(ctx, t, metaDB, bs, 1, true)
+
+	updated, err := metaDB.BackfillChecksums(ctx, bs, storeKey, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	got, err := metaDB.GetBlobRef(ctx, blobs[0].Key)
+	require.NoError(t, err)
+	want := sha256.Sum256([]byte(blobs[0].Key.String())[:blobs[0].Size])
+	assert.Equal(t, checksums.HashAlgorithmSHA256, got.ContentHashAlgorithm)
+	assert.Equal(t, want[:], got.ContentHash)
+
+	// The backfilled hash is indexed.
+	found, err := metaDB.FindBlobRefByContentHash(ctx, storeKey, checksums.HashAlgorithmSHA256, want[:], got.Size, m.ConsistencyStrong)
+	require.NoError(t, err)
+	assert.Equal(t, got.Key, found.Key)
+}
+
+func TestMetaDB_BackfillChecksumsSkipsMissingObjects(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	storeKey, blobs := setupReadyBlobsWithoutChecksums(ctx, t, metaDB, bs, 2, false)
+
+	// Only the first blob has an object.
+	content := []byte("a")
+	require.NoError(t, bs.Put(ctx, blobs[0].ObjectPath(), content))
+
+	updated, err := metaDB.BackfillChecksums(ctx, bs, storeKey, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	got, err := metaDB.GetBlobRef(ctx, blobs[0].Key)
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, got.MD5)
+		assert.True(t, got.HasCRC32C)
+	}
+	got, err = metaDB.GetBlobRef(ctx, blobs[1].Key)
+	if assert.NoError(t, err) {
+		assert.Empty(t, got.MD5)
+		assert.False(t, got.HasCRC32C)
+	}
+}
+
+func TestMetaDB_BackfillChecksumsCopiesMD5(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	// The object is not written, so the content hash can only come from MD5.
+	storeKey, blobs := setupReadyBlobsWithoutChecksums(ctx, t, metaDB, bs, 1, false)
+	md5 := []byte("0123456789abcdef")
+	blobs[0].MD5 = md5
+	_, err := metaDB.UpdateBlobRef(ctx, blobs[0])
+	require.NoError(t, err)
+
+	updated, err := metaDB.BackfillChecksums(ctx, bs, storeKey, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	got, err := metaDB.GetBlobRef(ctx, blobs[0].Key)
+	require.NoError(t, err)
+	assert.Equal(t, checksums.HashAlgorithmMD5, got.ContentHashAlgorithm)
+	assert.Equal(t, md5, got.ContentHash)
+}
+
+func TestMetaDB_BackfillChecksumsLimit(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	storeKey, blobs := setupReadyBlobsWithoutChecksums(ctx, t, metaDB, bs, 3, true)
+
+	updated, err := metaDB.BackfillChecksums(ctx, bs, storeKey, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	// The next run picks up the rest.
+	updated, err = metaDB.BackfillChecksums(ctx, bs, storeKey, 2)
+	require.NoError(t, err)
+	assert.Equal(t, len(blobs)-2, updated)
+}
+
+func TestMetaDB_DerivedPropertiesOnInsertAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey := newStoreKey()
+	metaDB.DerivationFuncs = map[string]record.DerivationFunc{
+		storeKey: func(r *record.Record) record.PropertyMap {
+			return record.PropertyMap{"tag_count": {Type: pb.Property_INTEGER, IntegerValue: int64(len(r.Tags))}}
+		},
+	}
+	_, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Tags: []string{"a"}, Properties: make(record.PropertyMap)})
+	assertIntProperty(ctx, t, metaDB, r, "tag_count", 1)
+
+	_, err := metaDB.UpdateRecord(ctx, storeKey, r.Key, func(r *record.Record) (*record.Record, error) {
+		r.Tags = append(r.Tags, "b")
+		return r, nil
+	})
+	require.NoError(t, err)
+	assertIntProperty(ctx, t, metaDB, r, "tag_count", 2)
+}
+
+func TestMetaDB_RecomputeDerivedProperties(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey := newStoreKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()}, nil)
+	var records []*record.Record
+	for i := int64(0); i < 3; i++ {
+		records = append(records, setupTestRecord(ctx, t, metaDB, storeKey, &record.Record{
+			Key:        newRecordKey(),
+			Properties: record.PropertyMap{"level": {Type: pb.Property_INTEGER, IntegerValue: 10 * i}},
+		}))
+	}
+
+	// The DerivationFunc is added after the records are saved.
+	metaDB.DerivationFuncs = map[string]record.DerivationFunc{
+		storeKey: func(r *record.Record) record.PropertyMap {
+			return record.PropertyMap{"double_level": {Type: pb.Property_INTEGER, IntegerValue: 2 * r.Properties["level"].IntegerValue}}
+		},
+	}
+
+	updated, err := metaDB.RecomputeDerivedProperties(ctx, storeKey, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+	updated, err = metaDB.RecomputeDerivedProperties(ctx, storeKey, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	for i, r := range records {
+		assertIntProperty(ctx, t, metaDB, r, "double_level", 20*int64(i))
+		got, err := metaDB.GetRecord(ctx, storeKey, r.Key)
+		require.NoError(t, err)
+		assert.Equal(t, r.Timestamps, got.Timestamps, "the migration must not update timestamps")
+	}
+
+	// Re-running the migration is a no-op.
+	updated, err = metaDB.RecomputeDerivedProperties(ctx, storeKey, 0)
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+}
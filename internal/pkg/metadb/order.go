@@ -0,0 +1,85 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"fmt"
+	"strconv"
+
+	ds "cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordOrderProperties maps the built-in sort properties to the indexed
+// Datastore properties of records.
+var recordOrderProperties = map[pb.SortOrder_Property]string{
+	pb.SortOrder_CREATED_AT: "Timestamps.CreatedAt",
+	pb.SortOrder_UPDATED_AT: "Timestamps.UpdatedAt",
+}
+
+// orderRecordQuery adds an order clause to q for each of orders, in priority
+// order. It returns InvalidArgument if a property can't be sorted by or a
+// direction is invalid.
+// User properties are sorted by their values, and records that have a BYTES
+// value for the property are not returned as BYTES values are not indexed.
+func (m *MetaDB) orderRecordQuery(q *ds.Query, orders []*pb.SortOrder) (*ds.Query, error) {
+	for _, s := range orders {
+		property, err := m.recordOrderProperty(s)
+		if err != nil {
+			return nil, err
+		}
+		switch s.GetDirection() {
+		case pb.SortOrder_ASC:
+			q = q.Order(strconv.Quote(property))
+		case pb.SortOrder_DESC:
+			q = q.Order("-" + strconv.Quote(property))
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "got invalid SortOrder direction value: %v", s.GetDirection())
+		}
+	}
+	return q, nil
+}
+
+// recordOrderProperty returns the Datastore property name to sort by for s.
+func (m *MetaDB) recordOrderProperty(s *pb.SortOrder) (string, error) {
+	if s.GetProperty() != pb.SortOrder_USER_PROPERTY {
+		property, ok := recordOrderProperties[s.GetProperty()]
+		if !ok {
+			return "", status.Errorf(codes.InvalidArgument, "got invalid SortOrder property value: %v", s.GetProperty())
+		}
+		return property, nil
+	}
+	if s.GetUserPropertyName() == "" {
+		return "", status.Error(codes.InvalidArgument, "got empty user sort property")
+	}
+	name, err := record.EncodePropertyName(s.GetUserPropertyName(), m.PropertyNameMode)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return fmt.Sprintf("%s.%s", propertiesField, name), nil
+}
+
+// sortOrderStrings returns a string for each of orders, e.g. to fingerprint
+// the query that they are applied to.
+func sortOrderStrings(orders []*pb.SortOrder) []string {
+	ss := make([]string, len(orders))
+	for i, o := range orders {
+		ss[i] = fmt.Sprintf("%v:%v:%q", o.GetProperty(), o.GetDirection(), o.GetUserPropertyName())
+	}
+	return ss
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setupOrderRecords creates records in the order of groups and scores, and
+// updates the first record so that it is the last one updated.
+func setupOrderRecords(ctx context.Context, t *testing.T, metaDB *m.MetaDB, groups []string, scores []int64) (string, []string) {
+	t.Helper()
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+	var keys []string
+	for i := range groups {
+		r := setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{
+			Key: newRecordKey(),
+			Properties: record.PropertyMap{
+				"group": {Type: pb.Property_STRING, StringValue: groups[i]},
+				"score": {Type: pb.Property_INTEGER, IntegerValue: scores[i]},
+			},
+		})
+		keys = append(keys, r.Key)
+	}
+	_, err := metaDB.UpdateRecord(ctx, st.Key, keys[0], func(r *record.Record) (*record.Record, error) {
+		r.OwnerID = "updated"
+		return r, nil
+	})
+	require.NoError(t, err)
+	return st.Key, keys
+}
+
+func queryOrderedKeys(ctx context.Context, t *testing.T, metaDB *m.MetaDB, storeKey string, orders ...*pb.SortOrder) []string {
+	t.Helper()
+	got, err := metaDB.QueryRecords(ctx, &pb.QueryRecordsRequest{StoreKey: storeKey, SortOrders: orders})
+	require.NoError(t, err)
+	return recordKeys(got)
+}
+
+func TestMetaDB_QueryRecordsOrder(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, keys := setupOrderRecords(ctx, t, metaDB, []string{"a", "b", "a"}, []int64{2, 3, 1})
+
+	testCases := []struct {
+		name  string
+		order *pb.SortOrder
+		want  []string
+	}{
+		{"CreatedAt ASC", &pb.SortOrder{Property: pb.SortOrder_CREATED_AT, Direction: pb.SortOrder_ASC}, keys},
+		{"CreatedAt DESC", &pb.SortOrder{Property: pb.SortOrder_CREATED_AT, Direction: pb.SortOrder_DESC}, []string{keys[2], keys[1], keys[0]}},
+		{"UpdatedAt ASC", &pb.SortOrder{Property: pb.SortOrder_UPDATED_AT, Direction: pb.SortOrder_ASC}, []string{keys[1], keys[2], keys[0]}},
+		{"UpdatedAt DESC", &pb.SortOrder{Property: pb.SortOrder_UPDATED_AT, Direction: pb.SortOrder_DESC}, []string{keys[0], keys[2], keys[1]}},
+		{"property ASC", &pb.SortOrder{Property: pb.SortOrder_USER_PROPERTY, UserPropertyName: "score", Direction: pb.SortOrder_ASC}, []string{keys[2], keys[0], keys[1]}},
+		{"property DESC", &pb.SortOrder{Property: pb.SortOrder_USER_PROPERTY, UserPropertyName: "score", Direction: pb.SortOrder_DESC}, []string{keys[1], keys[0], keys[2]}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, queryOrderedKeys(ctx, t, metaDB, storeKey, tc.order))
+		})
+	}
+}
+
+func TestMetaDB_QueryRecordsMultiKeyOrder(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey, keys := setupOrderRecords(ctx, t, metaDB, []string{"b", "a", "b", "a"}, []int64{1, 1, 2, 2})
+
+	got := queryOrderedKeys(ctx, t, metaDB, storeKey,
+		&pb.SortOrder{Property: pb.SortOrder_USER_PROPERTY, UserPropertyName: "group", Direction: pb.SortOrder_ASC},
+		&pb.SortOrder{Property: pb.SortOrder_USER_PROPERTY, UserPropertyName: "score", Direction: pb.SortOrder_DESC},
+	)
+	assert.Equal(t, []string{keys[3], keys[1], keys[2], keys[0]}, got)
+
+	records, _, err := metaDB.QueryRecordsByCategory(ctx, storeKey, "", 0, "",
+		&pb.SortOrder{Property: pb.SortOrder_CREATED_AT, Direction: pb.SortOrder_DESC})
+	require.NoError(t, err)
+	assert.Equal(t, []string{keys[3], keys[2], keys[1], keys[0]}, recordKeys(records))
+}
+
+func TestMetaDB_QueryRecordsOrderInvalid(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	for name, order := range map[string]*pb.SortOrder{
+		"unknown property":      {Property: pb.SortOrder_Property(100), Direction: pb.SortOrder_ASC},
+		"empty user property":   {Property: pb.SortOrder_USER_PROPERTY, Direction: pb.SortOrder_ASC},
+		"invalid user property": {Property: pb.SortOrder_USER_PROPERTY, UserPropertyName: "a.b", Direction: pb.SortOrder_ASC},
+		"unknown direction":     {Property: pb.SortOrder_CREATED_AT, Direction: pb.SortOrder_Direction(100)},
+	} {
+		_, err := metaDB.QueryRecords(ctx, &pb.QueryRecordsRequest{SortOrders: []*pb.SortOrder{order}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+		_, _, err = metaDB.QueryRecordsByCategory(ctx, "store", "", 0, "", order)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	ds "cloud.google.com/go/datastore"
+)
+
+// PreviewProperties returns the Datastore properties that pls, such as a
+// BlobRef or Record, would be written as, without accessing Datastore.
+// Note that InsertBlobRef and InsertRecord reset Timestamps, and InsertBlobRef
+// sets OwnerID from the record if empty, before writing the entity.
+func PreviewProperties(pls ds.PropertyLoadSaver) ([]ds.Property, error) {
+	return pls.Save()
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sortProperties sorts ps by name so that entities read from Datastore can be
+// compared with saved properties.
+func sortProperties(ps []datastore.Property) []datastore.Property {
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Name < ps[j].Name })
+	for _, p := range ps {
+		if e, ok := p.Value.(*datastore.Entity); ok {
+			sortProperties(e.Properties)
+		}
+	}
+	return ps
+}
+
+func TestPreviewProperties_BlobRef(t *testing.T) {
+	blob := blobref.NewBlobRef(42, "store", "record")
+	blob.Metadata = map[string]string{"slot": "1"}
+	blob.Timestamps = timestamps.Timestamps{
+		CreatedAt: time.Date(2023, 4, 1, 10, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2023, 4, 2, 10, 0, 0, 0, time.UTC),
+		Signature: uuid.MustParse("397f94f5-f851-4969-8bd8-7828abc473a6"),
+	}
+	before := *blob
+
+	got, err := m.PreviewProperties(blob)
+	require.NoError(t, err)
+	want, err := blob.Save()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, before, *blob, "PreviewProperties must not change the BlobRef")
+
+	var ts *datastore.Entity
+	for _, p := range got {
+		if p.Name == "Timestamps" {
+			ts, _ = p.Value.(*datastore.Entity)
+		}
+	}
+	if assert.NotNil(t, ts, "PreviewProperties() must include Timestamps") {
+		assert.Equal(t, []datastore.Property{
+			{Name: "CreatedAt", Value: blob.Timestamps.CreatedAt, NoIndex: true},
+			{Name: "UpdatedAt", Value: blob.Timestamps.UpdatedAt},
+			{Name: "Signature", Value: blob.Timestamps.Signature.String(), NoIndex: true},
+		}, ts.Properties)
+	}
+
+	// Invalid entities fail the same as writes.
+	_, err = m.PreviewProperties(blobref.NewBlobRef(0, "", ""))
+	assert.ErrorIs(t, err, blobref.ErrMissingKey)
+}
+
+func TestMetaDB_PreviewPropertiesMatchesWrittenEntity(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	blob := blobref.NewBlobRef(42, st.Key, r.Key)
+	blob.Metadata = map[string]string{"slot": "1"}
+	blob = setupTestBlobRef(ctx, t, metaDB, blob)
+
+	preview, err := m.PreviewProperties(blob)
+	require.NoError(t, err)
+	var written datastore.PropertyList
+	require.NoError(t, newDatastoreClient(ctx, t).Get(ctx, blobRefKey(blob.Key), &written))
+	if diff := cmp.Diff(sortProperties(preview), sortProperties(written),
+		cmpopts.EquateApproxTime(time.Microsecond)); diff != "" {
+		t.Errorf("PreviewProperties() doesn't match the written entity (-preview +written):\n%s", diff)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetRecordProperties reads only the properties of the record with names by
+// Datastore projection queries instead of the whole record.
+// Projections read indexes, so properties that are not indexed can't be read:
+// it returns FailedPrecondition if the index policy of the store excludes any of
+// names (see SetStorePolicy), or if any of them is a BYTES property, or a LIST
+// of BYTES, which are never indexed.
+// Projections of LIST properties lose the order and duplicates of the elements,
+// so the record is read in full if any of names is a LIST property.
+// Requested properties that the record doesn't have are omitted from the result.
+// It returns an empty map without reading Datastore if names is empty,
+// NotFound if the record doesn't exist, and ErrRecordExpired if it has expired.
+// See record.ProjectionTypesName for records saved before it was added.
+func (m *MetaDB) GetRecordProperties(ctx context.Context, storeKey, recordKey string, names []string) (record.PropertyMap, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetRecordProperties")
+	defer span.End()
+
+	props := make(record.PropertyMap)
+	if len(names) == 0 {
+		return props, nil
+	}
+	encoded := make(map[string]string, len(names))
+	for _, name := range names {
+		e, err := record.EncodePropertyName(name, m.PropertyNameMode)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		encoded[name] = e
+	}
+	policy, err := m.storePolicy(ctx, storeKey)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		allowed := make(map[string]bool, len(policy.IndexedProperties))
+		for _, name := range policy.IndexedProperties {
+			allowed[name] = true
+		}
+		for _, name := range names {
+			if !allowed[name] {
+				return nil, status.Errorf(codes.FailedPrecondition,
+					"property (%s) is not indexed in store (%s) and can't be projected", name, storeKey)
+			}
+		}
+	}
+
+	rkey := m.createRecordKey(storeKey, recordKey)
+	types, err := m.projectionTypes(ctx, rkey)
+	if err != nil {
+		return nil, err
+	}
+	hasList := false
+	for name, e := range encoded {
+		switch types[e] {
+		case pb.Property_BYTES:
+			return nil, status.Errorf(codes.FailedPrecondition,
+				"property (%s) of record (%s) is BYTES, which is not indexed and can't be projected", name, recordKey)
+		case pb.Property_LIST:
+			hasList = true
+		}
+	}
+	if hasList {
+		r, err := m.GetRecord(ctx, storeKey, recordKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if v, ok := r.Properties[name]; ok {
+				props[name] = v
+			}
+		}
+		return props, nil
+	}
+
+	// A projection only returns the entities that have all projected
+	// properties indexed, so each property is projected separately.
+	for name, e := range encoded {
+		field := propertiesField + "." + e
+		query := m.newQuery(recordKind).Filter("__key__ =", rkey).Project(field)
+		var results []ds.PropertyList
+		if _, err := m.client.GetAll(ctx, query, &results); err != nil {
+			return nil, datastoreErrToGRPCStatus(err)
+		}
+		for _, r := range results {
+			if v, ok := projectedValue(r, e); ok {
+				if err := props.Load([]ds.Property{{Name: e, Value: v}}); err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to load projected property (%s): %v", name, err)
+				}
+			}
+		}
+	}
+	if expired, err := m.recordExpired(ctx, rkey, time.Now()); err != nil {
+		return nil, err
+	} else if expired {
+		return nil, ErrRecordExpired
+	}
+	if len(props) > 0 || len(types) > 0 {
+		return props, nil
+	}
+	// Check whether the record has none of names or doesn't exist.
+	query := m.newQuery(recordKind).Filter("__key__ =", rkey).KeysOnly()
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	if len(keys) == 0 {
+		return nil, status.Errorf(codes.NotFound, "record (%s) was not found in store (%s)", recordKey, storeKey)
+	}
+	return props, nil
+}
+
+// projectionTypes returns the types of the LIST and BYTES properties of the
+// record of rkey by their encoded names, using a projection of
+// record.ProjectionTypesName.
+func (m *MetaDB) projectionTypes(ctx context.Context, rkey *ds.Key) (map[string]pb.Property_Type, error) {
+	query := m.newQuery(recordKind).Filter("__key__ =", rkey).Project(record.ProjectionTypesName)
+	var results []ds.PropertyList
+	if _, err := m.client.GetAll(ctx, query, &results); err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	types := make(map[string]pb.Property_Type)
+	for _, ps := range results {
+		for _, p := range ps {
+			v, ok := p.Value.(string)
+			if p.Name != record.ProjectionTypesName || !ok {
+				continue
+			}
+			if t, name, ok := record.ParseProjectionType(v); ok {
+				types[name] = t
+			}
+		}
+	}
+	return types, nil
+}
+
+// projectedValue returns the value of the record property with the encoded
+// name in the result of a projection query. Datastore returns projected
+// properties of embedded entities either by their full paths or as embedded
+// entities.
+func projectedValue(ps ds.PropertyList, name string) (interface{}, bool) {
+	for _, p := range ps {
+		if p.Name == propertiesField+"."+name {
+			return p.Value, true
+		}
+		if p.Name != propertiesField {
+			continue
+		}
+		if e, ok := p.Value.(*ds.Entity); ok {
+			for _, ep := range e.Properties {
+				if ep.Name == name {
+					return ep.Value, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
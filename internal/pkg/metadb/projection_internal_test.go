@@ -0,0 +1,41 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectedValue(t *testing.T) {
+	flat := ds.PropertyList{{Name: "Properties.level", Value: int64(7)}}
+	v, ok := projectedValue(flat, "level")
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), v)
+
+	embedded := ds.PropertyList{{Name: "Properties", Value: &ds.Entity{
+		Properties: []ds.Property{{Name: "name", Value: "hero"}},
+	}}}
+	v, ok = projectedValue(embedded, "name")
+	assert.True(t, ok)
+	assert.Equal(t, "hero", v)
+
+	_, ok = projectedValue(embedded, "level")
+	assert.False(t, ok)
+	_, ok = projectedValue(ds.PropertyList{{Name: "level", Value: int64(7)}}, "level")
+	assert.False(t, ok, "only record properties are returned")
+}
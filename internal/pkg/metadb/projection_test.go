@@ -0,0 +1,114 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_GetRecordProperties(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey := newStoreKey()
+	recordKey := newRecordKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()}, &record.Record{
+		Key: recordKey,
+		Properties: record.PropertyMap{
+			"score":  {Type: pb.Property_INTEGER, IntegerValue: 1200},
+			"name":   {Type: pb.Property_STRING, StringValue: "hero"},
+			"online": {Type: pb.Property_BOOLEAN, BooleanValue: true},
+			"avatar": {Type: pb.Property_BYTES, BytesValue: []byte{1, 2, 3}},
+			"items":  {Type: pb.Property_LIST, ListValue: []*record.PropertyValue{{Type: pb.Property_STRING, StringValue: "sword"}}},
+			"badges": {Type: pb.Property_LIST, ListValue: []*record.PropertyValue{
+				{Type: pb.Property_INTEGER, IntegerValue: 1}, {Type: pb.Property_INTEGER, IntegerValue: 2},
+			}},
+		},
+	})
+
+	got, err := metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"score", "name"})
+	require.NoError(t, err)
+	assert.Equal(t, record.PropertyMap{
+		"score": {Type: pb.Property_INTEGER, IntegerValue: 1200},
+		"name":  {Type: pb.Property_STRING, StringValue: "hero"},
+	}, got)
+
+	got, err = metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"online", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, record.PropertyMap{
+		"online": {Type: pb.Property_BOOLEAN, BooleanValue: true},
+	}, got, "missing properties are omitted")
+
+	_, err = metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"online", "avatar"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "BYTES properties can't be projected")
+
+	got, err = metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"items", "badges"})
+	require.NoError(t, err)
+	assert.Equal(t, record.PropertyMap{
+		"items": {Type: pb.Property_LIST, ListValue: []*record.PropertyValue{{Type: pb.Property_STRING, StringValue: "sword"}}},
+		"badges": {Type: pb.Property_LIST, ListValue: []*record.PropertyValue{
+			{Type: pb.Property_INTEGER, IntegerValue: 1}, {Type: pb.Property_INTEGER, IntegerValue: 2},
+		}},
+	}, got, "lists are returned whole")
+
+	got, err = metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"items", "score"})
+	require.NoError(t, err)
+	assert.Equal(t, record.PropertyMap{
+		"items": {Type: pb.Property_LIST, ListValue: []*record.PropertyValue{{Type: pb.Property_STRING, StringValue: "sword"}}},
+		"score": {Type: pb.Property_INTEGER, IntegerValue: 1200},
+	}, got, "a list of one element is not returned as a scalar")
+
+	got, err = metaDB.GetRecordProperties(ctx, storeKey, recordKey, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"missing"})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = metaDB.GetRecordProperties(ctx, storeKey, newRecordKey(), []string{"score"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMetaDB_GetRecordPropertiesNotIndexed(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey := newStoreKey()
+	recordKey := newRecordKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()}, &record.Record{
+		Key: recordKey,
+		Properties: record.PropertyMap{
+			"score": {Type: pb.Property_INTEGER, IntegerValue: 1200},
+			"bio":   {Type: pb.Property_STRING, StringValue: "long text"},
+		},
+	})
+	require.NoError(t, metaDB.SetStorePolicy(ctx, &store.StorePolicy{StoreKey: storeKey, IndexedProperties: []string{"score"}}))
+	t.Cleanup(func() { metaDB.DeleteStorePolicy(ctx, storeKey) })
+
+	_, err := metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"score", "bio"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	got, err := metaDB.GetRecordProperties(ctx, storeKey, recordKey, []string{"score"})
+	require.NoError(t, err)
+	assert.Equal(t, record.PropertyMap{"score": {Type: pb.Property_INTEGER, IntegerValue: 1200}}, got)
+}
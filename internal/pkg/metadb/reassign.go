@@ -0,0 +1,92 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReassignBlob moves the Ready blob with blobKey to the record with
+// newStoreKey and newRecordKey without copying the content, e.g. to merge
+// accounts. In a single transaction, the blob is detached from its current
+// record if it is still the record's external blob, and becomes the external
+// blob of the new record. The previous blob of the new record is marked for
+// deletion and its inline blob is removed, like PromoteBlobRefToCurrent.
+// The blob object doesn't move as object paths only depend on the blob key.
+// The blob's OwnerID is set to the owner of the new record.
+// Returned errors:
+//   - NotFound: the blob or the new record was not found
+//   - FailedPrecondition: the blob is not Ready
+func (m *MetaDB) ReassignBlob(ctx context.Context, blobKey uuid.UUID, newStoreKey, newRecordKey string) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ReassignBlob")
+	defer span.End()
+
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		blob, err := m.getBlobRef(ctx, tx, blobKey)
+		if err != nil {
+			return err
+		}
+		if blob.Status != blobref.StatusReady {
+			return status.Errorf(codes.FailedPrecondition, "blob (%v) must be Ready to be reassigned, current status = %v", blobKey, blob.Status)
+		}
+		if blob.StoreKey == newStoreKey && blob.RecordKey == newRecordKey {
+			return nil
+		}
+
+		oldKey := m.createRecordKey(blob.StoreKey, blob.RecordKey)
+		old := new(record.Record)
+		switch err := tx.Get(oldKey, old); {
+		case err == ds.ErrNoSuchEntity:
+			// The record was deleted, so there is nothing to detach.
+		case err != nil:
+			return err
+		case old.ExternalBlob == blobKey:
+			old.ExternalBlob = uuid.Nil
+			old.BlobSize = 0
+			old.Chunked = false
+			old.ChunkCount = 0
+			if err := m.updateTimestamps(&old.Timestamps); err != nil {
+				return err
+			}
+			entity, err := m.recordEntity(ctx, blob.StoreKey, old)
+			if err != nil {
+				return err
+			}
+			if err := m.mutateSingleInTransaction(tx, ds.NewUpdate(oldKey, entity)); err != nil {
+				return err
+			}
+		}
+
+		newRecord := new(record.Record)
+		if err := tx.Get(m.createRecordKey(newStoreKey, newRecordKey), newRecord); err != nil {
+			return err
+		}
+		blob.StoreKey = newStoreKey
+		blob.RecordKey = newRecordKey
+		blob.OwnerID = newRecord.OwnerID
+		_, _, err = m.promoteBlobRefInTransaction(ctx, tx, blob)
+		return err
+	})
+	return datastoreErrToGRPCStatus(err)
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_ReassignBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	fromStore, fromRecord, blob := setupCurrentBlob(ctx, t, metaDB)
+	toStore, toRecord, previous := setupCurrentBlob(ctx, t, metaDB)
+
+	require.NoError(t, metaDB.ReassignBlob(ctx, blob.Key, toStore, toRecord))
+
+	from, err := metaDB.GetRecord(ctx, fromStore, fromRecord)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, from.ExternalBlob)
+	assert.Zero(t, from.BlobSize)
+
+	to, err := metaDB.GetRecord(ctx, toStore, toRecord)
+	require.NoError(t, err)
+	assert.Equal(t, blob.Key, to.ExternalBlob)
+	assert.Equal(t, blob.Size, to.BlobSize)
+
+	got, err := metaDB.GetBlobRef(ctx, blob.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusReady, got.Status)
+	assert.Equal(t, toStore, got.StoreKey)
+	assert.Equal(t, toRecord, got.RecordKey)
+	assert.Equal(t, to.OwnerID, got.OwnerID)
+
+	got, err = metaDB.GetBlobRef(ctx, previous.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusPendingDeletion, got.Status, "the previous blob of the new record must be deleted")
+
+	// Reassigning to the current record is a no-op.
+	assert.NoError(t, metaDB.ReassignBlob(ctx, blob.Key, toStore, toRecord))
+}
+
+func TestMetaDB_ReassignBlobNotReady(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	fromStore, fromRecord, blob := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	toStore, toRecord, _ := setupCurrentBlob(ctx, t, metaDB)
+
+	err := metaDB.ReassignBlob(ctx, blob.Key, toStore, toRecord)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	got, err := metaDB.GetBlobRef(ctx, blob.Key)
+	require.NoError(t, err)
+	assert.Equal(t, fromStore.Key, got.StoreKey)
+	assert.Equal(t, fromRecord.Key, got.RecordKey)
+
+	_, _, ready := setupCurrentBlob(ctx, t, metaDB)
+	err = metaDB.ReassignBlob(ctx, ready.Key, toStore, newRecordKey())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	err = metaDB.ReassignBlob(ctx, uuid.New(), toStore, toRecord)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
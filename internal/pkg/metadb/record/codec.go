@@ -0,0 +1,441 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/cache"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Format tags of the Record codecs.
+const (
+	ProtoFormat byte = 'p'
+	JSONFormat  byte = 'j'
+	GobFormat   byte = 'g'
+)
+
+// Assert the codecs implement cache.Codec.
+var _ cache.Codec = ProtoCodec{}
+var _ cache.Codec = JSONCodec{}
+var _ cache.Codec = GobCodec{}
+
+// ErrMalformedProto is returned by ProtoCodec when the data cannot be decoded.
+var ErrMalformedProto = errors.New("malformed record proto")
+
+// NewCodec returns the Record codec for name, which is one of "proto", "json",
+// and "gob". It returns nil for "" and "msgpack", in which case the cache
+// keeps using EncodeBytes and DecodeBytes.
+func NewCodec(name string) (cache.Codec, error) {
+	switch name {
+	case "", "msgpack":
+		return nil, nil
+	case "proto":
+		return ProtoCodec{}, nil
+	case "json":
+		return JSONCodec{}, nil
+	case "gob":
+		return GobCodec{}, nil
+	}
+	return nil, fmt.Errorf("unknown cache codec: %q", name)
+}
+
+// The Record codecs encode objects other than Records with EncodeBytes and
+// DecodeBytes so that a Cache can share a codec between Records and Stores.
+
+// JSONCodec encodes Records with encoding/json.
+type JSONCodec struct{}
+
+// Format returns JSONFormat.
+func (JSONCodec) Format() byte { return JSONFormat }
+
+// Marshal returns the JSON encoding of object.
+func (JSONCodec) Marshal(object cache.Cacheable) ([]byte, error) {
+	r, ok := object.(*Record)
+	if !ok {
+		return object.EncodeBytes()
+	}
+	return json.Marshal(r)
+}
+
+// Unmarshal decodes the JSON encoded data into dest.
+func (JSONCodec) Unmarshal(data []byte, dest cache.Cacheable) error {
+	r, ok := dest.(*Record)
+	if !ok {
+		return dest.DecodeBytes(data)
+	}
+	return json.Unmarshal(data, r)
+}
+
+// GobCodec encodes Records with encoding/gob.
+type GobCodec struct{}
+
+// Format returns GobFormat.
+func (GobCodec) Format() byte { return GobFormat }
+
+// Marshal returns the gob encoding of object.
+func (GobCodec) Marshal(object cache.Cacheable) ([]byte, error) {
+	r, ok := object.(*Record)
+	if !ok {
+		return object.EncodeBytes()
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the gob encoded data into dest.
+func (GobCodec) Unmarshal(data []byte, dest cache.Cacheable) error {
+	r, ok := dest.(*Record)
+	if !ok {
+		return dest.DecodeBytes(data)
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(r)
+}
+
+// ProtoCodec encodes Records in the protocol buffers wire format.
+// pb.Record doesn't have the server side fields, so ProtoCodec uses its own
+// field numbers below. Do not reuse the numbers when removing fields.
+type ProtoCodec struct{}
+
+// Field numbers of the Record message.
+const (
+	protoKey protowire.Number = iota + 1
+	protoBlob
+	protoBlobSize
+	protoExternalBlob
+	protoChunked
+	protoChunkCount
+	protoProperties
+	protoOwnerID
+	protoTags
+	protoOpaqueString
+	protoCategory
+	protoLeaseID
+	protoLeaseExpiresAt
+	protoMD5
+	protoCRC32C
+	protoHasCRC32C
+	protoCreatedAt
+	protoUpdatedAt
+	protoSignature
+	protoStoreKey
+	protoDerivedProperties
+	protoExpiresAt
+	protoPropertyUpdatedAt
+)
+
+// Field numbers of the property entry message.
+const (
+	protoPropertyName protowire.Number = iota + 1
+	protoPropertyType
+	protoPropertyInteger
+	protoPropertyString
+	protoPropertyBoolean
+	protoPropertyBytes
+	protoPropertyListElement
+)
+
+// Field numbers of the property time entry message.
+const (
+	protoPropertyTimeName protowire.Number = iota + 1
+	protoPropertyTimeValue
+)
+
+// Format returns ProtoFormat.
+func (ProtoCodec) Format() byte { return ProtoFormat }
+
+// Marshal returns the proto encoding of object.
+func (ProtoCodec) Marshal(object cache.Cacheable) ([]byte, error) {
+	r, ok := object.(*Record)
+	if !ok {
+		return object.EncodeBytes()
+	}
+	var b []byte
+	b = appendString(b, protoKey, r.Key)
+	b = appendBytes(b, protoBlob, r.Blob)
+	b = appendVarint(b, protoBlobSize, uint64(r.BlobSize))
+	if r.ExternalBlob != uuid.Nil {
+		b = appendBytes(b, protoExternalBlob, r.ExternalBlob[:])
+	}
+	b = appendBool(b, protoChunked, r.Chunked)
+	b = appendVarint(b, protoChunkCount, uint64(r.ChunkCount))
+	for name, v := range r.Properties {
+		if v == nil {
+			continue
+		}
+		p := appendString(nil, protoPropertyName, name)
+		p = appendPropertyValue(p, v)
+		b = protowire.AppendTag(b, protoProperties, protowire.BytesType)
+		b = protowire.AppendBytes(b, p)
+	}
+	b = appendString(b, protoOwnerID, r.OwnerID)
+	for _, t := range r.Tags {
+		b = protowire.AppendTag(b, protoTags, protowire.BytesType)
+		b = protowire.AppendString(b, t)
+	}
+	b = appendString(b, protoOpaqueString, r.OpaqueString)
+	b = appendString(b, protoCategory, r.Category)
+	b = appendString(b, protoLeaseID, r.LeaseID)
+	b = appendTime(b, protoLeaseExpiresAt, r.LeaseExpiresAt)
+	b = appendBytes(b, protoMD5, r.MD5)
+	b = appendVarint(b, protoCRC32C, uint64(uint32(r.CRC32C)))
+	b = appendBool(b, protoHasCRC32C, r.HasCRC32C)
+	b = appendTime(b, protoCreatedAt, r.Timestamps.CreatedAt)
+	b = appendTime(b, protoUpdatedAt, r.Timestamps.UpdatedAt)
+	if r.Timestamps.Signature != uuid.Nil {
+		b = appendBytes(b, protoSignature, r.Timestamps.Signature[:])
+	}
+	b = appendString(b, protoStoreKey, r.StoreKey)
+	for _, name := range r.DerivedProperties {
+		b = protowire.AppendTag(b, protoDerivedProperties, protowire.BytesType)
+		b = protowire.AppendString(b, name)
+	}
+	b = appendTime(b, protoExpiresAt, r.ExpiresAt)
+	for name, at := range r.PropertyUpdatedAt {
+		e := appendString(nil, protoPropertyTimeName, name)
+		e = appendTime(e, protoPropertyTimeValue, at)
+		b = protowire.AppendTag(b, protoPropertyUpdatedAt, protowire.BytesType)
+		b = protowire.AppendBytes(b, e)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes the proto encoded data into dest.
+// Unknown fields are skipped.
+func (ProtoCodec) Unmarshal(data []byte, dest cache.Cacheable) error {
+	r, ok := dest.(*Record)
+	if !ok {
+		return dest.DecodeBytes(data)
+	}
+	*r = Record{}
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		var err error
+		switch num {
+		case protoKey:
+			r.Key = string(v)
+		case protoBlob:
+			r.Blob = append([]byte{}, v...)
+		case protoBlobSize:
+			r.BlobSize = int64(n)
+		case protoExternalBlob:
+			r.ExternalBlob, err = uuid.FromBytes(v)
+		case protoChunked:
+			r.Chunked = n != 0
+		case protoChunkCount:
+			r.ChunkCount = int64(n)
+		case protoProperties:
+			err = r.unmarshalProperty(v)
+		case protoOwnerID:
+			r.OwnerID = string(v)
+		case protoTags:
+			r.Tags = append(r.Tags, string(v))
+		case protoOpaqueString:
+			r.OpaqueString = string(v)
+		case protoCategory:
+			r.Category = string(v)
+		case protoLeaseID:
+			r.LeaseID = string(v)
+		case protoLeaseExpiresAt:
+			r.LeaseExpiresAt = unmarshalTime(n)
+		case protoMD5:
+			r.MD5 = append([]byte{}, v...)
+		case protoCRC32C:
+			r.CRC32C = int32(uint32(n))
+		case protoHasCRC32C:
+			r.HasCRC32C = n != 0
+		case protoCreatedAt:
+			r.Timestamps.CreatedAt = unmarshalTime(n)
+		case protoUpdatedAt:
+			r.Timestamps.UpdatedAt = unmarshalTime(n)
+		case protoSignature:
+			r.Timestamps.Signature, err = uuid.FromBytes(v)
+		case protoStoreKey:
+			r.StoreKey = string(v)
+		case protoDerivedProperties:
+			r.DerivedProperties = append(r.DerivedProperties, string(v))
+		case protoExpiresAt:
+			r.ExpiresAt = unmarshalTime(n)
+		case protoPropertyUpdatedAt:
+			err = r.unmarshalPropertyTime(v)
+		}
+		return err
+	})
+}
+
+// appendPropertyValue appends the fields of v other than the name to b.
+// The elements of a list are appended as nested messages without names.
+func appendPropertyValue(b []byte, v *PropertyValue) []byte {
+	b = appendVarint(b, protoPropertyType, uint64(v.Type))
+	b = appendVarint(b, protoPropertyInteger, uint64(v.IntegerValue))
+	b = appendString(b, protoPropertyString, v.StringValue)
+	b = appendBool(b, protoPropertyBoolean, v.BooleanValue)
+	b = appendBytes(b, protoPropertyBytes, v.BytesValue)
+	for _, e := range v.ListValue {
+		b = protowire.AppendTag(b, protoPropertyListElement, protowire.BytesType)
+		b = protowire.AppendBytes(b, appendPropertyValue(nil, e))
+	}
+	return b
+}
+
+func (r *Record) unmarshalProperty(data []byte) error {
+	var name string
+	v := new(PropertyValue)
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, b []byte, n uint64) error {
+		if num == protoPropertyName {
+			name = string(b)
+			return nil
+		}
+		return unmarshalPropertyField(v, num, b, n)
+	})
+	if err != nil {
+		return err
+	}
+	if r.Properties == nil {
+		r.Properties = make(PropertyMap)
+	}
+	r.Properties[name] = v
+	return nil
+}
+
+func (r *Record) unmarshalPropertyTime(data []byte) error {
+	var name string
+	var at time.Time
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, b []byte, n uint64) error {
+		switch num {
+		case protoPropertyTimeName:
+			name = string(b)
+		case protoPropertyTimeValue:
+			at = unmarshalTime(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if r.PropertyUpdatedAt == nil {
+		r.PropertyUpdatedAt = make(PropertyTimes)
+	}
+	r.PropertyUpdatedAt[name] = at
+	return nil
+}
+
+// unmarshalPropertyField decodes a field of the property entry message into v.
+func unmarshalPropertyField(v *PropertyValue, num protowire.Number, b []byte, n uint64) error {
+	switch num {
+	case protoPropertyType:
+		v.Type = pb.Property_Type(n)
+	case protoPropertyInteger:
+		v.IntegerValue = int64(n)
+	case protoPropertyString:
+		v.StringValue = string(b)
+	case protoPropertyBoolean:
+		v.BooleanValue = n != 0
+	case protoPropertyBytes:
+		v.BytesValue = append([]byte{}, b...)
+	case protoPropertyListElement:
+		e := new(PropertyValue)
+		err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte, n uint64) error {
+			return unmarshalPropertyField(e, num, b, n)
+		})
+		if err != nil {
+			return err
+		}
+		v.ListValue = append(v.ListValue, e)
+	}
+	return nil
+}
+
+// consumeFields calls f for each field in data. f receives the content of
+// length-delimited fields in b and the value of varint fields in n.
+func consumeFields(data []byte, f func(num protowire.Number, typ protowire.Type, b []byte, n uint64) error) error {
+	for len(data) > 0 {
+		num, typ, l := protowire.ConsumeTag(data)
+		if l < 0 {
+			return fmt.Errorf("%w: %v", ErrMalformedProto, protowire.ParseError(l))
+		}
+		data = data[l:]
+		var b []byte
+		var n uint64
+		switch typ {
+		case protowire.BytesType:
+			b, l = protowire.ConsumeBytes(data)
+		case protowire.VarintType:
+			n, l = protowire.ConsumeVarint(data)
+		default:
+			l = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if l < 0 {
+			return fmt.Errorf("%w: %v", ErrMalformedProto, protowire.ParseError(l))
+		}
+		data = data[l:]
+		if err := f(num, typ, b, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	return appendVarint(b, num, protowire.EncodeBool(v))
+}
+
+// appendTime encodes t as nanoseconds since the Unix epoch.
+// A zero time is omitted and decoded back to the zero time.
+func appendTime(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(t.UnixNano()))
+}
+
+func unmarshalTime(n uint64) time.Time {
+	return time.Unix(0, int64(n))
+}
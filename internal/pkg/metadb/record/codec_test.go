@@ -0,0 +1,213 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/cache"
+	"github.com/googleforgames/open-saves/internal/pkg/config"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapDriver is an in-memory cache.Driver.
+type mapDriver map[string][]byte
+
+func (d mapDriver) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	d[key] = value
+	return nil
+}
+
+func (d mapDriver) Get(ctx context.Context, key string) ([]byte, error) {
+	v, ok := d[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return v, nil
+}
+
+func (d mapDriver) Delete(ctx context.Context, key string) error {
+	delete(d, key)
+	return nil
+}
+
+func (d mapDriver) ListKeys(ctx context.Context) ([]string, error) {
+	var keys []string
+	for k := range d {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (d mapDriver) FlushAll(ctx context.Context) error {
+	for k := range d {
+		delete(d, k)
+	}
+	return nil
+}
+
+func newCodecTestCache(driver mapDriver, codec cache.Codec) *cache.Cache {
+	c := cache.New(driver, &config.CacheConfig{DefaultTTL: time.Minute})
+	c.Codec = codec
+	return c
+}
+
+// fullRecord returns a Record with all fields set.
+func fullRecord() *Record {
+	return &Record{
+		Key:          "key",
+		Blob:         []byte{0x24, 0x42, 0x11},
+		BlobSize:     3,
+		ExternalBlob: uuid.MustParse("397F94F5-F851-4969-8BD8-7828ABC473A6"),
+		Chunked:      true,
+		ChunkCount:   2,
+		Properties: PropertyMap{
+			"int":    {Type: pb.Property_INTEGER, IntegerValue: -42},
+			"string": {Type: pb.Property_STRING, StringValue: "value"},
+			"bool":   {Type: pb.Property_BOOLEAN, BooleanValue: true},
+			"bytes":  {Type: pb.Property_BYTES, BytesValue: []byte{0, 1, 2}},
+			"list": {Type: pb.Property_LIST, ListValue: []*PropertyValue{
+				{Type: pb.Property_STRING, StringValue: "a"},
+				{Type: pb.Property_STRING, StringValue: "b"},
+			}},
+		},
+		OwnerID:           "owner",
+		Tags:              []string{"a", "b"},
+		OpaqueString:      "opaque",
+		Category:          "category",
+		LeaseID:           "lease",
+		LeaseExpiresAt:    time.Date(2023, 1, 2, 3, 4, 5, 6, time.UTC),
+		ExpiresAt:         time.Date(2023, 2, 3, 4, 5, 6, 7, time.UTC),
+		DerivedProperties: []string{"int"},
+		PropertyUpdatedAt: PropertyTimes{
+			"int":    time.Date(1992, 11, 27, 1, 3, 11, 0, time.UTC),
+			"string": time.Date(1992, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Checksums: checksums.Checksums{
+			MD5:       []byte{0xde, 0xad, 0xbe, 0xef},
+			CRC32C:    -12345,
+			HasCRC32C: true,
+		},
+		Timestamps: timestamps.Timestamps{
+			CreatedAt: time.Date(1992, 1, 15, 3, 15, 55, 0, time.UTC),
+			UpdatedAt: time.Date(1992, 11, 27, 1, 3, 11, 999, time.UTC),
+			Signature: uuid.MustParse("34E1A605-C0FD-4A3D-A9ED-9BA42CAFAF6E"),
+		},
+		StoreKey: "store",
+	}
+}
+
+// assertNoZeroFields fails if v has a zero exported field, so that new Record
+// fields are added to fullRecord and ProtoCodec.
+func assertNoZeroFields(t *testing.T, v reflect.Value, path string) {
+	t.Helper()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous || (fv.Kind() == reflect.Struct && f.Type != reflect.TypeOf(time.Time{})) {
+			assertNoZeroFields(t, fv, path+f.Name+".")
+			continue
+		}
+		if fv.IsZero() {
+			t.Errorf("fullRecord() has zero field %s%s", path, f.Name)
+		}
+	}
+}
+
+func TestCodec_FullRecord(t *testing.T) {
+	assertNoZeroFields(t, reflect.ValueOf(*fullRecord()), "")
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	codecs := []cache.Codec{ProtoCodec{}, JSONCodec{}, GobCodec{}}
+	records := map[string]*Record{
+		"full":  fullRecord(),
+		"empty": {Key: "key", StoreKey: "store"},
+	}
+	for _, codec := range codecs {
+		for name, want := range records {
+			t.Run(string(codec.Format())+"/"+name, func(t *testing.T) {
+				c := newCodecTestCache(mapDriver{}, codec)
+				ctx := context.Background()
+				require.NoError(t, c.Set(ctx, want))
+				got := new(Record)
+				require.NoError(t, c.Get(ctx, want.CacheKey(), got))
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("Get() = (-want, +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestCodec_FormatMismatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	driver := mapDriver{}
+	r := fullRecord()
+	require.NoError(t, newCodecTestCache(driver, JSONCodec{}).Set(ctx, r))
+	assert.Equal(t, JSONFormat, driver[r.CacheKey()][0])
+
+	for _, codec := range []cache.Codec{ProtoCodec{}, GobCodec{}} {
+		err := newCodecTestCache(driver, codec).Get(ctx, r.CacheKey(), new(Record))
+		assert.ErrorIs(t, err, cache.ErrFormatMismatch)
+	}
+
+	// Untagged entries written without a codec are rejected too.
+	driver[r.CacheKey()] = []byte{}
+	err := newCodecTestCache(driver, ProtoCodec{}).Get(ctx, r.CacheKey(), new(Record))
+	assert.ErrorIs(t, err, cache.ErrFormatMismatch)
+}
+
+func TestProtoCodec_Malformed(t *testing.T) {
+	t.Parallel()
+
+	err := ProtoCodec{}.Unmarshal([]byte{0x0a, 0x05, 'a'}, new(Record))
+	assert.ErrorIs(t, err, ErrMalformedProto)
+}
+
+func TestNewCodec(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]cache.Codec{
+		"":        nil,
+		"msgpack": nil,
+		"proto":   ProtoCodec{},
+		"json":    JSONCodec{},
+		"gob":     GobCodec{},
+	} {
+		got, err := NewCodec(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := NewCodec("xml")
+	assert.Error(t, err)
+}
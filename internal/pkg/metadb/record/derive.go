@@ -0,0 +1,70 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"reflect"
+	"sort"
+)
+
+// DerivationFunc computes the derived properties of r from its other fields,
+// e.g. "level_bucket" from "level". It is called by DeriveProperties without
+// the derived properties of the previous call, and returns the derived
+// properties to add to r. It must not modify r, and must return the same
+// properties for the same record so that recomputing them is idempotent.
+type DerivationFunc func(r *Record) PropertyMap
+
+// DeriveProperties replaces the derived properties of r with the ones computed
+// by fn, and records their names in DerivedProperties. A nil fn removes the
+// derived properties. It returns true if the derived properties changed, e.g.
+// to find records to rewrite after the DerivationFunc of a store is changed.
+func (r *Record) DeriveProperties(fn DerivationFunc) bool {
+	if fn == nil && len(r.DerivedProperties) == 0 {
+		return false
+	}
+
+	old := make(PropertyMap, len(r.DerivedProperties))
+	for _, name := range r.DerivedProperties {
+		if v, ok := r.Properties[name]; ok {
+			old[name] = v
+		}
+		delete(r.Properties, name)
+	}
+	oldNames := r.DerivedProperties
+
+	var derived PropertyMap
+	if fn != nil {
+		derived = fn(r)
+	}
+	r.DerivedProperties = nil
+	if len(derived) > 0 && r.Properties == nil {
+		r.Properties = make(PropertyMap)
+	}
+	for name, v := range derived {
+		r.Properties[name] = v
+		r.DerivedProperties = append(r.DerivedProperties, name)
+	}
+	sort.Strings(r.DerivedProperties)
+
+	if len(oldNames) != len(r.DerivedProperties) || len(old) != len(derived) {
+		return true
+	}
+	for i, name := range oldNames {
+		if name != r.DerivedProperties[i] || !reflect.DeepEqual(old[name], derived[name]) {
+			return true
+		}
+	}
+	return false
+}
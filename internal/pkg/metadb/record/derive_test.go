@@ -0,0 +1,116 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// levelBucket derives "level_bucket" from "level" in steps of 10.
+func levelBucket(r *Record) PropertyMap {
+	level, ok := r.Properties["level"]
+	if !ok {
+		return nil
+	}
+	return PropertyMap{
+		"level_bucket": {Type: pb.Property_INTEGER, IntegerValue: level.IntegerValue / 10 * 10},
+	}
+}
+
+func newLevelRecord(storeKey string, level int64) *Record {
+	return &Record{
+		Key:        "key",
+		StoreKey:   storeKey,
+		Properties: PropertyMap{"level": {Type: pb.Property_INTEGER, IntegerValue: level}},
+	}
+}
+
+func TestRecord_DerivePropertiesSaveLoad(t *testing.T) {
+	r := newLevelRecord("derive-save-load", 42)
+
+	// Save doesn't derive the properties by itself.
+	_, err := r.Save()
+	require.NoError(t, err)
+	assert.Empty(t, r.DerivedProperties)
+	assert.NotContains(t, r.Properties, "level_bucket")
+
+	assert.True(t, r.DeriveProperties(levelBucket))
+	ps, err := r.Save()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"level_bucket"}, r.DerivedProperties)
+	if assert.Contains(t, r.Properties, "level_bucket") {
+		assert.Equal(t, int64(40), r.Properties["level_bucket"].IntegerValue)
+	}
+
+	loaded := new(Record)
+	require.NoError(t, loaded.Load(ps))
+	assert.Equal(t, r.DerivedProperties, loaded.DerivedProperties)
+	assert.Equal(t, r.Properties, loaded.Properties)
+
+	// Derived properties follow the source property.
+	r.Properties["level"].IntegerValue = 57
+	assert.True(t, r.DeriveProperties(levelBucket))
+	assert.Equal(t, int64(50), r.Properties["level_bucket"].IntegerValue)
+
+	// And are removed when the DerivationFunc no longer returns them.
+	delete(r.Properties, "level")
+	assert.True(t, r.DeriveProperties(levelBucket))
+	assert.Empty(t, r.DerivedProperties)
+	assert.NotContains(t, r.Properties, "level_bucket")
+}
+
+func TestRecord_DerivePropertiesIdempotent(t *testing.T) {
+	r := newLevelRecord("derive-idempotent", 42)
+
+	assert.True(t, r.DeriveProperties(levelBucket))
+	derived := cloneTestProperties(r.Properties)
+	for i := 0; i < 3; i++ {
+		assert.False(t, r.DeriveProperties(levelBucket), "re-running the derivation must not change the record")
+		assert.Equal(t, derived, r.Properties)
+		assert.Equal(t, []string{"level_bucket"}, r.DerivedProperties)
+	}
+
+	// A client overwriting the derived property doesn't change the result.
+	r.Properties["level_bucket"] = &PropertyValue{Type: pb.Property_INTEGER, IntegerValue: 99}
+	assert.True(t, r.DeriveProperties(levelBucket))
+	assert.Equal(t, derived, r.Properties)
+}
+
+func TestRecord_DerivePropertiesNilFunc(t *testing.T) {
+	r := newLevelRecord("derive-nil", 42)
+
+	assert.False(t, r.DeriveProperties(nil))
+	assert.Empty(t, r.DerivedProperties)
+	assert.NotContains(t, r.Properties, "level_bucket")
+
+	// Derived properties are removed when the DerivationFunc is unset.
+	assert.True(t, r.DeriveProperties(levelBucket))
+	assert.True(t, r.DeriveProperties(nil))
+	assert.Empty(t, r.DerivedProperties)
+	assert.NotContains(t, r.Properties, "level_bucket")
+}
+
+func cloneTestProperties(m PropertyMap) PropertyMap {
+	c := make(PropertyMap, len(m))
+	for k, v := range m {
+		copied := *v
+		c[k] = &copied
+	}
+	return c
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"bytes"
+	"sort"
+
+	pb "github.com/googleforgames/open-saves/api"
+)
+
+// RecordDiff describes the changes from one version of a record to another.
+// All key and tag lists are sorted.
+type RecordDiff struct {
+	AddedProperties   []string
+	ChangedProperties []string
+	RemovedProperties []string
+	AddedTags         []string
+	RemovedTags       []string
+	// BlobChanged is true if the inline blob is different.
+	BlobChanged bool
+}
+
+// IsEmpty reports whether there are no changes.
+func (d RecordDiff) IsEmpty() bool {
+	return len(d.AddedProperties) == 0 && len(d.ChangedProperties) == 0 && len(d.RemovedProperties) == 0 &&
+		len(d.AddedTags) == 0 && len(d.RemovedTags) == 0 && !d.BlobChanged
+}
+
+// Diff returns the changes from other, the older version, to r.
+// Everything in r is considered added if other is nil.
+// Properties whose type changed are reported as changed.
+func (r *Record) Diff(other *Record) RecordDiff {
+	if other == nil {
+		other = new(Record)
+	}
+	var d RecordDiff
+	for k, v := range r.Properties {
+		o, ok := other.Properties[k]
+		switch {
+		case !ok:
+			d.AddedProperties = append(d.AddedProperties, k)
+		case !equalPropertyValues(v, o):
+			d.ChangedProperties = append(d.ChangedProperties, k)
+		}
+	}
+	for k := range other.Properties {
+		if _, ok := r.Properties[k]; !ok {
+			d.RemovedProperties = append(d.RemovedProperties, k)
+		}
+	}
+
+	d.AddedTags = missingTags(r.Tags, other.Tags)
+	d.RemovedTags = missingTags(other.Tags, r.Tags)
+	d.BlobChanged = !bytes.Equal(r.Blob, other.Blob)
+
+	sort.Strings(d.AddedProperties)
+	sort.Strings(d.ChangedProperties)
+	sort.Strings(d.RemovedProperties)
+	return d
+}
+
+// missingTags returns the sorted, unique tags in tags that are not in from.
+func missingTags(tags, from []string) []string {
+	exists := make(map[string]bool, len(from))
+	for _, t := range from {
+		exists[t] = true
+	}
+	var missing []string
+	for _, t := range tags {
+		if !exists[t] {
+			missing = append(missing, t)
+			exists[t] = true
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// equalPropertyValues reports whether a and b have the same type and value.
+func equalPropertyValues(a, b *PropertyValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case pb.Property_BOOLEAN:
+		return a.BooleanValue == b.BooleanValue
+	case pb.Property_INTEGER:
+		return a.IntegerValue == b.IntegerValue
+	case pb.Property_STRING:
+		return a.StringValue == b.StringValue
+	case pb.Property_BYTES:
+		return bytes.Equal(a.BytesValue, b.BytesValue)
+	case pb.Property_LIST:
+		if len(a.ListValue) != len(b.ListValue) {
+			return false
+		}
+		for i := range a.ListValue {
+			if !equalPropertyValues(a.ListValue[i], b.ListValue[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDiffTestRecord() *Record {
+	return &Record{
+		Key:  "key",
+		Blob: []byte{0x01, 0x02},
+		Properties: PropertyMap{
+			"int":   {Type: pb.Property_INTEGER, IntegerValue: 42},
+			"str":   {Type: pb.Property_STRING, StringValue: "value"},
+			"bytes": {Type: pb.Property_BYTES, BytesValue: []byte{0x03}},
+		},
+		Tags: []string{"a", "b"},
+	}
+}
+
+func TestRecord_DiffIdentical(t *testing.T) {
+	r := newDiffTestRecord()
+	d := r.Diff(newDiffTestRecord())
+	assert.Equal(t, RecordDiff{}, d)
+	assert.True(t, d.IsEmpty())
+}
+
+func TestRecord_DiffNil(t *testing.T) {
+	d := newDiffTestRecord().Diff(nil)
+	assert.Equal(t, RecordDiff{
+		AddedProperties: []string{"bytes", "int", "str"},
+		AddedTags:       []string{"a", "b"},
+		BlobChanged:     true,
+	}, d)
+	assert.False(t, d.IsEmpty())
+
+	assert.True(t, new(Record).Diff(nil).IsEmpty())
+}
+
+func TestRecord_DiffProperties(t *testing.T) {
+	old := newDiffTestRecord()
+	r := newDiffTestRecord()
+	delete(r.Properties, "str")
+	r.Properties["int"].IntegerValue = 43
+	r.Properties["bytes"].BytesValue = []byte{0x04}
+	r.Properties["bool"] = &PropertyValue{Type: pb.Property_BOOLEAN, BooleanValue: true}
+
+	assert.Equal(t, RecordDiff{
+		AddedProperties:   []string{"bool"},
+		ChangedProperties: []string{"bytes", "int"},
+		RemovedProperties: []string{"str"},
+	}, r.Diff(old))
+}
+
+func TestRecord_DiffPropertyType(t *testing.T) {
+	old := newDiffTestRecord()
+	r := newDiffTestRecord()
+	r.Properties["int"] = &PropertyValue{Type: pb.Property_STRING, StringValue: "42"}
+
+	assert.Equal(t, RecordDiff{ChangedProperties: []string{"int"}}, r.Diff(old))
+}
+
+func TestRecord_DiffTags(t *testing.T) {
+	old := newDiffTestRecord()
+	r := newDiffTestRecord()
+	r.Tags = []string{"c", "b", "c"}
+
+	assert.Equal(t, RecordDiff{
+		AddedTags:   []string{"c"},
+		RemovedTags: []string{"a"},
+	}, r.Diff(old))
+
+	// Tag order doesn't matter.
+	r.Tags = []string{"b", "a"}
+	assert.True(t, r.Diff(old).IsEmpty())
+}
+
+func TestRecord_DiffBlob(t *testing.T) {
+	old := newDiffTestRecord()
+	r := newDiffTestRecord()
+	r.Blob = []byte{0x01, 0x03}
+	assert.Equal(t, RecordDiff{BlobChanged: true}, r.Diff(old))
+
+	r.Blob = nil
+	assert.Equal(t, RecordDiff{BlobChanged: true}, r.Diff(old))
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+// MaxEntitySize is the maximum size of a Datastore entity in bytes.
+const MaxEntitySize = 1 << 20
+
+// entityOverhead is the fixed number of bytes Datastore adds to the size of
+// every entity and key.
+const entityOverhead = 32
+
+// ErrEntityTooLarge is returned by Record.Save when the estimated size of the
+// entity exceeds SaveOptions.MaxEntitySize.
+var ErrEntityTooLarge = errors.New("the record is too large to be saved")
+
+// maxEntitySize returns the limit of the estimated entity size in o.
+func (o SaveOptions) maxEntitySize() int64 {
+	if o.MaxEntitySize <= 0 || o.MaxEntitySize > MaxEntitySize {
+		return MaxEntitySize
+	}
+	return o.MaxEntitySize
+}
+
+// checkEntitySize returns ErrEntityTooLarge with the estimated size if the
+// entity of r with properties exceeds limit.
+func (r *Record) checkEntitySize(properties []datastore.Property, limit int64) error {
+	size := int64(entityOverhead+len(r.StoreKey)+1+len(r.Key)+1) + estimatePropertiesSize(properties)
+	if size <= limit {
+		return nil
+	}
+	return fmt.Errorf("%w: the estimated size is %d bytes, which exceeds the limit of %d bytes; consider moving the inline blob (%d bytes) to an external blob",
+		ErrEntityTooLarge, size, limit, len(r.Blob))
+}
+
+// estimatePropertiesSize estimates the serialized size of properties after
+// the storage size calculation of Datastore, where each property counts the
+// length of its name plus one and the size of its value.
+func estimatePropertiesSize(properties []datastore.Property) int64 {
+	var size int64
+	for _, p := range properties {
+		size += int64(len(p.Name)+1) + estimateValueSize(p.Value)
+	}
+	return size
+}
+
+func estimateValueSize(v interface{}) int64 {
+	switch v := v.(type) {
+	case nil, bool:
+		return 1
+	case int64, float64, time.Time:
+		return 8
+	case string:
+		return int64(len(v) + 1)
+	case []byte:
+		return int64(len(v))
+	case *datastore.Key:
+		var size int64 = entityOverhead
+		for k := v; k != nil; k = k.Parent {
+			size += int64(len(k.Kind)+1+len(k.Name)+1) + 8
+		}
+		return size
+	case datastore.GeoPoint:
+		return 16
+	case *datastore.Entity:
+		if v == nil {
+			return 1
+		}
+		return estimatePropertiesSize(v.Properties)
+	case []interface{}:
+		var size int64
+		for _, e := range v {
+			size += estimateValueSize(e)
+		}
+		return size
+	default:
+		// Unknown values are not produced by the record types, but count
+		// them as a fixed-size value rather than ignoring them.
+		return 8
+	}
+}
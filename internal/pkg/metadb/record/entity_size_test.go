@@ -0,0 +1,86 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord_SaveUnderEntitySizeLimit(t *testing.T) {
+	blob := make([]byte, MaxEntitySize/2)
+	r := &Record{
+		Key:      "key",
+		StoreKey: "store",
+		Blob:     blob,
+		BlobSize: int64(len(blob)),
+		Properties: PropertyMap{
+			"name": {Type: pb.Property_STRING, StringValue: "value"},
+		},
+	}
+	_, err := r.Save()
+	assert.NoError(t, err)
+}
+
+func TestRecord_SaveLargeInlineBlob(t *testing.T) {
+	blob := make([]byte, MaxEntitySize)
+	r := &Record{
+		Key:      "key",
+		StoreKey: "store",
+		Blob:     blob,
+		BlobSize: int64(len(blob)),
+	}
+	_, err := r.Save()
+	if assert.ErrorIs(t, err, ErrEntityTooLarge) {
+		assert.Contains(t, err.Error(), "inline blob")
+	}
+}
+
+func TestRecord_SaveManyProperties(t *testing.T) {
+	value := strings.Repeat("x", 1024)
+	properties := make(PropertyMap)
+	for i := 0; i < 1100; i++ {
+		properties[fmt.Sprintf("prop%d", i)] = &PropertyValue{Type: pb.Property_STRING, StringValue: value}
+	}
+	r := &Record{Key: "key", StoreKey: "store", Properties: properties}
+	_, err := r.Save()
+	assert.ErrorIs(t, err, ErrEntityTooLarge)
+
+	// Removing some properties brings the record under the limit.
+	for i := 0; i < 200; i++ {
+		delete(r.Properties, fmt.Sprintf("prop%d", i))
+	}
+	_, err = r.Save()
+	assert.NoError(t, err)
+}
+
+func TestRecord_SaveConfiguredEntitySize(t *testing.T) {
+	blob := make([]byte, 2048)
+	r := &Record{Key: "key", StoreKey: "store", Blob: blob, BlobSize: int64(len(blob))}
+	_, err := r.WithSaveOptions(SaveOptions{MaxEntitySize: 1024}).Save()
+	if assert.ErrorIs(t, err, ErrEntityTooLarge) {
+		assert.Contains(t, err.Error(), "limit of 1024 bytes")
+	}
+	_, err = r.Save()
+	assert.NoError(t, err)
+
+	large := &Record{Key: "key", StoreKey: "store", Blob: make([]byte, MaxEntitySize), BlobSize: MaxEntitySize}
+	_, err = large.WithSaveOptions(SaveOptions{MaxEntitySize: 2 * MaxEntitySize}).Save()
+	assert.ErrorIs(t, err, ErrEntityTooLarge, "the limit can't be raised above MaxEntitySize")
+}
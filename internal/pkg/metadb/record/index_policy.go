@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"cloud.google.com/go/datastore"
+)
+
+// propertiesName is the name of the Datastore property of Record.Properties.
+const propertiesName = "Properties"
+
+// IndexPolicy restricts the record properties that are indexed when records
+// are saved to Properties. Other properties are saved with NoIndex and can't
+// be used in queries. An empty Properties indexes none. Bytes properties are
+// never indexed.
+type IndexPolicy struct {
+	Properties []string
+}
+
+// applyIndexPolicy sets NoIndex on the record properties in properties that
+// are not indexed by policy. All properties are indexed if policy is nil.
+func applyIndexPolicy(policy *IndexPolicy, mode PropertyNameMode, properties []datastore.Property) error {
+	if policy == nil {
+		return nil
+	}
+	allowed := make(map[string]bool, len(policy.Properties))
+	for _, name := range policy.Properties {
+		encoded, err := EncodePropertyName(name, mode)
+		if err != nil {
+			return err
+		}
+		allowed[encoded] = true
+	}
+	for _, p := range properties {
+		if p.Name != propertiesName {
+			continue
+		}
+		if e, ok := p.Value.(*datastore.Entity); ok {
+			for i := range e.Properties {
+				if !allowed[e.Properties[i].Name] {
+					e.Properties[i].NoIndex = true
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// savedPropertyNoIndex saves r with o and returns the NoIndex flags of its properties.
+func savedPropertyNoIndex(t *testing.T, r *Record, o SaveOptions) map[string]bool {
+	t.Helper()
+	ps, err := r.WithSaveOptions(o).Save()
+	require.NoError(t, err)
+	got := make(map[string]bool)
+	for _, p := range ps {
+		if p.Name != propertiesName {
+			continue
+		}
+		e, ok := p.Value.(*datastore.Entity)
+		require.True(t, ok, "Properties must be saved as an entity")
+		for _, ep := range e.Properties {
+			got[ep.Name] = ep.NoIndex
+		}
+	}
+	return got
+}
+
+func newIndexPolicyTestRecord() *Record {
+	return &Record{
+		Key:      "record",
+		StoreKey: "store",
+		Properties: PropertyMap{
+			"level": {Type: pb.Property_INTEGER, IntegerValue: 7},
+			"name":  {Type: pb.Property_STRING, StringValue: "hero"},
+		},
+	}
+}
+
+func TestIndexPolicy_Restricted(t *testing.T) {
+	r := newIndexPolicyTestRecord()
+	assert.Equal(t, map[string]bool{"level": true, "name": false},
+		savedPropertyNoIndex(t, r, SaveOptions{IndexPolicy: &IndexPolicy{Properties: []string{"name"}}}))
+	assert.Equal(t, map[string]bool{"level": true, "name": true},
+		savedPropertyNoIndex(t, r, SaveOptions{IndexPolicy: &IndexPolicy{}}))
+}
+
+func TestIndexPolicy_NoPolicy(t *testing.T) {
+	r := newIndexPolicyTestRecord()
+	assert.Equal(t, map[string]bool{"level": false, "name": false}, savedPropertyNoIndex(t, r, SaveOptions{}))
+}
+
+func TestIndexPolicy_WithSaveOptionsLoad(t *testing.T) {
+	r := newIndexPolicyTestRecord()
+	ps, err := r.WithSaveOptions(SaveOptions{IndexPolicy: &IndexPolicy{}}).Save()
+	require.NoError(t, err)
+
+	loaded := new(Record)
+	require.NoError(t, loaded.WithSaveOptions(SaveOptions{}).Load(ps))
+	assert.Equal(t, r.Properties, loaded.Properties)
+}
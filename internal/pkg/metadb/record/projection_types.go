@@ -0,0 +1,95 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"strings"
+
+	"cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+)
+
+// ProjectionTypesName is the name of the indexed Datastore property that lists
+// the record properties that projection queries can't read as is, so that
+// they can be told apart by a projection of their own. Each value is the
+// type and the encoded name of a property, e.g. "LIST:items".
+//   - LIST properties are projected as one result per distinct element, which
+//     loses the order and duplicates, and a LIST of one element can't be told
+//     from a scalar.
+//   - BYTES properties, and LISTs of them, are never indexed and can't be
+//     projected at all.
+//
+// Records saved before ProjectionTypes was added don't have it until they are
+// saved again.
+const ProjectionTypesName = "ProjectionTypes"
+
+// projectionTypes returns the ProjectionTypes property of the encoded
+// properties of a record, and false if the record has no LIST or BYTES property.
+func projectionTypes(properties []datastore.Property) (datastore.Property, bool) {
+	var values []interface{}
+	for _, p := range properties {
+		if p.Name != propertiesName {
+			continue
+		}
+		e, ok := p.Value.(*datastore.Entity)
+		if !ok {
+			continue
+		}
+		for _, ep := range e.Properties {
+			switch ep.Value.(type) {
+			case []byte:
+				values = append(values, pb.Property_BYTES.String()+":"+ep.Name)
+			case []interface{}:
+				// Lists of BYTES are saved with NoIndex.
+				if ep.NoIndex {
+					values = append(values, pb.Property_BYTES.String()+":"+ep.Name)
+				} else {
+					values = append(values, pb.Property_LIST.String()+":"+ep.Name)
+				}
+			}
+		}
+	}
+	if len(values) == 0 {
+		return datastore.Property{}, false
+	}
+	return datastore.Property{Name: ProjectionTypesName, Value: values}, true
+}
+
+// ParseProjectionType parses a value of the ProjectionTypes property into the
+// type and the encoded name of a property. ok is false if v is malformed.
+func ParseProjectionType(v string) (t pb.Property_Type, name string, ok bool) {
+	typeName, name, found := strings.Cut(v, ":")
+	if !found {
+		return pb.Property_DATATYPE_UNDEFINED, "", false
+	}
+	switch typeName {
+	case pb.Property_LIST.String():
+		return pb.Property_LIST, name, true
+	case pb.Property_BYTES.String():
+		return pb.Property_BYTES, name, true
+	}
+	return pb.Property_DATATYPE_UNDEFINED, "", false
+}
+
+// removeProjectionTypes returns ps without the ProjectionTypes property, which
+// is derived from Properties and not loaded.
+func removeProjectionTypes(ps []datastore.Property) []datastore.Property {
+	for i, p := range ps {
+		if p.Name == ProjectionTypesName {
+			return append(ps[:i:i], ps[i+1:]...)
+		}
+	}
+	return ps
+}
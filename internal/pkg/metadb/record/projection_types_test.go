@@ -0,0 +1,81 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"testing"
+
+	"cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_SaveProjectionTypes(t *testing.T) {
+	r := &Record{
+		Key: "key",
+		Properties: PropertyMap{
+			"score":  {Type: pb.Property_INTEGER, IntegerValue: 1},
+			"avatar": {Type: pb.Property_BYTES, BytesValue: []byte{1}},
+			"items":  {Type: pb.Property_LIST, ListValue: []*PropertyValue{{Type: pb.Property_STRING, StringValue: "sword"}}},
+			"blobs":  {Type: pb.Property_LIST, ListValue: []*PropertyValue{{Type: pb.Property_BYTES, BytesValue: []byte{2}}}},
+		},
+	}
+	ps, err := r.Save()
+	require.NoError(t, err)
+	var got []interface{}
+	for _, p := range ps {
+		if p.Name == ProjectionTypesName {
+			assert.False(t, p.NoIndex, "ProjectionTypes must be indexed to be projected")
+			got = p.Value.([]interface{})
+		}
+	}
+	assert.ElementsMatch(t, []interface{}{"BYTES:avatar", "LIST:items", "BYTES:blobs"}, got)
+
+	loaded := new(Record)
+	require.NoError(t, loaded.Load(ps))
+	assert.Equal(t, r.Properties, loaded.Properties)
+
+	ps, err = (&Record{Key: "key", Properties: PropertyMap{
+		"score": {Type: pb.Property_INTEGER, IntegerValue: 1},
+	}}).Save()
+	require.NoError(t, err)
+	for _, p := range ps {
+		assert.NotEqual(t, ProjectionTypesName, p.Name, "ProjectionTypes is omitted without LIST or BYTES properties")
+	}
+}
+
+func TestParseProjectionType(t *testing.T) {
+	typ, name, ok := ParseProjectionType("LIST:items")
+	assert.True(t, ok)
+	assert.Equal(t, pb.Property_LIST, typ)
+	assert.Equal(t, "items", name)
+
+	typ, name, ok = ParseProjectionType("BYTES:a:b")
+	assert.True(t, ok)
+	assert.Equal(t, pb.Property_BYTES, typ)
+	assert.Equal(t, "a:b", name)
+
+	for _, v := range []string{"items", "STRING:items", ""} {
+		_, _, ok = ParseProjectionType(v)
+		assert.False(t, ok, v)
+	}
+}
+
+func TestRemoveProjectionTypes(t *testing.T) {
+	ps := []datastore.Property{{Name: "OwnerID"}, {Name: ProjectionTypesName}, {Name: "Tags"}}
+	assert.Equal(t, []datastore.Property{{Name: "OwnerID"}, {Name: "Tags"}}, removeProjectionTypes(ps))
+	assert.Equal(t, []datastore.Property{{Name: "Tags"}}, removeProjectionTypes([]datastore.Property{{Name: "Tags"}}))
+}
@@ -55,8 +55,21 @@ func (m *PropertyMap) ToProto() map[string]*pb.Property {
 	return ret
 }
 
+// ValidateLists checks that all LIST properties in m have elements of the
+// same scalar type.
+func (m PropertyMap) ValidateLists() error {
+	for name, value := range m {
+		if err := value.ValidateList(); err != nil {
+			return fmt.Errorf("property [%s]: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // Save implements the Datastore PropertyLoadSaver interface and converts
 // PropertyMap to a slice of datastore Properties.
+// Property names are saved as is, and encoded with EncodePropertyName when
+// the Record is saved.
 func (m *PropertyMap) Save() ([]datastore.Property, error) {
 	var ps []datastore.Property
 	if m == nil {
@@ -79,6 +92,28 @@ func (m *PropertyMap) Save() ([]datastore.Property, error) {
 				Name:  name,
 				Value: value.StringValue,
 			})
+		case pb.Property_BYTES:
+			ps = append(ps, datastore.Property{
+				Name:    name,
+				Value:   value.BytesValue,
+				NoIndex: true,
+			})
+		case pb.Property_LIST:
+			// Lists are saved as array values, which Datastore indexes
+			// element by element so that an equality filter matches lists
+			// containing the value.
+			if err := value.ValidateList(); err != nil {
+				return nil, fmt.Errorf("error storing property [%s]: %w", name, err)
+			}
+			values := make([]interface{}, 0, len(value.ListValue))
+			for _, v := range value.ListValue {
+				values = append(values, scalarValue(v))
+			}
+			ps = append(ps, datastore.Property{
+				Name:    name,
+				Value:   values,
+				NoIndex: value.ElementType() == pb.Property_BYTES,
+			})
 		default:
 			return nil,
 				fmt.Errorf(
@@ -91,6 +126,7 @@ func (m *PropertyMap) Save() ([]datastore.Property, error) {
 
 // Load implements the Datastore PropertyLoadSaver interface and converts
 // individual properties to PropertyMap.
+// Property names are decoded with DecodePropertyName.
 func (m *PropertyMap) Load(ps []datastore.Property) error {
 	if ps == nil || len(ps) == 0 {
 		// No custom properties
@@ -103,24 +139,77 @@ func (m *PropertyMap) Load(ps []datastore.Property) error {
 	}
 
 	for _, v := range ps {
-		var newValue = new(PropertyValue)
-		t := reflect.TypeOf(v.Value)
-		switch t.Kind() {
-		case reflect.Bool:
-			newValue.Type = pb.Property_BOOLEAN
-			newValue.BooleanValue = v.Value.(bool)
-		case reflect.Int64:
-			newValue.Type = pb.Property_INTEGER
-			newValue.IntegerValue = v.Value.(int64)
-		case reflect.String:
-			newValue.Type = pb.Property_STRING
-			newValue.StringValue = v.Value.(string)
-		default:
-			return fmt.Errorf(
-				"error loading property, unknown type: name=[%s], type=[%s]",
-				v.Name, t.Name())
+		var newValue *PropertyValue
+		if values, ok := v.Value.([]interface{}); ok {
+			newValue = &PropertyValue{Type: pb.Property_LIST}
+			for _, e := range values {
+				element, err := loadScalarValue(v.Name, e)
+				if err != nil {
+					return err
+				}
+				newValue.ListValue = append(newValue.ListValue, element)
+			}
+		} else {
+			var err error
+			if newValue, err = loadScalarValue(v.Name, v.Value); err != nil {
+				return err
+			}
 		}
-		(*m)[v.Name] = newValue
+		name, err := DecodePropertyName(v.Name)
+		if err != nil {
+			return err
+		}
+		(*m)[name] = newValue
+	}
+	return nil
+}
+
+// scalarValue returns the Datastore value of a scalar property.
+func scalarValue(p *PropertyValue) interface{} {
+	switch p.Type {
+	case pb.Property_BOOLEAN:
+		return p.BooleanValue
+	case pb.Property_INTEGER:
+		return p.IntegerValue
+	case pb.Property_STRING:
+		return p.StringValue
+	case pb.Property_BYTES:
+		return p.BytesValue
 	}
 	return nil
 }
+
+// loadScalarValue converts the Datastore value of the property named name to
+// a scalar PropertyValue.
+func loadScalarValue(name string, value interface{}) (*PropertyValue, error) {
+	newValue := new(PropertyValue)
+	t := reflect.TypeOf(value)
+	if t == nil {
+		return nil, fmt.Errorf("error loading property, nil value: name=[%s]", name)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		newValue.Type = pb.Property_BOOLEAN
+		newValue.BooleanValue = value.(bool)
+	case reflect.Int64:
+		newValue.Type = pb.Property_INTEGER
+		newValue.IntegerValue = value.(int64)
+	case reflect.String:
+		newValue.Type = pb.Property_STRING
+		newValue.StringValue = value.(string)
+	case reflect.Slice:
+		b, ok := value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf(
+				"error loading property, unknown type: name=[%s], type=[%s]",
+				name, t.String())
+		}
+		newValue.Type = pb.Property_BYTES
+		newValue.BytesValue = b
+	default:
+		return nil, fmt.Errorf(
+			"error loading property, unknown type: name=[%s], type=[%s]",
+			name, t.Name())
+	}
+	return newValue, nil
+}
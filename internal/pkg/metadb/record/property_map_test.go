@@ -69,3 +69,88 @@ func TestPropertyMap_ToProto(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	}
 }
+
+func TestPropertyMap_BytesRoundTrip(t *testing.T) {
+	propertyMap := PropertyMap{
+		"bytes": {Type: pb.Property_BYTES, BytesValue: []byte{0x00, 0x42, 0xff}},
+	}
+	ps, err := propertyMap.Save()
+	if assert.NoError(t, err) && assert.Len(t, ps, 1) {
+		assert.Equal(t, "bytes", ps[0].Name)
+		assert.True(t, ps[0].NoIndex, "BYTES properties should not be indexed")
+	}
+
+	loaded := make(PropertyMap)
+	if assert.NoError(t, loaded.Load(ps)) {
+		assert.Equal(t, propertyMap, loaded)
+	}
+
+	// Proto conversion should round-trip as well.
+	assert.Equal(t, propertyMap, NewPropertyMapFromProto(propertyMap.ToProto()))
+}
+
+func TestPropertyMap_ListRoundTrip(t *testing.T) {
+	propertyMap := PropertyMap{
+		"strings": {Type: pb.Property_LIST, ListValue: []*PropertyValue{
+			{Type: pb.Property_STRING, StringValue: "a"},
+			{Type: pb.Property_STRING, StringValue: "b"},
+		}},
+		"bytes": {Type: pb.Property_LIST, ListValue: []*PropertyValue{
+			{Type: pb.Property_BYTES, BytesValue: []byte{0x42}},
+		}},
+	}
+	ps, err := propertyMap.Save()
+	if assert.NoError(t, err) && assert.Len(t, ps, 2) {
+		for _, p := range ps {
+			switch p.Name {
+			case "strings":
+				assert.Equal(t, []interface{}{"a", "b"}, p.Value)
+				assert.False(t, p.NoIndex, "lists of strings should be indexed")
+			case "bytes":
+				assert.True(t, p.NoIndex, "lists of BYTES should not be indexed")
+			}
+		}
+	}
+
+	loaded := make(PropertyMap)
+	if assert.NoError(t, loaded.Load(ps)) {
+		assert.Equal(t, propertyMap, loaded)
+	}
+	assert.Equal(t, propertyMap, NewPropertyMapFromProto(propertyMap.ToProto()))
+
+	// Empty lists are saved as empty arrays.
+	empty := PropertyMap{"empty": {Type: pb.Property_LIST}}
+	ps, err = empty.Save()
+	if assert.NoError(t, err) && assert.Len(t, ps, 1) {
+		assert.Equal(t, []interface{}{}, ps[0].Value)
+	}
+}
+
+func TestPropertyMap_ListRejectsMixedTypes(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value *PropertyValue
+	}{
+		{"Mixed", &PropertyValue{Type: pb.Property_LIST, ListValue: []*PropertyValue{
+			{Type: pb.Property_STRING, StringValue: "a"},
+			{Type: pb.Property_INTEGER, IntegerValue: 1},
+		}}},
+		{"Nested", &PropertyValue{Type: pb.Property_LIST, ListValue: []*PropertyValue{
+			{Type: pb.Property_LIST},
+		}}},
+		{"Undefined", &PropertyValue{Type: pb.Property_LIST, ListValue: []*PropertyValue{
+			{Type: pb.Property_DATATYPE_UNDEFINED},
+		}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := PropertyMap{"list": tc.value}
+			assert.ErrorIs(t, m.ValidateLists(), ErrInvalidList)
+			_, err := m.Save()
+			assert.ErrorIs(t, err, ErrInvalidList)
+
+			_, err = FromProto("store", &pb.Record{Key: "record", Properties: m.ToProto()})
+			assert.ErrorIs(t, err, ErrInvalidList)
+		})
+	}
+}
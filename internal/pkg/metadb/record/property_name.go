@@ -0,0 +1,135 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/datastore"
+)
+
+// PropertyNameMode controls how property names that can't be saved to
+// Datastore as is are handled.
+type PropertyNameMode int32
+
+const (
+	// PropertyNameReject rejects such names with ErrInvalidPropertyName.
+	// This is the default.
+	PropertyNameReject PropertyNameMode = iota
+	// PropertyNameEscape saves such names escaped with EscapedPropertyPrefix,
+	// and unescapes them when loaded.
+	PropertyNameEscape
+)
+
+// EscapedPropertyPrefix is the reserved prefix of escaped property names.
+// Names that begin with it are escaped too so that clients can't collide
+// with escaped names.
+const EscapedPropertyPrefix = "~esc~"
+
+var ErrInvalidPropertyName = errors.New("invalid property name")
+
+// ParsePropertyNameMode returns the mode named s, either "reject" or "escape".
+// An empty string is PropertyNameReject.
+func ParsePropertyNameMode(s string) (PropertyNameMode, error) {
+	switch s {
+	case "", "reject":
+		return PropertyNameReject, nil
+	case "escape":
+		return PropertyNameEscape, nil
+	default:
+		return PropertyNameReject, fmt.Errorf("unknown property name mode: %q", s)
+	}
+}
+
+// ValidatePropertyName returns ErrInvalidPropertyName if name is empty, contains
+// a dot, which separates names in the flattened Properties.name encoding used
+// by queries, begins with an underscore, which Datastore reserves, or begins
+// with EscapedPropertyPrefix.
+func ValidatePropertyName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is empty", ErrInvalidPropertyName)
+	case strings.Contains(name, "."):
+		return fmt.Errorf("%w: %q contains a dot", ErrInvalidPropertyName, name)
+	case strings.HasPrefix(name, "_"):
+		return fmt.Errorf("%w: %q begins with an underscore", ErrInvalidPropertyName, name)
+	case strings.HasPrefix(name, EscapedPropertyPrefix):
+		return fmt.Errorf("%w: %q begins with the reserved prefix %q", ErrInvalidPropertyName, name, EscapedPropertyPrefix)
+	}
+	return nil
+}
+
+// EncodePropertyName returns the name to save to Datastore for the property
+// name in mode. Valid names are returned as is. Use it for property names of
+// query filters too.
+func EncodePropertyName(name string, mode PropertyNameMode) (string, error) {
+	err := ValidatePropertyName(name)
+	if err == nil {
+		return name, nil
+	}
+	if mode != PropertyNameEscape || name == "" {
+		return "", err
+	}
+	// PathEscape keeps '_' and '~', so escape dots explicitly.
+	return EscapedPropertyPrefix + strings.ReplaceAll(url.PathEscape(name), ".", "%2E"), nil
+}
+
+// DecodePropertyName returns the property name for the name saved to Datastore.
+// It unescapes names escaped by EncodePropertyName regardless of the mode.
+func DecodePropertyName(saved string) (string, error) {
+	if !strings.HasPrefix(saved, EscapedPropertyPrefix) {
+		return saved, nil
+	}
+	name, err := url.PathUnescape(strings.TrimPrefix(saved, EscapedPropertyPrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: can't unescape %q: %v", ErrInvalidPropertyName, saved, err)
+	}
+	return name, nil
+}
+
+// ValidateNames checks that all property names in m can be saved in mode.
+func (m PropertyMap) ValidateNames(mode PropertyNameMode) error {
+	for name := range m {
+		if _, err := EncodePropertyName(name, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodePropertyNames encodes the names of the record properties and their
+// modification times in properties with EncodePropertyName in mode.
+func encodePropertyNames(mode PropertyNameMode, properties []datastore.Property) error {
+	for _, p := range properties {
+		if p.Name != propertiesName && p.Name != propertyUpdatedAtName {
+			continue
+		}
+		e, ok := p.Value.(*datastore.Entity)
+		if !ok || e == nil {
+			continue
+		}
+		for i := range e.Properties {
+			name, err := EncodePropertyName(e.Properties[i].Name, mode)
+			if err != nil {
+				return err
+			}
+			e.Properties[i].Name = name
+		}
+	}
+	return nil
+}
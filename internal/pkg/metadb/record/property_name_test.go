@@ -0,0 +1,122 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveWithPropertyNameMode saves m as the properties of a record in mode and
+// returns the saved properties.
+func saveWithPropertyNameMode(t *testing.T, m PropertyMap, mode PropertyNameMode) ([]datastore.Property, error) {
+	t.Helper()
+	r := &Record{Key: "key", StoreKey: "store", Properties: m}
+	ps, err := r.WithSaveOptions(SaveOptions{PropertyNameMode: mode}).Save()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range ps {
+		if p.Name == propertiesName {
+			return p.Value.(*datastore.Entity).Properties, nil
+		}
+	}
+	t.Fatalf("the saved record has no %s", propertiesName)
+	return nil, nil
+}
+
+func TestValidatePropertyName(t *testing.T) {
+	for _, name := range []string{"score", "player_name", "level-1", "~tilde"} {
+		assert.NoError(t, ValidatePropertyName(name), name)
+	}
+	for _, name := range []string{"", "a.b", "_hidden", "__key__", EscapedPropertyPrefix + "x"} {
+		assert.ErrorIs(t, ValidatePropertyName(name), ErrInvalidPropertyName, name)
+	}
+}
+
+func TestRecord_SaveRejectsInvalidPropertyNames(t *testing.T) {
+	valid := PropertyMap{"score": {Type: pb.Property_INTEGER, IntegerValue: 1}}
+	ps, err := saveWithPropertyNameMode(t, valid, PropertyNameReject)
+	require.NoError(t, err)
+	if assert.Len(t, ps, 1) {
+		assert.Equal(t, "score", ps[0].Name)
+	}
+
+	for _, name := range []string{"a.b", "_hidden", EscapedPropertyPrefix + "x"} {
+		m := PropertyMap{name: {Type: pb.Property_INTEGER, IntegerValue: 1}}
+		_, err := saveWithPropertyNameMode(t, m, PropertyNameReject)
+		assert.ErrorIs(t, err, ErrInvalidPropertyName, name)
+		assert.ErrorIs(t, m.ValidateNames(PropertyNameReject), ErrInvalidPropertyName, name)
+	}
+}
+
+func TestRecord_SaveEscapesInvalidPropertyNames(t *testing.T) {
+	m := PropertyMap{
+		"score":                     {Type: pb.Property_INTEGER, IntegerValue: 1},
+		"a.b":                       {Type: pb.Property_INTEGER, IntegerValue: 2},
+		"_hidden":                   {Type: pb.Property_INTEGER, IntegerValue: 3},
+		"a.100%":                    {Type: pb.Property_INTEGER, IntegerValue: 4},
+		EscapedPropertyPrefix + "x": {Type: pb.Property_INTEGER, IntegerValue: 5},
+	}
+	assert.NoError(t, m.ValidateNames(PropertyNameEscape))
+	ps, err := saveWithPropertyNameMode(t, m, PropertyNameEscape)
+	require.NoError(t, err)
+	for _, p := range ps {
+		if p.Name != "score" {
+			assert.True(t, strings.HasPrefix(p.Name, EscapedPropertyPrefix), p.Name)
+			assert.NotContains(t, p.Name, ".", p.Name)
+		}
+	}
+
+	// Escaped names are unescaped when loaded, regardless of the mode.
+	got := make(PropertyMap)
+	require.NoError(t, got.Load(ps))
+	assert.Equal(t, m, got)
+
+	empty := PropertyMap{"": {Type: pb.Property_INTEGER}}
+	_, err = saveWithPropertyNameMode(t, empty, PropertyNameEscape)
+	assert.ErrorIs(t, err, ErrInvalidPropertyName)
+}
+
+func TestRecord_SaveEscapesPropertyTimes(t *testing.T) {
+	r := &Record{
+		Key:               "key",
+		StoreKey:          "store",
+		Properties:        PropertyMap{"a.b": {Type: pb.Property_INTEGER, IntegerValue: 1}},
+		PropertyUpdatedAt: PropertyTimes{"a.b": time.Unix(100, 0)},
+	}
+	ps, err := r.WithSaveOptions(SaveOptions{PropertyNameMode: PropertyNameEscape}).Save()
+	require.NoError(t, err)
+
+	loaded := new(Record)
+	require.NoError(t, loaded.Load(ps))
+	assert.Equal(t, r.PropertyUpdatedAt, loaded.PropertyUpdatedAt)
+}
+
+func TestParsePropertyNameMode(t *testing.T) {
+	for s, want := range map[string]PropertyNameMode{"": PropertyNameReject, "reject": PropertyNameReject, "escape": PropertyNameEscape} {
+		got, err := ParsePropertyNameMode(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+	_, err := ParsePropertyNameMode("unknown")
+	assert.Error(t, err)
+}
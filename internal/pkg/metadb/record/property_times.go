@@ -0,0 +1,149 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/datastore"
+)
+
+// Assert PropertyTimes implements PropertyLoadSaver.
+var _ datastore.PropertyLoadSaver = new(PropertyTimes)
+
+// PropertyTimes maps property names to the times they were last modified.
+// It is saved as an unindexed nested entity with the names encoded by
+// EncodePropertyName when the Record is saved.
+type PropertyTimes map[string]time.Time
+
+// propertyUpdatedAtName is the name of the PropertyUpdatedAt field of Record.
+const propertyUpdatedAtName = "PropertyUpdatedAt"
+
+// Save implements the Datastore PropertyLoadSaver interface.
+func (t *PropertyTimes) Save() ([]datastore.Property, error) {
+	var ps []datastore.Property
+	if t == nil {
+		return ps, nil
+	}
+	for name, at := range *t {
+		ps = append(ps, datastore.Property{Name: name, Value: at, NoIndex: true})
+	}
+	return ps, nil
+}
+
+// Load implements the Datastore PropertyLoadSaver interface.
+func (t *PropertyTimes) Load(ps []datastore.Property) error {
+	if len(ps) == 0 {
+		return nil
+	}
+	if *t == nil {
+		*t = make(PropertyTimes, len(ps))
+	}
+	for _, p := range ps {
+		at, ok := p.Value.(time.Time)
+		if !ok {
+			return fmt.Errorf("error loading the modification time of property [%s]: unexpected type %T", p.Name, p.Value)
+		}
+		name, err := DecodePropertyName(p.Name)
+		if err != nil {
+			return err
+		}
+		(*t)[name] = at
+	}
+	return nil
+}
+
+// PropertyModifiedAt returns the time the property name was last modified.
+// Properties without a recorded time, such as those of records saved before
+// the times were tracked, are treated as modified at Timestamps.UpdatedAt.
+// It returns the zero time if r doesn't have the property.
+func (r *Record) PropertyModifiedAt(name string) time.Time {
+	if _, ok := r.Properties[name]; !ok {
+		return time.Time{}
+	}
+	if at, ok := r.PropertyUpdatedAt[name]; ok {
+		return at
+	}
+	return r.Timestamps.UpdatedAt
+}
+
+// ChangedPropertiesSince returns the sorted names of the properties modified
+// after since, so that a client can fetch only the properties newer than its
+// last sync. Removed properties are not reported; compare the names with
+// those known to the client to find them.
+func (r *Record) ChangedPropertiesSince(since time.Time) []string {
+	var names []string
+	for name := range r.Properties {
+		if r.PropertyModifiedAt(name).After(since) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StampPropertyChanges sets the modification time of the properties of r that
+// were added or changed from before, the properties prior to the mutation,
+// to at, and drops the times of the removed ones. Unchanged properties
+// without a time get beforeUpdatedAt, the UpdatedAt of the record prior to
+// the mutation, so that they are not reported as modified at at.
+func (r *Record) StampPropertyChanges(before PropertyMap, beforeUpdatedAt, at time.Time) {
+	if len(r.Properties) == 0 {
+		r.PropertyUpdatedAt = nil
+		return
+	}
+	times := make(PropertyTimes, len(r.Properties))
+	for name, v := range r.Properties {
+		old, ok := before[name]
+		switch {
+		case !ok || !equalPropertyValues(v, old):
+			times[name] = at
+		case !r.PropertyUpdatedAt[name].IsZero():
+			times[name] = r.PropertyUpdatedAt[name]
+		default:
+			times[name] = beforeUpdatedAt
+		}
+	}
+	r.PropertyUpdatedAt = times
+}
+
+// Clone returns a copy of m whose values can be modified without affecting m.
+func (m PropertyMap) Clone() PropertyMap {
+	if m == nil {
+		return nil
+	}
+	c := make(PropertyMap, len(m))
+	for name, v := range m {
+		c[name] = v.clone()
+	}
+	return c
+}
+
+func (v *PropertyValue) clone() *PropertyValue {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	c.BytesValue = append([]byte(nil), v.BytesValue...)
+	if v.ListValue != nil {
+		c.ListValue = make([]*PropertyValue, len(v.ListValue))
+		for i, e := range v.ListValue {
+			c.ListValue[i] = e.clone()
+		}
+	}
+	return &c
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_StampPropertyChanges(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := created.Add(time.Hour)
+	second := first.Add(time.Hour)
+	r := &Record{
+		Key: "key",
+		Properties: PropertyMap{
+			"score": {Type: pb.Property_INTEGER, IntegerValue: 1},
+			"name":  {Type: pb.Property_STRING, StringValue: "player"},
+			"old":   {Type: pb.Property_BOOLEAN, BooleanValue: true},
+		},
+		Timestamps: timestamps.Timestamps{CreatedAt: created, UpdatedAt: created},
+	}
+	// Properties without a time are modified at UpdatedAt.
+	assert.Equal(t, created, r.PropertyModifiedAt("name"))
+	assert.True(t, r.PropertyModifiedAt("missing").IsZero())
+
+	before := r.Properties.Clone()
+	r.Properties["score"].IntegerValue = 2
+	r.Properties["level"] = &PropertyValue{Type: pb.Property_INTEGER, IntegerValue: 5}
+	delete(r.Properties, "old")
+	r.Timestamps.UpdatedAt = first
+	r.StampPropertyChanges(before, created, first)
+	assert.Equal(t, PropertyTimes{"score": first, "level": first, "name": created}, r.PropertyUpdatedAt)
+
+	before = r.Properties.Clone()
+	r.Properties["name"] = &PropertyValue{Type: pb.Property_STRING, StringValue: "renamed"}
+	r.Timestamps.UpdatedAt = second
+	r.StampPropertyChanges(before, first, second)
+	assert.Equal(t, PropertyTimes{"score": first, "level": first, "name": second}, r.PropertyUpdatedAt)
+}
+
+func TestRecord_ChangedPropertiesSince(t *testing.T) {
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &Record{
+		Properties: PropertyMap{
+			"a": {Type: pb.Property_INTEGER, IntegerValue: 1},
+			"b": {Type: pb.Property_INTEGER, IntegerValue: 2},
+			"c": {Type: pb.Property_INTEGER, IntegerValue: 3},
+		},
+		PropertyUpdatedAt: PropertyTimes{
+			"a": base,
+			"b": base.Add(2 * time.Minute),
+		},
+		Timestamps: timestamps.Timestamps{UpdatedAt: base.Add(3 * time.Minute)},
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, r.ChangedPropertiesSince(time.Time{}))
+	assert.Equal(t, []string{"b", "c"}, r.ChangedPropertiesSince(base))
+	assert.Equal(t, []string{"c"}, r.ChangedPropertiesSince(base.Add(2*time.Minute)))
+	assert.Empty(t, r.ChangedPropertiesSince(base.Add(3*time.Minute)))
+}
+
+func TestPropertyMap_CloneIsIndependent(t *testing.T) {
+	m := PropertyMap{
+		"bytes": {Type: pb.Property_BYTES, BytesValue: []byte{1}},
+		"list":  {Type: pb.Property_LIST, ListValue: []*PropertyValue{{Type: pb.Property_INTEGER, IntegerValue: 1}}},
+	}
+	c := m.Clone()
+	c["bytes"].BytesValue[0] = 2
+	c["list"].ListValue[0].IntegerValue = 2
+	assert.Equal(t, []byte{1}, m["bytes"].BytesValue)
+	assert.Equal(t, int64(1), m["list"].ListValue[0].IntegerValue)
+	assert.Nil(t, PropertyMap(nil).Clone())
+}
+
+func TestRecord_SaveLoadPropertyUpdatedAt(t *testing.T) {
+	at := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &Record{
+		Key:               "key",
+		Properties:        PropertyMap{"score": {Type: pb.Property_INTEGER, IntegerValue: 1}},
+		PropertyUpdatedAt: PropertyTimes{"score": at},
+	}
+	ps, err := r.Save()
+	require.NoError(t, err)
+	var saved *datastore.Entity
+	for _, p := range ps {
+		if p.Name == "PropertyUpdatedAt" {
+			saved, _ = p.Value.(*datastore.Entity)
+		}
+	}
+	if assert.NotNil(t, saved) && assert.Len(t, saved.Properties, 1) {
+		assert.True(t, saved.Properties[0].NoIndex)
+	}
+
+	loaded := new(Record)
+	require.NoError(t, loaded.Load(ps))
+	assert.Equal(t, PropertyTimes{"score": at}, loaded.PropertyUpdatedAt)
+
+	// The times are omitted for records without them.
+	r.PropertyUpdatedAt = nil
+	ps, err = r.Save()
+	require.NoError(t, err)
+	for _, p := range ps {
+		assert.NotEqual(t, "PropertyUpdatedAt", p.Name)
+	}
+}
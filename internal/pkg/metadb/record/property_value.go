@@ -15,9 +15,18 @@
 package record
 
 import (
+	"errors"
+
 	pb "github.com/googleforgames/open-saves/api"
 )
 
+var (
+	ErrPropertyTypeMismatch = errors.New("the property has a different type")
+	// ErrInvalidList is returned when a LIST property has elements of
+	// different types or nested lists.
+	ErrInvalidList = errors.New("list elements must be scalars of the same type")
+)
+
 // PropertyValue is an internal representation of the user-defined property.
 // See the Open Saves API definition for details.
 type PropertyValue struct {
@@ -25,6 +34,10 @@ type PropertyValue struct {
 	IntegerValue int64
 	StringValue  string
 	BooleanValue bool
+	BytesValue   []byte
+	// ListValue has the elements of a LIST property. The elements are
+	// scalar PropertyValues of the same type.
+	ListValue []*PropertyValue
 }
 
 // ToProto converts the struct to a proto.
@@ -36,10 +49,116 @@ func (p *PropertyValue) ToProto() *pb.Property {
 		return NewIntegerPropertyProto(p.IntegerValue)
 	case pb.Property_STRING:
 		return NewStringPropertyProto(p.StringValue)
+	case pb.Property_BYTES:
+		return NewBytesPropertyProto(p.BytesValue)
+	case pb.Property_LIST:
+		values := make([]*pb.Property, 0, len(p.ListValue))
+		for _, v := range p.ListValue {
+			values = append(values, v.ToProto())
+		}
+		return NewListPropertyProto(values...)
 	}
 	return new(pb.Property)
 }
 
+// ElementType returns the type of the elements of a LIST property.
+// It returns DATATYPE_UNDEFINED if the property is not a LIST or is empty.
+func (p *PropertyValue) ElementType() pb.Property_Type {
+	if p.Type != pb.Property_LIST || len(p.ListValue) == 0 {
+		return pb.Property_DATATYPE_UNDEFINED
+	}
+	return p.ListValue[0].Type
+}
+
+// ValidateList returns ErrInvalidList if p is a LIST property with elements
+// that are lists, undefined, or of different types.
+// It returns nil for other properties.
+func (p *PropertyValue) ValidateList() error {
+	if p.Type != pb.Property_LIST {
+		return nil
+	}
+	t := p.ElementType()
+	for _, v := range p.ListValue {
+		if v == nil || v.Type != t || v.Type == pb.Property_LIST || v.Type == pb.Property_DATATYPE_UNDEFINED {
+			return ErrInvalidList
+		}
+	}
+	return nil
+}
+
+// GetBytes returns the value of a BYTES property.
+// Returns ErrPropertyTypeMismatch if the property is not BYTES.
+func (p *PropertyValue) GetBytes() ([]byte, error) {
+	if p.Type != pb.Property_BYTES {
+		return nil, ErrPropertyTypeMismatch
+	}
+	return p.BytesValue, nil
+}
+
+// checkList returns ErrPropertyTypeMismatch if p is not a LIST property
+// with elements of type t. Empty lists match any element type.
+func (p *PropertyValue) checkList(t pb.Property_Type) error {
+	if p.Type != pb.Property_LIST {
+		return ErrPropertyTypeMismatch
+	}
+	if et := p.ElementType(); et != pb.Property_DATATYPE_UNDEFINED && et != t {
+		return ErrPropertyTypeMismatch
+	}
+	return nil
+}
+
+// GetIntegerList returns the elements of a LIST property of INTEGER values.
+// Returns ErrPropertyTypeMismatch if the property is not such a list.
+func (p *PropertyValue) GetIntegerList() ([]int64, error) {
+	if err := p.checkList(pb.Property_INTEGER); err != nil {
+		return nil, err
+	}
+	ret := make([]int64, 0, len(p.ListValue))
+	for _, v := range p.ListValue {
+		ret = append(ret, v.IntegerValue)
+	}
+	return ret, nil
+}
+
+// GetStringList returns the elements of a LIST property of STRING values.
+// Returns ErrPropertyTypeMismatch if the property is not such a list.
+func (p *PropertyValue) GetStringList() ([]string, error) {
+	if err := p.checkList(pb.Property_STRING); err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0, len(p.ListValue))
+	for _, v := range p.ListValue {
+		ret = append(ret, v.StringValue)
+	}
+	return ret, nil
+}
+
+// GetBooleanList returns the elements of a LIST property of BOOLEAN values.
+// Returns ErrPropertyTypeMismatch if the property is not such a list.
+func (p *PropertyValue) GetBooleanList() ([]bool, error) {
+	if err := p.checkList(pb.Property_BOOLEAN); err != nil {
+		return nil, err
+	}
+	ret := make([]bool, 0, len(p.ListValue))
+	for _, v := range p.ListValue {
+		ret = append(ret, v.BooleanValue)
+	}
+	return ret, nil
+}
+
+// GetBytesList returns the elements of a LIST property of BYTES values.
+// Returns ErrPropertyTypeMismatch if the property is not such a list.
+func (p *PropertyValue) GetBytesList() ([][]byte, error) {
+	if err := p.checkList(pb.Property_BYTES); err != nil {
+		return nil, err
+	}
+	ret := make([][]byte, 0, len(p.ListValue))
+	for _, v := range p.ListValue {
+		ret = append(ret, v.BytesValue)
+	}
+	return ret, nil
+}
+
 // NewPropertyValueFromProto creates a new Property instance from a proto.
 // Passing nil returns a zero-initialized Property.
 func NewPropertyValueFromProto(proto *pb.Property) *PropertyValue {
@@ -56,6 +175,12 @@ func NewPropertyValueFromProto(proto *pb.Property) *PropertyValue {
 		ret.IntegerValue = proto.GetIntegerValue()
 	case pb.Property_STRING:
 		ret.StringValue = proto.GetStringValue()
+	case pb.Property_BYTES:
+		ret.BytesValue = proto.GetBytesValue()
+	case pb.Property_LIST:
+		for _, v := range proto.GetListValue().GetValues() {
+			ret.ListValue = append(ret.ListValue, NewPropertyValueFromProto(v))
+		}
 	}
 	return ret
 }
@@ -69,6 +194,8 @@ func ExtractValue(p *pb.Property) interface{} {
 		return p.GetIntegerValue()
 	case pb.Property_STRING:
 		return p.GetStringValue()
+	case pb.Property_BYTES:
+		return p.GetBytesValue()
 	}
 	return nil
 }
@@ -96,3 +223,19 @@ func NewStringPropertyProto(v string) *pb.Property {
 		Value: &pb.Property_StringValue{StringValue: v},
 	}
 }
+
+// NewBytesPropertyProto returns a new pb.Property with bytes value v.
+func NewBytesPropertyProto(v []byte) *pb.Property {
+	return &pb.Property{
+		Type:  pb.Property_BYTES,
+		Value: &pb.Property_BytesValue{BytesValue: v},
+	}
+}
+
+// NewListPropertyProto returns a new pb.Property with list value values.
+func NewListPropertyProto(values ...*pb.Property) *pb.Property {
+	return &pb.Property{
+		Type:  pb.Property_LIST,
+		Value: &pb.Property_ListValue{ListValue: &pb.ListValue{Values: values}},
+	}
+}
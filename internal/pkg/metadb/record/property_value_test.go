@@ -74,3 +74,54 @@ func TestPropertyValue_NewPropertyFromProto(t *testing.T) {
 	assert.Equal(t, stringExpected, NewPropertyValueFromProto(stringProto))
 
 }
+
+func TestPropertyValue_GetBytes(t *testing.T) {
+	p := &PropertyValue{Type: pb.Property_BYTES, BytesValue: []byte("abc")}
+	got, err := p.GetBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("abc"), got)
+
+	p = &PropertyValue{Type: pb.Property_STRING, StringValue: "abc"}
+	got, err = p.GetBytes()
+	assert.ErrorIs(t, err, ErrPropertyTypeMismatch)
+	assert.Nil(t, got)
+}
+
+func TestPropertyValue_GetList(t *testing.T) {
+	p := NewPropertyValueFromProto(NewListPropertyProto(
+		NewIntegerPropertyProto(1), NewIntegerPropertyProto(2)))
+	assert.Equal(t, pb.Property_LIST, p.Type)
+	assert.Equal(t, pb.Property_INTEGER, p.ElementType())
+	ints, err := p.GetIntegerList()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ints)
+	_, err = p.GetStringList()
+	assert.ErrorIs(t, err, ErrPropertyTypeMismatch)
+
+	p = NewPropertyValueFromProto(NewListPropertyProto(NewStringPropertyProto("a")))
+	strs, err := p.GetStringList()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, strs)
+
+	p = NewPropertyValueFromProto(NewListPropertyProto(NewBooleanPropertyProto(true)))
+	bools, err := p.GetBooleanList()
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true}, bools)
+
+	p = NewPropertyValueFromProto(NewListPropertyProto(NewBytesPropertyProto([]byte("a"))))
+	bs, err := p.GetBytesList()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a")}, bs)
+
+	// Empty lists match any element type.
+	p = &PropertyValue{Type: pb.Property_LIST}
+	assert.Equal(t, pb.Property_DATATYPE_UNDEFINED, p.ElementType())
+	strs, err = p.GetStringList()
+	assert.NoError(t, err)
+	assert.Empty(t, strs)
+
+	// Scalars are not lists.
+	p = &PropertyValue{Type: pb.Property_INTEGER, IntegerValue: 1}
+	_, err = p.GetIntegerList()
+	assert.ErrorIs(t, err, ErrPropertyTypeMismatch)
+}
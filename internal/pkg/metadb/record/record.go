@@ -15,6 +15,8 @@
 package record
 
 import (
+	"time"
+
 	"cloud.google.com/go/datastore"
 	"github.com/google/uuid"
 	pb "github.com/googleforgames/open-saves/api"
@@ -40,6 +42,27 @@ type Record struct {
 	OwnerID      string
 	Tags         []string
 	OpaqueString string `datastore:",noindex"`
+	// Category is the primary category of the record. Unlike Tags, a record has
+	// at most one category. It is always saved, even if empty, so that records
+	// without a category can be queried too.
+	Category string
+
+	// LeaseID is the ID of the lease currently held on the record for exclusive
+	// edits. It is valid until LeaseExpiresAt.
+	LeaseID        string    `datastore:",noindex,omitempty" msgpack:",omitempty"`
+	LeaseExpiresAt time.Time `datastore:",noindex,omitempty" msgpack:",omitempty"`
+
+	// ExpiresAt is the point in time when the record expires.
+	// The zero value means the record never expires.
+	ExpiresAt time.Time `datastore:",omitempty" msgpack:",omitempty"`
+
+	// DerivedProperties are the sorted names of the properties computed by the
+	// DerivationFunc of the store. MetaDB recomputes them every time it saves the record.
+	DerivedProperties []string `datastore:",noindex,omitempty" msgpack:",omitempty"`
+
+	// PropertyUpdatedAt are the times the properties were last modified, kept
+	// by StampPropertyChanges. See PropertyModifiedAt.
+	PropertyUpdatedAt PropertyTimes `datastore:",noindex,omitempty" msgpack:",omitempty"`
 
 	// Checksums have checksums for inline blobs.
 	// Note that a BlobRef object doesn't exist for inline blobs.
@@ -67,15 +90,72 @@ const externalBlobPropertyName = "ExternalBlob"
 // Save and Load replicates the default behaviors, however, they are required
 // for the KeyLoader interface.
 
+// SaveOptions are the settings of the store and the database that a record
+// is saved to. The zero value indexes all properties.
+type SaveOptions struct {
+	// IndexPolicy restricts the indexed properties if not nil.
+	IndexPolicy *IndexPolicy
+	// PropertyNameMode is how property names that can't be saved as is are
+	// handled. They are rejected by default.
+	PropertyNameMode PropertyNameMode
+	// MaxEntitySize is the maximum estimated entity size of the record, e.g.
+	// to leave a margin for estimation errors. MaxEntitySize is used if it is
+	// not positive or above MaxEntitySize.
+	MaxEntitySize int64
+}
+
+// recordWithOptions saves the record with the options.
+type recordWithOptions struct {
+	*Record
+	options SaveOptions
+}
+
+// WithSaveOptions returns a PropertyLoadSaver that saves r like Save with o
+// applied. Pass it to Datastore in place of r to save r to a store with settings.
+func (r *Record) WithSaveOptions(o SaveOptions) datastore.PropertyLoadSaver {
+	return &recordWithOptions{Record: r, options: o}
+}
+
+// Save implements the Datastore PropertyLoadSaver interface.
+func (r *recordWithOptions) Save() ([]datastore.Property, error) {
+	return r.save(r.options)
+}
+
 // Save implements the Datastore PropertyLoadSaver interface and converts struct fields
-// to Datastore properties.
+// to Datastore properties with the zero SaveOptions. It returns the error of Validate
+// if the record is invalid, and ErrEntityTooLarge if the estimated entity size exceeds
+// MaxEntitySize. Save doesn't modify r, so it can be called to preview the entity.
 func (r *Record) Save() ([]datastore.Property, error) {
+	return r.save(SaveOptions{})
+}
+
+func (r *Record) save(o SaveOptions) ([]datastore.Property, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if err := r.Properties.ValidateNames(o.PropertyNameMode); err != nil {
+		return nil, err
+	}
 	properties, err := datastore.SaveStruct(r)
 	if err != nil {
 		return nil, err
 	}
+	if err := encodePropertyNames(o.PropertyNameMode, properties); err != nil {
+		return nil, err
+	}
 	properties = append(properties,
 		timestamps.UUIDToDatastoreProperty(externalBlobPropertyName, r.ExternalBlob, false))
+	if p, ok := projectionTypes(properties); ok {
+		properties = append(properties, p)
+	}
+	// Timestamps are indexed so that queries can be sorted by them.
+	timestamps.IndexProperties(properties, "CreatedAt", "UpdatedAt")
+	if err := applyIndexPolicy(o.IndexPolicy, o.PropertyNameMode, properties); err != nil {
+		return nil, err
+	}
+	if err := r.checkEntitySize(properties, o.maxEntitySize()); err != nil {
+		return nil, err
+	}
 
 	return properties, nil
 }
@@ -90,6 +170,7 @@ func (r *Record) Load(ps []datastore.Property) error {
 			break
 		}
 	}
+	ps = removeProjectionTypes(ps)
 	externalBlob, ps, err := timestamps.LoadUUID(ps, externalBlobPropertyName)
 	if err != nil {
 		return err
@@ -111,6 +192,30 @@ func (r *Record) LoadKey(k *datastore.Key) error {
 	return nil
 }
 
+// LeaseActive reports whether a lease is held on the record at now.
+func (r *Record) LeaseActive(now time.Time) bool {
+	return r.LeaseID != "" && now.Before(r.LeaseExpiresAt)
+}
+
+// SetTTL sets ExpiresAt to ttl after now. defaultTTL is used instead if ttl
+// is not positive, and ExpiresAt is cleared if neither is positive, meaning
+// the record never expires.
+func (r *Record) SetTTL(ttl, defaultTTL time.Duration, now time.Time) {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if ttl <= 0 {
+		r.ExpiresAt = time.Time{}
+		return
+	}
+	r.ExpiresAt = now.Add(ttl)
+}
+
+// IsExpired reports whether the record has expired at now.
+func (r *Record) IsExpired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
 // GetStoreKey() returns the parent StoreKey.
 func (r *Record) GetStoreKey() string {
 	if r == nil {
@@ -136,6 +241,7 @@ func (r *Record) ToProto() *pb.Record {
 		CreatedAt:    timestamps.TimeToProto(r.Timestamps.CreatedAt),
 		UpdatedAt:    timestamps.TimeToProto(r.Timestamps.UpdatedAt),
 		Signature:    r.Timestamps.Signature[:],
+		ExpiresAt:    timestamps.TimeToProto(r.ExpiresAt),
 	}
 	return ret
 }
@@ -153,12 +259,16 @@ func FromProto(storeKey string, p *pb.Record) (*Record, error) {
 			return nil, err
 		}
 	}
+	properties := NewPropertyMapFromProto(p.GetProperties())
+	if err := properties.ValidateLists(); err != nil {
+		return nil, err
+	}
 	return &Record{
 		Key:          p.GetKey(),
 		BlobSize:     p.GetBlobSize(),
 		OwnerID:      p.GetOwnerId(),
 		Tags:         p.GetTags(),
-		Properties:   NewPropertyMapFromProto(p.GetProperties()),
+		Properties:   properties,
 		OpaqueString: p.GetOpaqueString(),
 		Timestamps: timestamps.Timestamps{
 			CreatedAt: p.GetCreatedAt().AsTime(),
@@ -208,3 +318,10 @@ func (r *Record) GetInlineBlobMetadata() *pb.BlobMetadata {
 		HasCrc32C: r.HasCRC32C,
 	}
 }
+
+// HasInlineBlob reports whether r has inline blob content. Empty inline blobs
+// have no content but are told apart from records without a blob by their
+// checksums, which are always set when an inline blob is stored.
+func (r *Record) HasInlineBlob() bool {
+	return len(r.Blob) > 0 || len(r.MD5) > 0 || r.HasCRC32C
+}
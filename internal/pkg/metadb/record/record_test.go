@@ -50,6 +50,7 @@ func TestRecord_Save(t *testing.T) {
 		OwnerID:      "owner",
 		OpaqueString: "Lorem ipsum dolor sit amet, consectetur adipiscing elit.",
 		Tags:         []string{"a", "b"},
+		Category:     "category",
 		Checksums:    checksumstest.RandomChecksums(t),
 		Timestamps: timestamps.Timestamps{
 			CreatedAt: createdAt,
@@ -61,7 +62,7 @@ func TestRecord_Save(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Save should not return err: %v", err)
 	}
-	if assert.Len(t, properties, 13, "Save didn't return the expected number of elements.") {
+	if assert.Len(t, properties, 14, "Save didn't return the expected number of elements.") {
 		idx := 4
 		assert.Equal(t, []datastore.Property{
 			{
@@ -115,8 +116,12 @@ func TestRecord_Save(t *testing.T) {
 				Value:   "Lorem ipsum dolor sit amet, consectetur adipiscing elit.",
 				NoIndex: true,
 			},
-		}, properties[idx:idx+3])
-		idx += 3
+			{
+				Name:  "Category",
+				Value: "category",
+			},
+		}, properties[idx:idx+4])
+		idx += 4
 		checksumstest.AssertPropertyListMatch(t, record.Checksums, properties[idx:idx+3])
 		idx += 3
 		assert.Equal(t, []datastore.Property{
@@ -125,14 +130,12 @@ func TestRecord_Save(t *testing.T) {
 				Value: &datastore.Entity{
 					Properties: []datastore.Property{
 						{
-							Name:    "CreatedAt",
-							Value:   createdAt,
-							NoIndex: true,
+							Name:  "CreatedAt",
+							Value: createdAt,
 						},
 						{
-							Name:    "UpdatedAt",
-							Value:   updatedAt,
-							NoIndex: true,
+							Name:  "UpdatedAt",
+							Value: updatedAt,
 						},
 						{
 							Name:    "Signature",
@@ -573,3 +576,69 @@ func TestRecord_GetStoreKey(t *testing.T) {
 		})
 	}
 }
+
+func TestRecord_CategoryRoundTrip(t *testing.T) {
+	for _, category := range []string{"weapons", ""} {
+		r := &Record{Category: category, Properties: make(PropertyMap)}
+		ps, err := r.Save()
+		if err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+		got := new(Record)
+		if err := got.Load(ps); err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		assert.Equal(t, category, got.Category)
+	}
+}
+
+func TestRecord_HasInlineBlob(t *testing.T) {
+	r := new(Record)
+	assert.False(t, r.HasInlineBlob())
+
+	digest := checksums.NewDigest()
+	r.Checksums = digest.Checksums()
+	assert.True(t, r.HasInlineBlob(), "an empty inline blob has checksums")
+
+	assert.True(t, (&Record{Blob: []byte{0x42}}).HasInlineBlob())
+}
+
+func TestRecord_SetTTL(t *testing.T) {
+	now := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	r := new(Record)
+
+	r.SetTTL(time.Hour, 10*time.Minute, now)
+	assert.Equal(t, now.Add(time.Hour), r.ExpiresAt)
+
+	r.SetTTL(0, 10*time.Minute, now)
+	assert.Equal(t, now.Add(10*time.Minute), r.ExpiresAt, "the default applies without a ttl")
+
+	r.SetTTL(0, 0, now)
+	assert.True(t, r.ExpiresAt.IsZero(), "the record never expires without a ttl or default")
+	assert.Nil(t, r.ToProto().GetExpiresAt())
+}
+
+func TestRecord_IsExpired(t *testing.T) {
+	now := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	assert.False(t, new(Record).IsExpired(now), "records without ExpiresAt never expire")
+
+	r := &Record{ExpiresAt: now}
+	assert.True(t, r.IsExpired(now))
+	assert.True(t, r.IsExpired(now.Add(time.Second)))
+	assert.False(t, r.IsExpired(now.Add(-time.Second)))
+	assert.Equal(t, now, r.ToProto().GetExpiresAt().AsTime())
+}
+
+func TestRecord_ExpiresAtRoundTrip(t *testing.T) {
+	expiresAt := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	r := &Record{ExpiresAt: expiresAt, Properties: make(PropertyMap)}
+	ps, err := r.Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	got := new(Record)
+	if err := got.Load(ps); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	assert.True(t, expiresAt.Equal(got.ExpiresAt))
+}
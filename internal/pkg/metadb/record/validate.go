@@ -0,0 +1,62 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MaxProperties is the maximum number of properties of a record.
+// It keeps the number of index entries of a record well under the Datastore
+// limit of 20,000 per entity.
+const MaxProperties = 5000
+
+// ErrInvalidRecord is wrapped by the errors returned by Validate.
+var ErrInvalidRecord = errors.New("invalid record")
+
+// Validate checks the invariants that every saved record must satisfy:
+//   - BlobSize and ChunkCount are not negative
+//   - the record doesn't have both an inline blob and an external blob
+//   - CreatedAt is not after UpdatedAt
+//   - the record has at most MaxProperties properties
+//
+// It returns all violations joined into a single error, each wrapping
+// ErrInvalidRecord, or nil if the record is valid.
+// Save calls Validate, so invalid records are never written to Datastore.
+func (r *Record) Validate() error {
+	var errs []error
+	invalid := func(format string, a ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w (%v): %s", ErrInvalidRecord, r.Key, fmt.Sprintf(format, a...)))
+	}
+	if r.BlobSize < 0 {
+		invalid("BlobSize must not be negative, got %v", r.BlobSize)
+	}
+	if r.ChunkCount < 0 {
+		invalid("ChunkCount must not be negative, got %v", r.ChunkCount)
+	}
+	if len(r.Blob) > 0 && r.ExternalBlob != uuid.Nil {
+		invalid("the record has both an inline blob and an external blob (%v)", r.ExternalBlob)
+	}
+	if ts := r.Timestamps; !ts.CreatedAt.IsZero() && !ts.UpdatedAt.IsZero() && ts.CreatedAt.After(ts.UpdatedAt) {
+		invalid("CreatedAt (%v) must not be after UpdatedAt (%v)", ts.CreatedAt, ts.UpdatedAt)
+	}
+	if len(r.Properties) > MaxProperties {
+		invalid("the record has %v properties, more than the maximum of %v", len(r.Properties), MaxProperties)
+	}
+	return errors.Join(errs...)
+}
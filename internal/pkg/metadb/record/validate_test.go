@@ -0,0 +1,93 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package record
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/stretchr/testify/assert"
+)
+
+func validTestRecord() *Record {
+	return &Record{
+		Key:        "key",
+		Blob:       []byte("blob"),
+		BlobSize:   4,
+		Properties: PropertyMap{"score": {Type: pb.Property_INTEGER, IntegerValue: 1}},
+		Timestamps: timestamps.Timestamps{
+			CreatedAt: time.Unix(100, 0),
+			UpdatedAt: time.Unix(200, 0),
+		},
+	}
+}
+
+func TestRecord_ValidateValid(t *testing.T) {
+	assert.NoError(t, validTestRecord().Validate())
+	assert.NoError(t, new(Record).Validate(), "a zero record is valid")
+
+	_, err := validTestRecord().Save()
+	assert.NoError(t, err)
+}
+
+func TestRecord_ValidateInvariants(t *testing.T) {
+	tooManyProperties := make(PropertyMap)
+	for i := 0; i <= MaxProperties; i++ {
+		tooManyProperties[fmt.Sprintf("p%d", i)] = &PropertyValue{Type: pb.Property_BOOLEAN}
+	}
+	testCases := []struct {
+		name    string
+		modify  func(r *Record)
+		message string
+	}{
+		{"negative BlobSize", func(r *Record) { r.BlobSize = -1 }, "BlobSize"},
+		{"negative ChunkCount", func(r *Record) { r.ChunkCount = -2 }, "ChunkCount"},
+		{"inline and external blobs", func(r *Record) { r.ExternalBlob = uuid.New() }, "external blob"},
+		{"CreatedAt after UpdatedAt", func(r *Record) { r.Timestamps.CreatedAt = time.Unix(300, 0) }, "CreatedAt"},
+		{"too many properties", func(r *Record) { r.Properties = tooManyProperties }, "properties"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := validTestRecord()
+			tc.modify(r)
+			err := r.Validate()
+			if assert.Error(t, err) {
+				assert.True(t, errors.Is(err, ErrInvalidRecord))
+				assert.Contains(t, err.Error(), tc.message)
+			}
+			_, saveErr := r.Save()
+			assert.Equal(t, err, saveErr, "Save() should return the Validate() error")
+		})
+	}
+}
+
+func TestRecord_ValidateAggregatesErrors(t *testing.T) {
+	r := validTestRecord()
+	r.BlobSize = -1
+	r.ChunkCount = -1
+	r.Timestamps.CreatedAt = time.Unix(300, 0)
+
+	err := r.Validate()
+	if assert.Error(t, err) {
+		assert.True(t, errors.Is(err, ErrInvalidRecord))
+		assert.Equal(t, 3, strings.Count(err.Error(), ErrInvalidRecord.Error()), err.Error())
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+// GetRecordWithBlobs fetches the record and all BlobRefs that belong to it,
+// regardless of their status, reading both concurrently.
+// If the record is fetched but the BlobRef query fails, it returns the record
+// along with the error, so check the record before discarding the result.
+// It returns nil for both if the record can't be fetched.
+func (m *MetaDB) GetRecordWithBlobs(ctx context.Context, storeKey, recordKey string) (*record.Record, []*blobref.BlobRef, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetRecordWithBlobs")
+	defer span.End()
+
+	return getRecordWithBlobs(ctx,
+		func(ctx context.Context) (*record.Record, error) {
+			return m.GetRecord(ctx, storeKey, recordKey)
+		},
+		func(ctx context.Context) ([]*blobref.BlobRef, error) {
+			return m.getRecordBlobRefs(ctx, storeKey, recordKey)
+		})
+}
+
+// getRecordWithBlobs calls getRecord and getBlobs in parallel and combines the
+// results as described in GetRecordWithBlobs.
+func getRecordWithBlobs(ctx context.Context,
+	getRecord func(context.Context) (*record.Record, error),
+	getBlobs func(context.Context) ([]*blobref.BlobRef, error)) (*record.Record, []*blobref.BlobRef, error) {
+	var blobs []*blobref.BlobRef
+	var blobErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		blobs, blobErr = getBlobs(ctx)
+	}()
+	r, err := getRecord(ctx)
+	<-done
+	if err != nil {
+		return nil, nil, err
+	}
+	if blobErr != nil {
+		return r, nil, blobErr
+	}
+	return r, blobs, nil
+}
+
+// getRecordBlobRefs returns all BlobRefs of the record.
+func (m *MetaDB) getRecordBlobRefs(ctx context.Context, storeKey, recordKey string) ([]*blobref.BlobRef, error) {
+	query := m.newQuery(blobKind).Filter("StoreKey = ", storeKey).Filter("RecordKey = ", recordKey)
+	var blobs []*blobref.BlobRef
+	if _, err := m.client.GetAll(ctx, query, &blobs); err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return blobs, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/stretchr/testify/assert"
+)
+
+// fetchBarrier makes each of n fetches wait until all of them have started,
+// which only succeeds if they run concurrently.
+type fetchBarrier struct {
+	started chan struct{}
+	n       int
+}
+
+func newFetchBarrier(n int) *fetchBarrier {
+	return &fetchBarrier{started: make(chan struct{}, n), n: n}
+}
+
+func (b *fetchBarrier) wait(t *testing.T) {
+	b.started <- struct{}{}
+	deadline := time.After(time.Second)
+	for len(b.started) < b.n {
+		select {
+		case <-deadline:
+			t.Error("fetches didn't run concurrently")
+			return
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestGetRecordWithBlobs_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	barrier := newFetchBarrier(2)
+	wantRecord := &record.Record{Key: "record"}
+	wantBlobs := []*blobref.BlobRef{{RecordKey: "record"}}
+
+	r, blobs, err := getRecordWithBlobs(ctx,
+		func(context.Context) (*record.Record, error) {
+			barrier.wait(t)
+			return wantRecord, nil
+		},
+		func(context.Context) ([]*blobref.BlobRef, error) {
+			barrier.wait(t)
+			return wantBlobs, nil
+		})
+	assert.NoError(t, err)
+	assert.Same(t, wantRecord, r)
+	assert.Equal(t, wantBlobs, blobs)
+}
+
+func TestGetRecordWithBlobs_PartialFailure(t *testing.T) {
+	ctx := context.Background()
+	wantRecord := &record.Record{Key: "record"}
+	errRecord := errors.New("record failed")
+	errBlobs := errors.New("blobs failed")
+	getRecord := func(context.Context) (*record.Record, error) { return wantRecord, nil }
+	failRecord := func(context.Context) (*record.Record, error) { return nil, errRecord }
+	getBlobs := func(context.Context) ([]*blobref.BlobRef, error) { return []*blobref.BlobRef{{}}, nil }
+	failBlobs := func(context.Context) ([]*blobref.BlobRef, error) { return nil, errBlobs }
+
+	// The record is returned even if the blob query fails.
+	r, blobs, err := getRecordWithBlobs(ctx, getRecord, failBlobs)
+	assert.ErrorIs(t, err, errBlobs)
+	assert.Same(t, wantRecord, r)
+	assert.Nil(t, blobs)
+
+	r, blobs, err = getRecordWithBlobs(ctx, failRecord, getBlobs)
+	assert.ErrorIs(t, err, errRecord)
+	assert.Nil(t, r)
+	assert.Nil(t, blobs)
+
+	// The record error takes precedence.
+	_, _, err = getRecordWithBlobs(ctx, failRecord, failBlobs)
+	assert.ErrorIs(t, err, errRecord)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_GetRecordWithBlobs(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r, blob := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	other := blobref.NewBlobRef(0, st.Key, r.Key)
+	other.Status = blobref.StatusPendingDeletion
+	setupTestBlobRef(ctx, t, metaDB, other)
+
+	got, blobs, err := metaDB.GetRecordWithBlobs(ctx, st.Key, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, r.Key, got.Key)
+	keys := make([]uuid.UUID, 0, len(blobs))
+	for _, b := range blobs {
+		keys = append(keys, b.Key)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{blob.Key, other.Key}, keys)
+}
+
+func TestMetaDB_GetRecordWithBlobsNotFound(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	got, blobs, err := metaDB.GetRecordWithBlobs(ctx, newStoreKey(), newRecordKey())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Nil(t, got)
+	assert.Nil(t, blobs)
+}
@@ -0,0 +1,200 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecordExpiredReason is the reason of the ErrorInfo detail of ErrRecordExpired.
+const RecordExpiredReason = "RECORD_EXPIRED"
+
+// ErrRecordExpired is returned by the reads and updates of a record that has
+// expired but hasn't been deleted by DeleteExpiredRecords yet. The code is
+// NotFound so that existing clients treat the record as missing, and the
+// status has an ErrorInfo detail with RecordExpiredReason to tell it apart
+// from a record that doesn't exist. Use IsRecordExpired to check for it.
+var ErrRecordExpired = newRecordExpiredError()
+
+func newRecordExpiredError() error {
+	st, err := status.New(codes.NotFound, "the record has expired").
+		WithDetails(&errdetails.ErrorInfo{Reason: RecordExpiredReason, Domain: "opensaves"})
+	if err != nil {
+		panic(err)
+	}
+	return st.Err()
+}
+
+// IsRecordExpired returns true if err is a status with the ErrorInfo detail of
+// ErrRecordExpired, e.g. received by a client of the service.
+func IsRecordExpired(err error) bool {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetReason() == RecordExpiredReason {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteExpiredRecords deletes up to limit records of all stores that have
+// expired at now, and returns the number of deleted records. All BlobRefs of
+// the deleted records are marked for deletion so that the garbage collector
+// deletes their objects. The idempotency tokens and the sharded counters of
+// the deleted records are deleted too. limit <= 0 means no limit.
+func (m *MetaDB) DeleteExpiredRecords(ctx context.Context, now time.Time, limit int) (int, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteExpiredRecords")
+	defer span.End()
+
+	query := m.newQuery(recordKind).KeysOnly().Filter("ExpiresAt <=", now)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	deleted := 0
+	for _, key := range keys {
+		ok, err := m.deleteExpiredRecord(ctx, key, now)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// expiredRecordBlobBatchSize is the number of BlobRefs of an expired record
+// that are marked for deletion in a transaction, which keeps the commit under
+// the limit of 500 entities per transaction.
+const expiredRecordBlobBatchSize = 400
+
+// deleteExpiredRecord deletes the record of rkey and marks its BlobRefs for
+// deletion if the record is still expired at now. It returns false if the
+// record was deleted or its expiry was extended after the query.
+// The first expiredRecordBlobBatchSize BlobRefs are marked in the transaction
+// that deletes the record, and the rest in subsequent transactions. If those
+// fail, the remaining BlobRefs are left to the garbage collector as dangling.
+func (m *MetaDB) deleteExpiredRecord(ctx context.Context, rkey *ds.Key, now time.Time) (bool, error) {
+	// BlobRefs are root entities and can't be queried in the transaction.
+	query := m.newQuery(blobKind).KeysOnly().
+		Filter("StoreKey = ", rkey.Parent.Name).Filter("RecordKey = ", rkey.Name)
+	blobKeys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return false, datastoreErrToGRPCStatus(err)
+	}
+	batch := blobKeys
+	if len(batch) > expiredRecordBlobBatchSize {
+		batch = batch[:expiredRecordBlobBatchSize]
+	}
+
+	deleted := false
+	_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		deleted = false
+		r := new(record.Record)
+		if err := tx.Get(rkey, r); err == ds.ErrNoSuchEntity {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !r.IsExpired(now) {
+			return nil
+		}
+		muts, err := m.blobRefDeletionMutations(tx, batch)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Mutate(append(muts, ds.NewDelete(rkey))...); err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		return false, datastoreErrToGRPCStatus(err)
+	}
+	if !deleted {
+		return false, nil
+	}
+	if err := m.deleteRecordTokens(ctx, rkey); err != nil {
+		return true, err
+	}
+	if err := m.deleteRecordCounters(ctx, rkey.Parent.Name, rkey.Name); err != nil {
+		return true, err
+	}
+
+	for rest := blobKeys[len(batch):]; len(rest) > 0; {
+		batch := rest
+		if len(batch) > expiredRecordBlobBatchSize {
+			batch = batch[:expiredRecordBlobBatchSize]
+		}
+		rest = rest[len(batch):]
+		_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+			muts, err := m.blobRefDeletionMutations(tx, batch)
+			if err != nil || len(muts) == 0 {
+				return err
+			}
+			_, err = tx.Mutate(muts...)
+			return err
+		})
+		if err != nil {
+			return true, datastoreErrToGRPCStatus(err)
+		}
+	}
+	return true, nil
+}
+
+// blobRefDeletionMutations reads the BlobRefs of blobKeys in tx and returns the
+// mutations that mark them for deletion. BlobRefs that were deleted, or are
+// already pending deletion or failed, are left to the garbage collector as they are.
+func (m *MetaDB) blobRefDeletionMutations(tx *ds.Transaction, blobKeys []*ds.Key) ([]*ds.Mutation, error) {
+	blobs := make([]*blobref.BlobRef, len(blobKeys))
+	for i := range blobs {
+		blobs[i] = new(blobref.BlobRef)
+	}
+	if err := tx.GetMulti(blobKeys, blobs); err != nil {
+		merr, ok := err.(ds.MultiError)
+		if !ok {
+			return nil, err
+		}
+		for i, e := range merr {
+			if e == ds.ErrNoSuchEntity {
+				blobs[i] = nil
+			} else if e != nil {
+				return nil, e
+			}
+		}
+	}
+	var muts []*ds.Mutation
+	for i, b := range blobs {
+		if b == nil || b.MarkForDeletion() != nil {
+			continue
+		}
+		muts = append(muts, ds.NewUpdate(blobKeys[i], b))
+	}
+	return muts, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_InsertRecordDefaultTTL(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB,
+		&store.Store{Key: newStoreKey(), Name: t.Name(), DefaultRecordTTL: time.Hour},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	assert.Equal(t, r.Timestamps.CreatedAt.Add(time.Hour), r.ExpiresAt)
+
+	// An explicit expiry overrides the default.
+	expiresAt := time.Now().Add(time.Minute).UTC().Truncate(time.Microsecond)
+	own := setupTestRecord(ctx, t, metaDB, st.Key,
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap), ExpiresAt: expiresAt})
+	got, err := metaDB.GetRecord(ctx, st.Key, own.Key)
+	require.NoError(t, err)
+	assert.True(t, expiresAt.Equal(got.ExpiresAt))
+}
+
+func TestMetaDB_GetExpiredRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap), ExpiresAt: time.Now().Add(-time.Minute)})
+
+	_, err := metaDB.GetRecord(ctx, st.Key, r.Key)
+	assert.ErrorIs(t, err, m.ErrRecordExpired)
+
+	_, err = metaDB.GetRecord(ctx, st.Key, newRecordKey())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotErrorIs(t, err, m.ErrRecordExpired)
+	assert.False(t, m.IsRecordExpired(err))
+}
+
+func TestIsRecordExpired(t *testing.T) {
+	assert.True(t, m.IsRecordExpired(m.ErrRecordExpired))
+	// The detail is kept when the status is sent to clients.
+	wire := status.ErrorProto(status.Convert(m.ErrRecordExpired).Proto())
+	assert.True(t, m.IsRecordExpired(wire))
+	assert.Equal(t, codes.NotFound, status.Code(wire))
+
+	assert.False(t, m.IsRecordExpired(status.Error(codes.NotFound, "the record has expired")))
+	assert.False(t, m.IsRecordExpired(nil))
+}
+
+func TestMetaDB_ReadExpiredRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, expired := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap), ExpiresAt: time.Now().Add(-time.Minute)})
+	live := setupTestRecord(ctx, t, metaDB, st.Key,
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+
+	for _, keysOnly := range []bool{false, true} {
+		records, err := metaDB.QueryRecords(ctx, &pb.QueryRecordsRequest{StoreKey: st.Key, KeysOnly: keysOnly})
+		require.NoError(t, err)
+		if assert.Len(t, records, 1, "keysOnly = %v", keysOnly) {
+			assert.Equal(t, live.Key, records[0].Key)
+		}
+	}
+
+	records, err := metaDB.GetRecords(ctx, []string{st.Key, st.Key}, []string{expired.Key, live.Key})
+	merr, ok := err.(ds.MultiError)
+	require.True(t, ok, "GetRecords returned %v", err)
+	assert.ErrorIs(t, merr[0], m.ErrRecordExpired)
+	assert.Nil(t, records[0])
+	assert.NoError(t, merr[1])
+	assert.Equal(t, live.Key, records[1].Key)
+
+	_, err = metaDB.GetRecordProjection(ctx, st.Key, expired.Key, "Tags")
+	assert.ErrorIs(t, err, m.ErrRecordExpired)
+	_, err = metaDB.GetRecordProjection(ctx, st.Key, live.Key, "Tags")
+	assert.NoError(t, err)
+
+	_, err = metaDB.UpdateRecord(ctx, st.Key, expired.Key,
+		func(r *record.Record) (*record.Record, error) { return r, nil })
+	assert.ErrorIs(t, err, m.ErrRecordExpired)
+}
+
+func TestMetaDB_DeleteExpiredRecordManyBlobs(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, expired := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap), ExpiresAt: time.Now().Add(-time.Minute)})
+
+	// More BlobRefs than fit in a single commit with the record.
+	blobs := make([]*blobref.BlobRef, 501)
+	for i := range blobs {
+		blobs[i] = blobref.NewBlobRef(0, st.Key, expired.Key)
+	}
+	for i := 0; i < len(blobs); i += 250 {
+		end := i + 250
+		if end > len(blobs) {
+			end = len(blobs)
+		}
+		require.NoError(t, metaDB.SaveBlobRefs(ctx, blobs[i:end]))
+	}
+	t.Cleanup(func() {
+		for _, b := range blobs {
+			metaDB.DeleteBlobRef(ctx, b.Key)
+		}
+	})
+
+	n, err := metaDB.DeleteExpiredRecords(ctx, time.Now(), 0)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+	for _, b := range []*blobref.BlobRef{blobs[0], blobs[len(blobs)-1]} {
+		got, err := metaDB.GetBlobRef(ctx, b.Key)
+		require.NoError(t, err)
+		assert.Equal(t, blobref.StatusPendingDeletion, got.Status)
+	}
+}
+
+func TestMetaDB_DeleteExpiredRecords(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, expired := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap), ExpiresAt: time.Now().Add(-time.Minute)})
+	live := setupTestRecord(ctx, t, metaDB, st.Key,
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap), ExpiresAt: time.Now().Add(time.Hour)})
+
+	ready := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(42, st.Key, expired.Key))
+	require.NoError(t, ready.Ready())
+	_, err := metaDB.UpdateBlobRef(ctx, ready)
+	require.NoError(t, err)
+	initializing := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, st.Key, expired.Key))
+	liveBlob := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(0, st.Key, live.Key))
+
+	n, err := metaDB.DeleteExpiredRecords(ctx, time.Now(), 0)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+
+	_, err = metaDB.GetRecord(ctx, st.Key, expired.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotErrorIs(t, err, m.ErrRecordExpired, "the record must be deleted")
+	_, err = metaDB.GetRecord(ctx, st.Key, live.Key)
+	assert.NoError(t, err)
+
+	// The blobs of the expired record are left to the garbage collector.
+	for _, b := range []*blobref.BlobRef{ready, initializing} {
+		got, err := metaDB.GetBlobRef(ctx, b.Key)
+		require.NoError(t, err)
+		assert.Equal(t, blobref.StatusPendingDeletion, got.Status)
+	}
+	got, err := metaDB.GetBlobRef(ctx, liveBlob.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusInitializing, got.Status)
+}
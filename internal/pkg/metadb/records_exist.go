@@ -0,0 +1,43 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+)
+
+// RecordsExist reports whether the records of keys exist in the store of
+// storeKey. The returned map has an entry for every key in keys. The records
+// are looked up with GetMulti without decoding them, in a single round trip
+// for up to 1,000 keys. Expired records that haven't been deleted yet are
+// reported as existing.
+func (m *MetaDB) RecordsExist(ctx context.Context, storeKey string, keys []string) (map[string]bool, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.RecordsExist")
+	defer span.End()
+
+	exists, err := m.recordsExist(ctx, storeKey, keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if !exists[k] {
+			exists[k] = false
+		}
+	}
+	return exists, nil
+}
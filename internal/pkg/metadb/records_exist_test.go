@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaDB_RecordsExist(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	other := setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	otherStore, inOtherStore := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name() + "-other"},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	missing := newRecordKey()
+
+	got, err := metaDB.RecordsExist(ctx, st.Key, []string{r.Key, missing, other.Key, inOtherStore.Key, r.Key})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		r.Key:            true,
+		other.Key:        true,
+		missing:          false,
+		inOtherStore.Key: false,
+	}, got)
+
+	got, err = metaDB.RecordsExist(ctx, otherStore.Key, []string{inOtherStore.Key})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{inOtherStore.Key: true}, got)
+}
+
+func TestMetaDB_RecordsExistEmpty(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	got, err := metaDB.RecordsExist(ctx, newStoreKey(), nil)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+	assert.Empty(t, got)
+}
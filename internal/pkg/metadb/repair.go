@@ -0,0 +1,135 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RepairChunkedBlob brings a chunked blob left inconsistent by a crash during
+// upload or commit back to a consistent state. It is idempotent and a no-op for
+// healthy blobs, so it is safe to retry.
+//
+// For each chunk number, the most recently updated Ready chunk whose object
+// exists in bs is kept. Other Ready chunks, i.e. leftovers replaced by a retried
+// upload, chunks beyond ChunkCount, and chunks whose objects are missing, are
+// marked for deletion and left for the garbage collector.
+// Then, if the blob is still Initializing because the commit didn't complete and
+// all ChunkCount chunks are present, the commit is completed: the BlobRef
+// becomes Ready and the current blob of the record, as in CommitChunkedUpload.
+// Returned errors:
+//   - NotFound: the BlobRef is not found
+//   - FailedPrecondition: the BlobRef is not chunked, is being deleted, or it is
+//     Initializing and some chunks are missing or ChunkCount is unknown
+//   - DataLoss: the BlobRef is Ready but some chunks are missing
+func (m *MetaDB) RepairChunkedBlob(ctx context.Context, bs blob.BlobStore, blobKey uuid.UUID) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.RepairChunkedBlob")
+	defer span.End()
+
+	var b *blobref.BlobRef
+	var complete bool
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		var err error
+		b, err = m.getBlobRef(ctx, tx, blobKey)
+		if err != nil {
+			return err
+		}
+		if !b.Chunked {
+			return status.Errorf(codes.FailedPrecondition, "BlobRef (%v) is not chunked", blobKey)
+		}
+		if b.Status != blobref.StatusInitializing && b.Status != blobref.StatusReady {
+			return status.Errorf(codes.FailedPrecondition, "BlobRef (%v) cannot be repaired in status (%v)", blobKey, b.Status)
+		}
+		complete, err = m.removeLeftoverChunks(ctx, tx, bs, b)
+		return err
+	})
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+
+	switch {
+	case complete && b.Status == blobref.StatusInitializing:
+		// Leftovers are committed by now so that the promotion sees only the kept chunks.
+		_, _, err := m.PromoteBlobRefToCurrent(ctx, b)
+		return err
+	case complete:
+		return nil
+	case b.Status == blobref.StatusReady:
+		return status.Errorf(codes.DataLoss, "chunks of BlobRef (%v) are missing", blobKey)
+	case b.ChunkCount == 0:
+		return status.Errorf(codes.FailedPrecondition, "BlobRef (%v) doesn't have ChunkCount and can't be checked for missing chunks", blobKey)
+	default:
+		return status.Errorf(codes.FailedPrecondition, "chunks of BlobRef (%v) are missing, upload them again", blobKey)
+	}
+}
+
+// removeLeftoverChunks marks Ready chunks of b other than the one to keep for each
+// number for deletion, and reports whether all chunks of b are present.
+func (m *MetaDB) removeLeftoverChunks(ctx context.Context, tx *ds.Transaction, bs blob.BlobStore, b *blobref.BlobRef) (bool, error) {
+	chunks, err := m.getReadyChunks(ctx, tx, b)
+	if err != nil {
+		return false, err
+	}
+	keep := make(map[int32]*chunkref.ChunkRef)
+	var leftovers []*chunkref.ChunkRef
+	for _, c := range chunks {
+		exists, err := blob.Exists(ctx, bs, c.ObjectPath())
+		if err != nil {
+			return false, err
+		}
+		if !exists || (b.ChunkCount > 0 && int64(c.Number) >= b.ChunkCount) {
+			leftovers = append(leftovers, c)
+			continue
+		}
+		if k, ok := keep[c.Number]; ok {
+			if k.Timestamps.UpdatedAt.After(c.Timestamps.UpdatedAt) {
+				leftovers = append(leftovers, c)
+				continue
+			}
+			leftovers = append(leftovers, k)
+		}
+		keep[c.Number] = c
+	}
+	for _, c := range leftovers {
+		if err := c.MarkForDeletion(); err != nil {
+			return false, err
+		}
+		c.Timestamps.Update()
+		if err := m.mutateSingleInTransaction(tx, ds.NewUpdate(m.createChunkRefKey(b.Key, c.Key), c)); err != nil {
+			return false, err
+		}
+	}
+
+	if b.ChunkCount == 0 {
+		// Commits set ChunkCount, so only Ready blobs without chunks end up here.
+		return b.Status == blobref.StatusReady, nil
+	}
+	for i := int64(0); i < b.ChunkCount; i++ {
+		if _, ok := keep[int32(i)]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
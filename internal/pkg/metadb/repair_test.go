@@ -0,0 +1,194 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref/chunkref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const repairChunkSize = 4
+
+// setupRepairBlob creates an Initializing chunked blob that expects chunkCount chunks.
+func setupRepairBlob(ctx context.Context, t *testing.T, metaDB *m.MetaDB, chunkCount int64) (*record.Record, *blobref.BlobRef) {
+	t.Helper()
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	return r, setupTestBlobRef(ctx, t, metaDB, blobref.NewChunkedBlobRef(st.Key, r.Key, chunkCount))
+}
+
+// uploadRepairChunk inserts a Ready chunk of b and writes its object to bs.
+func uploadRepairChunk(ctx context.Context, t *testing.T, metaDB *m.MetaDB, bs blob.BlobStore, b *blobref.BlobRef, number int32) *chunkref.ChunkRef {
+	t.Helper()
+	c := chunkref.New(b.Key, number)
+	c.Size = repairChunkSize
+	require.NoError(t, c.Ready())
+	require.NoError(t, bs.Put(ctx, c.ObjectPath(), make([]byte, repairChunkSize)))
+	setupTestChunkRef(ctx, t, metaDB, b, c)
+	return c
+}
+
+// chunkStatuses returns the statuses of chunks of b by key.
+func chunkStatuses(ctx context.Context, t *testing.T, metaDB *m.MetaDB, b *blobref.BlobRef) map[uuid.UUID]blobref.Status {
+	t.Helper()
+	statuses := make(map[uuid.UUID]blobref.Status)
+	cur := metaDB.GetChildChunkRefs(ctx, b.Key)
+	for {
+		c, err := cur.Next()
+		if err == iterator.Done {
+			break
+		}
+		require.NoError(t, err)
+		statuses[c.Key] = c.Status
+	}
+	return statuses
+}
+
+func TestMetaDB_RepairChunkedBlobLeftoverChunks(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	ds := newDatastoreClient(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	r, b := setupRepairBlob(ctx, t, metaDB, 2)
+	c0 := uploadRepairChunk(ctx, t, metaDB, bs, b, 0)
+	c1 := uploadRepairChunk(ctx, t, metaDB, bs, b, 1)
+	_, b, err := metaDB.PromoteBlobRefToCurrent(ctx, b)
+	require.NoError(t, err)
+
+	// A chunk beyond ChunkCount and an older duplicate of chunk 0 left by an interrupted retry.
+	extra := uploadRepairChunk(ctx, t, metaDB, bs, b, 2)
+	dup := chunkref.New(b.Key, 0)
+	dup.Size = repairChunkSize
+	require.NoError(t, dup.Ready())
+	dup.Timestamps.UpdatedAt = c0.Timestamps.UpdatedAt.Add(-time.Hour)
+	require.NoError(t, bs.Put(ctx, dup.ObjectPath(), make([]byte, repairChunkSize)))
+	_, err = ds.Put(ctx, chunkRefKey(b.Key, dup.Key), dup)
+	require.NoError(t, err)
+
+	require.NoError(t, metaDB.RepairChunkedBlob(ctx, bs, b.Key))
+	assert.Equal(t, map[uuid.UUID]blobref.Status{
+		c0.Key:    blobref.StatusReady,
+		c1.Key:    blobref.StatusReady,
+		extra.Key: blobref.StatusPendingDeletion,
+		dup.Key:   blobref.StatusPendingDeletion,
+	}, chunkStatuses(ctx, t, metaDB, b))
+
+	got, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusReady, got.Status)
+	gotRecord, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, b.Key, gotRecord.ExternalBlob)
+}
+
+func TestMetaDB_RepairChunkedBlobIncompleteCommit(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	r, b := setupRepairBlob(ctx, t, metaDB, 2)
+	// All chunks were uploaded but the commit didn't complete.
+	c0 := uploadRepairChunk(ctx, t, metaDB, bs, b, 0)
+	c1 := uploadRepairChunk(ctx, t, metaDB, bs, b, 1)
+	extra := uploadRepairChunk(ctx, t, metaDB, bs, b, 2)
+
+	require.NoError(t, metaDB.RepairChunkedBlob(ctx, bs, b.Key))
+	got, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusReady, got.Status)
+	assert.Equal(t, int64(2*repairChunkSize), got.Size)
+	assert.Equal(t, map[uuid.UUID]blobref.Status{
+		c0.Key:    blobref.StatusReady,
+		c1.Key:    blobref.StatusReady,
+		extra.Key: blobref.StatusPendingDeletion,
+	}, chunkStatuses(ctx, t, metaDB, b))
+
+	gotRecord, err := metaDB.GetRecord(ctx, r.StoreKey, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, b.Key, gotRecord.ExternalBlob)
+	assert.Equal(t, int64(2*repairChunkSize), gotRecord.BlobSize)
+	assert.True(t, gotRecord.Chunked)
+
+	// Repairing again is a no-op.
+	require.NoError(t, metaDB.RepairChunkedBlob(ctx, bs, b.Key))
+	again, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.Equal(t, got.Timestamps, again.Timestamps)
+}
+
+func TestMetaDB_RepairChunkedBlobHealthy(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	_, b := setupRepairBlob(ctx, t, metaDB, 2)
+	c0 := uploadRepairChunk(ctx, t, metaDB, bs, b, 0)
+	c1 := uploadRepairChunk(ctx, t, metaDB, bs, b, 1)
+	_, _, err := metaDB.PromoteBlobRefToCurrent(ctx, b)
+	require.NoError(t, err)
+	before, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+
+	require.NoError(t, metaDB.RepairChunkedBlob(ctx, bs, b.Key))
+	after, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.Equal(t, before.Timestamps, after.Timestamps)
+	assert.Equal(t, map[uuid.UUID]blobref.Status{
+		c0.Key: blobref.StatusReady,
+		c1.Key: blobref.StatusReady,
+	}, chunkStatuses(ctx, t, metaDB, b))
+}
+
+func TestMetaDB_RepairChunkedBlobMissingChunks(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	_, b := setupRepairBlob(ctx, t, metaDB, 2)
+	uploadRepairChunk(ctx, t, metaDB, bs, b, 0)
+	// The object of chunk 1 is gone.
+	c1 := uploadRepairChunk(ctx, t, metaDB, bs, b, 1)
+	require.NoError(t, bs.Delete(ctx, c1.ObjectPath()))
+
+	err := metaDB.RepairChunkedBlob(ctx, bs, b.Key)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	got, err := metaDB.GetBlobRef(ctx, b.Key)
+	require.NoError(t, err)
+	assert.Equal(t, blobref.StatusInitializing, got.Status)
+	assert.Equal(t, blobref.StatusPendingDeletion, chunkStatuses(ctx, t, metaDB, b)[c1.Key])
+}
+
+func TestMetaDB_RepairChunkedBlobNotChunked(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	_, _, b := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+
+	err := metaDB.RepairChunkedBlob(ctx, bs, b.Key)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	err = metaDB.RepairChunkedBlob(ctx, bs, uuid.New())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/blob"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReplaceBlob replaces the external blob of the record with content in two phases.
+// First, newBlob is inserted as an Initializing BlobRef and content is uploaded to bs.
+// Then the record is switched to newBlob and the previous blob is marked for
+// deletion in a single transaction, so the record keeps pointing to the previous
+// blob if anything fails. The uncommitted newBlob is marked for deletion on failure.
+// newBlob must be a non-chunked BlobRef; its StoreKey, RecordKey, Size, checksums,
+// and content hash are set from the arguments.
+// Returns the previous BlobRef, which is nil if the record didn't have an external blob.
+func (m *MetaDB) ReplaceBlob(ctx context.Context, bs blob.BlobStore, storeKey, recordKey string,
+	newBlob *blobref.BlobRef, content []byte) (*blobref.BlobRef, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ReplaceBlob")
+	defer span.End()
+
+	if newBlob.Chunked {
+		return nil, status.Error(codes.InvalidArgument, "ReplaceBlob doesn't support chunked blobs")
+	}
+	newBlob.StoreKey = storeKey
+	newBlob.RecordKey = recordKey
+	newBlob.Size = int64(len(content))
+	digest := checksums.NewDigestWithContentHash(m.ContentHashAlgorithm)
+	digest.Write(content)
+	newBlob.SetChecksums(digest)
+
+	if _, err := m.InsertBlobRef(ctx, newBlob); err != nil {
+		return nil, err
+	}
+	if err := bs.Put(ctx, newBlob.ObjectPath(), content); err != nil {
+		m.abortReplaceBlob(ctx, newBlob)
+		return nil, err
+	}
+
+	var oldBlob *blobref.BlobRef
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		var err error
+		_, oldBlob, err = m.promoteBlobRefInTransaction(ctx, tx, newBlob)
+		return err
+	})
+	if err != nil {
+		m.abortReplaceBlob(ctx, newBlob)
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return oldBlob, nil
+}
+
+// abortReplaceBlob marks the uncommitted blob for deletion so that the garbage
+// collector deletes the uploaded object.
+func (m *MetaDB) abortReplaceBlob(ctx context.Context, blob *blobref.BlobRef) {
+	if err := m.MarkUncommittedBlobForDeletion(ctx, blob.Key); err != nil {
+		log.Errorf("ReplaceBlob: failed to mark the uncommitted blob (%v) for deletion: %v", blob.Key, err)
+		blob.Fail()
+		return
+	}
+	blob.Status = blobref.StatusPendingDeletion
+}
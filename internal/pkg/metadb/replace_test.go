@@ -0,0 +1,134 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setupCurrentBlob creates a store, a record, and a Ready blob that is current for the record.
+func setupCurrentBlob(ctx context.Context, t *testing.T, metaDB *m.MetaDB) (string, string, *blobref.BlobRef) {
+	t.Helper()
+	st, r, b := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	_, b, err := metaDB.PromoteBlobRefToCurrent(ctx, b)
+	require.NoError(t, err)
+	return st.Key, r.Key, b
+}
+
+func TestMetaDB_ReplaceBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	storeKey, recordKey, oldBlob := setupCurrentBlob(ctx, t, metaDB)
+
+	content := []byte("new save data")
+	newBlob := blobref.NewBlobRef(0, "", "")
+	t.Cleanup(func() { metaDB.DeleteBlobRef(ctx, newBlob.Key) })
+	old, err := metaDB.ReplaceBlob(ctx, bs, storeKey, recordKey, newBlob, content)
+	require.NoError(t, err)
+	if assert.NotNil(t, old) {
+		assert.Equal(t, oldBlob.Key, old.Key)
+		assert.Equal(t, blobref.StatusPendingDeletion, old.Status)
+	}
+
+	r, err := metaDB.GetRecord(ctx, storeKey, recordKey)
+	require.NoError(t, err)
+	assert.Equal(t, newBlob.Key, r.ExternalBlob)
+	assert.Equal(t, int64(len(content)), r.BlobSize)
+
+	got, err := metaDB.GetBlobRef(ctx, newBlob.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blobref.StatusReady, got.Status)
+		assert.Equal(t, int64(len(content)), got.Size)
+		assert.NotEmpty(t, got.MD5)
+	}
+	got, err = metaDB.GetBlobRef(ctx, oldBlob.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blobref.StatusPendingDeletion, got.Status)
+	}
+
+	data, err := bs.Get(ctx, newBlob.ObjectPath())
+	assert.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestMetaDB_ReplaceBlobWithoutPreviousBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	st, r, _ := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+
+	newBlob := blobref.NewBlobRef(0, "", "")
+	t.Cleanup(func() { metaDB.DeleteBlobRef(ctx, newBlob.Key) })
+	old, err := metaDB.ReplaceBlob(ctx, bs, st.Key, r.Key, newBlob, []byte("data"))
+	assert.NoError(t, err)
+	assert.Nil(t, old)
+
+	got, err := metaDB.GetRecord(ctx, st.Key, r.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, newBlob.Key, got.ExternalBlob)
+	}
+}
+
+func TestMetaDB_ReplaceBlobSwapFailurePreservesOldBlob(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+	storeKey, recordKey, oldBlob := setupCurrentBlob(ctx, t, metaDB)
+
+	// The old blob can't be marked for deletion from StatusError, which makes
+	// the swap transaction fail after the new blob is uploaded.
+	oldBlob.Fail()
+	_, err := metaDB.UpdateBlobRef(ctx, oldBlob)
+	require.NoError(t, err)
+
+	newBlob := blobref.NewBlobRef(0, "", "")
+	t.Cleanup(func() { metaDB.DeleteBlobRef(ctx, newBlob.Key) })
+	old, err := metaDB.ReplaceBlob(ctx, bs, storeKey, recordKey, newBlob, []byte("new save data"))
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Nil(t, old)
+
+	r, err := metaDB.GetRecord(ctx, storeKey, recordKey)
+	require.NoError(t, err)
+	assert.Equal(t, oldBlob.Key, r.ExternalBlob)
+
+	got, err := metaDB.GetBlobRef(ctx, oldBlob.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blobref.StatusError, got.Status)
+	}
+	// The uncommitted blob is left for the garbage collector.
+	got, err = metaDB.GetBlobRef(ctx, newBlob.Key)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blobref.StatusPendingDeletion, got.Status)
+	}
+}
+
+func TestMetaDB_ReplaceBlobNonExistentRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	bs := newMemBlobStore(ctx, t)
+
+	newBlob := blobref.NewBlobRef(0, "", "")
+	_, err := metaDB.ReplaceBlob(ctx, bs, newStoreKey(), newRecordKey(), newBlob, []byte("data"))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
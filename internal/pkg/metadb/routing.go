@@ -0,0 +1,99 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"errors"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+)
+
+// OperationClass is the class of a MetadataStore operation used for routing.
+type OperationClass int
+
+const (
+	// OperationWrite is for writes and reads that need strong consistency.
+	OperationWrite OperationClass = iota
+	// OperationRead is for queries that tolerate stale results.
+	OperationRead
+)
+
+// ClassifyOperation returns the class of the MetaDB or MetadataStore method
+// named method. Only queries are OperationRead. Key-based reads such as
+// GetRecord are OperationWrite because callers use them in read-modify-write
+// flows, which must not see stale data.
+func ClassifyOperation(method string) OperationClass {
+	switch method {
+	case "QueryBlobRefs", "QueryRecords":
+		return OperationRead
+	default:
+		return OperationWrite
+	}
+}
+
+// RoutedMetadataStore is a MetadataStore that sends OperationRead operations
+// to a reader, e.g. a MetaDB for a read replica endpoint or one with
+// QueryConsistency set to ConsistencyEventual, and everything else to the primary.
+type RoutedMetadataStore struct {
+	// MetadataStore is the primary store.
+	MetadataStore
+	reader MetadataStore
+}
+
+// Assert RoutedMetadataStore implements the MetadataStore interface.
+var _ MetadataStore = new(RoutedMetadataStore)
+
+// NewRoutedMetadataStore returns a RoutedMetadataStore that routes operations
+// to primary and reader. All operations go to primary if reader is nil.
+func NewRoutedMetadataStore(primary, reader MetadataStore) *RoutedMetadataStore {
+	if reader == nil {
+		reader = primary
+	}
+	return &RoutedMetadataStore{MetadataStore: primary, reader: reader}
+}
+
+// route returns the store for operations of class.
+func (r *RoutedMetadataStore) route(class OperationClass) MetadataStore {
+	if class == OperationRead {
+		return r.reader
+	}
+	return r.MetadataStore
+}
+
+// readQuery applies QueryConsistency to q, the query of the MetaDB method named
+// method, if method is OperationRead. Queries of other methods stay strongly
+// consistent.
+func (m *MetaDB) readQuery(method string, q *ds.Query) *ds.Query {
+	if ClassifyOperation(method) != OperationRead {
+		return q
+	}
+	return m.QueryConsistency.apply(q)
+}
+
+// QueryBlobRefs runs the query on the reader.
+func (r *RoutedMetadataStore) QueryBlobRefs(ctx context.Context, filter BlobRefFilter) ([]*blobref.BlobRef, error) {
+	return r.route(ClassifyOperation("QueryBlobRefs")).QueryBlobRefs(ctx, filter)
+}
+
+// Disconnect disconnects both the primary and the reader.
+func (r *RoutedMetadataStore) Disconnect(ctx context.Context) error {
+	err := r.MetadataStore.Disconnect(ctx)
+	if r.reader != r.MetadataStore {
+		err = errors.Join(err, r.reader.Disconnect(ctx))
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaDB_QueryConsistencyEventual(t *testing.T) {
+	m := &MetaDB{QueryConsistency: ConsistencyEventual}
+	filter := BlobRefFilter{StoreKey: "store", Limit: 10}
+
+	got, err := m.blobRefsQuery(filter)
+	require.NoError(t, err)
+	want, err := m.blobRefFilterQuery(filter)
+	require.NoError(t, err)
+	assert.Equal(t, want.Limit(10).EventualConsistency(), got, "QueryBlobRefs must run an eventually consistent query")
+
+	q := ds.NewQuery(recordKind)
+	assert.Equal(t, q.EventualConsistency(), m.readQuery("QueryRecords", q))
+	assert.Equal(t, q, m.readQuery("GetRecord", q), "key-based reads must stay strongly consistent")
+
+	m.QueryConsistency = ConsistencyStrong
+	got, err = m.blobRefsQuery(filter)
+	require.NoError(t, err)
+	assert.Equal(t, want.Limit(10), got)
+}
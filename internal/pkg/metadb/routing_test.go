@@ -0,0 +1,95 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyOperation(t *testing.T) {
+	assert.Equal(t, m.OperationRead, m.ClassifyOperation("QueryBlobRefs"))
+	for _, method := range []string{"GetRecord", "GetBlobRef", "GetStore", "UpdateRecord", "InsertBlobRef"} {
+		assert.Equal(t, m.OperationWrite, m.ClassifyOperation(method), method)
+	}
+}
+
+func TestRoutedMetadataStore_Contract(t *testing.T) {
+	testMetadataStoreContract(context.Background(), t, m.NewRoutedMetadataStore(newFakeMetadataStore(), nil))
+}
+
+func TestRoutedMetadataStore_Routing(t *testing.T) {
+	ctx := context.Background()
+	primary := newFakeMetadataStore()
+	// The reader is a replica that hasn't caught up with the primary.
+	reader := newFakeMetadataStore()
+	routed := m.NewRoutedMetadataStore(primary, reader)
+
+	st, err := routed.CreateStore(ctx, &store.Store{Key: "store"})
+	require.NoError(t, err)
+	r, err := routed.InsertRecord(ctx, st.Key, &record.Record{Key: "record"})
+	require.NoError(t, err)
+	b, err := routed.InsertBlobRef(ctx, blobref.NewBlobRef(10, st.Key, r.Key))
+	require.NoError(t, err)
+
+	// Queries go to the reader.
+	blobs, err := routed.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: st.Key})
+	require.NoError(t, err)
+	assert.Empty(t, blobs)
+	reader.blobs[b.Key] = primary.blobs[b.Key]
+	blobs, err = routed.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: st.Key})
+	require.NoError(t, err)
+	assert.Len(t, blobs, 1)
+
+	// Key-based and transactional reads stay on the primary.
+	_, err = routed.GetBlobRef(ctx, b.Key)
+	assert.NoError(t, err)
+	updated, err := routed.UpdateRecord(ctx, st.Key, r.Key, func(r *record.Record) (*record.Record, error) {
+		r.OpaqueString = "updated"
+		return r, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "updated", updated.OpaqueString)
+	assert.Empty(t, reader.records)
+}
+
+func TestMetaDB_QueryConsistencyEventualTransactions(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	metaDB.QueryConsistency = m.ConsistencyEventual
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+
+	// Transactions are not affected by QueryConsistency. Datastore rejects
+	// eventually consistent queries in transactions.
+	_, err := metaDB.UpdateRecord(ctx, st.Key, r.Key, func(r *record.Record) (*record.Record, error) {
+		r.OpaqueString = "updated"
+		return r, nil
+	})
+	require.NoError(t, err)
+	got, err := metaDB.GetRecord(ctx, st.Key, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", got.OpaqueString)
+
+	_, err = metaDB.QueryBlobRefs(ctx, m.BlobRefFilter{StoreKey: st.Key})
+	assert.NoError(t, err)
+}
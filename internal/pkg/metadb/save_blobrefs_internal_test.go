@@ -0,0 +1,56 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/bulk"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/datastoretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSaveBlobRefs_FakeClient(t *testing.T) {
+	ctx := context.Background()
+	m := &MetaDB{entities: datastoretest.NewClient()}
+
+	first := blobref.NewBlobRef(1, "store", "record")
+	// Save fails as StoreKey and RecordKey are missing.
+	invalid := blobref.NewBlobRef(2, "", "")
+	last := blobref.NewBlobRef(3, "store", "record")
+
+	err := m.SaveBlobRefs(ctx, []*blobref.BlobRef{first, invalid, last})
+	var batchErr *bulk.BatchError
+	if assert.ErrorAs(t, err, &batchErr) && assert.Len(t, batchErr.Items, 1) {
+		assert.Equal(t, 1, batchErr.Items[0].Index)
+		assert.Equal(t, codes.InvalidArgument, status.Code(batchErr.Items[0].Err))
+	}
+	succeeded, failed := bulk.Partition(3, err)
+	assert.Equal(t, []int{0, 2}, succeeded)
+	assert.Equal(t, []int{1}, failed)
+
+	// The valid BlobRefs are saved although another one is invalid.
+	for _, b := range []*blobref.BlobRef{first, last} {
+		got := new(blobref.BlobRef)
+		require.NoError(t, m.entities.Get(ctx, m.createBlobKey(b.Key), got))
+		assert.Equal(t, b.Size, got.Size)
+	}
+	assert.Error(t, m.entities.Get(ctx, m.createBlobKey(invalid.Key), new(blobref.BlobRef)))
+}
@@ -0,0 +1,284 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	shardedCounterKind = "sharded_counter"
+	counterShardKind   = "counter_shard"
+
+	// MaxCounterShards is the maximum number of shards of a ShardedCounter.
+	MaxCounterShards = 1000
+
+	// counterShardDeleteBatchSize is the number of shards deleted at a time,
+	// which keeps each commit under the limit of 500 entities.
+	counterShardDeleteBatchSize = 400
+)
+
+// ShardedCounter is an integer property of a record that supports frequent
+// concurrent increments. Datastore limits writes to a single entity to about one
+// per second, so the value is split into Shards root entities, and each
+// increment updates one of them at random.
+// The number of shards is stored with the counter and only grows: increasing
+// Shards takes effect at the next increment, and decreasing it only spreads
+// increments across fewer shards, as reads sum all shards ever written.
+// The counters of a record are deleted with the record.
+type ShardedCounter struct {
+	// Property is the name of the counter. It is independent of the properties
+	// of the record.
+	Property string
+	// Shards is the number of shards to distribute increments across,
+	// between 1 and MaxCounterShards.
+	Shards int
+}
+
+// shardedCounter is the entity of a ShardedCounter that keeps the number of
+// its shards. It is a root entity like the shards, so that increments don't
+// contend with the writes of the record.
+type shardedCounter struct {
+	StoreKey  string
+	RecordKey string
+	Property  string `datastore:",noindex"`
+	Shards    int    `datastore:",noindex"`
+}
+
+// counterShard is a shard entity of a ShardedCounter.
+type counterShard struct {
+	Value int64 `datastore:",noindex"`
+}
+
+// shardedCounterName returns the key name of the counter property of the
+// record. The keys are quoted so that different keys never have the same name.
+func shardedCounterName(storeKey, recordKey, property string) string {
+	return fmt.Sprintf("%q/%q/%q", storeKey, recordKey, property)
+}
+
+func (m *MetaDB) createShardedCounterKey(storeKey, recordKey, property string) *ds.Key {
+	k := ds.NameKey(shardedCounterKind, shardedCounterName(storeKey, recordKey, property), nil)
+	k.Namespace = m.Namespace
+	return k
+}
+
+func (m *MetaDB) createCounterShardKey(storeKey, recordKey, property string, shard int) *ds.Key {
+	k := ds.NameKey(counterShardKind, fmt.Sprintf("%s#%d", shardedCounterName(storeKey, recordKey, property), shard), nil)
+	k.Namespace = m.Namespace
+	return k
+}
+
+// counterShardKeys returns the keys of the shards of the counter.
+func (m *MetaDB) counterShardKeys(c *shardedCounter) []*ds.Key {
+	keys := make([]*ds.Key, c.Shards)
+	for i := range keys {
+		keys[i] = m.createCounterShardKey(c.StoreKey, c.RecordKey, c.Property, i)
+	}
+	return keys
+}
+
+// IncrementShardedProperty adds delta to a random shard of counter.
+// A negative delta decrements the counter. The record is only read when the
+// counter is created or its number of shards grows.
+// Returned errors:
+//   - NotFound: the record is not found
+//   - InvalidArgument: the property name is empty or the number of shards is out of range
+//   - OutOfRange: the new value of the shard overflows int64
+func (m *MetaDB) IncrementShardedProperty(ctx context.Context, storeKey, recordKey string, counter ShardedCounter, delta int64) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.IncrementShardedProperty")
+	defer span.End()
+
+	if counter.Property == "" {
+		return status.Error(codes.InvalidArgument, "sharded counter property must not be empty")
+	}
+	if counter.Shards < 1 || counter.Shards > MaxCounterShards {
+		return status.Errorf(codes.InvalidArgument, "sharded counter must have 1 to %d shards, got %d",
+			MaxCounterShards, counter.Shards)
+	}
+	ckey := m.createShardedCounterKey(storeKey, recordKey, counter.Property)
+	key := m.createCounterShardKey(storeKey, recordKey, counter.Property, rand.Intn(counter.Shards))
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		c := new(shardedCounter)
+		err := tx.Get(ckey, c)
+		if err != nil && err != ds.ErrNoSuchEntity {
+			return err
+		}
+		if err == ds.ErrNoSuchEntity || c.Shards < counter.Shards {
+			if err := tx.Get(m.createRecordKey(storeKey, recordKey), new(record.Record)); err != nil {
+				return err
+			}
+			c = &shardedCounter{StoreKey: storeKey, RecordKey: recordKey, Property: counter.Property, Shards: counter.Shards}
+			if _, err := tx.Put(ckey, c); err != nil {
+				return err
+			}
+		}
+		var shard counterShard
+		if err := tx.Get(key, &shard); err != nil && err != ds.ErrNoSuchEntity {
+			return err
+		}
+		if (delta > 0 && shard.Value > math.MaxInt64-delta) || (delta < 0 && shard.Value < math.MinInt64-delta) {
+			return status.Errorf(codes.OutOfRange, "adding %v to sharded counter (%v) overflows", delta, counter.Property)
+		}
+		shard.Value += delta
+		_, err = tx.Put(key, &shard)
+		return err
+	})
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	return nil
+}
+
+// ReadShardedProperty returns the sum of all shards of the sharded counter
+// property of the record. It returns 0 if the counter has not been incremented.
+// Returns OutOfRange if the sum overflows int64.
+func (m *MetaDB) ReadShardedProperty(ctx context.Context, storeKey, recordKey, property string) (int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ReadShardedProperty")
+	defer span.End()
+
+	c := new(shardedCounter)
+	if err := m.entities.Get(ctx, m.createShardedCounterKey(storeKey, recordKey, property), c); err == ds.ErrNoSuchEntity {
+		return 0, nil
+	} else if err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	shards := make([]counterShard, c.Shards)
+	if err := m.entities.GetMulti(ctx, m.counterShardKeys(c), shards); err != nil {
+		merr, ok := err.(ds.MultiError)
+		if !ok {
+			return 0, datastoreErrToGRPCStatus(err)
+		}
+		for _, e := range merr {
+			// Shards that have not been incremented don't exist.
+			if e != nil && e != ds.ErrNoSuchEntity {
+				return 0, datastoreErrToGRPCStatus(e)
+			}
+		}
+	}
+	var sum int64
+	for _, shard := range shards {
+		if (shard.Value > 0 && sum > math.MaxInt64-shard.Value) || (shard.Value < 0 && sum < math.MinInt64-shard.Value) {
+			return 0, status.Errorf(codes.OutOfRange, "sharded counter (%v) overflows", property)
+		}
+		sum += shard.Value
+	}
+	return sum, nil
+}
+
+// DeleteShardedProperty deletes all shards of the sharded counter property of
+// the record. It is not an error if the counter doesn't exist.
+func (m *MetaDB) DeleteShardedProperty(ctx context.Context, storeKey, recordKey, property string) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteShardedProperty")
+	defer span.End()
+
+	ckey := m.createShardedCounterKey(storeKey, recordKey, property)
+	c := new(shardedCounter)
+	if err := m.entities.Get(ctx, ckey, c); err == ds.ErrNoSuchEntity {
+		return nil
+	} else if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	return m.deleteShardedCounter(ctx, ckey, c)
+}
+
+// deleteShardedCounter deletes the shards of c and then c, so that the shards
+// are found again if the deletion fails midway.
+func (m *MetaDB) deleteShardedCounter(ctx context.Context, ckey *ds.Key, c *shardedCounter) error {
+	keys := append(m.counterShardKeys(c), ckey)
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > counterShardDeleteBatchSize {
+			n = counterShardDeleteBatchSize
+		}
+		if err := m.entities.DeleteMulti(ctx, keys[:n]); err != nil {
+			return datastoreErrToGRPCStatus(err)
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+// deleteRecordCounters deletes the sharded counters of the record.
+func (m *MetaDB) deleteRecordCounters(ctx context.Context, storeKey, recordKey string) error {
+	query := m.newQuery(shardedCounterKind).
+		Filter("StoreKey =", storeKey).Filter("RecordKey =", recordKey)
+	var counters []*shardedCounter
+	keys, err := m.client.GetAll(ctx, query, &counters)
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	for i, c := range counters {
+		if err := m.deleteShardedCounter(ctx, keys[i], c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveRecordCounters moves the sharded counters of the record oldKey to the
+// record newKey, after RenameRecord. The counters of oldKey are deleted after
+// they are copied, so increments made to them in the meantime are lost.
+func (m *MetaDB) moveRecordCounters(ctx context.Context, storeKey, oldKey, newKey string) error {
+	query := m.newQuery(shardedCounterKind).
+		Filter("StoreKey =", storeKey).Filter("RecordKey =", oldKey)
+	var counters []*shardedCounter
+	keys, err := m.client.GetAll(ctx, query, &counters)
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	for i, c := range counters {
+		shards := make([]counterShard, c.Shards)
+		if err := m.entities.GetMulti(ctx, m.counterShardKeys(c), shards); err != nil {
+			merr, ok := err.(ds.MultiError)
+			if !ok {
+				return datastoreErrToGRPCStatus(err)
+			}
+			for _, e := range merr {
+				if e != nil && e != ds.ErrNoSuchEntity {
+					return datastoreErrToGRPCStatus(e)
+				}
+			}
+		}
+		moved := &shardedCounter{StoreKey: storeKey, RecordKey: newKey, Property: c.Property, Shards: c.Shards}
+		newKeys := m.counterShardKeys(moved)
+		for j := 0; j < len(shards); j += counterShardDeleteBatchSize {
+			end := j + counterShardDeleteBatchSize
+			if end > len(shards) {
+				end = len(shards)
+			}
+			if _, err := m.entities.PutMulti(ctx, newKeys[j:end], shards[j:end]); err != nil {
+				return datastoreErrToGRPCStatus(err)
+			}
+		}
+		ckey := m.createShardedCounterKey(storeKey, newKey, c.Property)
+		if _, err := m.entities.PutMulti(ctx, []*ds.Key{ckey}, []*shardedCounter{moved}); err != nil {
+			return datastoreErrToGRPCStatus(err)
+		}
+		if err := m.deleteShardedCounter(ctx, keys[i], c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"testing"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/datastoretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedCounter_ReadAndDeleteFakeClient(t *testing.T) {
+	ctx := context.Background()
+	fake := datastoretest.NewClient()
+	m := &MetaDB{entities: fake}
+
+	got, err := m.ReadShardedProperty(ctx, "store", "record", "views")
+	require.NoError(t, err)
+	assert.Zero(t, got)
+
+	// Shards are root entities that are found by key, and missing shards
+	// count as 0.
+	c := &shardedCounter{StoreKey: "store", RecordKey: "record", Property: "views", Shards: 3}
+	_, err = fake.Put(ctx, m.createShardedCounterKey("store", "record", "views"), c)
+	require.NoError(t, err)
+	keys := m.counterShardKeys(c)
+	for _, k := range keys {
+		assert.Nil(t, k.Parent)
+	}
+	_, err = fake.PutMulti(ctx, []*ds.Key{keys[0], keys[2]}, []counterShard{{Value: 5}, {Value: -2}})
+	require.NoError(t, err)
+	// Names are quoted, so a record key with separators is not another counter.
+	assert.NotEqual(t, shardedCounterName("store", "record/x", "views"), shardedCounterName("store", "record", "x/views"))
+
+	got, err = m.ReadShardedProperty(ctx, "store", "record", "views")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), got)
+
+	require.NoError(t, m.DeleteShardedProperty(ctx, "store", "record", "views"))
+	assert.Zero(t, fake.Len())
+	require.NoError(t, m.DeleteShardedProperty(ctx, "store", "record", "views"))
+}
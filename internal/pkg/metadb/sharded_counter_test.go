@@ -0,0 +1,145 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_ShardedCounterConcurrentIncrements(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+	counter := m.ShardedCounter{Property: "views", Shards: 10}
+	t.Cleanup(func() { metaDB.DeleteShardedProperty(ctx, r.StoreKey, r.Key, counter.Property) })
+
+	const (
+		workers    = 10
+		increments = 5
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				assert.NoError(t, metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, counter, 2))
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := metaDB.ReadShardedProperty(ctx, r.StoreKey, r.Key, counter.Property)
+	require.NoError(t, err)
+	assert.Equal(t, int64(workers*increments*2), got)
+
+	// The record properties are not affected.
+	assertIntProperty(ctx, t, metaDB, r, "score", 10)
+}
+
+func TestMetaDB_ShardedCounterReadAggregatesShards(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+	t.Cleanup(func() {
+		metaDB.DeleteShardedProperty(ctx, r.StoreKey, r.Key, "a")
+		metaDB.DeleteShardedProperty(ctx, r.StoreKey, r.Key, "b")
+	})
+
+	got, err := metaDB.ReadShardedProperty(ctx, r.StoreKey, r.Key, "a")
+	require.NoError(t, err)
+	assert.Zero(t, got)
+
+	// Changing the number of shards keeps the existing shards.
+	for _, shards := range []int{1, 100, 3} {
+		for i := 0; i < 10; i++ {
+			require.NoError(t, metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, m.ShardedCounter{Property: "a", Shards: shards}, 1))
+		}
+	}
+	require.NoError(t, metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, m.ShardedCounter{Property: "a", Shards: 3}, -5))
+	require.NoError(t, metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, m.ShardedCounter{Property: "b", Shards: 3}, 7))
+
+	got, err = metaDB.ReadShardedProperty(ctx, r.StoreKey, r.Key, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), got)
+	got, err = metaDB.ReadShardedProperty(ctx, r.StoreKey, r.Key, "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got)
+
+	require.NoError(t, metaDB.DeleteShardedProperty(ctx, r.StoreKey, r.Key, "a"))
+	got, err = metaDB.ReadShardedProperty(ctx, r.StoreKey, r.Key, "a")
+	require.NoError(t, err)
+	assert.Zero(t, got)
+}
+
+func TestMetaDB_ShardedCounterErrors(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+
+	err := metaDB.IncrementShardedProperty(ctx, r.StoreKey, newRecordKey(), m.ShardedCounter{Property: "a", Shards: 1}, 1)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	for _, c := range []m.ShardedCounter{
+		{Property: "", Shards: 1},
+		{Property: "a", Shards: 0},
+		{Property: "a", Shards: m.MaxCounterShards + 1},
+	} {
+		err := metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, c, 1)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), c)
+	}
+}
+
+func TestMetaDB_ShardedCounterDeletedWithRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+	counter := m.ShardedCounter{Property: "views", Shards: 5}
+	require.NoError(t, metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, counter, 3))
+
+	require.NoError(t, metaDB.DeleteRecord(ctx, r.StoreKey, r.Key))
+	got, err := metaDB.ReadShardedProperty(ctx, r.StoreKey, r.Key, counter.Property)
+	require.NoError(t, err)
+	assert.Zero(t, got)
+	err = metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, counter, 1)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMetaDB_ShardedCounterMovedWithRecord(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	r := setupIncrementRecord(ctx, t, metaDB)
+	counter := m.ShardedCounter{Property: "views", Shards: 5}
+	for i := 0; i < 4; i++ {
+		require.NoError(t, metaDB.IncrementShardedProperty(ctx, r.StoreKey, r.Key, counter, 2))
+	}
+
+	newKey := newRecordKey()
+	require.NoError(t, metaDB.RenameRecord(ctx, r.StoreKey, r.Key, newKey))
+	t.Cleanup(func() { metaDB.DeleteRecord(ctx, r.StoreKey, newKey) })
+	got, err := metaDB.ReadShardedProperty(ctx, r.StoreKey, newKey, counter.Property)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), got)
+	got, err = metaDB.ReadShardedProperty(ctx, r.StoreKey, r.Key, counter.Property)
+	require.NoError(t, err)
+	assert.Zero(t, got)
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTimestamps returns new Timestamps for a record or a BlobRef, signed by
+// the Signer of m if it is set.
+func (m *MetaDB) newTimestamps() (timestamps.Timestamps, error) {
+	t := timestamps.New()
+	return t, m.signTimestamps(&t)
+}
+
+// updateTimestamps updates t of a record or a BlobRef, and signs it with the
+// Signer of m if it is set.
+func (m *MetaDB) updateTimestamps(t *timestamps.Timestamps) error {
+	t.Update()
+	return m.signTimestamps(t)
+}
+
+func (m *MetaDB) signTimestamps(t *timestamps.Timestamps) error {
+	if m.Signer == nil {
+		return nil
+	}
+	if err := t.Sign(m.Signer); err != nil {
+		return status.Errorf(codes.Internal, "failed to sign the timestamps: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shortSigner returns signatures of the wrong size.
+type shortSigner struct{}
+
+func (shortSigner) Sign(payload []byte) []byte      { return []byte{1} }
+func (shortSigner) Verify(payload, sig []byte) bool { return false }
+
+func TestMetaDB_SignTimestamps(t *testing.T) {
+	signer := timestamps.NewHMACSigner([]byte("key"))
+	m := &MetaDB{Signer: signer}
+
+	ts, err := m.newTimestamps()
+	require.NoError(t, err)
+	assert.True(t, ts.VerifySignature(signer))
+
+	require.NoError(t, m.updateTimestamps(&ts))
+	assert.True(t, ts.VerifySignature(signer))
+	assert.False(t, ts.UpdatedAt.Before(ts.CreatedAt))
+
+	ts.UpdatedAt = ts.UpdatedAt.Add(timestamps.Precision)
+	assert.False(t, ts.VerifySignature(signer), "modified timestamps must be detected")
+
+	m.Signer = shortSigner{}
+	_, err = m.newTimestamps()
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, codes.Internal, status.Code(m.updateTimestamps(&ts)))
+}
+
+func TestMetaDB_SignTimestampsRandom(t *testing.T) {
+	m := new(MetaDB)
+	a, err := m.newTimestamps()
+	require.NoError(t, err)
+	b, err := m.newTimestamps()
+	require.NoError(t, err)
+	assert.NotEqual(t, a.Signature, b.Signature, "signatures are random without a Signer")
+}
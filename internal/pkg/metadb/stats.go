@@ -0,0 +1,234 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	dspb "google.golang.org/genproto/googleapis/datastore/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Stats is a set of store-level statistics returned by StoreStats.
+type Stats struct {
+	// RecordCount is the number of records in the store.
+	RecordCount int64
+	// BlobCount is the number of BlobRefs of the store in any status.
+	BlobCount int64
+	// BlobCountByStatus is the number of BlobRefs of the store by status.
+	// Statuses without BlobRefs are omitted.
+	BlobCountByStatus map[blobref.Status]int64
+	// TotalBlobBytes is the sum of Size of all BlobRefs of the store.
+	TotalBlobBytes int64
+	// ReadAt is the time when StoreStats started the read-only transaction
+	// that the statistics were read in.
+	ReadAt time.Time
+}
+
+// blobStatuses are the statuses counted by StoreStats.
+var blobStatuses = []blobref.Status{
+	blobref.StatusUnknown,
+	blobref.StatusInitializing,
+	blobref.StatusReady,
+	blobref.StatusPendingDeletion,
+	blobref.StatusError,
+}
+
+// blobSizeStatus is the projection of BlobRefs used by StoreStats.
+type blobSizeStatus struct {
+	Size   int64
+	Status int
+}
+
+// StoreStats returns the numbers of records and BlobRefs and the total bytes of
+// BlobRefs in the store. Counts are computed with aggregation queries where
+// possible, and with keys-only and projection queries otherwise, so entities
+// are never loaded entirely.
+//
+// All queries run in one read-only transaction, so the statistics are read from
+// a single consistent snapshot of the store taken shortly after ReadAt, and writes
+// committed after the snapshot are not included by any of them.
+// Returns NotFound if the store doesn't exist.
+func (m *MetaDB) StoreStats(ctx context.Context, storeKey string) (*Stats, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.StoreStats")
+	defer span.End()
+
+	if _, err := m.GetStore(ctx, storeKey); err != nil {
+		return nil, err
+	}
+	var stats *Stats
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		stats = &Stats{BlobCountByStatus: make(map[blobref.Status]int64), ReadAt: time.Now()}
+		if err := m.countStoreStats(ctx, tx, storeKey, stats); err != nil {
+			return err
+		}
+		return m.sumBlobBytes(ctx, tx, storeKey, stats)
+	}, ds.ReadOnly)
+	if status.Code(err) == codes.Unimplemented {
+		// Fall back to scanning keys and index entries in a new transaction.
+		_, err = m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+			stats = &Stats{BlobCountByStatus: make(map[blobref.Status]int64), ReadAt: time.Now()}
+			return m.scanStoreStats(ctx, tx, storeKey, stats)
+		}, ds.ReadOnly)
+	}
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return stats, nil
+}
+
+// storeRecordsQuery returns a query of all records in the store.
+func (m *MetaDB) storeRecordsQuery(storeKey string) *ds.Query {
+	return m.newQuery(recordKind).Ancestor(m.createStoreKey(storeKey))
+}
+
+// storeBlobsQuery returns a query of all BlobRefs of the store.
+func (m *MetaDB) storeBlobsQuery(storeKey string) *ds.Query {
+	return m.newQuery(blobKind).Filter("StoreKey =", storeKey)
+}
+
+// countStoreStats sets the counts of stats with aggregation queries.
+func (m *MetaDB) countStoreStats(ctx context.Context, tx *ds.Transaction, storeKey string, stats *Stats) error {
+	var err error
+	if stats.RecordCount, err = m.count(ctx, m.storeRecordsQuery(storeKey).Transaction(tx)); err != nil {
+		return err
+	}
+	for _, s := range blobStatuses {
+		n, err := m.count(ctx, m.storeBlobsQuery(storeKey).Filter("Status =", int(s)).Transaction(tx))
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			stats.BlobCountByStatus[s] = n
+			stats.BlobCount += n
+		}
+	}
+	return nil
+}
+
+// count returns the number of entities that match query using an aggregation query.
+func (m *MetaDB) count(ctx context.Context, query *ds.Query) (int64, error) {
+	const alias = "count"
+	res, err := m.client.RunAggregationQuery(ctx, query.NewAggregationQuery().WithCount(alias))
+	if err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	v, ok := res[alias].(*dspb.Value)
+	if !ok {
+		return 0, status.Errorf(codes.Internal, "unexpected aggregation result: %v", res[alias])
+	}
+	return v.GetIntegerValue(), nil
+}
+
+// sumBlobBytes adds the sizes of BlobRefs of the store to stats.
+func (m *MetaDB) sumBlobBytes(ctx context.Context, tx *ds.Transaction, storeKey string, stats *Stats) error {
+	var sizes []blobSizeStatus
+	if _, err := m.client.GetAll(ctx, m.storeBlobsQuery(storeKey).Project("Size").Transaction(tx), &sizes); err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	for _, s := range sizes {
+		stats.TotalBlobBytes += s.Size
+	}
+	return nil
+}
+
+// scanStoreStats sets all fields of stats by scanning record keys and the
+// size and status index entries of BlobRefs.
+func (m *MetaDB) scanStoreStats(ctx context.Context, tx *ds.Transaction, storeKey string, stats *Stats) error {
+	keys, err := m.client.GetAll(ctx, m.storeRecordsQuery(storeKey).KeysOnly().Transaction(tx), nil)
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	stats.RecordCount = int64(len(keys))
+
+	var blobs []blobSizeStatus
+	if _, err := m.client.GetAll(ctx, m.storeBlobsQuery(storeKey).Project("Size", "Status").Transaction(tx), &blobs); err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	for _, b := range blobs {
+		stats.BlobCount++
+		stats.BlobCountByStatus[blobref.Status(b.Status)]++
+		stats.TotalBlobBytes += b.Size
+	}
+	return nil
+}
+
+// UnknownContentType is the key of BlobUsageByContentType for the bytes of
+// BlobRefs without a content type.
+const UnknownContentType = "unknown"
+
+// blobSizeContentType is the projection of BlobRefs used by BlobUsageByContentType.
+type blobSizeContentType struct {
+	Size        int64
+	ContentType string
+}
+
+// BlobUsageByContentType returns the total bytes of BlobRefs of the store in
+// any status grouped by ContentType, with UnknownContentType for BlobRefs
+// without one. Content types without bytes are omitted.
+//
+// Only index entries are read with projection queries. Datastore omits
+// entities without an indexed ContentType from projections on it, which
+// include BlobRefs without a content type and those saved before ContentType
+// was indexed, so their bytes are computed as the difference from the total
+// of all BlobRefs. Like StoreStats, both queries run in one read-only transaction
+// so that the total and the typed bytes are read from the same snapshot.
+// Returns NotFound if the store doesn't exist.
+func (m *MetaDB) BlobUsageByContentType(ctx context.Context, storeKey string) (map[string]int64, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.BlobUsageByContentType")
+	defer span.End()
+
+	if _, err := m.GetStore(ctx, storeKey); err != nil {
+		return nil, err
+	}
+	var total Stats
+	var blobs []blobSizeContentType
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		total, blobs = Stats{}, nil
+		if err := m.sumBlobBytes(ctx, tx, storeKey, &total); err != nil {
+			return err
+		}
+		_, err := m.client.GetAll(ctx, m.storeBlobsQuery(storeKey).Project("Size", "ContentType").Transaction(tx), &blobs)
+		return err
+	}, ds.ReadOnly)
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	usage := make(map[string]int64)
+	var typed int64
+	for _, b := range blobs {
+		contentType := b.ContentType
+		if contentType == "" {
+			contentType = UnknownContentType
+		}
+		usage[contentType] += b.Size
+		typed += b.Size
+	}
+	if unknown := total.TotalBlobBytes - typed; unknown > 0 {
+		usage[UnknownContentType] += unknown
+	}
+	for contentType, n := range usage {
+		if n == 0 {
+			delete(usage, contentType)
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_StoreStats(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	for i := 0; i < 2; i++ {
+		setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	}
+
+	// One Ready, one PendingDeletion (replaced by the Ready one), and one Initializing.
+	old := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(100, st.Key, r.Key))
+	_, _, err := metaDB.PromoteBlobRefToCurrent(ctx, old)
+	require.NoError(t, err)
+	current := setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(20, st.Key, r.Key))
+	_, _, err = metaDB.PromoteBlobRefToCurrent(ctx, current)
+	require.NoError(t, err)
+	setupTestBlobRef(ctx, t, metaDB, blobref.NewBlobRef(3, st.Key, r.Key))
+
+	stats, err := metaDB.StoreStats(ctx, st.Key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.RecordCount)
+	assert.Equal(t, int64(3), stats.BlobCount)
+	assert.Equal(t, map[blobref.Status]int64{
+		blobref.StatusInitializing:    1,
+		blobref.StatusReady:           1,
+		blobref.StatusPendingDeletion: 1,
+	}, stats.BlobCountByStatus)
+	assert.Equal(t, int64(123), stats.TotalBlobBytes)
+	assert.False(t, stats.ReadAt.IsZero())
+}
+
+func TestMetaDB_StoreStatsEmptyStore(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+
+	stats, err := metaDB.StoreStats(ctx, st.Key)
+	require.NoError(t, err)
+	assert.Zero(t, stats.RecordCount)
+	assert.Zero(t, stats.BlobCount)
+	assert.Empty(t, stats.BlobCountByStatus)
+	assert.Zero(t, stats.TotalBlobBytes)
+}
+
+func TestMetaDB_StoreStatsNonExistentStore(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	_, err := metaDB.StoreStats(ctx, newStoreKey())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMetaDB_BlobUsageByContentType(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	for _, b := range []struct {
+		size        int64
+		contentType string
+	}{
+		{100, "image/png"},
+		{20, "image/png"},
+		{300, "application/json"},
+		{4, ""},
+		{5, ""},
+	} {
+		blob := blobref.NewBlobRef(b.size, st.Key, r.Key)
+		blob.ContentType = b.contentType
+		setupTestBlobRef(ctx, t, metaDB, blob)
+	}
+	// BlobRefs of other stores are not counted.
+	other, otherRecord := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	otherBlob := blobref.NewBlobRef(1000, other.Key, otherRecord.Key)
+	otherBlob.ContentType = "image/png"
+	setupTestBlobRef(ctx, t, metaDB, otherBlob)
+
+	usage, err := metaDB.BlobUsageByContentType(ctx, st.Key)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"image/png":          120,
+		"application/json":   300,
+		m.UnknownContentType: 9,
+	}, usage)
+}
+
+func TestMetaDB_BlobUsageByContentTypeEmptyStore(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+
+	usage, err := metaDB.BlobUsageByContentType(ctx, st.Key)
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+
+	_, err = metaDB.BlobUsageByContentType(ctx, newStoreKey())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+)
+
+// StorePolicy is the configuration of how the records of a store are saved.
+// It is a child entity of the store entity, and a store has at most one policy.
+type StorePolicy struct {
+	// StoreKey is the key of the store that the policy belongs to.
+	StoreKey string `datastore:"-"`
+	// IndexedProperties is the allowlist of the record properties that are
+	// indexed. Other properties are saved without indexes and can't be
+	// queried. An empty list indexes no property. A store without a policy
+	// indexes all properties.
+	IndexedProperties []string `datastore:",noindex"`
+
+	// Timestamps keeps track of creation and modification times and stores a randomly
+	// generated UUID to maintain consistency.
+	Timestamps timestamps.Timestamps
+}
+
+// Assert StorePolicy implements both PropertyLoadSave and KeyLoader.
+var _ datastore.PropertyLoadSaver = new(StorePolicy)
+var _ datastore.KeyLoader = new(StorePolicy)
+
+// Save implements the Datastore PropertyLoadSaver interface.
+func (p *StorePolicy) Save() ([]datastore.Property, error) {
+	return datastore.SaveStruct(p)
+}
+
+// Load implements the Datastore PropertyLoadSaver interface.
+func (p *StorePolicy) Load(ps []datastore.Property) error {
+	return datastore.LoadStruct(p, ps)
+}
+
+// LoadKey implements the KeyLoader interface and sets StoreKey to the key of
+// the parent store.
+func (p *StorePolicy) LoadKey(k *datastore.Key) error {
+	if k.Parent != nil {
+		p.StoreKey = k.Parent.Name
+	}
+	return nil
+}
@@ -15,11 +15,14 @@
 package store
 
 import (
-	"cloud.google.com/go/datastore"
 	"fmt"
+	"time"
+
+	"cloud.google.com/go/datastore"
 	pb "github.com/googleforgames/open-saves/api"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
 	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // Store represents a Open Saves store in the metadata database.
@@ -30,6 +33,14 @@ type Store struct {
 	Tags    []string
 	OwnerID string
 
+	// DefaultBlobTTL is the time to live applied to new external blobs in the
+	// store when the blob doesn't specify its own. Zero means blobs never expire.
+	DefaultBlobTTL time.Duration `datastore:",noindex"`
+
+	// DefaultRecordTTL is the time to live applied to new records in the store
+	// when the record doesn't specify its own. Zero means records never expire.
+	DefaultRecordTTL time.Duration `datastore:",noindex,omitempty"`
+
 	// Timestamps keeps track of creation and modification times and stores a randomly
 	// generated UUID to maintain consistency.
 	Timestamps timestamps.Timestamps
@@ -41,7 +52,7 @@ var _ datastore.KeyLoader = new(Store)
 
 // ToProto converts the structure a proto.
 func (s *Store) ToProto() *pb.Store {
-	return &pb.Store{
+	ret := &pb.Store{
 		Key:       s.Key,
 		Name:      s.Name,
 		Tags:      s.Tags,
@@ -49,6 +60,13 @@ func (s *Store) ToProto() *pb.Store {
 		CreatedAt: timestamps.TimeToProto(s.Timestamps.CreatedAt),
 		UpdatedAt: timestamps.TimeToProto(s.Timestamps.UpdatedAt),
 	}
+	if s.DefaultBlobTTL > 0 {
+		ret.DefaultBlobTtl = durationpb.New(s.DefaultBlobTTL)
+	}
+	if s.DefaultRecordTTL > 0 {
+		ret.DefaultRecordTtl = durationpb.New(s.DefaultRecordTTL)
+	}
+	return ret
 }
 
 // CacheKey returns a cache key string to manage cached entries.
@@ -114,6 +132,9 @@ func FromProto(p *pb.Store) *Store {
 		Name:    p.Name,
 		Tags:    p.Tags,
 		OwnerID: p.OwnerId,
+		// AsDuration returns zero for nil.
+		DefaultBlobTTL:   p.GetDefaultBlobTtl().AsDuration(),
+		DefaultRecordTTL: p.GetDefaultRecordTtl().AsDuration(),
 		Timestamps: timestamps.Timestamps{
 			CreatedAt: p.GetCreatedAt().AsTime(),
 			UpdatedAt: p.GetUpdatedAt().AsTime(),
@@ -23,6 +23,7 @@ import (
 	pb "github.com/googleforgames/open-saves/api"
 	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -82,6 +83,30 @@ func TestStore_NewStoreFromProtoSimple(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestStore_DefaultBlobTTLProto(t *testing.T) {
+	proto := &pb.Store{
+		Key:            "test",
+		DefaultBlobTtl: durationpb.New(90 * time.Minute),
+	}
+	store := FromProto(proto)
+	assert.Equal(t, 90*time.Minute, store.DefaultBlobTTL)
+	assert.Equal(t, proto.GetDefaultBlobTtl().AsDuration(), store.ToProto().GetDefaultBlobTtl().AsDuration())
+
+	// No default means blobs never expire.
+	assert.Nil(t, (&Store{Key: "test"}).ToProto().GetDefaultBlobTtl())
+}
+
+func TestStore_DefaultRecordTTLProto(t *testing.T) {
+	proto := &pb.Store{
+		Key:              "test",
+		DefaultRecordTtl: durationpb.New(2 * time.Hour),
+	}
+	store := FromProto(proto)
+	assert.Equal(t, 2*time.Hour, store.DefaultRecordTTL)
+	assert.Equal(t, 2*time.Hour, store.ToProto().GetDefaultRecordTtl().AsDuration())
+	assert.Nil(t, (&Store{Key: "test"}).ToProto().GetDefaultRecordTtl())
+}
+
 func TestStore_LoadKey(t *testing.T) {
 	store := new(Store)
 	key := datastore.NameKey("kind", "testkey", nil)
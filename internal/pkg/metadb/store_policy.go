@@ -0,0 +1,215 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	storePolicyKind = "storepolicy"
+	// storePolicyName is the key name of the only policy of a store.
+	storePolicyName = "policy"
+)
+
+func (m *MetaDB) createStorePolicyKey(storeKey string) *ds.Key {
+	k := ds.NameKey(storePolicyKind, storePolicyName, m.createStoreKey(storeKey))
+	k.Namespace = m.Namespace
+	return k
+}
+
+// DefaultStorePolicyTTL is the time that MetaDB caches the policy of a store
+// if StorePolicyTTL is not positive.
+const DefaultStorePolicyTTL = 30 * time.Second
+
+// storePolicyCacheKey identifies the policy of a store in a namespace.
+type storePolicyCacheKey struct {
+	namespace, storeKey string
+}
+
+type storePolicyCacheEntry struct {
+	// policy is nil if the store doesn't have a policy.
+	policy    *store.StorePolicy
+	fetchedAt time.Time
+}
+
+// storePolicyCache caches the store policies read from Datastore. The zero
+// value is an empty cache.
+type storePolicyCache struct {
+	mu      sync.Mutex
+	entries map[storePolicyCacheKey]storePolicyCacheEntry
+}
+
+func (c *storePolicyCache) get(key storePolicyCacheKey, ttl time.Duration, now time.Time) (*store.StorePolicy, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || now.Sub(e.fetchedAt) >= ttl {
+		return nil, false
+	}
+	return e.policy, true
+}
+
+func (c *storePolicyCache) set(key storePolicyCacheKey, p *store.StorePolicy, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[storePolicyCacheKey]storePolicyCacheEntry)
+	}
+	c.entries[key] = storePolicyCacheEntry{policy: p, fetchedAt: now}
+}
+
+func (c *storePolicyCache) invalidate(key storePolicyCacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+func (m *MetaDB) storePolicyCacheKey(storeKey string) storePolicyCacheKey {
+	return storePolicyCacheKey{namespace: m.Namespace, storeKey: storeKey}
+}
+
+// storePolicy returns the policy of the store, or nil if the store doesn't
+// have one. Policies are cached for StorePolicyTTL, so a policy set by another
+// MetaDB takes effect after the TTL at the latest.
+func (m *MetaDB) storePolicy(ctx context.Context, storeKey string) (*store.StorePolicy, error) {
+	ttl := m.StorePolicyTTL
+	if ttl <= 0 {
+		ttl = DefaultStorePolicyTTL
+	}
+	key := m.storePolicyCacheKey(storeKey)
+	if p, ok := m.storePolicies.get(key, ttl, time.Now()); ok {
+		return p, nil
+	}
+	p := new(store.StorePolicy)
+	switch err := m.entities.Get(ctx, m.createStorePolicyKey(storeKey), p); err {
+	case nil:
+	case ds.ErrNoSuchEntity:
+		p = nil
+	default:
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	m.storePolicies.set(key, p, time.Now())
+	return p, nil
+}
+
+// recordSaveOptions returns the options that the records of the store are
+// saved with. It returns an error instead of the default options if the policy
+// of the store can't be read, so that records are never saved with the wrong
+// indexes.
+func (m *MetaDB) recordSaveOptions(ctx context.Context, storeKey string) (record.SaveOptions, error) {
+	p, err := m.storePolicy(ctx, storeKey)
+	if err != nil {
+		return record.SaveOptions{}, err
+	}
+	o := record.SaveOptions{PropertyNameMode: m.PropertyNameMode, MaxEntitySize: m.MaxEntitySize}
+	if p != nil {
+		// A nil list, e.g. loaded from an empty array, still restricts indexing.
+		o.IndexPolicy = &record.IndexPolicy{Properties: append([]string{}, p.IndexedProperties...)}
+	}
+	return o, nil
+}
+
+// recordEntity recomputes the derived properties of r and returns r to be
+// saved to the store with the record save options of the store.
+func (m *MetaDB) recordEntity(ctx context.Context, storeKey string, r *record.Record) (ds.PropertyLoadSaver, error) {
+	o, err := m.recordSaveOptions(ctx, storeKey)
+	if err != nil {
+		return nil, err
+	}
+	m.deriveProperties(storeKey, r)
+	return r.WithSaveOptions(o), nil
+}
+
+// deriveProperties recomputes the derived properties of r in the store with
+// the DerivationFunc of the store. Returns true if they changed.
+func (m *MetaDB) deriveProperties(storeKey string, r *record.Record) bool {
+	return r.DeriveProperties(m.DerivationFuncs[storeKey])
+}
+
+// SetStorePolicy creates or replaces the policy of the store p.StoreKey. It
+// applies to the records saved by m from now on, and to the records saved by
+// other MetaDB instances when their cached policy expires (see StorePolicyTTL).
+// Records already saved keep their indexes until they are saved again, e.g.
+// with RewriteRecords.
+// Returns FailedPrecondition if the store doesn't exist.
+func (m *MetaDB) SetStorePolicy(ctx context.Context, p *store.StorePolicy) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.SetStorePolicy")
+	defer span.End()
+
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		if err := tx.Get(m.createStoreKey(p.StoreKey), new(store.Store)); err != nil {
+			if err == ds.ErrNoSuchEntity {
+				return status.Errorf(codes.FailedPrecondition, "SetStorePolicy was called with a non-existent store (%s)", p.StoreKey)
+			}
+			return err
+		}
+		key := m.createStorePolicyKey(p.StoreKey)
+		var current store.StorePolicy
+		switch err := tx.Get(key, &current); err {
+		case nil:
+			p.Timestamps = current.Timestamps
+			p.Timestamps.Update()
+		case ds.ErrNoSuchEntity:
+			p.Timestamps = timestamps.New()
+		default:
+			return err
+		}
+		return m.mutateSingleInTransaction(tx, ds.NewUpsert(key, p))
+	})
+	if err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	m.storePolicies.invalidate(m.storePolicyCacheKey(p.StoreKey))
+	return nil
+}
+
+// GetStorePolicy returns the policy of the store, or NotFound if the store
+// doesn't have one.
+func (m *MetaDB) GetStorePolicy(ctx context.Context, storeKey string) (*store.StorePolicy, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetStorePolicy")
+	defer span.End()
+
+	p := new(store.StorePolicy)
+	if err := m.entities.Get(ctx, m.createStorePolicyKey(storeKey), p); err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	return p, nil
+}
+
+// DeleteStorePolicy deletes the policy of the store so that all properties
+// are indexed again, by other MetaDB instances after StorePolicyTTL.
+// It is not an error if there is no policy.
+func (m *MetaDB) DeleteStorePolicy(ctx context.Context, storeKey string) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteStorePolicy")
+	defer span.End()
+
+	if err := m.entities.Delete(ctx, m.createStorePolicyKey(storeKey)); err != nil {
+		return datastoreErrToGRPCStatus(err)
+	}
+	m.storePolicies.invalidate(m.storePolicyCacheKey(storeKey))
+	return nil
+}
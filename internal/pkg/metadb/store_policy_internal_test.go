@@ -0,0 +1,84 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/datastoretest"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStorePolicyCache(t *testing.T) {
+	var c storePolicyCache
+	key := storePolicyCacheKey{namespace: "ns", storeKey: "store"}
+	now := time.Now()
+
+	_, ok := c.get(key, time.Minute, now)
+	assert.False(t, ok)
+
+	p := &store.StorePolicy{StoreKey: "store"}
+	c.set(key, p, now)
+	got, ok := c.get(key, time.Minute, now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Same(t, p, got)
+
+	_, ok = c.get(storePolicyCacheKey{namespace: "other", storeKey: "store"}, time.Minute, now)
+	assert.False(t, ok, "policies are cached per namespace")
+	_, ok = c.get(key, time.Minute, now.Add(time.Minute))
+	assert.False(t, ok, "the entry expires after the TTL")
+
+	c.set(key, nil, now)
+	got, ok = c.get(key, time.Minute, now)
+	assert.True(t, ok, "a store without a policy is cached too")
+	assert.Nil(t, got)
+
+	c.invalidate(key)
+	_, ok = c.get(key, time.Minute, now)
+	assert.False(t, ok)
+}
+
+func TestStorePolicy_FakeClient(t *testing.T) {
+	ctx := context.Background()
+	fake := datastoretest.NewClient()
+	m := &MetaDB{Namespace: "ns", entities: fake}
+
+	_, err := m.GetStorePolicy(ctx, "store")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	o, err := m.recordSaveOptions(ctx, "store")
+	require.NoError(t, err)
+	assert.Equal(t, record.SaveOptions{}, o, "a store without a policy indexes all properties")
+
+	p := &store.StorePolicy{StoreKey: "store", IndexedProperties: []string{"level"}}
+	_, err = fake.Put(ctx, m.createStorePolicyKey("store"), p)
+	require.NoError(t, err)
+	got, err := m.GetStorePolicy(ctx, "store")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"level"}, got.IndexedProperties)
+	o, err = m.recordSaveOptions(ctx, "store")
+	require.NoError(t, err)
+	assert.Equal(t, record.SaveOptions{}, o, "the missing policy is cached")
+
+	require.NoError(t, m.DeleteStorePolicy(ctx, "store"))
+	assert.Equal(t, 0, fake.Len())
+	require.NoError(t, m.DeleteStorePolicy(ctx, "store"), "deleting a missing policy is not an error")
+}
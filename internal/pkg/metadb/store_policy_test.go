@@ -0,0 +1,125 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/googleforgames/open-saves/api"
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// queryRecordsByLevel returns the number of records in the store that have the
+// integer property level = value.
+func queryRecordsByLevel(ctx context.Context, t *testing.T, metaDB *m.MetaDB, storeKey string, value int64) int {
+	t.Helper()
+	records, err := metaDB.QueryRecords(ctx, &pb.QueryRecordsRequest{
+		StoreKey: storeKey,
+		Filters: []*pb.QueryFilter{{
+			PropertyName: "level",
+			Operator:     pb.FilterOperator_EQUAL,
+			Value:        &pb.Property{Type: pb.Property_INTEGER, Value: &pb.Property_IntegerValue{IntegerValue: value}},
+		}},
+	})
+	require.NoError(t, err)
+	return len(records)
+}
+
+func TestMetaDB_StorePolicy(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	storeKey := newStoreKey()
+	recordKey := newRecordKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()}, &record.Record{
+		Key: recordKey,
+		Properties: record.PropertyMap{
+			"level": {Type: pb.Property_INTEGER, IntegerValue: 7},
+			"name":  {Type: pb.Property_STRING, StringValue: "hero"},
+		},
+	})
+	t.Cleanup(func() { metaDB.DeleteStorePolicy(ctx, storeKey) })
+
+	_, err := metaDB.GetStorePolicy(ctx, storeKey)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, 1, queryRecordsByLevel(ctx, t, metaDB, storeKey, 7),
+		"a store without a policy indexes all properties")
+
+	require.NoError(t, metaDB.SetStorePolicy(ctx, &store.StorePolicy{StoreKey: storeKey, IndexedProperties: []string{"name"}}))
+	got, err := metaDB.GetStorePolicy(ctx, storeKey)
+	require.NoError(t, err)
+	assert.Equal(t, storeKey, got.StoreKey)
+	assert.Equal(t, []string{"name"}, got.IndexedProperties)
+	assert.False(t, got.Timestamps.CreatedAt.IsZero())
+
+	// The record keeps its index until it is saved again.
+	assert.Equal(t, 1, queryRecordsByLevel(ctx, t, metaDB, storeKey, 7))
+	resave := func() {
+		t.Helper()
+		_, err := metaDB.UpdateRecord(ctx, storeKey, recordKey,
+			func(r *record.Record) (*record.Record, error) { return r, nil })
+		require.NoError(t, err)
+	}
+	resave()
+	assert.Zero(t, queryRecordsByLevel(ctx, t, metaDB, storeKey, 7), "level is no longer indexed")
+
+	require.NoError(t, metaDB.DeleteStorePolicy(ctx, storeKey))
+	_, err = metaDB.GetStorePolicy(ctx, storeKey)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	resave()
+	assert.Equal(t, 1, queryRecordsByLevel(ctx, t, metaDB, storeKey, 7), "level is indexed again")
+}
+
+func TestMetaDB_StorePolicyOtherInstance(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	other := newMetaDB(ctx, t)
+	other.StorePolicyTTL = time.Millisecond
+	storeKey := newStoreKey()
+	recordKey := newRecordKey()
+	setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: storeKey, Name: t.Name()}, &record.Record{
+		Key:        recordKey,
+		Properties: record.PropertyMap{"level": {Type: pb.Property_INTEGER, IntegerValue: 7}},
+	})
+	t.Cleanup(func() { metaDB.DeleteStorePolicy(ctx, storeKey) })
+	resave := func() {
+		t.Helper()
+		_, err := other.UpdateRecord(ctx, storeKey, recordKey,
+			func(r *record.Record) (*record.Record, error) { return r, nil })
+		require.NoError(t, err)
+	}
+	// Caches that the store has no policy.
+	resave()
+
+	require.NoError(t, metaDB.SetStorePolicy(ctx, &store.StorePolicy{StoreKey: storeKey, IndexedProperties: []string{}}))
+	time.Sleep(2 * other.StorePolicyTTL)
+	resave()
+	assert.Zero(t, queryRecordsByLevel(ctx, t, metaDB, storeKey, 7),
+		"the policy set by another instance applies after the TTL")
+}
+
+func TestMetaDB_StorePolicyNonExistentStore(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	err := metaDB.SetStorePolicy(ctx, &store.StorePolicy{StoreKey: newStoreKey(), IndexedProperties: []string{"name"}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestamps
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// SignatureSize is the byte length of signatures. Signatures are stored
+// as UUIDs, so Signer implementations must return exactly this many bytes.
+const SignatureSize = len(uuid.UUID{})
+
+// ErrInvalidSignatureSize is returned by Timestamps.Sign when the Signer
+// returns a signature that is not SignatureSize bytes long.
+var ErrInvalidSignatureSize = errors.New("the signer returned a signature of the wrong size")
+
+// Signer generates and verifies the Signature of Timestamps, and is passed
+// to Timestamps.Sign and Timestamps.VerifySignature.
+// The payload passed to Sign and Verify is derived from CreatedAt and UpdatedAt.
+type Signer interface {
+	// Sign returns a SignatureSize-byte signature for payload.
+	Sign(payload []byte) []byte
+	// Verify reports whether sig is a valid signature for payload.
+	Verify(payload, sig []byte) bool
+}
+
+// randomSigner ignores the payload and returns a random UUID each time,
+// therefore any signature of the right length is considered valid.
+type randomSigner struct{}
+
+// NewRandomSigner returns a Signer that signs like New and Update, with
+// random UUIDs.
+func NewRandomSigner() Signer {
+	return randomSigner{}
+}
+
+func (randomSigner) Sign(payload []byte) []byte {
+	u := uuid.New()
+	return u[:]
+}
+
+func (randomSigner) Verify(payload, sig []byte) bool {
+	return len(sig) == SignatureSize
+}
+
+// hmacSigner signs the payload with HMAC-SHA256 truncated to SignatureSize bytes.
+type hmacSigner struct {
+	key []byte
+}
+
+// NewHMACSigner returns a Signer that computes keyed HMAC-SHA256 signatures
+// so that modified timestamps can be detected with VerifySignature.
+func NewHMACSigner(key []byte) Signer {
+	return &hmacSigner{key: append([]byte{}, key...)}
+}
+
+func (h *hmacSigner) Sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(payload)
+	return mac.Sum(nil)[:SignatureSize]
+}
+
+func (h *hmacSigner) Verify(payload, sig []byte) bool {
+	return hmac.Equal(h.Sign(payload), sig)
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestamps
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSigner_HMACDetectsTampering(t *testing.T) {
+	signer := NewHMACSigner([]byte("test key"))
+
+	ts := New()
+	if err := ts.Sign(signer); err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+	if !ts.VerifySignature(signer) {
+		t.Errorf("VerifySignature() = false, want true for %+v", ts)
+	}
+	ts.Update()
+	if ts.VerifySignature(signer) {
+		t.Errorf("VerifySignature() after Update() = true, want false until signed again")
+	}
+	if err := ts.Sign(signer); err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+	if !ts.VerifySignature(signer) {
+		t.Errorf("VerifySignature() after Update() and Sign() = false, want true for %+v", ts)
+	}
+
+	tampered := ts
+	tampered.UpdatedAt = tampered.UpdatedAt.Add(time.Second)
+	if tampered.VerifySignature(signer) {
+		t.Errorf("VerifySignature() = true, want false for tampered %+v", tampered)
+	}
+
+	if ts.VerifySignature(NewHMACSigner([]byte("another key"))) {
+		t.Errorf("VerifySignature() = true, want false with a different key")
+	}
+}
+
+func TestSigner_RandomRoundTrip(t *testing.T) {
+	signer := NewRandomSigner()
+
+	ts := New()
+	if !ts.VerifySignature(signer) {
+		t.Errorf("VerifySignature() = false, want true for %+v", ts)
+	}
+	other := New()
+	if ts.Signature == other.Signature {
+		t.Errorf("New() should generate random signatures, got %v twice", ts.Signature)
+	}
+
+	properties, err := ts.Save()
+	if err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded := new(Timestamps)
+	if err := loaded.Load(properties); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if diff := cmp.Diff(ts, *loaded); diff != "" {
+		t.Errorf("Load() = (-want, +got):\n%s", diff)
+	}
+	if !loaded.VerifySignature(signer) {
+		t.Errorf("VerifySignature() = false after round trip, want true for %+v", loaded)
+	}
+}
+
+// shortSigner returns signatures that are too short.
+type shortSigner struct{}
+
+func (shortSigner) Sign(payload []byte) []byte {
+	return payload[:4]
+}
+
+func (shortSigner) Verify(payload, sig []byte) bool {
+	return false
+}
+
+func TestSigner_InvalidSignatureSize(t *testing.T) {
+	ts := New()
+	want := ts.Signature
+	if err := ts.Sign(shortSigner{}); !errors.Is(err, ErrInvalidSignatureSize) {
+		t.Errorf("Sign() = %v, want %v", err, ErrInvalidSignatureSize)
+	}
+	if ts.Signature != want {
+		t.Errorf("Sign() changed Signature to %v after failing", ts.Signature)
+	}
+}
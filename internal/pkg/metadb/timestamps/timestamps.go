@@ -15,6 +15,7 @@
 package timestamps
 
 import (
+	"encoding/binary"
 	"time"
 
 	"cloud.google.com/go/datastore"
@@ -41,7 +42,8 @@ type Timestamps struct {
 	// UpdatedAt is the timestamp of the last modification time
 	// Automatically set and managed by MetaDB
 	UpdatedAt time.Time `datastore:",noindex"`
-	// Signature is a UUID that is randomly created each time the record is updated
+	// Signature is a UUID that is randomly created each time the record is updated,
+	// or the signature of CreatedAt and UpdatedAt if signed with Sign.
 	// Automatically set and managed by MetaDB
 	Signature uuid.UUID `datastore:"-"`
 }
@@ -75,6 +77,34 @@ func (t *Timestamps) Update() {
 	t.Signature = uuid.New()
 }
 
+// Sign sets Signature to the signature of CreatedAt and UpdatedAt by s, e.g.
+// after New or Update, so that modifications can be detected with
+// VerifySignature. It returns ErrInvalidSignatureSize and keeps Signature
+// unchanged if s returns a signature of the wrong size.
+func (t *Timestamps) Sign(s Signer) error {
+	sig, err := uuid.FromBytes(s.Sign(t.signaturePayload()))
+	if err != nil {
+		return ErrInvalidSignatureSize
+	}
+	t.Signature = sig
+	return nil
+}
+
+// VerifySignature reports whether Signature is valid for CreatedAt and UpdatedAt
+// according to s.
+func (t *Timestamps) VerifySignature(s Signer) bool {
+	return s.Verify(t.signaturePayload(), t.Signature[:])
+}
+
+// signaturePayload returns the bytes that the Signer signs, which are
+// CreatedAt and UpdatedAt in microseconds since the Unix epoch.
+func (t *Timestamps) signaturePayload() []byte {
+	payload := make([]byte, 16)
+	binary.BigEndian.PutUint64(payload[:8], uint64(t.CreatedAt.UnixMicro()))
+	binary.BigEndian.PutUint64(payload[8:], uint64(t.UpdatedAt.UnixMicro()))
+	return payload
+}
+
 // Load implements the Datastore PropertyLoadSaver interface and converts Datastore
 // properties to corresponding struct fields.
 func (t *Timestamps) Load(ps []datastore.Property) error {
@@ -97,3 +127,26 @@ func (t *Timestamps) Save() ([]datastore.Property, error) {
 	ps = append(ps, UUIDToDatastoreProperty(signaturePropertyName, t.Signature, true))
 	return ps, nil
 }
+
+// IndexProperties clears NoIndex of the Timestamps fields in names within the
+// "Timestamps" entity property in properties, e.g. the output of
+// datastore.SaveStruct of an entity that embeds Timestamps, so that queries
+// can filter or sort by them.
+// Entities saved before a field was indexed are not returned by such queries
+// until they are saved again.
+func IndexProperties(properties []datastore.Property, names ...string) {
+	for _, p := range properties {
+		if p.Name != "Timestamps" {
+			continue
+		}
+		if e, ok := p.Value.(*datastore.Entity); ok {
+			for i := range e.Properties {
+				for _, name := range names {
+					if e.Properties[i].Name == name {
+						e.Properties[i].NoIndex = false
+					}
+				}
+			}
+		}
+	}
+}
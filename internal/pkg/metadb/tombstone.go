@@ -0,0 +1,108 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	tombstoneKind = "tombstone"
+
+	// tombstoneReapBatchSize is the number of tombstones deleted per call to DeleteMulti.
+	tombstoneReapBatchSize = 500
+)
+
+// ErrRecordDeleted is returned by GetRecordWithTombstone when the record doesn't
+// exist but a tombstone shows that it was deleted.
+var ErrRecordDeleted = status.Error(codes.NotFound, "the record has been deleted")
+
+// Tombstone is left by DeleteRecordWithTombstone in place of a deleted record so
+// that clients can tell a deletion from a record that never existed.
+// It is a child of the store entity keyed by the record key, like records.
+type Tombstone struct {
+	RecordKey string `datastore:",noindex"`
+	DeletedAt time.Time
+}
+
+func (m *MetaDB) createTombstoneKey(storeKey, key string) *ds.Key {
+	tk := ds.NameKey(tombstoneKind, key, m.createStoreKey(storeKey))
+	tk.Namespace = m.Namespace
+	return tk
+}
+
+// DeleteRecordWithTombstone is the same as DeleteRecord, but leaves a tombstone
+// of the record if it exists. The tombstone is kept until ReapTombstones removes it.
+func (m *MetaDB) DeleteRecordWithTombstone(ctx context.Context, storeKey, key string) error {
+	_, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.DeleteRecordWithTombstone")
+	defer span.End()
+
+	_, err := m.deleteRecord(ctx, storeKey, key, true)
+	return err
+}
+
+// GetRecordWithTombstone is the same as GetRecord, but returns ErrRecordDeleted
+// instead of the NotFound error when the record has a tombstone.
+// A record that is created again after the deletion is returned as usual.
+func (m *MetaDB) GetRecordWithTombstone(ctx context.Context, storeKey, key string) (*record.Record, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.GetRecordWithTombstone")
+	defer span.End()
+
+	r, err := m.GetRecord(ctx, storeKey, key)
+	if status.Code(err) != codes.NotFound {
+		return r, err
+	}
+	if terr := m.entities.Get(ctx, m.createTombstoneKey(storeKey, key), new(Tombstone)); terr != nil {
+		if terr == ds.ErrNoSuchEntity {
+			return nil, err
+		}
+		return nil, datastoreErrToGRPCStatus(terr)
+	}
+	return nil, ErrRecordDeleted
+}
+
+// ReapTombstones deletes tombstones of all stores that are older than retention,
+// and returns the number of deleted tombstones.
+func (m *MetaDB) ReapTombstones(ctx context.Context, retention time.Duration) (int, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.ReapTombstones")
+	defer span.End()
+
+	query := m.newQuery(tombstoneKind).KeysOnly().Filter("DeletedAt <", time.Now().Add(-retention))
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return 0, datastoreErrToGRPCStatus(err)
+	}
+	deleted := 0
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > tombstoneReapBatchSize {
+			n = tombstoneReapBatchSize
+		}
+		if err := m.entities.DeleteMulti(ctx, keys[:n]); err != nil {
+			return deleted, datastoreErrToGRPCStatus(err)
+		}
+		deleted += n
+		keys = keys[n:]
+	}
+	return deleted, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetaDB_DeleteRecordWithTombstone(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+
+	require.NoError(t, metaDB.DeleteRecordWithTombstone(ctx, st.Key, r.Key))
+	_, err := metaDB.GetRecord(ctx, st.Key, r.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = metaDB.GetRecordWithTombstone(ctx, st.Key, r.Key)
+	assert.ErrorIs(t, err, m.ErrRecordDeleted)
+
+	// Deleting a missing record neither fails nor leaves a tombstone.
+	missing := newRecordKey()
+	require.NoError(t, metaDB.DeleteRecordWithTombstone(ctx, st.Key, missing))
+	_, err = metaDB.GetRecordWithTombstone(ctx, st.Key, missing)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotErrorIs(t, err, m.ErrRecordDeleted)
+}
+
+func TestMetaDB_GetRecordWithTombstone(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+
+	got, err := metaDB.GetRecordWithTombstone(ctx, st.Key, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, r.Key, got.Key)
+
+	// A plain DeleteRecord doesn't leave a tombstone.
+	require.NoError(t, metaDB.DeleteRecord(ctx, st.Key, r.Key))
+	_, err = metaDB.GetRecordWithTombstone(ctx, st.Key, r.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotErrorIs(t, err, m.ErrRecordDeleted)
+
+	// A record created again after the deletion shadows the tombstone.
+	setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: r.Key, Properties: make(record.PropertyMap)})
+	require.NoError(t, metaDB.DeleteRecordWithTombstone(ctx, st.Key, r.Key))
+	setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{Key: r.Key, Properties: make(record.PropertyMap)})
+	got, err = metaDB.GetRecordWithTombstone(ctx, st.Key, r.Key)
+	require.NoError(t, err)
+	assert.Equal(t, r.Key, got.Key)
+}
+
+func TestMetaDB_ReapTombstones(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, r := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()},
+		&record.Record{Key: newRecordKey(), Properties: make(record.PropertyMap)})
+	require.NoError(t, metaDB.DeleteRecordWithTombstone(ctx, st.Key, r.Key))
+
+	// The tombstone is within the retention.
+	_, err := metaDB.ReapTombstones(ctx, time.Hour)
+	require.NoError(t, err)
+	_, err = metaDB.GetRecordWithTombstone(ctx, st.Key, r.Key)
+	assert.ErrorIs(t, err, m.ErrRecordDeleted)
+
+	n, err := metaDB.ReapTombstones(ctx, -time.Minute)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, 1)
+	_, err = metaDB.GetRecordWithTombstone(ctx, st.Key, r.Key)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.NotErrorIs(t, err, m.ErrRecordDeleted)
+}
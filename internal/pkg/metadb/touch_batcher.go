@@ -0,0 +1,254 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ds "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultTouchWindow is the window used by NewTouchBatcher if it is not positive.
+	DefaultTouchWindow = time.Second
+	// maxTouchBatchSize is the maximum number of BlobRefs written by a single
+	// PutMulti, which is the maximum Datastore allows in a transaction.
+	maxTouchBatchSize = 500
+)
+
+// ErrTouchBatcherClosed is returned by TouchBatcher.Touch after Close is called.
+var ErrTouchBatcherClosed = status.Error(codes.FailedPrecondition, "the touch batcher is closed")
+
+// touchWriter writes a batch of LastAccessedAt updates.
+type touchWriter func(ctx context.Context, batch map[uuid.UUID]time.Time) error
+
+// TouchBatcher coalesces LastAccessedAt updates of BlobRefs, which are made
+// on every read, and writes them in PutMulti batches so that frequent reads
+// don't cause as many small Datastore writes. Touches of the same BlobRef
+// within a window are deduplicated to the latest time.
+// Touches are kept in memory until they are written, so call Close on
+// shutdown to write the pending ones.
+type TouchBatcher struct {
+	window   time.Duration
+	maxBatch int
+	write    touchWriter
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]time.Time
+	timer   *time.Timer
+	closed  bool
+	flushes sync.WaitGroup
+}
+
+// NewTouchBatcher returns a TouchBatcher that writes pending touches window
+// after the first one, or as soon as maxBatch BlobRefs are pending.
+// DefaultTouchWindow is used if window is not positive, and the Datastore limit
+// of 500 if maxBatch is not positive or larger than the limit.
+func (m *MetaDB) NewTouchBatcher(window time.Duration, maxBatch int) *TouchBatcher {
+	return newTouchBatcher(window, maxBatch, m.writeTouches)
+}
+
+func newTouchBatcher(window time.Duration, maxBatch int, write touchWriter) *TouchBatcher {
+	if window <= 0 {
+		window = DefaultTouchWindow
+	}
+	if maxBatch <= 0 || maxBatch > maxTouchBatchSize {
+		maxBatch = maxTouchBatchSize
+	}
+	return &TouchBatcher{
+		window:   window,
+		maxBatch: maxBatch,
+		write:    write,
+		pending:  make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Touch sets LastAccessedAt of the BlobRef of key to at in the next batch.
+// An earlier at than a pending touch of the same key is ignored.
+// Touches of BlobRefs that are deleted before the batch is written are dropped.
+// Returns ErrTouchBatcherClosed if Close has been called.
+func (b *TouchBatcher) Touch(key uuid.UUID, at time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrTouchBatcherClosed
+	}
+	if prev, ok := b.pending[key]; !ok || at.After(prev) {
+		b.pending[key] = at
+	}
+	switch {
+	case len(b.pending) >= b.maxBatch:
+		if b.timer != nil {
+			b.timer.Stop()
+			b.timer = nil
+		}
+		b.flushInBackgroundLocked()
+	case b.timer == nil:
+		b.timer = time.AfterFunc(b.window, b.flushOnTimer)
+	}
+	return nil
+}
+
+func (b *TouchBatcher) flushOnTimer() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.timer = nil
+	if !b.closed {
+		b.flushInBackgroundLocked()
+	}
+}
+
+// flushInBackgroundLocked writes the pending touches in a new goroutine.
+// Close waits for the goroutine to finish. b.mu must be held.
+func (b *TouchBatcher) flushInBackgroundLocked() {
+	pending := b.takePendingLocked()
+	if len(pending) == 0 {
+		return
+	}
+	b.flushes.Add(1)
+	go func() {
+		defer b.flushes.Done()
+		if err := b.writeAll(context.Background(), pending); err != nil {
+			log.Errorf("TouchBatcher: failed to write %d touches: %v", len(pending), err)
+		}
+	}()
+}
+
+func (b *TouchBatcher) takePendingLocked() map[uuid.UUID]time.Time {
+	pending := b.pending
+	b.pending = make(map[uuid.UUID]time.Time)
+	return pending
+}
+
+// Flush writes the pending touches now.
+func (b *TouchBatcher) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	pending := b.takePendingLocked()
+	b.mu.Unlock()
+	return b.writeAll(ctx, pending)
+}
+
+// Close stops accepting touches, writes the pending ones, and waits for the
+// batches being written in the background until ctx is done.
+func (b *TouchBatcher) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	pending := b.takePendingLocked()
+	b.mu.Unlock()
+
+	err := b.writeAll(ctx, pending)
+	done := make(chan struct{})
+	go func() {
+		b.flushes.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	return err
+}
+
+// writeAll writes pending in batches of at most maxBatch BlobRefs.
+func (b *TouchBatcher) writeAll(ctx context.Context, pending map[uuid.UUID]time.Time) error {
+	batch := make(map[uuid.UUID]time.Time)
+	for key, at := range pending {
+		batch[key] = at
+		if len(batch) == b.maxBatch {
+			if err := b.write(ctx, batch); err != nil {
+				return err
+			}
+			batch = make(map[uuid.UUID]time.Time)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return b.write(ctx, batch)
+}
+
+// writeTouches updates LastAccessedAt of the BlobRefs in batch with a single
+// PutMulti. The BlobRefs are read and written in a transaction so that
+// concurrent changes to other fields are not overwritten.
+func (m *MetaDB) writeTouches(ctx context.Context, batch map[uuid.UUID]time.Time) error {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.writeTouches")
+	defer span.End()
+
+	keys := make([]*ds.Key, 0, len(batch))
+	times := make([]time.Time, 0, len(batch))
+	for key, at := range batch {
+		keys = append(keys, m.createBlobKey(key))
+		times = append(times, at)
+	}
+	_, err := m.runInTransaction(ctx, func(tx *ds.Transaction) error {
+		blobs := make([]*blobref.BlobRef, len(keys))
+		for i := range blobs {
+			blobs[i] = new(blobref.BlobRef)
+		}
+		exists := make([]bool, len(keys))
+		for i := range exists {
+			exists[i] = true
+		}
+		if err := tx.GetMulti(keys, blobs); err != nil {
+			merr, ok := err.(ds.MultiError)
+			if !ok {
+				return err
+			}
+			for i, e := range merr {
+				if e == ds.ErrNoSuchEntity {
+					exists[i] = false
+				} else if e != nil {
+					return e
+				}
+			}
+		}
+		var putKeys []*ds.Key
+		var putBlobs []*blobref.BlobRef
+		for i, b := range blobs {
+			if !exists[i] || !times[i].After(b.LastAccessedAt) {
+				continue
+			}
+			b.LastAccessedAt = times[i]
+			putKeys = append(putKeys, keys[i])
+			putBlobs = append(putBlobs, b)
+		}
+		if len(putKeys) == 0 {
+			return nil
+		}
+		_, err := tx.PutMulti(putKeys, putBlobs)
+		return err
+	})
+	return datastoreErrToGRPCStatus(err)
+}
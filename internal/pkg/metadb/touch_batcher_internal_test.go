@@ -0,0 +1,121 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTouchWriter records the batches written by a TouchBatcher.
+type recordingTouchWriter struct {
+	mu      sync.Mutex
+	batches []map[uuid.UUID]time.Time
+}
+
+func (w *recordingTouchWriter) write(ctx context.Context, batch map[uuid.UUID]time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, batch)
+	return nil
+}
+
+func (w *recordingTouchWriter) written() map[uuid.UUID]time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	all := make(map[uuid.UUID]time.Time)
+	for _, b := range w.batches {
+		for k, v := range b {
+			all[k] = v
+		}
+	}
+	return all
+}
+
+func (w *recordingTouchWriter) batchCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.batches)
+}
+
+func TestTouchBatcher_CoalescesTouches(t *testing.T) {
+	w := new(recordingTouchWriter)
+	b := newTouchBatcher(time.Hour, 0, w.write)
+	now := time.Now()
+	a, c := uuid.New(), uuid.New()
+
+	require.NoError(t, b.Touch(a, now))
+	require.NoError(t, b.Touch(a, now.Add(2*time.Second)))
+	require.NoError(t, b.Touch(a, now.Add(time.Second)), "an earlier touch doesn't win")
+	require.NoError(t, b.Touch(c, now))
+	require.NoError(t, b.Flush(context.Background()))
+
+	assert.Equal(t, 1, w.batchCount())
+	assert.Equal(t, map[uuid.UUID]time.Time{a: now.Add(2 * time.Second), c: now}, w.written())
+
+	// Nothing is written when nothing is pending.
+	require.NoError(t, b.Flush(context.Background()))
+	assert.Equal(t, 1, w.batchCount())
+}
+
+func TestTouchBatcher_FlushesOnTimer(t *testing.T) {
+	w := new(recordingTouchWriter)
+	b := newTouchBatcher(10*time.Millisecond, 0, w.write)
+	key := uuid.New()
+	now := time.Now()
+	require.NoError(t, b.Touch(key, now))
+
+	assert.Eventually(t, func() bool { return w.batchCount() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, map[uuid.UUID]time.Time{key: now}, w.written())
+	require.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, 1, w.batchCount())
+}
+
+func TestTouchBatcher_FlushesFullBatches(t *testing.T) {
+	w := new(recordingTouchWriter)
+	b := newTouchBatcher(time.Hour, 2, w.write)
+	now := time.Now()
+	require.NoError(t, b.Touch(uuid.New(), now))
+	require.NoError(t, b.Touch(uuid.New(), now))
+
+	// The batch is written without waiting for the window.
+	assert.Eventually(t, func() bool { return w.batchCount() == 1 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, b.Close(context.Background()))
+}
+
+func TestTouchBatcher_CloseFlushesPending(t *testing.T) {
+	w := new(recordingTouchWriter)
+	b := newTouchBatcher(time.Hour, 2, w.write)
+	now := time.Now()
+	want := make(map[uuid.UUID]time.Time)
+	for i := 0; i < 5; i++ {
+		key := uuid.New()
+		want[key] = now
+		require.NoError(t, b.Touch(key, now))
+	}
+
+	require.NoError(t, b.Close(context.Background()))
+	assert.Equal(t, want, w.written())
+	assert.Equal(t, 3, w.batchCount())
+
+	assert.ErrorIs(t, b.Touch(uuid.New(), now), ErrTouchBatcherClosed)
+	assert.Equal(t, 3, w.batchCount())
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/blobref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaDB_TouchBatcher(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	_, _, blob := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	batcher := metaDB.NewTouchBatcher(time.Hour, 0)
+
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, batcher.Touch(blob.Key, at.Add(-time.Minute)))
+	require.NoError(t, batcher.Touch(blob.Key, at))
+	// Touches of missing BlobRefs are dropped.
+	require.NoError(t, batcher.Touch(uuid.New(), at))
+	require.NoError(t, batcher.Close(ctx))
+
+	got, err := metaDB.GetBlobRef(ctx, blob.Key)
+	require.NoError(t, err)
+	assert.True(t, at.Equal(got.LastAccessedAt))
+	assert.Equal(t, blobref.StatusInitializing, got.Status, "other fields are kept")
+}
+
+func TestMetaDB_TouchBatcherKeepsLatest(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	_, _, blob := setupTestStoreRecordBlobSet(ctx, t, metaDB, false)
+	batcher := metaDB.NewTouchBatcher(time.Hour, 0)
+	t.Cleanup(func() { batcher.Close(ctx) })
+
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, batcher.Touch(blob.Key, at))
+	require.NoError(t, batcher.Flush(ctx))
+
+	// A touch older than the saved one doesn't move LastAccessedAt back.
+	require.NoError(t, batcher.Touch(blob.Key, at.Add(-time.Hour)))
+	require.NoError(t, batcher.Flush(ctx))
+	got, err := metaDB.GetBlobRef(ctx, blob.Key)
+	require.NoError(t, err)
+	assert.True(t, at.Equal(got.LastAccessedAt))
+}
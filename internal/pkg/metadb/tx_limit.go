@@ -0,0 +1,76 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+
+	ds "cloud.google.com/go/datastore"
+	"google.golang.org/grpc/status"
+)
+
+// txLimiter is a semaphore that bounds the number of concurrent transactions.
+// A nil txLimiter doesn't limit transactions.
+type txLimiter chan struct{}
+
+// newTxLimiter returns a txLimiter that allows up to n concurrent transactions,
+// or nil if n is not positive.
+func newTxLimiter(n int) txLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return make(txLimiter, n)
+}
+
+// acquire blocks until a transaction can start or ctx is done. It returns the
+// gRPC status of the context error in the latter case.
+func (l txLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// release ends a transaction started with acquire.
+func (l txLimiter) release() {
+	if l != nil {
+		<-l
+	}
+}
+
+// SetMaxConcurrentTransactions limits the number of transactions that m runs
+// at once to n. Callers wait for a running transaction to finish, or for their
+// context to be done, when n transactions are running. There is no limit if n
+// is not positive, which is the default.
+// It must be called before m is used.
+func (m *MetaDB) SetMaxConcurrentTransactions(n int) {
+	m.txLimiter = newTxLimiter(n)
+}
+
+// runInTransaction runs f in a transaction with m.client.RunInTransaction
+// under the limit set by SetMaxConcurrentTransactions. Retries of f due to
+// contention are made without waiting for the limiter again.
+func (m *MetaDB) runInTransaction(ctx context.Context, f func(tx *ds.Transaction) error, opts ...ds.TransactionOption) (*ds.Commit, error) {
+	if err := m.txLimiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer m.txLimiter.release()
+	return m.client.RunInTransaction(ctx, f, opts...)
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTxLimiter_Paced(t *testing.T) {
+	const limit = 2
+	l := newTxLimiter(limit)
+	ctx := context.Background()
+
+	var running, maxRunning int32
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !assert.NoError(t, l.acquire(ctx)) {
+				return
+			}
+			defer l.release()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(limit), maxRunning)
+}
+
+func TestTxLimiter_CancelReleasesWaiter(t *testing.T) {
+	l := newTxLimiter(1)
+	require.NoError(t, l.acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- l.acquire(ctx) }()
+	select {
+	case err := <-errc:
+		t.Fatalf("acquire() = %v, want to block while saturated", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	cancel()
+	assert.Equal(t, codes.Canceled, status.Code(<-errc))
+
+	// The cancelled waiter didn't take the slot.
+	l.release()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, l.acquire(ctx))
+}
+
+func TestTxLimiter_Unlimited(t *testing.T) {
+	l := newTxLimiter(0)
+	assert.Nil(t, l)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, l.acquire(ctx))
+	}
+	l.release()
+}
+
+func TestTxLimiter_RunInTransactionCanceled(t *testing.T) {
+	m := new(MetaDB)
+	m.SetMaxConcurrentTransactions(1)
+	require.NoError(t, m.txLimiter.acquire(context.Background()))
+	defer m.txLimiter.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	// The client is not used as the transaction doesn't start.
+	_, err := m.runInTransaction(ctx, nil)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
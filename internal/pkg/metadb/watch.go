@@ -0,0 +1,255 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/tracing"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWatchInterval is the polling interval used by Watch.
+const DefaultWatchInterval = 5 * time.Second
+
+// RecordChangeType is the type of a RecordChangeEvent.
+type RecordChangeType int
+
+const (
+	// RecordCreated means a new record was inserted.
+	RecordCreated RecordChangeType = iota + 1
+	// RecordUpdated means an existing record was modified.
+	RecordUpdated
+	// RecordDeleted means a record was deleted.
+	RecordDeleted
+)
+
+// RecordChangeEvent is emitted by Watch when a record in the store changes.
+type RecordChangeEvent struct {
+	Type     RecordChangeType
+	StoreKey string
+	Key      string
+	// Record is the record after the change. It is nil for RecordDeleted.
+	Record *record.Record
+}
+
+// recordVersion identifies a revision of a record seen by Watch.
+type recordVersion struct {
+	updatedAt time.Time
+	signature uuid.UUID
+}
+
+func versionOf(r *record.Record) recordVersion {
+	return recordVersion{updatedAt: r.Timestamps.UpdatedAt, signature: r.Timestamps.Signature}
+}
+
+// watchState is what Watch remembers between polls.
+type watchState struct {
+	// since is the latest UpdatedAt seen so far. Each poll reads records updated
+	// at or after since - watchLookback.
+	since time.Time
+	// known is the set of record keys in the store that have been reported or
+	// existed when Watch started.
+	known map[string]struct{}
+	// seen has the versions of records read by the previous polls that are
+	// still within the lookback window, so that they are not reported twice.
+	seen map[string]recordVersion
+}
+
+// Watch is an alias to WatchWithInterval(ctx, storeKey, DefaultWatchInterval).
+func (m *MetaDB) Watch(ctx context.Context, storeKey string) (<-chan RecordChangeEvent, error) {
+	return m.WatchWithInterval(ctx, storeKey, DefaultWatchInterval)
+}
+
+// WatchWithInterval emits events for records in the store that are created, updated,
+// or deleted after the call. Datastore doesn't provide a change feed, so every interval
+// Watch queries the records whose Timestamps.UpdatedAt is later than the last poll,
+// minus watchLookback to tolerate clock skew between writers, and lists the record keys
+// of the store to detect deletions. Multiple changes to a record within an interval are
+// reported as one event. Records that were saved before Timestamps.UpdatedAt was indexed
+// are only reported once they are updated again.
+// The returned channel is closed when ctx is done.
+// Returns InvalidArgument if interval is not positive, and NotFound if the store
+// doesn't exist.
+func (m *MetaDB) WatchWithInterval(ctx context.Context, storeKey string, interval time.Duration) (<-chan RecordChangeEvent, error) {
+	if interval <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "watch interval must be positive, got %v", interval)
+	}
+	if _, err := m.GetStore(ctx, storeKey); err != nil {
+		return nil, err
+	}
+	state := &watchState{since: time.Now(), seen: make(map[string]recordVersion)}
+	known, err := m.watchKeys(ctx, storeKey)
+	if err != nil {
+		return nil, err
+	}
+	state.known = known
+	// Records updated within the lookback window before the call are not changes
+	// after the call, so they are only remembered.
+	updated, err := m.watchUpdatedRecords(ctx, storeKey, state.since.Add(-watchLookback))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range updated {
+		state.seen[r.Key] = versionOf(r)
+	}
+
+	events := make(chan RecordChangeEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			updated, err := m.watchUpdatedRecords(ctx, storeKey, state.since.Add(-watchLookback))
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warnf("Watch: failed to query updated records of store (%v): %v", storeKey, err)
+				}
+				continue
+			}
+			keys, err := m.watchKeys(ctx, storeKey)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warnf("Watch: failed to read record keys of store (%v): %v", storeKey, err)
+				}
+				continue
+			}
+			for _, e := range state.advance(storeKey, updated, keys) {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// watchLookback is how far before the latest UpdatedAt seen each poll of Watch
+// reads, so that writes committed late or by a writer with a skewed clock are not missed.
+const watchLookback = 5 * time.Second
+
+// watchPageSize is the number of records read by each query of a Watch poll.
+const watchPageSize = 300
+
+// watchUpdatedRecords returns the records of the store updated at or after since,
+// ordered by UpdatedAt.
+func (m *MetaDB) watchUpdatedRecords(ctx context.Context, storeKey string, since time.Time) ([]*record.Record, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.watchUpdatedRecords")
+	defer span.End()
+
+	query := m.newQuery(recordKind).Ancestor(m.createStoreKey(storeKey)).
+		Filter("Timestamps.UpdatedAt >=", since).Order("Timestamps.UpdatedAt").Limit(watchPageSize)
+	var records []*record.Record
+	for {
+		iter := m.client.Run(ctx, query)
+		n := 0
+		for {
+			r := new(record.Record)
+			_, err := iter.Next(r)
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, datastoreErrToGRPCStatus(err)
+			}
+			records = append(records, r)
+			n++
+		}
+		if n < watchPageSize {
+			return records, nil
+		}
+		cursor, err := iter.Cursor()
+		if err != nil {
+			return nil, datastoreErrToGRPCStatus(err)
+		}
+		query = query.Start(cursor)
+	}
+}
+
+// watchKeys returns the set of record keys in the store.
+func (m *MetaDB) watchKeys(ctx context.Context, storeKey string) (map[string]struct{}, error) {
+	ctx, span := otel.Tracer(tracing.ServiceName).Start(ctx, "MetaDB.watchKeys")
+	defer span.End()
+
+	query := m.newQuery(recordKind).Ancestor(m.createStoreKey(storeKey)).KeysOnly()
+	keys, err := m.client.GetAll(ctx, query, nil)
+	if err != nil {
+		return nil, datastoreErrToGRPCStatus(err)
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k.Name] = struct{}{}
+	}
+	return set, nil
+}
+
+// advance returns the events for the records updated since the previous poll and
+// the current record keys of the store, and updates the state.
+// Events are sorted by record key with deletions last.
+func (w *watchState) advance(storeKey string, updated []*record.Record, keys map[string]struct{}) []RecordChangeEvent {
+	var changed, deleted []RecordChangeEvent
+	for _, r := range updated {
+		v := versionOf(r)
+		if v.updatedAt.After(w.since) {
+			w.since = v.updatedAt
+		}
+		if old, ok := w.seen[r.Key]; ok && old == v {
+			continue
+		}
+		w.seen[r.Key] = v
+		if _, ok := keys[r.Key]; !ok {
+			// Deleted after it was read. The deletion is reported below if it was known.
+			continue
+		}
+		e := RecordChangeEvent{Type: RecordUpdated, StoreKey: storeKey, Key: r.Key, Record: r}
+		if _, ok := w.known[r.Key]; !ok {
+			e.Type = RecordCreated
+			w.known[r.Key] = struct{}{}
+		}
+		changed = append(changed, e)
+	}
+	for key := range w.known {
+		if _, ok := keys[key]; !ok {
+			deleted = append(deleted, RecordChangeEvent{Type: RecordDeleted, StoreKey: storeKey, Key: key})
+			delete(w.known, key)
+		}
+	}
+	for key, v := range w.seen {
+		if v.updatedAt.Before(w.since.Add(-watchLookback)) {
+			delete(w.seen, key)
+		}
+	}
+	byKey := func(events []RecordChangeEvent) {
+		sort.Slice(events, func(i, j int) bool { return events[i].Key < events[j].Key })
+	}
+	byKey(changed)
+	byKey(deleted)
+	return append(changed, deleted...)
+}
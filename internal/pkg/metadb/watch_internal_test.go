@@ -0,0 +1,70 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/timestamps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchState_Advance(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	newRecord := func(key string, updatedAt time.Time) *record.Record {
+		return &record.Record{Key: key, Timestamps: timestamps.Timestamps{
+			CreatedAt: updatedAt,
+			UpdatedAt: updatedAt,
+			Signature: uuid.New(),
+		}}
+	}
+	state := &watchState{
+		since: start,
+		known: map[string]struct{}{"existing": {}, "deleted": {}},
+		seen:  make(map[string]recordVersion),
+	}
+	existing := newRecord("existing", start.Add(time.Second))
+	created := newRecord("created", start.Add(2*time.Second))
+	gone := newRecord("gone", start.Add(time.Second))
+	keys := map[string]struct{}{"existing": {}, "created": {}}
+
+	events := state.advance("store", []*record.Record{existing, gone, created}, keys)
+	require.Len(t, events, 3)
+	assert.Equal(t, RecordChangeEvent{Type: RecordCreated, StoreKey: "store", Key: "created", Record: created}, events[0])
+	assert.Equal(t, RecordChangeEvent{Type: RecordUpdated, StoreKey: "store", Key: "existing", Record: existing}, events[1])
+	assert.Equal(t, RecordChangeEvent{Type: RecordDeleted, StoreKey: "store", Key: "deleted"}, events[2])
+	assert.Equal(t, created.Timestamps.UpdatedAt, state.since)
+
+	// The same versions are read again within the lookback window.
+	assert.Empty(t, state.advance("store", []*record.Record{existing, created}, keys))
+
+	// A change with the same UpdatedAt but a new signature is still reported.
+	changed := newRecord("existing", existing.Timestamps.UpdatedAt)
+	events = state.advance("store", []*record.Record{changed}, keys)
+	require.Len(t, events, 1)
+	assert.Equal(t, RecordUpdated, events[0].Type)
+
+	// Versions older than the lookback window are forgotten.
+	later := newRecord("created", start.Add(watchLookback+time.Minute))
+	state.advance("store", []*record.Record{later}, keys)
+	assert.NotContains(t, state.seen, "existing")
+	assert.Contains(t, state.seen, "created")
+}
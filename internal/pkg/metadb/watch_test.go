@@ -0,0 +1,122 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	m "github.com/googleforgames/open-saves/internal/pkg/metadb"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/record"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testWatchInterval = 50 * time.Millisecond
+
+func receiveEvent(t *testing.T, events <-chan m.RecordChangeEvent) m.RecordChangeEvent {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		require.True(t, ok, "the event channel was closed unexpectedly")
+		return e
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a record change event")
+	}
+	return m.RecordChangeEvent{}
+}
+
+func TestMetaDB_Watch(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := metaDB.WatchWithInterval(watchCtx, st.Key, testWatchInterval)
+	require.NoError(t, err)
+
+	r := setupTestRecord(ctx, t, metaDB, st.Key, &record.Record{
+		Key:        newRecordKey(),
+		Properties: make(record.PropertyMap),
+	})
+	e := receiveEvent(t, events)
+	assert.Equal(t, m.RecordCreated, e.Type)
+	assert.Equal(t, st.Key, e.StoreKey)
+	assert.Equal(t, r.Key, e.Key)
+	if assert.NotNil(t, e.Record) {
+		assert.Equal(t, r.Key, e.Record.Key)
+	}
+
+	_, err = metaDB.UpdateRecord(ctx, st.Key, r.Key, func(r *record.Record) (*record.Record, error) {
+		r.OpaqueString = "updated"
+		return r, nil
+	})
+	require.NoError(t, err)
+	e = receiveEvent(t, events)
+	assert.Equal(t, m.RecordUpdated, e.Type)
+	assert.Equal(t, r.Key, e.Key)
+	if assert.NotNil(t, e.Record) {
+		assert.Equal(t, "updated", e.Record.OpaqueString)
+	}
+
+	require.NoError(t, metaDB.DeleteRecord(ctx, st.Key, r.Key))
+	e = receiveEvent(t, events)
+	assert.Equal(t, m.RecordDeleted, e.Type)
+	assert.Equal(t, r.Key, e.Key)
+	assert.Nil(t, e.Record)
+}
+
+func TestMetaDB_WatchCancel(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+	st, _ := setupTestStoreRecord(ctx, t, metaDB, &store.Store{Key: newStoreKey(), Name: t.Name()}, nil)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	events, err := metaDB.WatchWithInterval(watchCtx, st.Key, testWatchInterval)
+	require.NoError(t, err)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "no events should be emitted after cancellation")
+	case <-time.After(10 * time.Second):
+		t.Fatal("the event channel was not closed after cancellation")
+	}
+}
+
+func TestMetaDB_WatchNonExistentStore(t *testing.T) {
+	ctx := context.Background()
+	metaDB := newMetaDB(ctx, t)
+
+	_, err := metaDB.Watch(ctx, newStoreKey())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestWatchWithInterval_InvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	// The interval is checked before the store is read.
+	metaDB := new(m.MetaDB)
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := metaDB.WatchWithInterval(ctx, "store", interval)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "interval = %v", interval)
+	}
+}
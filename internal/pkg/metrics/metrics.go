@@ -0,0 +1,52 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines a pluggable interface to record operational metrics.
+package metrics
+
+import "time"
+
+// Outcome is the result label of an operation.
+type Outcome string
+
+const (
+	Success Outcome = "success"
+	Error   Outcome = "error"
+)
+
+// OutcomeOf returns Error if err is non-nil, otherwise Success.
+func OutcomeOf(err error) Outcome {
+	if err != nil {
+		return Error
+	}
+	return Success
+}
+
+// Collector receives measurements from instrumented components and exports them
+// to a metrics backend. Implementations must be safe for concurrent use.
+type Collector interface {
+	// ObserveLatency records the duration of an operation to a histogram.
+	ObserveLatency(op string, outcome Outcome, d time.Duration)
+	// AddBytes adds n to the bytes transferred counter of an operation.
+	AddBytes(op string, outcome Outcome, n int64)
+}
+
+// NopCollector is a Collector that discards all measurements.
+type NopCollector struct{}
+
+// Assert NopCollector implements Collector.
+var _ Collector = NopCollector{}
+
+func (NopCollector) ObserveLatency(string, Outcome, time.Duration) {}
+func (NopCollector) AddBytes(string, Outcome, int64)               {}
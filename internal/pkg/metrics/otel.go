@@ -0,0 +1,80 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+)
+
+// Names of the OpenTelemetry instruments and attributes of OTelCollector.
+const (
+	LatencyInstrumentName = "open_saves.operation.latency"
+	BytesInstrumentName   = "open_saves.operation.bytes"
+
+	OperationAttribute = "operation"
+	OutcomeAttribute   = "outcome"
+)
+
+// OTelCollector is a Collector that records measurements to OpenTelemetry
+// instruments: latencies to a histogram in milliseconds and bytes to a counter,
+// both with the operation and outcome attributes.
+type OTelCollector struct {
+	latency instrument.Float64Histogram
+	bytes   instrument.Int64Counter
+}
+
+// Assert OTelCollector implements Collector.
+var _ Collector = new(OTelCollector)
+
+// NewOTelCollector creates the instruments of an OTelCollector with meter,
+// which is typically global.Meter so that measurements are exported by the
+// MeterProvider that the process registers.
+func NewOTelCollector(meter metric.Meter) (*OTelCollector, error) {
+	latency, err := meter.Float64Histogram(LatencyInstrumentName,
+		instrument.WithDescription("Latency of operations"),
+		instrument.WithUnit("ms"))
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := meter.Int64Counter(BytesInstrumentName,
+		instrument.WithDescription("Bytes transferred by operations"),
+		instrument.WithUnit("By"))
+	if err != nil {
+		return nil, err
+	}
+	return &OTelCollector{latency: latency, bytes: bytes}, nil
+}
+
+func attributes(op string, outcome Outcome) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String(OperationAttribute, op),
+		attribute.String(OutcomeAttribute, string(outcome)),
+	}
+}
+
+// ObserveLatency records d in milliseconds to the latency histogram.
+func (c *OTelCollector) ObserveLatency(op string, outcome Outcome, d time.Duration) {
+	c.latency.Record(context.Background(), float64(d)/float64(time.Millisecond), attributes(op, outcome)...)
+}
+
+// AddBytes adds n to the bytes counter.
+func (c *OTelCollector) AddBytes(op string, outcome Outcome, n int64) {
+	c.bytes.Add(context.Background(), n, attributes(op, outcome)...)
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+)
+
+type measurement struct {
+	value float64
+	attrs []attribute.KeyValue
+}
+
+// fakeMeter records the measurements of the instruments that OTelCollector uses.
+type fakeMeter struct {
+	metric.Meter
+	measurements map[string][]measurement
+}
+
+type fakeHistogram struct {
+	instrument.Float64Histogram
+	name  string
+	meter *fakeMeter
+}
+
+func (h *fakeHistogram) Record(_ context.Context, v float64, attrs ...attribute.KeyValue) {
+	h.meter.measurements[h.name] = append(h.meter.measurements[h.name], measurement{v, attrs})
+}
+
+type fakeCounter struct {
+	instrument.Int64Counter
+	name  string
+	meter *fakeMeter
+}
+
+func (c *fakeCounter) Add(_ context.Context, v int64, attrs ...attribute.KeyValue) {
+	c.meter.measurements[c.name] = append(c.meter.measurements[c.name], measurement{float64(v), attrs})
+}
+
+func (m *fakeMeter) Float64Histogram(name string, opts ...instrument.Float64Option) (instrument.Float64Histogram, error) {
+	h, err := m.Meter.Float64Histogram(name, opts...)
+	return &fakeHistogram{Float64Histogram: h, name: name, meter: m}, err
+}
+
+func (m *fakeMeter) Int64Counter(name string, opts ...instrument.Int64Option) (instrument.Int64Counter, error) {
+	c, err := m.Meter.Int64Counter(name, opts...)
+	return &fakeCounter{Int64Counter: c, name: name, meter: m}, err
+}
+
+func TestOTelCollector(t *testing.T) {
+	t.Parallel()
+
+	meter := &fakeMeter{Meter: metric.NewNoopMeter(), measurements: make(map[string][]measurement)}
+	c, err := NewOTelCollector(meter)
+	require.NoError(t, err)
+
+	c.ObserveLatency("get", Success, 1500*time.Microsecond)
+	c.AddBytes("put", Error, 42)
+
+	attrs := func(op string, outcome Outcome) []attribute.KeyValue {
+		return []attribute.KeyValue{attribute.String(OperationAttribute, op), attribute.String(OutcomeAttribute, string(outcome))}
+	}
+	assert.Equal(t, []measurement{{1.5, attrs("get", Success)}}, meter.measurements[LatencyInstrumentName])
+	assert.Equal(t, []measurement{{42, attrs("put", Error)}}, meter.measurements[BytesInstrumentName])
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"google.golang.org/grpc"
+)
+
+// ArchiveRecordBlobs writes the zip archive of the blobs of the record in in
+// to w as it is received, and returns the number of bytes written. See the
+// ArchiveRecordBlobs RPC for the entries of the archive. If it returns an
+// error, the archive written to w is incomplete.
+func (c *Client) ArchiveRecordBlobs(ctx context.Context, in *pb.ArchiveRecordBlobsRequest, w io.Writer, opts ...grpc.CallOption) (int64, error) {
+	stream, err := c.OpenSavesClient.ArchiveRecordBlobs(ctx, in, opts...)
+	if err != nil {
+		return 0, err
+	}
+	written := int64(0)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(res.GetContent())
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeArchiveClient streams parts and then fails with err if it is set.
+type fakeArchiveClient struct {
+	pb.OpenSavesClient
+	parts [][]byte
+	err   error
+}
+
+func (f *fakeArchiveClient) ArchiveRecordBlobs(ctx context.Context, in *pb.ArchiveRecordBlobsRequest, opts ...grpc.CallOption) (pb.OpenSaves_ArchiveRecordBlobsClient, error) {
+	return &fakeArchiveStream{parts: f.parts, err: f.err}, nil
+}
+
+type fakeArchiveStream struct {
+	grpc.ClientStream
+	parts [][]byte
+	err   error
+}
+
+func (s *fakeArchiveStream) Recv() (*pb.ArchiveRecordBlobsResponse, error) {
+	if len(s.parts) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	part := s.parts[0]
+	s.parts = s.parts[1:]
+	return &pb.ArchiveRecordBlobsResponse{Content: part}, nil
+}
+
+func TestClient_ArchiveRecordBlobs(t *testing.T) {
+	c := &Client{OpenSavesClient: &fakeArchiveClient{parts: [][]byte{[]byte("PK"), []byte("archive")}}}
+	buf := new(bytes.Buffer)
+	n, err := c.ArchiveRecordBlobs(context.Background(), &pb.ArchiveRecordBlobsRequest{}, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), n)
+	assert.Equal(t, "PKarchive", buf.String())
+}
+
+func TestClient_ArchiveRecordBlobsError(t *testing.T) {
+	c := &Client{OpenSavesClient: &fakeArchiveClient{
+		parts: [][]byte{[]byte("PK")},
+		err:   status.Error(codes.Unavailable, "connection lost"),
+	}}
+	buf := new(bytes.Buffer)
+	n, err := c.ArchiveRecordBlobs(context.Background(), &pb.ArchiveRecordBlobsRequest{}, buf)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int64(2), n)
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/googleforgames/open-saves/internal/pkg/metadb/checksums"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrShortRead is returned by DownloadBlob when the server sends less content
+// than the size of the blob, e.g. because the stream was interrupted.
+// The download can be resumed from the number of bytes written so far.
+var ErrShortRead = errors.New("the server sent less content than the blob size")
+
+// DownloadBlob writes the content of the blob of the record in in to w,
+// starting from in.Offset, and returns the metadata of the blob.
+//
+// To resume an interrupted download, set in.Offset to the number of bytes
+// already written and pass the reader of those bytes as received. They are
+// used to verify the checksums of the whole blob once the download completes.
+// Checksums are not verified if received is nil and in.Offset is not zero.
+//
+// It returns an error wrapping ErrShortRead if the blob is incomplete, and a
+// DataLoss error if the server sends too much content or the checksums don't
+// match.
+func (c *Client) DownloadBlob(ctx context.Context, in *pb.GetBlobRequest, w io.Writer, received io.Reader, opts ...grpc.CallOption) (*pb.BlobMetadata, error) {
+	stream, err := c.OpenSavesClient.GetBlob(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	meta := res.GetMetadata()
+	if meta == nil {
+		return nil, status.Error(codes.Internal, "DownloadBlob: the first message must be metadata")
+	}
+
+	digest := checksums.NewDigest()
+	verify := in.GetOffset() == 0 || received != nil
+	if in.GetOffset() > 0 && received != nil {
+		if _, err := io.CopyN(digest, received, in.GetOffset()); err != nil {
+			return nil, fmt.Errorf("DownloadBlob: failed to read the %v bytes already received: %w", in.GetOffset(), err)
+		}
+	}
+
+	written := in.GetOffset()
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return meta, err
+		}
+		content := res.GetContent()
+		n, err := w.Write(content)
+		written += int64(n)
+		if err != nil {
+			return meta, err
+		}
+		digest.Write(content)
+	}
+
+	if written < meta.GetSize() {
+		return meta, fmt.Errorf("%w: got %v of %v bytes", ErrShortRead, written, meta.GetSize())
+	}
+	if written > meta.GetSize() {
+		return meta, status.Errorf(codes.DataLoss, "DownloadBlob: got %v bytes, more than the blob size (%v)", written, meta.GetSize())
+	}
+	if verify {
+		sums := digest.Checksums()
+		if err := sums.ValidateIfPresent(meta); err != nil {
+			return meta, err
+		}
+	}
+	return meta, nil
+}
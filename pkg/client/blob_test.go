@@ -0,0 +1,143 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeBlobClient serves content from the requested offset in messages of
+// chunkSize bytes. It stops after sending limit bytes if limit is positive.
+type fakeBlobClient struct {
+	pb.OpenSavesClient
+	content   []byte
+	chunkSize int
+	limit     int
+	requests  []*pb.GetBlobRequest
+}
+
+func (f *fakeBlobClient) GetBlob(ctx context.Context, in *pb.GetBlobRequest, opts ...grpc.CallOption) (pb.OpenSaves_GetBlobClient, error) {
+	f.requests = append(f.requests, in)
+	md5sum := md5.Sum(f.content)
+	res := []*pb.GetBlobResponse{{Response: &pb.GetBlobResponse_Metadata{Metadata: &pb.BlobMetadata{
+		Size:      int64(len(f.content)),
+		Md5:       md5sum[:],
+		Crc32C:    crc32.Checksum(f.content, crc32.MakeTable(crc32.Castagnoli)),
+		HasCrc32C: true,
+	}}}}
+	content := f.content[in.GetOffset():]
+	if f.limit > 0 && f.limit < len(content) {
+		content = content[:f.limit]
+	}
+	for len(content) > 0 {
+		n := f.chunkSize
+		if n > len(content) {
+			n = len(content)
+		}
+		res = append(res, &pb.GetBlobResponse{Response: &pb.GetBlobResponse_Content{Content: content[:n]}})
+		content = content[n:]
+	}
+	return &fakeGetBlobStream{responses: res}, nil
+}
+
+type fakeGetBlobStream struct {
+	grpc.ClientStream
+	responses []*pb.GetBlobResponse
+}
+
+func (s *fakeGetBlobStream) Recv() (*pb.GetBlobResponse, error) {
+	if len(s.responses) == 0 {
+		return nil, io.EOF
+	}
+	res := s.responses[0]
+	s.responses = s.responses[1:]
+	return res, nil
+}
+
+func newBlobTestClient(content []byte) (*Client, *fakeBlobClient) {
+	fake := &fakeBlobClient{content: content, chunkSize: 7}
+	return &Client{OpenSavesClient: fake}, fake
+}
+
+var testBlobContent = []byte("The quick brown fox jumps over the lazy dog.")
+
+func TestClient_DownloadBlob(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newBlobTestClient(testBlobContent)
+
+	var buf bytes.Buffer
+	meta, err := c.DownloadBlob(ctx, &pb.GetBlobRequest{StoreKey: "store", RecordKey: "record"}, &buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(testBlobContent)), meta.GetSize())
+	assert.Equal(t, testBlobContent, buf.Bytes())
+}
+
+func TestClient_DownloadBlobResume(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newBlobTestClient(testBlobContent)
+
+	// The first download is interrupted in the middle.
+	fake.limit = 20
+	var buf bytes.Buffer
+	_, err := c.DownloadBlob(ctx, &pb.GetBlobRequest{StoreKey: "store", RecordKey: "record"}, &buf, nil)
+	assert.True(t, errors.Is(err, ErrShortRead), "DownloadBlob() = %v, want ErrShortRead", err)
+	require.Equal(t, 20, buf.Len())
+
+	// Resume from the bytes written so far.
+	fake.limit = 0
+	received := bytes.NewReader(buf.Bytes())
+	_, err = c.DownloadBlob(ctx, &pb.GetBlobRequest{StoreKey: "store", RecordKey: "record", Offset: int64(buf.Len())}, &buf, received)
+	require.NoError(t, err)
+	assert.Equal(t, testBlobContent, buf.Bytes())
+	if assert.Len(t, fake.requests, 2) {
+		assert.Equal(t, int64(20), fake.requests[1].GetOffset())
+	}
+}
+
+func TestClient_DownloadBlobResumeChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newBlobTestClient(testBlobContent)
+
+	// The bytes received before don't match the blob.
+	received := bytes.NewReader([]byte("The slow"))
+	var buf bytes.Buffer
+	_, err := c.DownloadBlob(ctx, &pb.GetBlobRequest{Offset: 8}, &buf, received)
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+	assert.Equal(t, testBlobContent[8:], buf.Bytes())
+}
+
+func TestClient_DownloadBlobShortRead(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newBlobTestClient(testBlobContent)
+	fake.limit = 10
+
+	var buf bytes.Buffer
+	_, err := c.DownloadBlob(ctx, &pb.GetBlobRequest{Offset: 4}, &buf, bytes.NewReader(testBlobContent[:4]))
+	assert.True(t, errors.Is(err, ErrShortRead), "DownloadBlob() = %v, want ErrShortRead", err)
+	assert.Equal(t, testBlobContent[4:14], buf.Bytes())
+}
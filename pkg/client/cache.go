@@ -0,0 +1,188 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// recordCacheMaxEntries is the number of entries above which the record cache
+// drops expired entries.
+const recordCacheMaxEntries = 10000
+
+// WithRecordCache caches records returned by GetRecord in memory for ttl.
+// The cache is invalidated by the UpdateRecord and DeleteRecord calls of the
+// same Client, but not by changes made by other clients, so GetRecord may
+// return a stale record for up to ttl after another client updates it.
+// Use ForceRefresh to read the latest record.
+// The cache is disabled if ttl is not positive.
+func WithRecordCache(ttl time.Duration) Option {
+	return func(o *options) error {
+		o.recordCacheTTL = ttl
+		return nil
+	}
+}
+
+// forceRefresh is a grpc.CallOption that makes GetRecord bypass the cache.
+type forceRefresh struct {
+	grpc.EmptyCallOption
+}
+
+// ForceRefresh returns a call option for GetRecord that reads the record from
+// the server even if it is cached. The cache is updated with the result.
+func ForceRefresh() grpc.CallOption {
+	return forceRefresh{}
+}
+
+// GetRecord returns the record from the cache if WithRecordCache is enabled
+// and the cached record hasn't expired, and calls the server otherwise.
+// Requests with a field mask always call the server and are not cached.
+func (c *Client) GetRecord(ctx context.Context, in *pb.GetRecordRequest, opts ...grpc.CallOption) (*pb.Record, error) {
+	if c.records == nil || len(in.GetFieldMask().GetPaths()) > 0 {
+		opts, _ = removeForceRefresh(opts)
+		return c.OpenSavesClient.GetRecord(ctx, in, opts...)
+	}
+	key := recordCacheKey{storeKey: in.GetStoreKey(), key: in.GetKey()}
+	opts, refresh := removeForceRefresh(opts)
+	if !refresh {
+		if r, ok := c.records.get(key); ok {
+			return r, nil
+		}
+	}
+	// An update or deletion while the record is read invalidates the result.
+	gen := c.records.generation()
+	r, err := c.OpenSavesClient.GetRecord(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.records.set(key, r, gen)
+	return r, nil
+}
+
+// UpdateRecord updates the record and removes it from the cache.
+func (c *Client) UpdateRecord(ctx context.Context, in *pb.UpdateRecordRequest, opts ...grpc.CallOption) (*pb.Record, error) {
+	r, err := c.OpenSavesClient.UpdateRecord(ctx, in, opts...)
+	if c.records != nil {
+		c.records.invalidate(recordCacheKey{storeKey: in.GetStoreKey(), key: in.GetRecord().GetKey()})
+	}
+	return r, err
+}
+
+// DeleteRecord deletes the record and removes it from the cache.
+func (c *Client) DeleteRecord(ctx context.Context, in *pb.DeleteRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	res, err := c.OpenSavesClient.DeleteRecord(ctx, in, opts...)
+	if c.records != nil {
+		c.records.invalidate(recordCacheKey{storeKey: in.GetStoreKey(), key: in.GetKey()})
+	}
+	return res, err
+}
+
+func removeForceRefresh(opts []grpc.CallOption) ([]grpc.CallOption, bool) {
+	refresh := false
+	filtered := make([]grpc.CallOption, 0, len(opts))
+	for _, o := range opts {
+		if _, ok := o.(forceRefresh); ok {
+			refresh = true
+			continue
+		}
+		filtered = append(filtered, o)
+	}
+	return filtered, refresh
+}
+
+type recordCacheKey struct {
+	storeKey, key string
+}
+
+type recordCacheEntry struct {
+	record    *pb.Record
+	expiresAt time.Time
+}
+
+// recordCache is an in-memory cache of records with a fixed TTL.
+// Records are cloned on the way in and out so that callers can't modify
+// the cached records.
+type recordCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[recordCacheKey]recordCacheEntry
+	// gen is incremented by invalidate so that set drops the records that
+	// were read before the invalidation.
+	gen uint64
+}
+
+func newRecordCache(ttl time.Duration) *recordCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &recordCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[recordCacheKey]recordCacheEntry),
+	}
+}
+
+func (c *recordCache) get(key recordCacheKey) (*pb.Record, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expiresAt) {
+		return nil, false
+	}
+	return proto.Clone(e.record).(*pb.Record), true
+}
+
+// generation returns the generation to pass to set for a record that is read
+// from now on.
+func (c *recordCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// set caches r unless the cache was invalidated after gen was returned by
+// generation, in which case r may be stale.
+func (c *recordCache) set(key recordCacheKey, r *pb.Record, gen uint64) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	if len(c.entries) >= recordCacheMaxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = recordCacheEntry{record: proto.Clone(r).(*pb.Record), expiresAt: now.Add(c.ttl)}
+}
+
+func (c *recordCache) invalidate(key recordCacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	c.gen++
+}
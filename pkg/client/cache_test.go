@@ -0,0 +1,180 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// fakeRecordClient serves records from a map and counts GetRecord calls.
+type fakeRecordClient struct {
+	pb.OpenSavesClient
+	records map[string]*pb.Record
+	gets    int
+	// onGet is called by GetRecord after reading the record if not nil.
+	onGet func()
+}
+
+func (f *fakeRecordClient) GetRecord(ctx context.Context, in *pb.GetRecordRequest, opts ...grpc.CallOption) (*pb.Record, error) {
+	f.gets++
+	r := f.records[in.GetKey()]
+	if in.GetFieldMask() != nil {
+		r = &pb.Record{Key: r.GetKey()}
+	}
+	if f.onGet != nil {
+		f.onGet()
+	}
+	return r, nil
+}
+
+func (f *fakeRecordClient) UpdateRecord(ctx context.Context, in *pb.UpdateRecordRequest, opts ...grpc.CallOption) (*pb.Record, error) {
+	f.records[in.GetRecord().GetKey()] = in.GetRecord()
+	return in.GetRecord(), nil
+}
+
+func (f *fakeRecordClient) DeleteRecord(ctx context.Context, in *pb.DeleteRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	delete(f.records, in.GetKey())
+	return new(emptypb.Empty), nil
+}
+
+func newCachedTestClient(ttl time.Duration) (*Client, *fakeRecordClient, *time.Time) {
+	fake := &fakeRecordClient{records: map[string]*pb.Record{"key": {Key: "key", OpaqueString: "v1"}}}
+	c := &Client{OpenSavesClient: fake, records: newRecordCache(ttl)}
+	now := time.Unix(1000, 0)
+	c.records.now = func() time.Time { return now }
+	return c, fake, &now
+}
+
+func mustGetOpaqueString(ctx context.Context, t *testing.T, c *Client, want string, opts ...grpc.CallOption) {
+	t.Helper()
+	r, err := c.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: "store", Key: "key"}, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, want, r.GetOpaqueString())
+}
+
+func TestClient_GetRecordCacheHit(t *testing.T) {
+	ctx := context.Background()
+	c, fake, now := newCachedTestClient(time.Minute)
+
+	mustGetOpaqueString(ctx, t, c, "v1")
+	// Modifying the returned record doesn't change the cached one.
+	r, err := c.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: "store", Key: "key"})
+	require.NoError(t, err)
+	r.OpaqueString = "modified"
+	// Updates by other clients are not seen within the TTL.
+	fake.records["key"] = &pb.Record{Key: "key", OpaqueString: "v2"}
+	*now = now.Add(time.Minute - time.Nanosecond)
+	mustGetOpaqueString(ctx, t, c, "v1")
+	assert.Equal(t, 1, fake.gets)
+
+	// Another store has a separate entry.
+	_, err = c.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: "other", Key: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.gets)
+}
+
+func TestClient_GetRecordCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	c, fake, now := newCachedTestClient(time.Minute)
+
+	mustGetOpaqueString(ctx, t, c, "v1")
+	fake.records["key"] = &pb.Record{Key: "key", OpaqueString: "v2"}
+	*now = now.Add(time.Minute)
+	mustGetOpaqueString(ctx, t, c, "v2")
+	assert.Equal(t, 2, fake.gets)
+}
+
+func TestClient_GetRecordForceRefresh(t *testing.T) {
+	ctx := context.Background()
+	c, fake, _ := newCachedTestClient(time.Minute)
+
+	mustGetOpaqueString(ctx, t, c, "v1")
+	fake.records["key"] = &pb.Record{Key: "key", OpaqueString: "v2"}
+	mustGetOpaqueString(ctx, t, c, "v2", ForceRefresh())
+	// The refreshed record is cached.
+	mustGetOpaqueString(ctx, t, c, "v2")
+	assert.Equal(t, 2, fake.gets)
+}
+
+func TestClient_RecordCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	c, fake, _ := newCachedTestClient(time.Minute)
+
+	mustGetOpaqueString(ctx, t, c, "v1")
+	_, err := c.UpdateRecord(ctx, &pb.UpdateRecordRequest{StoreKey: "store", Record: &pb.Record{Key: "key", OpaqueString: "v2"}})
+	require.NoError(t, err)
+	mustGetOpaqueString(ctx, t, c, "v2")
+	assert.Equal(t, 2, fake.gets)
+
+	_, err = c.DeleteRecord(ctx, &pb.DeleteRecordRequest{StoreKey: "store", Key: "key"})
+	require.NoError(t, err)
+	r, err := c.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: "store", Key: "key"})
+	require.NoError(t, err)
+	assert.Nil(t, r)
+	assert.Equal(t, 3, fake.gets)
+}
+
+func TestClient_GetRecordFieldMaskNotCached(t *testing.T) {
+	ctx := context.Background()
+	c, fake, _ := newCachedTestClient(time.Minute)
+
+	masked := &pb.GetRecordRequest{StoreKey: "store", Key: "key", FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"key"}}}
+	r, err := c.GetRecord(ctx, masked)
+	require.NoError(t, err)
+	assert.Empty(t, r.GetOpaqueString())
+	// The masked record is not returned for a full read.
+	mustGetOpaqueString(ctx, t, c, "v1")
+	// A cached full record is not returned for a masked read.
+	r, err = c.GetRecord(ctx, masked)
+	require.NoError(t, err)
+	assert.Empty(t, r.GetOpaqueString())
+	assert.Equal(t, 3, fake.gets)
+}
+
+func TestClient_RecordCacheInvalidationDuringGet(t *testing.T) {
+	ctx := context.Background()
+	c, fake, _ := newCachedTestClient(time.Minute)
+
+	// The record is updated after the stale record was read by the server.
+	fake.onGet = func() {
+		fake.onGet = nil
+		_, err := c.UpdateRecord(ctx, &pb.UpdateRecordRequest{StoreKey: "store", Record: &pb.Record{Key: "key", OpaqueString: "v2"}})
+		require.NoError(t, err)
+	}
+	mustGetOpaqueString(ctx, t, c, "v1")
+	mustGetOpaqueString(ctx, t, c, "v2")
+	assert.Equal(t, 2, fake.gets)
+}
+
+func TestClient_RecordCacheDisabled(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeRecordClient{records: map[string]*pb.Record{"key": {Key: "key"}}}
+	c := &Client{OpenSavesClient: fake, records: newRecordCache(0)}
+
+	for i := 0; i < 2; i++ {
+		_, err := c.GetRecord(ctx, &pb.GetRecordRequest{StoreKey: "store", Key: "key"}, ForceRefresh())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, fake.gets)
+}
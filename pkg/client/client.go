@@ -0,0 +1,59 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client provides a Go client of the Open Saves gRPC service.
+package client
+
+import (
+	"context"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"google.golang.org/grpc"
+)
+
+// Client is a client of the Open Saves service.
+// It embeds pb.OpenSavesClient so that all RPCs can be called directly.
+type Client struct {
+	pb.OpenSavesClient
+	conn *grpc.ClientConn
+	// records is nil unless WithRecordCache is given.
+	records *recordCache
+}
+
+// New creates a Client connected to address.
+// The connection uses TLS with the system root CAs unless one of the
+// transport options, WithTLS, WithMTLS, or WithInsecure, is given.
+// Errors in options, such as unreadable certificate files, are returned here
+// rather than from the first RPC.
+func New(ctx context.Context, address string, opts ...Option) (*Client, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(o.creds)}, o.dialOptions...)
+	conn, err := grpc.DialContext(ctx, address, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		OpenSavesClient: pb.NewOpenSavesClient(conn),
+		conn:            conn,
+		records:         newRecordCache(o.recordCacheTTL),
+	}, nil
+}
+
+// Close closes the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
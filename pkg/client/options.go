@@ -0,0 +1,104 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Option configures a Client.
+type Option func(*options) error
+
+type options struct {
+	creds          credentials.TransportCredentials
+	dialOptions    []grpc.DialOption
+	recordCacheTTL time.Duration
+}
+
+func newOptions(opts []Option) (*options, error) {
+	o := new(options)
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	if o.creds == nil {
+		o.creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	return o, nil
+}
+
+// WithTLS uses TLS with config to connect to the server.
+// A nil config uses the system root CAs.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) error {
+		if config == nil {
+			config = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		o.creds = credentials.NewTLS(config)
+		return nil
+	}
+}
+
+// WithMTLS uses mutual TLS to connect to the server. The client presents the
+// certificate in certFile and keyFile, and verifies the server with the CA
+// certificates in caFile.
+func WithMTLS(certFile, keyFile, caFile string) Option {
+	return func(o *options) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return errors.New("no valid CA certificates were found in " + caFile)
+		}
+		o.creds = credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      pool,
+			MinVersion:   tls.VersionTLS12,
+		})
+		return nil
+	}
+}
+
+// WithInsecure disables transport security. Use it only for local development.
+func WithInsecure() Option {
+	return func(o *options) error {
+		o.creds = insecure.NewCredentials()
+		return nil
+	}
+}
+
+// WithDialOptions appends gRPC dial options, e.g. for interceptors or per-RPC credentials.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) error {
+		o.dialOptions = append(o.dialOptions, opts...)
+		return nil
+	}
+}
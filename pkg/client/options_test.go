@@ -0,0 +1,208 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testPKI struct {
+	caFile, certFile, keyFile string
+	serverCert                tls.Certificate
+	pool                      *x509.CertPool
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600))
+}
+
+// newTestPKI creates a CA and server and client certificates signed by it.
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		return der, key
+	}
+
+	p := &testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client.key"),
+		pool:     x509.NewCertPool(),
+	}
+	p.pool.AddCert(caCert)
+	writePEM(t, p.caFile, "CERTIFICATE", caDER)
+
+	clientDER, clientKey := issue(2, x509.ExtKeyUsageClientAuth)
+	writePEM(t, p.certFile, "CERTIFICATE", clientDER)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	require.NoError(t, err)
+	writePEM(t, p.keyFile, "EC PRIVATE KEY", keyDER)
+
+	serverDER, serverKey := issue(3, x509.ExtKeyUsageServerAuth)
+	p.serverCert = tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+	return p
+}
+
+// startMTLSServer starts a gRPC health server that requires client certificates.
+func startMTLSServer(t *testing.T, p *testPKI) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{p.serverCert},
+		ClientCAs:    p.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	s := grpc.NewServer(grpc.Creds(creds))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func checkHealth(ctx context.Context, t *testing.T, address string, opts ...Option) error {
+	t.Helper()
+	c, err := New(ctx, address, opts...)
+	require.NoError(t, err)
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(c.conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestOptions_TransportCredentials(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPKI(t)
+	testCases := []struct {
+		name     string
+		opts     []Option
+		protocol string
+	}{
+		{"default", nil, "tls"},
+		{"WithTLS", []Option{WithTLS(&tls.Config{RootCAs: p.pool})}, "tls"},
+		{"WithTLS nil", []Option{WithTLS(nil)}, "tls"},
+		{"WithMTLS", []Option{WithMTLS(p.certFile, p.keyFile, p.caFile)}, "tls"},
+		{"WithInsecure", []Option{WithInsecure()}, "insecure"},
+		{"last wins", []Option{WithMTLS(p.certFile, p.keyFile, p.caFile), WithInsecure()}, "insecure"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			o, err := newOptions(tc.opts)
+			require.NoError(t, err)
+			if assert.NotNil(t, o.creds) {
+				assert.Equal(t, tc.protocol, o.creds.Info().SecurityProtocol)
+			}
+
+			c, err := New(context.Background(), "localhost:0", tc.opts...)
+			require.NoError(t, err)
+			assert.NotNil(t, c.OpenSavesClient)
+			assert.NoError(t, c.Close())
+		})
+	}
+}
+
+func TestOptions_WithMTLSBadPaths(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPKI(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	testCases := []struct {
+		name                      string
+		certFile, keyFile, caFile string
+	}{
+		{"missing cert", missing, p.keyFile, p.caFile},
+		{"missing key", p.certFile, missing, p.caFile},
+		{"missing CA", p.certFile, p.keyFile, missing},
+		{"invalid CA", p.certFile, p.keyFile, p.keyFile},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c, err := New(context.Background(), "localhost:0", WithMTLS(tc.certFile, tc.keyFile, tc.caFile))
+			assert.Error(t, err)
+			assert.Nil(t, c)
+		})
+	}
+}
+
+func TestOptions_MTLSHandshake(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p := newTestPKI(t)
+	address := startMTLSServer(t, p)
+
+	assert.NoError(t, checkHealth(ctx, t, address, WithMTLS(p.certFile, p.keyFile, p.caFile)))
+	// The server rejects clients without a certificate.
+	assert.Error(t, checkHealth(ctx, t, address, WithTLS(&tls.Config{RootCAs: p.pool})))
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadBufferSize is the maximum size of the content of a CreateBlob message
+// sent by UploadBlob.
+const uploadBufferSize = 1 * 1024 * 1024 // 1 MiB
+
+// ProgressFunc is called with the number of bytes sent so far and the total
+// size of the upload.
+type ProgressFunc func(bytesSent, totalBytes int64)
+
+// withProgress is a grpc.CallOption that sets the progress callback of UploadBlob.
+type withProgress struct {
+	grpc.EmptyCallOption
+	fn ProgressFunc
+}
+
+// WithProgress returns a call option for UploadBlob that calls fn as the
+// content is sent. fn is called synchronously from the goroutine that called
+// UploadBlob, after each message is sent, so a slow fn slows down the upload.
+// bytesSent never decreases, and if the upload succeeds, the last call is
+// made with bytesSent equal to totalBytes once the server has stored the blob.
+func WithProgress(fn ProgressFunc) grpc.CallOption {
+	return withProgress{fn: fn}
+}
+
+func removeWithProgress(opts []grpc.CallOption) ([]grpc.CallOption, ProgressFunc) {
+	var progress ProgressFunc
+	filtered := make([]grpc.CallOption, 0, len(opts))
+	for _, o := range opts {
+		if p, ok := o.(withProgress); ok {
+			progress = p.fn
+			continue
+		}
+		filtered = append(filtered, o)
+	}
+	return filtered, progress
+}
+
+// UploadBlob creates the blob described by meta with the content read from r
+// until EOF, and returns the metadata of the created blob. meta.Size must be
+// the length of the content. Pass WithProgress to be notified of the progress.
+func (c *Client) UploadBlob(ctx context.Context, meta *pb.BlobMetadata, r io.Reader, opts ...grpc.CallOption) (*pb.BlobMetadata, error) {
+	opts, progress := removeWithProgress(opts)
+	if progress == nil {
+		progress = func(int64, int64) {}
+	}
+	if meta.GetSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "UploadBlob: size (%v) must not be negative", meta.GetSize())
+	}
+
+	// Canceling the stream on errors makes the server discard the partial upload.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.OpenSavesClient.CreateBlob(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&pb.CreateBlobRequest{Request: &pb.CreateBlobRequest_Metadata{Metadata: meta}}); err != nil {
+		return nil, err
+	}
+
+	total := meta.GetSize()
+	sent := int64(0)
+	for {
+		// A new buffer for each message, as messages must not be modified
+		// after they are sent.
+		buf := make([]byte, uploadBufferSize)
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.CreateBlobRequest{Request: &pb.CreateBlobRequest_Content{Content: buf[:n]}}); err != nil {
+				// The actual error is returned by CloseAndRecv.
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+			sent += int64(n)
+			if sent < total {
+				progress(sent, total)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	progress(total, total)
+	return res, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	pb "github.com/googleforgames/open-saves/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUploadClient receives CreateBlob streams, and fails them with err if set.
+type fakeUploadClient struct {
+	pb.OpenSavesClient
+	stream *fakeCreateBlobStream
+	err    error
+}
+
+func (f *fakeUploadClient) CreateBlob(ctx context.Context, opts ...grpc.CallOption) (pb.OpenSaves_CreateBlobClient, error) {
+	f.stream = &fakeCreateBlobStream{err: f.err}
+	return f.stream, nil
+}
+
+type fakeCreateBlobStream struct {
+	grpc.ClientStream
+	meta     *pb.BlobMetadata
+	content  bytes.Buffer
+	messages int
+	err      error
+}
+
+func (s *fakeCreateBlobStream) Send(req *pb.CreateBlobRequest) error {
+	if m := req.GetMetadata(); m != nil {
+		s.meta = m
+		return nil
+	}
+	s.messages++
+	s.content.Write(req.GetContent())
+	return nil
+}
+
+func (s *fakeCreateBlobStream) CloseAndRecv() (*pb.BlobMetadata, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &pb.BlobMetadata{StoreKey: s.meta.GetStoreKey(), RecordKey: s.meta.GetRecordKey(), Size: int64(s.content.Len())}, nil
+}
+
+type progressCall struct {
+	sent, total int64
+}
+
+func TestClient_UploadBlobProgress(t *testing.T) {
+	ctx := context.Background()
+	fake := new(fakeUploadClient)
+	c := &Client{OpenSavesClient: fake}
+
+	content := bytes.Repeat([]byte("0123456789"), uploadBufferSize/4)
+	var calls []progressCall
+	meta, err := c.UploadBlob(ctx, &pb.BlobMetadata{StoreKey: "store", RecordKey: "record", Size: int64(len(content))},
+		bytes.NewReader(content), WithProgress(func(sent, total int64) {
+			// The callback runs on the uploading goroutine, between sends.
+			assert.Equal(t, int64(fake.stream.content.Len()), sent)
+			calls = append(calls, progressCall{sent, total})
+		}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), meta.GetSize())
+	assert.Equal(t, content, fake.stream.content.Bytes())
+	assert.Equal(t, 3, fake.stream.messages)
+
+	require.Len(t, calls, 3)
+	for i, call := range calls {
+		assert.Equal(t, int64(len(content)), call.total)
+		if i > 0 {
+			assert.Greater(t, call.sent, calls[i-1].sent, "progress must be monotonic")
+		}
+	}
+	assert.Equal(t, progressCall{int64(len(content)), int64(len(content))}, calls[len(calls)-1])
+}
+
+func TestClient_UploadBlobEmpty(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{OpenSavesClient: new(fakeUploadClient)}
+
+	var calls []progressCall
+	_, err := c.UploadBlob(ctx, &pb.BlobMetadata{}, bytes.NewReader(nil), WithProgress(func(sent, total int64) {
+		calls = append(calls, progressCall{sent, total})
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []progressCall{{0, 0}}, calls)
+}
+
+func TestClient_UploadBlobError(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeUploadClient{err: status.Error(codes.InvalidArgument, "too large")}
+	c := &Client{OpenSavesClient: fake}
+
+	content := []byte("content")
+	var calls []progressCall
+	_, err := c.UploadBlob(ctx, &pb.BlobMetadata{Size: int64(len(content))}, bytes.NewReader(content), WithProgress(func(sent, total int64) {
+		calls = append(calls, progressCall{sent, total})
+	}))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Empty(t, calls, "failed uploads must not report completion")
+
+	readErr := errors.New("read failed")
+	_, err = c.UploadBlob(ctx, &pb.BlobMetadata{Size: 10}, io.MultiReader(bytes.NewReader(content), &errReader{readErr}))
+	assert.Equal(t, readErr, err)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}